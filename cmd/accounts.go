@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/exitcode"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// ACCOUNTS COMMAND DEFINITIONS
+// =============================================================================
+
+// accountsCmd represents the local account settings command
+var accountsCmd = &cobra.Command{
+	Use:   "accounts",
+	Short: "Manage local account settings",
+	Long: `Manage settings fintrack keeps locally for each account.
+
+Bend only provides partial history for older accounts, so computed balances
+need an anchor: an opening balance and the date it applies from. Transactions
+dated before an account's start date are ignored.
+
+Available subcommands:
+- show: Display local settings for all accounts
+- set-opening: Set an account's opening balance and history start date
+- clear-opening: Remove an account's opening balance and start date
+- set-consent: Record when an account's aggregator consent expires
+- tag: Give an account a nickname or group, or exclude it
+- consents: Show when each account's aggregator consent expires`,
+}
+
+// accountsShowCmd shows local account settings
+var accountsShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show local account settings",
+	RunE:  runAccountsShow,
+}
+
+// accountsSetOpeningCmd sets an opening balance
+var accountsSetOpeningCmd = &cobra.Command{
+	Use:   "set-opening <account-uuid> <balance>",
+	Short: "Set an account's opening balance and start date",
+	Args:  cobra.ExactArgs(2),
+	Example: `  fintrack accounts set-opening 6f1c...-uuid 125000.50 --start-date 2023-04-01
+  fintrack accounts set-opening 6f1c...-uuid 0`,
+	RunE: runAccountsSetOpening,
+}
+
+// accountsClearOpeningCmd removes an opening balance
+var accountsClearOpeningCmd = &cobra.Command{
+	Use:   "clear-opening <account-uuid>",
+	Short: "Remove an account's opening balance and start date",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAccountsClearOpening,
+}
+
+// accountsSetConsentCmd records a consent expiry Bend doesn't report
+var accountsSetConsentCmd = &cobra.Command{
+	Use:   "set-consent <account-uuid> <expiry-date>",
+	Short: "Record when an account's aggregator consent expires",
+	Long: `Record the date an account's account aggregator consent expires, for
+accounts whose expiry Bend doesn't report. The date is shown when the consent
+is granted or renewed in the Bend app.`,
+	Args:    cobra.ExactArgs(2),
+	Example: `  fintrack accounts set-consent 6f1c...-uuid 2027-04-01`,
+	RunE:    runAccountsSetConsent,
+}
+
+// accountsTagCmd sets an account's nickname, group and exclude flag
+var accountsTagCmd = &cobra.Command{
+	Use:   "tag <account>",
+	Short: "Set an account's nickname, group or exclude flag",
+	Long: `Give an account a nickname, put it in a group or exclude it.
+
+A nickname is shown in place of the account's UUID and accepted wherever an
+account is given. Groups (e.g. "personal", "business") can be picked with
+--group on bend transactions, bend accounts and the report commands.
+Excluded accounts are skipped by sync, reports and net worth, like those
+listed under accounts.exclude; report --group still picks them by group.
+An empty --nickname or --group removes it.`,
+	Args: cobra.ExactArgs(1),
+	Example: `  fintrack accounts tag 6f1c...-uuid --nickname salary --group personal
+  fintrack accounts tag salary --group ""
+  fintrack accounts tag old-card --exclude`,
+	RunE: runAccountsTag,
+}
+
+var openingStartDate string
+
+var (
+	tagNickname, tagGroup  string
+	tagExclude, tagInclude bool
+)
+
+func init() {
+	accountsSetOpeningCmd.Flags().StringVar(&openingStartDate, "start-date", "", "Date the opening balance applies from (YYYY-MM-DD)")
+
+	accountsCmd.AddCommand(accountsShowCmd)
+	accountsCmd.AddCommand(accountsSetOpeningCmd)
+	accountsCmd.AddCommand(accountsClearOpeningCmd)
+	accountsTagCmd.Flags().StringVar(&tagNickname, "nickname", "", "Short name for the account")
+	accountsTagCmd.Flags().StringVar(&tagGroup, "group", "", "Group the account belongs to, e.g. personal or business")
+	accountsTagCmd.Flags().BoolVar(&tagExclude, "exclude", false, "Skip the account in sync, reports and net worth")
+	accountsTagCmd.Flags().BoolVar(&tagInclude, "include", false, "Stop skipping the account")
+
+	accountsCmd.AddCommand(accountsSetConsentCmd)
+	accountsCmd.AddCommand(accountsTagCmd)
+	accountsCmd.AddCommand(accountsConsentsCmd)
+}
+
+// =============================================================================
+// ACCOUNTS COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runAccountsShow prints the local settings of every configured account
+func runAccountsShow(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	if len(cfg.Accounts.Settings) == 0 {
+		fmt.Println("No local account settings. Use 'fintrack accounts set-opening' to add one")
+		return nil
+	}
+
+	ids := make([]string, 0, len(cfg.Accounts.Settings))
+	for id := range cfg.Accounts.Settings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	f := display.New(cfg.Display)
+	table := f.Table(
+		display.Column{Header: "Account"}, display.Column{Header: "Nickname"}, display.Column{Header: "Group"},
+		display.Column{Header: "Opening Balance", Right: true}, display.Column{Header: "Start Date"},
+		display.Column{Header: "Consent Expires"}, display.Column{Header: "Status"})
+	for _, id := range ids {
+		settings := cfg.Accounts.Settings[id]
+		startDate, consentExpires := "-", "-"
+		if start, err := settings.HistoryStart(); err == nil && !start.IsZero() {
+			startDate = f.Date(start)
+		}
+		if expiry, err := settings.ConsentExpiry(); err == nil && !expiry.IsZero() {
+			consentExpires = f.Date(expiry)
+		}
+		nickname, group, status := "-", "-", "included"
+		if settings.Nickname != "" {
+			nickname = settings.Nickname
+		}
+		if settings.Group != "" {
+			group = strings.ToLower(settings.Group)
+		}
+		if cfg.AccountExcluded(id) {
+			status = "excluded"
+		}
+		table.Row(id, nickname, group, fmt.Sprintf("%.2f", settings.OpeningBalance), startDate, consentExpires, status)
+	}
+	table.Render(os.Stdout)
+
+	return nil
+}
+
+// runAccountsSetOpening writes an account's opening balance to the config file
+func runAccountsSetOpening(cmd *cobra.Command, args []string) error {
+	accountID := args[0]
+
+	balance, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return fmt.Errorf("invalid balance %q: %w", args[1], err)
+	}
+
+	if openingStartDate != "" {
+		if _, err := time.Parse("2006-01-02", openingStartDate); err != nil {
+			return fmt.Errorf("invalid start date %q (use YYYY-MM-DD)", openingStartDate)
+		}
+	}
+
+	v, err := loadViperConfig()
+	if err != nil {
+		return err
+	}
+
+	key := "accounts.settings." + accountID
+	v.Set(key+".opening_balance", balance)
+	if openingStartDate != "" {
+		v.Set(key+".start_date", openingStartDate)
+	}
+
+	if err := config.WriteConfig(v); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✓ Opening balance for %s set to %.2f", accountID, balance)
+		if openingStartDate != "" {
+			fmt.Printf(" from %s", openingStartDate)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// runAccountsClearOpening removes an account's opening balance and start date
+// from the config file
+func runAccountsClearOpening(cmd *cobra.Command, args []string) error {
+	accountID := args[0]
+
+	v, err := loadViperConfig()
+	if err != nil {
+		return err
+	}
+
+	configPath := v.ConfigFileUsed()
+	if configPath == "" {
+		return fmt.Errorf("no config file found")
+	}
+
+	// Other settings, such as the consent expiry, stay
+	found := false
+	for _, key := range []string{"opening_balance", "start_date"} {
+		deleted, err := deleteConfigKey(configPath, "accounts.settings."+accountID+"."+key)
+		if err != nil {
+			return err
+		}
+		found = found || deleted
+	}
+	if !found {
+		return fmt.Errorf("no opening balance found for account %s", accountID)
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✓ Cleared opening balance for %s\n", accountID)
+	}
+
+	return nil
+}
+
+// runAccountsSetConsent writes an account's consent expiry to the config file
+func runAccountsSetConsent(cmd *cobra.Command, args []string) error {
+	accountID, expiry := args[0], args[1]
+	if _, err := time.Parse("2006-01-02", expiry); err != nil {
+		return fmt.Errorf("invalid expiry date %q (use YYYY-MM-DD)", expiry)
+	}
+
+	v, err := loadViperConfig()
+	if err != nil {
+		return err
+	}
+
+	v.Set("accounts.settings."+accountID+".consent_expires", expiry)
+	if err := config.WriteConfig(v); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✓ Consent for %s expires %s\n", accountID, expiry)
+	}
+
+	return nil
+}
+
+// runAccountsTag writes an account's nickname, group and exclude flag to the
+// config file
+func runAccountsTag(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	flags := cmd.Flags()
+	if !flags.Changed("nickname") && !flags.Changed("group") && !tagExclude && !tagInclude {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("give at least one of --nickname, --group, --exclude or --include"))
+	}
+	if tagExclude && tagInclude {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--exclude and --include can't be combined"))
+	}
+
+	accountID := cfg.ResolveAccount(args[0])
+	nickname := strings.TrimSpace(tagNickname)
+	if nickname != "" {
+		if other := cfg.ResolveAccount(nickname); other != nickname && other != accountID {
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("nickname %q is already used by %s", nickname, other))
+		}
+	}
+
+	v, err := loadViperConfig()
+	if err != nil {
+		return err
+	}
+
+	key := "accounts.settings." + accountID
+	var cleared []string
+	if flags.Changed("nickname") {
+		if nickname == "" {
+			cleared = append(cleared, key+".nickname")
+		} else {
+			v.Set(key+".nickname", nickname)
+		}
+	}
+	if flags.Changed("group") {
+		if group := strings.ToLower(strings.TrimSpace(tagGroup)); group == "" {
+			cleared = append(cleared, key+".group")
+		} else {
+			v.Set(key+".group", group)
+		}
+	}
+	if tagExclude {
+		v.Set(key+".exclude", true)
+	} else if tagInclude {
+		cleared = append(cleared, key+".exclude")
+	}
+
+	if err := config.WriteConfig(v); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	for _, k := range cleared {
+		if _, err := deleteConfigKey(v.ConfigFileUsed(), k); err != nil {
+			return err
+		}
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✓ Updated %s\n", accountID)
+	}
+
+	return nil
+}
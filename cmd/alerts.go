@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/alerts"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/store"
+	"github.com/quickkly/fintrack/internal/syncreport"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// ALERTS COMMAND DEFINITIONS
+// =============================================================================
+
+// alertsCmd groups alert rule commands
+var alertsCmd = &cobra.Command{
+	Use:   "alerts",
+	Short: "Evaluate alert rules",
+	Long: `Alert rules in the alerts: config section are evaluated after every
+'fintrack sync'. A rule is one or more conditions joined by "and":
+
+  category=dining and monthly total > 1.5 * trailing 3 month average
+  merchant="AMAZON" and weekly count >= 5
+  type=incoming and monthly total < 50000
+
+Filters (category, subcategory, merchant, account, tag, type; = or !=) choose
+the transactions; without a type filter only outgoing transactions count.
+Comparisons test the daily, weekly or monthly total or count of the current
+period against a number or an average of previous periods. A triggered rule
+notifies its channels once per period.
+
+Available subcommands:
+- check: Evaluate the rules now`,
+}
+
+// alertsCheckCmd evaluates the rules on demand
+var alertsCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Evaluate alert rules against the local store",
+	Long: `Evaluate every alert rule against the local store and list the ones that
+trigger. Nothing is sent unless --notify is given.`,
+	Args: cobra.NoArgs,
+	RunE: runAlertsCheck,
+}
+
+var alertsNotify bool
+
+func init() {
+	alertsCheckCmd.Flags().BoolVar(&alertsNotify, "notify", false, "Send due alerts to their channels, as a sync would")
+
+	alertsCmd.AddCommand(alertsCheckCmd)
+}
+
+// =============================================================================
+// ALERTS COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runAlertsCheck prints the triggered alerts and optionally delivers them
+func runAlertsCheck(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	if len(cfg.Alerts.Rules) == 0 {
+		fmt.Println("No alert rules. Add some under alerts.rules in the config file")
+		return nil
+	}
+
+	triggered, errs := evaluateAlerts(cfg, alertsNotify && !IsDryRun())
+	for _, alert := range triggered {
+		fmt.Printf("🚨 %s\n", alert.Message)
+	}
+	if len(triggered) == 0 && !IsQuiet() {
+		fmt.Printf("✅ None of %d rule(s) triggered\n", len(cfg.Alerts.Rules))
+	}
+
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d alert delivery error(s)", len(errs))
+	}
+	return nil
+}
+
+// syncAlerts evaluates the alert rules after a sync, recording triggered
+// alerts and delivery failures in the report
+func syncAlerts(cfg *config.Config, report *syncreport.Report) {
+	if len(cfg.Alerts.Rules) == 0 {
+		return
+	}
+
+	triggered, errs := evaluateAlerts(cfg, true)
+	for _, alert := range triggered {
+		report.Alerts = append(report.Alerts, alert.Message)
+		if !IsQuiet() {
+			fmt.Printf("🚨 %s\n", alert.Message)
+		}
+	}
+	for _, err := range errs {
+		report.AddError(err)
+	}
+}
+
+// evaluateAlerts runs the rules over the store and, when notify is set,
+// delivers the alerts not yet sent for their period
+func evaluateAlerts(cfg *config.Config, notify bool) ([]alerts.Alert, []error) {
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	triggered, err := alerts.Evaluate(cfg.Alerts, st.Find(store.Query{}), time.Now(), cfg.RoundingMode())
+	if err != nil {
+		return nil, []error{err}
+	}
+	if !notify || len(triggered) == 0 {
+		return triggered, nil
+	}
+
+	state, err := alerts.LoadState(cfg.Alerts.StateFile)
+	if err != nil {
+		return triggered, []error{err}
+	}
+	_, errs := alerts.Deliver(context.Background(), cfg.Alerts, state, triggered)
+	if err := state.Save(); err != nil {
+		errs = append(errs, err)
+	}
+	return triggered, errs
+}
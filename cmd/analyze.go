@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/report"
+	"github.com/quickkly/fintrack/internal/store"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// ANALYZE COMMAND DEFINITIONS
+// =============================================================================
+
+// analyzeCmd groups analyses of the local store
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Analyze stored transactions",
+	Long: `Analyses of the transactions in the local store (store.path).
+
+Available subcommands:
+- refunds: Refunds matched to their purchases, and refunds still pending`,
+}
+
+// analyzeRefundsCmd lists pending and matched refunds
+var analyzeRefundsCmd = &cobra.Command{
+	Use:   "refunds",
+	Short: "List refunds still pending and refunds matched to purchases",
+	Long: `Match refunds and reversals to the purchases they undo and list the
+purchases Bend marks with a refund status that no refund was matched to yet.
+
+A refund matches a purchase on the same account and in the same currency, at
+the same merchant (ignoring words like "refund" or "rev" and reference
+numbers), within 10% of its amount and up to refunds.window_days after it.
+Reports (report tree, summary and merchant) count matched purchases net of
+their refunds.
+
+Examples:
+  fintrack analyze refunds
+  fintrack analyze refunds --matched           # Also list the matched pairs
+  fintrack analyze refunds --output json`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeRefunds,
+}
+
+var (
+	analyzeMatched bool
+	analyzeOutput  string
+)
+
+func init() {
+	analyzeRefundsCmd.Flags().BoolVar(&analyzeMatched, "matched", false, "Also list refunds matched to their purchase")
+	analyzeRefundsCmd.Flags().StringVarP(&analyzeOutput, "output", "o", "table", "Output format (table, json; default from display.output)")
+
+	analyzeCmd.AddCommand(analyzeRefundsCmd)
+}
+
+// =============================================================================
+// ANALYZE COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runAnalyzeRefunds prints the pending refunds and, with --matched, the pairs
+func runAnalyzeRefunds(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	transactions := st.Transactions()
+	matches := report.MatchRefunds(transactions, cfg.Refunds.WindowDays, cfg.RoundingMode())
+	pending := report.PendingRefunds(transactions, matches)
+
+	switch format := display.Output(cmd, cfg.Display); format {
+	case "table":
+		f := display.New(cfg.Display)
+		if len(pending) == 0 {
+			fmt.Println("✅ No pending refunds")
+		} else {
+			fmt.Printf("⏳ %d purchase(s) awaiting a refund:\n", len(pending))
+			table := f.Table(display.Column{Header: "Date"}, display.Column{Header: "Account"}, display.Column{Header: "Merchant"},
+				display.Column{Header: "Status"}, display.Column{Header: "Amount", Right: true})
+			for _, txn := range pending {
+				table.Row(f.Date(txn.TxnTimestamp), txn.AccountID, report.Merchant(txn), txn.Refund.Status,
+					f.Money(txn.Money(cfg.RoundingMode())))
+			}
+			table.Render(os.Stdout)
+		}
+
+		if analyzeMatched {
+			fmt.Println()
+			if len(matches) == 0 {
+				fmt.Println("📭 No refunds matched to purchases")
+				break
+			}
+			fmt.Printf("🔗 %d refund(s) matched to purchases:\n", len(matches))
+			table := f.Table(display.Column{Header: "Purchased"}, display.Column{Header: "Merchant"},
+				display.Column{Header: "Paid", Right: true}, display.Column{Header: "Refunded"},
+				display.Column{Header: "Refund", Right: true}, display.Column{Header: "After", Right: true})
+			for _, m := range matches {
+				table.Row(f.Date(m.Purchase.TxnTimestamp), report.Merchant(m.Purchase), f.Money(m.Purchase.Money(cfg.RoundingMode())),
+					f.Date(m.Refund.TxnTimestamp), f.Money(m.Refund.Money(cfg.RoundingMode())), fmt.Sprintf("%d days", m.Days))
+			}
+			table.Render(os.Stdout)
+		} else if IsVerbose() && len(matches) > 0 {
+			fmt.Printf("\n🔗 %d refund(s) matched to purchases (--matched lists them)\n", len(matches))
+		}
+
+	case "json":
+		result := struct {
+			Pending []blend.Transaction  `json:"pending"`
+			Matched []report.RefundMatch `json:"matched,omitempty"`
+		}{Pending: pending}
+		if result.Pending == nil {
+			result.Pending = []blend.Transaction{}
+		}
+		if analyzeMatched {
+			result.Matched = matches
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal refunds to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table or json", format)
+	}
+	return nil
+}
+
+// netOfRefunds takes matched refunds off their purchases, so reports show
+// net spend. It needs the full history, since a refund can land in a later
+// period than its purchase.
+func netOfRefunds(cfg *config.Config, transactions []blend.Transaction) []blend.Transaction {
+	matches := report.MatchRefunds(transactions, cfg.Refunds.WindowDays, cfg.RoundingMode())
+	return report.NetRefunds(transactions, matches)
+}
@@ -0,0 +1,427 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/backup"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/safefile"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// BACKUP COMMAND DEFINITIONS
+// =============================================================================
+
+// backupCmd groups the backup commands
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up local data to one encrypted archive, or restore it",
+	Long: `Back up everything fintrack keeps locally to a single passphrase-encrypted
+archive, to keep safe or to move to a new machine:
+
+- the local store (transactions, tags, splits and the undo history)
+- the config file (accounts settings, goals, alert rules, contacts, ...)
+  with tokens and passwords removed unless --include-secrets is given
+- the alert state, so alerts already sent aren't sent again
+- receipts.dir, with --receipts, except files matching .fintrackignore
+
+The Bend session isn't included; log in again on the new machine. The
+passphrase comes from FINTRACK_BACKUP_PASSPHRASE or a prompt.
+
+Available subcommands:
+- create: Write a backup archive
+- restore: Restore a backup archive`,
+}
+
+// backupCreateCmd writes an archive
+var backupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Write a backup archive",
+	Example: `  fintrack backup create
+  fintrack backup create --output ~/fintrack.ftbackup --receipts
+  fintrack backup create --include-secrets      # Keep refresh tokens and passwords`,
+	Args: cobra.NoArgs,
+	RunE: runBackupCreate,
+}
+
+// backupRestoreCmd restores an archive
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "Restore a backup archive",
+	Long: `Restore a backup archive. The config is written to --config (or
+$FINTRACK_CONFIG, or ~/.config/fintrack/config.yaml) and a project-local
+config back to where it was, then the store, alert state and receipts to the
+paths the restored config points at.
+
+Existing files are never overwritten unless --force is given; --dry-run
+lists what would be written.
+
+Examples:
+  fintrack backup restore fintrack-2026-10-16.ftbackup --dry-run
+  fintrack backup restore fintrack-2026-10-16.ftbackup
+  fintrack --config ./.fintrack/config.yaml backup restore old.ftbackup --force`,
+	Args: cobra.ExactArgs(1),
+	// On a new machine the --config file doesn't exist yet: it comes from the backup
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if path := configFilePath(); path != "" {
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				return nil
+			}
+		}
+		return setupRootCommand(cmd, args)
+	},
+	RunE: runBackupRestore,
+}
+
+var (
+	backupOutput         string
+	backupIncludeSecrets bool
+	backupReceipts       bool
+	backupForce          bool
+)
+
+// localConfigEntry names a project-local config layer in an archive; it is
+// restored to where it was backed up from
+const localConfigEntry = "config.local"
+
+func init() {
+	backupCreateCmd.Flags().StringVarP(&backupOutput, "output", "o", "", "Archive to write (default fintrack-<date>.ftbackup)")
+	backupCreateCmd.Flags().BoolVar(&backupIncludeSecrets, "include-secrets", false, "Keep tokens and passwords in the backed up config")
+	backupCreateCmd.Flags().BoolVar(&backupReceipts, "receipts", false, "Also back up receipts.dir")
+
+	backupRestoreCmd.Flags().BoolVar(&backupForce, "force", false, "Overwrite existing files")
+
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+}
+
+// =============================================================================
+// BACKUP COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runBackupCreate collects the local files into an archive
+func runBackupCreate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+	if cfg.Store.Backend != "" && cfg.Store.Backend != "json" {
+		return fmt.Errorf("backups only support the json store backend, not %s", cfg.Store.Backend)
+	}
+
+	host, _ := os.Hostname()
+	archive := backup.New(time.Now().UTC(), host)
+	archive.Manifest.Secrets = backupIncludeSecrets
+
+	// Every config layer, without secrets by default
+	files := config.ConfigFiles(configFilePath())
+	for _, file := range files {
+		path, err := filepath.Abs(file.Path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", file.Path, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read config: %w", err)
+		}
+		if !backupIncludeSecrets {
+			var removed []string
+			if data, removed, err = backup.StripSecrets(data); err != nil {
+				return err
+			}
+			if len(removed) > 0 && !IsQuiet() {
+				fmt.Printf("🔐 Left out %d secret(s) from %s: %s\n", len(removed), path, strings.Join(removed, ", "))
+			}
+		}
+		name := "config"
+		if file.Source == config.SourceLocal {
+			name = localConfigEntry
+		}
+		if err := archive.Add(name+filepath.Ext(path), backup.RoleConfig, path, data); err != nil {
+			return err
+		}
+	}
+	if len(files) == 0 && !IsQuiet() {
+		fmt.Println("⚠️  No config file found; backing up data files only")
+	}
+
+	// Store and alert state, as they are on disk (an encrypted store stays encrypted)
+	if err := addBackupFile(archive, "store.json", backup.RoleStore, cfg.Store.Path); err != nil {
+		return err
+	}
+	if err := addBackupFile(archive, "alert-state.json", backup.RoleAlertState, cfg.Alerts.StateFile); err != nil {
+		return err
+	}
+
+	if backupReceipts {
+		matcher, err := loadIgnore()
+		if err != nil {
+			return err
+		}
+		ignored := 0
+		err = filepath.WalkDir(cfg.Receipts.Dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && p == cfg.Receipts.Dir {
+					return nil
+				}
+				return err
+			}
+			if p != cfg.Receipts.Dir && matcher.Ignored(p, d.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				ignored++
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(cfg.Receipts.Dir, p)
+			if err != nil {
+				return err
+			}
+			return addBackupFile(archive, path.Join("receipts", filepath.ToSlash(rel)), backup.RoleReceipt, p)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to back up receipts: %w", err)
+		}
+		reportIgnored(matcher, ignored)
+	}
+
+	if len(archive.Manifest.Files) == 0 {
+		return fmt.Errorf("nothing to back up: no config file, store or alert state found")
+	}
+
+	output := backupOutput
+	if output == "" {
+		output = fmt.Sprintf("fintrack-%s.ftbackup", time.Now().Format("2006-01-02"))
+	}
+
+	if IsDryRun() || IsVerbose() {
+		for _, entry := range archive.Manifest.Files {
+			fmt.Printf("  📄 %-24s %8d bytes  %s\n", entry.Name, entry.Size, entry.Source)
+		}
+	}
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would write %d file(s) to %s\n", len(archive.Manifest.Files), output)
+		return nil
+	}
+
+	passphrase, err := backupPassphrase(true)
+	if err != nil {
+		return err
+	}
+	data, err := archive.Encode(passphrase)
+	if err != nil {
+		return err
+	}
+	if err := safefile.Write(output, data, 0600); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✅ Backed up %d file(s) to %s\n", len(archive.Manifest.Files), output)
+	}
+	return nil
+}
+
+// runBackupRestore writes an archive's files back in place
+func runBackupRestore(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	if !backup.IsArchive(data) {
+		return fmt.Errorf("%s is not a fintrack backup", args[0])
+	}
+	passphrase, err := backupPassphrase(false)
+	if err != nil {
+		return err
+	}
+	archive, err := backup.Decode(data, passphrase)
+	if err != nil {
+		return err
+	}
+
+	// The restored config decides where everything else goes; without one
+	// in the backup, the current config does
+	type restore struct {
+		name, target string
+	}
+	var plan []restore
+	var cfg *config.Config
+	if entries := archive.Entries(backup.RoleConfig); len(entries) > 0 {
+		target := configFilePath()
+		if target == "" {
+			if target, err = config.GetConfigFilePath(); err != nil {
+				return err
+			}
+		}
+		var targets []string
+		var layers [][]byte
+		for _, entry := range entries {
+			path := target
+			if strings.HasPrefix(entry.Name, localConfigEntry+".") {
+				path = entry.Source
+			}
+			plan = append(plan, restore{entry.Name, path})
+			targets = append(targets, path)
+			layers = append(layers, archive.File(entry.Name))
+		}
+		if cfg, err = restoredConfig(targets, layers); err != nil {
+			return fmt.Errorf("restored config is invalid: %w", err)
+		}
+	} else if cfg, err = config.GetFromContext(cmd); err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+	for _, entry := range archive.Entries(backup.RoleStore) {
+		plan = append(plan, restore{entry.Name, cfg.Store.Path})
+	}
+	for _, entry := range archive.Entries(backup.RoleAlertState) {
+		plan = append(plan, restore{entry.Name, cfg.Alerts.StateFile})
+	}
+	for _, entry := range archive.Entries(backup.RoleReceipt) {
+		rel := strings.TrimPrefix(entry.Name, "receipts/")
+		plan = append(plan, restore{entry.Name, filepath.Join(cfg.Receipts.Dir, filepath.FromSlash(rel))})
+	}
+
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Backup of %s from %s:\n", archive.Manifest.CreatedAt.Local().Format("2006-01-02 15:04"), archive.Manifest.Host)
+		for _, r := range plan {
+			note := ""
+			if _, err := os.Stat(r.target); err == nil {
+				note = " (exists)"
+			}
+			fmt.Printf("  📄 %s → %s%s\n", r.name, r.target, note)
+		}
+		return nil
+	}
+
+	// Check every target first, so a conflict doesn't leave a partial restore
+	if !backupForce {
+		for _, r := range plan {
+			if _, err := os.Stat(r.target); err == nil {
+				return fmt.Errorf("%s already exists; use --force to overwrite it", r.target)
+			}
+		}
+	}
+	for _, r := range plan {
+		if err := writeRestored(r.target, archive.File(r.name)); err != nil {
+			return err
+		}
+		if IsVerbose() {
+			fmt.Printf("  📄 %s\n", r.target)
+		}
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✅ Restored %d file(s) from the backup of %s\n", len(plan), archive.Manifest.CreatedAt.Local().Format("2006-01-02 15:04"))
+		if len(archive.Entries(backup.RoleConfig)) > 0 && !archive.Manifest.Secrets {
+			fmt.Println("💡 Secrets weren't backed up: run 'fintrack bend login' to sign in again")
+		}
+	}
+	return nil
+}
+
+// addBackupFile adds a file to the archive if it exists
+func addBackupFile(archive *backup.Archive, name, role, path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return archive.Add(name, role, path, data)
+}
+
+// writeRestored writes a restored file, creating its directory
+func writeRestored(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := safefile.Write(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", path, err)
+	}
+	return nil
+}
+
+// restoredConfig loads the backed up config layers before they are written
+// to targets. Paths in each resolve against its temporary copy's directory,
+// so they are moved over to its target's.
+func restoredConfig(targets []string, layers [][]byte) (*config.Config, error) {
+	dir, err := os.MkdirTemp("", "fintrack-restore-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := make([]config.ConfigFile, len(layers))
+	for i, data := range layers {
+		tmp := filepath.Join(dir, strconv.Itoa(i), filepath.Base(targets[i]))
+		if err := os.MkdirAll(filepath.Dir(tmp), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		if err := os.WriteFile(tmp, data, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write temporary config: %w", err)
+		}
+		files[i] = config.ConfigFile{Path: tmp, Source: config.SourceGlobal}
+		if i > 0 {
+			files[i].Source = config.SourceLocal
+		}
+	}
+	cfg, err := config.LoadFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	rebase := func(path string) string {
+		for i := range files {
+			layer := filepath.Dir(files[i].Path)
+			if rel, err := filepath.Rel(layer, path); err == nil && !strings.HasPrefix(rel, "..") {
+				return filepath.Join(filepath.Dir(targets[i]), rel)
+			}
+		}
+		return path
+	}
+	cfg.Store.Path = rebase(cfg.Store.Path)
+	cfg.Alerts.StateFile = rebase(cfg.Alerts.StateFile)
+	return cfg, nil
+}
+
+// backupPassphrase reads the archive passphrase from the environment or a
+// prompt, asking twice when creating one
+func backupPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv("FINTRACK_BACKUP_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	passphrase, err := promptSecret("Backup passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		if len(passphrase) < 8 {
+			return "", fmt.Errorf("passphrase must be at least 8 characters")
+		}
+		repeat, err := promptSecret("Repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if repeat != passphrase {
+			return "", fmt.Errorf("passphrases don't match")
+		}
+	}
+	return passphrase, nil
+}
@@ -19,14 +19,21 @@ var bendCmd = &cobra.Command{
 Available operations:
 - check: Check session status and validity
 - login: Interactive authentication setup with refresh token
+- sessions: List the default and named sessions with their expiry
 - accounts: List all connected bank accounts
+- refresh-accounts: Force Bend to re-pull account data
 - transactions: Fetch transaction data with advanced filtering options
+- statement: Show an account's staged transactions with running balances
+- sync: Fetch every account's transactions in parallel into the local store
 
 Examples:
   fintrack bend check                    # Check if session is valid
   fintrack bend login                    # Set up authentication
+  fintrack bend sessions                 # List sessions and when they expire
   fintrack bend accounts                 # List all accounts
-  fintrack bend transactions --days 7    # Fetch last 7 days of transactions`,
+  fintrack bend refresh-accounts         # Pull fresh account data
+  fintrack bend transactions --days 7    # Fetch last 7 days of transactions
+  fintrack bend sync --all-accounts      # Fetch all accounts in parallel`,
 }
 
 func init() {
@@ -37,6 +44,10 @@ func init() {
 func setupBendSubcommands() {
 	bendCmd.AddCommand(blend.CheckCmd)
 	bendCmd.AddCommand(blend.LoginCmd)
+	bendCmd.AddCommand(blend.SessionsCmd)
 	bendCmd.AddCommand(blend.AccountsCmd)
+	bendCmd.AddCommand(blend.RefreshAccountsCmd)
 	bendCmd.AddCommand(blend.TransactionsCmd)
+	bendCmd.AddCommand(blend.StatementCmd)
+	bendCmd.AddCommand(blend.SyncCmd)
 }
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/alerts"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/report"
+	"github.com/quickkly/fintrack/internal/store"
+	"github.com/quickkly/fintrack/internal/syncreport"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// BILLS COMMAND DEFINITIONS
+// =============================================================================
+
+// billsCmd groups bill and recurring payment commands
+var billsCmd = &cobra.Command{
+	Use:   "bills",
+	Short: "Upcoming bills and recurring payments",
+	Long: `Predict upcoming payments from the local store: credit card bill payments
+and recurring payments detected from history (same merchant, similar amount,
+weekly to yearly cycle).
+
+When bills.channels names alert channels, 'fintrack sync' sends a reminder
+bills.notify_days before each payment is due (once per payment).
+
+Available subcommands:
+- upcoming: List payments expected in the next days`,
+}
+
+// billsUpcomingCmd lists expected payments
+var billsUpcomingCmd = &cobra.Command{
+	Use:   "upcoming",
+	Short: "List payments expected in the next days",
+	Long: `List credit card bills and recurring payments expected within --days,
+with the predicted due date and amount. Card bill amounts are the last
+payment; other amounts are the typical payment.
+
+Examples:
+  fintrack bills upcoming
+  fintrack bills upcoming --days 60 --output json
+  fintrack bills upcoming --notify            # Also send reminders now`,
+	Args: cobra.NoArgs,
+	RunE: runBillsUpcoming,
+}
+
+var (
+	billsDays   int
+	billsNotify bool
+	billsOutput string
+)
+
+func init() {
+	billsUpcomingCmd.Flags().IntVar(&billsDays, "days", 30, "How many days ahead to look")
+	billsUpcomingCmd.Flags().BoolVar(&billsNotify, "notify", false, "Send reminders for payments due within bills.notify_days to bills.channels")
+	billsUpcomingCmd.Flags().StringVarP(&billsOutput, "output", "o", "table", "Output format (table, json; default from display.output)")
+
+	billsCmd.AddCommand(billsUpcomingCmd)
+}
+
+// =============================================================================
+// BILLS COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runBillsUpcoming prints the payments expected within --days
+func runBillsUpcoming(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+	if billsDays <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	now := time.Now()
+	upcoming, err := upcomingBills(cfg, now, now.AddDate(0, 0, billsDays))
+	if err != nil {
+		return err
+	}
+
+	switch format := display.Output(cmd, cfg.Display); format {
+	case "table":
+		if len(upcoming) == 0 {
+			fmt.Printf("📭 No payments expected in the next %d days\n", billsDays)
+			break
+		}
+		f := display.New(cfg.Display)
+		table := f.Table(
+			display.Column{Header: "Due"}, display.Column{Header: "In", Right: true}, display.Column{Header: "Payment"},
+			display.Column{Header: "Kind"}, display.Column{Header: "Cycle"}, display.Column{Header: "Amount", Right: true})
+		for _, bill := range upcoming {
+			table.Row(f.Date(bill.Next), dueIn(bill.Next, now), bill.Name, bill.Kind, bill.Interval, f.Amount(bill.Amount, bill.Currency))
+		}
+		table.Render(os.Stdout)
+
+	case "json":
+		data, err := json.MarshalIndent(upcoming, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal bills to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table or json", format)
+	}
+
+	if billsNotify && !IsDryRun() {
+		sent, errs := remindBills(cfg, now)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		}
+		if !IsQuiet() {
+			fmt.Printf("📨 Sent %d reminder(s)\n", sent)
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("%d reminder delivery error(s)", len(errs))
+		}
+	}
+	return nil
+}
+
+// syncBills sends due bill reminders after a sync, recording failures in the report
+func syncBills(cfg *config.Config, report *syncreport.Report) {
+	if len(cfg.Bills.Channels) == 0 {
+		return
+	}
+	_, errs := remindBills(cfg, time.Now())
+	for _, err := range errs {
+		report.AddError(err)
+	}
+}
+
+// remindBills sends a reminder for every payment due within bills.notify_days
+// that wasn't reminded of yet
+func remindBills(cfg *config.Config, now time.Time) (int, []error) {
+	if len(cfg.Bills.Channels) == 0 {
+		return 0, []error{fmt.Errorf("no bills.channels configured")}
+	}
+
+	due, err := upcomingBills(cfg, now, now.AddDate(0, 0, cfg.Bills.NotifyDays))
+	if err != nil {
+		return 0, []error{err}
+	}
+
+	state, err := alerts.LoadState(cfg.Alerts.StateFile)
+	if err != nil {
+		return 0, []error{err}
+	}
+
+	var reminders []alerts.Alert
+	for _, bill := range due {
+		reminders = append(reminders, alerts.Alert{
+			Rule: "bill:" + bill.Name,
+			Message: fmt.Sprintf("%s of %s %s due %s (%s)", bill.Name, bill.Amount, bill.Currency,
+				bill.Next.Format("2006-01-02"), dueIn(bill.Next, now)),
+			Period:   bill.Next.Format("2006-01-02"),
+			Channels: cfg.Bills.Channels,
+			At:       now,
+		})
+	}
+
+	sent, errs := alerts.Deliver(context.Background(), cfg.Alerts, state, reminders)
+	if err := state.Save(); err != nil {
+		errs = append(errs, err)
+	}
+	return sent, errs
+}
+
+// upcomingBills returns the recurring payments expected between from and to
+func upcomingBills(cfg *config.Config, from, to time.Time) ([]report.Recurring, error) {
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var upcoming []report.Recurring
+	for _, bill := range report.DetectRecurring(st.Transactions(), from, cfg.RoundingMode()) {
+		if !bill.Next.After(to) {
+			upcoming = append(upcoming, bill)
+		}
+	}
+	return upcoming, nil
+}
+
+// dueIn describes how far away a due date is
+func dueIn(due, now time.Time) string {
+	days := int(due.Sub(now).Hours() / 24)
+	switch {
+	case days < 0:
+		return "overdue"
+	case days == 0:
+		return "today"
+	case days == 1:
+		return "1 day"
+	default:
+		return strconv.Itoa(days) + " days"
+	}
+}
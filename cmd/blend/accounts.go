@@ -3,10 +3,20 @@ package blend
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
+	"github.com/quickkly/fintrack/cmd/console"
 	"github.com/quickkly/fintrack/internal/blend"
 	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/exitcode"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/report"
+	"github.com/quickkly/fintrack/internal/store"
 
 	"github.com/spf13/cobra"
 )
@@ -19,10 +29,77 @@ Shows account details including balances, bank information, and recent activity.
 	RunE: runAccounts,
 }
 
-var output string
+var (
+	output          string
+	accountsEntity  string
+	accountsGroups  []string
+	accountsColumns string
+	accountsFormat  string
+
+	accountsWithStats bool
+	accountsStatsDays int
+
+	accountsShowRaw bool
+)
+
+// accountFields are the table columns --columns can pick from
+var accountFields = []display.Field{
+	{Name: "id", Column: display.Column{Header: "ID"}},
+	{Name: "name", Column: display.Column{Header: "Name"}},
+	{Name: "nickname", Column: display.Column{Header: "Nickname"}},
+	{Name: "group", Column: display.Column{Header: "Group"}},
+	{Name: "holder", Column: display.Column{Header: "Holder Name"}},
+	{Name: "bank", Column: display.Column{Header: "Bank"}},
+	{Name: "type", Column: display.Column{Header: "Type"}},
+	{Name: "number", Column: display.Column{Header: "Account No"}},
+	{Name: "ifsc", Column: display.Column{Header: "IFSC"}},
+	{Name: "balance", Column: display.Column{Header: "Balance", Right: true}},
+	{Name: "currency", Column: display.Column{Header: "Currency"}},
+	{Name: "updated", Column: display.Column{Header: "Last Updated"}},
+	{Name: "consent", Column: display.Column{Header: "Consent", Right: true}},
+	{Name: "inflow", Column: display.Column{Header: "In", Right: true}},
+	{Name: "outflow", Column: display.Column{Header: "Out", Right: true}},
+	{Name: "txns", Column: display.Column{Header: "Txns", Right: true}},
+	{Name: "last_txn", Column: display.Column{Header: "Last Txn"}},
+}
+
+// defaultAccountColumns are the columns shown without --columns
+var defaultAccountColumns = []string{"id", "holder", "bank", "type", "balance", "updated", "consent"}
+
+// statsAccountColumns are the columns --with-stats adds, computed from the
+// local store
+var statsAccountColumns = []string{"inflow", "outflow", "txns", "last_txn"}
+
+// accountRow is an account with its recent activity, for JSON output and
+// --format templates under --with-stats
+type accountRow struct {
+	blend.Account
+	Stats *report.AccountActivity `json:"stats"`
+}
+
+// MarshalJSON encodes the account and adds the stats; the embedded
+// MarshalJSON alone would drop them
+func (r accountRow) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(r.Account)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := json.Marshal(r.Stats)
+	if err != nil {
+		return nil, err
+	}
+	return blend.MergeJSONFields(data, map[string]json.RawMessage{"stats": stats})
+}
 
 func init() {
-	AccountsCmd.Flags().StringVarP(&output, "output", "o", "table", "Output format (table, json, csv)")
+	AccountsCmd.Flags().StringVarP(&output, "output", "o", "table", "Output format (table, json, csv; default from display.output)")
+	AccountsCmd.Flags().StringVar(&accountsEntity, "entity", "", "Only list accounts belonging to this entity")
+	AccountsCmd.Flags().StringSliceVar(&accountsGroups, "group", nil, "Only list accounts in these groups (repeatable)")
+	AccountsCmd.Flags().StringVar(&accountsColumns, "columns", "", "Table columns to show, e.g. id,bank,balance (available: "+fieldNames(accountFields)+")")
+	AccountsCmd.Flags().StringVar(&accountsFormat, "format", "", "Print each account with a Go template, e.g. '{{.UUID}}\\t{{.CurrentBalance}}'")
+	AccountsCmd.Flags().BoolVar(&accountsWithStats, "with-stats", false, "Add each account's inflow, outflow and transaction count from the local store")
+	AccountsCmd.Flags().IntVar(&accountsStatsDays, "stats-days", 30, "Days --with-stats covers")
+	AccountsCmd.Flags().BoolVar(&accountsShowRaw, "show-raw", false, "Also list, on stderr, the fields Bend sent that fintrack doesn't know yet")
 }
 
 func runAccounts(cmd *cobra.Command, args []string) error {
@@ -54,29 +131,109 @@ func runAccounts(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("session expired. Run 'fintrack bend check' to refresh or 'fintrack bend login' to re-authenticate")
 	}
 
-	fmt.Println("🔄 Fetching accounts...")
+	f := display.New(cfg.Display)
+	format := display.Output(cmd, cfg.Display)
+	defaults := defaultAccountColumns
+	if accountsWithStats {
+		defaults = append(append([]string(nil), defaults...), statsAccountColumns...)
+	}
+	fields, err := display.PickFields(accountFields, defaults, accountsColumns)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Usage, err)
+	}
+	if accountsStatsDays < 1 {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--stats-days must be positive"))
+	}
+	for _, field := range fields {
+		if slices.Contains(statsAccountColumns, field.Name) && !accountsWithStats {
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("the %s column needs --with-stats", field.Name))
+		}
+	}
+	if accountsColumns != "" && (format != "table" || accountsFormat != "") {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--columns only applies to table output"))
+	}
+	var tmpl *template.Template
+	if accountsFormat != "" {
+		if tmpl, err = f.Template(accountsFormat, cfg.RoundingMode()); err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+	}
+
+	out := console.New(cmd)
+	// Templated lines are for scripts too; keep stdout to them
+	out.Machine = out.Machine || tmpl != nil
+	out.Statusf("🔄 Fetching accounts...\n")
 
 	// Create client and get accounts
 	client := blend.NewClient(cfg)
-	client.SetSession(session)
+	if err := client.UseSession(session); err != nil {
+		return err
+	}
 
 	accounts, err := client.GetAccounts()
 	if err != nil {
 		return fmt.Errorf("failed to fetch accounts: %w", err)
 	}
 
+	if accountsEntity != "" {
+		if err := cfg.ValidateEntity(accountsEntity); err != nil {
+			return err
+		}
+		accounts = filterAccountsByEntity(cfg, accounts, accountsEntity)
+	}
+	if len(accountsGroups) > 0 {
+		inGroups, err := cfg.AccountFilter(accountsGroups)
+		if err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+		accounts = filterAccounts(accounts, inGroups)
+	}
+
 	if len(accounts) == 0 {
-		fmt.Println("📭 No accounts found")
+		out.Statusf("📭 No accounts found\n")
 		return nil
 	}
 
-	fmt.Printf("\n📋 Found %d account(s):\n\n", len(accounts))
+	if accountsShowRaw {
+		ids := make([]string, len(accounts))
+		extras := make([]map[string]json.RawMessage, len(accounts))
+		for i, account := range accounts {
+			ids[i], extras[i] = account.UUID, account.RawExtra
+		}
+		printRawExtra(out.Stderr(), "account", ids, extras)
+	}
+
+	var activity map[string]*report.AccountActivity
+	since := time.Now().AddDate(0, 0, -accountsStatsDays)
+	if accountsWithStats {
+		if activity, err = accountActivity(cfg, since); err != nil {
+			return err
+		}
+	}
+	rows := make([]interface{}, len(accounts))
+	for i, account := range accounts {
+		rows[i] = account
+		if activity != nil {
+			rows[i] = accountRow{Account: account, Stats: activityOf(activity, account.UUID)}
+		}
+	}
+
+	out.Statusf("\n📋 Found %d account(s):\n\n", len(accounts))
+
+	if tmpl != nil {
+		for _, row := range rows {
+			if err := display.ExecuteLine(out.Stdout(), tmpl, row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 
-	switch output {
+	switch format {
 	case "table":
-		fmt.Printf("%-36s | %-33s | %-19s | %-7s | %12s | %-16s\n",
-			"ID", "Holder Name", "Bank", "Type", "Balance", "Last Updated")
-		fmt.Printf("-------------------------------------+-----------------------------------+---------------------+---------+--------------+------------------\n")
+		table := f.Table(display.Columns(fields)...)
+		now := time.Now()
+		var stale []string
 		for _, account := range accounts {
 			bankName := account.FinancialInformationProvider.Name
 			if len(bankName) > 19 {
@@ -88,40 +245,185 @@ func runAccounts(cmd *cobra.Command, args []string) error {
 				holderName = holderName[:30] + "..."
 			}
 
-			lastUpdate := account.LastFetchedAt.Format("2006-01-02 15:04")
+			lastUpdated := f.DateTime(account.LastFetchedAt)
+			if reason := account.StaleReason(now, cfg.Sync.StaleAfter); reason != "" {
+				lastUpdated = "⚠️ " + lastUpdated
+				stale = append(stale, fmt.Sprintf("%s: %s", account.DisplayName(), reason))
+			}
+
+			values := map[string]string{
+				"id":       account.UUID,
+				"name":     account.DisplayName(),
+				"nickname": cfg.Accounts.Settings[account.UUID].Nickname,
+				"group":    cfg.AccountGroup(account.UUID),
+				"holder":   holderName,
+				"bank":     bankName,
+				"type":     account.Type,
+				"number":   account.MaskedAccountNumber,
+				"ifsc":     account.IFSCCode,
+				"balance":  f.Amount(money.FromFloat(account.CurrentBalance, cfg.RoundingMode()), account.Currency),
+				"currency": account.Currency,
+				"updated":  lastUpdated,
+				"consent":  consentLeft(cfg, account, now),
+			}
+			for name, value := range activityCells(f, activity, account) {
+				values[name] = value
+			}
+			table.Row(display.Cells(fields, values)...)
+		}
+		table.Render(out.Stdout())
 
-			fmt.Printf("%-36s | %-33s | %-19s | %-7s | %10.2f %s | %s\n",
-				account.UUID, holderName, bankName, account.Type,
-				account.CurrentBalance, account.Currency, lastUpdate)
+		if activity != nil {
+			var dormant []string
+			for _, account := range accounts {
+				if activityOf(activity, account.UUID).Dormant() {
+					dormant = append(dormant, account.DisplayName())
+				}
+			}
+			if len(dormant) > 0 {
+				out.Statusf("\n💤 No transactions in the last %d day(s): %s\n", accountsStatsDays, strings.Join(dormant, ", "))
+			}
+		}
+
+		if len(stale) > 0 {
+			out.Warnf("\n⚠️  STALE DATA: these balances are the last ones Bend got, not current:\n")
+			for _, line := range stale {
+				out.Warnf("   • %s\n", line)
+			}
 		}
 
 	case "json":
-		jsonData, err := json.MarshalIndent(accounts, "", "  ")
+		jsonData, err := json.MarshalIndent(rows, "", "  ")
 		if err != nil {
 			return fmt.Errorf("failed to marshal accounts to JSON: %w", err)
 		}
-		fmt.Println(string(jsonData))
+		out.Resultf("%s\n", jsonData)
 
 	case "csv":
-		fmt.Printf("ID,HolderName,Bank,Type,Balance,Currency,MaskedAccount,IFSC,LastUpdate\n")
+		header := "ID,HolderName,Bank,Type,Balance,Currency,MaskedAccount,IFSC,LastUpdate,ConsentExpires"
+		if activity != nil {
+			header += ",Inflow,Outflow,Transactions,LastTransaction"
+		}
+		out.Resultf("%s\n", header)
 		for _, account := range accounts {
 			lastUpdate := account.LastFetchedAt.Format("2006-01-02T15:04:05Z")
+			consentExpires := ""
+			if expiry := blend.ConsentExpiry(cfg, account); !expiry.IsZero() {
+				consentExpires = expiry.Format("2006-01-02")
+			}
 
 			// Escape CSV fields if they contain commas
 			holderName := strings.ReplaceAll(account.HolderName, ",", ";")
 			bankName := strings.ReplaceAll(account.FinancialInformationProvider.Name, ",", ";")
 
-			fmt.Printf("%s,%s,%s,%s,%.2f,%s,%s,%s,%s\n",
+			out.Resultf("%s,%s,%s,%s,%.2f,%s,%s,%s,%s,%s",
 				account.UUID, holderName, bankName,
 				account.Type, account.CurrentBalance, account.Currency,
-				account.MaskedAccountNumber, account.IFSCCode, lastUpdate)
+				account.MaskedAccountNumber, account.IFSCCode, lastUpdate, consentExpires)
+			if activity != nil {
+				stats, last := activityOf(activity, account.UUID), ""
+				if stats.Last != nil {
+					last = stats.Last.Local().Format("2006-01-02")
+				}
+				out.Resultf(",%.2f,%.2f,%d,%s", stats.InflowAmount, stats.OutflowAmount, stats.Count, last)
+			}
+			out.Resultf("\n")
 		}
 
 	default:
-		return fmt.Errorf("unsupported output format: %s. Use table, json, or csv", output)
+		return fmt.Errorf("unsupported output format: %s. Use table, json, or csv", format)
 	}
 
-	fmt.Printf("\n💡 Use account ID with 'fintrack bend transactions --account-id <UUID>' to fetch transactions\n")
+	out.Statusf("\n💡 Use account ID with 'fintrack bend transactions --account-id <UUID>' to fetch transactions\n")
 
 	return nil
 }
+
+// accountActivity reads each account's activity since since from the local
+// store
+func accountActivity(cfg *config.Config, since time.Time) (map[string]*report.AccountActivity, error) {
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return nil, err
+	}
+	return report.Activity(st.Transactions(), since, cfg.RoundingMode()), nil
+}
+
+// activityOf is an account's activity, empty when the store has none for it
+func activityOf(activity map[string]*report.AccountActivity, accountID string) *report.AccountActivity {
+	if a, ok := activity[accountID]; ok {
+		return a
+	}
+	return &report.AccountActivity{AccountID: accountID}
+}
+
+// activityCells adds the --with-stats cells to an account's table row
+func activityCells(f *display.Formatter, activity map[string]*report.AccountActivity, account blend.Account) map[string]string {
+	if activity == nil {
+		return nil
+	}
+	stats := activityOf(activity, account.UUID)
+	last, txns := "-", strconv.Itoa(stats.Count)
+	if stats.Last != nil {
+		last = f.Date(*stats.Last)
+	}
+	if stats.Dormant() {
+		txns = "💤 0"
+	}
+	return map[string]string{
+		"inflow":   f.Amount(stats.Inflow, account.Currency),
+		"outflow":  f.Amount(stats.Outflow, account.Currency),
+		"txns":     txns,
+		"last_txn": last,
+	}
+}
+
+// fieldNames lists the names --columns accepts
+func fieldNames(fields []display.Field) string {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// filterAccountsByEntity keeps only accounts assigned to the given entity
+func filterAccountsByEntity(cfg *config.Config, accounts []blend.Account, entity string) []blend.Account {
+	name := strings.ToLower(entity)
+	return filterAccounts(accounts, func(accountID string) bool {
+		return cfg.EntityForAccount(accountID) == name
+	})
+}
+
+// filterAccounts keeps the accounts keep returns true for
+func filterAccounts(accounts []blend.Account, keep func(accountID string) bool) []blend.Account {
+	filtered := make([]blend.Account, 0, len(accounts))
+	for _, account := range accounts {
+		if keep(account.UUID) {
+			filtered = append(filtered, account)
+		}
+	}
+	return filtered
+}
+
+// consentLeft is the days left on the account's aggregator consent, flagged
+// once it is within consent.notify_days
+func consentLeft(cfg *config.Config, account blend.Account, now time.Time) string {
+	if account.Consent != nil && blend.ConsentInactive(account.Consent.Status) {
+		return "⚠️ " + strings.ToLower(account.Consent.Status)
+	}
+	expiry := blend.ConsentExpiry(cfg, account)
+	if expiry.IsZero() {
+		return "-"
+	}
+
+	days := blend.ConsentDaysLeft(expiry, now)
+	switch {
+	case days < 0:
+		return "⚠️ expired"
+	case days <= cfg.Consent.NotifyDays:
+		return fmt.Sprintf("⚠️ %d days", days)
+	default:
+		return fmt.Sprintf("%d days", days)
+	}
+}
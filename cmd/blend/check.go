@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/quickkly/fintrack/cmd/console"
 	"github.com/quickkly/fintrack/internal/blend"
 	"github.com/quickkly/fintrack/internal/config"
 
@@ -37,13 +38,14 @@ func runCheck(cmd *cobra.Command) error {
 	}
 
 	// Display session status
-	fmt.Println("Bend Session Status:")
-	fmt.Println("=========================")
+	out := console.New(cmd)
+	out.Statusf("Bend Session Status:\n")
+	out.Statusf("=========================\n")
 
 	if !sessionInfo.Exists {
-		fmt.Println("❌ No session file found")
-		fmt.Println("🔄 Attempting to authenticate using configuration...")
-		if err := authenticateFromConfig(cfg, sessionManager); err != nil {
+		out.Statusf("❌ No session file found\n")
+		out.Statusf("🔄 Attempting to authenticate using configuration...\n")
+		if err := authenticateFromConfig(out, cfg, sessionManager); err != nil {
 			return err
 		}
 		// Reload session info
@@ -53,21 +55,21 @@ func runCheck(cmd *cobra.Command) error {
 		}
 	}
 
-	fmt.Printf("📁 Session file: %s\n", cfg.Bend.SessionFile)
+	out.Statusf("📁 Session file: %s\n", cfg.Bend.SessionFile)
 
 	if !sessionInfo.Valid {
-		fmt.Println("❌ Session expired or invalid")
+		out.Statusf("❌ Session expired or invalid\n")
 		if sessionInfo.HasRefreshToken {
-			fmt.Println("💡 Trying to refresh session...")
-			if err := refreshSession(cfg, sessionManager); err != nil {
-				fmt.Println("⚠️ Refresh failed, attempting fallback to config...")
-				if err := authenticateFromConfig(cfg, sessionManager); err != nil {
+			out.Statusf("💡 Trying to refresh session...\n")
+			if err := refreshSession(out, cfg, sessionManager); err != nil {
+				out.Warnf("⚠️ Refresh failed, attempting fallback to config...\n")
+				if err := authenticateFromConfig(out, cfg, sessionManager); err != nil {
 					return err
 				}
 			}
 		} else {
-			fmt.Println("🔄 Attempting to authenticate using configuration...")
-			if err := authenticateFromConfig(cfg, sessionManager); err != nil {
+			out.Statusf("🔄 Attempting to authenticate using configuration...\n")
+			if err := authenticateFromConfig(out, cfg, sessionManager); err != nil {
 				return err
 			}
 		}
@@ -78,16 +80,16 @@ func runCheck(cmd *cobra.Command) error {
 		}
 	}
 
-	fmt.Println("✅ Session is valid")
-	fmt.Printf("⏰ Expires: %s\n", sessionInfo.ExpiresAt.Format("2006-01-02 15:04:05"))
-	fmt.Printf("⏳ Time remaining: %s\n", sessionInfo.TimeRemaining.Round(time.Minute))
+	out.Statusf("✅ Session is valid\n")
+	out.Statusf("⏰ Expires: %s\n", sessionInfo.ExpiresAt.Format("2006-01-02 15:04:05"))
+	out.Statusf("⏳ Time remaining: %s\n", sessionInfo.TimeRemaining.Round(time.Minute))
 
 	if sessionInfo.HasRefreshToken {
-		fmt.Println("🔄 Refresh token available")
+		out.Statusf("🔄 Refresh token available\n")
 	}
 
 	// Test API connection
-	fmt.Println("\nTesting API connection...")
+	out.Statusf("\nTesting API connection...\n")
 	client := blend.NewClient(cfg)
 
 	session, err := sessionManager.LoadSession()
@@ -95,59 +97,62 @@ func runCheck(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to load session: %w", err)
 	}
 
-	client.SetSession(session)
+	if err := client.UseSession(session); err != nil {
+		out.Warnf("❌ %v\n", err)
+		return err
+	}
 
 	userInfo, err := client.CheckSession()
 	if err != nil {
-		fmt.Printf("❌ API test failed: %v\n", err)
+		out.Warnf("❌ API test failed: %v\n", err)
 		if sessionInfo.HasRefreshToken {
-			fmt.Println("💡 Trying to refresh session...")
-			if err := refreshSession(cfg, sessionManager); err != nil {
-				fmt.Println("⚠️ Refresh failed, attempting fallback to config...")
-				return authenticateFromConfig(cfg, sessionManager)
+			out.Statusf("💡 Trying to refresh session...\n")
+			if err := refreshSession(out, cfg, sessionManager); err != nil {
+				out.Warnf("⚠️ Refresh failed, attempting fallback to config...\n")
+				return authenticateFromConfig(out, cfg, sessionManager)
 			}
 			return nil
 		}
 		
-		fmt.Println("🔄 Attempting to authenticate using configuration...")
-		return authenticateFromConfig(cfg, sessionManager)
+		out.Statusf("🔄 Attempting to authenticate using configuration...\n")
+		return authenticateFromConfig(out, cfg, sessionManager)
 	}
 
-	fmt.Println("✅ API connection successful")
-	fmt.Printf("👤 User: %s (%s)\n", userInfo.GetFullName(), userInfo.Email)
-	fmt.Printf("🆔 ID: %s\n", userInfo.UUID)
-	fmt.Printf("📱 Phone: %s\n", userInfo.Phone)
-	fmt.Printf("🌍 Timezone: %s\n", userInfo.Timezone)
-	fmt.Printf("👑 Role: %s\n", userInfo.Role)
+	out.Statusf("✅ API connection successful\n")
+	out.Statusf("👤 User: %s (%s)\n", userInfo.GetFullName(), userInfo.Email)
+	out.Statusf("🆔 ID: %s\n", userInfo.UUID)
+	out.Statusf("📱 Phone: %s\n", userInfo.Phone)
+	out.Statusf("🌍 Timezone: %s\n", userInfo.Timezone)
+	out.Statusf("👑 Role: %s\n", userInfo.Role)
 
 	if userInfo.EmailVerified {
-		fmt.Println("✅ Email verified")
+		out.Statusf("✅ Email verified\n")
 	} else {
-		fmt.Println("⚠️  Email not verified")
+		out.Statusf("⚠️  Email not verified\n")
 	}
 
 	if userInfo.PhoneVerified {
-		fmt.Println("✅ Phone verified")
+		out.Statusf("✅ Phone verified\n")
 	} else {
-		fmt.Println("⚠️  Phone not verified")
+		out.Statusf("⚠️  Phone not verified\n")
 	}
 
 	if userInfo.BetaAccess {
-		fmt.Println("🧪 Beta access enabled")
+		out.Statusf("🧪 Beta access enabled\n")
 	}
 
 	if userInfo.GoogleLinked {
-		fmt.Println("🔗 Google account linked")
+		out.Statusf("🔗 Google account linked\n")
 	}
 
 	if userInfo.AppleLinked {
-		fmt.Println("🍎 Apple account linked")
+		out.Statusf("🍎 Apple account linked\n")
 	}
 
 	return nil
 }
 
-func refreshSession(cfg *config.Config, sessionManager *blend.SessionManager) error {
+func refreshSession(out *console.Printer, cfg *config.Config, sessionManager *blend.SessionManager) error {
 	client := blend.NewClient(cfg)
 
 	session, err := sessionManager.LoadSession()
@@ -155,55 +160,75 @@ func refreshSession(cfg *config.Config, sessionManager *blend.SessionManager) er
 		return fmt.Errorf("failed to load session: %w", err)
 	}
 
-	client.SetSession(session)
-
-	if err := client.RefreshSession(); err != nil {
-		fmt.Printf("❌ Session refresh failed: %v\n", err)
-		fmt.Println("Run 'fintrack bend login' to re-authenticate")
+	// Refresh under the session lock; the updated session is saved with it
+	session, shared, err := sessionManager.Refresh(session, func(current *blend.Session) (*blend.Session, error) {
+		if err := client.UseSession(current); err != nil {
+			return nil, err
+		}
+		if err := client.RefreshSession(); err != nil {
+			return nil, err
+		}
+		return client.GetSession(), nil
+	})
+	if err != nil {
+		out.Warnf("❌ Session refresh failed: %v\n", err)
+		out.Warnf("Run 'fintrack bend login' to re-authenticate\n")
 		return err
 	}
+	client.SetSession(session)
 
-	// Save updated session
-	if err := sessionManager.SaveSession(client.GetSession()); err != nil {
-		return fmt.Errorf("failed to save refreshed session: %w", err)
+	if shared {
+		out.Statusf("✅ Session was refreshed by another fintrack run\n")
+	} else {
+		out.Statusf("✅ Session refreshed successfully\n")
 	}
 
-	fmt.Println("✅ Session refreshed successfully")
-
 	// Test the refreshed session
 	userInfo, err := client.CheckSession()
 	if err != nil {
 		return fmt.Errorf("refreshed session test failed: %w", err)
 	}
 
-	fmt.Printf("👤 User: %s (%s)\n", userInfo.GetFullName(), userInfo.Email)
+	out.Statusf("👤 User: %s (%s)\n", userInfo.GetFullName(), userInfo.Email)
 	return nil
 }
 
-func authenticateFromConfig(cfg *config.Config, sessionManager *blend.SessionManager) error {
+func authenticateFromConfig(out *console.Printer, cfg *config.Config, sessionManager *blend.SessionManager) error {
+	if cfg.Session != "" {
+		return fmt.Errorf("session '%s' can't be created from the configured refresh token, which belongs to the default session. Run 'fintrack --session %s bend login'", cfg.Session, cfg.Session)
+	}
 	if cfg.Bend.RefreshToken == "" {
 		return fmt.Errorf("no refresh token in configuration, cannot authenticate")
 	}
 
 	client := blend.NewClient(cfg)
-	fmt.Println("🔄 Initializing session from configuration refresh token...")
+	out.Statusf("🔄 Initializing session from configuration refresh token...\n")
 
-	if err := client.InitializeFromRefreshToken(cfg.Bend.RefreshToken); err != nil {
-		return fmt.Errorf("failed to initialize from config token: %w", err)
+	// The session on disk, if any, is the one that didn't work
+	stale, _ := sessionManager.LoadSession()
+	session, shared, err := sessionManager.Refresh(stale, func(*blend.Session) (*blend.Session, error) {
+		if err := client.InitializeFromRefreshToken(cfg.Bend.RefreshToken); err != nil {
+			return nil, fmt.Errorf("failed to initialize from config token: %w", err)
+		}
+		return client.GetSession(), nil
+	})
+	if err != nil {
+		return err
 	}
+	client.SetSession(session)
 
-	if err := sessionManager.SaveSession(client.GetSession()); err != nil {
-		return fmt.Errorf("failed to save new session: %w", err)
+	if shared {
+		out.Statusf("✅ Using the session another fintrack run just created\n")
+	} else {
+		out.Statusf("✅ Authenticated successfully from configuration\n")
 	}
 
-	fmt.Println("✅ Authenticated successfully from configuration")
-
 	// Test the new session
 	userInfo, err := client.CheckSession()
 	if err != nil {
 		return fmt.Errorf("new session test failed: %w", err)
 	}
-	fmt.Printf("👤 User: %s (%s)\n", userInfo.GetFullName(), userInfo.Email)
+	out.Statusf("👤 User: %s (%s)\n", userInfo.GetFullName(), userInfo.Email)
 
 	return nil
 }
@@ -0,0 +1,56 @@
+package blend
+
+import (
+	"github.com/quickkly/fintrack/cmd/console"
+	"github.com/quickkly/fintrack/internal/staging"
+)
+
+// fetchCheckpoint saves --fetch-all progress to the staging directory after
+// every page so an interrupted run can continue with --resume
+type fetchCheckpoint struct {
+	path  string
+	state *staging.Checkpoint
+}
+
+// pendingCheckpoint is the checkpoint of a completed fetch whose results have
+// not been saved yet; it is removed once the staging file is written
+var pendingCheckpoint *fetchCheckpoint
+
+// openFetchCheckpoint prepares the checkpoint for a fetch identified by params.
+// With --resume a previously saved checkpoint is loaded; otherwise the fetch
+// starts from the first page and overwrites any stale checkpoint.
+func openFetchCheckpoint(out *console.Printer, stagingDir string, params ...interface{}) (*fetchCheckpoint, error) {
+	key := staging.CheckpointKey(params...)
+	checkpoint := &fetchCheckpoint{
+		path:  staging.CheckpointPath(stagingDir, key),
+		state: &staging.Checkpoint{Key: key, Page: 1},
+	}
+
+	if !resume {
+		return checkpoint, nil
+	}
+
+	saved, err := staging.LoadCheckpoint(checkpoint.path)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil || saved.Key != key {
+		out.Statusf("ℹ️  No checkpoint found for this query, starting from the first page\n")
+		return checkpoint, nil
+	}
+
+	out.Statusf("♻️  Resuming from page %d (%d transactions already fetched, checkpoint from %s)\n",
+		saved.Page, saved.Fetched, saved.UpdatedAt.Format("2006-01-02 15:04:05"))
+	checkpoint.state = saved
+	return checkpoint, nil
+}
+
+// Save persists the current state
+func (c *fetchCheckpoint) Save() error {
+	return staging.SaveCheckpoint(c.path, c.state)
+}
+
+// Remove deletes the checkpoint file
+func (c *fetchCheckpoint) Remove() error {
+	return staging.RemoveCheckpoint(c.path)
+}
@@ -0,0 +1,122 @@
+package blend
+
+import (
+	"fmt"
+	"text/template"
+
+	"github.com/quickkly/fintrack/cmd/console"
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/exitcode"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// transactionFields are the table columns --columns can pick from
+var transactionFields = []display.Field{
+	{Name: "date", Column: display.Column{Header: "Date"}},
+	{Name: "amount", Column: display.Column{Header: "Amount", Right: true}},
+	{Name: "currency", Column: display.Column{Header: "Currency"}},
+	{Name: "type", Column: display.Column{Header: "Type"}},
+	{Name: "merchant", Column: display.Column{Header: "Merchant"}},
+	{Name: "category", Column: display.Column{Header: "Category"}},
+	{Name: "subcategory", Column: display.Column{Header: "Subcategory"}},
+	{Name: "narration", Column: display.Column{Header: "Narration"}},
+	{Name: "mode", Column: display.Column{Header: "Mode"}},
+	{Name: "account", Column: display.Column{Header: "Account"}},
+	{Name: "id", Column: display.Column{Header: "ID"}},
+	{Name: "reference", Column: display.Column{Header: "Reference"}},
+	{Name: "fingerprint", Column: display.Column{Header: "Fingerprint"}},
+}
+
+// transactionListing prints fetched transactions for --columns or --format
+type transactionListing struct {
+	out    *console.Printer
+	cfg    *config.Config
+	f      *display.Formatter
+	mode   money.RoundingMode
+	fields []display.Field
+	tmpl   *template.Template
+}
+
+// newTransactionListing returns the listing the flags ask for, or nil when
+// neither --columns nor --format is given
+func newTransactionListing(out *console.Printer, cfg *config.Config, columns, format string) (*transactionListing, error) {
+	if columns == "" && format == "" {
+		return nil, nil
+	}
+	if columns != "" && format != "" {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("--columns and --format can't be combined"))
+	}
+
+	listing := &transactionListing{out: out, cfg: cfg, f: display.New(cfg.Display), mode: cfg.RoundingMode()}
+	var err error
+	if format != "" {
+		listing.tmpl, err = listing.f.Template(format, listing.mode)
+	} else {
+		listing.fields, err = display.PickFields(transactionFields, nil, columns)
+	}
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.Usage, err)
+	}
+	return listing, nil
+}
+
+// Print writes the transactions to stdout
+func (l *transactionListing) Print(transactions []blend.Transaction) error {
+	if l == nil {
+		return nil
+	}
+
+	if l.tmpl != nil {
+		for _, txn := range transactions {
+			if err := display.ExecuteLine(l.out.Stdout(), l.tmpl, txn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	table := l.f.Table(display.Columns(l.fields)...)
+	for _, txn := range transactions {
+		table.Row(display.Cells(l.fields, l.values(txn))...)
+	}
+	table.Render(l.out.Stdout())
+	return nil
+}
+
+// values are a transaction's cells, keyed by field name
+func (l *transactionListing) values(txn blend.Transaction) map[string]string {
+	merchant, category, subcategory := "", "", ""
+	if txn.Merchant != nil && txn.Merchant.Name != nil {
+		merchant = *txn.Merchant.Name
+	}
+	if txn.Category != nil {
+		if txn.Category.ID != nil {
+			category = *txn.Category.ID
+		}
+		if txn.Category.SubcategoryID != nil {
+			subcategory = *txn.Category.SubcategoryID
+		}
+	}
+	narration := txn.Narration
+	if len(narration) > 40 {
+		narration = narration[:37] + "..."
+	}
+
+	return map[string]string{
+		"date":        l.f.DateTime(txn.TxnTimestamp),
+		"amount":      l.f.Amount(money.FromFloat(txn.SignedAmount(), l.mode), txn.Currency),
+		"currency":    txn.Currency,
+		"type":        txn.Type,
+		"merchant":    merchant,
+		"category":    category,
+		"subcategory": subcategory,
+		"narration":   narration,
+		"mode":        txn.Mode,
+		"account":     l.cfg.AccountName(txn.AccountID),
+		"id":          txn.UUID,
+		"reference":   txn.Reference,
+		"fingerprint": txn.Fingerprint(),
+	}
+}
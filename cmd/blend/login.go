@@ -4,10 +4,16 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/quickkly/fintrack/cmd/console"
 	"github.com/quickkly/fintrack/internal/blend"
 	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/exitcode"
+	"github.com/quickkly/fintrack/internal/redact"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -20,12 +26,25 @@ var LoginCmd = &cobra.Command{
 	Long: `Authenticate with Bend financial service using either:
 1. Refresh token (from config file)
 2. OTP-based authentication (interactive)
+3. Email and password, with the account's two-factor code if it has 2FA
 
 OTP Mode:
   Use --otp-mode or --phone to enable OTP-based authentication.
   This will send an OTP to your phone number and prompt you to enter it.
-  After successful verification, it will automatically update your config
-  with device_hash and refresh_token, then initialize the session.
+  After successful verification, it will automatically update your global
+  config with device_hash and refresh_token, then initialize the session.
+  A project-local config only gets them if it already sets them.
+
+  --channel picks how the OTP is sent: sms (default), whatsapp or voice.
+  A wrong code can be re-entered up to --otp-attempts times. Type 'resend'
+  at the prompt to have it sent again, or 'resend voice' to switch channel;
+  resends are allowed once every 30 seconds.
+
+Password Mode:
+  Use --email to log in with a password, which is prompted for without
+  echo. If the account has two-factor authentication, the code sent to it
+  is prompted for next (or taken from --otp), with --otp-attempts tries.
+  Like OTP mode, it saves device_hash and refresh_token to your global config.
 
 Refresh Token Mode:
   If a refresh_token is already configured, it will be used automatically.
@@ -36,22 +55,43 @@ Refresh Token Mode:
 }
 
 var (
-	email    string
-	password string
-	phone    string
-	otp      string
-	useOTP   bool
+	email       string
+	password    string
+	phone       string
+	otp         string
+	useOTP      bool
+	otpChannel  string
+	otpAttempts int
 )
 
+// loginInput reads every login prompt, so answers piped in together aren't
+// lost to separate buffers
+var loginInput = bufio.NewReader(os.Stdin)
+
+// ReadLine reads one line from the login prompts' input. Commands that prompt
+// before running a login (init --interactive) read through it too.
+func ReadLine() (string, error) {
+	return loginInput.ReadString('\n')
+}
+
+// otpResendCooldown is how long to wait between OTP sends
+const otpResendCooldown = 30 * time.Second
+
+// otpMaxResends is how many times one login may resend the OTP
+const otpMaxResends = 3
+
 func init() {
-	LoginCmd.Flags().StringVar(&email, "email", "", "Email address for authentication (legacy)")
-	LoginCmd.Flags().StringVar(&password, "password", "", "Password (not recommended, use interactive mode)")
+	LoginCmd.Flags().StringVar(&email, "email", "", "Email address for password login")
+	LoginCmd.Flags().StringVar(&password, "password", "", "Password (not recommended: omit it to be prompted without echo)")
 	LoginCmd.Flags().StringVar(&phone, "phone", "", "Phone number for OTP-based authentication (e.g., +1234567890)")
-	LoginCmd.Flags().StringVar(&otp, "otp", "", "OTP code for verification (if not provided, will prompt interactively)")
+	LoginCmd.Flags().StringVar(&otp, "otp", "", "OTP or two-factor code for verification (if not provided, will prompt interactively)")
 	LoginCmd.Flags().BoolVar(&useOTP, "otp-mode", false, "Use OTP-based authentication instead of refresh token")
+	LoginCmd.Flags().StringVar(&otpChannel, "channel", "sms", "Channel to send the OTP on: "+strings.Join(blend.OTPChannels, ", "))
+	LoginCmd.Flags().IntVar(&otpAttempts, "otp-attempts", 3, "How many times a wrong OTP may be entered before giving up")
 }
 
 func runLogin(cmd *cobra.Command) error {
+	out := console.New(cmd)
 	cfg, err := config.GetFromContext(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to get configuration: %w", err)
@@ -64,7 +104,7 @@ func runLogin(cmd *cobra.Command) error {
 	// Check if session already exists and is valid
 	sessionInfo, err := sessionManager.GetSessionInfo()
 	if err == nil && sessionInfo.Exists && sessionInfo.Valid {
-		fmt.Println("✅ Already authenticated with Bend")
+		out.Statusf("✅ Already authenticated with Bend\n")
 
 		// Load and test session
 		session, err := sessionManager.LoadSession()
@@ -72,50 +112,66 @@ func runLogin(cmd *cobra.Command) error {
 			return fmt.Errorf("failed to load session: %w", err)
 		}
 
-		client.SetSession(session)
-		userInfo, err := client.CheckSession()
-		if err == nil {
-			fmt.Printf("👤 Logged in as: %s (%s)\n", userInfo.GetFullName(), userInfo.Email)
-			fmt.Println("Use 'fintrack bend check' to see session details")
+		// A session issued by another Bend is logged over
+		if err := client.UseSession(session); err != nil {
+			out.Warnf("⚠️  %v\n", err)
+		} else if userInfo, err := client.CheckSession(); err == nil {
+			out.Statusf("👤 Logged in as: %s (%s)\n", userInfo.GetFullName(), userInfo.Email)
+			out.Statusf("Use 'fintrack bend check' to see session details\n")
 			return nil
 		}
 	}
 
-	fmt.Println("🔐 Bend Authentication")
-	fmt.Println("============================")
+	out.Statusf("🔐 Bend Authentication\n")
+	out.Statusf("============================\n")
+
+	// Email and password flow
+	if email != "" {
+		return runPasswordLogin(cmd, cfg, client)
+	}
 
 	// OTP-based authentication flow
 	if useOTP || phone != "" {
 		return runOTPLogin(cmd, cfg, client, sessionManager)
 	}
 
+	// The configured refresh token belongs to the default session; a named
+	// session logs in on its own so it never borrows that identity
+	if cfg.Session != "" {
+		out.Warnf("Session '%s' needs its own login:\n", cfg.Session)
+		out.Warnf("  fintrack --session %s bend login --phone +1234567890\n", cfg.Session)
+		out.Warnf("  fintrack --session %s bend login --email you@example.com\n", cfg.Session)
+		return fmt.Errorf("named sessions log in with OTP or a password")
+	}
+
 	// Check if refresh token is available in config
 	if cfg.Bend.RefreshToken != "" {
 		return runLoginWithRefreshToken(cmd, cfg)
 	}
 
 	// Fallback to manual token input
-	fmt.Println("No refresh token found in configuration.")
-	fmt.Println("Please add your refresh token to the config file:")
-	fmt.Printf("  bend.refresh_token: \"your-refresh-token-here\"\n")
-	fmt.Println("\nAlternatively, you can set it using:")
-	fmt.Println("  fintrack config set bend.refresh_token \"your-refresh-token\"")
-	fmt.Println("\nOr use OTP-based login:")
-	fmt.Println("  fintrack bend login --otp-mode --phone +1234567890")
-
-	return fmt.Errorf("refresh token required for authentication")
+	out.Warnf("No refresh token found in configuration.\n")
+	out.Warnf("Please add your refresh token to the config file:\n")
+	out.Warnf("  bend.refresh_token: \"your-refresh-token-here\"\n")
+	out.Warnf("\nAlternatively, you can set it using:\n")
+	out.Warnf("  fintrack config set bend.refresh_token \"your-refresh-token\"\n")
+	out.Warnf("  export %s=\"your-refresh-token\"\n", config.EnvVar(cfg.BendKeyPrefix()+".refresh_token"))
+	out.Warnf("\nOr use OTP-based login:\n")
+	out.Warnf("  fintrack bend login --otp-mode --phone +1234567890\n")
+
+	return exitcode.Wrap(exitcode.Auth, fmt.Errorf("refresh token required for authentication"))
 }
 
 // runOTPLogin handles OTP-based authentication flow
 func runOTPLogin(cmd *cobra.Command, cfg *config.Config, client *blend.Client, sessionManager *blend.SessionManager) error {
+	out := console.New(cmd)
 	// Enable logging for debugging OTP flow
 	client.SetLogging(true)
 
 	// Get phone number
 	if phone == "" {
-		reader := bufio.NewReader(os.Stdin)
-		fmt.Print("Enter phone number (e.g., +1234567890): ")
-		phoneInput, err := reader.ReadString('\n')
+		out.Promptf("Enter phone number (e.g., +1234567890): ")
+		phoneInput, err := loginInput.ReadString('\n')
 		if err != nil {
 			return fmt.Errorf("failed to read phone number: %w", err)
 		}
@@ -125,89 +181,285 @@ func runOTPLogin(cmd *cobra.Command, cfg *config.Config, client *blend.Client, s
 	if phone == "" {
 		return fmt.Errorf("phone number is required")
 	}
+	if !isOTPChannel(otpChannel) {
+		return fmt.Errorf("invalid --channel %q: must be one of %s", otpChannel, strings.Join(blend.OTPChannels, ", "))
+	}
+	if otpAttempts < 1 {
+		return fmt.Errorf("--otp-attempts must be at least 1")
+	}
 
 	// Generate request ID and device hash (must be same for both OTP and verify)
 	// We'll generate these using the client's internal methods
 	requestID := generateRequestIDForOTP()
 	deviceHash := generateDeviceHashForOTP()
 
-	fmt.Printf("📱 Requesting OTP for %s...\n", phone)
-	fmt.Printf("🔑 Using Request ID: %s\n", requestID)
-	fmt.Printf("📱 Using Device Hash: %s\n", deviceHash)
+	out.Statusf("📱 Requesting OTP for %s...\n", phone)
+	out.Statusf("🔑 Using Request ID: %s\n", requestID)
+	out.Statusf("📱 Using Device Hash: %s\n", redact.String(deviceHash))
 
 	// IMPORTANT: Set device hash BEFORE requesting OTP (must be same for both calls)
 	originalDeviceHash := client.GetDeviceHash()
 	client.SetDeviceHash(deviceHash)
 
-	// Request OTP
-	if err := client.RequestOTP(phone, "sms", requestID); err != nil {
-		client.SetDeviceHash(originalDeviceHash)
-		return fmt.Errorf("failed to request OTP: %w", err)
-	}
-
-	fmt.Println("✅ OTP sent successfully!")
+	verifyData, marbleCookie, err := exchangeOTP(out, client, requestID)
 
-	// Get OTP from user
-	otpCode := otp
-	if otpCode == "" {
-		reader := bufio.NewReader(os.Stdin)
-		fmt.Print("Enter OTP code: ")
-		otpInput, err := reader.ReadString('\n')
-		if err != nil {
-			client.SetDeviceHash(originalDeviceHash)
-			return fmt.Errorf("failed to read OTP: %w", err)
-		}
-		otpCode = strings.TrimSpace(otpInput)
-	}
-
-	if otpCode == "" {
-		client.SetDeviceHash(originalDeviceHash)
-		return fmt.Errorf("OTP code is required")
-	}
-
-	fmt.Println("🔐 Verifying OTP...")
-
-	// Verify OTP - device hash is already set from RequestOTP call above
-
-	verifyData, marbleCookie, err := client.VerifyOTP(phone, otpCode, requestID)
+	// Restore original device hash
+	client.SetDeviceHash(originalDeviceHash)
 	if err != nil {
-		client.SetDeviceHash(originalDeviceHash)
-		return fmt.Errorf("failed to verify OTP: %w", err)
+		return err
 	}
 
 	// Note: marbleCookie is extracted but not currently used in session
 	// It may be needed for future API calls
 	_ = marbleCookie
 
-	// Restore original device hash
-	client.SetDeviceHash(originalDeviceHash)
+	out.Statusf("✅ OTP verified successfully!\n")
+	return completeLogin(cmd, cfg, deviceHash, verifyData)
+}
 
-	fmt.Println("✅ OTP verified successfully!")
+// completeLogin saves the device hash and refresh token a verified login
+// returned to the config, then starts a session from them
+func completeLogin(cmd *cobra.Command, cfg *config.Config, deviceHash string, verifyData *blend.OTPVerifyData) error {
+	out := console.New(cmd)
+	if cfg.Session != "" {
+		// A named session keeps its tokens and device hash in its session
+		// file alone; the config's belong to the default session
+		sessionCfg := *cfg
+		sessionCfg.Bend.RefreshToken = verifyData.RefreshToken
+		sessionCfg.Bend.DeviceHash = deviceHash
+		config.SetInContext(cmd, &sessionCfg)
+
+		out.Statusf("🔄 Initializing session '%s'...\n", cfg.Session)
+		return runLoginWithRefreshToken(cmd, &sessionCfg)
+	}
 
 	// Update config with device_hash and refresh_token
-	fmt.Println("💾 Updating configuration...")
-	if err := updateConfigWithTokens(cfg, deviceHash, verifyData.RefreshToken); err != nil {
+	out.Statusf("💾 Updating configuration...\n")
+	refreshToken := verifyData.RefreshToken
+	if cfg.SecretsFromEnv {
+		// The token must never reach the file; hand it to the user instead
+		refreshToken = ""
+	}
+	if err := updateConfigWithTokens(cfg, deviceHash, refreshToken); err != nil {
 		return fmt.Errorf("failed to update config: %w", err)
 	}
 
-	fmt.Printf("✅ Configuration updated with device_hash and refresh_token\n")
+	if cfg.SecretsFromEnv {
+		out.Statusf("✅ Configuration updated with device_hash\n")
+		out.Warnf("🔐 secrets_from_env is enabled, so the refresh token was not saved. Export it for future runs:\n")
+		out.Warnf("  export %s=%q\n", config.EnvVar(cfg.BendKeyPrefix()+".refresh_token"), verifyData.RefreshToken)
+	} else {
+		out.Statusf("✅ Configuration updated with device_hash and refresh_token\n")
+	}
 
-	// Reload config from file to get updated values
-	reloadedCfg, err := config.Load("")
+	// Reload config from the file updateConfigWithTokens wrote
+	reloadedCfg, err := config.LoadEnvironment(os.Getenv("FINTRACK_CONFIG"), cfg.Environment)
 	if err != nil {
 		return fmt.Errorf("failed to reload configuration: %w", err)
 	}
+	if reloadedCfg.Bend.RefreshToken == "" {
+		reloadedCfg.Bend.RefreshToken = verifyData.RefreshToken
+	}
 
 	// Update context with reloaded config
 	config.SetInContext(cmd, reloadedCfg)
 
 	// Now call the normal login flow which will use the refresh token from config
-	fmt.Println("🔄 Initializing session using refresh token from config...")
+	out.Statusf("🔄 Initializing session using refresh token from config...\n")
 	return runLoginWithRefreshToken(cmd, reloadedCfg)
 }
 
+// =============================================================================
+// PASSWORD LOGIN
+// =============================================================================
+
+// runPasswordLogin handles the email and password flow, with the second
+// factor when the account has one
+func runPasswordLogin(cmd *cobra.Command, cfg *config.Config, client *blend.Client) error {
+	out := console.New(cmd)
+	if otpAttempts < 1 {
+		return fmt.Errorf("--otp-attempts must be at least 1")
+	}
+
+	loginPassword := password
+	if loginPassword != "" {
+		out.Warnf("⚠️  --password leaves the password in your shell history; omit it to be prompted\n")
+	} else {
+		var err error
+		if loginPassword, err = promptPassword(fmt.Sprintf("Password for %s: ", email)); err != nil {
+			return err
+		}
+	}
+	if loginPassword == "" {
+		return fmt.Errorf("password is required")
+	}
+
+	// Both steps must come from the same request ID and device hash, as for OTP
+	requestID := generateRequestIDForOTP()
+	deviceHash := generateDeviceHashForOTP()
+	originalDeviceHash := client.GetDeviceHash()
+	client.SetDeviceHash(deviceHash)
+	defer client.SetDeviceHash(originalDeviceHash)
+
+	out.Statusf("🔐 Logging in as %s...\n", email)
+	loginData, marbleCookie, err := client.PasswordLogin(email, loginPassword, requestID)
+	if err != nil {
+		if blend.IsWrongPassword(err) {
+			return fmt.Errorf("login failed: wrong email or password")
+		}
+		return fmt.Errorf("failed to log in: %w", err)
+	}
+
+	verifyData := &loginData.OTPVerifyData
+	if loginData.TwoFactorRequired {
+		if verifyData, marbleCookie, err = verifyTwoFactor(out, client, loginData, requestID); err != nil {
+			return err
+		}
+	}
+
+	// Note: marbleCookie is extracted but not currently used in session,
+	// as for OTP login
+	_ = marbleCookie
+
+	out.Statusf("✅ Password verified successfully!\n")
+	return completeLogin(cmd, cfg, deviceHash, verifyData)
+}
+
+// verifyTwoFactor completes a password login's two-factor challenge with the
+// code from --otp or the prompt, letting the user retry a wrong one
+func verifyTwoFactor(out *console.Printer, client *blend.Client, loginData *blend.PasswordLoginData, requestID string) (*blend.OTPVerifyData, string, error) {
+	sentTo := "your registered device"
+	if loginData.TwoFactorChannel != "" {
+		sentTo = loginData.TwoFactorChannel
+	}
+	out.Statusf("📱 Two-factor authentication is on: a code was sent by %s\n", sentTo)
+
+	for attempt := 1; ; attempt++ {
+		code := otp
+		for code == "" {
+			out.Promptf("Enter verification code: ")
+			input, err := loginInput.ReadString('\n')
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read verification code: %w", err)
+			}
+			code = strings.TrimSpace(input)
+		}
+
+		verifyData, marbleCookie, err := client.VerifyTwoFactor(loginData.ChallengeID, code, requestID)
+		if err == nil {
+			return verifyData, marbleCookie, nil
+		}
+		// A code given with --otp can't be re-entered
+		if !blend.IsWrongOTP(err) || otp != "" || attempt >= otpAttempts {
+			return nil, "", fmt.Errorf("failed to verify code: %w", err)
+		}
+		out.Warnf("❌ Code rejected (%d attempt(s) left): %v\n", otpAttempts-attempt, err)
+	}
+}
+
+// promptPassword reads a password from the terminal without echoing it, or a
+// line from stdin when it isn't a terminal
+func promptPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	if isTerminal(os.Stdin) {
+		stty := exec.Command("stty", "-echo")
+		stty.Stdin = os.Stdin
+		if stty.Run() == nil {
+			defer func() {
+				stty := exec.Command("stty", "echo")
+				stty.Stdin = os.Stdin
+				_ = stty.Run()
+				fmt.Fprintln(os.Stderr)
+			}()
+		}
+	}
+
+	line, err := loginInput.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// exchangeOTP sends the OTP and verifies the code the user enters, letting
+// them retry a wrong code and resend it, on another channel if they like. The
+// client's device hash must already be the one to log in with.
+func exchangeOTP(out *console.Printer, client *blend.Client, requestID string) (*blend.OTPVerifyData, string, error) {
+	channel := otpChannel
+	if err := client.RequestOTP(phone, channel, requestID); err != nil {
+		return nil, "", fmt.Errorf("failed to request OTP: %w", err)
+	}
+	sentAt := time.Now()
+	out.Statusf("✅ OTP sent by %s!\n", channel)
+
+	resends := 0
+	for attempt := 1; ; attempt++ {
+		// Get OTP from user
+		otpCode := otp
+		for otpCode == "" {
+			out.Promptf("Enter OTP code (or 'resend [sms|whatsapp|voice]'): ")
+			otpInput, err := loginInput.ReadString('\n')
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read OTP: %w", err)
+			}
+			otpCode = strings.TrimSpace(otpInput)
+
+			fields := strings.Fields(strings.ToLower(otpCode))
+			if len(fields) == 0 || fields[0] != "resend" {
+				continue
+			}
+			otpCode = ""
+			if len(fields) > 2 || (len(fields) == 2 && !isOTPChannel(fields[1])) {
+				out.Warnf("❌ Unknown channel: use one of %s\n", strings.Join(blend.OTPChannels, ", "))
+				continue
+			}
+			if resends >= otpMaxResends {
+				out.Warnf("❌ The OTP was already resent too often; enter the last code or start over\n")
+				continue
+			}
+			if wait := otpResendCooldown - time.Since(sentAt); wait > 0 {
+				out.Warnf("⏳ Wait %s before requesting another OTP\n", wait.Round(time.Second))
+				continue
+			}
+			if len(fields) == 2 {
+				channel = fields[1]
+			}
+			if err := client.RequestOTP(phone, channel, requestID); err != nil {
+				return nil, "", fmt.Errorf("failed to resend OTP: %w", err)
+			}
+			sentAt = time.Now()
+			resends++
+			out.Statusf("✅ OTP resent by %s!\n", channel)
+		}
+
+		out.Statusf("🔐 Verifying OTP...\n")
+
+		// Verify OTP - device hash is already set from RequestOTP call above
+		verifyData, marbleCookie, err := client.VerifyOTP(phone, otpCode, requestID)
+		if err == nil {
+			return verifyData, marbleCookie, nil
+		}
+		// A code given with --otp can't be re-entered
+		if !blend.IsWrongOTP(err) || otp != "" || attempt >= otpAttempts {
+			return nil, "", fmt.Errorf("failed to verify OTP: %w", err)
+		}
+		out.Warnf("❌ OTP rejected (%d attempt(s) left): %v\n", otpAttempts-attempt, err)
+	}
+}
+
+// isOTPChannel reports whether channel is one Bend can send an OTP on
+func isOTPChannel(channel string) bool {
+	for _, c := range blend.OTPChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
 // runLoginWithRefreshToken handles the refresh token login flow (extracted from runLogin)
 func runLoginWithRefreshToken(cmd *cobra.Command, cfg *config.Config) error {
+	out := console.New(cmd)
 	// Create client and session manager
 	client := blend.NewClient(cfg)
 	sessionManager := blend.NewSessionManager(cfg.Bend.SessionFile)
@@ -216,34 +468,40 @@ func runLoginWithRefreshToken(cmd *cobra.Command, cfg *config.Config) error {
 		return fmt.Errorf("refresh token not found in configuration")
 	}
 
-	fmt.Println("🔄 Using refresh token from configuration...")
+	out.Statusf("🔄 Using refresh token from configuration...\n")
 
-	// Initialize session from refresh token
-	if err := client.InitializeFromRefreshToken(cfg.Bend.RefreshToken); err != nil {
-		return fmt.Errorf("failed to initialize from refresh token: %w", err)
+	// Spend the refresh token under the session lock, so a concurrent run
+	// doesn't spend it too; the new session is saved with it
+	stale, _ := sessionManager.LoadSession()
+	session, shared, err := sessionManager.Refresh(stale, func(*blend.Session) (*blend.Session, error) {
+		if err := client.InitializeFromRefreshToken(cfg.Bend.RefreshToken); err != nil {
+			return nil, fmt.Errorf("failed to initialize from refresh token: %w", err)
+		}
+		return client.GetSession(), nil
+	})
+	if err != nil {
+		return err
 	}
+	client.SetSession(session)
 
-	// Save session
-	session := client.GetSession()
-	if err := sessionManager.SaveSession(session); err != nil {
-		return fmt.Errorf("failed to save session: %w", err)
+	if shared {
+		out.Statusf("✅ Using the session another fintrack run just created\n")
+	} else {
+		out.Statusf("✅ Authentication successful!\n")
 	}
-
-	fmt.Println("✅ Authentication successful!")
-	fmt.Printf("💾 Session saved to: %s\n", cfg.Bend.SessionFile)
-	fmt.Printf("⏰ Token expires: %s\n", session.ExpiresAt.Format("2006-01-02 15:04:05"))
+	out.Statusf("💾 Session saved to: %s\n", cfg.Bend.SessionFile)
+	out.Statusf("⏰ Token expires: %s\n", session.ExpiresAt.Format("2006-01-02 15:04:05"))
 
 	// Test the session
-	_, err := client.CheckSession()
-	if err != nil {
-		fmt.Printf("⚠️  Warning: Session verification failed: %v\n", err)
+	if _, err := client.CheckSession(); err != nil {
+		out.Warnf("⚠️  Warning: Session verification failed: %v\n", err)
 	} else {
-		fmt.Printf("👤 Authenticated successfully\n")
+		out.Statusf("👤 Authenticated successfully\n")
 	}
 
-	fmt.Println("\nNext steps:")
-	fmt.Println("- Check accounts: fintrack bend accounts")
-	fmt.Println("- Fetch transactions: fintrack bend transactions")
+	out.Statusf("\nNext steps:\n")
+	out.Statusf("- Check accounts: fintrack bend accounts\n")
+	out.Statusf("- Fetch transactions: fintrack bend transactions\n")
 
 	return nil
 }
@@ -259,66 +517,31 @@ func generateDeviceHashForOTP() string {
 	return blend.GenerateDeviceHash()
 }
 
-// updateConfigWithTokens updates the config file with device_hash and refresh_token
+// updateConfigWithTokens saves device_hash and refresh_token to the global
+// config, or the file already holding them, never to a project-local override
 func updateConfigWithTokens(cfg *config.Config, deviceHash, refreshToken string) error {
-	v := viper.New()
-
-	// Set config file path
-	configFile := ""
-	if cfgFile := os.Getenv("FINTRACK_CONFIG"); cfgFile != "" {
-		configFile = cfgFile
-	} else {
-		// Use the same search paths as config loading
-		v.SetConfigName("config")
-		v.SetConfigType("yaml")
-		v.AddConfigPath(".fintrack")
-		v.AddConfigPath("./configs")
-		v.AddConfigPath(".")
-
-		homeDir, err := os.UserHomeDir()
-		if err == nil {
-			globalConfigDir := fmt.Sprintf("%s/.config/fintrack", homeDir)
-			v.AddConfigPath(globalConfigDir)
-		}
-	}
-
-	if configFile != "" {
-		v.SetConfigFile(configFile)
+	prefix := cfg.BendKeyPrefix()
+	configPath, err := config.CredentialsFile(os.Getenv("FINTRACK_CONFIG"), prefix+".refresh_token", prefix+".device_hash")
+	if err != nil {
+		return err
 	}
 
-	// Read existing config
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	if _, err := os.Stat(configPath); err == nil {
+		if err := v.ReadInConfig(); err != nil {
 			return fmt.Errorf("failed to read config: %w", err)
 		}
-		// Config file doesn't exist, we'll create it
+	} else if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	// Set the values
-	v.Set("bend.device_hash", deviceHash)
-	v.Set("bend.refresh_token", refreshToken)
-
-	// Write config
-	if err := v.WriteConfig(); err != nil {
-		// If config file doesn't exist, try to create it
-		configPath := v.ConfigFileUsed()
-		if configPath == "" {
-			// Determine where to create the config file
-			homeDir, err := os.UserHomeDir()
-			if err != nil {
-				return fmt.Errorf("failed to get home directory: %w", err)
-			}
-			configDir := fmt.Sprintf("%s/.config/fintrack", homeDir)
-			if err := os.MkdirAll(configDir, 0755); err != nil {
-				return fmt.Errorf("failed to create config directory: %w", err)
-			}
-			configPath = fmt.Sprintf("%s/config.yaml", configDir)
-			v.SetConfigFile(configPath)
-		}
-		if err := v.WriteConfig(); err != nil {
-			return fmt.Errorf("failed to write config: %w", err)
-		}
+	v.Set(prefix+".device_hash", deviceHash)
+	if refreshToken != "" {
+		v.Set(prefix+".refresh_token", refreshToken)
+	}
+	if err := config.WriteConfig(v); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
 	}
-
 	return nil
 }
@@ -0,0 +1,86 @@
+package blend
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/cmd/console"
+	"github.com/quickkly/fintrack/internal/blend"
+
+	"github.com/spf13/cobra"
+)
+
+// progressBarWidth is the number of cells in the rendered bar
+const progressBarWidth = 24
+
+// progressBar renders pagination progress. On a terminal it redraws a single
+// line in place; otherwise it prints one line per page.
+type progressBar struct {
+	out         io.Writer
+	enabled     bool
+	interactive bool
+	drawn       bool
+}
+
+// newProgressBar creates a progress bar that is silent in quiet, JSON or CSV
+// output mode
+func newProgressBar(cmd *cobra.Command) *progressBar {
+	out := console.New(cmd)
+	return &progressBar{
+		out:         out.Stderr(),
+		enabled:     !out.Quiet && !out.Machine,
+		interactive: isTerminal(os.Stderr),
+	}
+}
+
+// Update renders the latest progress
+func (p *progressBar) Update(progress blend.FetchProgress) {
+	if p == nil || !p.enabled {
+		return
+	}
+
+	if !p.interactive {
+		fmt.Fprintf(p.out, "  📄 Fetched page %d: %d transactions so far\n", progress.Page, progress.Fetched)
+		return
+	}
+
+	line := fmt.Sprintf("  📄 page %d · %d", progress.Page, progress.Fetched)
+	if progress.Total > 0 {
+		ratio := float64(progress.Fetched) / float64(progress.Total)
+		if ratio > 1 {
+			ratio = 1
+		}
+		filled := int(ratio * progressBarWidth)
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+		line = fmt.Sprintf("  [%s] %3.0f%% page %d · %d/%d txns", bar, ratio*100, progress.Page, progress.Fetched, progress.Total)
+		if eta := progress.ETA(); eta > 0 {
+			line += fmt.Sprintf(" · ETA %s", eta.Round(time.Second))
+		}
+	} else {
+		line += " txns"
+	}
+
+	fmt.Fprintf(p.out, "\r\033[K%s", line)
+	p.drawn = true
+}
+
+// Done finishes the progress line so subsequent output starts on a new line
+func (p *progressBar) Done() {
+	if p == nil || !p.enabled || !p.drawn {
+		return
+	}
+	fmt.Fprintln(p.out)
+	p.drawn = false
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,28 @@
+package blend
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// printRawExtra lists, for --show-raw, the fields Bend sent for each record
+// that the models don't know yet. ids and extras are parallel.
+func printRawExtra(w io.Writer, kind string, ids []string, extras []map[string]json.RawMessage) {
+	found := 0
+	for i, extra := range extras {
+		if len(extra) == 0 {
+			continue
+		}
+		found++
+		fmt.Fprintf(w, "🧩 %s %s: %d field(s) the models don't know\n", kind, ids[i], len(extra))
+		for _, name := range blend.ExtraFieldNames(extra) {
+			fmt.Fprintf(w, "    %s: %s\n", name, extra[name])
+		}
+	}
+	if found == 0 {
+		fmt.Fprintf(w, "🧩 No fields beyond the models in %d %s(s)\n", len(extras), kind)
+	}
+}
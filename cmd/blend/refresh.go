@@ -0,0 +1,140 @@
+package blend
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quickkly/fintrack/cmd/console"
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// RefreshAccountsCmd represents the bend refresh-accounts command
+var RefreshAccountsCmd = &cobra.Command{
+	Use:   "refresh-accounts",
+	Short: "Force Bend to re-pull account data",
+	Long: `Trigger a fresh data pull for linked accounts and wait until Bend reports
+new data (the account's last fetched time advances).
+
+Use this before fetching transactions when you need the latest data rather
+than whatever Bend pulled on its own schedule.
+
+Examples:
+  fintrack bend refresh-accounts                          # Refresh all accounts
+  fintrack bend refresh-accounts --account-id <UUID>      # Refresh one account
+  fintrack bend refresh-accounts --timeout 5m --interval 15s`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRefreshAccounts(cmd)
+	},
+}
+
+var (
+	refreshAccountID string
+	refreshTimeout   time.Duration
+	refreshInterval  time.Duration
+	refreshNoWait    bool
+)
+
+func init() {
+	RefreshAccountsCmd.Flags().StringVar(&refreshAccountID, "account-id", "", "Refresh only this account UUID")
+	RefreshAccountsCmd.Flags().DurationVar(&refreshTimeout, "timeout", 3*time.Minute, "Maximum time to wait for fresh data")
+	RefreshAccountsCmd.Flags().DurationVar(&refreshInterval, "interval", 10*time.Second, "Polling interval while waiting")
+	RefreshAccountsCmd.Flags().BoolVar(&refreshNoWait, "no-wait", false, "Trigger the refresh and return without polling")
+}
+
+func runRefreshAccounts(cmd *cobra.Command) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	out := console.New(cmd)
+	client, _, err := setupClientAndSession(cfg)
+	if err != nil {
+		return err
+	}
+	// Polling needs live account data, never a cached copy
+	client.SetCacheBypass(true)
+
+	// Snapshot the current fetch times so we can tell when new data lands
+	accounts, err := client.GetAccounts()
+	if err != nil {
+		return fmt.Errorf("failed to fetch accounts: %w", err)
+	}
+
+	baseline := make(map[string]time.Time)
+	for _, account := range accounts {
+		if refreshAccountID == "" || account.UUID == refreshAccountID {
+			baseline[account.UUID] = account.LastFetchedAt
+		}
+	}
+
+	if len(baseline) == 0 {
+		if refreshAccountID != "" {
+			return fmt.Errorf("account %s not found", refreshAccountID)
+		}
+		out.Statusf("📭 No accounts found\n")
+		return nil
+	}
+
+	var accountIDs []string
+	if refreshAccountID != "" {
+		accountIDs = []string{refreshAccountID}
+	}
+
+	out.Statusf("🔄 Triggering refresh for %d account(s)...\n", len(baseline))
+	if err := client.TriggerAccountRefresh(accountIDs); err != nil {
+		return err
+	}
+
+	out.Statusf("✅ Refresh requested\n")
+
+	if refreshNoWait {
+		return nil
+	}
+
+	return waitForAccountRefresh(out, client, baseline)
+}
+
+// waitForAccountRefresh polls accounts until every tracked account's LastFetchedAt advances
+func waitForAccountRefresh(out *console.Printer, client *blend.Client, baseline map[string]time.Time) error {
+	deadline := time.Now().Add(refreshTimeout)
+	pending := make(map[string]time.Time, len(baseline))
+	for id, fetchedAt := range baseline {
+		pending[id] = fetchedAt
+	}
+
+	out.Statusf("⏳ Waiting for fresh data (timeout %s)...\n", refreshTimeout)
+
+	for len(pending) > 0 {
+		if time.Now().After(deadline) {
+			for id := range pending {
+				out.Warnf("⚠️  %s: no new data yet\n", id)
+			}
+			return fmt.Errorf("timed out waiting for %d account(s) to refresh", len(pending))
+		}
+
+		time.Sleep(refreshInterval)
+
+		accounts, err := client.GetAccounts()
+		if err != nil {
+			return fmt.Errorf("failed to poll accounts: %w", err)
+		}
+
+		for _, account := range accounts {
+			previous, ok := pending[account.UUID]
+			if !ok {
+				continue
+			}
+			if account.LastFetchedAt.After(previous) {
+				out.Statusf("  ✓ %s refreshed at %s\n", account.UUID, account.LastFetchedAt.Format("2006-01-02 15:04:05"))
+				delete(pending, account.UUID)
+			}
+		}
+	}
+
+	out.Statusf("✅ All accounts refreshed\n")
+	return nil
+}
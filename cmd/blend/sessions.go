@@ -0,0 +1,144 @@
+package blend
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/quickkly/fintrack/cmd/console"
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/redact"
+
+	"github.com/spf13/cobra"
+)
+
+// SessionsCmd lists the named sessions of the active environment
+var SessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List the default and named Bend sessions with their expiry",
+	Long: `List the Bend sessions of the active environment: the default one and every
+named session. A named session (--session work) has its own session file next
+to the default one (session@work.json), its own tokens and its own device
+hash, so several logins can be used side by side, e.g. against sandbox and
+production at once.
+
+A session remembers the Bend that issued it and is refused against any other.
+
+Examples:
+  fintrack bend sessions
+  fintrack --env sandbox bend sessions
+  fintrack --session work bend login --phone +1234567890
+  fintrack --session work bend accounts`,
+	Args: cobra.NoArgs,
+	RunE: runSessions,
+}
+
+var sessionsOutput string
+
+func init() {
+	SessionsCmd.Flags().StringVarP(&sessionsOutput, "output", "o", "table", "Output format (table, json; default from display.output)")
+}
+
+// sessionStatus is one session as listed by 'bend sessions'
+type sessionStatus struct {
+	Name            string     `json:"name"` // "" for the default session
+	Active          bool       `json:"active"`
+	File            string     `json:"file"`
+	Exists          bool       `json:"exists"`
+	Valid           bool       `json:"valid"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	BaseURL         string     `json:"base_url,omitempty"`
+	DeviceHash      string     `json:"device_hash,omitempty"` // Redacted
+	HasRefreshToken bool       `json:"has_refresh_token"`
+}
+
+func runSessions(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	names, err := cfg.SessionNames()
+	if err != nil {
+		return err
+	}
+	// The active session is listed even before its first login
+	if cfg.Session != "" && !containsName(names, cfg.Session) {
+		names = append(names, cfg.Session)
+	}
+
+	sessions := []sessionStatus{loadSessionStatus(cfg, "")}
+	for _, name := range names {
+		sessions = append(sessions, loadSessionStatus(cfg, name))
+	}
+
+	out := console.New(cmd)
+	switch format := display.Output(cmd, cfg.Display); format {
+	case "table":
+		if cfg.Environment != "" {
+			out.Resultf("🌐 Environment: %s\n\n", cfg.Environment)
+		}
+		f := display.New(cfg.Display)
+		table := f.Table(display.Column{Header: ""}, display.Column{Header: "Session"}, display.Column{Header: "Status"},
+			display.Column{Header: "Expires"}, display.Column{Header: "Bend"}, display.Column{Header: "Device"}, display.Column{Header: "File"})
+		for _, s := range sessions {
+			active, name, status, expires := "", s.Name, "❌ none", ""
+			if s.Active {
+				active = "*"
+			}
+			if name == "" {
+				name = "(default)"
+			}
+			if s.Exists {
+				status = "⚠️ expired"
+				if s.Valid {
+					status = "✅ valid"
+				}
+				expires = f.DateTime(*s.ExpiresAt)
+			}
+			table.Row(active, name, status, expires, s.BaseURL, s.DeviceHash, s.File)
+		}
+		table.Render(out.Stdout())
+
+	case "json":
+		data, err := json.MarshalIndent(sessions, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal sessions to JSON: %w", err)
+		}
+		out.Resultf("%s\n", data)
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table or json", format)
+	}
+	return nil
+}
+
+// loadSessionStatus reads the named session's file, "" being the default
+func loadSessionStatus(cfg *config.Config, name string) sessionStatus {
+	status := sessionStatus{Name: name, Active: name == cfg.Session, File: cfg.NamedSessionFile(name)}
+
+	sessionManager := blend.NewSessionManager(status.File)
+	session, err := sessionManager.LoadSession()
+	if err != nil {
+		return status
+	}
+	status.Exists = true
+	status.Valid = sessionManager.IsSessionValid(session)
+	status.ExpiresAt = &session.ExpiresAt
+	status.BaseURL = session.BaseURL
+	status.DeviceHash = redact.String(session.DeviceHash)
+	status.HasRefreshToken = session.RefreshToken != ""
+	return status
+}
+
+// containsName reports whether names holds name
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package blend
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quickkly/fintrack/cmd/console"
+	"github.com/quickkly/fintrack/internal/balance"
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/staging"
+	"github.com/quickkly/fintrack/internal/store"
+
+	"github.com/spf13/cobra"
+)
+
+// StatementCmd represents the bend statement command
+var StatementCmd = &cobra.Command{
+	Use:   "statement",
+	Short: "Show an account statement with running balances",
+	Long: `List an account's staged transactions in date order with a running balance
+computed from the account's opening balance (see 'fintrack accounts set-opening').
+
+The computed balance is compared with the balance Bend currently reports for
+the account. A mismatch is flagged on the statement and usually means some
+transactions are missing from the staging directory.
+
+Examples:
+  fintrack bend statement --account-id <UUID>
+  fintrack bend statement --account-id <UUID> --offline   # Skip the Bend balance check
+  fintrack bend statement --account-id <UUID> --tag vacation
+
+Tag filters only choose which rows are listed; running balances still include
+every transaction.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStatement(cmd)
+	},
+}
+
+var (
+	statementAccountID  string
+	statementStagingDir string
+	statementOffline    bool
+
+	statementTags, statementAnyTags, statementExcludeTags []string
+)
+
+func init() {
+	StatementCmd.Flags().StringVar(&statementAccountID, "account-id", "", "Account UUID (required)")
+	StatementCmd.Flags().StringVar(&statementStagingDir, "staging-dir", "", "Staging directory to read transactions from (default: paths.staging_dir)")
+	StatementCmd.Flags().BoolVar(&statementOffline, "offline", false, "Don't fetch the current balance from Bend")
+	StatementCmd.Flags().StringSliceVar(&statementTags, "tag", nil, "Only list transactions carrying every one of these tags (repeatable)")
+	StatementCmd.Flags().StringSliceVar(&statementAnyTags, "any-tag", nil, "Only list transactions carrying at least one of these tags (repeatable)")
+	StatementCmd.Flags().StringSliceVar(&statementExcludeTags, "exclude-tag", nil, "Leave out transactions carrying any of these tags (repeatable)")
+	StatementCmd.MarkFlagRequired("account-id")
+}
+
+func runStatement(cmd *cobra.Command) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	dir := statementStagingDir
+	if dir == "" {
+		dir = cfg.Paths.StagingDir
+	}
+
+	staged, err := staging.LoadTransactions(dir)
+	if err != nil {
+		return err
+	}
+
+	// Leave out transactions soft-deleted after disappearing upstream
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	staged = st.WithoutDeleted(staged)
+
+	tags, err := store.NewTagFilter(statementTags, statementAnyTags, statementExcludeTags)
+	if err != nil {
+		return err
+	}
+
+	var transactions []blend.Transaction
+	for _, txn := range staged {
+		if txn.AccountID == statementAccountID {
+			transactions = append(transactions, txn)
+		}
+	}
+
+	out := console.New(cmd)
+	settings, hasSettings := cfg.AccountSettingsFor(statementAccountID)
+	start, _ := settings.HistoryStart()
+	if !hasSettings {
+		out.Warnf("⚠️  No opening balance configured, starting from 0. Use 'fintrack accounts set-opening' to set one\n")
+	}
+
+	var snapshots []balance.Snapshot
+	if !statementOffline {
+		snapshot, err := fetchBalanceSnapshot(out, cfg, statementAccountID)
+		if err != nil {
+			out.Warnf("⚠️  Could not fetch current balance from Bend: %v\n", err)
+		} else {
+			snapshots = append(snapshots, *snapshot)
+		}
+	}
+
+	mode := cfg.RoundingMode()
+	opening := money.FromFloat(settings.OpeningBalance, mode)
+	result := balance.Compute(opening, start, transactions, snapshots, mode)
+
+	// Balances run over every transaction; tag filters only choose the rows shown
+	if !tags.IsEmpty() {
+		var points []balance.Point
+		for _, point := range result.Points {
+			if tags.Matches(st.Records[point.Transaction.UUID]) {
+				points = append(points, point)
+			}
+		}
+		result.Points = points
+	}
+	printStatement(out, display.New(cfg.Display), statementAccountID, start, result, mode)
+
+	return nil
+}
+
+// fetchBalanceSnapshot returns the balance Bend reports for an account
+func fetchBalanceSnapshot(out *console.Printer, cfg *config.Config, accountID string) (*balance.Snapshot, error) {
+	client, _, err := setupClientAndSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := client.GetAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range accounts {
+		if account.UUID == accountID {
+			if reason := account.StaleReason(time.Now(), cfg.Sync.StaleAfter); reason != "" {
+				out.Warnf("⚠️  Bend's balance for this account is stale (%s)\n", reason)
+			}
+			return &balance.Snapshot{
+				Balance: money.FromFloat(account.CurrentBalance, cfg.RoundingMode()),
+				At:      account.LastFetchedAt,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("account %s not found", accountID)
+}
+
+// printStatement renders the running balance table and divergence summary
+func printStatement(out *console.Printer, f *display.Formatter, accountID string, start time.Time, result balance.Result, mode money.RoundingMode) {
+	out.Resultf("\n📒 Statement for %s\n", accountID)
+	if !start.IsZero() {
+		out.Resultf("📅 History starts %s\n", f.Date(start))
+	}
+	out.Resultf("💰 Opening balance: %s\n\n", result.Opening)
+
+	if len(result.Points) == 0 {
+		out.Resultf("📭 No staged transactions for this account\n")
+	} else {
+		table := f.Table(
+			display.Column{Header: "Date"}, display.Column{Header: "Amount", Right: true},
+			display.Column{Header: "Balance", Right: true}, display.Column{Header: ""}, display.Column{Header: "Narration"})
+		for _, point := range result.Points {
+			txn := point.Transaction
+			flag := ""
+			if point.Divergence != nil {
+				flag = "⚠️"
+			}
+			narration := txn.Narration
+			if len(narration) > 40 {
+				narration = narration[:37] + "..."
+			}
+			table.Row(f.DateTime(txn.TxnTimestamp), money.FromFloat(txn.SignedAmount(), mode).String(),
+				point.RunningBalance.String(), flag, narration)
+		}
+		table.Render(out.Stdout())
+	}
+
+	out.Resultf("\n💰 Closing balance: %s\n", result.Closing)
+
+	for _, d := range result.Divergences {
+		out.Warnf("⚠️  Bend reported %s at %s but the computed balance was %s (difference %s) — transactions are probably missing\n",
+			d.Snapshot.Balance, f.DateTime(d.Snapshot.At), d.Computed, d.Difference())
+	}
+}
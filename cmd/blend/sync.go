@@ -0,0 +1,293 @@
+package blend
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/quickkly/fintrack/cmd/console"
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/exitcode"
+	"github.com/quickkly/fintrack/internal/plugin"
+	"github.com/quickkly/fintrack/internal/store"
+
+	"github.com/spf13/cobra"
+)
+
+var SyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Fetch every account's transactions in parallel into the local store",
+	Long: `Fetch transactions account by account, several accounts at a time, and
+upsert them into the local store (store.path). Each account's transactions
+are recorded with the source sync:<account-id>, so the store shows which
+account fetch they came from.
+
+--all-accounts enumerates the connected accounts; --account-id picks some
+instead. An account whose fetch fails is reported in the summary without
+stopping the others. Unlike 'fintrack sync', nothing is staged and nothing
+is marked deleted.
+
+Examples:
+  fintrack bend sync --all-accounts
+  fintrack bend sync --all-accounts --days 90 --concurrency 8
+  fintrack bend sync --account-id <UUID> --account-id <UUID> --from 2026-01-01`,
+	RunE: runSync,
+}
+
+var (
+	syncAllAccounts bool
+	syncAccountIDs  []string
+	syncFrom        string
+	syncTo          string
+	syncDays        int
+	syncConcurrency int
+	syncOutput      string
+)
+
+func init() {
+	SyncCmd.Flags().BoolVar(&syncAllAccounts, "all-accounts", false, "Sync every connected account")
+	SyncCmd.Flags().StringSliceVar(&syncAccountIDs, "account-id", nil, "Account UUID to sync (repeatable)")
+	SyncCmd.Flags().StringVar(&syncFrom, "from", "", "Start date (YYYY-MM-DD or RFC3339 format)")
+	SyncCmd.Flags().StringVar(&syncTo, "to", "", "End date (YYYY-MM-DD or RFC3339 format)")
+	SyncCmd.Flags().IntVar(&syncDays, "days", 30, "Number of days to fetch when dates are not fully specified")
+	SyncCmd.Flags().IntVar(&syncConcurrency, "concurrency", 4, "Accounts fetched at the same time")
+	SyncCmd.Flags().StringVarP(&syncOutput, "output", "o", "table", "Summary format (table, json; default from display.output)")
+}
+
+// accountSync is the outcome of one account's fetch
+type accountSync struct {
+	AccountID string `json:"account_id"`
+	Name      string `json:"name"`
+	Fetched   int    `json:"fetched"`
+	New       int    `json:"new"`
+	Updated   int    `json:"updated"`
+	Duplicate int    `json:"duplicate"`
+	Error     string `json:"error,omitempty"`
+	Partial   bool   `json:"partial,omitempty"` // Pages before the error were kept
+
+	transactions []blend.Transaction
+	err          error // The fetch error behind Error, for the exit code
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	if !syncAllAccounts && len(syncAccountIDs) == 0 {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("specify --all-accounts or at least one --account-id"))
+	}
+	if syncConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	from, to, err := parseDateRange(syncFrom, syncTo, syncDays)
+	if err != nil {
+		return err
+	}
+
+	out := console.New(cmd)
+	saveCircuits, err := blend.StartBreaker(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := saveCircuits(); err != nil {
+			out.Warnf("⚠️  %v\n", err)
+		}
+	}()
+
+	client, _, err := setupClientAndSession(cfg)
+	if err != nil {
+		return err
+	}
+
+	out.Statusf("🔄 Fetching accounts...\n")
+	accounts, err := client.GetAccounts()
+	if err != nil {
+		return fmt.Errorf("failed to fetch accounts: %w", err)
+	}
+	syncs, err := selectSyncAccounts(cfg, accounts, syncAccountIDs)
+	if err != nil {
+		return err
+	}
+
+	userID, err := client.GetUserID()
+	if err != nil {
+		return fmt.Errorf("failed to get user ID: %w", err)
+	}
+
+	out.Statusf("🔄 Fetching %d account(s) from %s to %s, %d at a time\n",
+		len(syncs), from.Format("2006-01-02"), to.Format("2006-01-02"), syncConcurrency)
+	fetchAccounts(out, client, userID, syncs, from, to, syncConcurrency)
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+
+	// Upserting stays sequential; only the fetches run in parallel
+	now := time.Now()
+	var total store.UpsertResult
+	failed := 0
+	for _, s := range syncs {
+		if s.Error != "" {
+			failed++
+			if !s.Partial {
+				continue
+			}
+		}
+		if s.transactions, err = plugin.Enrich(cmd.Context(), s.transactions); err != nil {
+			return err
+		}
+		result := st.Upsert(s.transactions, now, "sync:"+s.AccountID)
+		s.New, s.Updated, s.Duplicate = result.New, result.Changed, result.Unchanged
+		total.Add(result)
+	}
+
+	if err := printSyncSummary(out, cmd, cfg, syncs); err != nil {
+		return err
+	}
+
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		out.Statusf("🔍 [dry-run] Would store %d new and %d updated transaction(s) in %s\n", total.New, total.Changed, st.Path())
+	} else {
+		if err := st.Save(); err != nil {
+			return err
+		}
+		out.Statusf("✅ Synced %d account(s) into %s: %d new, %d updated, %d duplicate\n",
+			len(syncs)-failed, st.Path(), total.New, total.Changed, total.Unchanged)
+		if partial := countPartial(syncs); partial > 0 {
+			out.Warnf("⚠️  Stored incomplete fetches for %d account(s); sync them again to fetch the rest\n", partial)
+		}
+	}
+
+	if failed > 0 {
+		return syncFailure(syncs, failed)
+	}
+	return nil
+}
+
+// syncFailure describes failed accounts. If nothing was stored the error
+// keeps the first account's cause, so it exits as, say, an auth or network
+// failure; otherwise the sync exits as partial.
+func syncFailure(syncs []*accountSync, failed int) error {
+	if failed < len(syncs) || countPartial(syncs) > 0 {
+		return exitcode.Wrap(exitcode.Partial, fmt.Errorf("%d of %d account(s) failed to sync", failed, len(syncs)))
+	}
+	return fmt.Errorf("%d of %d account(s) failed to sync: %w", failed, len(syncs), syncs[0].err)
+}
+
+// countPartial counts accounts whose fetch failed part-way
+func countPartial(syncs []*accountSync) int {
+	n := 0
+	for _, s := range syncs {
+		if s.Partial {
+			n++
+		}
+	}
+	return n
+}
+
+// selectSyncAccounts picks the accounts to sync: all but the excluded ones, or
+// the given IDs
+func selectSyncAccounts(cfg *config.Config, accounts []blend.Account, ids []string) ([]*accountSync, error) {
+	byID := make(map[string]blend.Account, len(accounts))
+	for _, account := range accounts {
+		byID[account.UUID] = account
+	}
+
+	var syncs []*accountSync
+	if len(ids) == 0 {
+		for _, account := range accounts {
+			if cfg.AccountExcluded(account.UUID) {
+				continue
+			}
+			syncs = append(syncs, &accountSync{AccountID: account.UUID, Name: account.DisplayName()})
+		}
+		return syncs, nil
+	}
+
+	for _, id := range ids {
+		id = cfg.ResolveAccount(id)
+		account, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("account %s not found; run 'fintrack bend accounts' to list them", id)
+		}
+		syncs = append(syncs, &accountSync{AccountID: id, Name: account.DisplayName()})
+	}
+	return syncs, nil
+}
+
+// fetchAccounts fetches each account's transactions, at most limit at a time.
+// A failed account records its error and leaves the others running.
+func fetchAccounts(out *console.Printer, client *blend.Client, userID string, syncs []*accountSync, from, to time.Time, limit int) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, limit)
+
+	for _, s := range syncs {
+		wg.Add(1)
+		go func(s *accountSync) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			transactions, _, err := client.FetchAllTransactionsWithFilters(userID, blend.TransactionFilters{
+				AccountIDs: []string{s.AccountID},
+				StartDate:  from,
+				EndDate:    to,
+				SortBy:     "txn_timestamp",
+				SortOrder:  "DESC",
+				// The store keeps every transaction; reports apply the flag filters
+				IncludeHidden: true,
+			})
+			partial, isPartial := blend.AsPartial(err)
+			if err != nil && !isPartial {
+				s.Error, s.err = err.Error(), err
+				out.Warnf("❌ %s: %v\n", s.Name, err)
+				return
+			}
+			if isPartial {
+				s.Error, s.Partial, s.err = partial.Error(), true, err
+				out.Warnf("⚠️  %s: keeping %d transaction(s) from %d page(s): %v\n", s.Name, partial.Fetched, partial.Pages, partial.Err)
+			}
+			s.transactions = transactions
+			s.Fetched = len(transactions)
+			out.Statusf("  📥 %s: %d transaction(s)\n", s.Name, len(transactions))
+		}(s)
+	}
+	wg.Wait()
+}
+
+// printSyncSummary shows the per-account counts
+func printSyncSummary(out *console.Printer, cmd *cobra.Command, cfg *config.Config, syncs []*accountSync) error {
+	switch display.Output(cmd, cfg.Display) {
+	case "table":
+		out.Resultf("\n")
+		table := display.New(cfg.Display).Table(
+			display.Column{Header: "Account"}, display.Column{Header: "Fetched", Right: true},
+			display.Column{Header: "New", Right: true}, display.Column{Header: "Updated", Right: true},
+			display.Column{Header: "Duplicate", Right: true}, display.Column{Header: "Error"})
+		for _, s := range syncs {
+			table.Row(s.Name, fmt.Sprint(s.Fetched), fmt.Sprint(s.New), fmt.Sprint(s.Updated),
+				fmt.Sprint(s.Duplicate), s.Error)
+		}
+		table.Render(out.Stdout())
+		out.Resultf("\n")
+
+	case "json":
+		jsonData, err := json.MarshalIndent(syncs, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal sync summary to JSON: %w", err)
+		}
+		out.Resultf("%s\n", jsonData)
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table or json", display.Output(cmd, cfg.Display))
+	}
+	return nil
+}
@@ -1,15 +1,25 @@
 package blend
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/quickkly/fintrack/cmd/console"
 	"github.com/quickkly/fintrack/internal/blend"
 	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/exitcode"
+	"github.com/quickkly/fintrack/internal/export"
+	"github.com/quickkly/fintrack/internal/fx"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/staging"
+	"github.com/quickkly/fintrack/internal/store"
 
 	"github.com/spf13/cobra"
 )
@@ -26,18 +36,39 @@ Basic filtering:
 - Time-based filters (this_month, last_month, etc.)
 
 Advanced filtering (matching curl parameters):
-- Category and subcategory filtering
+- Category and subcategory filtering, several IDs at once, and exclusions
+  (--exclude-category, --exclude-subcategory)
 - Custom sorting (amount, txn_timestamp, etc.)
 - Detailed search summaries
 - OR logic for category/subcategory combinations
 - Aggregated totals and counts
 
-Pagination:
-By default, this command fetches the first page of results (up to 50 transactions).
-Use --fetch-all to automatically fetch all pages of transactions matching your filters.
-This is useful when you have many transactions and want to retrieve the complete dataset.
+Transactions hidden in the Bend app are left out unless --include-hidden is
+given; --exclude-cashflow-excluded also drops the ones Bend excludes from cash
+flow (such as own-account transfers). Both are sent to Bend and checked again
+on every fetched page, the same way reports apply them to the local store.
+Repeated --category-id and --subcategory-id flags are checked on every page
+too; Bend has no exclusion parameters, so exclusions are only applied there.
 
-Data is saved to the staging directory for further processing.`,
+Pagination:
+By default, this command fetches the first page of results (bend.page_size, 50
+unless configured). Use --fetch-all to automatically fetch all pages of
+transactions matching your filters. Pagination stops with an error after
+bend.max_pages pages (or --max-pages) so a misbehaving API cannot loop forever.
+Progress is checkpointed to the staging directory after every page; if a run
+dies part-way, rerun the same command with --resume to continue from there.
+
+Fetched transactions are upserted by UUID into the local store (store.path).
+Only transactions that are new, or that Bend now returns differently, are
+written to the staging directory, so re-fetching an overlapping date range
+doesn't stage duplicates. Each run reports how many transactions were new,
+already known or changed.
+
+Listing:
+--columns date,amount,merchant,category also prints the fetched transactions
+as a table of those columns, and --format prints each one with a Go template
+over the transaction's fields, e.g. --format '{{.TxnTimestamp}} {{.Amount}}'.
+Status lines then go to stderr.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runTransactions(cmd)
 	},
@@ -46,7 +77,7 @@ Data is saved to the staging directory for further processing.`,
 var (
 	fromDate      string
 	toDate        string
-	accountID     string
+	accountIDs    []string
 	days          int
 	stagingDir    string
 	timeFilter    string
@@ -54,35 +85,86 @@ var (
 	includeTotals bool
 
 	// Advanced filtering options
-	categoryID      string
-	subcategoryID   string
+	categoryIDs     []string
+	subcategoryIDs  []string
 	sortBy          string
 	sortOrder       string
 	includeDetailed bool
 	orCategory      bool
 
+	// Category exclusions, applied client-side
+	excludeCategories    []string
+	excludeSubcategories []string
+
 	// Debug options
 	enableLogging bool
 
 	// Pagination options
-	fetchAll bool
+	fetchAll  bool
+	pageLimit int
+	maxPages  int
+	resume    bool
+
+	// Free-text search, by Bend where it supports it
+	search string
+
+	// Amount and type options, applied client-side
+	minAmount string
+	maxAmount string
+	txnType   string
+
+	// Hidden and cash flow flag options, sent to Bend and checked client-side
+	includeHidden           bool
+	excludeCashflowExcluded bool
+
+	// Entity and account group options
+	entity string
+	groups []string
+
+	// Listing options, printing what was fetched
+	txnColumns string
+	txnFormat  string
+	listing    *transactionListing
+
+	// Streaming output, writing each fetched page to stdout
+	txnOutput string
+	stream    *export.NDJSONWriter
+
+	// showRaw lists the fields Bend sent that the models don't know
+	showRaw bool
+
+	// printer prints status lines, honouring --quiet
+	printer *console.Printer
+
+	// progress renders pagination progress for --fetch-all
+	progress *progressBar
+
+	// Currency normalization options
+	normalize    bool
+	baseCurrency string
+	converter    *fx.Converter
+
+	// txnStore deduplicates fetches by transaction UUID
+	txnStore *store.Store
 )
 
 func init() {
 	// Basic filtering options
 	TransactionsCmd.Flags().StringVar(&fromDate, "from", "", "Start date (YYYY-MM-DD or RFC3339 format). If only --from is provided, fetches from that date to now")
 	TransactionsCmd.Flags().StringVar(&toDate, "to", "", "End date (YYYY-MM-DD or RFC3339 format). If only --to is provided, fetches --days back from that date")
-	TransactionsCmd.Flags().StringVar(&accountID, "account-id", "", "Specific account UUID")
+	TransactionsCmd.Flags().StringSliceVar(&accountIDs, "account-id", nil, "Account UUID or nickname (repeatable, fetched in one request)")
 	TransactionsCmd.Flags().IntVar(&days, "days", 30, "Number of days to fetch (default: 30, used when dates not fully specified)")
 
 	TransactionsCmd.Flags().StringVar(&stagingDir, "staging-dir", "", "Staging directory (default: from config)")
-	TransactionsCmd.Flags().StringVar(&timeFilter, "time-filter", "", "Predefined time filter (this_month, last_month, this_year, etc.)")
+	TransactionsCmd.Flags().StringVar(&timeFilter, "time-filter", "", "Predefined time filter (this_month, last_month, this_year, etc.), or a fiscal period (fy2025, q1, last_fy) or periods.custom name")
 	TransactionsCmd.Flags().StringVar(&countBy, "count-by", "", "Aggregation period (month, week, day)")
 	TransactionsCmd.Flags().BoolVar(&includeTotals, "include-totals", false, "Include aggregated totals in response")
 
 	// Advanced filtering options
-	TransactionsCmd.Flags().StringVar(&categoryID, "category-id", "", "Filter by category ID")
-	TransactionsCmd.Flags().StringVar(&subcategoryID, "subcategory-id", "", "Filter by subcategory ID")
+	TransactionsCmd.Flags().StringSliceVar(&categoryIDs, "category-id", nil, "Filter by category ID (repeatable, any of them)")
+	TransactionsCmd.Flags().StringSliceVar(&subcategoryIDs, "subcategory-id", nil, "Filter by subcategory ID (repeatable, any of them)")
+	TransactionsCmd.Flags().StringSliceVar(&excludeCategories, "exclude-category", nil, "Drop transactions in this category ID (repeatable)")
+	TransactionsCmd.Flags().StringSliceVar(&excludeSubcategories, "exclude-subcategory", nil, "Drop transactions in this subcategory ID (repeatable)")
 	TransactionsCmd.Flags().StringVar(&sortBy, "sort-by", "txn_timestamp", "Sort field (default: txn_timestamp)")
 	TransactionsCmd.Flags().StringVar(&sortOrder, "sort-order", "DESC", "Sort order (ASC/DESC, default: DESC)")
 	TransactionsCmd.Flags().BoolVar(&includeDetailed, "include-detailed", false, "Include detailed search summary")
@@ -102,6 +184,36 @@ When enabled, this flag will:
 
 Use this when you need the complete dataset matching your filters, especially
 for large date ranges or when you expect more than 50 transactions.`)
+
+	TransactionsCmd.Flags().IntVar(&pageLimit, "limit", 0, "Transactions per page (default: bend.page_size)")
+	TransactionsCmd.Flags().BoolVar(&resume, "resume", false, "Continue an interrupted --fetch-all run from its last saved page")
+	TransactionsCmd.Flags().IntVar(&maxPages, "max-pages", 0, "Maximum pages to fetch with --fetch-all, 0 for no limit (default: bend.max_pages)")
+
+	// Search options
+	TransactionsCmd.Flags().StringVar(&search, "search", "", "Free-text search over narration and merchant (e.g. \"uber\")")
+
+	// Amount and type options
+	TransactionsCmd.Flags().StringVar(&minAmount, "min-amount", "", "Only keep transactions of at least this amount (e.g. 1000 or 1000INR)")
+	TransactionsCmd.Flags().StringVar(&maxAmount, "max-amount", "", "Only keep transactions of at most this amount (e.g. 5000 or 50USD)")
+	TransactionsCmd.Flags().StringVar(&txnType, "type", "", "Only keep incoming or outgoing transactions")
+
+	// Flag options
+	TransactionsCmd.Flags().BoolVar(&includeHidden, "include-hidden", false, "Keep transactions hidden in the Bend app")
+	TransactionsCmd.Flags().BoolVar(&excludeCashflowExcluded, "exclude-cashflow-excluded", false, "Drop transactions excluded from cash flow (e.g. own-account transfers)")
+
+	// Listing options
+	TransactionsCmd.Flags().StringVar(&txnColumns, "columns", "", "Also print the fetched transactions as a table of these columns, e.g. date,amount,merchant,category (available: "+fieldNames(transactionFields)+")")
+	TransactionsCmd.Flags().StringVar(&txnFormat, "format", "", "Also print each fetched transaction with a Go template, e.g. '{{.TxnTimestamp}} {{.Amount}}'")
+	TransactionsCmd.Flags().BoolVar(&showRaw, "show-raw", false, "Also list, on stderr, the fields Bend sent that fintrack doesn't know yet")
+	TransactionsCmd.Flags().StringVarP(&txnOutput, "output", "o", "", "Also stream fetched transactions to stdout as they arrive (ndjson: one JSON object per line)")
+
+	// Entity options
+	TransactionsCmd.Flags().StringVar(&entity, "entity", "", "Only keep transactions belonging to this entity (e.g. personal, llp)")
+	TransactionsCmd.Flags().StringSliceVar(&groups, "group", nil, "Only keep transactions of accounts in these groups (repeatable, see 'fintrack accounts tag')")
+
+	// Currency normalization options
+	TransactionsCmd.Flags().BoolVar(&normalize, "normalize", false, "Add base-currency amounts converted at each transaction's historical rate")
+	TransactionsCmd.Flags().StringVar(&baseCurrency, "base-currency", "", "Currency to normalize into (default: fx.base_currency)")
 }
 
 func runTransactions(cmd *cobra.Command) error {
@@ -116,17 +228,82 @@ func runTransactions(cmd *cobra.Command) error {
 		return err
 	}
 
+	if pageLimit < 0 || maxPages < 0 {
+		return fmt.Errorf("--limit and --max-pages cannot be negative")
+	}
+	if resume && !fetchAll {
+		return fmt.Errorf("--resume only applies to --fetch-all")
+	}
+	// Without --max-pages, bend.max_pages stands (0 there is no cap too)
+	pages := client.MaxPages()
+	if cmd.Flags().Changed("max-pages") {
+		pages = maxPages
+	}
+	client.SetPagination(pageLimit, pages)
+	printer = console.New(cmd)
+	if listing, err = newTransactionListing(printer, cfg, txnColumns, txnFormat); err != nil {
+		return err
+	}
+	stream = nil
+	switch strings.ToLower(txnOutput) {
+	case "":
+	case "ndjson":
+		if listing != nil {
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--output ndjson can't be combined with --columns or --format"))
+		}
+		stream = export.NewNDJSONWriter(printer.Stdout())
+	default:
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("unsupported output format: %s. Use ndjson", txnOutput))
+	}
+	// A listing or stream owns stdout; status lines move to stderr
+	printer.Machine = printer.Machine || listing != nil || stream != nil
+	progress = newProgressBar(cmd)
+	client.SetProgress(progress.Update)
+
+	converter = nil
+	if normalize {
+		if baseCurrency == "" {
+			baseCurrency = cfg.FX.BaseCurrency
+		}
+		baseCurrency = strings.ToUpper(baseCurrency)
+		if converter, err = fx.New(cfg); err != nil {
+			return err
+		}
+	}
+
+	for i, id := range accountIDs {
+		accountIDs[i] = cfg.ResolveAccount(id)
+	}
+
 	// Parse date range
 	from, to, err := parseDateRange(fromDate, toDate, days)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("🔄 Fetching transactions from %s to %s\n",
+	// Fiscal and custom periods are resolved here and sent as dates; other
+	// filters are the API's own
+	apiTimeFilter := timeFilter
+	if start, end, ok, err := cfg.Periods.Resolve(timeFilter, time.Now()); err != nil {
+		return exitcode.Wrap(exitcode.Usage, err)
+	} else if ok {
+		if fromDate != "" || toDate != "" {
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--time-filter %s can't be combined with --from/--to", timeFilter))
+		}
+		from, to, apiTimeFilter = start, end, ""
+	}
+
+	// Don't ask the API for history before the accounts' configured start dates
+	if start := historyStart(cfg, accountIDs); start.After(from) {
+		printer.Statusf("✂️  Account history starts %s, adjusting from date\n", start.Format("2006-01-02"))
+		from = start
+	}
+
+	printer.Statusf("🔄 Fetching transactions from %s to %s\n",
 		from.Format("2006-01-02"), to.Format("2006-01-02"))
 
 	// Setup staging directory
-	stagingDir, err := setupStagingDirectory(stagingDir)
+	stagingDir, err := setupStagingDirectory(cfg, stagingDir)
 	if err != nil {
 		return err
 	}
@@ -137,15 +314,35 @@ func runTransactions(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to get user ID: %w", err)
 	}
 
-	fmt.Printf("👤 Fetching transactions for user: %s\n", userID)
+	printer.Statusf("👤 Fetching transactions for user: %s\n", userID)
+
+	// Configure client-side filtering
+	if err := setupLocalFilters(cfg); err != nil {
+		return err
+	}
+
+	// Fetched transactions are deduplicated against the local store
+	if txnStore, err = store.OpenBackend(cfg.Store.Backend, cfg.Store.Path); err != nil {
+		return err
+	}
 
 	// Prepare filters
-	filters := prepareTransactionFilters(from, to, countBy, timeFilter, sortBy, sortOrder,
-		accountID, categoryID, subcategoryID, includeTotals, includeDetailed, orCategory)
+	filters := prepareTransactionFilters(from, to, client.PageSize(), countBy, apiTimeFilter, sortBy, sortOrder,
+		accountIDs, categoryIDs, subcategoryIDs, includeTotals, includeDetailed, orCategory)
+	filters.ExcludeCategoryIDs = excludeCategories
+	filters.ExcludeSubcategoryIDs = excludeSubcategories
+	filters.Search = strings.TrimSpace(search)
+	filters.IncludeHidden = includeHidden
+	filters.ExcludeCashflowExcluded = excludeCashflowExcluded
+	localFilters = append(localFilters, func(txn blend.Transaction) bool {
+		return filters.Allows(&txn)
+	})
 
 	// Check if using advanced filtering
-	hasAdvancedOptions := hasAdvancedFilteringOptions(timeFilter, accountID, categoryID, subcategoryID,
-		sortBy, sortOrder, includeDetailed, orCategory)
+	hasAdvancedOptions := hasAdvancedFilteringOptions(apiTimeFilter, accountIDs, categoryIDs, subcategoryIDs,
+		sortBy, sortOrder, includeDetailed, orCategory) || entity != "" || search != "" ||
+		len(excludeCategories) > 0 || len(excludeSubcategories) > 0 ||
+		minAmount != "" || maxAmount != "" || txnType != "" || includeHidden || excludeCashflowExcluded
 
 	if hasAdvancedOptions {
 		return handleAdvancedTransactions(client, userID, filters, stagingDir, from, to, fetchAll)
@@ -175,7 +372,9 @@ func setupClientAndSession(cfg *config.Config) (*blend.Client, *blend.Session, e
 		return nil, nil, fmt.Errorf("session expired. Run 'fintrack bend check' to refresh or 'fintrack bend login' to re-authenticate")
 	}
 
-	client.SetSession(session)
+	if err := client.UseSession(session); err != nil {
+		return nil, nil, err
+	}
 
 	return client, session, nil
 }
@@ -191,8 +390,8 @@ func parseDateRange(fromDate, toDate string, days int) (from, to time.Time, err
 		if t, err := time.Parse("2006-01-02T15:04:05Z", dateStr); err == nil {
 			return t, nil
 		}
-		// Try YYYY-MM-DD format (for basic usage)
-		if t, err := time.Parse("2006-01-02", dateStr); err == nil {
+		// Try YYYY-MM-DD format (for basic usage), a day in the user's timezone
+		if t, err := time.ParseInLocation("2006-01-02", dateStr, time.Local); err == nil {
 			return t, nil
 		}
 		return time.Time{}, fmt.Errorf("invalid %s date format (use YYYY-MM-DD or RFC3339): %s", fieldName, dateStr)
@@ -236,10 +435,16 @@ func parseDateRange(fromDate, toDate string, days int) (from, to time.Time, err
 	return from, to, nil
 }
 
+// startOfDay returns midnight of t's day in the user's timezone
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
 // setupStagingDirectory ensures the staging directory exists
-func setupStagingDirectory(stagingDir string) (string, error) {
+func setupStagingDirectory(cfg *config.Config, stagingDir string) (string, error) {
 	if stagingDir == "" {
-		stagingDir = "./staging"
+		stagingDir = cfg.Paths.StagingDir
 	}
 
 	if err := os.MkdirAll(stagingDir, 0755); err != nil {
@@ -250,29 +455,49 @@ func setupStagingDirectory(stagingDir string) (string, error) {
 }
 
 // prepareTransactionFilters creates the transaction filters struct
-func prepareTransactionFilters(from, to time.Time, countBy, timeFilter, sortBy, sortOrder,
-	accountID, categoryID, subcategoryID string, includeTotals, includeDetailed, orCategory bool) blend.TransactionFilters {
+func prepareTransactionFilters(from, to time.Time, limit int, countBy, timeFilter, sortBy, sortOrder string,
+	accountIDs, categoryIDs, subcategoryIDs []string, includeTotals, includeDetailed, orCategory bool) blend.TransactionFilters {
 	return blend.TransactionFilters{
-		Limit:           50, // Default limit per page
+		Limit:           limit,
 		CountBy:         countBy,
 		TimeFilter:      timeFilter,
 		SortBy:          sortBy,
 		SortOrder:       sortOrder,
 		StartDate:       from,
 		EndDate:         to,
-		AccountID:       accountID,
-		CategoryID:      categoryID,
-		SubcategoryID:   subcategoryID,
+		AccountIDs:      accountIDs,
+		CategoryIDs:     categoryIDs,
+		SubcategoryIDs:  subcategoryIDs,
 		IncludeCountBy:  includeTotals,
 		IncludeDetailed: includeDetailed,
 		OrCategory:      orCategory,
 	}
 }
 
+// historyStart is the earliest configured history start of accounts, or zero
+// when any of them has none, so no account's history is cut short
+func historyStart(cfg *config.Config, accounts []string) time.Time {
+	var earliest time.Time
+	for _, id := range accounts {
+		settings, ok := cfg.AccountSettingsFor(id)
+		if !ok {
+			return time.Time{}
+		}
+		start, _ := settings.HistoryStart()
+		if start.IsZero() {
+			return time.Time{}
+		}
+		if earliest.IsZero() || start.Before(earliest) {
+			earliest = start
+		}
+	}
+	return earliest
+}
+
 // hasAdvancedFilteringOptions checks if any advanced filtering is being used
-func hasAdvancedFilteringOptions(timeFilter, accountID, categoryID, subcategoryID,
+func hasAdvancedFilteringOptions(apiTimeFilter string, accountIDs, categoryIDs, subcategoryIDs []string,
 	sortBy, sortOrder string, includeDetailed, orCategory bool) bool {
-	return timeFilter != "" || accountID != "" || categoryID != "" || subcategoryID != "" ||
+	return apiTimeFilter != "" || len(accountIDs) > 0 || len(categoryIDs) > 0 || len(subcategoryIDs) > 0 ||
 		sortBy != "txn_timestamp" || sortOrder != "DESC" || includeDetailed || orCategory
 }
 
@@ -284,69 +509,71 @@ func handleAdvancedTransactions(client *blend.Client, userID string, filters ble
 	logAdvancedFilteringOptions(filters)
 
 	if fetchAll {
-		fmt.Println("🔄 Fetching all pages of transactions...")
-		allTransactions, allCounts, totalInAPI, err := fetchAllTransactionsWithFilters(client, userID, filters)
+		printer.Statusf("🔄 Fetching all pages of transactions...\n")
+		filepath := filepath.Join(stagingDir, generateAdvancedFilename(filters))
+		allTransactions, allCounts, totalInAPI, err := fetchAllTransactionsWithFilters(client, userID, filters, stagingDir, filepath, from, to)
+		partial, err := splitPartial(err)
 		if err != nil {
 			return fmt.Errorf("failed to fetch all transactions: %w", err)
 		}
+		if stream != nil {
+			printer.Statusf("📁 Staging directory: %s\n", stagingDir)
+			return finishPartial(partial)
+		}
 
 		if len(allTransactions) == 0 {
-			fmt.Println("📭 No transactions found")
-			return nil
+			printer.Statusf("📭 No transactions found\n")
+			return finishPartial(partial)
 		}
 
 		// Display summary
-		fmt.Printf("📊 Fetched %d transactions across all pages (Total in API: %d)\n", len(allTransactions), totalInAPI)
+		printer.Statusf("📊 Fetched %d transactions across all pages (Total in API: %d)\n", len(allTransactions), totalInAPI)
 
-		// Generate filename and save
-		filename := generateAdvancedFilename(filters)
-		filepath := filepath.Join(stagingDir, filename)
-
-		if err := saveTransactionsV3(filepath, allTransactions, allCounts, from, to); err != nil {
-			return fmt.Errorf("failed to save transactions: %w", err)
+		if err := saveFetched(filepath, allTransactions, allCounts, from, to); err != nil {
+			return err
 		}
 
-		fmt.Printf("✅ Saved %d transactions to %s\n", len(allTransactions), filename)
-
 		// Display counts if available
 		if len(allCounts) > 0 {
 			displayTransactionCounts(allCounts)
 		}
 
-		fmt.Printf("📁 Staging directory: %s\n", stagingDir)
-		return nil
+		printer.Statusf("📁 Staging directory: %s\n", stagingDir)
+		return finishPartial(partial)
 	}
 
 	// Single page fetch (original behavior)
-	data, err := client.FetchTransactionsWithFilters(userID, filters)
+	data, err := fetchFilteredPage(client, userID, filters)
 	if err != nil {
 		return fmt.Errorf("failed to fetch transactions with filters: %w", err)
 	}
+	data.Transactions = applyLocalFilters(data.Transactions)
+	if err := streamPage(data.Transactions); err != nil {
+		return err
+	}
 
 	if len(data.Transactions) == 0 {
-		fmt.Println("📭 No transactions found")
+		printer.Statusf("📭 No transactions found\n")
 		return nil
 	}
 
 	// Display summary
-	fmt.Printf("📊 Found %d transactions (Total in API: %d)\n", len(data.Transactions), data.Total)
+	printer.Statusf("📊 Found %d transactions (Total in API: %d)\n", len(data.Transactions), data.Total)
 
 	// Generate filename and save
 	filename := generateAdvancedFilename(filters)
 	filepath := filepath.Join(stagingDir, filename)
 
-	if err := saveTransactionsV3(filepath, data.Transactions, data.Counts, from, to); err != nil {
-		return fmt.Errorf("failed to save transactions: %w", err)
+	if err := saveFetched(filepath, data.Transactions, data.Counts, from, to); err != nil {
+		return err
 	}
 
-	fmt.Printf("✅ Saved %d transactions to %s\n", len(data.Transactions), filename)
-
 	// Display counts if available
 	if len(data.Counts) > 0 {
 		displayTransactionCounts(data.Counts)
 	}
 
-	fmt.Printf("📁 Staging directory: %s\n", stagingDir)
+	printer.Statusf("📁 Staging directory: %s\n", stagingDir)
 	return nil
 }
 
@@ -356,34 +583,36 @@ func handleBasicTransactions(client *blend.Client, userID string, filters blend.
 
 	// Use the standard v3 transactions API with pagination
 	// If account filtering is specified, use API filtering instead of local filtering
-	if filters.AccountID != "" {
-		fmt.Printf("🏦 Account filter: %s\n", filters.AccountID)
+	if len(filters.AccountIDs) > 0 {
+		printer.Statusf("🏦 Account filter: %s\n", strings.Join(filters.AccountIDs, ", "))
 
 		if fetchAll {
-			fmt.Println("🔄 Fetching all pages of transactions...")
-			allTransactions, allCounts, totalInAPI, err := fetchAllTransactionsWithFilters(client, userID, filters)
+			printer.Statusf("🔄 Fetching all pages of transactions...\n")
+			filename := fmt.Sprintf("transactions_%s_to_%s_account_%s.json",
+				from.Format("2006-01-02"), to.Format("2006-01-02"), filenameIDs(filters.AccountIDs))
+			filepath := filepath.Join(stagingDir, filename)
+			allTransactions, allCounts, totalInAPI, err := fetchAllTransactionsWithFilters(client, userID, filters, stagingDir, filepath, from, to)
+			partial, err := splitPartial(err)
 			if err != nil {
 				return fmt.Errorf("failed to fetch all transactions with account filter: %w", err)
 			}
+			if stream != nil {
+				printer.Statusf("📁 Staging directory: %s\n", stagingDir)
+				return finishPartial(partial)
+			}
 
 			if len(allTransactions) == 0 {
-				fmt.Println("📭 No transactions found")
-				return nil
+				printer.Statusf("📭 No transactions found\n")
+				return finishPartial(partial)
 			}
 
-			fmt.Printf("📊 Fetched %d transactions across all pages (Total in API: %d)\n", len(allTransactions), totalInAPI)
-
-			filename := fmt.Sprintf("transactions_%s_to_%s_account_%s.json",
-				from.Format("2006-01-02"), to.Format("2006-01-02"), filters.AccountID)
-			filepath := filepath.Join(stagingDir, filename)
+			printer.Statusf("📊 Fetched %d transactions across all pages (Total in API: %d)\n", len(allTransactions), totalInAPI)
 
-			if err := saveTransactionsV3(filepath, allTransactions, allCounts, from, to); err != nil {
-				return fmt.Errorf("failed to save transactions: %w", err)
+			if err := saveFetched(filepath, allTransactions, allCounts, from, to); err != nil {
+				return err
 			}
-
-			fmt.Printf("✅ Saved %d transactions to %s\n", len(allTransactions), filename)
-			fmt.Printf("📁 Staging directory: %s\n", stagingDir)
-			return nil
+			printer.Statusf("📁 Staging directory: %s\n", stagingDir)
+			return finishPartial(partial)
 		}
 
 		// Single page fetch (original behavior)
@@ -391,110 +620,259 @@ func handleBasicTransactions(client *blend.Client, userID string, filters blend.
 		if err != nil {
 			return fmt.Errorf("failed to fetch transactions with account filter: %w", err)
 		}
+		data.Transactions = applyLocalFilters(data.Transactions)
+		if err := streamPage(data.Transactions); err != nil {
+			return err
+		}
 
 		if len(data.Transactions) == 0 {
-			fmt.Println("📭 No transactions found")
+			printer.Statusf("📭 No transactions found\n")
 			return nil
 		}
 
-		fmt.Printf("📊 Found %d transactions (Total in API: %d)\n", len(data.Transactions), data.Total)
+		printer.Statusf("📊 Found %d transactions (Total in API: %d)\n", len(data.Transactions), data.Total)
 
 		filename := fmt.Sprintf("transactions_%s_to_%s_account_%s.json",
-			from.Format("2006-01-02"), to.Format("2006-01-02"), filters.AccountID)
+			from.Format("2006-01-02"), to.Format("2006-01-02"), filenameIDs(filters.AccountIDs))
 		filepath := filepath.Join(stagingDir, filename)
 
-		if err := saveTransactionsV3(filepath, data.Transactions, data.Counts, from, to); err != nil {
-			return fmt.Errorf("failed to save transactions: %w", err)
+		if err := saveFetched(filepath, data.Transactions, data.Counts, from, to); err != nil {
+			return err
 		}
-
-		fmt.Printf("✅ Saved %d transactions to %s\n", len(data.Transactions), filename)
-		fmt.Printf("📁 Staging directory: %s\n", stagingDir)
+		printer.Statusf("📁 Staging directory: %s\n", stagingDir)
 		return nil
 	}
 
 	// Basic fetching without account filtering
 	if fetchAll {
-		fmt.Println("🔄 Fetching all pages of transactions...")
-		allTransactions, allCounts, totalInAPI, err := fetchAllTransactionsBasic(client, userID, filters.Limit)
+		printer.Statusf("🔄 Fetching all pages of transactions...\n")
+		filename := fmt.Sprintf("transactions_%s_to_%s.json",
+			from.Format("2006-01-02"), to.Format("2006-01-02"))
+		filepath := filepath.Join(stagingDir, filename)
+		allTransactions, allCounts, totalInAPI, err := fetchAllTransactionsWithFilters(client, userID, filters, stagingDir, filepath, from, to)
+		partial, err := splitPartial(err)
 		if err != nil {
 			return fmt.Errorf("failed to fetch all transactions: %w", err)
 		}
+		if stream != nil {
+			printer.Statusf("📁 Staging directory: %s\n", stagingDir)
+			return finishPartial(partial)
+		}
 
 		if len(allTransactions) == 0 {
-			fmt.Println("📭 No transactions found")
-			return nil
+			printer.Statusf("📭 No transactions found\n")
+			return finishPartial(partial)
 		}
 
-		fmt.Printf("📊 Fetched %d transactions across all pages (Total in API: %d)\n", len(allTransactions), totalInAPI)
+		printer.Statusf("📊 Fetched %d transactions across all pages (Total in API: %d)\n", len(allTransactions), totalInAPI)
 
-		filename := fmt.Sprintf("transactions_%s_to_%s.json",
-			from.Format("2006-01-02"), to.Format("2006-01-02"))
-		filepath := filepath.Join(stagingDir, filename)
-
-		if err := saveTransactionsV3(filepath, allTransactions, allCounts, from, to); err != nil {
-			return fmt.Errorf("failed to save transactions: %w", err)
+		if err := saveFetched(filepath, allTransactions, allCounts, from, to); err != nil {
+			return err
 		}
-
-		fmt.Printf("✅ Saved %d transactions to %s\n", len(allTransactions), filename)
-		fmt.Printf("📁 Staging directory: %s\n", stagingDir)
-		return nil
+		printer.Statusf("📁 Staging directory: %s\n", stagingDir)
+		return finishPartial(partial)
 	}
 
-	// Single page fetch (original behavior)
-	data, err := client.FetchTransactions(userID, 50, "")
+	// Single page fetch; the filters still carry the date range
+	data, err := client.FetchTransactionsWithFilters(userID, filters)
 	if err != nil {
 		return fmt.Errorf("failed to fetch transactions: %w", err)
 	}
+	data.Transactions = applyLocalFilters(data.Transactions)
+	if err := streamPage(data.Transactions); err != nil {
+		return err
+	}
 
 	if len(data.Transactions) == 0 {
-		fmt.Println("📭 No transactions found")
+		printer.Statusf("📭 No transactions found\n")
 		return nil
 	}
 
-	fmt.Printf("📊 Found %d transactions (Total in API: %d)\n", len(data.Transactions), data.Total)
+	printer.Statusf("📊 Found %d transactions (Total in API: %d)\n", len(data.Transactions), data.Total)
 
 	filename := fmt.Sprintf("transactions_%s_to_%s.json",
 		from.Format("2006-01-02"), to.Format("2006-01-02"))
 	filepath := filepath.Join(stagingDir, filename)
 
-	if err := saveTransactionsV3(filepath, data.Transactions, data.Counts, from, to); err != nil {
-		return fmt.Errorf("failed to save transactions: %w", err)
+	if err := saveFetched(filepath, data.Transactions, data.Counts, from, to); err != nil {
+		return err
+	}
+	printer.Statusf("📁 Staging directory: %s\n", stagingDir)
+	return nil
+}
+
+// iterateFiltered runs client.IterateTransactions. When Bend rejects the
+// free-text search parameter, the search moves to a client-side filter and
+// the query is sent again without it.
+func iterateFiltered(client *blend.Client, userID string, filters blend.TransactionFilters,
+	fn func(page *blend.TransactionsV3Data) error) error {
+	pages := 0
+	err := client.IterateTransactions(userID, filters, func(page *blend.TransactionsV3Data) error {
+		pages++
+		return fn(page)
+	})
+	if err == nil || pages > 0 || filters.Search == "" || !blend.IsRejected(err) {
+		return err
+	}
+
+	q := filters.Search
+	printer.Warnf("⚠️  Bend rejected the search parameter, searching for %q client-side instead\n", q)
+	filters.Search = ""
+	localFilters = append(localFilters, func(txn blend.Transaction) bool {
+		return txn.MatchesSearch(q)
+	})
+	return client.IterateTransactions(userID, filters, fn)
+}
+
+// fetchFilteredPage fetches the first page of a query through iterateFiltered
+func fetchFilteredPage(client *blend.Client, userID string, filters blend.TransactionFilters) (*blend.TransactionsV3Data, error) {
+	var data blend.TransactionsV3Data
+	err := iterateFiltered(client, userID, filters, func(page *blend.TransactionsV3Data) error {
+		data = *page
+		return blend.ErrStopIteration
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// localFilter decides whether a fetched transaction is kept
+type localFilter func(txn blend.Transaction) bool
+
+// localFilters are applied client-side to every fetched page
+var localFilters []localFilter
+
+// setupLocalFilters builds the client-side filters for options the API cannot filter on
+func setupLocalFilters(cfg *config.Config) error {
+	localFilters = nil
+
+	if entity != "" {
+		if err := cfg.ValidateEntity(entity); err != nil {
+			return err
+		}
+		name := strings.ToLower(entity)
+		printer.Statusf("🏢 Entity filter: %s\n", name)
+		localFilters = append(localFilters, func(txn blend.Transaction) bool {
+			return cfg.EntityForTransaction(txn.UUID, txn.AccountID) == name
+		})
+	}
+
+	if len(groups) > 0 {
+		inGroups, err := cfg.AccountFilter(groups)
+		if err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+		printer.Statusf("🗂️  Account group filter: %s\n", strings.ToLower(strings.Join(groups, ", ")))
+		localFilters = append(localFilters, func(txn blend.Transaction) bool {
+			return inGroups(txn.AccountID)
+		})
+	}
+
+	// Bend has no amount or type query parameters, so these are applied to each page
+	amounts, err := money.ParseRange(minAmount, maxAmount, cfg.RoundingMode())
+	if err != nil {
+		return fmt.Errorf("invalid --min-amount/--max-amount: %w", err)
+	}
+	if !amounts.IsEmpty() {
+		printer.Statusf("💰 Amount filter: %s\n", amounts)
+		mode := cfg.RoundingMode()
+		localFilters = append(localFilters, func(txn blend.Transaction) bool {
+			return amounts.Contains(txn.Money(mode))
+		})
+	}
+	if txnType != "" {
+		direction, err := blend.ParseTransactionType(txnType)
+		if err != nil {
+			return err
+		}
+		printer.Statusf("↔️  Type filter: %s\n", strings.ToLower(direction))
+		localFilters = append(localFilters, func(txn blend.Transaction) bool {
+			return txn.Type == direction
+		})
+	}
+
+	// Honour per-account history cutoffs
+	if len(cfg.Accounts.Settings) > 0 {
+		localFilters = append(localFilters, func(txn blend.Transaction) bool {
+			settings, ok := cfg.AccountSettingsFor(txn.AccountID)
+			if !ok {
+				return true
+			}
+			start, _ := settings.HistoryStart() // Validated when the config was loaded
+			return start.IsZero() || !txn.TxnTimestamp.Before(start)
+		})
 	}
 
-	fmt.Printf("✅ Saved %d transactions to %s\n", len(data.Transactions), filename)
-	fmt.Printf("📁 Staging directory: %s\n", stagingDir)
 	return nil
 }
 
+// applyLocalFilters drops transactions rejected by any configured local filter
+func applyLocalFilters(transactions []blend.Transaction) []blend.Transaction {
+	if len(localFilters) == 0 {
+		return transactions
+	}
+
+	filtered := make([]blend.Transaction, 0, len(transactions))
+	for _, txn := range transactions {
+		keep := true
+		for _, filter := range localFilters {
+			if !filter(txn) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			filtered = append(filtered, txn)
+		}
+	}
+	return filtered
+}
+
 // logAdvancedFilteringOptions logs which advanced filtering options are being used
 func logAdvancedFilteringOptions(filters blend.TransactionFilters) {
 	if filters.TimeFilter != "" {
-		fmt.Printf("📅 Using time filter: %s\n", filters.TimeFilter)
+		printer.Statusf("📅 Using time filter: %s\n", filters.TimeFilter)
+	}
+	if len(filters.AccountIDs) > 0 {
+		printer.Statusf("🏦 Account filter: %s\n", strings.Join(filters.AccountIDs, ", "))
 	}
-	if filters.AccountID != "" {
-		fmt.Printf("🏦 Account filter: %s\n", filters.AccountID)
+	if len(filters.CategoryIDs) > 0 {
+		printer.Statusf("🏷️  Category filter: %s\n", strings.Join(filters.CategoryIDs, ", "))
 	}
-	if filters.CategoryID != "" {
-		fmt.Printf("🏷️  Category filter: %s\n", filters.CategoryID)
+	if len(filters.SubcategoryIDs) > 0 {
+		printer.Statusf("🏷️  Subcategory filter: %s\n", strings.Join(filters.SubcategoryIDs, ", "))
 	}
-	if filters.SubcategoryID != "" {
-		fmt.Printf("🏷️  Subcategory filter: %s\n", filters.SubcategoryID)
+	if len(filters.ExcludeCategoryIDs) > 0 {
+		printer.Statusf("🚫 Excluding categories: %s\n", strings.Join(filters.ExcludeCategoryIDs, ", "))
+	}
+	if len(filters.ExcludeSubcategoryIDs) > 0 {
+		printer.Statusf("🚫 Excluding subcategories: %s\n", strings.Join(filters.ExcludeSubcategoryIDs, ", "))
 	}
 	if filters.SortBy != "txn_timestamp" || filters.SortOrder != "DESC" {
-		fmt.Printf("📊 Sorting: %s %s\n", filters.SortBy, filters.SortOrder)
+		printer.Statusf("📊 Sorting: %s %s\n", filters.SortBy, filters.SortOrder)
 	}
 	if filters.IncludeDetailed {
-		fmt.Printf("📋 Including detailed search summary\n")
+		printer.Statusf("📋 Including detailed search summary\n")
 	}
 	if filters.OrCategory {
-		fmt.Printf("🔗 Using OR logic for category/subcategory\n")
+		printer.Statusf("🔗 Using OR logic for category/subcategory\n")
+	}
+	if filters.Search != "" {
+		printer.Statusf("🔎 Search: %q\n", filters.Search)
+	}
+	if filters.IncludeHidden {
+		printer.Statusf("👁️  Including hidden transactions\n")
+	}
+	if filters.ExcludeCashflowExcluded {
+		printer.Statusf("💸 Excluding transactions excluded from cash flow\n")
 	}
 }
 
 // displayTransactionCounts displays transaction count summaries
 func displayTransactionCounts(counts []blend.TransactionCount) {
 	for _, count := range counts {
-		fmt.Printf("📈 %s: %.2f INR in (%d txns), %.2f INR out (%d txns)\n",
+		printer.Statusf("📈 %s: %.2f INR in (%d txns), %.2f INR out (%d txns)\n",
 			count.Date, count.TotalIncoming, count.IncomingCount,
 			count.TotalOutgoing, count.OutgoingCount)
 	}
@@ -510,14 +888,20 @@ func generateAdvancedFilename(filters blend.TransactionFilters) string {
 		parts = append(parts, "advanced")
 	}
 
-	if filters.AccountID != "" {
-		parts = append(parts, "account-"+filters.AccountID)
+	if len(filters.AccountIDs) > 0 {
+		parts = append(parts, "account-"+filenameIDs(filters.AccountIDs))
+	}
+	if len(filters.CategoryIDs) > 0 {
+		parts = append(parts, "cat-"+filenameIDs(filters.CategoryIDs))
 	}
-	if filters.CategoryID != "" {
-		parts = append(parts, "cat-"+filters.CategoryID)
+	if len(filters.SubcategoryIDs) > 0 {
+		parts = append(parts, "subcat-"+filenameIDs(filters.SubcategoryIDs))
 	}
-	if filters.SubcategoryID != "" {
-		parts = append(parts, "subcat-"+filters.SubcategoryID)
+	if len(filters.ExcludeCategoryIDs) > 0 {
+		parts = append(parts, "notcat-"+filenameIDs(filters.ExcludeCategoryIDs))
+	}
+	if len(filters.ExcludeSubcategoryIDs) > 0 {
+		parts = append(parts, "notsubcat-"+filenameIDs(filters.ExcludeSubcategoryIDs))
 	}
 	if filters.SortBy != "txn_timestamp" {
 		parts = append(parts, "sort-"+filters.SortBy)
@@ -525,121 +909,322 @@ func generateAdvancedFilename(filters blend.TransactionFilters) string {
 	if filters.SortOrder != "DESC" {
 		parts = append(parts, filters.SortOrder)
 	}
+	if entity != "" {
+		parts = append(parts, "entity-"+strings.ToLower(entity))
+	}
+	if search != "" {
+		parts = append(parts, "search-"+searchSlug(search))
+	}
+	if txnType != "" {
+		parts = append(parts, strings.ToLower(txnType))
+	}
+	if filters.IncludeHidden {
+		parts = append(parts, "with-hidden")
+	}
+	if filters.ExcludeCashflowExcluded {
+		parts = append(parts, "cashflow-only")
+	}
+	if minAmount != "" {
+		parts = append(parts, "min-"+searchSlug(minAmount))
+	}
+	if maxAmount != "" {
+		parts = append(parts, "max-"+searchSlug(maxAmount))
+	}
 
 	parts = append(parts, time.Now().Format("20060102_150405"))
 	return strings.Join(parts, "_") + ".json"
 }
 
-// TransactionFileV3 represents the structure for saving fetched v3 transaction data
-type TransactionFileV3 struct {
-	Transactions []blend.Transaction      `json:"transactions"`
-	Counts       []blend.TransactionCount `json:"counts"`
-	FetchedAt    time.Time                `json:"fetched_at"`
-	DateRange    DateRange                `json:"date_range"`
-	TotalCount   int                      `json:"total_count"`
+// filenameIDs names a set of IDs in a filename: a single ID as it is, several
+// by their count and a hash, so filtering on many accounts or categories
+// doesn't run past the 255-byte limit on filenames
+func filenameIDs(ids []string) string {
+	if len(ids) == 1 {
+		return ids[0]
+	}
+	sorted := slices.Clone(ids)
+	slices.Sort(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	return fmt.Sprintf("%d-%x", len(ids), sum[:4])
 }
 
-// DateRange represents the date range for fetched transactions
-type DateRange struct {
-	From time.Time `json:"from"`
-	To   time.Time `json:"to"`
+// searchSlug makes a search query safe for a filename
+func searchSlug(q string) string {
+	slug := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, q)
+	return strings.Trim(slug, "-")
 }
 
 // fetchAllTransactionsWithFilters fetches all pages of transactions with filters
-func fetchAllTransactionsWithFilters(client *blend.Client, userID string, filters blend.TransactionFilters) ([]blend.Transaction, []blend.TransactionCount, int, error) {
-	var allTransactions []blend.Transaction
-	var allCounts []blend.TransactionCount
-	after := ""
-	pageNum := 1
-	totalInAPI := 0
-
-	for {
-		filters.After = after
-		data, err := client.FetchTransactionsWithFilters(userID, filters)
-		if err != nil {
-			return nil, nil, 0, fmt.Errorf("failed to fetch page %d: %w", pageNum, err)
+func fetchAllTransactionsWithFilters(client *blend.Client, userID string, filters blend.TransactionFilters,
+	stagingDir, path string, from, to time.Time) ([]blend.Transaction, []blend.TransactionCount, int, error) {
+	// Key on whole days so a rerun with relative dates (--days) still matches
+	query := filters
+	query.After = ""
+	query.StartDate = startOfDay(query.StartDate)
+	query.EndDate = startOfDay(query.EndDate)
+	checkpoint, err := openFetchCheckpoint(printer, stagingDir, "filters", userID, query, entity)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return fetchCheckpointed(client, userID, filters, checkpoint, path, from, to)
+}
+
+// fetchCheckpointed pages through a query from the checkpoint's cursor,
+// saving the checkpoint after every page. When a later page fails, the pages
+// before it are returned with a *blend.PartialError.
+//
+// With --output ndjson nothing is kept across pages: each one is streamed and
+// staged next to path as it arrives, the checkpoint holds only the cursor,
+// and no transactions are returned.
+func fetchCheckpointed(client *blend.Client, userID string, filters blend.TransactionFilters,
+	checkpoint *fetchCheckpoint, path string, from, to time.Time) ([]blend.Transaction, []blend.TransactionCount, int, error) {
+	state := checkpoint.state
+	// Pages restored by --resume are streamed before the new ones
+	if err := streamPage(state.Transactions); err != nil {
+		return nil, nil, 0, err
+	}
+
+	var streamed store.UpsertResult
+	keep := func(page int, transactions []blend.Transaction, counts []blend.TransactionCount) error {
+		if stream == nil {
+			state.Transactions = append(state.Transactions, transactions...)
+			state.Counts = append(state.Counts, counts...)
+			return nil
+		}
+		if len(transactions) == 0 {
+			return nil
 		}
+		result, err := stagePage(path, page, transactions, counts, from, to)
+		streamed.Add(result)
+		return err
+	}
+	if stream != nil && len(state.Transactions) > 0 {
+		// A checkpoint from a run without --output ndjson
+		restored, counts := state.Transactions, state.Counts
+		state.Transactions, state.Counts = nil, nil
+		if err := keep(0, restored, counts); err != nil {
+			return nil, nil, 0, err
+		}
+	}
 
-		allTransactions = append(allTransactions, data.Transactions...)
-		if len(data.Counts) > 0 {
-			allCounts = append(allCounts, data.Counts...)
+	// Progress counts the resumed pages too
+	firstPage, resumed := state.Page, state.Fetched
+	client.SetProgress(func(p blend.FetchProgress) {
+		p.Page += firstPage - 1
+		p.Fetched += resumed
+		progress.Update(p)
+	})
+	defer client.SetProgress(progress.Update)
+	defer progress.Done()
+
+	if filters.Limit <= 0 {
+		filters.Limit = client.PageSize()
+	}
+	filters.After = state.After
+	pages := 0
+	err := iterateFiltered(client, userID, filters, func(data *blend.TransactionsV3Data) error {
+		pages++
+		kept := applyLocalFilters(data.Transactions)
+		if err := streamPage(kept); err != nil {
+			return err
+		}
+		if err := keep(state.Page, kept, data.Counts); err != nil {
+			return err
 		}
 
 		// Store total from first page (should be consistent across pages)
-		if pageNum == 1 {
-			totalInAPI = data.Total
+		if state.Page == 1 {
+			state.Total = data.Total
 		}
+		state.Fetched += len(data.Transactions)
 
-		fmt.Printf("  📄 Fetched page %d: %d transactions\n", pageNum, len(data.Transactions))
-
-		// Check if there are more pages
 		if data.After == "" || len(data.Transactions) < filters.Limit {
-			break
+			return nil
+		}
+		state.After = data.After
+		state.Page++
+		return checkpoint.Save()
+	})
+	if stream != nil && pages > 0 {
+		// Whatever was staged is recorded, however the fetch ended
+		if serr := finishStream(streamed, state.Fetched); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	if partial, ok := blend.AsPartial(err); ok {
+		// Earlier pages are kept, and checkpointed for --resume
+		return state.Transactions, state.Counts, state.Total, &blend.PartialError{
+			Pages: state.Page - 1, Fetched: state.Fetched, Cursor: state.After, Err: partial.Err}
+	}
+	if err != nil {
+		if pages == 0 {
+			return nil, nil, 0, fmt.Errorf("failed to fetch page %d (rerun with --resume to continue): %w", state.Page, err)
+		}
+		return nil, nil, 0, err
+	}
+
+	if stream != nil {
+		// Every page is staged already
+		if err := checkpoint.Remove(); err != nil {
+			return nil, nil, 0, err
+		}
+		return nil, nil, state.Total, nil
+	}
+	pendingCheckpoint = checkpoint
+	return state.Transactions, state.Counts, state.Total, nil
+}
+
+// stagePage records one streamed page in the store and writes its new and
+// changed transactions to a staging file of its own next to path
+func stagePage(path string, page int, transactions []blend.Transaction, counts []blend.TransactionCount,
+	from, to time.Time) (store.UpsertResult, error) {
+	pagePath := fmt.Sprintf("%s_page%03d.json", strings.TrimSuffix(path, ".json"), page)
+	result := txnStore.Upsert(transactions, time.Now(), filepath.Base(pagePath))
+	if fresh := result.Fresh(transactions); len(fresh) > 0 {
+		if err := saveTransactionsV3(pagePath, fresh, counts, from, to); err != nil {
+			return result, fmt.Errorf("failed to save transactions: %w", err)
 		}
-		after = data.After
-		pageNum++
 	}
+	return result, nil
+}
 
-	return allTransactions, allCounts, totalInAPI, nil
+// finishStream saves the store once a streamed fetch is over
+func finishStream(result store.UpsertResult, fetched int) error {
+	if err := txnStore.Save(); err != nil {
+		return err
+	}
+	printer.Statusf("📊 Streamed %d transactions\n", fetched)
+	printer.Statusf("🔁 %d new, %d already known, %d changed\n", result.New, result.Unchanged, result.Changed)
+	return nil
 }
 
-// fetchAllTransactionsBasic fetches all pages of transactions without filters
-func fetchAllTransactionsBasic(client *blend.Client, userID string, limit int) ([]blend.Transaction, []blend.TransactionCount, int, error) {
-	var allTransactions []blend.Transaction
-	var allCounts []blend.TransactionCount
-	after := ""
-	pageNum := 1
-	totalInAPI := 0
+// streamPage writes a fetched page's kept transactions to stdout for
+// --output ndjson
+func streamPage(transactions []blend.Transaction) error {
+	if stream == nil {
+		return nil
+	}
+	if err := stream.Write(transactions); err != nil {
+		return fmt.Errorf("failed to stream transactions: %w", err)
+	}
+	return nil
+}
 
-	if limit == 0 {
-		limit = 50 // Default limit
+// splitPartial separates a partial fetch, whose pages are still worth
+// saving, from a failure that produced nothing
+func splitPartial(err error) (*blend.PartialError, error) {
+	if partial, ok := blend.AsPartial(err); ok {
+		return partial, nil
 	}
+	return nil, err
+}
 
-	for {
-		data, err := client.FetchTransactions(userID, limit, after)
-		if err != nil {
-			return nil, nil, 0, fmt.Errorf("failed to fetch page %d: %w", pageNum, err)
+// finishPartial warns that what was saved is an incomplete fetch, and fails
+// the command so scripts notice
+func finishPartial(partial *blend.PartialError) error {
+	if partial == nil {
+		return nil
+	}
+	printer.Warnf("⚠️  Saved a partial fetch: %d page(s) with %d transaction(s) arrived before the next page failed\n",
+		partial.Pages, partial.Fetched)
+	return fmt.Errorf("incomplete fetch, rerun with --resume to fetch the remaining pages: %w", partial)
+}
+
+// saveFetched upserts a fetch into the local store and stages only the
+// transactions it didn't already hold (or holds an older copy of), so
+// overlapping fetches don't produce overlapping staging files
+func saveFetched(path string, transactions []blend.Transaction, counts []blend.TransactionCount, from, to time.Time) error {
+	result := txnStore.Upsert(transactions, time.Now(), filepath.Base(path))
+	fresh := result.Fresh(transactions)
+
+	if len(fresh) > 0 {
+		if err := saveTransactionsV3(path, fresh, counts, from, to); err != nil {
+			return fmt.Errorf("failed to save transactions: %w", err)
 		}
+		printer.Statusf("✅ Saved %d new or changed transaction(s) to %s\n", len(fresh), filepath.Base(path))
+	} else {
+		// Nothing to stage, but the fetch itself completed
+		if pendingCheckpoint != nil {
+			if err := pendingCheckpoint.Remove(); err != nil {
+				return err
+			}
+			pendingCheckpoint = nil
+		}
+		printer.Statusf("✅ Nothing new to stage, every transaction was already fetched\n")
+	}
 
-		allTransactions = append(allTransactions, data.Transactions...)
-		if len(data.Counts) > 0 {
-			allCounts = append(allCounts, data.Counts...)
+	if err := txnStore.Save(); err != nil {
+		return err
+	}
+	printer.Statusf("🔁 %d new, %d already known, %d changed\n", result.New, result.Unchanged, result.Changed)
+	if showRaw {
+		ids := make([]string, len(transactions))
+		extras := make([]map[string]json.RawMessage, len(transactions))
+		for i, txn := range transactions {
+			ids[i], extras[i] = txn.UUID, txn.RawExtra
 		}
+		printRawExtra(printer.Stderr(), "transaction", ids, extras)
+	}
+	return listing.Print(transactions)
+}
 
-		// Store total from first page (should be consistent across pages)
-		if pageNum == 1 {
-			totalInAPI = data.Total
+// saveTransactionsV3 writes fetched transactions to a staging file, adding
+// base-currency amounts when --normalize is set
+func saveTransactionsV3(filepath string, transactions []blend.Transaction, counts []blend.TransactionCount, from, to time.Time) error {
+	file := staging.NewFile(transactions, counts, from, to)
+
+	if converter != nil {
+		if err := normalizeAmounts(file); err != nil {
+			return err
 		}
+	}
 
-		fmt.Printf("  📄 Fetched page %d: %d transactions\n", pageNum, len(data.Transactions))
+	if err := staging.Write(filepath, file); err != nil {
+		return err
+	}
 
-		// Check if there are more pages
-		if data.After == "" || len(data.Transactions) < limit {
-			break
+	// The fetch is safely on disk, its checkpoint is no longer needed
+	if pendingCheckpoint != nil {
+		if err := pendingCheckpoint.Remove(); err != nil {
+			return err
 		}
-		after = data.After
-		pageNum++
+		pendingCheckpoint = nil
 	}
 
-	return allTransactions, allCounts, totalInAPI, nil
+	return nil
 }
 
-func saveTransactionsV3(filepath string, transactions []blend.Transaction, counts []blend.TransactionCount, from, to time.Time) error {
-	data := TransactionFileV3{
-		Transactions: transactions,
-		Counts:       counts,
-		FetchedAt:    time.Now(),
-		DateRange: DateRange{
-			From: from,
-			To:   to,
-		},
-		TotalCount: len(transactions),
-	}
-
-	jsonData, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal transaction data: %w", err)
+// normalizeAmounts converts every transaction into the base currency using
+// the exchange rate on the transaction's own date
+func normalizeAmounts(file *staging.TransactionFileV3) error {
+	file.BaseCurrency = baseCurrency
+	file.BaseAmounts = make(map[string]float64, len(file.Transactions))
+
+	converted := 0
+	for _, txn := range file.Transactions {
+		currency := txn.Currency
+		if currency == "" {
+			currency = baseCurrency
+		}
+
+		amount, err := converter.Convert(txn.SignedAmount(), currency, baseCurrency, txn.TxnTimestamp)
+		if err != nil {
+			return fmt.Errorf("failed to convert transaction %s: %w", txn.UUID, err)
+		}
+		if !strings.EqualFold(currency, baseCurrency) {
+			converted++
+		}
+		file.BaseAmounts[txn.UUID] = amount
+	}
+
+	if converted > 0 {
+		printer.Statusf("💱 Converted %d foreign-currency transaction(s) to %s at historical rates\n", converted, baseCurrency)
 	}
 
-	return os.WriteFile(filepath, jsonData, 0644)
+	return converter.Close()
 }
@@ -1,12 +1,25 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/quickkly/fintrack/internal/alerts"
 	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/exitcode"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/redact"
+	"github.com/quickkly/fintrack/internal/safefile"
+	"github.com/quickkly/fintrack/internal/store"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -27,6 +40,9 @@ Available subcommands:
 - show: Display current configuration
 - set: Set a configuration value
 - get: Get a configuration value
+- unset: Remove a configuration value
+- edit: Open the config file in $EDITOR and validate it on save
+- env: List the environment variables that override config keys
 - validate: Validate configuration syntax and values`,
 }
 
@@ -34,8 +50,44 @@ Available subcommands:
 var configShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show current configuration",
-	Long:  "Display the current configuration in YAML format",
-	RunE:  runConfigShow,
+	Long: `Display the current configuration in YAML format.
+
+Secrets (refresh tokens, device hashes, credential headers) are masked unless
+--reveal is given.
+
+--trace lists every setting with its resolved value and where it came from:
+an environment overlay (environments.<name>), a FINTRACK_* variable, the
+config file, the built-in default, or derived while loading (file locations
+from paths.*, the generated device hash).`,
+	Example: `  fintrack config show
+  fintrack config show --trace
+  fintrack config show --trace | grep store.`,
+	RunE: runConfigShow,
+}
+
+// configSourcesCmd shows where each setting came from
+var configSourcesCmd = &cobra.Command{
+	Use:   "sources [key-prefix]",
+	Short: "Show where each setting came from",
+	Long: `List every setting with its value and origin, like 'git config --show-origin'.
+Each line starts with the source:
+
+- flag:<flag>           a command-line flag (--tz, --env, --session, ...)
+- environment:<name>    the environments.<name> overlay
+- env:<variable>        a FINTRACK_* environment variable
+- file:<path>           the config file given with FINTRACK_CONFIG or --config
+- local:<path>          a config file found in the working directory
+- global:<path>         the config file in the global config directory
+- default               the built-in default
+- derived               computed while loading (file locations, device hash)
+
+Settings nobody set are left out unless --all is given. Secrets are masked
+unless --reveal is given.`,
+	Args: cobra.MaximumNArgs(1),
+	Example: `  fintrack config sources
+  fintrack config sources bend.
+  fintrack config sources --all --json`,
+	RunE: runConfigSources,
 }
 
 // configSetCmd sets a configuration value
@@ -54,13 +106,52 @@ var configSetCmd = &cobra.Command{
 var configGetCmd = &cobra.Command{
 	Use:   "get <key>",
 	Short: "Get a configuration value",
-	Long:  "Get a configuration value. Use dot notation for nested keys",
+	Long:  "Get a configuration value. Use dot notation for nested keys. Secret values are masked unless --reveal is given",
 	Args:  cobra.ExactArgs(1),
 	Example: `  fintrack config get bend.base_url
-  fintrack config get bend.timeout`,
+  fintrack config get bend.timeout
+  fintrack config get bend.refresh_token --reveal`,
 	RunE: runConfigGet,
 }
 
+// configUnsetCmd removes a configuration value
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a configuration value",
+	Long:  "Remove a key from the config file so its default applies again. Use dot notation for nested keys",
+	Args:  cobra.ExactArgs(1),
+	Example: `  fintrack config unset bend.refresh_token
+  fintrack config unset bend.page_size`,
+	RunE: runConfigUnset,
+}
+
+// configEditCmd opens the config file in an editor
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the configuration file in $EDITOR",
+	Long: `Open the resolved config file in $VISUAL or $EDITOR (vi if neither is set).
+Changes are validated when the editor exits and only saved if they are valid.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigEdit,
+}
+
+// configEnvCmd lists environment variable overrides
+var configEnvCmd = &cobra.Command{
+	Use:   "env",
+	Short: "List environment variable overrides",
+	Long: `List the FINTRACK_* environment variable for every config key and whether it
+is currently set. Nested keys map to variables by upper-casing them and
+replacing dots with underscores: bend.base_url is FINTRACK_BEND_BASE_URL.
+
+Keys inside named sections follow the same rule once the section exists in
+the config file, e.g. FINTRACK_ENVIRONMENTS_SANDBOX_REFRESH_TOKEN.
+
+With secrets_from_env: true, refresh tokens are only read from the
+environment and fintrack refuses to load a config file that contains one.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigEnv,
+}
+
 // configValidateCmd validates the configuration
 var configValidateCmd = &cobra.Command{
 	Use:   "validate",
@@ -69,11 +160,33 @@ var configValidateCmd = &cobra.Command{
 	RunE:  runConfigValidate,
 }
 
+var (
+	configReveal      bool
+	configTrace       bool
+	configSourcesAll  bool
+	configSourcesJSON bool
+	configGlobal      bool
+)
+
 func init() {
+	configShowCmd.Flags().BoolVar(&configReveal, "reveal", false, "Show secrets in plain text")
+	configShowCmd.Flags().BoolVar(&configTrace, "trace", false, "Show where each value came from")
+	configSourcesCmd.Flags().BoolVar(&configReveal, "reveal", false, "Show secrets in plain text")
+	configSourcesCmd.Flags().BoolVar(&configSourcesAll, "all", false, "Include settings that are unset")
+	configSourcesCmd.Flags().BoolVar(&configSourcesJSON, "json", false, "Print the settings as JSON")
+	configGetCmd.Flags().BoolVar(&configReveal, "reveal", false, "Show secrets in plain text")
+	configGetCmd.Flags().BoolVar(&configGlobal, "global", false, "Read only the global config file")
+	configSetCmd.Flags().BoolVar(&configGlobal, "global", false, "Write the global config file even when a project-local one exists")
+	configUnsetCmd.Flags().BoolVar(&configGlobal, "global", false, "Edit the global config file even when a project-local one exists")
+
 	// Add subcommands
 	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configSourcesCmd)
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configEnvCmd)
 	configCmd.AddCommand(configValidateCmd)
 }
 
@@ -88,6 +201,15 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get configuration: %w", err)
 	}
 
+	if !configReveal {
+		cfg = cfg.Redacted()
+	}
+
+	if configTrace {
+		printConfigTrace(cfg.Trace())
+		return nil
+	}
+
 	// Marshal to YAML for pretty printing
 	data, err := yaml.Marshal(cfg)
 	if err != nil {
@@ -98,6 +220,70 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runConfigSources lists each setting with its origin
+func runConfigSources(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+	if !configReveal {
+		cfg = cfg.Redacted()
+	}
+
+	prefix := ""
+	if len(args) == 1 {
+		prefix = args[0]
+	}
+
+	var entries []config.TraceEntry
+	for _, entry := range cfg.Trace() {
+		if !strings.HasPrefix(entry.Key, prefix) {
+			continue
+		}
+		if entry.Source == config.SourceUnset && !configSourcesAll {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if configSourcesJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	width := 0
+	origins := make([]string, len(entries))
+	for i, entry := range entries {
+		origins[i] = entry.Source
+		if entry.Origin != "" {
+			origins[i] += ":" + entry.Origin
+		}
+		width = max(width, len(origins[i]))
+	}
+	for i, entry := range entries {
+		fmt.Printf("%-*s  %s=%s\n", width, origins[i], entry.Key, entry.Value)
+	}
+	return nil
+}
+
+// printConfigTrace prints each setting's value and source, aligned
+func printConfigTrace(entries []config.TraceEntry) {
+	keyWidth, valueWidth := 0, 0
+	for _, entry := range entries {
+		keyWidth = max(keyWidth, len(entry.Key))
+		valueWidth = max(valueWidth, min(len(entry.Value), 50))
+	}
+
+	for _, entry := range entries {
+		source := entry.Source
+		if entry.Origin != "" {
+			source += " (" + entry.Origin + ")"
+		}
+		fmt.Printf("%-*s  %-*s  %s\n", keyWidth, entry.Key, valueWidth, entry.Value, source)
+	}
+}
+
 // runConfigSet sets a configuration value
 func runConfigSet(cmd *cobra.Command, args []string) error {
 	key := args[0]
@@ -105,12 +291,12 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 
 	// Validate the key format
 	if err := validateConfigKey(key); err != nil {
-		return fmt.Errorf("invalid config key: %w", err)
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("invalid config key: %w", err))
 	}
 
 	// Validate the value for known keys
 	if err := validateConfigValue(key, value); err != nil {
-		return fmt.Errorf("invalid config value: %w", err)
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("invalid config value: %w", err))
 	}
 
 	// Load and update configuration
@@ -119,16 +305,20 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if config.IsSecretKey(key) && v.GetBool("secrets_from_env") {
+		return fmt.Errorf("secrets_from_env is enabled; set %s in the environment instead of the config file", config.EnvVar(key))
+	}
+
 	// Set the value
 	v.Set(key, value)
 
 	// Write back to file
-	if err := v.WriteConfig(); err != nil {
+	if err := config.WriteConfig(v); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
 
 	if !IsQuiet() {
-		fmt.Printf("✓ Set %s = %s\n", key, value)
+		fmt.Printf("✓ Set %s = %s\n", key, redact.Value(key, value))
 	}
 
 	return nil
@@ -140,12 +330,18 @@ func runConfigGet(cmd *cobra.Command, args []string) error {
 
 	// Validate the key format
 	if err := validateConfigKey(key); err != nil {
-		return fmt.Errorf("invalid config key: %w", err)
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("invalid config key: %w", err))
 	}
 
-	// Load configuration
-	v, err := loadViperConfig()
-	if err != nil {
+	// Read the layered config files, as commands see them, unless --global
+	v := viper.New()
+	if configGlobal {
+		global, err := loadViperConfig()
+		if err != nil {
+			return err
+		}
+		v = global
+	} else if err := config.ReadConfigFiles(v, config.ConfigFiles(configFilePath())); err != nil {
 		return err
 	}
 
@@ -154,28 +350,190 @@ func runConfigGet(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("key '%s' not found", key)
 	}
 
+	if !configReveal {
+		value = redact.Setting(key, value)
+	}
+
 	fmt.Println(value)
 	return nil
 }
 
-// runConfigValidate validates the configuration
-func runConfigValidate(cmd *cobra.Command, args []string) error {
-	// Load configuration
+// runConfigUnset removes a key from the config file
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	key := args[0]
+
+	if err := validateConfigKey(key); err != nil {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("invalid config key: %w", err))
+	}
+
+	v, err := loadViperConfig()
+	if err != nil {
+		return err
+	}
+
+	configPath := v.ConfigFileUsed()
+	if configPath == "" {
+		return fmt.Errorf("no config file found")
+	}
+
+	found, err := deleteConfigKey(configPath, key)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("key '%s' not found in %s", key, configPath)
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✓ Unset %s\n", key)
+	}
+
+	return nil
+}
+
+// runConfigEdit edits a copy of the config file and saves it back once valid
+func runConfigEdit(cmd *cobra.Command, args []string) error {
 	v, err := loadViperConfig()
 	if err != nil {
 		return err
 	}
 
-	// Parse into config struct to validate
+	configPath := v.ConfigFileUsed()
+	if configPath == "" {
+		return fmt.Errorf("no config file found. Run 'fintrack init' first")
+	}
+
+	original, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	// Edit a copy so an invalid config never replaces the working one
+	tmp, err := os.CreateTemp(filepath.Dir(configPath), ".config-edit-*"+filepath.Ext(configPath))
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(original); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	tmp.Close()
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		if err := openEditor(tmpPath); err != nil {
+			return err
+		}
+
+		edited, err := os.ReadFile(tmpPath)
+		if err != nil {
+			return fmt.Errorf("failed to read edited config: %w", err)
+		}
+		if bytes.Equal(edited, original) {
+			if !IsQuiet() {
+				fmt.Println("No changes made")
+			}
+			return nil
+		}
+
+		err = validateConfigFile(tmpPath)
+		if err == nil {
+			if err := safefile.Write(configPath, edited, safefile.Perm(configPath, 0644)); err != nil {
+				return fmt.Errorf("failed to write config: %w", err)
+			}
+			if !IsQuiet() {
+				fmt.Printf("✓ Saved %s\n", configPath)
+			}
+			return nil
+		}
+
+		fmt.Printf("❌ %v\n", err)
+		fmt.Print("Edit again? [Y/n]: ")
+		answer, _ := reader.ReadString('\n')
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "n") {
+			return fmt.Errorf("changes discarded, %s left unchanged", configPath)
+		}
+	}
+}
+
+// openEditor runs the user's editor on path and waits for it to exit
+func openEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// $EDITOR may carry arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	editorCmd := exec.Command(parts[0], append(parts[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", parts[0], err)
+	}
+	return nil
+}
+
+// validateConfigFile parses a config file and checks its values
+func validateConfigFile(path string) error {
+	v := viper.New()
+	config.SetDefaults(v)
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("configuration syntax error: %w", err)
+	}
+	return validateViperConfig(v)
+}
+
+// validateViperConfig unmarshals loaded settings and validates them
+func validateViperConfig(v *viper.Viper) error {
 	var cfg config.Config
-	if err := v.Unmarshal(&cfg); err != nil {
+	if err := config.Unmarshal(v, &cfg); err != nil {
 		return fmt.Errorf("configuration syntax error: %w", err)
 	}
 
-	// Validate required fields
 	if err := validateConfiguration(&cfg); err != nil {
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
+	return nil
+}
+
+// runConfigEnv prints each config key's environment variable and whether it is set
+func runConfigEnv(cmd *cobra.Command, args []string) error {
+	for _, key := range config.EnvKeys() {
+		name := config.EnvVar(key)
+		value, set := os.LookupEnv(name)
+		switch {
+		case !set:
+			fmt.Printf("  %-42s %s\n", name, key)
+		case redact.IsSecret(key):
+			fmt.Printf("✓ %-42s %s (set)\n", name, key)
+		default:
+			fmt.Printf("✓ %-42s %s = %s\n", name, key, value)
+		}
+	}
+	return nil
+}
+
+// runConfigValidate validates the configuration
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	// Load configuration
+	v, err := loadViperConfig()
+	if err != nil {
+		return err
+	}
+
+	if err := validateViperConfig(v); err != nil {
+		return exitcode.Wrap(exitcode.Usage, err)
+	}
 
 	if !IsQuiet() {
 		fmt.Println("✓ Configuration is valid")
@@ -192,33 +550,25 @@ func runConfigValidate(cmd *cobra.Command, args []string) error {
 func loadViperConfig() (*viper.Viper, error) {
 	v := viper.New()
 
-	// Set config file path
-	if cfgFile != "" {
-		v.SetConfigFile(cfgFile)
-	} else {
-		// Use the same local-first approach as the main config loading
-		v.SetConfigName("config")
-		v.SetConfigType("yaml")
-
-		// Local config paths (like Git's local config)
-		v.AddConfigPath(".fintrack")
-		v.AddConfigPath("./configs")
-		v.AddConfigPath(".")
-
-		// Global config path (like Git's global config)
-		homeDir, err := os.UserHomeDir()
-		if err == nil {
-			globalConfigDir := filepath.Join(homeDir, ".config", "fintrack")
-			v.AddConfigPath(globalConfigDir)
+	// Edits go to one file, the project-local config when there is one,
+	// never to the merged settings
+	path := configFilePath()
+	if path == "" {
+		for _, file := range config.ConfigFiles("") {
+			if !configGlobal || file.Source == config.SourceGlobal {
+				path = file.Path
+			}
 		}
 	}
+	if path == "" {
+		// No config file yet, that's okay for set operations
+		return v, nil
+	}
+	v.SetConfigFile(path)
 
 	// Read existing config
 	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, fmt.Errorf("failed to read config: %w", err)
-		}
-		// Config file doesn't exist, that's okay for set operations
+		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
 
 	return v, nil
@@ -241,6 +591,28 @@ func validateConfigKey(key string) error {
 	validKeys := []string{
 		"bend.base_url", "bend.rate_limit", "bend.timeout", "bend.session_file",
 		"bend.refresh_token", "bend.device_hash", "bend.device_type", "bend.device_location",
+		"bend.page_size", "bend.max_pages", "bend.strict_decode",
+		"bend.archive_raw", "bend.archive_dir",
+		"bend.cache", "bend.cache_ttl", "bend.cache_dir", "bend.usage_file",
+		"bend.user_agent", "bend.origin",
+		"bend.circuit_threshold", "bend.circuit_cooldown", "bend.circuit_file",
+		"bend.transport.max_idle_conns", "bend.transport.max_idle_conns_per_host", "bend.transport.max_conns_per_host",
+		"bend.transport.idle_conn_timeout", "bend.transport.keep_alive",
+		"bend.transport.disable_keep_alives", "bend.transport.force_http2",
+		"fx.base_currency", "fx.rates_url", "fx.cache_file",
+		"money.rounding",
+		"sync.days", "sync.report_file", "sync.max_age", "sync.stale_after",
+		"store.backend", "store.path", "store.keychain", "logging.format", "bills.notify_days", "consent.notify_days",
+		"ledger.default_account", "ledger.default_expense", "ledger.default_income",
+		"display.output", "display.date_format", "display.currency_symbol", "display.table_style",
+		"environment", "session", "secrets_from_env", "timezone",
+		"telemetry.metrics_addr", "telemetry.otlp_endpoint", "telemetry.service_name",
+		"telemetry.stats", "telemetry.stats_file", "telemetry.stats_endpoint",
+		"timeseries.format", "timeseries.url", "timeseries.days", "forecast.days", "forecast.threshold",
+		"refunds.window_days", "receipts.dir", "periods.fiscal_year_start",
+		"paths.data_dir", "paths.cache_dir", "paths.state_dir", "paths.staging_dir",
+		"alerts.smtp.host", "alerts.smtp.port", "alerts.smtp.username", "alerts.smtp.password",
+		"alerts.smtp.from", "alerts.smtp.tls",
 	}
 
 	isValid := false
@@ -264,9 +636,13 @@ func validateConfigKey(key string) error {
 // validateConfigValue validates configuration values for known keys
 func validateConfigValue(key, value string) error {
 	switch key {
-	case "bend.base_url":
+	case "bend.base_url", "bend.origin", "telemetry.otlp_endpoint", "telemetry.stats_endpoint", "timeseries.url":
 		if !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
-			return fmt.Errorf("base_url must be a valid HTTP/HTTPS URL")
+			return fmt.Errorf("%s must be a valid HTTP/HTTPS URL", key)
+		}
+	case "session":
+		if value != "" {
+			return config.ValidateSessionName(value)
 		}
 	case "bend.timeout":
 		if !strings.HasSuffix(value, "s") && !strings.HasSuffix(value, "m") && !strings.HasSuffix(value, "h") {
@@ -276,6 +652,73 @@ func validateConfigValue(key, value string) error {
 		if !strings.HasSuffix(value, "s") && !strings.HasSuffix(value, "ms") {
 			return fmt.Errorf("rate_limit must include unit (s, ms)")
 		}
+	case "bend.page_size", "sync.days", "timeseries.days", "forecast.days":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("%s must be a positive integer", key)
+		}
+	case "bend.max_pages", "bills.notify_days", "consent.notify_days", "refunds.window_days", "bend.circuit_threshold",
+		"bend.transport.max_idle_conns", "bend.transport.max_idle_conns_per_host", "bend.transport.max_conns_per_host":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s must be a non-negative integer", key)
+		}
+	case "bend.cache_ttl", "bend.circuit_cooldown", "bend.transport.idle_conn_timeout", "bend.transport.keep_alive",
+		"sync.max_age", "sync.stale_after":
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("%s must be a duration (e.g. 5m, 1h)", key)
+		}
+	case "bend.strict_decode", "bend.archive_raw", "bend.cache", "secrets_from_env", "store.keychain", "telemetry.stats",
+		"bend.transport.disable_keep_alives", "bend.transport.force_http2":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s must be true or false", key)
+		}
+	case "periods.fiscal_year_start":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 12 {
+			return fmt.Errorf("periods.fiscal_year_start must be a month from 1 to 12")
+		}
+	case "forecast.threshold":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("forecast.threshold must be a number")
+		}
+	case "fx.base_currency":
+		if !money.IsCurrencyCode(value) {
+			return fmt.Errorf("fx.base_currency must be a three-letter currency code (e.g. INR, USD)")
+		}
+	case "money.rounding":
+		if _, err := money.ParseRounding(value); err != nil {
+			return err
+		}
+	case "logging.format":
+		if value != "text" && value != "json" {
+			return fmt.Errorf("logging.format must be text or json")
+		}
+	case "timezone":
+		if _, err := config.LoadTimezone(value); err != nil {
+			return err
+		}
+	case "alerts.smtp.port":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("alerts.smtp.port must be a port number")
+		}
+	case "alerts.smtp.tls":
+		if err := alerts.ValidateSMTP(config.SMTPConfig{TLS: value}); err != nil {
+			return err
+		}
+	case "store.backend":
+		if err := store.ValidateBackend(value); err != nil {
+			return err
+		}
+	case "timeseries.format":
+		if !slices.Contains(config.TimeseriesFormats, value) {
+			return fmt.Errorf("timeseries.format must be one of: %s", strings.Join(config.TimeseriesFormats, ", "))
+		}
+	case "display.output", "display.date_format", "display.currency_symbol", "display.table_style":
+		if err := config.ValidateDisplayValue(strings.TrimPrefix(key, "display."), value); err != nil {
+			return err
+		}
 	case "bend.device_type":
 		validTypes := []string{"Web", "Mobile", "CLI"}
 		isValid := false
@@ -300,3 +743,55 @@ func isValidConfigKeyChar(char rune) bool {
 		(char >= '0' && char <= '9') ||
 		char == '.' || char == '_' || char == '-'
 }
+
+// deleteConfigKey removes a dotted key from the YAML config file in place,
+// preserving comments and the order of the remaining keys
+func deleteConfigKey(configPath, key string) (bool, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return false, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return false, nil
+	}
+
+	if !deleteYAMLKey(root.Content[0], strings.Split(key, ".")) {
+		return false, nil
+	}
+
+	out, err := yaml.Marshal(&root)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := safefile.Write(configPath, out, safefile.Perm(configPath, 0644)); err != nil {
+		return false, fmt.Errorf("failed to write config: %w", err)
+	}
+
+	return true, nil
+}
+
+// deleteYAMLKey walks mapping nodes along path and removes the final key
+func deleteYAMLKey(node *yaml.Node, path []string) bool {
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !strings.EqualFold(node.Content[i].Value, path[0]) {
+			continue
+		}
+		if len(path) == 1 {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true
+		}
+		return deleteYAMLKey(node.Content[i+1], path[1:])
+	}
+
+	return false
+}
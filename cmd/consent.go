@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/alerts"
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/syncreport"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// CONSENT COMMAND DEFINITION
+// =============================================================================
+
+// accountsConsentsCmd lists account aggregator consents
+var accountsConsentsCmd = &cobra.Command{
+	Use:   "consents",
+	Short: "Show when each account's aggregator consent expires",
+	Long: `Show the account aggregator (AA) consent behind each linked account and
+the days left before it expires. Once a consent expires Bend stops receiving
+the account's data, so syncs keep succeeding with nothing new in them.
+
+The expiry comes from Bend where it reports one, otherwise from
+accounts.settings.<uuid>.consent_expires (see 'fintrack accounts set-consent').
+
+When consent.channels names alert channels, 'fintrack sync' warns
+consent.notify_days (14 unless configured) before a consent expires, and when
+Bend reports one paused or revoked.
+
+Examples:
+  fintrack accounts consents
+  fintrack accounts consents --output json
+  fintrack accounts consents --notify          # Also send warnings now`,
+	RunE: runAccountsConsents,
+}
+
+var (
+	consentsNotify bool
+	consentsOutput string
+)
+
+func init() {
+	accountsConsentsCmd.Flags().BoolVar(&consentsNotify, "notify", false, "Send warnings for consents expiring within consent.notify_days to consent.channels")
+	accountsConsentsCmd.Flags().StringVarP(&consentsOutput, "output", "o", "table", "Output format (table, json; default from display.output)")
+}
+
+// =============================================================================
+// CONSENT COMMAND IMPLEMENTATION
+// =============================================================================
+
+// runAccountsConsents fetches the accounts and prints their consents
+func runAccountsConsents(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	client, err := newSessionClient(cfg)
+	if err != nil {
+		return err
+	}
+	accounts, err := client.GetAccounts()
+	if err != nil {
+		return fmt.Errorf("failed to fetch accounts: %w", err)
+	}
+
+	now := time.Now()
+	consents := consentStatuses(cfg, accounts, now)
+
+	switch format := display.Output(cmd, cfg.Display); format {
+	case "table":
+		if len(consents) == 0 {
+			fmt.Println("📭 Bend reports no consent expiry for any account. Use 'fintrack accounts set-consent' to record one")
+			break
+		}
+		f := display.New(cfg.Display)
+		table := f.Table(
+			display.Column{Header: "Account"}, display.Column{Header: "Status"},
+			display.Column{Header: "Expires"}, display.Column{Header: "Days Left", Right: true})
+		for _, consent := range consents {
+			expires, daysLeft := "-", "-"
+			if consent.ExpiresAt != nil {
+				expires = f.Date(*consent.ExpiresAt)
+				daysLeft = strconv.Itoa(*consent.DaysLeft)
+			}
+			status := consent.Status
+			if status == "" {
+				status = "-"
+			}
+			table.Row(consent.Name, status, expires, daysLeft)
+		}
+		table.Render(os.Stdout)
+
+	case "json":
+		data, err := json.MarshalIndent(consents, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal consents to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table or json", format)
+	}
+
+	if consentsNotify && !IsDryRun() {
+		sent, errs := warnConsents(cfg, consents, now)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		}
+		if !IsQuiet() {
+			fmt.Printf("📨 Sent %d warning(s)\n", sent)
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("%d warning delivery error(s)", len(errs))
+		}
+	}
+	return nil
+}
+
+// consentStatuses returns the consent of every account whose expiry or
+// status is known, soonest expiry first
+func consentStatuses(cfg *config.Config, accounts []blend.Account, now time.Time) []syncreport.Consent {
+	var consents []syncreport.Consent
+	for i := range accounts {
+		account := &accounts[i]
+		consent := syncreport.Consent{AccountID: account.UUID, Name: account.DisplayName()}
+		if account.Consent != nil {
+			consent.Status = account.Consent.Status
+		}
+		if expiry := blend.ConsentExpiry(cfg, *account); !expiry.IsZero() {
+			daysLeft := blend.ConsentDaysLeft(expiry, now)
+			consent.ExpiresAt, consent.DaysLeft = &expiry, &daysLeft
+		}
+		if consent.ExpiresAt != nil || consent.Status != "" {
+			consents = append(consents, consent)
+		}
+	}
+
+	sort.SliceStable(consents, func(i, j int) bool {
+		a, b := consents[i].ExpiresAt, consents[j].ExpiresAt
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.Before(*b)
+	})
+	return consents
+}
+
+// consentWarning describes a consent that needs renewing, or "" when it doesn't
+// yet. Paused and revoked consents always do.
+func consentWarning(cfg *config.Config, consent syncreport.Consent) string {
+	switch {
+	case blend.ConsentInactive(consent.Status):
+		return fmt.Sprintf("Consent for %s is %s; Bend no longer receives its data. Renew it in the Bend app", consent.Name, consent.Status)
+	case consent.DaysLeft == nil || *consent.DaysLeft > cfg.Consent.NotifyDays:
+		return ""
+	case *consent.DaysLeft < 0:
+		return fmt.Sprintf("Consent for %s expired on %s; Bend no longer receives its data. Renew it in the Bend app",
+			consent.Name, consent.ExpiresAt.Format("2006-01-02"))
+	default:
+		return fmt.Sprintf("Consent for %s expires %s (%s); renew it in the Bend app so its data keeps flowing",
+			consent.Name, consent.ExpiresAt.Format("2006-01-02"), dueIn(*consent.ExpiresAt, time.Now()))
+	}
+}
+
+// warnConsents sends a warning for every consent that needs renewing and
+// wasn't warned about yet
+func warnConsents(cfg *config.Config, consents []syncreport.Consent, now time.Time) (int, []error) {
+	if len(cfg.Consent.Channels) == 0 {
+		return 0, []error{fmt.Errorf("no consent.channels configured")}
+	}
+
+	state, err := alerts.LoadState(cfg.Alerts.StateFile)
+	if err != nil {
+		return 0, []error{err}
+	}
+
+	var warnings []alerts.Alert
+	for _, consent := range consents {
+		message := consentWarning(cfg, consent)
+		if message == "" {
+			continue
+		}
+		// One warning per expiry date, and another if it lapses anyway
+		period := consent.Status
+		if consent.ExpiresAt != nil {
+			period = consent.ExpiresAt.Format("2006-01-02")
+			if *consent.DaysLeft < 0 {
+				period += ":expired"
+			}
+		}
+		warnings = append(warnings, alerts.Alert{
+			Rule:     "consent:" + consent.AccountID,
+			Message:  message,
+			Period:   period,
+			Channels: cfg.Consent.Channels,
+			At:       now,
+		})
+	}
+
+	sent, errs := alerts.Deliver(context.Background(), cfg.Alerts, state, warnings)
+	if err := state.Save(); err != nil {
+		errs = append(errs, err)
+	}
+	return sent, errs
+}
+
+// syncConsents prints the consents that need renewing after a sync and sends
+// warnings when consent.channels is configured
+func syncConsents(cfg *config.Config, report *syncreport.Report) {
+	for _, consent := range report.Consents {
+		if message := consentWarning(cfg, consent); message != "" {
+			report.Alerts = append(report.Alerts, message)
+			if !IsQuiet() {
+				fmt.Printf("⚠️  %s\n", message)
+			}
+		}
+	}
+
+	if len(cfg.Consent.Channels) == 0 {
+		return
+	}
+	_, errs := warnConsents(cfg, report.Consents, time.Now())
+	for _, err := range errs {
+		report.AddError(err)
+	}
+}
@@ -0,0 +1,100 @@
+// Package console is how commands talk to the user. Results (tables, JSON,
+// CSV) always go to stdout; status and progress lines are dropped with
+// --quiet and move to stderr when stdout carries JSON or CSV, so scripts can
+// parse it. Tests can swap the writers.
+package console
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+
+	"github.com/spf13/cobra"
+)
+
+// Printer writes a command's output according to the global output flags
+type Printer struct {
+	Out io.Writer // Results; nil means os.Stdout
+	Err io.Writer // Warnings, and status lines when Machine is set; nil means os.Stderr
+
+	Quiet   bool // --quiet: drop status lines
+	Verbose bool // --verbose: show detail lines
+	Machine bool // Output is JSON, NDJSON or CSV: keep stdout for the result
+}
+
+// New creates a printer for cmd from --quiet, --verbose and the output
+// format (--output, or display.output from the configuration)
+func New(cmd *cobra.Command) *Printer {
+	p := &Printer{}
+	p.Quiet, _ = cmd.Flags().GetBool("quiet")
+	p.Verbose, _ = cmd.Flags().GetBool("verbose")
+
+	format := ""
+	if cfg, err := config.GetFromContext(cmd); err == nil {
+		format = display.Output(cmd, cfg.Display)
+	} else if f := cmd.Flags().Lookup("output"); f != nil {
+		format = f.Value.String()
+	}
+	p.Machine = format == "json" || format == "csv" || format == "ndjson"
+	return p
+}
+
+// Stdout is where results go. It is looked up on each call because JSON
+// logging replaces os.Stdout once the command starts.
+func (p *Printer) Stdout() io.Writer {
+	if p.Out != nil {
+		return p.Out
+	}
+	return os.Stdout
+}
+
+// Stderr is where warnings go
+func (p *Printer) Stderr() io.Writer {
+	if p.Err != nil {
+		return p.Err
+	}
+	return os.Stderr
+}
+
+// Statusf prints a progress or success line, e.g. "🔄 Fetching accounts..."
+func (p *Printer) Statusf(format string, args ...interface{}) {
+	if p.Quiet {
+		return
+	}
+	fmt.Fprintf(p.status(), format, args...)
+}
+
+// Verbosef prints a detail line only with --verbose
+func (p *Printer) Verbosef(format string, args ...interface{}) {
+	if p.Quiet || !p.Verbose {
+		return
+	}
+	fmt.Fprintf(p.status(), format, args...)
+}
+
+// Warnf prints a warning. Warnings are not status, so --quiet keeps them.
+func (p *Printer) Warnf(format string, args ...interface{}) {
+	fmt.Fprintf(p.Stderr(), format, args...)
+}
+
+// Promptf asks the user for input. Prompts show even with --quiet, since
+// the command waits for an answer.
+func (p *Printer) Promptf(format string, args ...interface{}) {
+	fmt.Fprintf(p.status(), format, args...)
+}
+
+// Resultf prints part of the command's result
+func (p *Printer) Resultf(format string, args ...interface{}) {
+	fmt.Fprintf(p.Stdout(), format, args...)
+}
+
+// status is where status lines go
+func (p *Printer) status() io.Writer {
+	if p.Machine {
+		return p.Stderr()
+	}
+	return p.Stdout()
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/report"
+	"github.com/quickkly/fintrack/internal/store"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// CONTACTS COMMAND DEFINITIONS
+// =============================================================================
+
+// contactsCmd groups the UPI contact book commands
+var contactsCmd = &cobra.Command{
+	Use:   "contacts",
+	Short: "UPI counterparties and the contact book",
+	Long: `UPI narrations usually carry the other side's address (VPA, name@bank).
+fintrack extracts them from the local store; labelling one saves it to the
+contact book (contacts in the config file).
+
+Transfers to and from people are reported under the "people" category, by
+label and name, instead of the category Bend gave them. A VPA counts as a
+person unless Bend resolved a merchant for it or it's saved with --merchant.
+
+Available subcommands:
+- list: Show counterparties found in the store
+- label: Save a counterparty with a label and name
+- remove: Remove a counterparty from the contact book`,
+}
+
+// contactsListCmd lists counterparties
+var contactsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List UPI counterparties found in the store",
+	Long: `List the UPI addresses found in stored narrations with the money sent and
+received, most money moved first. Saved contacts show their label and name.
+
+Examples:
+  fintrack contacts list
+  fintrack contacts list --people              # Leave out merchants
+  fintrack contacts list --unlabelled          # Who still needs a label
+  fintrack contacts list --output json`,
+	Args: cobra.NoArgs,
+	RunE: runContactsList,
+}
+
+// contactsLabelCmd saves a contact
+var contactsLabelCmd = &cobra.Command{
+	Use:   "label <vpa> <label>",
+	Short: "Save a counterparty to the contact book with a label",
+	Example: `  fintrack contacts label ravi.k@okaxis flatmate --name "Ravi"
+  fintrack contacts label sunita@ybl maid
+  fintrack contacts label q12345@ybl shop --merchant     # A business, not a person`,
+	Args: cobra.ExactArgs(2),
+	RunE: runContactsLabel,
+}
+
+// contactsRemoveCmd drops a contact
+var contactsRemoveCmd = &cobra.Command{
+	Use:   "remove <vpa>",
+	Short: "Remove a counterparty from the contact book",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runContactsRemove,
+}
+
+var (
+	contactsPeople     bool
+	contactsUnlabelled bool
+	contactsOutput     string
+	contactsName       string
+	contactsMerchant   bool
+)
+
+func init() {
+	contactsListCmd.Flags().BoolVar(&contactsPeople, "people", false, "Only list people, not merchants")
+	contactsListCmd.Flags().BoolVar(&contactsUnlabelled, "unlabelled", false, "Only list counterparties without a label")
+	contactsListCmd.Flags().StringVarP(&contactsOutput, "output", "o", "table", "Output format (table, json; default from display.output)")
+
+	contactsLabelCmd.Flags().StringVar(&contactsName, "name", "", "Name to show instead of the VPA")
+	contactsLabelCmd.Flags().BoolVar(&contactsMerchant, "merchant", false, "The VPA belongs to a business, so its payments stay merchant spend")
+
+	contactsCmd.AddCommand(contactsListCmd)
+	contactsCmd.AddCommand(contactsLabelCmd)
+	contactsCmd.AddCommand(contactsRemoveCmd)
+}
+
+// =============================================================================
+// CONTACTS COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runContactsList prints the counterparties found in the store
+func runContactsList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	transactions, err := inBaseCurrency(cfg, st.Transactions())
+	if err != nil {
+		return err
+	}
+
+	var counterparties []report.Counterparty
+	for _, c := range report.Counterparties(transactions, cfg.Contacts, cfg.RoundingMode()) {
+		if contactsPeople && c.Merchant || contactsUnlabelled && c.Label != "" {
+			continue
+		}
+		counterparties = append(counterparties, c)
+	}
+
+	switch format := display.Output(cmd, cfg.Display); format {
+	case "table":
+		if len(counterparties) == 0 {
+			fmt.Println("📭 No UPI counterparties found")
+			return nil
+		}
+		f := display.New(cfg.Display)
+		base := cfg.FX.BaseCurrency
+		table := f.Table(display.Column{Header: "VPA"}, display.Column{Header: "Name"}, display.Column{Header: "Label"},
+			display.Column{Header: "Kind"}, display.Column{Header: "Sent", Right: true}, display.Column{Header: "Received", Right: true},
+			display.Column{Header: "Txns", Right: true}, display.Column{Header: "Last"})
+		for _, c := range counterparties {
+			kind := "person"
+			if c.Merchant {
+				kind = "merchant"
+			}
+			table.Row(c.VPA, c.Name, c.Label, kind, f.Amount(c.Sent, base), f.Amount(c.Received, base),
+				fmt.Sprint(c.SentCount+c.ReceivedCount), f.Date(c.Last))
+		}
+		table.Render(os.Stdout)
+
+	case "json":
+		if counterparties == nil {
+			counterparties = []report.Counterparty{}
+		}
+		data, err := json.MarshalIndent(counterparties, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal contacts to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table or json", format)
+	}
+	return nil
+}
+
+// runContactsLabel adds or updates a contact book entry in the config file
+func runContactsLabel(cmd *cobra.Command, args []string) error {
+	vpa, label := strings.ToLower(args[0]), args[1]
+	if report.ExtractVPA(vpa) != vpa {
+		return fmt.Errorf("%q is not a UPI address (name@bank)", args[0])
+	}
+
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	contact := config.ContactConfig{VPA: vpa, Label: label, Name: contactsName, Merchant: contactsMerchant}
+	contacts := make([]config.ContactConfig, 0, len(cfg.Contacts)+1)
+	for _, existing := range cfg.Contacts {
+		if !strings.EqualFold(existing.VPA, vpa) {
+			contacts = append(contacts, existing)
+			continue
+		}
+		// Relabelling keeps the name unless a new one is given
+		if contact.Name == "" {
+			contact.Name = existing.Name
+		}
+	}
+	contacts = append(contacts, contact)
+
+	if err := writeContacts(contacts); err != nil {
+		return err
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✓ Saved %s as %s\n", vpa, label)
+	}
+	return nil
+}
+
+// runContactsRemove drops a contact book entry from the config file
+func runContactsRemove(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	var contacts []config.ContactConfig
+	for _, contact := range cfg.Contacts {
+		if !strings.EqualFold(contact.VPA, args[0]) {
+			contacts = append(contacts, contact)
+		}
+	}
+	if len(contacts) == len(cfg.Contacts) {
+		return fmt.Errorf("%s is not in the contact book", args[0])
+	}
+
+	if err := writeContacts(contacts); err != nil {
+		return err
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✓ Removed %s from the contact book\n", strings.ToLower(args[0]))
+	}
+	return nil
+}
+
+// writeContacts replaces the contact book in the config file
+func writeContacts(contacts []config.ContactConfig) error {
+	v, err := loadViperConfig()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]map[string]interface{}, 0, len(contacts))
+	for _, contact := range contacts {
+		entry := map[string]interface{}{"vpa": contact.VPA, "label": contact.Label}
+		if contact.Name != "" {
+			entry["name"] = contact.Name
+		}
+		if contact.Merchant {
+			entry["merchant"] = true
+		}
+		entries = append(entries, entry)
+	}
+	v.Set("contacts", entries)
+
+	if err := config.WriteConfig(v); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
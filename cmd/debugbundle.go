@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/profile"
+	"github.com/quickkly/fintrack/internal/redact"
+	"github.com/quickkly/fintrack/internal/safefile"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// debugBundle captures the running command for a bug report, see --debug-bundle
+var debugBundle *blend.DebugBundle
+
+// bundleSummary is the bundle.json written when the command finishes
+type bundleSummary struct {
+	Command     string    `json:"command"`
+	Args        []string  `json:"args"` // Values of secret flags and keys masked
+	Environment string    `json:"environment,omitempty"`
+	Session     string    `json:"session,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	DurationMS  int64     `json:"duration_ms"`
+	Success     bool      `json:"success"`
+	Error       string    `json:"error,omitempty"`
+	Requests    int       `json:"requests"`
+	Version     string    `json:"version"`
+	GoVersion   string    `json:"go_version"`
+	OS          string    `json:"os"`
+	Arch        string    `json:"arch"`
+}
+
+// startDebugBundle creates the bundle directory and captures every API
+// request from here on. It runs before the configuration loads so that
+// configuration errors end up in the bundle too.
+func startDebugBundle() error {
+	if debugBundleDir == "" {
+		return nil
+	}
+
+	var err error
+	debugBundle, err = blend.NewDebugBundle(debugBundleDir)
+	if err != nil {
+		return err
+	}
+	blend.RegisterMiddleware(debugBundle.Middleware)
+	profile.Enable()
+	return nil
+}
+
+// writeBundleConfig adds the loaded configuration, secrets masked, to the bundle
+func writeBundleConfig(cfg *config.Config) error {
+	if debugBundle == nil {
+		return nil
+	}
+
+	data, err := yaml.Marshal(cfg.Redacted())
+	if err != nil {
+		return fmt.Errorf("failed to marshal config for the debug bundle: %w", err)
+	}
+	if err := safefile.Write(filepath.Join(debugBundle.Dir, "config.yaml"), data, 0600); err != nil {
+		return fmt.Errorf("failed to write debug bundle config: %w", err)
+	}
+	return nil
+}
+
+// finishDebugBundle writes the run's summary and timings and says where the
+// bundle is
+func finishDebugBundle(cmd *cobra.Command, err error, elapsed time.Duration) {
+	if debugBundle == nil {
+		return
+	}
+
+	now := time.Now()
+	summary := bundleSummary{
+		Command:    cmd.CommandPath(),
+		Args:       redactArgs(os.Args[1:]),
+		StartedAt:  now.Add(-elapsed),
+		FinishedAt: now,
+		DurationMS: elapsed.Milliseconds(),
+		Success:    err == nil,
+		Requests:   debugBundle.Requests(),
+		Version:    blend.Version,
+		GoVersion:  runtime.Version(),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+	}
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	if loadedConfig != nil {
+		summary.Environment = loadedConfig.Environment
+		summary.Session = loadedConfig.Session
+	}
+
+	var timings bytes.Buffer
+	profile.Print(&timings, elapsed)
+
+	data, marshalErr := json.MarshalIndent(summary, "", "  ")
+	if marshalErr == nil {
+		marshalErr = safefile.Write(filepath.Join(debugBundle.Dir, "bundle.json"), data, 0600)
+	}
+	if marshalErr == nil {
+		marshalErr = safefile.Write(filepath.Join(debugBundle.Dir, "timings.txt"), timings.Bytes(), 0600)
+	}
+	if marshalErr != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  failed to write debug bundle: %v\n", marshalErr)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "🧾 Debug bundle: %s (%d request(s)). Check it before attaching it to an issue.\n",
+		debugBundle.Dir, summary.Requests)
+}
+
+// redactArgs masks the values of secret flags (--password x, --token=x) and
+// of secret config keys (config set bend.refresh_token x)
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	maskNext := false
+	for i, arg := range args {
+		switch {
+		case maskNext:
+			redacted[i] = redact.String(arg)
+			maskNext = false
+		case strings.HasPrefix(arg, "-"):
+			name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+			redacted[i] = arg
+			if redact.IsSecret(name) {
+				if hasValue {
+					redacted[i] = arg[:len(arg)-len(value)] + redact.String(value)
+				} else {
+					maskNext = true
+				}
+			}
+		default:
+			redacted[i] = arg
+			maskNext = redact.IsSecret(arg)
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// ENTITY COMMAND DEFINITIONS
+// =============================================================================
+
+// entityCmd represents the entity command
+var entityCmd = &cobra.Command{
+	Use:   "entity",
+	Short: "Manage entities (separate books)",
+	Long: `Manage entities used to split one Bend login into separate books,
+for example "personal" and "llp".
+
+Accounts are assigned to an entity; individual transactions can be assigned
+to a different entity than their account. Accounts without an assignment
+belong to default_entity.
+
+Available subcommands:
+- list: Show entities and their assignments
+- assign: Assign accounts or transactions to an entity
+- unassign: Remove accounts or transactions from an entity
+- default: Set the entity for unassigned accounts`,
+}
+
+// entityListCmd lists configured entities
+var entityListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List entities and their assignments",
+	RunE:  runEntityList,
+}
+
+// entityAssignCmd assigns accounts or transactions to an entity
+var entityAssignCmd = &cobra.Command{
+	Use:   "assign <entity> <uuid>...",
+	Short: "Assign accounts or transactions to an entity",
+	Args:  cobra.MinimumNArgs(2),
+	Example: `  fintrack entity assign llp 6f1c...-account-uuid
+  fintrack entity assign personal --transaction 91ab...-txn-uuid`,
+	RunE: runEntityAssign,
+}
+
+// entityUnassignCmd removes accounts or transactions from an entity
+var entityUnassignCmd = &cobra.Command{
+	Use:   "unassign <entity> <uuid>...",
+	Short: "Remove accounts or transactions from an entity",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runEntityUnassign,
+}
+
+// entityDefaultCmd sets the default entity
+var entityDefaultCmd = &cobra.Command{
+	Use:   "default <entity>",
+	Short: "Set the entity used for unassigned accounts",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runEntityDefault,
+}
+
+var entityTransactions bool
+
+func init() {
+	entityAssignCmd.Flags().BoolVar(&entityTransactions, "transaction", false, "Treat the UUIDs as transaction IDs instead of account IDs")
+	entityUnassignCmd.Flags().BoolVar(&entityTransactions, "transaction", false, "Treat the UUIDs as transaction IDs instead of account IDs")
+
+	entityCmd.AddCommand(entityListCmd)
+	entityCmd.AddCommand(entityAssignCmd)
+	entityCmd.AddCommand(entityUnassignCmd)
+	entityCmd.AddCommand(entityDefaultCmd)
+}
+
+// =============================================================================
+// ENTITY COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runEntityList prints every entity with its assignments
+func runEntityList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	names := cfg.EntityNames()
+	if len(names) == 0 {
+		fmt.Println("No entities configured. Use 'fintrack entity assign <entity> <account-uuid>' to create one")
+		return nil
+	}
+
+	for _, name := range names {
+		marker := ""
+		if name == strings.ToLower(cfg.DefaultEntity) {
+			marker = " (default)"
+		}
+		fmt.Printf("%s%s\n", name, marker)
+
+		entity := cfg.Entities[name]
+		for _, id := range entity.Accounts {
+			fmt.Printf("  account:     %s\n", id)
+		}
+		for _, id := range entity.Transactions {
+			fmt.Printf("  transaction: %s\n", id)
+		}
+	}
+
+	return nil
+}
+
+// runEntityAssign adds UUIDs to an entity, moving them out of any other entity
+func runEntityAssign(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+	ids := args[1:]
+
+	v, err := loadViperConfig()
+	if err != nil {
+		return err
+	}
+
+	field := entityField()
+
+	// An account or transaction belongs to exactly one entity
+	for other := range v.GetStringMap("entities") {
+		if other == name {
+			continue
+		}
+		key := "entities." + other + "." + field
+		if existing := v.GetStringSlice(key); len(existing) > 0 {
+			v.Set(key, removeIDs(existing, ids))
+		}
+	}
+
+	key := "entities." + name + "." + field
+	v.Set(key, appendIDs(v.GetStringSlice(key), ids))
+
+	if err := config.WriteConfig(v); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✓ Assigned %d %s(s) to %s\n", len(ids), strings.TrimSuffix(field, "s"), name)
+	}
+
+	return nil
+}
+
+// runEntityUnassign removes UUIDs from an entity
+func runEntityUnassign(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+	ids := args[1:]
+
+	v, err := loadViperConfig()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := v.GetStringMap("entities")[name]; !ok {
+		return fmt.Errorf("entity '%s' not found", name)
+	}
+
+	key := "entities." + name + "." + entityField()
+	v.Set(key, removeIDs(v.GetStringSlice(key), ids))
+
+	if err := config.WriteConfig(v); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✓ Removed %d id(s) from %s\n", len(ids), name)
+	}
+
+	return nil
+}
+
+// runEntityDefault sets default_entity
+func runEntityDefault(cmd *cobra.Command, args []string) error {
+	v, err := loadViperConfig()
+	if err != nil {
+		return err
+	}
+
+	v.Set("default_entity", strings.ToLower(args[0]))
+
+	if err := config.WriteConfig(v); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✓ Default entity set to %s\n", strings.ToLower(args[0]))
+	}
+
+	return nil
+}
+
+// =============================================================================
+// ENTITY UTILITIES
+// =============================================================================
+
+// entityField returns the config field the assign/unassign UUIDs belong to
+func entityField() string {
+	if entityTransactions {
+		return "transactions"
+	}
+	return "accounts"
+}
+
+// entityOnly keeps the transactions inEntity accepts (see config.EntityFilter)
+func entityOnly(transactions []blend.Transaction, inEntity func(transactionID, accountID string) bool) []blend.Transaction {
+	kept := make([]blend.Transaction, 0, len(transactions))
+	for _, txn := range transactions {
+		if inEntity(txn.UUID, txn.AccountID) {
+			kept = append(kept, txn)
+		}
+	}
+	return kept
+}
+
+// appendIDs appends ids that are not already present
+func appendIDs(existing, ids []string) []string {
+	for _, id := range ids {
+		if !containsString(existing, id) {
+			existing = append(existing, id)
+		}
+	}
+	return existing
+}
+
+// removeIDs returns existing without any of ids
+func removeIDs(existing, ids []string) []string {
+	kept := make([]string, 0, len(existing))
+	for _, id := range existing {
+		if !containsString(ids, id) {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,465 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/export"
+	"github.com/quickkly/fintrack/internal/staging"
+	"github.com/quickkly/fintrack/internal/store"
+	"github.com/quickkly/fintrack/internal/syncreport"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// EXPORT COMMAND DEFINITION
+// =============================================================================
+
+// exportCmd exports staged transactions to the configured targets
+var exportCmd = &cobra.Command{
+	Use:   "export [target...]",
+	Short: "Export staged transactions to configured targets",
+	Long: `Export staged transactions to the targets in the exports: config section
+(CSV, QIF and GnuCash files, webhooks). With no arguments every configured
+target is exported.
+
+QIF and GnuCash targets name accounts and categories through the ledger:
+config section, so desktop accounting tools receive their own account names.
+
+Targets run concurrently over a single snapshot of the staging directory. A
+failing target does not stop the others; failures are reported at the end.
+
+A target with an entity: key only receives the transactions belonging to
+that entity (see 'fintrack entity').
+
+Targets with incremental: true keep a high-water mark in the state directory
+(paths.state_dir) and only receive transactions fetched since their last successful export, so
+frequent scheduled exports are cheap and never send a transaction twice. Use
+--full to ignore the mark and export everything again.
+
+Example configuration:
+  exports:
+    spreadsheet:
+      type: csv
+      path: ./exports/transactions.csv
+      incremental: true
+    gnucash:
+      type: gnucash          # or qif
+      path: ./exports/gnucash.csv
+    ledger-hook:
+      type: webhook
+      url: https://example.com/hooks/fintrack
+      batch_size: 200
+    llp-books:
+      type: qif
+      path: ./exports/llp.qif
+      entity: llp            # Only the LLP's transactions
+
+Examples:
+  fintrack export                  # All targets
+  fintrack export spreadsheet      # One target
+  fintrack export --full           # Ignore incremental cursors
+  fintrack export spreadsheet --tag vacation --exclude-tag reimbursed
+
+Parquet files for analytics are written from the local store by 'fintrack
+export parquet', and time series for Grafana by 'fintrack export timeseries'.`,
+	RunE: runExport,
+}
+
+// exportParquetCmd writes the local store as partitioned Parquet files
+var exportParquetCmd = &cobra.Command{
+	Use:   "parquet",
+	Short: "Export the local store as partitioned Parquet files",
+	Long: `Write every transaction in the local store (store.path) as columnar Parquet
+files, for analysis in DuckDB, Pandas/PyArrow or Spark without parsing JSON.
+
+Files are partitioned Hive-style, so readers see year and month columns and
+skip partitions a query doesn't need:
+
+  exports/parquet/year=2024/month=03/transactions.parquet
+
+--partition-by year writes one file per year, none a single file. Each run
+rewrites the directory to match the store; partition files left from earlier
+runs are removed.
+
+Columns: uuid, date, timestamp (UTC), account_id, type, amount (signed,
+negative for outgoing), currency, mode, narration, category_id,
+subcategory_id, merchant, reference, tags (comma-separated), hidden,
+excluded_from_cash_flow, source, first_seen.
+
+Examples:
+  fintrack export parquet
+  fintrack export parquet --partition-by year --output-dir ~/data/fintrack
+
+  duckdb -c "SELECT year, month, sum(amount) FROM
+    read_parquet('exports/parquet/**/*.parquet', hive_partitioning = true)
+    GROUP BY ALL ORDER BY ALL"`,
+	Args: cobra.NoArgs,
+	RunE: runExportParquet,
+}
+
+// exportTimeseriesCmd writes balances and spending as time series points
+var exportTimeseriesCmd = &cobra.Command{
+	Use:     "timeseries",
+	Aliases: []string{"influx"},
+	Short:   "Export balances, net worth and daily spend as time series",
+	Long: `Write balances, net worth and daily spend per category as time series points,
+for Grafana dashboards over InfluxDB or Prometheus.
+
+Series:
+  fintrack_balance{account,currency}     Balance of each account at its last
+                                          fetch, from the last sync report
+  fintrack_net_worth{currency}           Sum of balances per currency
+  fintrack_spend{category,currency}      Outgoing amount per category and day,
+                                          in fx.base_currency, from the store
+
+Daily spend covers the last --days days (default timeseries.days) and leaves
+out transactions hidden in the app or excluded from cash flow, like reports.
+
+--format influx writes the InfluxDB line protocol with nanosecond timestamps.
+--format prometheus writes gauges in the Prometheus text format; it has no
+history, so spend is the total over the exported days. Run 'fintrack sync'
+first so balances are current.
+
+With --push the points are sent to timeseries.url instead of printed: a POST
+to an InfluxDB write endpoint, or a PUT to a Pushgateway job URL.
+
+Example configuration:
+  timeseries:
+    format: influx
+    url: http://localhost:8086/api/v2/write?org=home&bucket=finance
+    headers:
+      Authorization: Token my-influx-token
+
+Examples:
+  fintrack export timeseries > points.lp
+  fintrack export timeseries --push
+  fintrack export timeseries --format prometheus --days 1 --output /var/lib/node_exporter/fintrack.prom`,
+	Args: cobra.NoArgs,
+	RunE: runExportTimeseries,
+}
+
+var (
+	exportStagingDir string
+	exportFull       bool
+
+	exportTags, exportAnyTags, exportExcludeTags []string
+
+	parquetPartitionBy string
+	parquetOutputDir   string
+
+	timeseriesFormat string
+	timeseriesDays   int
+	timeseriesOutput string
+	timeseriesPush   bool
+)
+
+func init() {
+	exportCmd.Flags().StringVar(&exportStagingDir, "staging-dir", "", "Staging directory to export from (default: paths.staging_dir)")
+	exportCmd.Flags().BoolVar(&exportFull, "full", false, "Export everything, ignoring incremental targets' cursors")
+	tagFilterFlags(exportCmd, &exportTags, &exportAnyTags, &exportExcludeTags)
+
+	exportParquetCmd.Flags().StringVar(&parquetPartitionBy, "partition-by", "month", "Partitioning: month, year or none")
+	exportParquetCmd.Flags().StringVar(&parquetOutputDir, "output-dir", "./exports/parquet", "Directory to write the partitions to")
+	exportCmd.AddCommand(exportParquetCmd)
+
+	exportTimeseriesCmd.Flags().StringVar(&timeseriesFormat, "format", "", "Point format: influx or prometheus (default: timeseries.format)")
+	exportTimeseriesCmd.Flags().IntVar(&timeseriesDays, "days", 0, "Days of daily spend to export (default: timeseries.days)")
+	exportTimeseriesCmd.Flags().StringVar(&timeseriesOutput, "output", "", "File to write the points to (default: stdout)")
+	exportTimeseriesCmd.Flags().BoolVar(&timeseriesPush, "push", false, "Send the points to timeseries.url instead of writing them")
+	exportCmd.AddCommand(exportTimeseriesCmd)
+}
+
+// =============================================================================
+// EXPORT COMMAND IMPLEMENTATION
+// =============================================================================
+
+// runExport loads the staging snapshot once and fans it out to every target
+func runExport(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	targets, err := export.Targets(cfg, args)
+	if err != nil {
+		return err
+	}
+
+	tags, err := store.NewTagFilter(exportTags, exportAnyTags, exportExcludeTags)
+	if err != nil {
+		return err
+	}
+
+	dir := exportStagingDir
+	if dir == "" {
+		dir = cfg.Paths.StagingDir
+	}
+
+	snapshot, err := staging.LoadSnapshot(dir)
+	if err != nil {
+		return err
+	}
+
+	// Transactions removed upstream are kept in the store for audit only
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	snapshot.Transactions = st.FilterTags(st.WithoutDeleted(snapshot.Transactions), tags)
+
+	cursorPath, legacyCursorPath := export.CursorPath(cfg.Paths.StateDir), export.LegacyCursorPath(dir)
+	cursors, err := export.LoadCursors(cursorPath, legacyCursorPath)
+	if err != nil {
+		return err
+	}
+
+	jobs := make([]export.Job, 0, len(targets))
+	for _, target := range targets {
+		transactions := snapshot.Transactions
+		if target.Incremental && !exportFull {
+			transactions = snapshot.Since(cursors[target.Name].HighWater)
+		}
+		if target.Entity != "" {
+			inEntity, err := cfg.EntityFilter(target.Entity)
+			if err != nil {
+				return err
+			}
+			transactions = entityOnly(transactions, inEntity)
+		}
+		// A full export rewrites an appending CSV rather than duplicating rows
+		if csv, ok := target.Exporter.(*export.CSVExporter); ok && exportFull {
+			csv.Append = false
+		}
+		// --verbose shows the size of each webhook request
+		if webhook, ok := target.Exporter.(*export.WebhookExporter); ok && IsVerbose() {
+			name := target.Name
+			webhook.Logf = func(format string, args ...interface{}) {
+				fmt.Printf("  📦 "+name+" "+format, args...)
+			}
+		}
+		jobs = append(jobs, export.Job{Target: target, Transactions: transactions})
+	}
+
+	if IsDryRun() {
+		for _, job := range jobs {
+			fmt.Printf("🔍 [dry-run] Would export %d transaction(s) to %s (%s)\n", len(job.Transactions), job.Target.Name, job.Target.Type)
+		}
+		return nil
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("📤 Exporting %d transaction(s) to %d target(s)...\n", len(snapshot.Transactions), len(targets))
+	}
+
+	results := export.Run(context.Background(), jobs, exportProgress())
+
+	// Only targets that succeeded move their cursor forward
+	for i, result := range results {
+		if result.Err == nil && jobs[i].Target.Incremental {
+			cursors.Advance(result.Target, snapshot.LatestFetch, len(jobs[i].Transactions))
+		}
+	}
+	if err := cursors.Save(cursorPath); err != nil {
+		return err
+	}
+	// The cursors now live in the state directory
+	os.Remove(legacyCursorPath)
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", result.Target, result.Err)
+			continue
+		}
+		if !IsQuiet() {
+			fmt.Printf("✅ %s: %d transaction(s) in %s\n", result.Target, result.Count, result.Duration.Round(time.Millisecond))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d export target(s) failed", failed, len(results))
+	}
+	return nil
+}
+
+// runExportParquet writes the store's live transactions as Parquet partitions
+func runExportParquet(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	partitionBy := strings.ToLower(parquetPartitionBy)
+	if !slices.Contains(export.ParquetPartitions, partitionBy) {
+		return fmt.Errorf("invalid --partition-by %q (use %s)", parquetPartitionBy, strings.Join(export.ParquetPartitions, ", "))
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	records := st.Find(store.Query{})
+
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would export %d transaction(s) to %s, partitioned by %s\n", len(records), parquetOutputDir, partitionBy)
+		return nil
+	}
+
+	partitions, err := export.WriteParquet(parquetOutputDir, partitionBy, records, cfg.RoundingMode())
+	if err != nil {
+		return err
+	}
+
+	if IsVerbose() {
+		for _, partition := range partitions {
+			fmt.Printf("  📄 %s: %d row(s)\n", partition.Path, partition.Rows)
+		}
+	}
+	if !IsQuiet() {
+		fmt.Printf("✅ Exported %d transaction(s) to %d Parquet file(s) in %s\n", len(records), len(partitions), parquetOutputDir)
+	}
+	return nil
+}
+
+// runExportTimeseries writes or pushes the balance and spend series
+func runExportTimeseries(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	format := strings.ToLower(cfg.Timeseries.Format)
+	if timeseriesFormat != "" {
+		format = strings.ToLower(timeseriesFormat)
+	}
+	if !slices.Contains(config.TimeseriesFormats, format) {
+		return fmt.Errorf("invalid --format %q (use %s)", format, strings.Join(config.TimeseriesFormats, ", "))
+	}
+	days := cfg.Timeseries.Days
+	if timeseriesDays != 0 {
+		days = timeseriesDays
+	}
+	if days <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+	if timeseriesPush && cfg.Timeseries.URL == "" {
+		return fmt.Errorf("--push needs timeseries.url")
+	}
+	if timeseriesPush && timeseriesOutput != "" {
+		return fmt.Errorf("use either --push or --output")
+	}
+
+	// Balances come from the last sync; without one only spend is exported
+	lastSync, err := syncreport.Load(cfg.Sync.ReportFile)
+	if err != nil {
+		return err
+	}
+	if lastSync == nil {
+		fmt.Fprintf(os.Stderr, "⚠️  No sync report at %s, exporting spend only (run 'fintrack sync' for balances)\n", cfg.Sync.ReportFile)
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1-days)
+	spending, err := dailySpending(cfg, from, now)
+	if err != nil {
+		return err
+	}
+
+	points := export.TimeseriesPoints(lastSync, spending, cfg.FX.BaseCurrency, cfg.RoundingMode())
+	var buf bytes.Buffer
+	if format == "prometheus" {
+		err = export.WritePrometheus(&buf, points)
+	} else {
+		err = export.WriteInflux(&buf, points)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode time series: %w", err)
+	}
+
+	switch {
+	case timeseriesPush:
+		if IsDryRun() {
+			fmt.Printf("🔍 [dry-run] Would push %d point(s) to %s\n", len(points), cfg.Timeseries.URL)
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Bend.Timeout)
+		defer cancel()
+		if err := export.PushTimeseries(ctx, nil, cfg.Timeseries.URL, format, cfg.Timeseries.Headers, buf.Bytes()); err != nil {
+			return err
+		}
+		if !IsQuiet() {
+			fmt.Printf("✅ Pushed %d point(s) to %s\n", len(points), cfg.Timeseries.URL)
+		}
+
+	case timeseriesOutput != "":
+		if IsDryRun() {
+			fmt.Printf("🔍 [dry-run] Would write %d point(s) to %s\n", len(points), timeseriesOutput)
+			return nil
+		}
+		if err := os.WriteFile(timeseriesOutput, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", timeseriesOutput, err)
+		}
+		if !IsQuiet() {
+			fmt.Printf("✅ Wrote %d point(s) to %s\n", len(points), timeseriesOutput)
+		}
+
+	default:
+		os.Stdout.Write(buf.Bytes())
+	}
+	return nil
+}
+
+// dailySpending is the outgoing transactions between from and to that reports
+// count, in the base currency
+func dailySpending(cfg *config.Config, from, to time.Time) ([]blend.Transaction, error) {
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := blend.TransactionFilters{ExcludeCashflowExcluded: true}
+	var selected []blend.Transaction
+	for _, txn := range st.Transactions() {
+		if txn.Type != blend.TransactionTypeOutgoing || !flags.Allows(&txn) || cfg.AccountExcluded(txn.AccountID) {
+			continue
+		}
+		if txn.TxnTimestamp.Before(from) || txn.TxnTimestamp.After(to) {
+			continue
+		}
+		selected = append(selected, txn)
+	}
+	return inBaseCurrency(cfg, selected)
+}
+
+// exportProgress prints each target's progress in 25% steps
+func exportProgress() func(target string, done, total int) {
+	if IsQuiet() {
+		return nil
+	}
+
+	var mu sync.Mutex
+	reported := make(map[string]int)
+	return func(target string, done, total int) {
+		if total == 0 {
+			return
+		}
+		step := done * 4 / total
+		mu.Lock()
+		defer mu.Unlock()
+		if step > reported[target] && done < total {
+			reported[target] = step
+			fmt.Fprintf(os.Stderr, "  %s: %d/%d\n", target, done, total)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/report"
+	"github.com/quickkly/fintrack/internal/store"
+	"github.com/quickkly/fintrack/internal/syncreport"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// FORECAST COMMAND DEFINITIONS
+// =============================================================================
+
+// forecastCmd projects account balances forward
+var forecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Project account balances forward",
+	Long: `Project each account's balance --days ahead, starting from the balances in
+the last sync report. Recurring income (such as a salary) and recurring
+payments detected from the local store are applied on their expected dates,
+and the account's average daily discretionary spend over the last 90 days is
+taken off every day.
+
+Dates where an account may drop below forecast.threshold (or the account's
+accounts.settings.<id>.low_balance) are flagged.
+
+Examples:
+  fintrack forecast
+  fintrack forecast --days 60 --threshold 10000
+  fintrack forecast --verbose                 # Also list expected payments
+  fintrack forecast --output json             # Includes daily balances`,
+	Args: cobra.NoArgs,
+	RunE: runForecast,
+}
+
+var (
+	forecastDays      int
+	forecastThreshold string
+	forecastOutput    string
+)
+
+func init() {
+	forecastCmd.Flags().IntVar(&forecastDays, "days", 0, "How many days ahead to project (default forecast.days)")
+	forecastCmd.Flags().StringVar(&forecastThreshold, "threshold", "", "Warn below this balance for every account (default forecast.threshold)")
+	forecastCmd.Flags().StringVarP(&forecastOutput, "output", "o", "table", "Output format (table, json; default from display.output)")
+}
+
+// =============================================================================
+// FORECAST COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runForecast prints the projected balance of every synced account
+func runForecast(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	days := cfg.Forecast.Days
+	if forecastDays != 0 {
+		days = forecastDays
+	}
+	if days <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+	var threshold *money.Money
+	if forecastThreshold != "" {
+		m, err := money.ParseMoney(forecastThreshold, cfg.RoundingMode())
+		if err != nil {
+			return fmt.Errorf("invalid --threshold: %w", err)
+		}
+		threshold = &m
+	}
+
+	lastSync, err := syncreport.Load(cfg.Sync.ReportFile)
+	if err != nil {
+		return err
+	}
+	if lastSync == nil || len(lastSync.Balances) == 0 {
+		return fmt.Errorf("no account balances in %s; run 'fintrack sync' first", cfg.Sync.ReportFile)
+	}
+
+	var accounts []report.ForecastAccount
+	stale := make(map[string]bool)
+	for _, b := range lastSync.Balances {
+		low := money.FromFloat(cfg.LowBalanceFor(b.AccountID), cfg.RoundingMode())
+		if threshold != nil {
+			if threshold.Currency != "" && threshold.Currency != b.Currency {
+				continue
+			}
+			low = threshold.Amount
+		}
+		accounts = append(accounts, report.ForecastAccount{
+			AccountID: b.AccountID,
+			Currency:  b.Currency,
+			Balance:   money.FromFloat(b.Balance, cfg.RoundingMode()),
+			Threshold: low,
+		})
+		stale[b.AccountID] = b.Stale
+	}
+	if len(accounts) == 0 {
+		return fmt.Errorf("no %s accounts to forecast", threshold.Currency)
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	forecasts := report.Forecast(st.Transactions(), accounts, now, days, cfg.RoundingMode())
+
+	switch format := display.Output(cmd, cfg.Display); format {
+	case "table":
+		f := display.New(cfg.Display)
+		fmt.Printf("🔮 Balance forecast for the next %d days (from the sync at %s)\n\n", days, f.Date(lastSync.FinishedAt))
+		table := f.Table(
+			display.Column{Header: "Account"}, display.Column{Header: "Now", Right: true},
+			display.Column{Header: "Daily spend", Right: true}, display.Column{Header: "Lowest", Right: true},
+			display.Column{Header: "On"}, display.Column{Header: "In " + fmt.Sprint(days) + " days", Right: true})
+		for _, fc := range forecasts {
+			account := fc.AccountID
+			if stale[fc.AccountID] {
+				account += " (stale)"
+			}
+			table.Row(account, f.Amount(fc.Start, fc.Currency), f.Amount(fc.DailySpend, fc.Currency),
+				f.Amount(fc.Lowest, fc.Currency), f.Date(fc.LowestOn), f.Amount(fc.End, fc.Currency))
+		}
+		table.Render(os.Stdout)
+
+		if IsVerbose() {
+			for _, fc := range forecasts {
+				if len(fc.Events) == 0 {
+					continue
+				}
+				fmt.Printf("\n📅 Expected for %s:\n", fc.AccountID)
+				events := f.Table(display.Column{Header: "Date"}, display.Column{Header: "Name"},
+					display.Column{Header: "Kind"}, display.Column{Header: "Amount", Right: true})
+				for _, event := range fc.Events {
+					events.Row(f.Date(event.Date), event.Name, event.Kind, f.Amount(event.Amount, fc.Currency))
+				}
+				events.Render(os.Stdout)
+			}
+		}
+
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		warned := false
+		for _, fc := range forecasts {
+			if fc.BelowOn == nil {
+				continue
+			}
+			if !warned {
+				fmt.Println()
+				warned = true
+			}
+			fmt.Printf("⚠️  %s may drop below %s on %s (in %s, lowest %s on %s, %d day(s) below)\n", fc.AccountID,
+				f.Amount(fc.Threshold, fc.Currency), f.Date(*fc.BelowOn), dueIn(*fc.BelowOn, today),
+				f.Amount(fc.Lowest, fc.Currency), f.Date(fc.LowestOn), fc.BelowDays)
+		}
+		if !warned && !IsQuiet() {
+			fmt.Println("\n✅ No account is projected to drop below its threshold")
+		}
+
+	case "json":
+		data, err := json.MarshalIndent(forecasts, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal forecast to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table or json", format)
+	}
+	return nil
+}
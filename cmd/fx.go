@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/fx"
+	"github.com/quickkly/fintrack/internal/money"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// FX COMMAND DEFINITIONS
+// =============================================================================
+
+// fxCmd represents the fx command
+var fxCmd = &cobra.Command{
+	Use:   "fx",
+	Short: "Historical exchange rates",
+	Long: `Look up and convert with historical exchange rates.
+
+Rates are taken as of the given date (not today's rate) and cached locally
+in fx.cache_file, so converting old transactions is both accurate and fast.
+
+Available subcommands:
+- rate: Show the rate between two currencies on a date
+- convert: Convert an amount at the rate on a date`,
+}
+
+// fxRateCmd shows a historical rate
+var fxRateCmd = &cobra.Command{
+	Use:   "rate <from> [to]",
+	Short: "Show the exchange rate on a date",
+	Args:  cobra.RangeArgs(1, 2),
+	Example: `  fintrack fx rate USD INR --date 2023-06-15
+  fintrack fx rate EUR          # Into fx.base_currency, today`,
+	RunE: runFXRate,
+}
+
+// fxConvertCmd converts an amount
+var fxConvertCmd = &cobra.Command{
+	Use:     "convert <amount> <from> [to]",
+	Short:   "Convert an amount at the rate on a date",
+	Args:    cobra.RangeArgs(2, 3),
+	Example: `  fintrack fx convert 49.99 USD INR --date 2023-06-15`,
+	RunE:    runFXConvert,
+}
+
+var fxDate string
+
+func init() {
+	fxRateCmd.Flags().StringVar(&fxDate, "date", "", "Date of the rate (YYYY-MM-DD, default: today)")
+	fxConvertCmd.Flags().StringVar(&fxDate, "date", "", "Date of the rate (YYYY-MM-DD, default: today)")
+
+	fxCmd.AddCommand(fxRateCmd)
+	fxCmd.AddCommand(fxConvertCmd)
+}
+
+// =============================================================================
+// FX COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runFXRate prints the from→to rate on the requested date
+func runFXRate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	from, to := fxCurrencies(cfg, args)
+	date, err := parseFXDate()
+	if err != nil {
+		return err
+	}
+
+	converter, err := fx.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	rate, err := converter.Rate(date, from, to)
+	if err != nil {
+		return err
+	}
+
+	if err := converter.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("1 %s = %.6f %s on %s\n", from, rate, to, display.New(cfg.Display).Date(date))
+	return nil
+}
+
+// runFXConvert converts an amount at the rate on the requested date
+func runFXConvert(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	amount, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %q: %w", args[0], err)
+	}
+
+	from, to := fxCurrencies(cfg, args[1:])
+	date, err := parseFXDate()
+	if err != nil {
+		return err
+	}
+
+	converter, err := fx.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	converted, err := converter.Convert(amount, from, to, date)
+	if err != nil {
+		return err
+	}
+
+	if err := converter.Close(); err != nil {
+		return err
+	}
+
+	f := display.New(cfg.Display)
+	mode := cfg.RoundingMode()
+	fmt.Printf("%s = %s on %s\n", f.Amount(money.FromFloat(amount, mode), from), f.Amount(money.FromFloat(converted, mode), to), f.Date(date))
+	return nil
+}
+
+// =============================================================================
+// FX UTILITIES
+// =============================================================================
+
+// fxCurrencies returns the from/to currencies, defaulting to the base currency
+func fxCurrencies(cfg *config.Config, args []string) (string, string) {
+	from := strings.ToUpper(args[0])
+	to := strings.ToUpper(cfg.FX.BaseCurrency)
+	if len(args) > 1 {
+		to = strings.ToUpper(args[1])
+	}
+	return from, to
+}
+
+// parseFXDate parses --date, defaulting to today
+func parseFXDate() (time.Time, error) {
+	if fxDate == "" {
+		return time.Now(), nil
+	}
+	date, err := time.Parse("2006-01-02", fxDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD)", fxDate)
+	}
+	return date, nil
+}
+
+// inBaseCurrency converts transactions into fx.base_currency at their
+// historical rates for reports. Transactions whose rate can't be fetched are
+// left out with a warning rather than failing the report.
+func inBaseCurrency(cfg *config.Config, transactions []blend.Transaction) ([]blend.Transaction, error) {
+	base := cfg.FX.BaseCurrency
+	foreign := 0
+	for _, txn := range transactions {
+		if txn.Currency != "" && !strings.EqualFold(txn.Currency, base) {
+			foreign++
+		}
+	}
+	if foreign == 0 {
+		return transactions, nil
+	}
+
+	converter, err := fx.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]blend.Transaction, 0, len(transactions))
+	var failed []error
+	for _, txn := range transactions {
+		txn, err := converter.ConvertTransaction(txn, base)
+		if err != nil {
+			failed = append(failed, err)
+			continue
+		}
+		converted = append(converted, txn)
+	}
+
+	if err := converter.Close(); err != nil {
+		return nil, err
+	}
+
+	if len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  Left out %d of %d foreign-currency transaction(s) that couldn't be converted to %s: %v\n",
+			len(failed), foreign, base, failed[0])
+	} else if IsVerbose() {
+		fmt.Fprintf(os.Stderr, "💱 Converted %d foreign-currency transaction(s) to %s at historical rates\n", foreign, base)
+	}
+	return converted, nil
+}
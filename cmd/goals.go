@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/exitcode"
+	"github.com/quickkly/fintrack/internal/report"
+	"github.com/quickkly/fintrack/internal/store"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// GOALS COMMAND DEFINITIONS
+// =============================================================================
+
+// goalsCmd shows savings goal progress
+var goalsCmd = &cobra.Command{
+	Use:   "goals",
+	Short: "Track savings goals",
+	Long: `Track savings goals such as "save 50,000 by December".
+
+A goal is tied to accounts, categories or both. Money counts towards it when
+it flows into one of its accounts (net of outflows), or is paid out in one of
+its categories (e.g. an investments category), between its start date and
+deadline. A goal with an entity (see 'fintrack entity') only counts that
+entity's transactions. Progress is computed from the local store (store.path) in
+fx.base_currency.
+
+Without a subcommand, shows the status of every goal: saved so far, the
+monthly pace required to reach the target, and the pace actually achieved.
+
+Available subcommands:
+- status: Show progress of every goal (default)
+- add: Add or replace a goal
+- remove: Remove a goal`,
+	Args: cobra.NoArgs,
+	RunE: runGoalsStatus,
+}
+
+// goalsStatusCmd shows savings goal progress
+var goalsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show progress of every goal",
+	Args:  cobra.NoArgs,
+	RunE:  runGoalsStatus,
+}
+
+// goalsAddCmd adds a goal to the config file
+var goalsAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add or replace a savings goal",
+	Args:  cobra.ExactArgs(1),
+	Example: `  fintrack goals add emergency-fund --target 50000 --by 2024-12 --account-id 6f1c...-uuid
+  fintrack goals add retirement --target 300000 --by 2025-03-31 --category-id investments --start 2024-04-01`,
+	RunE: runGoalsAdd,
+}
+
+// goalsRemoveCmd removes a goal from the config file
+var goalsRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a savings goal",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runGoalsRemove,
+}
+
+var (
+	goalTarget     float64
+	goalBy         string
+	goalStart      string
+	goalAccounts   []string
+	goalCategories []string
+	goalEntity     string
+	goalsOutput    string
+	goalsEntity    string
+)
+
+func init() {
+	goalsAddCmd.Flags().Float64Var(&goalTarget, "target", 0, "Amount to save, in fx.base_currency (required)")
+	goalsAddCmd.Flags().StringVar(&goalBy, "by", "", "Deadline: YYYY-MM-DD, or YYYY-MM for the end of that month (required)")
+	goalsAddCmd.Flags().StringVar(&goalStart, "start", "", "Date saving started (YYYY-MM-DD, default: today)")
+	goalsAddCmd.Flags().StringSliceVar(&goalAccounts, "account-id", nil, "Account UUID whose net inflow counts (repeatable)")
+	goalsAddCmd.Flags().StringSliceVar(&goalCategories, "category-id", nil, "Category ID whose payments count (repeatable)")
+	goalsAddCmd.Flags().StringVar(&goalEntity, "entity", "", "Only count transactions belonging to this entity")
+	goalsAddCmd.MarkFlagRequired("target")
+	goalsAddCmd.MarkFlagRequired("by")
+
+	for _, c := range []*cobra.Command{goalsCmd, goalsStatusCmd} {
+		c.Flags().StringVarP(&goalsOutput, "output", "o", "table", "Output format (table, json; default from display.output)")
+		c.Flags().StringVar(&goalsEntity, "entity", "", "Only show the goals of this entity")
+	}
+
+	goalsCmd.AddCommand(goalsStatusCmd)
+	goalsCmd.AddCommand(goalsAddCmd)
+	goalsCmd.AddCommand(goalsRemoveCmd)
+}
+
+// =============================================================================
+// GOALS COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runGoalsStatus prints the progress of every goal
+func runGoalsStatus(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	if len(cfg.Goals) == 0 {
+		fmt.Println("No savings goals. Use 'fintrack goals add' to add one")
+		return nil
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+
+	if goalsEntity != "" {
+		if err := cfg.ValidateEntity(goalsEntity); err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+	}
+
+	inBase, err := inBaseCurrency(cfg, st.Transactions())
+	if err != nil {
+		return err
+	}
+
+	names := goalNames(cfg, goalsEntity)
+	now := time.Now()
+	progress := make([]report.GoalProgress, 0, len(names))
+	for _, name := range names {
+		p, err := goalProgress(cfg, name, inBase, now)
+		if err != nil {
+			return err
+		}
+		progress = append(progress, p)
+	}
+
+	switch format := display.Output(cmd, cfg.Display); format {
+	case "table":
+		base := cfg.FX.BaseCurrency
+		f := display.New(cfg.Display)
+		table := f.Table(
+			display.Column{Header: "Goal"}, display.Column{Header: "Saved", Right: true}, display.Column{Header: "Target", Right: true},
+			display.Column{Header: "%", Right: true}, display.Column{Header: "By"}, display.Column{Header: "Need/Month", Right: true},
+			display.Column{Header: "Actual/Month", Right: true}, display.Column{Header: "Status"})
+		for _, p := range progress {
+			table.Row(p.Name, f.Amount(p.Saved, base), f.Amount(p.Target, base), fmt.Sprintf("%.0f%%", p.Percent()),
+				f.Date(p.By), f.Amount(p.RequiredPace, base), f.Amount(p.ActualPace, base), goalStatusIcon(p.Status)+" "+p.Status)
+		}
+		table.Render(os.Stdout)
+
+	case "json":
+		data, err := json.MarshalIndent(progress, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal goals to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table or json", format)
+	}
+
+	return nil
+}
+
+// goalStatusIcon is the emoji shown next to a goal status
+func goalStatusIcon(status string) string {
+	switch status {
+	case report.GoalAchieved:
+		return "🎉"
+	case report.GoalOnTrack:
+		return "✅"
+	case report.GoalBehind:
+		return "⚠️"
+	default:
+		return "❌"
+	}
+}
+
+// runGoalsAdd writes a goal to the config file
+func runGoalsAdd(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+
+	by := goalBy
+	if month, err := time.ParseInLocation("2006-01", goalBy, time.Local); err == nil {
+		by = month.AddDate(0, 1, -1).Format("2006-01-02")
+	}
+	start := goalStart
+	if start == "" {
+		start = time.Now().Format("2006-01-02")
+	}
+
+	goal := config.GoalConfig{
+		Target:     goalTarget,
+		By:         by,
+		StartDate:  start,
+		Accounts:   goalAccounts,
+		Categories: goalCategories,
+		Entity:     strings.ToLower(goalEntity),
+	}
+	if err := goal.Validate(); err != nil {
+		return err
+	}
+	if goal.Entity != "" {
+		cfg, err := config.GetFromContext(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get configuration: %w", err)
+		}
+		if err := cfg.ValidateEntity(goal.Entity); err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+	}
+
+	v, err := loadViperConfig()
+	if err != nil {
+		return err
+	}
+
+	// Replace rather than merge with an existing goal of the same name
+	key := "goals." + name
+	values := map[string]interface{}{
+		"target":     goal.Target,
+		"by":         goal.By,
+		"start_date": goal.StartDate,
+		"accounts":   goal.Accounts,
+		"categories": goal.Categories,
+	}
+	if goal.Entity != "" {
+		values["entity"] = goal.Entity
+	}
+	v.Set(key, values)
+
+	if err := config.WriteConfig(v); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✓ Goal %s: save %.2f from %s by %s\n", name, goal.Target, goal.StartDate, goal.By)
+	}
+	return nil
+}
+
+// runGoalsRemove deletes a goal from the config file
+func runGoalsRemove(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+
+	v, err := loadViperConfig()
+	if err != nil {
+		return err
+	}
+
+	configPath := v.ConfigFileUsed()
+	if configPath == "" {
+		return fmt.Errorf("no config file found")
+	}
+
+	found, err := deleteConfigKey(configPath, "goals."+name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no goal named %s", name)
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✓ Removed goal %s\n", name)
+	}
+	return nil
+}
+
+// goalNames returns the goal names in sorted order, only those of entity when
+// one is given
+func goalNames(cfg *config.Config, entity string) []string {
+	names := make([]string, 0, len(cfg.Goals))
+	for name, goal := range cfg.Goals {
+		if entity == "" || strings.EqualFold(goal.Entity, entity) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// goalProgress computes a goal's progress at now from base-currency
+// transactions, counting only its entity's when it has one
+func goalProgress(cfg *config.Config, name string, inBase []blend.Transaction, now time.Time) (report.GoalProgress, error) {
+	goal := cfg.Goals[name]
+	if goal.Entity != "" {
+		inEntity, err := cfg.EntityFilter(goal.Entity)
+		if err != nil {
+			return report.GoalProgress{}, fmt.Errorf("goal %s: %w", name, err)
+		}
+		inBase = entityOnly(inBase, inEntity)
+	}
+	p, err := report.Goal(name, goal, inBase, now, cfg.RoundingMode())
+	if err != nil {
+		return report.GoalProgress{}, fmt.Errorf("goal %s: %w", name, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/syncreport"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// HEALTHZ COMMAND DEFINITION
+// =============================================================================
+
+// healthzCmd is a liveness/readiness probe for unattended deployments
+var healthzCmd = &cobra.Command{
+	Use:   "healthz",
+	Short: "Exit 0 only if fintrack is healthy (for container probes)",
+	Long: `Check that fintrack can run unattended and exit non-zero if not:
+
+- the configuration loads and validates
+- the Bend session is valid, or can be renewed with a refresh token
+- the last sync succeeded within sync.max_age (25h unless configured)
+- no API endpoint's circuit is open (see bend.circuit_threshold)
+
+Use it as a Docker HEALTHCHECK or a Kubernetes liveness/readiness probe:
+
+  HEALTHCHECK CMD fintrack healthz --quiet
+
+Examples:
+  fintrack healthz
+  fintrack healthz --max-age 2h`,
+	Args: cobra.NoArgs,
+	RunE: runHealthz,
+}
+
+var healthzMaxAge time.Duration
+
+func init() {
+	healthzCmd.Flags().DurationVar(&healthzMaxAge, "max-age", 0, "Oldest acceptable successful sync (default: sync.max_age)")
+}
+
+// =============================================================================
+// HEALTHZ COMMAND IMPLEMENTATION
+// =============================================================================
+
+// runHealthz runs every check and fails if any of them failed. Configuration
+// problems already fail while the root command loads the config.
+func runHealthz(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+	healthzResult("config", nil)
+
+	failed := 0
+	for _, check := range []struct {
+		name string
+		run  func(*config.Config) error
+	}{
+		{"session", checkSessionHealth},
+		{"sync", checkSyncHealth},
+		{"circuit", checkCircuitHealth},
+	} {
+		err := check.run(cfg)
+		healthzResult(check.name, err)
+		if err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d health check(s) failed", failed)
+	}
+	return nil
+}
+
+// healthzResult prints the outcome of one check
+func healthzResult(name string, err error) {
+	if IsQuiet() {
+		return
+	}
+	if err != nil {
+		fmt.Printf("❌ %-8s %v\n", name, err)
+		return
+	}
+	fmt.Printf("✅ %-8s ok\n", name)
+}
+
+// checkSessionHealth passes if the session is valid or can be renewed
+func checkSessionHealth(cfg *config.Config) error {
+	info, err := blend.NewSessionManager(cfg.Bend.SessionFile).GetSessionInfo()
+	if err != nil {
+		return fmt.Errorf("failed to get session info: %w", err)
+	}
+
+	switch {
+	case info.Valid:
+		return nil
+	case info.HasRefreshToken || (cfg.Bend.RefreshToken != "" && cfg.Session == ""):
+		return nil // Renewed by the next sync
+	case !info.Exists:
+		return fmt.Errorf("no session and no refresh token")
+	default:
+		return fmt.Errorf("session expired and no refresh token")
+	}
+}
+
+// checkSyncHealth passes if the last successful sync is recent enough
+func checkSyncHealth(cfg *config.Config) error {
+	maxAge := healthzMaxAge
+	if maxAge <= 0 {
+		maxAge = cfg.Sync.MaxAge
+	}
+
+	report, err := syncreport.Load(cfg.Sync.ReportFile)
+	if err != nil {
+		return err
+	}
+	if report == nil || report.LastSuccess.IsZero() {
+		return fmt.Errorf("no successful sync recorded in %s", cfg.Sync.ReportFile)
+	}
+
+	if !report.Success {
+		reason := "unknown error"
+		if len(report.Errors) > 0 {
+			reason = report.Errors[len(report.Errors)-1]
+		}
+		return fmt.Errorf("last sync at %s failed: %s", report.FinishedAt.Format("2006-01-02 15:04"), reason)
+	}
+
+	age := time.Since(report.LastSuccess)
+	if maxAge > 0 && age > maxAge {
+		return fmt.Errorf("last successful sync was %s ago (max %s)", age.Round(time.Minute), maxAge)
+	}
+	return nil
+}
+
+// checkCircuitHealth passes unless a sync left an endpoint's circuit open
+func checkCircuitHealth(cfg *config.Config) error {
+	circuits, err := blend.LoadCircuits(cfg.Bend.CircuitFile)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, c := range circuits {
+		if c.Open(now) {
+			return fmt.Errorf("circuit open for %s until %s: %s", c.Endpoint, c.OpenUntil.Format("15:04"), c.LastError)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/store"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// HISTORY COMMAND DEFINITIONS
+// =============================================================================
+
+// historyCmd lists the undo log
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List local changes to the store that can be undone",
+	Long: `List the operations that changed the local store, newest first: imports,
+staging migrations, tag changes and splits. Syncs from Bend aren't listed;
+re-syncing brings back what Bend returns anyway.
+
+The log is kept in the store itself (encrypted with it) and holds the last
+200 operations. Undo one with 'fintrack undo <id>'.
+
+Examples:
+  fintrack history
+  fintrack history --limit 5
+  fintrack history --verbose                   # List the transactions each changed
+  fintrack history --output json`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+// undoCmd reverts a logged operation
+var undoCmd = &cobra.Command{
+	Use:   "undo <op-id>",
+	Short: "Revert an operation from the history",
+	Long: `Put back the transactions an operation changed, as they were before it:
+imported transactions are removed, tags and splits restored, and migrated
+staging files can be migrated again.
+
+The undo is logged as an operation of its own, so undoing it redoes the
+original. Transactions changed since the operation (a later tag, or a sync)
+would be overwritten, so undo refuses unless --force is given.
+
+Examples:
+  fintrack undo 12
+  fintrack undo 12 --dry-run                   # Show what would be reverted
+  fintrack undo 12 --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUndo,
+}
+
+var (
+	historyLimit  int
+	historyOutput string
+	undoForce     bool
+)
+
+func init() {
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Show at most this many operations (0 for all)")
+	historyCmd.Flags().StringVarP(&historyOutput, "output", "o", "table", "Output format (table, json; default from display.output)")
+
+	undoCmd.Flags().BoolVar(&undoForce, "force", false, "Undo even if transactions changed since the operation")
+}
+
+// =============================================================================
+// HISTORY COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runHistory prints the logged operations, newest first
+func runHistory(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+
+	ops := make([]store.Operation, 0, len(st.History))
+	for i := len(st.History) - 1; i >= 0; i-- {
+		if historyLimit > 0 && len(ops) == historyLimit {
+			break
+		}
+		ops = append(ops, st.History[i])
+	}
+
+	switch format := display.Output(cmd, cfg.Display); format {
+	case "table":
+		if len(ops) == 0 {
+			fmt.Println("📭 No local changes recorded yet")
+			return nil
+		}
+		f := display.New(cfg.Display)
+		table := f.Table(display.Column{Header: "ID", Right: true}, display.Column{Header: "When"},
+			display.Column{Header: "Summary"}, display.Column{Header: "Txns", Right: true}, display.Column{Header: "Status"})
+		for _, op := range ops {
+			status := ""
+			if undo := st.UndoneBy(op.ID); undo != nil {
+				status = fmt.Sprintf("undone by #%d", undo.ID)
+			}
+			table.Row(fmt.Sprint(op.ID), op.At.Local().Format("2006-01-02 15:04"), op.Summary, fmt.Sprint(len(op.Changes)), status)
+		}
+		table.Render(os.Stdout)
+
+		if IsVerbose() {
+			for _, op := range ops {
+				fmt.Printf("\n#%d %s\n", op.ID, op.Command)
+				for _, change := range op.Changes {
+					fmt.Printf("  %s %s\n", describeChange(change), change.UUID)
+				}
+				for _, name := range op.Imported {
+					fmt.Printf("  📄 %s\n", name)
+				}
+			}
+		}
+
+	case "json":
+		data, err := json.MarshalIndent(ops, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal history to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table or json", format)
+	}
+	return nil
+}
+
+// runUndo reverts an operation and saves the store
+func runUndo(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return fmt.Errorf("invalid operation id %q: use the number 'fintrack history' shows", args[0])
+	}
+
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+
+	var summary string
+	var staged int
+	if op := st.Operation(id); op != nil {
+		summary, staged = op.Summary, len(op.Imported)
+	}
+	undo, err := st.Undo(id, commandLine(), undoForce, time.Now())
+	if err != nil {
+		return err
+	}
+	if undo == nil {
+		fmt.Printf("✅ Nothing to undo: the store already matches operation %d's starting point\n", id)
+		return nil
+	}
+
+	if IsVerbose() || IsDryRun() {
+		for _, change := range undo.Changes {
+			fmt.Printf("  %s %s\n", describeChange(change), change.UUID)
+		}
+	}
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would revert %d transaction(s) and %d staging file(s) from operation %d\n", len(undo.Changes), staged, id)
+		return nil
+	}
+
+	if err := st.Save(); err != nil {
+		return err
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("↩️  Undid operation %d (%s): %d transaction(s) reverted\n", id, summary, len(undo.Changes))
+		fmt.Printf("💡 Logged as operation %d; 'fintrack undo %d' redoes it\n", undo.ID, undo.ID)
+	}
+	return nil
+}
+
+// saveWithHistory logs what changed since checkpoint as an operation and
+// saves the store
+func saveWithHistory(st *store.Store, checkpoint *store.Checkpoint, summary string) error {
+	if _, err := st.Record(commandLine(), summary, checkpoint, time.Now()); err != nil {
+		return err
+	}
+	return st.Save()
+}
+
+// commandLine is the command being run, for the history
+func commandLine() string {
+	return strings.Join(append([]string{"fintrack"}, os.Args[1:]...), " ")
+}
+
+// describeChange says what an undo log entry did to its transaction
+func describeChange(change store.Change) string {
+	switch {
+	case change.Before == nil:
+		return "➕ added"
+	case change.After == "":
+		return "➖ removed"
+	default:
+		return "✏️  changed"
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/quickkly/fintrack/internal/ignore"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// IGNORE COMMAND DEFINITIONS
+// =============================================================================
+
+// ignoreCmd groups the .fintrackignore commands
+var ignoreCmd = &cobra.Command{
+	Use:   "ignore",
+	Short: "Inspect the .fintrackignore file",
+	Long: `A .fintrackignore file, in gitignore syntax, lists files that commands
+scanning directories leave out: 'import --dir', 'migrate staging' and
+'backup create --receipts'. Files named on the command line are never
+ignored.
+
+fintrack uses the .fintrackignore in the working directory or the nearest
+directory above it, as created by 'fintrack init'. Patterns with a "/" are
+relative to that directory; others match a file or directory name anywhere,
+including in directories outside the project like the staging directory.
+
+Available subcommands:
+- check: Show whether paths are ignored, and by which pattern`,
+}
+
+// ignoreCheckCmd reports the rule matching each path
+var ignoreCheckCmd = &cobra.Command{
+	Use:   "check <path>...",
+	Short: "Show whether paths are ignored, and by which pattern",
+	Args:  cobra.MinimumNArgs(1),
+	Example: `  fintrack ignore check statements/2024-01.csv
+  fintrack ignore check ~/.local/share/fintrack/staging/transactions_20240101.json`,
+	RunE: runIgnoreCheck,
+}
+
+func init() {
+	ignoreCmd.AddCommand(ignoreCheckCmd)
+}
+
+// =============================================================================
+// IGNORE COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runIgnoreCheck prints, for each path, the pattern deciding whether it is
+// ignored
+func runIgnoreCheck(cmd *cobra.Command, args []string) error {
+	matcher, err := loadIgnore()
+	if err != nil {
+		return err
+	}
+	if matcher.File == "" {
+		fmt.Printf("ℹ️  No %s found in this directory or above it; nothing is ignored\n", ignore.FileName)
+		return nil
+	}
+	if IsVerbose() {
+		fmt.Printf("📄 Using %s (%d pattern(s))\n", matcher.File, len(matcher.Rules))
+	}
+
+	for _, path := range args {
+		isDir := strings.HasSuffix(path, "/")
+		if info, err := os.Stat(path); err == nil {
+			isDir = info.IsDir()
+		}
+
+		rule := matcher.Match(path, isDir)
+		switch {
+		case rule == nil:
+			fmt.Printf("✅ %s: not ignored\n", path)
+		case rule.Negate:
+			fmt.Printf("✅ %s: not ignored (%s:%d: !%s)\n", path, matcher.File, rule.Line, rule.Pattern)
+		default:
+			fmt.Printf("🚫 %s: ignored (%s:%d: %s)\n", path, matcher.File, rule.Line, rule.Pattern)
+		}
+	}
+	return nil
+}
+
+// loadIgnore loads the .fintrackignore that applies to the working directory
+func loadIgnore() (*ignore.Matcher, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	return ignore.Find(dir)
+}
+
+// reportIgnored tells how many files a scan left out because of the ignore
+// file
+func reportIgnored(matcher *ignore.Matcher, count int) {
+	if count == 0 || IsQuiet() {
+		return
+	}
+	fmt.Printf("🚫 Skipped %d file(s) matching %s ('fintrack ignore check <path>' shows why)\n", count, matcher.File)
+}
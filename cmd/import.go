@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/importer"
+	"github.com/quickkly/fintrack/internal/plugin"
+	"github.com/quickkly/fintrack/internal/store"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// IMPORT COMMAND DEFINITION
+// =============================================================================
+
+// importCmd imports statement files into the store
+var importCmd = &cobra.Command{
+	Use:   "import <file>...",
+	Short: "Import bank statement files into the local store",
+	Long: `Import statement files (bank CSV, OFX/QFX, QIF, MT940, camt.053) into the
+local store, for accounts Bend doesn't cover. The format is detected from each file's
+content and name; --format names it explicitly.
+
+The built-in CSV importer finds the header row and the date, narration,
+amount (or debit and credit) columns by their usual names. Banks whose CSVs
+it can't read, and entirely different formats, are added under importers:
+a csv importer maps the columns by name, a command importer runs a program
+that turns the file into JSON. Custom importers are tried before the
+built-in ones.
+
+Each transaction gets an ID derived from its content, so re-importing a
+statement, or one overlapping an earlier one, adds nothing twice. Imported
+transactions are kept by 'fintrack sync'.
+
+--dir imports every statement file under a directory, leaving out files
+matching .fintrackignore and files in no format fintrack knows.
+
+Examples:
+  fintrack import statement.csv
+  fintrack import ~/Downloads/*.ofx --account-id savings
+  fintrack import nre-statement.sta camt053.xml
+  fintrack import export.txt --format hdfc --dry-run
+  fintrack import --dir ~/statements
+  fintrack import --list-formats`,
+	RunE: runImport,
+}
+
+var (
+	importFormat      string
+	importAccountID   string
+	importCurrency    string
+	importListFormats bool
+	importDirs        []string
+)
+
+func init() {
+	importCmd.Flags().StringVar(&importFormat, "format", "", "Statement format (default: detected)")
+	importCmd.Flags().StringVar(&importAccountID, "account-id", "", "Account to file the transactions under (default: from the file, or its name)")
+	importCmd.Flags().StringVar(&importCurrency, "currency", "", "Currency of files that don't say (default: fx.base_currency)")
+	importCmd.Flags().BoolVar(&importListFormats, "list-formats", false, "List the available formats in detection order")
+	importCmd.Flags().StringArrayVar(&importDirs, "dir", nil, "Import the statement files under a directory (repeatable)")
+}
+
+// =============================================================================
+// IMPORT COMMAND IMPLEMENTATION
+// =============================================================================
+
+// runImport imports each file into the store
+func runImport(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	if err := registerImporters(cfg); err != nil {
+		return err
+	}
+	if importListFormats {
+		for _, name := range importer.Names() {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	// Files found under --dir, skipped rather than failing the import when
+	// they aren't statements
+	scanned := make(map[string]bool)
+	for _, dir := range importDirs {
+		files, err := scanImportDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, path := range files {
+			scanned[path] = true
+		}
+		args = append(args, files...)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("no files to import")
+	}
+
+	var forced importer.Importer
+	if importFormat != "" {
+		if forced, err = importer.Lookup(importFormat); err != nil {
+			return err
+		}
+	}
+
+	currency := strings.ToUpper(importCurrency)
+	if currency == "" {
+		currency = cfg.FX.BaseCurrency
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := st.Checkpoint()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var total store.UpsertResult
+	imported := 0
+	for _, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		imp := forced
+		if imp == nil {
+			if imp, err = importer.Detect(data, path); err != nil {
+				if scanned[path] {
+					if IsVerbose() {
+						fmt.Printf("⚠️  Skipping %s: %v\n", path, err)
+					}
+					continue
+				}
+				return err
+			}
+		}
+
+		transactions, err := importer.Read(imp, data, importer.Options{
+			Path:      path,
+			AccountID: importAccountID,
+			Currency:  currency,
+		})
+		if err != nil {
+			return err
+		}
+		if transactions, err = plugin.Enrich(cmd.Context(), transactions); err != nil {
+			return err
+		}
+
+		result := st.Upsert(transactions, now, store.ImportSourcePrefix+filepath.Base(path))
+		total.Add(result)
+		imported++
+		fmt.Printf("📄 %s (%s): %d transaction(s), %d new, %d changed, %d duplicate\n",
+			filepath.Base(path), imp.Name(), len(transactions), result.New, result.Changed, result.Unchanged)
+	}
+
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would import %d new and %d changed transaction(s) into %s\n", total.New, total.Changed, st.Path())
+		return nil
+	}
+
+	summary := fmt.Sprintf("import %d file(s): %d new, %d changed", imported, total.New, total.Changed)
+	if err := saveWithHistory(st, checkpoint, summary); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Imported %d file(s) into %s: %d new, %d changed, %d duplicate\n",
+		imported, st.Path(), total.New, total.Changed, total.Unchanged)
+	fmt.Printf("💾 Store now holds %d transaction(s)\n", st.Len())
+	return nil
+}
+
+// scanImportDir returns the files under dir in name order, leaving out
+// the ones .fintrackignore matches
+func scanImportDir(dir string) ([]string, error) {
+	matcher, err := loadIgnore()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	ignored := 0
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && matcher.Ignored(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			ignored++
+			return nil
+		}
+		if d.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	reportIgnored(matcher, ignored)
+	return files, nil
+}
+
+// registerImporters adds the custom importers from the configuration
+func registerImporters(cfg *config.Config) error {
+	for _, name := range cfg.ImporterNames() {
+		imp, err := importer.FromConfig(name, cfg.Importers[name])
+		if err != nil {
+			return err
+		}
+		importer.Register(imp)
+	}
+	return nil
+}
@@ -4,6 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/quickkly/fintrack/cmd/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/store"
 
 	"github.com/spf13/cobra"
 )
@@ -27,9 +34,15 @@ This command will:
 The configuration will be created with default values suitable for most users.
 You can customize it later using 'fintrack config set' commands.
 
+With --interactive, a setup wizard asks for the Bend base URL, device type,
+login method (OTP, email and password, or a refresh token), storage backend
+and budget defaults, writes them to the config and logs in at the end, so the
+next step is the first 'fintrack sync'.
+
 Examples:
   fintrack init                    # Initialize in current directory
-  fintrack init /path/to/project  # Initialize in specified directory`,
+  fintrack init /path/to/project  # Initialize in specified directory
+  fintrack init --interactive     # Guided setup, ending with the login`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		targetDir := "."
@@ -37,12 +50,17 @@ Examples:
 			targetDir = args[0]
 		}
 		force, _ := cmd.Flags().GetBool("force")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		if interactive {
+			return runInteractiveInit(cmd, targetDir, force)
+		}
 		return runInit(targetDir, force)
 	},
 }
 
 func init() {
 	initCmd.Flags().BoolP("force", "f", false, "Force initialization even if .fintrack directory already exists")
+	initCmd.Flags().BoolP("interactive", "i", false, "Walk through the settings and log in at the end")
 }
 
 func runInit(targetDir string, force bool) error {
@@ -61,7 +79,7 @@ func runInit(targetDir string, force bool) error {
 	}
 
 	// Step 3: Create default config file
-	if err := createLocalConfigFile(targetDir, force); err != nil {
+	if err := createLocalConfigFile(targetDir, force, nil); err != nil {
 		return err
 	}
 
@@ -121,8 +139,9 @@ func ensureLocalConfigDirectory(targetDir string, force bool) error {
 	return nil
 }
 
-// createLocalConfigFile creates the local configuration file
-func createLocalConfigFile(targetDir string, force bool) error {
+// createLocalConfigFile creates the local configuration file from the wizard's
+// answers, or with the defaults when setup is nil
+func createLocalConfigFile(targetDir string, force bool, setup *initSetup) error {
 	configPath := filepath.Join(targetDir, ".fintrack", "config.yaml")
 
 	// Check if config file already exists
@@ -141,8 +160,13 @@ func createLocalConfigFile(targetDir string, force bool) error {
 	absTargetDir, _ := filepath.Abs(targetDir)
 	sessionFile := filepath.Join(absTargetDir, ".fintrack", "session.json")
 
+	if setup == nil {
+		setup = defaultInitSetup()
+	}
+	setup.SessionFile = sessionFile
+
 	// Create default configuration content
-	defaultConfig := generateLocalDefaultConfig(sessionFile)
+	defaultConfig := generateLocalConfig(setup)
 
 	// Write configuration file with proper permissions
 	if err := os.WriteFile(configPath, []byte(defaultConfig), 0644); err != nil {
@@ -213,8 +237,264 @@ func displayLocalInitSuccess(targetDir string) {
 	fmt.Printf("   fintrack bend transactions --help\n")
 }
 
-// generateLocalDefaultConfig creates the default configuration YAML content for local setup
-func generateLocalDefaultConfig(sessionFile string) string {
+// =============================================================================
+// INTERACTIVE SETUP
+// =============================================================================
+
+// Login methods offered by the setup wizard
+var initLoginMethods = []string{"otp", "password", "token", "skip"}
+
+// initSetup holds the settings written to a new local config
+type initSetup struct {
+	SessionFile   string
+	BaseURL       string
+	DeviceType    string
+	LoginMethod   string // otp, password, token or skip
+	Phone         string // OTP login
+	Email         string // Password login
+	RefreshToken  string // Token login, written to the config
+	StoreBackend  string
+	StorePath     string // "" keeps the default location
+	BaseCurrency  string
+	MonthlyBudget string // "" adds no budget alert
+	LowBalance    string // "" sets no forecast threshold
+}
+
+// defaultInitSetup returns the settings of a non-interactive init
+func defaultInitSetup() *initSetup {
+	return &initSetup{
+		BaseURL:      "https://bend.example.com",
+		DeviceType:   "Web",
+		LoginMethod:  "skip",
+		StoreBackend: "json",
+		BaseCurrency: "INR",
+	}
+}
+
+// runInteractiveInit asks for the settings, initializes targetDir with them
+// and logs in with the chosen method
+func runInteractiveInit(cmd *cobra.Command, targetDir string, force bool) error {
+	if err := validateTargetDirectory(targetDir); err != nil {
+		return err
+	}
+	configPath := filepath.Join(targetDir, ".fintrack", "config.yaml")
+	if _, err := os.Stat(configPath); err == nil && !force {
+		return fmt.Errorf("%s already exists. Use --force to run the setup again", configPath)
+	}
+
+	fmt.Println("🧭 FinTrack setup")
+	fmt.Println("=================")
+	fmt.Println("Press Enter to accept the [default].")
+
+	setup, err := askInitSetup()
+	if err != nil {
+		return err
+	}
+
+	if err := ensureLocalConfigDirectory(targetDir, true); err != nil {
+		return err
+	}
+	if err := createLocalConfigFile(targetDir, true, setup); err != nil {
+		return err
+	}
+	if err := createFintrackIgnoreFile(targetDir, force); err != nil {
+		return err
+	}
+	absConfigPath, _ := filepath.Abs(configPath)
+	fmt.Printf("\n✅ Configuration written to %s\n", absConfigPath)
+
+	if setup.LoginMethod == "skip" {
+		fmt.Println("\n🎯 Log in when you're ready:")
+		fmt.Printf("   FINTRACK_CONFIG=%s fintrack bend login\n", absConfigPath)
+		return nil
+	}
+
+	fmt.Println()
+	if err := loginAfterSetup(cmd, absConfigPath, setup); err != nil {
+		return fmt.Errorf("setup saved, but the login failed: %w", err)
+	}
+
+	fmt.Println("\n🎯 Ready for your first sync:")
+	fmt.Println("   fintrack sync")
+	return nil
+}
+
+// askInitSetup walks through the settings, validating each answer like
+// 'fintrack config set' would
+func askInitSetup() (*initSetup, error) {
+	setup := defaultInitSetup()
+	var err error
+
+	fmt.Println("\n🏦 Bend")
+	if setup.BaseURL, err = askSetting("Base URL", setup.BaseURL, "bend.base_url"); err != nil {
+		return nil, err
+	}
+	if setup.DeviceType, err = askSetting("Device type (Web, Mobile, CLI)", setup.DeviceType, "bend.device_type"); err != nil {
+		return nil, err
+	}
+
+	fmt.Println("\n🔐 Login")
+	fmt.Println("  otp       a code sent to your phone")
+	fmt.Println("  password  email and password, with two-factor if enabled")
+	fmt.Println("  token     a refresh token you already have")
+	fmt.Println("  skip      log in later with 'fintrack bend login'")
+	setup.LoginMethod, err = ask("Login method", "otp", func(answer string) error {
+		if !slices.Contains(initLoginMethods, answer) {
+			return fmt.Errorf("choose one of %s", strings.Join(initLoginMethods, ", "))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch setup.LoginMethod {
+	case "otp":
+		setup.Phone, err = ask("Phone number (e.g. +1234567890)", "", required)
+	case "password":
+		setup.Email, err = ask("Email", "", required)
+	case "token":
+		setup.RefreshToken, err = ask("Refresh token", "", required)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("\n💾 Storage")
+	fmt.Printf("  Backends: %s\n", strings.Join(store.Backends(), ", "))
+	if setup.StoreBackend, err = askSetting("Storage backend", setup.StoreBackend, "store.backend"); err != nil {
+		return nil, err
+	}
+	if setup.StorePath, err = ask("Store path or DSN (empty for the default location)", "", nil); err != nil {
+		return nil, err
+	}
+
+	fmt.Println("\n💰 Budget")
+	if setup.BaseCurrency, err = askSetting("Base currency", setup.BaseCurrency, "fx.base_currency"); err != nil {
+		return nil, err
+	}
+	setup.BaseCurrency = strings.ToUpper(setup.BaseCurrency)
+	if setup.MonthlyBudget, err = ask("Monthly spending budget (empty for none)", "", positiveAmount); err != nil {
+		return nil, err
+	}
+	if setup.LowBalance, err = ask("Warn when a balance is projected below (empty for none)", "", positiveAmount); err != nil {
+		return nil, err
+	}
+	return setup, nil
+}
+
+// askSetting asks for the value of a config key, checked with validateConfigValue
+func askSetting(question, def, key string) (string, error) {
+	return ask(question, def, func(answer string) error {
+		return validateConfigValue(key, answer)
+	})
+}
+
+// ask prompts until validate accepts the answer; an empty answer is def.
+// Input is read through the login's reader so answers piped in for the
+// wizard and the login that follows aren't lost.
+func ask(question, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Printf("  %s [%s]: ", question, def)
+		} else {
+			fmt.Printf("  %s: ", question)
+		}
+
+		line, err := blend.ReadLine()
+		answer := strings.TrimSpace(line)
+		if err != nil && answer == "" {
+			return "", fmt.Errorf("setup aborted: %w", err)
+		}
+		if answer == "" {
+			answer = def
+		}
+		if validate == nil {
+			return answer, nil
+		}
+		if err := validate(answer); err != nil {
+			fmt.Printf("  ❌ %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// required rejects an empty answer
+func required(answer string) error {
+	if answer == "" {
+		return fmt.Errorf("an answer is required")
+	}
+	return nil
+}
+
+// positiveAmount accepts an empty answer or a positive number
+func positiveAmount(answer string) error {
+	if answer == "" {
+		return nil
+	}
+	if n, err := strconv.ParseFloat(answer, 64); err != nil || n <= 0 {
+		return fmt.Errorf("enter a positive amount, e.g. 50000")
+	}
+	return nil
+}
+
+// loginAfterSetup runs 'bend login' with the chosen method against the new
+// config, which also receives the tokens the login returns
+func loginAfterSetup(cmd *cobra.Command, configPath string, setup *initSetup) error {
+	// bend login finds the config to update through FINTRACK_CONFIG
+	if err := os.Setenv("FINTRACK_CONFIG", configPath); err != nil {
+		return fmt.Errorf("failed to select the new configuration: %w", err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load the new configuration: %w", err)
+	}
+	if err := validateConfiguration(cfg); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	login := blend.LoginCmd
+	switch setup.LoginMethod {
+	case "otp":
+		err = login.Flags().Set("phone", setup.Phone)
+	case "password":
+		err = login.Flags().Set("email", setup.Email)
+	}
+	if err != nil {
+		return err
+	}
+
+	login.SetContext(cmd.Context())
+	config.SetInContext(login, cfg)
+	return login.RunE(login, nil)
+}
+
+// generateLocalConfig creates the configuration YAML content for local setup
+func generateLocalConfig(setup *initSetup) string {
+	refreshToken := `  # refresh_token: "your-refresh-token-here"`
+	if setup.RefreshToken != "" {
+		refreshToken = fmt.Sprintf("  refresh_token: %q", setup.RefreshToken)
+	}
+
+	var optional strings.Builder
+	if setup.StoreBackend != "json" || setup.StorePath != "" {
+		optional.WriteString("\nstore:\n")
+		fmt.Fprintf(&optional, "  backend: %q\n", setup.StoreBackend)
+		if setup.StorePath != "" {
+			fmt.Fprintf(&optional, "  path: %q\n", setup.StorePath)
+		}
+	}
+	if setup.LowBalance != "" {
+		optional.WriteString("\nforecast:\n")
+		optional.WriteString("  # Warn when a balance is projected to drop below this\n")
+		fmt.Fprintf(&optional, "  threshold: %s\n", setup.LowBalance)
+	}
+	if setup.MonthlyBudget != "" {
+		optional.WriteString("\nalerts:\n  rules:\n")
+		optional.WriteString("    # Recorded in the sync report; add channels to be notified\n")
+		fmt.Fprintf(&optional, "    monthly-budget:\n      when: \"type=outgoing and monthly total > %s\"\n", setup.MonthlyBudget)
+	}
+
 	return fmt.Sprintf(`# FinTrack Configuration
 # This file contains settings for the FinTrack CLI tool
 # This is a local configuration file for this project
@@ -222,7 +502,7 @@ func generateLocalDefaultConfig(sessionFile string) string {
 # Bend Financial Service Configuration
 bend:
   # Bend base URL
-  base_url: "https://bend.example.com"
+  base_url: %q
   
   # Rate limiting (requests per second)
   rate_limit: "1s"
@@ -232,35 +512,49 @@ bend:
   
   # Request timeout
   timeout: "30s"
+
+  # Pagination: transactions per page and a safety cap on pages per run (0 = unlimited)
+  page_size: 50
+  max_pages: 1000
   
   # Device configuration (required by Bend)
   # device_hash: ""                                     # Will be auto-generated if not provided
-  device_type: "Web"                                    # Device type: Web, Mobile, CLI
+  device_type: %-41q# Device type: Web, Mobile, CLI
   device_location: "Default"                            # Device location
   
   # Authentication (set this via 'fintrack bend login')
-  # refresh_token: "your-refresh-token-here"
-
+%s
+
+fx:
+  # Currency that foreign-currency amounts are normalized into
+  base_currency: %q
+
+money:
+  # Rounding for reports and exports: half_even, half_up, down, up
+  rounding: "half_even"
+
+display:
+  # Output preferences used by every command (flags still win)
+  output: "table"          # table, json, csv
+  date_format: "iso"       # iso, dmy, mdy or a Go layout like "02 Jan 2006"
+  currency_symbol: "code"  # code (100.00 INR), before (₹100.00), after, none
+  table_style: "ascii"     # ascii, plain, markdown, unicode
+%s
 # Configuration notes:
 # - This is a local configuration file for this project
 # - Modify device_type to "CLI" for better identification
 # - Adjust timeout based on your network conditions
 # - The refresh_token will be set automatically during login
 # - Session data is stored locally in this project
-`, sessionFile)
+`, setup.BaseURL, setup.SessionFile, setup.DeviceType, refreshToken, setup.BaseCurrency, optional.String())
 }
 
 // generateDefaultFintrackIgnore creates the default .fintrackignore content
 func generateDefaultFintrackIgnore() string {
 	return `# FinTrack Ignore File
-# Files and directories to exclude from FinTrack operations
-
-# Common financial data files
-*.csv
-*.xlsx
-*.xls
-*.pdf
-*.json
+# Files left out when fintrack scans a directory (import --dir,
+# migrate staging, backup create --receipts), in gitignore syntax.
+# Check a path with: fintrack ignore check <path>
 
 # Backup files
 *.bak
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/staging"
+	"github.com/quickkly/fintrack/internal/store"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// MIGRATE COMMAND DEFINITIONS
+// =============================================================================
+
+// migrateCmd groups one-off data migrations
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate data between storage formats",
+	Long: `Migrate data kept in older formats.
+
+Available subcommands:
+- staging: Import staging directory JSON files into the local store`,
+}
+
+// migrateStagingCmd imports legacy staging files into the store
+var migrateStagingCmd = &cobra.Command{
+	Use:   "staging",
+	Short: "Import staging JSON files into the local store",
+	Long: `Scan the staging directory for transaction files written by earlier fetches,
+deduplicate them by transaction UUID and import them into the local store
+(store.path), so historical pulls carry over to the store-backed workflow.
+
+Files are imported oldest fetch first; when a transaction appears in several
+files the most recently fetched copy is kept. Files already imported are
+skipped, so the command is safe to rerun. Staging files are left in place.
+Files matching .fintrackignore are left out.
+
+Examples:
+  fintrack migrate staging
+  fintrack migrate staging --staging-dir ./old-staging --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runMigrateStaging,
+}
+
+var migrateStagingDir string
+
+func init() {
+	migrateStagingCmd.Flags().StringVar(&migrateStagingDir, "staging-dir", "", "Staging directory to import (default: paths.staging_dir)")
+
+	migrateCmd.AddCommand(migrateStagingCmd)
+}
+
+// =============================================================================
+// MIGRATE COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// stagedFile is a staging file queued for import
+type stagedFile struct {
+	name string
+	file *staging.TransactionFileV3
+}
+
+// runMigrateStaging imports every not yet imported staging file
+func runMigrateStaging(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	dir := migrateStagingDir
+	if dir == "" {
+		dir = cfg.Paths.StagingDir
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := st.Checkpoint()
+	if err != nil {
+		return err
+	}
+
+	paths, err := staging.Files(dir)
+	if err != nil {
+		return err
+	}
+	matcher, err := loadIgnore()
+	if err != nil {
+		return err
+	}
+
+	var pending []stagedFile
+	skipped, ignored := 0, 0
+	for _, path := range paths {
+		if matcher.Ignored(path, false) {
+			ignored++
+			continue
+		}
+		file, err := staging.ReadFile(path)
+		if err != nil {
+			if IsVerbose() {
+				fmt.Printf("⚠️  Skipping %s: %v\n", path, err)
+			}
+			continue
+		}
+		name := filepath.Base(path)
+		if imported, ok := st.Imported[name]; ok && imported.Equal(file.FetchedAt) {
+			skipped++
+			continue
+		}
+		pending = append(pending, stagedFile{name: name, file: file})
+	}
+
+	reportIgnored(matcher, ignored)
+
+	if len(pending) == 0 {
+		fmt.Printf("✅ Nothing to migrate (%d file(s) already imported)\n", skipped)
+		return nil
+	}
+
+	// Oldest fetch first, so later copies of a transaction win
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].file.FetchedAt.Before(pending[j].file.FetchedAt)
+	})
+
+	var total store.UpsertResult
+	for _, p := range pending {
+		result := st.Upsert(p.file.Transactions, p.file.FetchedAt, p.name)
+		total.Add(result)
+		st.Imported[p.name] = p.file.FetchedAt
+		if IsVerbose() {
+			fmt.Printf("  📄 %s: %d new, %d changed, %d duplicate\n", p.name, result.New, result.Changed, result.Unchanged)
+		}
+	}
+
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would import %d file(s) into %s: %d new, %d changed, %d duplicate transaction(s)\n",
+			len(pending), st.Path(), total.New, total.Changed, total.Unchanged)
+		return nil
+	}
+
+	summary := fmt.Sprintf("migrate %d staging file(s): %d new, %d changed", len(pending), total.New, total.Changed)
+	if err := saveWithHistory(st, checkpoint, summary); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Imported %d file(s) into %s\n", len(pending), st.Path())
+	fmt.Printf("📊 %d transaction(s) read: %d new, %d changed, %d duplicate\n", total.Total(), total.New, total.Changed, total.Unchanged)
+	fmt.Printf("💾 Store now holds %d transaction(s)\n", st.Len())
+	if skipped > 0 {
+		fmt.Printf("⏭️  Skipped %d file(s) imported earlier\n", skipped)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/export"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// PATHS COMMAND DEFINITION
+// =============================================================================
+
+// pathsCmd prints where fintrack reads and writes its files
+var pathsCmd = &cobra.Command{
+	Use:   "paths",
+	Short: "Show where fintrack keeps its files",
+	Long: `Show the resolved locations of fintrack's configuration, data, cache and
+state files, and whether each exists yet.
+
+Directories follow the XDG base directory spec unless set with
+paths.data_dir, paths.cache_dir, paths.state_dir and paths.staging_dir:
+
+- data ($XDG_DATA_HOME/fintrack): the store and staging directory
+- cache ($XDG_CACHE_HOME/fintrack): HTTP and FX rate caches
+- state ($XDG_STATE_HOME/fintrack): usage, circuit breaker, sync report,
+  alert state, stats and device hash
+
+FINTRACK_DATA_DIR puts everything in one directory. Files left in
+~/.config/fintrack by earlier releases keep being used until moved.`,
+	Example: `  fintrack paths
+  fintrack paths --json`,
+	Args: cobra.NoArgs,
+	RunE: runPaths,
+}
+
+var pathsJSON bool
+
+func init() {
+	pathsCmd.Flags().BoolVar(&pathsJSON, "json", false, "Print the locations as JSON")
+}
+
+// =============================================================================
+// PATHS COMMAND IMPLEMENTATION
+// =============================================================================
+
+// resolvedPath is one location shown by 'fintrack paths'
+type resolvedPath struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+}
+
+// runPaths lists the resolved locations
+func runPaths(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	type location struct{ name, path string }
+	configDir, _ := config.Dir()
+	locations := []location{{"config_dir", configDir}}
+	for _, file := range config.ConfigFiles(configFilePath()) {
+		name := file.Source + "_config" // global_config, local_config
+		if file.Source == config.SourceFile {
+			name = "config_file"
+		}
+		locations = append(locations, location{name, file.Path})
+	}
+	locations = append(locations, []location{
+		{"data_dir", cfg.Paths.DataDir},
+		{"cache_dir", cfg.Paths.CacheDir},
+		{"state_dir", cfg.Paths.StateDir},
+		{"session_file", cfg.Bend.SessionFile},
+		{"store", cfg.Store.Path},
+		{"staging_dir", cfg.Paths.StagingDir},
+		{"archive_dir", cfg.Bend.ArchiveDir},
+		{"receipts_dir", cfg.Receipts.Dir},
+		{"http_cache", cfg.Bend.CacheDir},
+		{"fx_cache", cfg.FX.CacheFile},
+		{"usage_file", cfg.Bend.UsageFile},
+		{"circuit_file", cfg.Bend.CircuitFile},
+		{"sync_report", cfg.Sync.ReportFile},
+		{"alert_state", cfg.Alerts.StateFile},
+		{"stats_file", cfg.Telemetry.StatsFile},
+		{"export_cursors", export.CursorPath(cfg.Paths.StateDir)},
+		{"device_hash", cfg.DeviceHashFile()},
+	}...)
+
+	var paths []resolvedPath
+	for _, p := range locations {
+		exists := false
+		if p.path != "" {
+			_, err := os.Stat(p.path)
+			exists = err == nil
+		}
+		paths = append(paths, resolvedPath{Name: p.name, Path: p.path, Exists: exists})
+	}
+
+	if pathsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(paths)
+	}
+
+	for _, p := range paths {
+		path := p.Path
+		switch {
+		case path == "":
+			path = "(none)"
+		case !p.Exists:
+			path += " (not created yet)"
+		}
+		fmt.Printf("  %-14s %s\n", p.Name, path)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/plugin"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// PLUGINS COMMAND DEFINITIONS
+// =============================================================================
+
+// pluginsCmd groups the plugin commands
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "Inspect configured plugins",
+	Long: `Plugins are external programs, configured under plugins, that add export
+targets (type: plugin), alert channels (type: plugin) and enrichers that see
+new transactions before they're stored, e.g. to categorize them.
+
+fintrack runs the plugin's command for each request, writes the request as
+JSON to its stdin and reads the JSON response from its stdout.
+
+Available subcommands:
+- list: Show configured plugins and what uses them
+- ping: Check a plugin runs and answers`,
+}
+
+// pluginsListCmd lists plugins
+var pluginsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured plugins",
+	Args:  cobra.NoArgs,
+	RunE:  runPluginsList,
+}
+
+// pluginsPingCmd sends a ping request
+var pluginsPingCmd = &cobra.Command{
+	Use:     "ping <name>",
+	Short:   "Check a plugin runs and answers",
+	Args:    cobra.ExactArgs(1),
+	Example: `  fintrack plugins ping categorizer`,
+	RunE:    runPluginsPing,
+}
+
+func init() {
+	pluginsCmd.AddCommand(pluginsListCmd)
+	pluginsCmd.AddCommand(pluginsPingCmd)
+}
+
+// =============================================================================
+// PLUGINS COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runPluginsList prints every configured plugin
+func runPluginsList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	if len(cfg.Plugins) == 0 {
+		fmt.Println("No plugins configured. Add them under plugins: in the config file")
+		return nil
+	}
+
+	f := display.New(cfg.Display)
+	table := f.Table(display.Column{Header: "Plugin"}, display.Column{Header: "Command"}, display.Column{Header: "Used By"})
+	for _, name := range cfg.PluginNames() {
+		table.Row(name, cfg.Plugins[name].Command, strings.Join(pluginUses(cfg, name), ", "))
+	}
+	table.Render(os.Stdout)
+	return nil
+}
+
+// pluginUses lists what runs a plugin: enrichment, export targets and alert
+// channels
+func pluginUses(cfg *config.Config, name string) []string {
+	var uses []string
+	if cfg.Plugins[name].Enrich {
+		uses = append(uses, "enrich")
+	}
+	for target, export := range cfg.Exports {
+		if strings.EqualFold(export.Type, "plugin") && strings.EqualFold(export.Plugin, name) {
+			uses = append(uses, "export "+target)
+		}
+	}
+	for channel, alert := range cfg.Alerts.Channels {
+		if strings.EqualFold(alert.Type, "plugin") && strings.EqualFold(alert.Plugin, name) {
+			uses = append(uses, "channel "+channel)
+		}
+	}
+	sort.Strings(uses)
+	if len(uses) == 0 {
+		return []string{"-"}
+	}
+	return uses
+}
+
+// runPluginsPing sends a plugin a ping request and shows its answer
+func runPluginsPing(cmd *cobra.Command, args []string) error {
+	resp, err := plugin.Call(cmd.Context(), args[0], plugin.Request{Kind: plugin.KindPing})
+	if err != nil {
+		return err
+	}
+
+	if !IsQuiet() {
+		name := strings.ToLower(args[0])
+		if resp.Name != "" {
+			name += " (" + resp.Name + ")"
+		}
+		fmt.Printf("✅ Plugin %s answered", name)
+		if len(resp.Kinds) > 0 {
+			fmt.Printf(", handles: %s", strings.Join(resp.Kinds, ", "))
+		}
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/query"
+	"github.com/quickkly/fintrack/internal/store"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// QUERY COMMAND DEFINITION
+// =============================================================================
+
+// queryCmd runs ad-hoc SQL against the local store
+var queryCmd = &cobra.Command{
+	Use:   "query <sql>",
+	Short: "Run a SQL query over the local store",
+	Long: `Run a read-only SQL query over the transactions in the local store
+(store.path), for one-off questions the built-in reports don't answer.
+
+The store is a JSON file rather than a database, so fintrack implements the
+SELECT subset such questions need over a single "transactions" table:
+
+  SELECT [DISTINCT] ... FROM transactions
+    [WHERE ...] [GROUP BY ...] [HAVING ...] [ORDER BY ... [ASC|DESC]] [LIMIT n]
+
+Operators: = != <> < <= > >= AND OR NOT LIKE IN BETWEEN IS NULL + - * / % ||
+Aggregates: COUNT, SUM, AVG, MIN, MAX (COUNT(*) and COUNT(DISTINCT x))
+Functions: LOWER, UPPER, LENGTH, TRIM, ABS, ROUND, SUBSTR, COALESCE, IFNULL
+
+GROUP BY and ORDER BY accept output positions (GROUP BY 1) and aliases. LIKE
+is case-insensitive. Deleted transactions are not included.
+
+Columns:
+  uuid, date (2024-03-15), month (2024-03), year, timestamp, account_id,
+  type (INCOMING/OUTGOING), amount (negative for outgoing), currency, mode,
+  narration, merchant, category_id, subcategory_id, reference,
+  tags (comma-separated), hidden, excluded_from_cash_flow, source, first_seen
+
+Examples:
+  fintrack query "SELECT merchant, SUM(amount) FROM transactions WHERE type = 'OUTGOING' GROUP BY 1 ORDER BY 2 LIMIT 10"
+  fintrack query "SELECT month, COUNT(*) AS n, SUM(amount) AS net FROM transactions GROUP BY month ORDER BY month"
+  fintrack query "SELECT date, narration, amount FROM transactions WHERE narration LIKE '%swiggy%'" -o csv`,
+	Args: cobra.ExactArgs(1),
+	RunE: runQuery,
+}
+
+var queryOutput string
+
+func init() {
+	queryCmd.Flags().StringVarP(&queryOutput, "output", "o", "table", "Output format (table, csv, json; default from display.output)")
+}
+
+// =============================================================================
+// QUERY COMMAND IMPLEMENTATION
+// =============================================================================
+
+// runQuery runs the query against the store's live transactions
+func runQuery(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+
+	table := query.Transactions(st.Find(store.Query{}), cfg.RoundingMode())
+	result, err := query.Execute(args[0], table)
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+
+	switch format := display.Output(cmd, cfg.Display); format {
+	case "table":
+		if len(result.Rows) == 0 {
+			if !IsQuiet() {
+				fmt.Println("📭 No rows")
+			}
+			return nil
+		}
+		columns := make([]display.Column, len(result.Columns))
+		for i, name := range result.Columns {
+			columns[i] = display.Column{Header: name, Right: numericColumn(result, i)}
+		}
+		table := display.New(cfg.Display).Table(columns...)
+		for _, row := range result.Rows {
+			cells := make([]string, len(row))
+			for i, v := range row {
+				cells[i] = formatQueryValue(v)
+			}
+			table.Row(cells...)
+		}
+		table.Render(os.Stdout)
+		if !IsQuiet() {
+			fmt.Printf("\n%d row(s)\n", len(result.Rows))
+		}
+
+	case "csv":
+		cw := csv.NewWriter(os.Stdout)
+		cw.Write(result.Columns)
+		for _, row := range result.Rows {
+			cells := make([]string, len(row))
+			for i, v := range row {
+				cells[i] = formatQueryValue(v)
+			}
+			cw.Write(cells)
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+
+	case "json":
+		rows := make([]map[string]any, len(result.Rows))
+		for r, row := range result.Rows {
+			rows[r] = make(map[string]any, len(row))
+			for i, v := range row {
+				if n, ok := v.(float64); ok {
+					v = roundQueryNumber(n)
+				}
+				rows[r][result.Columns[i]] = v
+			}
+		}
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal query results to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table, csv, or json", format)
+	}
+	return nil
+}
+
+// formatQueryValue formats a result cell; NULL is empty
+func formatQueryValue(v query.Value) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(roundQueryNumber(v), 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// roundQueryNumber hides float noise from sums of amounts (0.30000000000000004)
+func roundQueryNumber(n float64) float64 {
+	return math.Round(n*1e4) / 1e4
+}
+
+// numericColumn reports whether every non-NULL value of a column is a number,
+// so the table can right-align it
+func numericColumn(result *query.Result, column int) bool {
+	numeric := false
+	for _, row := range result.Rows {
+		switch row[column].(type) {
+		case nil:
+		case float64:
+			numeric = true
+		default:
+			return false
+		}
+	}
+	return numeric
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/safefile"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// RECEIPTS COMMAND DEFINITIONS
+// =============================================================================
+
+// receiptsCmd groups the receipt commands
+var receiptsCmd = &cobra.Command{
+	Use:   "receipts",
+	Short: "Attach receipts to transactions and download them",
+	Long: `Manage the receipt files attached to transactions in Bend.
+
+Local copies are kept under receipts.dir (receipts in paths.data_dir unless
+configured), in a subdirectory named by the transaction's UUID.
+
+Available subcommands:
+- attach: Upload a file as a transaction's receipt
+- get: Download a transaction's receipts`,
+}
+
+// receiptsAttachCmd uploads a receipt
+var receiptsAttachCmd = &cobra.Command{
+	Use:   "attach <txn-uuid> <file>",
+	Short: "Upload a file as a transaction's receipt",
+	Long: `Upload a file (a photo or PDF, at most 10 MB) as a receipt of the
+transaction and keep a copy under receipts.dir.
+
+Examples:
+  fintrack receipts attach 1b2c...-uuid ~/Downloads/invoice.pdf
+  fintrack receipts attach 1b2c...-uuid bill.jpg --local-only    # Keep it local, don't upload`,
+	Args: cobra.ExactArgs(2),
+	RunE: runReceiptsAttach,
+}
+
+// receiptsGetCmd downloads receipts
+var receiptsGetCmd = &cobra.Command{
+	Use:   "get <txn-uuid>",
+	Short: "Download a transaction's receipts",
+	Long: `Download the receipts attached to the transaction in Bend into
+receipts.dir, skipping the ones already there, and list the local copies.
+
+Examples:
+  fintrack receipts get 1b2c...-uuid
+  fintrack receipts get 1b2c...-uuid --force      # Download again, overwriting local copies`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReceiptsGet,
+}
+
+var (
+	receiptsLocalOnly bool
+	receiptsForce     bool
+)
+
+func init() {
+	receiptsAttachCmd.Flags().BoolVar(&receiptsLocalOnly, "local-only", false, "Only copy the file into receipts.dir, without uploading it")
+	receiptsGetCmd.Flags().BoolVar(&receiptsForce, "force", false, "Download receipts that already have a local copy again")
+
+	receiptsCmd.AddCommand(receiptsAttachCmd)
+	receiptsCmd.AddCommand(receiptsGetCmd)
+}
+
+// =============================================================================
+// RECEIPTS COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runReceiptsAttach uploads a file and copies it into the media directory
+func runReceiptsAttach(cmd *cobra.Command, args []string) error {
+	uuid, path := args[0], args[1]
+
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read receipt: %w", err)
+	}
+	if len(data) > blend.MaxReceiptSize {
+		return fmt.Errorf("%s is %d bytes; receipts can be at most %d", path, len(data), blend.MaxReceiptSize)
+	}
+	name := filepath.Base(path)
+	local := filepath.Join(receiptDir(cfg, uuid), name)
+
+	if IsDryRun() {
+		if !receiptsLocalOnly {
+			fmt.Printf("🔍 [dry-run] Would upload %s (%d bytes) to transaction %s\n", name, len(data), uuid)
+		}
+		fmt.Printf("🔍 [dry-run] Would copy it to %s\n", local)
+		return nil
+	}
+
+	if !receiptsLocalOnly {
+		client, err := newSessionClient(cfg)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		userID, err := client.GetUserID()
+		if err != nil {
+			return fmt.Errorf("failed to get user ID: %w", err)
+		}
+
+		receipt, err := client.UploadReceipt(userID, uuid, name, receiptContentType(name, data), data)
+		if err != nil {
+			return err
+		}
+		if IsVerbose() {
+			fmt.Printf("📤 Uploaded %s as receipt %s\n", name, receipt.ID)
+		}
+	}
+
+	if err := writeReceipt(local, data); err != nil {
+		return err
+	}
+
+	if !IsQuiet() {
+		if receiptsLocalOnly {
+			fmt.Printf("✅ Saved %s to %s\n", name, local)
+		} else {
+			fmt.Printf("✅ Attached %s to %s (copy in %s)\n", name, uuid, local)
+		}
+	}
+	return nil
+}
+
+// runReceiptsGet downloads the receipts missing from the media directory
+func runReceiptsGet(cmd *cobra.Command, args []string) error {
+	uuid := args[0]
+
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	client, err := newSessionClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	userID, err := client.GetUserID()
+	if err != nil {
+		return fmt.Errorf("failed to get user ID: %w", err)
+	}
+
+	receipts, err := client.ListReceipts(userID, uuid)
+	if err != nil {
+		return err
+	}
+	if len(receipts) == 0 {
+		fmt.Printf("📭 Transaction %s has no receipts\n", uuid)
+		return nil
+	}
+
+	dir := receiptDir(cfg, uuid)
+	downloaded := 0
+	for _, receipt := range receipts {
+		local := filepath.Join(dir, receiptFileName(receipt))
+		if _, err := os.Stat(local); err == nil && !receiptsForce {
+			if IsVerbose() {
+				fmt.Printf("  ⏭️  %s (already downloaded)\n", local)
+			}
+			continue
+		}
+		if IsDryRun() {
+			fmt.Printf("🔍 [dry-run] Would download %s to %s\n", receipt.FileName, local)
+			continue
+		}
+
+		data, err := client.DownloadReceipt(userID, uuid, receipt.ID)
+		if err != nil {
+			return err
+		}
+		if err := writeReceipt(local, data); err != nil {
+			return err
+		}
+		downloaded++
+		if !IsQuiet() {
+			fmt.Printf("  📥 %s\n", local)
+		}
+	}
+
+	if !IsQuiet() && !IsDryRun() {
+		fmt.Printf("✅ %d receipt(s) in %s (%d downloaded)\n", len(receipts), dir, downloaded)
+	}
+	return nil
+}
+
+// receiptDir is where a transaction's receipts are kept
+func receiptDir(cfg *config.Config, uuid string) string {
+	return filepath.Join(cfg.Receipts.Dir, filepath.Base(uuid))
+}
+
+// receiptFileName is the local name of a downloaded receipt. Bend's name is
+// reduced to its base so a hostile one can't escape the media directory.
+func receiptFileName(receipt blend.Receipt) string {
+	name := filepath.Base(receipt.FileName)
+	if name == "." || name == "/" || name == ".." {
+		name = ""
+	}
+	if name == "" {
+		name = filepath.Base(receipt.ID)
+		if exts, _ := mime.ExtensionsByType(receipt.ContentType); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+	return name
+}
+
+// receiptContentType guesses a receipt's type from its extension, then its content
+func receiptContentType(name string, data []byte) string {
+	if contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(data)
+}
+
+// writeReceipt saves a receipt, creating the transaction's directory
+func writeReceipt(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create receipts directory: %w", err)
+	}
+	if err := safefile.Write(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write receipt: %w", err)
+	}
+	return nil
+}
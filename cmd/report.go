@@ -0,0 +1,838 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/alerts"
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/exitcode"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/report"
+	"github.com/quickkly/fintrack/internal/store"
+	"github.com/quickkly/fintrack/internal/syncreport"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// REPORT COMMAND DEFINITIONS
+// =============================================================================
+
+// reportCmd groups reports over the local store
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report on stored transactions",
+	Long: `Reports built from the transactions in the local store (store.path).
+
+Available subcommands:
+- tree: Spending by category, subcategory and merchant
+- summary: Spending, income, savings goals and anomalies for a period
+- merchant: Spending at one merchant, month by month
+- scheduled: Run the report jobs in reports.schedule that are due`,
+}
+
+// reportTreeCmd shows the category hierarchy of spending
+var reportTreeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Show spending by category, subcategory and merchant",
+	Long: `Show spending for a period as a category → subcategory → merchant
+hierarchy, largest first, so a category total can be followed down to the
+merchants behind it.
+
+Amounts are in the base currency (fx.base_currency); foreign-currency
+transactions are converted at the rate on their own date. Transactions Bend
+excludes from cash flow (such as own-account transfers) and those hidden in
+the app are left out; --exclude-cashflow-excluded=false and --include-hidden
+count them. Purchases matched to a refund count net of it (see 'fintrack
+analyze refunds'). Use --depth to collapse the tree to categories (1) or
+subcategories (2).
+
+Examples:
+  fintrack report tree                         # This month
+  fintrack report tree --month 2024-03
+  fintrack report tree --from 2024-01-01 --to 2024-03-31 --depth 2
+  fintrack report tree --income --output json`,
+	Args: cobra.NoArgs,
+	RunE: runReportTree,
+}
+
+// reportSummaryCmd shows the period summary sent by scheduled summary jobs
+var reportSummaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Summarize spending, income, goals and anomalies for a period",
+	Long: `Summarize a period: total spending and income against the period before,
+the top spending categories against their average over the three periods
+before, savings goal status, and anomalies (categories at 1.5x their average
+or more, and alert rules that trigger at the end of the period).
+
+This is the summary scheduled jobs with "report: summary" write and email;
+--send delivers it to alert channels now, e.g. to test an email channel.
+Amounts are in the base currency; hidden and cash-flow-excluded transactions
+are left out.
+
+Examples:
+  fintrack report summary                      # This month so far
+  fintrack report summary --month 2024-03
+  fintrack report summary --from 2024-03-04 --to 2024-03-10 --send mail
+  fintrack report summary --output json`,
+	Args: cobra.NoArgs,
+	RunE: runReportSummary,
+}
+
+// reportMerchantCmd drills into the spending at one merchant
+var reportMerchantCmd = &cobra.Command{
+	Use:   "merchant <name>",
+	Short: "Show spending at one merchant, month by month",
+	Long: `Show the total spend, number of payments, average ticket size and a
+month-by-month trend for one merchant over the last --months months
+(including this one).
+
+Transactions are grouped under the merchant Bend resolved, or the narration
+when it found none. Names are compared ignoring case, punctuation and spacing,
+and a merchant matches when its name contains the given one ("swiggy" matches
+"SWIGGY*ORDER" and "Swiggy Instamart"); --exact requires the whole name. The
+merchants that matched are listed. Amounts are in the base currency; hidden
+and cash-flow-excluded transactions are left out.
+
+Examples:
+  fintrack report merchant "Swiggy" --months 6
+  fintrack report merchant amazon --months 12 --output json
+  fintrack report merchant "Swiggy" --exact`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReportMerchant,
+}
+
+// reportScheduledCmd runs the scheduled report jobs
+var reportScheduledCmd = &cobra.Command{
+	Use:   "scheduled",
+	Short: "Run the scheduled report jobs that are due",
+	Long: `Run the report jobs declared under reports.schedule that are due. A job
+reports on the previous week or month and runs once that period is over, from
+its configured day on; each job runs once per period and tells its alert
+channels where the file was written.
+
+'fintrack sync' runs due jobs after every successful sync, so a scheduled
+sync is all that's needed for unattended monthly reports. This command is for
+running them without syncing, or re-running them with --force.
+
+Examples:
+  fintrack report scheduled
+  fintrack report scheduled --force            # Regenerate every job now
+  fintrack report scheduled --dry-run          # Show which jobs are due`,
+	Args: cobra.NoArgs,
+	RunE: runReportScheduled,
+}
+
+var (
+	reportMonth      string
+	reportPeriodName string
+	reportFrom       string
+	reportTo         string
+	reportDepth      int
+	reportIncome     bool
+	reportOutput     string
+
+	reportTags, reportAnyTags, reportExcludeTags []string
+	reportGroups                                 []string
+	reportEntity                                 string
+
+	reportIncludeHidden, reportExcludeCashflow bool
+
+	reportForce bool
+	reportSend  []string
+
+	reportMonths int
+	reportExact  bool
+)
+
+func init() {
+	reportTreeCmd.Flags().StringVar(&reportMonth, "month", "", "Month to report on (YYYY-MM, default: this month)")
+	reportTreeCmd.Flags().StringVar(&reportPeriodName, "period", "", "Named period: fy, last_fy, fy2025, q1, fy2025q3, this_quarter or a periods.custom name")
+	reportTreeCmd.Flags().StringVar(&reportFrom, "from", "", "Start date (YYYY-MM-DD)")
+	reportTreeCmd.Flags().StringVar(&reportTo, "to", "", "End date, inclusive (YYYY-MM-DD)")
+	reportTreeCmd.Flags().IntVar(&reportDepth, "depth", 3, "Levels to show: 1 categories, 2 subcategories, 3 merchants")
+	reportTreeCmd.Flags().BoolVar(&reportIncome, "income", false, "Report incoming instead of outgoing transactions")
+	reportTreeCmd.Flags().StringVarP(&reportOutput, "output", "o", "table", "Output format (table, json, csv; default from display.output)")
+	tagFilterFlags(reportTreeCmd, &reportTags, &reportAnyTags, &reportExcludeTags)
+	reportTreeCmd.Flags().StringSliceVar(&reportGroups, "group", nil, "Only count accounts in these groups (repeatable)")
+	reportTreeCmd.Flags().StringVar(&reportEntity, "entity", "", "Only count transactions belonging to this entity")
+	reportTreeCmd.Flags().BoolVar(&reportIncludeHidden, "include-hidden", false, "Count transactions hidden in the Bend app")
+	reportTreeCmd.Flags().BoolVar(&reportExcludeCashflow, "exclude-cashflow-excluded", true, "Leave out transactions excluded from cash flow")
+
+	reportSummaryCmd.Flags().StringVar(&reportMonth, "month", "", "Month to summarize (YYYY-MM, default: this month)")
+	reportSummaryCmd.Flags().StringVar(&reportPeriodName, "period", "", "Named period: fy, last_fy, fy2025, q1, fy2025q3, this_quarter or a periods.custom name")
+	reportSummaryCmd.Flags().StringVar(&reportFrom, "from", "", "Start date (YYYY-MM-DD)")
+	reportSummaryCmd.Flags().StringVar(&reportTo, "to", "", "End date, inclusive (YYYY-MM-DD)")
+	reportSummaryCmd.Flags().StringVarP(&reportOutput, "output", "o", "table", "Output format (table, json; default from display.output)")
+	reportSummaryCmd.Flags().StringSliceVar(&reportGroups, "group", nil, "Only count accounts in these groups (repeatable)")
+	reportSummaryCmd.Flags().StringVar(&reportEntity, "entity", "", "Only count transactions belonging to this entity, and only its goals")
+	reportSummaryCmd.Flags().StringSliceVar(&reportSend, "send", nil, "Also send the summary to these alert channels (repeatable)")
+
+	reportMerchantCmd.Flags().IntVar(&reportMonths, "months", 6, "Months to cover, ending with this one")
+	reportMerchantCmd.Flags().BoolVar(&reportExact, "exact", false, "Match the whole merchant name instead of part of it")
+	reportMerchantCmd.Flags().StringSliceVar(&reportGroups, "group", nil, "Only count accounts in these groups (repeatable)")
+	reportMerchantCmd.Flags().StringVar(&reportEntity, "entity", "", "Only count transactions belonging to this entity")
+	reportMerchantCmd.Flags().StringVarP(&reportOutput, "output", "o", "table", "Output format (table, json; default from display.output)")
+
+	reportScheduledCmd.Flags().BoolVar(&reportForce, "force", false, "Run every job for its latest period, even if it already ran")
+
+	reportCmd.AddCommand(reportTreeCmd)
+	reportCmd.AddCommand(reportSummaryCmd)
+	reportCmd.AddCommand(reportMerchantCmd)
+	reportCmd.AddCommand(reportScheduledCmd)
+}
+
+// =============================================================================
+// REPORT COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runReportTree prints the spending hierarchy for the period
+func runReportTree(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	if reportDepth < 1 || reportDepth > 3 {
+		return fmt.Errorf("--depth must be 1, 2 or 3")
+	}
+
+	from, to, err := reportPeriod(cfg, reportPeriodName, reportMonth, reportFrom, reportTo)
+	if err != nil {
+		return err
+	}
+
+	tags, err := store.NewTagFilter(reportTags, reportAnyTags, reportExcludeTags)
+	if err != nil {
+		return err
+	}
+	scope, err := reportScope(cfg, reportGroups, reportEntity)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Usage, err)
+	}
+
+	flags := blend.TransactionFilters{IncludeHidden: reportIncludeHidden, ExcludeCashflowExcluded: reportExcludeCashflow}
+	tree, err := spendingTree(cfg, from, to, reportIncome, scope, tags, flags)
+	if err != nil {
+		return err
+	}
+
+	return writeTree(os.Stdout, display.Output(cmd, cfg.Display), cfg, tree, from, to, reportIncome, reportDepth)
+}
+
+// spendingTree builds the category tree of base-currency spending (or income)
+// between from and to, keeping the transactions flags allows
+func spendingTree(cfg *config.Config, from, to time.Time, income bool, scope func(*blend.Transaction) bool, tags store.TagFilter, flags blend.TransactionFilters) (*report.Node, error) {
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	direction := blend.TransactionTypeOutgoing
+	if income {
+		direction = blend.TransactionTypeIncoming
+	}
+
+	var selected []blend.Transaction
+	for _, txn := range st.FilterTags(reportTransactions(cfg, st.Transactions(), scope), tags) {
+		if txn.Type != direction || !flags.Allows(&txn) {
+			continue
+		}
+		if txn.TxnTimestamp.Before(from) || txn.TxnTimestamp.After(to) {
+			continue
+		}
+		selected = append(selected, txn)
+	}
+
+	if selected, err = inBaseCurrency(cfg, selected); err != nil {
+		return nil, err
+	}
+	return report.Tree(selected, cfg.RoundingMode()), nil
+}
+
+// reportScope returns whether a transaction belongs in a report limited to
+// account groups and an entity (either may be empty)
+func reportScope(cfg *config.Config, groups []string, entity string) (func(*blend.Transaction) bool, error) {
+	accounts, err := cfg.AccountFilter(groups)
+	if err != nil {
+		return nil, err
+	}
+	inEntity, err := cfg.EntityFilter(entity)
+	if err != nil {
+		return nil, err
+	}
+	return func(txn *blend.Transaction) bool {
+		return accounts(txn.AccountID) && inEntity(txn.UUID, txn.AccountID)
+	}, nil
+}
+
+// reportTransactions prepares stored transactions for reports: those in
+// scope, purchases net of their matched refunds, and person-to-person
+// transfers grouped under report.PeopleCategory
+func reportTransactions(cfg *config.Config, transactions []blend.Transaction, scope func(*blend.Transaction) bool) []blend.Transaction {
+	selected := make([]blend.Transaction, 0, len(transactions))
+	for i := range transactions {
+		if scope(&transactions[i]) {
+			selected = append(selected, transactions[i])
+		}
+	}
+	return report.GroupPeople(netOfRefunds(cfg, selected), cfg.Contacts)
+}
+
+// writeTree renders a category tree in the given format
+func writeTree(w io.Writer, format string, cfg *config.Config, tree *report.Node, from, to time.Time, income bool, depth int) error {
+	f := display.New(cfg.Display)
+
+	switch format {
+	case "table":
+		title := "Spending"
+		if income {
+			title = "Income"
+		}
+		fmt.Fprintf(w, "🌳 %s by category, %s to %s\n\n", title, f.Date(from), f.Date(to))
+		if tree.Count == 0 {
+			fmt.Fprintln(w, "📭 No transactions in this period")
+			break
+		}
+
+		table := f.Table(display.Column{Header: "Category"}, display.Column{Header: "Amount", Right: true},
+			display.Column{Header: "Txns", Right: true}, display.Column{Header: "Share", Right: true})
+		tree.Walk(depth, func(node, parent *report.Node, level int) {
+			table.Row(strings.Repeat("  ", level-1)+node.Name, f.Amount(node.Total, cfg.FX.BaseCurrency),
+				strconv.Itoa(node.Count), fmt.Sprintf("%.1f%%", node.Share(parent)))
+		})
+		table.Row("Total", f.Amount(tree.Total, cfg.FX.BaseCurrency), strconv.Itoa(tree.Count), "")
+		table.Render(w)
+
+	case "json":
+		tree.Prune(depth)
+		data, err := json.MarshalIndent(tree, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report to JSON: %w", err)
+		}
+		fmt.Fprintln(w, string(data))
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"category", "subcategory", "merchant", "amount", "count"})
+		path := make([]string, 3)
+		tree.Walk(depth, func(node, parent *report.Node, level int) {
+			path[level-1] = node.Name
+			for i := level; i < len(path); i++ {
+				path[i] = ""
+			}
+			cw.Write(append(append([]string{}, path...), node.Total.String(), strconv.Itoa(node.Count)))
+		})
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table, json, or csv", format)
+	}
+
+	return nil
+}
+
+// runReportSummary prints the summary of a period and optionally sends it
+func runReportSummary(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	for _, channel := range reportSend {
+		if _, ok := cfg.Alerts.Channels[strings.ToLower(channel)]; !ok {
+			return fmt.Errorf("unknown alert channel %q", channel)
+		}
+	}
+
+	from, to, err := reportPeriod(cfg, reportPeriodName, reportMonth, reportFrom, reportTo)
+	if err != nil {
+		return err
+	}
+
+	scope, err := reportScope(cfg, reportGroups, reportEntity)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Usage, err)
+	}
+
+	summary, err := periodSummary(cfg, from, to, scope, reportEntity)
+	if err != nil {
+		return err
+	}
+	if err := writeSummary(os.Stdout, display.Output(cmd, cfg.Display), cfg, summary); err != nil {
+		return err
+	}
+
+	if len(reportSend) == 0 {
+		return nil
+	}
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would send the summary to %s\n", strings.Join(reportSend, ", "))
+		return nil
+	}
+	var body strings.Builder
+	if err := writeSummary(&body, "table", cfg, summary); err != nil {
+		return err
+	}
+	f := display.New(cfg.Display)
+	notice := alerts.Alert{
+		Rule:    "summary",
+		Message: fmt.Sprintf("Summary for %s to %s", f.Date(from), f.Date(to)),
+		Period:  from.Format("2006-01-02"),
+		At:      time.Now(),
+		Body:    body.String(),
+	}
+	for _, channel := range reportSend {
+		name := strings.ToLower(channel)
+		if err := alerts.Notify(cmd.Context(), name, cfg.Alerts.Channels[name], cfg.Alerts.SMTP, notice); err != nil {
+			return err
+		}
+		if !IsQuiet() {
+			fmt.Printf("📬 Sent the summary to %s\n", name)
+		}
+	}
+	return nil
+}
+
+// periodSummary builds the summary of [from, to] from the local store. With
+// an entity, only that entity's goals are included.
+func periodSummary(cfg *config.Config, from, to time.Time, scope func(*blend.Transaction) bool, entity string) (*report.Summary, error) {
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Goals count everything since their start; otherwise the summary only
+	// needs the periods it compares against
+	historyFrom, _ := report.ShiftRange(from, to, -3)
+	if len(cfg.Goals) > 0 {
+		historyFrom = time.Time{}
+	}
+	flags := blend.TransactionFilters{ExcludeCashflowExcluded: true}
+	var selected []blend.Transaction
+	for _, txn := range reportTransactions(cfg, st.Transactions(), scope) {
+		if flags.Allows(&txn) && !txn.TxnTimestamp.Before(historyFrom) && !txn.TxnTimestamp.After(to) {
+			selected = append(selected, txn)
+		}
+	}
+	inBase, err := inBaseCurrency(cfg, selected)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := cfg.RoundingMode()
+	summary := report.Summarize(inBase, from, to, cfg.FX.BaseCurrency, mode)
+
+	for _, name := range goalNames(cfg, entity) {
+		p, err := goalProgress(cfg, name, inBase, to)
+		if err != nil {
+			return nil, err
+		}
+		summary.Goals = append(summary.Goals, p)
+	}
+
+	if len(cfg.Alerts.Rules) > 0 {
+		triggered, err := alerts.Evaluate(cfg.Alerts, st.Find(store.Query{}), to, mode)
+		if err != nil {
+			return nil, err
+		}
+		for _, alert := range triggered {
+			summary.Anomalies = append(summary.Anomalies, alert.Message)
+		}
+	}
+	return summary, nil
+}
+
+// writeSummary renders a period summary in the given format
+func writeSummary(w io.Writer, format string, cfg *config.Config, s *report.Summary) error {
+	f := display.New(cfg.Display)
+	base := cfg.FX.BaseCurrency
+
+	switch format {
+	case "table":
+		fmt.Fprintf(w, "📬 Summary, %s to %s\n\n", f.Date(s.From), f.Date(s.To))
+		spent := f.Amount(s.Spent, base)
+		if s.PreviousSpent > 0 {
+			change := (float64(s.Spent) - float64(s.PreviousSpent)) / float64(s.PreviousSpent) * 100
+			spent += fmt.Sprintf(" (%+.0f%% vs %s the period before)", change, f.Amount(s.PreviousSpent, base))
+		}
+		fmt.Fprintf(w, "💸 Spent:        %s\n", spent)
+		fmt.Fprintf(w, "💰 Income:       %s\n", f.Amount(s.Income, base))
+		fmt.Fprintf(w, "🧾 Transactions: %d\n", s.Transactions)
+
+		if len(s.Categories) > 0 {
+			fmt.Fprintln(w, "\nTop categories")
+			table := f.Table(display.Column{Header: "Category"}, display.Column{Header: "Amount", Right: true},
+				display.Column{Header: "3-Period Avg", Right: true}, display.Column{Header: "Txns", Right: true})
+			for _, c := range s.Categories {
+				table.Row(c.Name, f.Amount(c.Total, base), f.Amount(c.Average, base), strconv.Itoa(c.Count))
+			}
+			table.Render(w)
+		}
+
+		if len(s.Goals) > 0 {
+			fmt.Fprintln(w, "\nSavings goals")
+			table := f.Table(display.Column{Header: "Goal"}, display.Column{Header: "Saved", Right: true},
+				display.Column{Header: "Target", Right: true}, display.Column{Header: "%", Right: true}, display.Column{Header: "Status"})
+			for _, p := range s.Goals {
+				table.Row(p.Name, f.Amount(p.Saved, base), f.Amount(p.Target, base), fmt.Sprintf("%.0f%%", p.Percent()),
+					goalStatusIcon(p.Status)+" "+p.Status)
+			}
+			table.Render(w)
+		}
+
+		fmt.Fprintln(w)
+		if len(s.Anomalies) == 0 {
+			fmt.Fprintln(w, "✅ Nothing unusual")
+			break
+		}
+		fmt.Fprintln(w, "⚠️  Anomalies")
+		for _, anomaly := range s.Anomalies {
+			fmt.Fprintf(w, "  - %s\n", anomaly)
+		}
+
+	case "json":
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal summary to JSON: %w", err)
+		}
+		fmt.Fprintln(w, string(data))
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table or json", format)
+	}
+
+	return nil
+}
+
+// runReportMerchant prints one merchant's spending trend
+func runReportMerchant(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	if reportMonths < 1 {
+		return fmt.Errorf("--months must be positive")
+	}
+	if report.MerchantKey(args[0]) == "" {
+		return fmt.Errorf("merchant name %q has no letters or digits", args[0])
+	}
+
+	scope, err := reportScope(cfg, reportGroups, reportEntity)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Usage, err)
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -(reportMonths - 1), 0)
+	flags := blend.TransactionFilters{ExcludeCashflowExcluded: true}
+	var selected []blend.Transaction
+	for _, txn := range reportTransactions(cfg, st.Transactions(), scope) {
+		if txn.Type == blend.TransactionTypeOutgoing && flags.Allows(&txn) && !txn.TxnTimestamp.Before(from) {
+			selected = append(selected, txn)
+		}
+	}
+	if selected, err = inBaseCurrency(cfg, selected); err != nil {
+		return err
+	}
+
+	base := cfg.FX.BaseCurrency
+	spend := report.MerchantDrillDown(selected, args[0], reportExact, reportMonths, now, base, cfg.RoundingMode())
+
+	switch format := display.Output(cmd, cfg.Display); format {
+	case "table":
+		if spend.Count == 0 {
+			fmt.Printf("📭 No payments to a merchant matching %q in the last %d month(s)\n", args[0], reportMonths)
+			break
+		}
+		f := display.New(cfg.Display)
+		fmt.Printf("🏪 %s, last %d month(s)\n", strings.Join(spend.Merchants, ", "), reportMonths)
+		fmt.Printf("💸 Total:          %s\n", f.Amount(spend.Total, base))
+		fmt.Printf("🧾 Payments:       %d\n", spend.Count)
+		fmt.Printf("🎫 Average ticket: %s\n", f.Amount(spend.Average, base))
+		fmt.Printf("🔝 Largest:        %s\n", f.Amount(spend.Largest, base))
+		fmt.Printf("📅 First / last:   %s / %s\n\n", f.Date(*spend.First), f.Date(*spend.Last))
+
+		var peak money.Amount
+		for _, month := range spend.Months {
+			if month.Total > peak {
+				peak = month.Total
+			}
+		}
+		table := f.Table(display.Column{Header: "Month"}, display.Column{Header: "Amount", Right: true},
+			display.Column{Header: "Txns", Right: true}, display.Column{Header: "Trend"})
+		for _, month := range spend.Months {
+			width := 0
+			if peak > 0 {
+				width = int(float64(month.Total) / float64(peak) * merchantTrendWidth)
+			}
+			table.Row(month.Month, f.Amount(month.Total, base), strconv.Itoa(month.Count), strings.Repeat("█", width))
+		}
+		table.Render(os.Stdout)
+
+	case "json":
+		data, err := json.MarshalIndent(spend, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal merchant report to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table or json", format)
+	}
+
+	return nil
+}
+
+// merchantTrendWidth is the length of the bar of the month with the most spend
+const merchantTrendWidth = 30
+
+// runReportScheduled runs the due report jobs
+func runReportScheduled(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	if len(cfg.Reports.Schedule) == 0 {
+		fmt.Println("No scheduled reports. Add jobs under reports.schedule in the config file")
+		return nil
+	}
+
+	written, errs := runReportJobs(cfg, time.Now(), reportForce)
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+	}
+	if len(written) == 0 && len(errs) == 0 && !IsQuiet() && !IsDryRun() {
+		fmt.Println("✅ No report jobs due")
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d report job error(s)", len(errs))
+	}
+	return nil
+}
+
+// syncReports runs the due report jobs after a sync, recording failures in the report
+func syncReports(cfg *config.Config, report *syncreport.Report) {
+	if len(cfg.Reports.Schedule) == 0 {
+		return
+	}
+	_, errs := runReportJobs(cfg, time.Now(), false)
+	for _, err := range errs {
+		report.AddError(err)
+	}
+}
+
+// runReportJobs generates every report job due at now (all of them when
+// force is set), notifies their channels and returns the files written
+func runReportJobs(cfg *config.Config, now time.Time, force bool) ([]string, []error) {
+	state, err := alerts.LoadState(cfg.Alerts.StateFile)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	names := make([]string, 0, len(cfg.Reports.Schedule))
+	for name := range cfg.Reports.Schedule {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var written []string
+	var errs []error
+	for _, name := range names {
+		job := cfg.Reports.Schedule[name]
+		from, to, period, due := reportJobPeriod(job, now)
+
+		notice := alerts.Alert{
+			Rule:     "report:" + name,
+			Period:   period,
+			Channels: job.Channels,
+			At:       now,
+			File:     strings.ReplaceAll(job.Path, "{period}", period),
+		}
+		if !force && (!due || !state.Due(notice)) {
+			continue
+		}
+
+		if IsDryRun() {
+			fmt.Printf("📄 Would write %s report for %s to %s\n", name, period, notice.File)
+			continue
+		}
+
+		body, err := writeReportJob(cfg, job, notice.File, from, to)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("report %s: %w", name, err))
+			continue
+		}
+		written = append(written, notice.File)
+		if !IsQuiet() {
+			fmt.Printf("📄 Wrote %s report for %s to %s\n", name, period, notice.File)
+		}
+
+		notice.Message = fmt.Sprintf("%s report for %s written to %s", name, period, notice.File)
+		notice.Body = body
+		if force {
+			// A forced run is sent even if this period was sent before
+			delete(state.Sent, notice.Rule)
+		}
+		_, deliveryErrs := alerts.Deliver(context.Background(), cfg.Alerts, state, []alerts.Alert{notice})
+		errs = append(errs, deliveryErrs...)
+	}
+
+	if err := state.Save(); err != nil {
+		errs = append(errs, err)
+	}
+	return written, errs
+}
+
+// writeReportJob generates one job's report into path. For summaries it
+// returns the summary text, which channels such as email include in full.
+func writeReportJob(cfg *config.Config, job config.ReportJob, path string, from, to time.Time) (string, error) {
+	depth := job.Depth
+	if depth == 0 {
+		depth = 3
+	}
+	format := strings.ToLower(job.Format)
+	if format == "" {
+		format = "table"
+	}
+
+	// Scheduled reports leave out excluded accounts, as the commands do
+	scope, err := reportScope(cfg, nil, job.Entity)
+	if err != nil {
+		return "", err
+	}
+
+	var write func(io.Writer) error
+	var body strings.Builder
+	if strings.EqualFold(job.Report, "summary") {
+		summary, err := periodSummary(cfg, from, to, scope, job.Entity)
+		if err != nil {
+			return "", err
+		}
+		if err := writeSummary(&body, "table", cfg, summary); err != nil {
+			return "", err
+		}
+		write = func(w io.Writer) error { return writeSummary(w, format, cfg, summary) }
+	} else {
+		tree, err := spendingTree(cfg, from, to, job.Income, scope, store.TagFilter{}, blend.TransactionFilters{ExcludeCashflowExcluded: true})
+		if err != nil {
+			return "", err
+		}
+		write = func(w io.Writer) error { return writeTree(w, format, cfg, tree, from, to, job.Income, depth) }
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create report file: %w", err)
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return "", err
+	}
+	return body.String(), file.Close()
+}
+
+// reportJobPeriod returns the period a job reports on at now (the previous
+// week or month), its name, and whether the job's run day has been reached
+func reportJobPeriod(job config.ReportJob, now time.Time) (time.Time, time.Time, string, bool) {
+	day := job.Day
+	if day == 0 {
+		day = 1
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var current, previous time.Time
+	var period string
+	if strings.EqualFold(job.Every, "weekly") {
+		current = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		previous = current.AddDate(0, 0, -7)
+		year, week := previous.ISOWeek()
+		period = fmt.Sprintf("%d-W%02d", year, week)
+	} else {
+		current = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		previous = current.AddDate(0, -1, 0)
+		period = previous.Format("2006-01")
+	}
+
+	due := !today.Before(current.AddDate(0, 0, day-1))
+	return previous, current.Add(-time.Nanosecond), period, due
+}
+
+// reportPeriod resolves --period, --month or --from/--to into an inclusive
+// time range, defaulting to the current month
+func reportPeriod(cfg *config.Config, period, month, fromDate, toDate string) (time.Time, time.Time, error) {
+	if month != "" && (fromDate != "" || toDate != "") {
+		return time.Time{}, time.Time{}, fmt.Errorf("use either --month or --from/--to")
+	}
+
+	now := time.Now()
+	if period != "" {
+		if month != "" || fromDate != "" || toDate != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("use either --period, --month or --from/--to")
+		}
+		from, to, ok, err := cfg.Periods.Resolve(period, now)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if !ok {
+			return time.Time{}, time.Time{}, fmt.Errorf("unknown period %q (use fy, this_fy, last_fy, fyYYYY, q1-q4, fyYYYYq1, this_quarter, last_quarter or a periods.custom name)", period)
+		}
+		return from, to, nil
+	}
+
+	if month == "" && fromDate == "" && toDate == "" {
+		month = now.Format("2006-01")
+	}
+
+	if month != "" {
+		start, err := time.ParseInLocation("2006-01", month, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid month %q (use YYYY-MM)", month)
+		}
+		return start, start.AddDate(0, 1, 0).Add(-time.Nanosecond), nil
+	}
+
+	from := time.Time{}
+	to := now
+	var err error
+	if fromDate != "" {
+		if from, err = time.ParseInLocation("2006-01-02", fromDate, time.Local); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --from date %q (use YYYY-MM-DD)", fromDate)
+		}
+	}
+	if toDate != "" {
+		if to, err = time.ParseInLocation("2006-01-02", toDate, time.Local); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --to date %q (use YYYY-MM-DD)", toDate)
+		}
+		to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--to is before --from")
+	}
+	return from, to, nil
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/staging"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// REPROCESS COMMAND DEFINITION
+// =============================================================================
+
+// reprocessCmd re-parses archived raw responses and staged transactions
+var reprocessCmd = &cobra.Command{
+	Use:   "reprocess",
+	Short: "Re-parse archived raw responses and staged data with the current models",
+	Long: `Re-run response parsing over raw API responses archived with bend.archive_raw
+and over the transactions already in the staging directory, so parsing fixes in
+newer releases apply to history without fetching it from Bend again.
+
+Every staging file fetched since --since is rewritten through the current
+models. Where the archive holds the raw response a staged transaction came
+from, the freshly parsed copy replaces it. Archived transactions missing from
+the staging directory are written to a new reprocessed_*.json staging file.
+
+Examples:
+  fintrack reprocess --since 2024-01
+  fintrack reprocess --since 2024-01-15 --dry-run`,
+	RunE: runReprocess,
+}
+
+var (
+	reprocessSince      string
+	reprocessStagingDir string
+)
+
+func init() {
+	reprocessCmd.Flags().StringVar(&reprocessSince, "since", "", "Only reprocess data fetched from this month or day (YYYY-MM or YYYY-MM-DD)")
+	reprocessCmd.Flags().StringVar(&reprocessStagingDir, "staging-dir", "", "Staging directory to rewrite (default: paths.staging_dir)")
+}
+
+// =============================================================================
+// REPROCESS COMMAND IMPLEMENTATION
+// =============================================================================
+
+// archivedTransaction is a transaction parsed from an archived response
+type archivedTransaction struct {
+	txn       blend.Transaction
+	fetchedAt time.Time
+}
+
+// runReprocess re-parses the archive and rewrites staging files
+func runReprocess(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	since, err := parseSince(reprocessSince)
+	if err != nil {
+		return err
+	}
+
+	dir := reprocessStagingDir
+	if dir == "" {
+		dir = cfg.Paths.StagingDir
+	}
+
+	archived, err := parseArchivedTransactions(cfg, since)
+	if err != nil {
+		return err
+	}
+
+	files, err := staging.Files(dir)
+	if err != nil {
+		return err
+	}
+
+	rewritten, replaced := 0, 0
+	staged := make(map[string]bool)
+	for _, path := range files {
+		file, err := staging.ReadFile(path)
+		if err != nil || file.FetchedAt.Before(since) {
+			continue
+		}
+
+		for i, txn := range file.Transactions {
+			staged[txn.UUID] = true
+			// Only use raw data the staged copy could have come from
+			if versions, ok := archived[txn.UUID]; ok {
+				if latest, found := latestArchivedBefore(versions, file.FetchedAt); found {
+					file.Transactions[i] = latest
+					replaced++
+				}
+			}
+		}
+
+		if !IsDryRun() {
+			if err := staging.Write(path, file); err != nil {
+				return fmt.Errorf("failed to rewrite %s: %w", path, err)
+			}
+		}
+		rewritten++
+	}
+
+	// Transactions only present in the archive become a new staging file
+	var missing []blend.Transaction
+	var newest time.Time
+	for uuid, versions := range archived {
+		if staged[uuid] {
+			continue
+		}
+		latest := versions[len(versions)-1]
+		missing = append(missing, latest.txn)
+		if latest.fetchedAt.After(newest) {
+			newest = latest.fetchedAt
+		}
+	}
+
+	if len(missing) > 0 && !IsDryRun() {
+		file := staging.NewFile(missing, nil, since, newest)
+		file.FetchedAt = newest
+		path := filepath.Join(dir, fmt.Sprintf("reprocessed_%s.json", time.Now().Format("20060102_150405")))
+		if err := staging.Write(path, file); err != nil {
+			return fmt.Errorf("failed to save reprocessed transactions: %w", err)
+		}
+	}
+
+	if !IsQuiet() {
+		prefix := "✅"
+		if IsDryRun() {
+			prefix = "🔍 [dry-run]"
+		}
+		fmt.Printf("%s Rewrote %d staging file(s), replaced %d transaction(s) from the raw archive, recovered %d archived transaction(s)\n",
+			prefix, rewritten, replaced, len(missing))
+	}
+
+	return nil
+}
+
+// parseArchivedTransactions parses every archived transactions response since
+// the given time, returning each transaction's versions oldest first
+func parseArchivedTransactions(cfg *config.Config, since time.Time) (map[string][]archivedTransaction, error) {
+	dir := cfg.Bend.ArchiveDir
+	if dir == "" {
+		dir = blend.DefaultArchiveDir
+	}
+
+	responses, err := blend.ReadArchive(dir, since)
+	if err != nil {
+		return nil, err
+	}
+
+	archived := make(map[string][]archivedTransaction)
+	parsed := 0
+	for _, resp := range responses {
+		if resp.Status < 200 || resp.Status >= 300 || !strings.HasSuffix(resp.Path, "/transactions") {
+			continue
+		}
+
+		body, err := resp.Body()
+		if err != nil {
+			return nil, err
+		}
+
+		var response blend.TransactionsV3Response
+		if err := json.Unmarshal(body, &response); err != nil {
+			fmt.Printf("⚠️  Skipping %s fetched %s: %v\n", resp.Path, resp.FetchedAt.Format(time.RFC3339), err)
+			continue
+		}
+		if cfg.Bend.StrictDecode {
+			if fields := blend.UnknownFields(body, &response); len(fields) > 0 {
+				fmt.Printf("⚠️  %s fetched %s has fields missing from the models: %s\n",
+					resp.Path, resp.FetchedAt.Format(time.RFC3339), strings.Join(fields, ", "))
+			}
+		}
+
+		for _, txn := range response.Data.Transactions {
+			archived[txn.UUID] = append(archived[txn.UUID], archivedTransaction{txn: txn, fetchedAt: resp.FetchedAt})
+		}
+		parsed++
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("📦 Parsed %d archived transaction response(s) from %s\n", parsed, dir)
+	}
+
+	return archived, nil
+}
+
+// latestArchivedBefore returns the newest archived version fetched no later than t
+func latestArchivedBefore(versions []archivedTransaction, t time.Time) (blend.Transaction, bool) {
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !versions[i].fetchedAt.After(t) {
+			return versions[i].txn, true
+		}
+	}
+	return blend.Transaction{}, false
+}
+
+// parseSince parses a YYYY-MM or YYYY-MM-DD lower bound. Empty means everything.
+func parseSince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (use YYYY-MM or YYYY-MM-DD)", value)
+}
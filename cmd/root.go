@@ -3,8 +3,20 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/quickkly/fintrack/internal/alerts"
+	"github.com/quickkly/fintrack/internal/blend"
 	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/exitcode"
+	"github.com/quickkly/fintrack/internal/logging"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/plugin"
+	"github.com/quickkly/fintrack/internal/profile"
+	"github.com/quickkly/fintrack/internal/store"
+	"github.com/quickkly/fintrack/internal/telemetry"
+	"github.com/quickkly/fintrack/internal/usage"
 
 	"github.com/spf13/cobra"
 )
@@ -16,6 +28,17 @@ var (
 	dryRun  bool
 	quiet   bool
 	logHTTP bool
+
+	strictDecode bool
+	noCache      bool
+	envName      string
+	sessionName  string
+	profileRun   bool
+	tzName       string
+
+	excludeAccounts []string
+
+	debugBundleDir string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -39,26 +62,129 @@ For more information, visit the project documentation.`,
 
 // setupRootCommand initializes the root command and loads configuration
 func setupRootCommand(cmd *cobra.Command, args []string) error {
+	if err := startDebugBundle(); err != nil {
+		return err
+	}
+	if profileRun {
+		profile.Enable()
+	}
+	stopConfig := profile.Start(profile.Config)
+
 	// Load configuration
-	cfg, err := config.Load(cfgFile)
+	cfg, err := config.LoadEnvironment(configFilePath(), envName)
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	if envName != "" {
+		cfg.SetSource("environment", config.SourceFlag, "--env")
+	}
+	if sessionName != "" {
+		if err := cfg.SelectSession(sessionName); err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+		cfg.SetSource("session", config.SourceFlag, "--session")
+		cfg.SetSource("bend.session_file", config.SourceFlag, "--session")
+	}
+
+	// Command-line overrides
+	if strictDecode {
+		cfg.Bend.StrictDecode = true
+		cfg.SetSource("bend.strict_decode", config.SourceFlag, "--strict-decode")
+	}
+	if noCache {
+		cfg.Bend.Cache = false
+		cfg.SetSource("bend.cache", config.SourceFlag, "--no-cache")
+	}
+	if tzName != "" {
+		cfg.Timezone = tzName
+		cfg.SetSource("timezone", config.SourceFlag, "--tz")
+	}
+	if len(excludeAccounts) > 0 {
+		cfg.Accounts.Exclude = append(cfg.Accounts.Exclude, excludeAccounts...)
+		cfg.SetSource("accounts.exclude", config.SourceFlag, "--exclude-account")
 	}
 
 	// Validate configuration
 	if err := validateConfiguration(cfg); err != nil {
-		return fmt.Errorf("configuration validation failed: %w", err)
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("configuration validation failed: %w", err))
+	}
+	stopConfig()
+
+	// Every date from here on, parsed, formatted or bucketed into days, is in
+	// the user's timezone
+	if err := config.UseTimezone(cfg.Timezone); err != nil {
+		return exitcode.Wrap(exitcode.Usage, err)
 	}
 
 	// Store configuration in command context
 	config.SetInContext(cmd, cfg)
+	loadedConfig = cfg
+
+	if err := writeBundleConfig(cfg); err != nil {
+		return err
+	}
+
+	// Encrypted stores get their passphrase from the environment, keychain or a prompt
+	store.SetPassphraseSource(newStorePassphrases(cfg))
+	plugin.Configure(cfg.Plugins)
 
 	// Set up logging based on flags
-	setupLogging()
+	if err := setupLogging(cmd, cfg); err != nil {
+		return err
+	}
+
+	return setupTelemetry(cfg)
+}
+
+// Telemetry state for the running command, see setupTelemetry
+var (
+	metrics      *telemetry.Metrics
+	tracer       *telemetry.Tracer
+	commandStart time.Time
+)
+
+// setupTelemetry installs tracing middleware on every Bend client when
+// configured. Ad-hoc runs without telemetry settings are unaffected.
+func setupTelemetry(cfg *config.Config) error {
+	commandStart = time.Now()
+
+	if endpoint := cfg.Telemetry.OTLPEndpoint; endpoint != "" {
+		tracer = telemetry.NewTracer(endpoint, cfg.Telemetry.ServiceName)
+		blend.RegisterMiddleware(tracer.Middleware)
+	}
 
 	return nil
 }
 
+// serveMetrics exposes /metrics on telemetry.metrics_addr, when configured,
+// and installs the metrics middleware. Only long-running commands call it:
+// a one-off command would hold the port for nothing, and two at once would
+// fight over it.
+func serveMetrics(cfg *config.Config) error {
+	addr := cfg.Telemetry.MetricsAddr
+	if addr == "" || metrics != nil {
+		return nil
+	}
+	metrics = telemetry.NewMetrics()
+	if _, err := metrics.Serve(addr); err != nil {
+		return err
+	}
+	blend.RegisterMiddleware(metrics.Middleware)
+	return nil
+}
+
+// finishTelemetry records the command's duration and exports pending spans
+func finishTelemetry(cmd *cobra.Command, err error) {
+	if metrics != nil {
+		metrics.ObserveCommand(cmd.CommandPath(), time.Since(commandStart), err)
+	}
+	if tracer != nil {
+		if err := tracer.Flush(); err != nil && IsVerbose() {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+}
+
 // validateConfiguration performs basic validation of the loaded configuration
 func validateConfiguration(cfg *config.Config) error {
 	if cfg == nil {
@@ -70,24 +196,259 @@ func validateConfiguration(cfg *config.Config) error {
 		return fmt.Errorf("bend.base_url is required")
 	}
 
+	if origin := cfg.Bend.Origin; origin != "" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+		return fmt.Errorf("bend.origin must be a valid HTTP/HTTPS URL")
+	}
+
+	if err := blend.ValidateHeaders(cfg.Bend.Headers); err != nil {
+		return err
+	}
+
 	if cfg.Bend.Timeout <= 0 {
 		return fmt.Errorf("bend.timeout must be positive")
 	}
 
+	if cfg.Bend.CacheTTL < 0 {
+		return fmt.Errorf("bend.cache_ttl cannot be negative")
+	}
+
+	if cfg.Bend.PageSize < 0 || cfg.Bend.MaxPages < 0 {
+		return fmt.Errorf("bend.page_size and bend.max_pages cannot be negative")
+	}
+
+	if cfg.Bend.CircuitThreshold < 0 {
+		return fmt.Errorf("bend.circuit_threshold cannot be negative")
+	}
+
+	if cfg.Bend.CircuitThreshold > 0 && cfg.Bend.CircuitCooldown <= 0 {
+		return fmt.Errorf("bend.circuit_cooldown must be positive")
+	}
+
+	if t := cfg.Bend.Transport; t.MaxIdleConns < 0 || t.MaxIdleConnsPerHost < 0 || t.MaxConnsPerHost < 0 {
+		return fmt.Errorf("bend.transport connection limits cannot be negative")
+	}
+
+	if t := cfg.Bend.Transport; t.IdleConnTimeout < 0 || t.KeepAlive < 0 {
+		return fmt.Errorf("bend.transport.idle_conn_timeout and bend.transport.keep_alive cannot be negative")
+	}
+
+	if err := cfg.ValidatePaths(); err != nil {
+		return err
+	}
+
+	if err := cfg.ValidateAccounts(); err != nil {
+		return err
+	}
+
+	if err := cfg.ValidateGoals(); err != nil {
+		return err
+	}
+
+	if err := alerts.Validate(cfg.Alerts); err != nil {
+		return err
+	}
+
+	if err := cfg.ValidateBills(); err != nil {
+		return err
+	}
+
+	if err := cfg.ValidateConsent(); err != nil {
+		return err
+	}
+
+	if err := store.ValidateBackend(cfg.Store.Backend); err != nil {
+		return err
+	}
+
+	if err := cfg.ValidateTimeseries(); err != nil {
+		return err
+	}
+
+	if err := cfg.ValidateForecast(); err != nil {
+		return err
+	}
+
+	if err := cfg.ValidateRefunds(); err != nil {
+		return err
+	}
+
+	if err := cfg.ValidateContacts(); err != nil {
+		return err
+	}
+
+	if err := cfg.ValidateReceipts(); err != nil {
+		return err
+	}
+
+	if err := cfg.ValidateReports(); err != nil {
+		return err
+	}
+
+	if err := cfg.ValidateImporters(); err != nil {
+		return err
+	}
+
+	if err := cfg.ValidatePlugins(); err != nil {
+		return err
+	}
+
+	if _, err := money.ParseRounding(cfg.Money.Rounding); err != nil {
+		return fmt.Errorf("money.rounding: %w", err)
+	}
+
+	if err := cfg.Display.Validate(); err != nil {
+		return err
+	}
+
+	if format := cfg.Logging.Format; format != "" && format != "text" && format != "json" {
+		return fmt.Errorf("logging.format must be text or json")
+	}
+
+	if _, err := config.LoadTimezone(cfg.Timezone); err != nil {
+		return fmt.Errorf("timezone: %w", err)
+	}
+
+	if err := cfg.Periods.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-// setupLogging configures logging based on global flags
-func setupLogging() {
-	// This could be expanded to set up structured logging
-	// For now, we just use the global flags
+// jsonLog captures console output as JSON records when logging.format is json
+var jsonLog *logging.JSON
+
+// setupLogging switches console output to JSON records for log collectors
+func setupLogging(cmd *cobra.Command, cfg *config.Config) error {
+	if cfg.Logging.Format != "json" {
+		return nil
+	}
+
+	var err error
+	jsonLog, err = logging.StartJSON(cmd.CommandPath())
+	if err != nil {
+		return fmt.Errorf("failed to set up JSON logging: %w", err)
+	}
+	return nil
+}
+
+// finishLogging logs the command's outcome and restores console output
+func finishLogging(err error, code int) {
+	if jsonLog == nil {
+		return
+	}
+
+	logger := jsonLog.Logger()
+	jsonLog.Stop()
+	jsonLog = nil
+
+	if err != nil {
+		logger.Error("command failed", "error", err.Error(), "exit_code", code, "error_class", exitcode.Name(code),
+			"duration_ms", time.Since(commandStart).Milliseconds())
+		return
+	}
+	logger.Info("command finished", "duration_ms", time.Since(commandStart).Milliseconds())
+}
+
+// loadedConfig is the configuration of the running command, kept for
+// bookkeeping that happens after the command returns
+var loadedConfig *config.Config
+
+// SetVersion sets the version shown by --version and sent in the User-Agent
+func SetVersion(version string) {
+	rootCmd.Version = version
+	blend.Version = version
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
+// The process exits with the error's code from the exitcode package.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	tagUsageErrors(rootCmd)
+
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil && strings.HasPrefix(err.Error(), "unknown command ") {
+		err = exitcode.Wrap(exitcode.Usage, err)
+	}
+	if profileRun {
+		profile.Print(os.Stderr, time.Since(start))
+	}
+	finishDebugBundle(cmd, err, time.Since(start))
+	recordUsage(cmd)
+	recordStats(cmd, err)
+	finishTelemetry(cmd, err)
+
+	code := exitcode.Of(err)
+	if jsonLog != nil {
+		finishLogging(err, code)
+		os.Exit(code)
+	}
+	if err != nil {
+		printError(err, code)
+	}
+	os.Exit(code)
+}
+
+// printError reports the command's error on stderr. In quiet mode it is one
+// line led by the exit code's name, for scripts and cron mail.
+func printError(err error, code int) {
+	if IsQuiet() {
+		fmt.Fprintf(os.Stderr, "fintrack: %s: %v\n", exitcode.Name(code), err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+}
+
+// tagUsageErrors marks the argument errors of cmd and its subcommands as
+// usage errors. Unknown commands are tagged in Execute.
+func tagUsageErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(c *cobra.Command, args []string) error {
+			return exitcode.Wrap(exitcode.Usage, validate(c, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		tagUsageErrors(sub)
+	}
+}
+
+// recordUsage adds this run's API traffic to the cumulative usage file
+func recordUsage(cmd *cobra.Command) {
+	run := blend.RunUsage()
+	if loadedConfig == nil || run.Requests == 0 {
+		return
+	}
+
+	if IsVerbose() {
+		fmt.Fprintf(os.Stderr, "📶 %d request(s), %s downloaded, %s uploaded\n",
+			run.Requests, usage.FormatBytes(run.BytesReceived), usage.FormatBytes(run.BytesSent))
+	}
+
+	err := usage.Record(loadedConfig.Bend.UsageFile, usage.Run{
+		Command: cmd.CommandPath(),
+		At:      time.Now(),
+		Usage:   run,
+	})
+	if err != nil && IsVerbose() {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// recordStats adds this run to the usage stats when the user opted in. Only the
+// command, its outcome and error class are kept, never arguments or data.
+func recordStats(cmd *cobra.Command, err error) {
+	if loadedConfig == nil || !loadedConfig.Telemetry.Stats || IsDryRun() {
+		return
+	}
+
+	event := telemetry.NewStatsEvent(cmd.CommandPath(), time.Since(commandStart), telemetry.ClassifyError(err))
+	if err := telemetry.RecordStats(loadedConfig.Telemetry.StatsFile, event); err != nil && IsVerbose() {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if endpoint := loadedConfig.Telemetry.StatsEndpoint; endpoint != "" {
+		if err := telemetry.SendStats(endpoint, event); err != nil && IsVerbose() {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
 	}
 }
 
@@ -98,11 +459,22 @@ func init() {
 
 // setupGlobalFlags configures all global flags
 func setupGlobalFlags() {
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return exitcode.Wrap(exitcode.Usage, err)
+	})
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ~/.config/fintrack/config.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "show what would happen without executing")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress output except errors")
 	rootCmd.PersistentFlags().BoolVar(&logHTTP, "log-http", false, "enable HTTP request/response logging")
+	rootCmd.PersistentFlags().StringVar(&envName, "env", "", "Bend environment from the environments: block (e.g. sandbox)")
+	rootCmd.PersistentFlags().StringVar(&sessionName, "session", "", "named Bend session to use, with its own session file and device hash (e.g. work)")
+	rootCmd.PersistentFlags().StringVar(&tzName, "tz", "", "timezone for dates and day boundaries, e.g. Asia/Kolkata (default: timezone from the config, else the system's)")
+	rootCmd.PersistentFlags().StringSliceVar(&excludeAccounts, "exclude-account", nil, "skip this account (UUID or nickname) in sync, reports and net worth, on top of accounts.exclude (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "don't use the on-disk HTTP cache for this run")
+	rootCmd.PersistentFlags().BoolVar(&profileRun, "profile-run", false, "print where the run's time went (config, auth, API, parse, write) on exit")
+	rootCmd.PersistentFlags().StringVar(&debugBundleDir, "debug-bundle", "", "write sanitized API requests and responses, timings and the config into this directory for a bug report")
+	rootCmd.PersistentFlags().BoolVar(&strictDecode, "strict-decode", false, "fail on API response fields the models don't know (reports every unknown field)")
 
 	// Mark config flag as deprecated in favor of environment variable
 	rootCmd.PersistentFlags().MarkDeprecated("config", "use FINTRACK_CONFIG environment variable instead")
@@ -113,12 +485,49 @@ func setupSubcommands() {
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(bendCmd)
+	rootCmd.AddCommand(entityCmd)
+	rootCmd.AddCommand(accountsCmd)
+	rootCmd.AddCommand(fxCmd)
+	rootCmd.AddCommand(reprocessCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(healthzCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(tagCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(goalsCmd)
+	rootCmd.AddCommand(alertsCmd)
+	rootCmd.AddCommand(billsCmd)
+	rootCmd.AddCommand(forecastCmd)
+	rootCmd.AddCommand(analyzeCmd)
+	rootCmd.AddCommand(contactsCmd)
+	rootCmd.AddCommand(splitCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(receiptsCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(storeCmd)
+	rootCmd.AddCommand(pathsCmd)
+	rootCmd.AddCommand(queryCmd)
+	rootCmd.AddCommand(pluginsCmd)
+	rootCmd.AddCommand(ignoreCmd)
 }
 
 // =============================================================================
 // GLOBAL FLAG ACCESSORS
 // =============================================================================
 
+// configFilePath returns the config file given with --config or
+// FINTRACK_CONFIG, or "" to search the default locations
+func configFilePath() string {
+	if cfgFile != "" {
+		return cfgFile
+	}
+	return os.Getenv("FINTRACK_CONFIG")
+}
+
 // IsVerbose returns whether verbose mode is enabled
 func IsVerbose() bool {
 	return verbose
@@ -0,0 +1,531 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/store"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// SPLIT COMMAND DEFINITIONS
+// =============================================================================
+
+// splitCmd groups shared expense commands
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split shared expenses and track who owes whom",
+	Long: `Track expenses shared with other people over the transactions in the local
+store, like a lightweight Splitwise.
+
+Mark a payment as shared with a person and their share is added to what they
+owe you; mark a transfer as a settlement and it squares up the balance. Splits
+are local, like tags, and never sent to Bend.
+
+Available subcommands:
+- add: Share a transaction with a person
+- settle: Mark a transaction as settling up with a person
+- remove: Remove the split from transactions
+- list: Show split transactions
+- balances: Show who owes whom
+- export: Write a settlement summary (CSV or JSON)`,
+}
+
+// splitAddCmd shares a transaction
+var splitAddCmd = &cobra.Command{
+	Use:   "add <transaction-uuid> <person>",
+	Short: "Share a transaction with a person",
+	Long: `Share a transaction with a person. For a payment, their --share (half by
+default) is added to what they owe you; for money you received on their
+behalf, it's added to what you owe them.
+
+Examples:
+  fintrack split add 91ab...-uuid ravi                  # Half each
+  fintrack split add 91ab...-uuid ravi --share 1200
+  fintrack split add 91ab...-uuid ravi --share 33% --note "electricity"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSplitAdd,
+}
+
+// splitSettleCmd marks a settlement payment
+var splitSettleCmd = &cobra.Command{
+	Use:   "settle <transaction-uuid> <person>",
+	Short: "Mark a transaction as settling up with a person",
+	Long: `Mark a transfer as a settlement: money received from the person reduces
+what they owe you, money paid to them reduces what you owe.
+
+Examples:
+  fintrack split settle 4c7d...-uuid ravi`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSplitSettle,
+}
+
+// splitRemoveCmd removes splits
+var splitRemoveCmd = &cobra.Command{
+	Use:   "remove <transaction-uuid>...",
+	Short: "Remove the split from transactions",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runSplitRemove,
+}
+
+// splitListCmd lists split transactions
+var splitListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List split transactions",
+	Example: `  fintrack split list
+  fintrack split list --person ravi`,
+	Args: cobra.NoArgs,
+	RunE: runSplitList,
+}
+
+// splitBalancesCmd shows balances per person
+var splitBalancesCmd = &cobra.Command{
+	Use:   "balances",
+	Short: "Show who owes whom",
+	Example: `  fintrack split balances
+  fintrack split balances --person ravi --output json`,
+	Args: cobra.NoArgs,
+	RunE: runSplitBalances,
+}
+
+// splitExportCmd writes the settlement summary
+var splitExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write a settlement summary",
+	Long: `Write a settlement summary: the balance with each person and the
+transactions behind it, as CSV (one row per transaction, then one per
+balance) or JSON.
+
+Examples:
+  fintrack split export --format csv --output settlement.csv
+  fintrack split export --person ravi --format json`,
+	Args: cobra.NoArgs,
+	RunE: runSplitExport,
+}
+
+var (
+	splitShare  string
+	splitNote   string
+	splitPerson string
+	splitOutput string
+	splitFormat string
+)
+
+func init() {
+	splitAddCmd.Flags().StringVar(&splitShare, "share", "50%", "Their share: a percentage (33%) or an amount")
+	splitAddCmd.Flags().StringVar(&splitNote, "note", "", "Note shown in lists and exports")
+	splitSettleCmd.Flags().StringVar(&splitNote, "note", "", "Note shown in lists and exports")
+
+	splitListCmd.Flags().StringVar(&splitPerson, "person", "", "Only this person's splits")
+	splitListCmd.Flags().StringVarP(&splitOutput, "output", "o", "table", "Output format (table, json; default from display.output)")
+	splitBalancesCmd.Flags().StringVar(&splitPerson, "person", "", "Only this person's balance")
+	splitBalancesCmd.Flags().StringVarP(&splitOutput, "output", "o", "table", "Output format (table, json; default from display.output)")
+	splitExportCmd.Flags().StringVar(&splitPerson, "person", "", "Only this person's settlement")
+	splitExportCmd.Flags().StringVar(&splitFormat, "format", "csv", "Summary format (csv, json)")
+	splitExportCmd.Flags().StringVarP(&splitOutput, "output", "o", "", "File to write (default: stdout)")
+
+	splitCmd.AddCommand(splitAddCmd)
+	splitCmd.AddCommand(splitSettleCmd)
+	splitCmd.AddCommand(splitRemoveCmd)
+	splitCmd.AddCommand(splitListCmd)
+	splitCmd.AddCommand(splitBalancesCmd)
+	splitCmd.AddCommand(splitExportCmd)
+}
+
+// =============================================================================
+// SPLIT COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runSplitAdd shares a transaction with a person
+func runSplitAdd(cmd *cobra.Command, args []string) error {
+	return setSplit(cmd, args[0], args[1], false)
+}
+
+// runSplitSettle marks a transaction as a settlement
+func runSplitSettle(cmd *cobra.Command, args []string) error {
+	return setSplit(cmd, args[0], args[1], true)
+}
+
+// setSplit records a share or settlement on a stored transaction
+func setSplit(cmd *cobra.Command, uuid, name string, settle bool) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	person, err := store.NormalizePerson(name)
+	if err != nil {
+		return err
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := st.Checkpoint()
+	if err != nil {
+		return err
+	}
+	record, ok := st.Records[uuid]
+	if !ok || record.DeletedAt != nil {
+		return fmt.Errorf("transaction %s is not in the local store", uuid)
+	}
+	txn := record.Transaction
+
+	split := &store.Split{With: person, Settle: settle, Note: splitNote}
+	if !settle {
+		share, err := parseShare(splitShare, txn.Money(cfg.RoundingMode()), cfg.RoundingMode())
+		if err != nil {
+			return err
+		}
+		split.Share = share.Float64()
+	}
+
+	previous := record.Split
+	record.Split = split
+	owed := record.SplitOwed(cfg.RoundingMode())
+	f := display.New(cfg.Display)
+
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would split %s %s with %s (%s)\n", f.Date(txn.TxnTimestamp), txn.Narration, person, describeOwed(f, owed, txn.Currency))
+		return nil
+	}
+	if previous != nil && previous.With != person && IsVerbose() {
+		fmt.Printf("↪️  Moving the split from %s to %s\n", previous.With, person)
+	}
+	summary := fmt.Sprintf("split %s with %s", uuid, person)
+	if settle {
+		summary = fmt.Sprintf("settle %s with %s", uuid, person)
+	}
+	if err := saveWithHistory(st, checkpoint, summary); err != nil {
+		return err
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✅ Split %s %s with %s: %s\n", f.Date(txn.TxnTimestamp), txn.Narration, person, describeOwed(f, owed, txn.Currency))
+	}
+	return nil
+}
+
+// runSplitRemove clears the split from transactions
+func runSplitRemove(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := st.Checkpoint()
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, uuid := range args {
+		record, ok := st.Records[uuid]
+		if !ok || record.Split == nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %s has no split\n", uuid)
+			continue
+		}
+		record.Split = nil
+		removed++
+	}
+
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would remove %d split(s)\n", removed)
+		return nil
+	}
+	if removed > 0 {
+		if err := saveWithHistory(st, checkpoint, fmt.Sprintf("remove %d split(s)", removed)); err != nil {
+			return err
+		}
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✅ Removed %d split(s)\n", removed)
+	}
+	return nil
+}
+
+// runSplitList prints the split transactions
+func runSplitList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	st, person, err := openSplits(cfg)
+	if err != nil {
+		return err
+	}
+	records := st.Splits(person)
+
+	switch format := display.Output(cmd, cfg.Display); format {
+	case "table":
+		if len(records) == 0 {
+			fmt.Println("📭 No split transactions")
+			return nil
+		}
+		f := display.New(cfg.Display)
+		table := f.Table(display.Column{Header: "Date"}, display.Column{Header: "Person"}, display.Column{Header: "Transaction"},
+			display.Column{Header: "Amount", Right: true}, display.Column{Header: "Kind"}, display.Column{Header: "Owed", Right: true},
+			display.Column{Header: "Note"})
+		for _, record := range records {
+			txn := record.Transaction
+			kind := "shared"
+			if record.Split.Settle {
+				kind = "settlement"
+			}
+			table.Row(f.Date(txn.TxnTimestamp), record.Split.With, txn.Narration, f.Money(txn.SignedMoney(cfg.RoundingMode())),
+				kind, f.Amount(record.SplitOwed(cfg.RoundingMode()), txn.Currency), record.Split.Note)
+		}
+		table.Render(os.Stdout)
+
+	case "json":
+		entries := splitEntries(records, cfg.RoundingMode())
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal splits to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table or json", format)
+	}
+	return nil
+}
+
+// runSplitBalances prints who owes whom
+func runSplitBalances(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	st, person, err := openSplits(cfg)
+	if err != nil {
+		return err
+	}
+	balances := st.SplitBalances(person, cfg.RoundingMode())
+
+	switch format := display.Output(cmd, cfg.Display); format {
+	case "table":
+		if len(balances) == 0 {
+			fmt.Println("📭 No split transactions")
+			return nil
+		}
+		f := display.New(cfg.Display)
+		table := f.Table(display.Column{Header: "Person"}, display.Column{Header: "Shared", Right: true},
+			display.Column{Header: "Settled", Right: true}, display.Column{Header: "Balance", Right: true},
+			display.Column{Header: "Entries", Right: true}, display.Column{Header: "Last"})
+		for _, b := range balances {
+			table.Row(b.Person, f.Amount(b.Shared, b.Currency), f.Amount(b.Settled, b.Currency),
+				f.Amount(b.Balance, b.Currency), strconv.Itoa(b.Entries), f.Date(b.Last))
+		}
+		table.Render(os.Stdout)
+
+		fmt.Println()
+		for _, b := range balances {
+			fmt.Printf("%s\n", describeBalance(f, b))
+		}
+
+	case "json":
+		data, err := json.MarshalIndent(balances, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal balances to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+
+	default:
+		return fmt.Errorf("unsupported output format: %s. Use table or json", format)
+	}
+	return nil
+}
+
+// runSplitExport writes the settlement summary
+func runSplitExport(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	format := strings.ToLower(splitFormat)
+	if format != "csv" && format != "json" {
+		return fmt.Errorf("invalid --format %q (use csv or json)", splitFormat)
+	}
+
+	st, person, err := openSplits(cfg)
+	if err != nil {
+		return err
+	}
+	records := st.Splits(person)
+	balances := st.SplitBalances(person, cfg.RoundingMode())
+
+	w := io.Writer(os.Stdout)
+	if splitOutput != "" {
+		if IsDryRun() {
+			fmt.Printf("🔍 [dry-run] Would write %d balance(s) and %d transaction(s) to %s\n", len(balances), len(records), splitOutput)
+			return nil
+		}
+		file, err := os.Create(splitOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", splitOutput, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if format == "json" {
+		summary := struct {
+			Balances     []store.PersonBalance `json:"balances"`
+			Transactions []splitEntry          `json:"transactions"`
+		}{balances, splitEntries(records, cfg.RoundingMode())}
+		data, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal settlement summary to JSON: %w", err)
+		}
+		if _, err := fmt.Fprintln(w, string(data)); err != nil {
+			return fmt.Errorf("failed to write settlement summary: %w", err)
+		}
+	} else {
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"row", "person", "date", "uuid", "narration", "kind", "amount", "owed", "currency", "note"})
+		for _, e := range splitEntries(records, cfg.RoundingMode()) {
+			cw.Write([]string{"transaction", e.Person, e.Date.Format("2006-01-02"), e.UUID, e.Narration, e.Kind,
+				e.Amount.String(), e.Owed.String(), e.Currency, e.Note})
+		}
+		for _, b := range balances {
+			cw.Write([]string{"balance", b.Person, b.Last.Format("2006-01-02"), "", "", "balance",
+				"", b.Balance.String(), b.Currency, ""})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to write settlement summary: %w", err)
+		}
+	}
+
+	if splitOutput != "" && !IsQuiet() {
+		fmt.Printf("✅ Wrote %d balance(s) and %d transaction(s) to %s\n", len(balances), len(records), splitOutput)
+	}
+	return nil
+}
+
+// =============================================================================
+// SPLIT UTILITIES
+// =============================================================================
+
+// splitEntry is a split transaction in list and export output
+type splitEntry struct {
+	Person    string       `json:"person"`
+	Date      time.Time    `json:"date"`
+	UUID      string       `json:"uuid"`
+	Narration string       `json:"narration"`
+	Kind      string       `json:"kind"` // shared or settlement
+	Amount    money.Amount `json:"-"`    // Signed transaction amount
+	Owed      money.Amount `json:"-"`    // Added to the person's balance
+	Currency  string       `json:"currency"`
+	Note      string       `json:"note,omitempty"`
+
+	AmountValue float64 `json:"amount"`
+	OwedValue   float64 `json:"owed"`
+}
+
+// splitEntries converts split records for output
+func splitEntries(records []*store.Record, mode money.RoundingMode) []splitEntry {
+	entries := make([]splitEntry, 0, len(records))
+	for _, record := range records {
+		txn := record.Transaction
+		e := splitEntry{
+			Person:    record.Split.With,
+			Date:      txn.TxnTimestamp,
+			UUID:      txn.UUID,
+			Narration: txn.Narration,
+			Kind:      "shared",
+			Amount:    money.FromFloat(txn.SignedAmount(), mode),
+			Owed:      record.SplitOwed(mode),
+			Currency:  txn.Currency,
+			Note:      record.Split.Note,
+		}
+		if record.Split.Settle {
+			e.Kind = "settlement"
+		}
+		e.AmountValue = e.Amount.Float64()
+		e.OwedValue = e.Owed.Float64()
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// openSplits opens the store and normalizes --person
+func openSplits(cfg *config.Config) (*store.Store, string, error) {
+	person := ""
+	if splitPerson != "" {
+		var err error
+		if person, err = store.NormalizePerson(splitPerson); err != nil {
+			return nil, "", err
+		}
+	}
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return nil, "", err
+	}
+	return st, person, nil
+}
+
+// parseShare resolves --share against the transaction amount: a percentage
+// of it, or an amount no larger than it
+func parseShare(value string, amount money.Money, mode money.RoundingMode) (money.Amount, error) {
+	if percent, ok := strings.CutSuffix(strings.TrimSpace(value), "%"); ok {
+		p, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		if err != nil || p <= 0 || p > 100 {
+			return 0, fmt.Errorf("invalid --share %q: use a percentage between 0 and 100", value)
+		}
+		return money.FromFloat(amount.Amount.Float64()*p/100, mode), nil
+	}
+
+	share, err := money.ParseMoney(value, mode)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --share: %w", err)
+	}
+	if !share.SameCurrency(amount) {
+		return 0, fmt.Errorf("--share is in %s but the transaction is in %s", share.Currency, amount.Currency)
+	}
+	if share.Amount <= 0 || share.Amount > amount.Amount.Abs() {
+		return 0, fmt.Errorf("--share must be more than 0 and at most the transaction amount %s", amount)
+	}
+	return share.Amount, nil
+}
+
+// describeOwed words what a split adds to a balance
+func describeOwed(f *display.Formatter, owed money.Amount, currency string) string {
+	if owed < 0 {
+		return "you owe " + f.Amount(owed.Abs(), currency)
+	}
+	return "they owe " + f.Amount(owed, currency)
+}
+
+// describeBalance words a person's balance
+func describeBalance(f *display.Formatter, b store.PersonBalance) string {
+	switch {
+	case b.Balance > 0:
+		return fmt.Sprintf("💰 %s owes you %s", b.Person, f.Amount(b.Balance, b.Currency))
+	case b.Balance < 0:
+		return fmt.Sprintf("💸 You owe %s %s", b.Person, f.Amount(b.Balance.Abs(), b.Currency))
+	default:
+		return fmt.Sprintf("✅ You and %s are settled up (%s)", b.Person, b.Currency)
+	}
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/telemetry"
+	"github.com/quickkly/fintrack/internal/usage"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// STATUS COMMAND DEFINITION
+// =============================================================================
+
+// statusCmd shows the session state and API usage
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show session state and API usage",
+	Long: `Show whether the Bend session is valid and how much API traffic fintrack has
+generated: requests issued and bytes downloaded by the last run and in total.
+With telemetry.stats enabled it also shows how often each command ran and failed.
+
+Useful on metered connections or when keeping an eye on API quotas.`,
+	RunE: runStatus,
+}
+
+// =============================================================================
+// STATUS COMMAND IMPLEMENTATION
+// =============================================================================
+
+// runStatus prints the session summary and usage totals
+func runStatus(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	sessionInfo, err := blend.NewSessionManager(cfg.Bend.SessionFile).GetSessionInfo()
+	if err != nil {
+		return fmt.Errorf("failed to get session info: %w", err)
+	}
+
+	f := display.New(cfg.Display)
+
+	fmt.Println("Session:")
+	switch {
+	case !sessionInfo.Exists:
+		fmt.Println("  ❌ No session. Run 'fintrack bend login'")
+	case sessionInfo.Valid:
+		fmt.Printf("  ✅ Valid for %s (expires %s)\n",
+			sessionInfo.TimeRemaining.Round(time.Minute), f.DateTime(sessionInfo.ExpiresAt))
+	default:
+		fmt.Println("  ⚠️  Expired. Run 'fintrack bend check' to refresh")
+	}
+
+	totals, err := usage.Load(cfg.Bend.UsageFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\nAPI usage:")
+	if totals.Runs == 0 {
+		fmt.Println("  No API requests recorded yet")
+	} else {
+		if last := totals.LastRun; last != nil {
+			fmt.Printf("  Last run:  %d request(s), %s down, %s up (%s, %s)\n",
+				last.Usage.Requests, usage.FormatBytes(last.Usage.BytesReceived), usage.FormatBytes(last.Usage.BytesSent),
+				last.Command, f.DateTime(last.At))
+		}
+		fmt.Printf("  Total:     %d request(s), %s down, %s up over %d run(s) since %s\n",
+			totals.Usage.Requests, usage.FormatBytes(totals.Usage.BytesReceived), usage.FormatBytes(totals.Usage.BytesSent),
+			totals.Runs, f.Date(totals.Since))
+		fmt.Printf("  Usage file: %s\n", cfg.Bend.UsageFile)
+	}
+
+	if err := printCircuits(cfg, f); err != nil {
+		return err
+	}
+
+	if cfg.Telemetry.Stats {
+		return printStats(cfg, f)
+	}
+	return nil
+}
+
+// printCircuits prints the endpoints whose requests have been failing, if any
+func printCircuits(cfg *config.Config, f *display.Formatter) error {
+	circuits, err := blend.LoadCircuits(cfg.Bend.CircuitFile)
+	if err != nil {
+		return err
+	}
+	if len(circuits) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	fmt.Println("\nAPI circuits:")
+	for _, c := range circuits {
+		switch {
+		case c.Open(now):
+			fmt.Printf("  🔴 %s open until %s after %d failure(s): %s\n",
+				c.Endpoint, f.DateTime(*c.OpenUntil), c.Failures, c.LastError)
+		case c.OpenUntil != nil:
+			fmt.Printf("  🟡 %s cooled down, the next request retries it (%d failure(s): %s)\n",
+				c.Endpoint, c.Failures, c.LastError)
+		default:
+			fmt.Printf("  🟡 %s %d failure(s) in a row: %s\n", c.Endpoint, c.Failures, c.LastError)
+		}
+	}
+	return nil
+}
+
+// printStats prints the opt-in command stats
+func printStats(cfg *config.Config, f *display.Formatter) error {
+	stats, err := telemetry.LoadStats(cfg.Telemetry.StatsFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\nCommand stats:")
+	if len(stats.Commands) == 0 {
+		fmt.Println("  No runs recorded yet")
+		return nil
+	}
+
+	commands := make([]string, 0, len(stats.Commands))
+	for command := range stats.Commands {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+
+	for _, command := range commands {
+		c := stats.Commands[command]
+		avg := time.Duration(c.DurationMS/int64(c.Runs)) * time.Millisecond
+		fmt.Printf("  %-28s %4d run(s), %3d failed, avg %s, last %s\n",
+			command, c.Runs, c.Failures, avg.Round(time.Millisecond), f.DateTime(c.LastRun))
+	}
+
+	if len(stats.Errors) > 0 {
+		classes := make([]string, 0, len(stats.Errors))
+		for class := range stats.Errors {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		parts := make([]string, len(classes))
+		for i, class := range classes {
+			parts[i] = fmt.Sprintf("%s %d", class, stats.Errors[class])
+		}
+		fmt.Printf("  Errors: %s\n", strings.Join(parts, ", "))
+	}
+	fmt.Printf("  Since %s, stats file: %s\n", f.Date(stats.Since), cfg.Telemetry.StatsFile)
+	return nil
+}
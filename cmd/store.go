@@ -0,0 +1,519 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/balance"
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/exitcode"
+	"github.com/quickkly/fintrack/internal/keychain"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/store"
+	"github.com/quickkly/fintrack/internal/syncreport"
+	"github.com/quickkly/fintrack/internal/usage"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// STORE COMMAND DEFINITIONS
+// =============================================================================
+
+// storeCmd manages the local store file
+var storeCmd = &cobra.Command{
+	Use:   "store",
+	Short: "Manage the local transaction store",
+	Long: `Manage the local transaction store file (store.path).
+
+The store can be encrypted at rest with a passphrase (AES-256-GCM, key derived
+with PBKDF2). It is only ever decrypted in memory. The passphrase is taken
+from FINTRACK_STORE_PASSPHRASE, then the OS keychain (store.keychain, on by
+default), and is otherwise prompted for; a prompted passphrase is cached in
+the keychain once it has opened the store.
+
+Available subcommands:
+- encrypt: Encrypt the store, or change its passphrase
+- decrypt: Store it as plain JSON again
+- forget-passphrase: Remove the cached passphrase from the keychain
+- migrate: Upgrade the store to this release's schema version
+- verify: Check the store's integrity
+- compact: Drop old soft-deleted transactions and history to shrink the store`,
+}
+
+// storeEncryptCmd encrypts the store
+var storeEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the store, or change its passphrase",
+	Long: `Encrypt the store with a new passphrase, prompted for twice (or taken from
+FINTRACK_STORE_NEW_PASSPHRASE for scripts). Run it on an encrypted store to
+change the passphrase.`,
+	Args: cobra.NoArgs,
+	RunE: runStoreEncrypt,
+}
+
+// storeDecryptCmd decrypts the store
+var storeDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Store the store as plain JSON again",
+	Args:  cobra.NoArgs,
+	RunE:  runStoreDecrypt,
+}
+
+// storeForgetCmd removes the cached passphrase
+var storeForgetCmd = &cobra.Command{
+	Use:   "forget-passphrase",
+	Short: "Remove the cached store passphrase from the OS keychain",
+	Args:  cobra.NoArgs,
+	RunE:  runStoreForget,
+}
+
+// storeMigrateCmd upgrades the store's schema
+var storeMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the store to this release's schema version",
+	Long: `Upgrade the store to the schema version this release writes, and save it.
+
+Stores from older releases are also upgraded automatically whenever they're
+opened, and saved in the new format by the next command that writes the
+store, so running this is never required. It shows what changes and saves
+right away. The file from before the upgrade is kept next to the store as
+<store>.v<old version>.bak.`,
+	Example: `  fintrack store migrate
+  fintrack store migrate --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runStoreMigrate,
+}
+
+// storeVerifyCmd checks the store's integrity
+var storeVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the store's integrity",
+	Long: `Check the store for inconsistencies without changing it:
+
+- key: a transaction stored under another UUID than its own, or none
+- duplicate: the same transaction stored twice under different UUIDs
+- split: splits on transactions deleted upstream, with an invalid person,
+  a share larger than the amount, or both a share and a settlement
+- history: undo log entries out of order or undoing unknown operations
+- audit: deletion state disagreeing with the record's audit trail
+- balance: for accounts with an opening balance configured, the balance
+  computed from stored transactions disagreeing with the one in the last
+  sync report
+
+Exits with a non-zero status when any issue is found.`,
+	Example: `  fintrack store verify
+  fintrack store verify && fintrack export`,
+	Args: cobra.NoArgs,
+	RunE: runStoreVerify,
+}
+
+// storeCompactCmd shrinks the store
+var storeCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Drop old soft-deleted transactions and history to shrink the store",
+	Long: `Rewrite the store without what's no longer needed, reporting its size and
+contents before and after.
+
+Soft-deleted transactions (no longer returned by Bend) are dropped once they
+were deleted more than --purge-deleted-days days ago; 0 keeps them all.
+--drop-history also clears the undo log, after which no earlier operation can
+be undone.`,
+	Example: `  fintrack store compact
+  fintrack store compact --purge-deleted-days 30 --drop-history
+  fintrack store compact --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runStoreCompact,
+}
+
+var (
+	compactPurgeDeletedDays int
+	compactDropHistory      bool
+)
+
+func init() {
+	storeCompactCmd.Flags().IntVar(&compactPurgeDeletedDays, "purge-deleted-days", 90, "Drop transactions soft-deleted more than this many days ago (0 keeps them)")
+	storeCompactCmd.Flags().BoolVar(&compactDropHistory, "drop-history", false, "Clear the undo log")
+
+	storeCmd.AddCommand(storeVerifyCmd)
+	storeCmd.AddCommand(storeCompactCmd)
+	storeCmd.AddCommand(storeMigrateCmd)
+	storeCmd.AddCommand(storeEncryptCmd)
+	storeCmd.AddCommand(storeDecryptCmd)
+	storeCmd.AddCommand(storeForgetCmd)
+}
+
+// =============================================================================
+// STORE COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runStoreEncrypt encrypts the store with a new passphrase
+func runStoreEncrypt(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+
+	passphrase := os.Getenv("FINTRACK_STORE_NEW_PASSPHRASE")
+	if passphrase == "" {
+		if passphrase, err = promptSecret("New store passphrase: "); err != nil {
+			return err
+		}
+		confirm, err := promptSecret("Repeat passphrase: ")
+		if err != nil {
+			return err
+		}
+		if confirm != passphrase {
+			return fmt.Errorf("passphrases don't match")
+		}
+	}
+	if len(passphrase) < 8 {
+		return fmt.Errorf("passphrase must be at least 8 characters")
+	}
+
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would encrypt %s (%d transactions)\n", cfg.Store.Path, st.Len())
+		return nil
+	}
+
+	if err := st.SetPassphrase(passphrase); err != nil {
+		return err
+	}
+	if err := st.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("🔒 Encrypted %s (%d transactions)\n", cfg.Store.Path, st.Len())
+
+	if cfg.Store.Keychain && keychain.Available() {
+		if err := keychain.Set(storeKeychainAccount(cfg), passphrase); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		} else if !IsQuiet() {
+			fmt.Println("🔑 Passphrase saved in the OS keychain")
+		}
+	}
+	return nil
+}
+
+// runStoreDecrypt rewrites the store as plain JSON
+func runStoreDecrypt(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	if !st.Encrypted() {
+		fmt.Printf("%s is not encrypted\n", cfg.Store.Path)
+		return nil
+	}
+
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would decrypt %s\n", cfg.Store.Path)
+		return nil
+	}
+
+	if err := st.SetPassphrase(""); err != nil {
+		return err
+	}
+	if err := st.Save(); err != nil {
+		return err
+	}
+	if keychain.Available() {
+		keychain.Delete(storeKeychainAccount(cfg))
+	}
+	fmt.Printf("🔓 Decrypted %s\n", cfg.Store.Path)
+	return nil
+}
+
+// runStoreMigrate saves the store after the migrations applied on open
+func runStoreMigrate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	migrator, ok := st.Backend().(store.Migrator)
+	if !ok {
+		fmt.Printf("The %s backend manages its own schema; nothing to migrate\n", cfg.Store.Backend)
+		return nil
+	}
+
+	migrated := migrator.Migrated()
+	if len(migrated) == 0 {
+		fmt.Printf("✅ %s is at schema version %d, nothing to migrate\n", st.Path(), migrator.OpenedVersion())
+		return nil
+	}
+
+	for _, m := range migrated {
+		fmt.Printf("  ⬆️  v%d: %s\n", m.Version, m.Description)
+	}
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would migrate %s from schema version %d to %d\n", st.Path(), migrator.OpenedVersion(), store.SchemaVersion)
+		return nil
+	}
+
+	backup := migrator.BackupPath()
+	if err := st.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Migrated %s from schema version %d to %d (previous file kept as %s)\n", st.Path(), migrator.OpenedVersion(), store.SchemaVersion, backup)
+	return nil
+}
+
+// runStoreVerify reports the store's integrity issues
+func runStoreVerify(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+
+	issues := st.Verify()
+	balanceIssues, err := verifyStoreBalances(cfg, st)
+	if err != nil {
+		return err
+	}
+	issues = append(issues, balanceIssues...)
+
+	stats := st.Stats()
+	if len(issues) == 0 {
+		fmt.Printf("✅ %s is consistent (%d transactions, %d soft-deleted, %d history operations)\n", st.Path(), stats.Records, stats.Deleted, stats.History)
+		return nil
+	}
+
+	for _, issue := range issues {
+		if issue.UUID != "" {
+			fmt.Printf("  ❌ [%s] %s: %s\n", issue.Check, issue.UUID, issue.Detail)
+		} else {
+			fmt.Printf("  ❌ [%s] %s\n", issue.Check, issue.Detail)
+		}
+	}
+	return exitcode.Wrap(exitcode.Failure, fmt.Errorf("%s has %d integrity issue(s)", st.Path(), len(issues)))
+}
+
+// verifyStoreBalances compares each account's balance computed from the
+// store with the one in the last sync report. Accounts without an opening
+// balance configured are skipped, having no known starting point.
+func verifyStoreBalances(cfg *config.Config, st *store.Store) ([]store.Issue, error) {
+	report, err := syncreport.Load(cfg.Sync.ReportFile)
+	if err != nil || report == nil {
+		return nil, err
+	}
+
+	byAccount := make(map[string][]blend.Transaction)
+	for _, txn := range st.Transactions() {
+		byAccount[txn.AccountID] = append(byAccount[txn.AccountID], txn)
+	}
+
+	mode := cfg.RoundingMode()
+	var issues []store.Issue
+	for _, reported := range report.Balances {
+		settings, ok := cfg.AccountSettingsFor(reported.AccountID)
+		if !ok {
+			continue
+		}
+		start, _ := settings.HistoryStart()
+		snapshot := balance.Snapshot{Balance: money.FromFloat(reported.Balance, mode), At: reported.AsOf}
+		result := balance.Compute(money.FromFloat(settings.OpeningBalance, mode), start, byAccount[reported.AccountID], []balance.Snapshot{snapshot}, mode)
+		for _, d := range result.Divergences {
+			issues = append(issues, store.Issue{
+				Check:  store.CheckBalance,
+				Detail: fmt.Sprintf("account %s: computed %s, Bend reported %s at %s (off by %s)", cfg.AccountName(reported.AccountID), d.Computed, d.Snapshot.Balance, d.Snapshot.At.Format("2006-01-02 15:04"), d.Difference()),
+			})
+		}
+	}
+	return issues, nil
+}
+
+// runStoreCompact drops old soft-deleted records and, optionally, the history
+func runStoreCompact(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+	if compactPurgeDeletedDays < 0 {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--purge-deleted-days must not be negative"))
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+
+	before := storeStats(st)
+	opts := store.CompactOptions{DropHistory: compactDropHistory}
+	if compactPurgeDeletedDays > 0 {
+		opts.PurgeDeletedBefore = time.Now().AddDate(0, 0, -compactPurgeDeletedDays)
+	}
+	result := st.Compact(opts)
+
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would drop %d soft-deleted transaction(s) and %d history operation(s) from %s\n", result.Purged, result.History, st.Path())
+		return nil
+	}
+	if err := st.Save(); err != nil {
+		return err
+	}
+	after := storeStats(st)
+
+	fmt.Printf("🗜️  Compacted %s\n", st.Path())
+	fmt.Printf("  %-14s %10s %10s\n", "", "Before", "After")
+	fmt.Printf("  %-14s %10s %10s\n", "Size", usage.FormatBytes(before.Bytes), usage.FormatBytes(after.Bytes))
+	fmt.Printf("  %-14s %10d %10d\n", "Transactions", before.Records, after.Records)
+	fmt.Printf("  %-14s %10d %10d\n", "Soft-deleted", before.Deleted, after.Deleted)
+	fmt.Printf("  %-14s %10d %10d\n", "History", before.History, after.History)
+	return nil
+}
+
+// storeStats is st.Stats with the size of the store file, when it is one
+func storeStats(st *store.Store) store.Stats {
+	stats := st.Stats()
+	if info, err := os.Stat(st.Path()); err == nil && !info.IsDir() {
+		stats.Bytes = info.Size()
+	}
+	return stats
+}
+
+// runStoreForget removes the cached passphrase
+func runStoreForget(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	if err := keychain.Delete(storeKeychainAccount(cfg)); err != nil {
+		return err
+	}
+	if !IsQuiet() {
+		fmt.Println("✓ Removed the store passphrase from the OS keychain")
+	}
+	return nil
+}
+
+// =============================================================================
+// STORE PASSPHRASES
+// =============================================================================
+
+// storePassphrases supplies encrypted store passphrases from the environment,
+// the OS keychain or a prompt, remembering them for the rest of the command
+type storePassphrases struct {
+	cfg    *config.Config
+	known  map[string]string
+	cached map[string]bool // Already in the keychain
+}
+
+// newStorePassphrases creates the passphrase source for a command
+func newStorePassphrases(cfg *config.Config) *storePassphrases {
+	return &storePassphrases{cfg: cfg, known: make(map[string]string), cached: make(map[string]bool)}
+}
+
+// Passphrase returns the passphrase for the store at path
+func (p *storePassphrases) Passphrase(path string) (string, error) {
+	if passphrase, ok := p.known[path]; ok {
+		return passphrase, nil
+	}
+	if passphrase := os.Getenv("FINTRACK_STORE_PASSPHRASE"); passphrase != "" {
+		p.cached[path] = true // Never copy the environment into the keychain
+		return passphrase, nil
+	}
+
+	if p.cfg.Store.Keychain {
+		passphrase, err := keychain.Get(keychainAccount(path))
+		if err == nil {
+			p.cached[path] = true
+			return passphrase, nil
+		}
+		if !errors.Is(err, keychain.ErrNotFound) && !errors.Is(err, keychain.ErrUnsupported) {
+			return "", err
+		}
+	}
+
+	if !isTerminal(os.Stdin) {
+		return "", fmt.Errorf("store %s is encrypted: set FINTRACK_STORE_PASSPHRASE or run interactively", path)
+	}
+	return promptSecret("Passphrase for " + path + ": ")
+}
+
+// Verified remembers a passphrase that opened the store, caching it in the keychain
+func (p *storePassphrases) Verified(path, passphrase string) {
+	p.known[path] = passphrase
+	if p.cached[path] || !p.cfg.Store.Keychain || !keychain.Available() {
+		return
+	}
+	p.cached[path] = true
+	if err := keychain.Set(keychainAccount(path), passphrase); err != nil && IsVerbose() {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// storeKeychainAccount is the keychain entry for the configured store
+func storeKeychainAccount(cfg *config.Config) string {
+	return keychainAccount(cfg.Store.Path)
+}
+
+// keychainAccount is the keychain entry for a store file
+func keychainAccount(path string) string {
+	return "store:" + path
+}
+
+// promptSecret reads a line from the terminal without echoing it
+func promptSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	if echoOff() {
+		defer func() {
+			echoOn()
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read passphrase (set FINTRACK_STORE_PASSPHRASE for unattended runs): %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// echoOff disables terminal echo, reporting whether it did
+func echoOff() bool {
+	if !isTerminal(os.Stdin) {
+		return false
+	}
+	stty := exec.Command("stty", "-echo")
+	stty.Stdin = os.Stdin
+	return stty.Run() == nil
+}
+
+// echoOn restores terminal echo
+func echoOn() {
+	stty := exec.Command("stty", "echo")
+	stty.Stdin = os.Stdin
+	_ = stty.Run()
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
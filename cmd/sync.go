@@ -0,0 +1,424 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/exitcode"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/plugin"
+	"github.com/quickkly/fintrack/internal/staging"
+	"github.com/quickkly/fintrack/internal/store"
+	"github.com/quickkly/fintrack/internal/syncreport"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// SYNC COMMAND DEFINITION
+// =============================================================================
+
+// syncCmd fetches balances and new transactions in one unattended run
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Fetch account balances and new transactions",
+	Long: `Fetch current account balances and every transaction since the last
+successful sync. The first sync fetches sync.days of history (30 unless
+configured).
+
+Transactions are upserted by UUID into the local store (store.path); only new
+and changed ones are written to the staging directory. Stored transactions in
+the synced window that Bend no longer returns (reversed or deleted upstream)
+are soft-deleted: they stay in the store with an audit trail but are left out
+of statements and exports.
+
+Accounts whose bank is down, or that Bend last fetched longer ago than
+sync.stale_after (48h unless configured), are flagged stale: their balances
+are the last ones Bend got, not today's.
+
+Each run writes a machine-readable report (counts, balance totals, stale
+accounts, errors, duration, alerts) to sync.report_file so monitoring can
+scrape the outcome of scheduled runs. The report is written even when the
+sync fails.
+
+After a successful sync the alert rules (see 'fintrack alerts') are evaluated;
+triggered alerts are listed in the report and sent to their channels.
+Reminders for upcoming bills are sent (see 'fintrack bills'), as are warnings
+for account aggregator consents about to expire (see 'fintrack accounts
+consents'). Scheduled report jobs that are due are run (see 'fintrack report
+scheduled').
+
+Examples:
+  fintrack sync
+  fintrack sync --days 90
+  fintrack sync --report /var/lib/fintrack/sync-report.json`,
+	RunE: runSync,
+}
+
+var (
+	syncDays       int
+	syncStagingDir string
+	syncReportFile string
+)
+
+// syncOverlap is re-fetched before the last successful sync so transactions
+// Bend posts late are still picked up
+const syncOverlap = 24 * time.Hour
+
+func init() {
+	syncCmd.Flags().IntVar(&syncDays, "days", 0, "Days of history to fetch when there is no previous sync (default: sync.days)")
+	syncCmd.Flags().StringVar(&syncStagingDir, "staging-dir", "", "Staging directory (default: paths.staging_dir)")
+	syncCmd.Flags().StringVar(&syncReportFile, "report", "", "Where to write the sync report (default: sync.report_file)")
+}
+
+// =============================================================================
+// SYNC COMMAND IMPLEMENTATION
+// =============================================================================
+
+// runSync performs the sync and always writes its report
+func runSync(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	if err := serveMetrics(cfg); err != nil {
+		return err
+	}
+
+	reportPath := syncReportFile
+	if reportPath == "" {
+		reportPath = cfg.Sync.ReportFile
+	}
+
+	previous, err := syncreport.Load(reportPath)
+	if err != nil {
+		// A corrupt report shouldn't block syncing; start afresh
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		previous = nil
+	}
+
+	report := syncreport.New(previous)
+	report.Environment = cfg.Environment
+
+	syncErr := performSync(cfg, report)
+	if syncErr != nil {
+		report.AddError(syncErr)
+	} else if !IsDryRun() {
+		syncAlerts(cfg, report)
+		syncBills(cfg, report)
+		syncConsents(cfg, report)
+		syncReports(cfg, report)
+	}
+	report.Finish(syncErr == nil)
+
+	if IsDryRun() {
+		return syncErr
+	}
+
+	if err := syncreport.Write(reportPath, report); err != nil {
+		return err
+	}
+	if !IsQuiet() {
+		fmt.Printf("📝 Sync report: %s\n", reportPath)
+	}
+
+	if syncErr != nil {
+		return fmt.Errorf("sync failed: %w", syncErr)
+	}
+	if len(report.Errors) > 0 {
+		// The sync itself was saved, but something around it failed
+		return exitcode.Wrap(exitcode.Partial,
+			fmt.Errorf("sync finished with %d error(s), see %s", len(report.Errors), reportPath))
+	}
+	return nil
+}
+
+// performSync fetches balances and transactions, filling in the report
+func performSync(cfg *config.Config, report *syncreport.Report) error {
+	dir := syncStagingDir
+	if dir == "" {
+		dir = cfg.Paths.StagingDir
+	}
+
+	report.To = time.Now()
+	report.From = syncStart(cfg, report)
+
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would sync transactions from %s to %s into %s\n",
+			report.From.Format("2006-01-02 15:04"), report.To.Format("2006-01-02 15:04"), dir)
+		return nil
+	}
+
+	saveCircuits, err := blend.StartBreaker(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := saveCircuits(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		}
+	}()
+
+	client, err := newSessionClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	if !IsQuiet() {
+		fmt.Println("🔄 Fetching account balances...")
+	}
+	accounts, err := client.GetAccounts()
+	if err != nil {
+		// Transactions are still worth fetching without balances
+		report.AddError(fmt.Errorf("failed to fetch accounts: %w", err))
+	} else {
+		accounts = includedAccounts(cfg, accounts)
+		recordBalances(cfg, report, accounts)
+		report.Consents = consentStatuses(cfg, accounts, report.StartedAt)
+		if !IsQuiet() {
+			printStaleBanner(report, accounts)
+		}
+	}
+
+	userID, err := client.GetUserID()
+	if err != nil {
+		return fmt.Errorf("failed to get user ID: %w", err)
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("🔄 Fetching transactions from %s to %s\n", report.From.Format("2006-01-02"), report.To.Format("2006-01-02"))
+	}
+	transactions, counts, err := client.FetchAllTransactionsWithFilters(userID, blend.TransactionFilters{
+		StartDate: report.From,
+		EndDate:   report.To,
+		SortBy:    "txn_timestamp",
+		SortOrder: "DESC",
+		// The store keeps every transaction; reports apply the flag filters
+		IncludeHidden: true,
+	})
+	// Pages that arrived before a failure are still stored, but the run fails
+	// so the next sync fetches the window again
+	partial, isPartial := blend.AsPartial(err)
+	if err != nil && !isPartial {
+		return fmt.Errorf("failed to fetch transactions: %w", err)
+	}
+	if isPartial {
+		report.Partial = &syncreport.Partial{Pages: partial.Pages, Fetched: partial.Fetched, Cursor: partial.Cursor}
+		fmt.Fprintf(os.Stderr, "⚠️  Only %d page(s) of transactions arrived before a failure; saving them\n", partial.Pages)
+	}
+	report.Fetched = len(transactions)
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	// Excluded accounts' transactions aren't stored, but the full response
+	// still decides what was deleted upstream
+	kept := make([]blend.Transaction, 0, len(transactions))
+	for _, txn := range transactions {
+		if !cfg.AccountExcluded(txn.AccountID) {
+			kept = append(kept, txn)
+		}
+	}
+	if kept, err = plugin.Enrich(context.Background(), kept); err != nil {
+		return err
+	}
+	result := st.Upsert(kept, report.StartedAt, "sync")
+	report.New, report.Known, report.Changed = result.New, result.Unchanged, result.Changed
+
+	// The window was fetched completely, so anything stored in it that Bend
+	// no longer returns was reversed or deleted upstream. An empty response
+	// is more likely an API hiccup than every transaction vanishing.
+	if len(transactions) > 0 && !isPartial {
+		deleted := st.MarkDeleted(report.From, report.To, transactions, report.StartedAt, "sync")
+		report.Deleted = len(deleted)
+		if IsVerbose() {
+			for _, record := range deleted {
+				txn := record.Transaction
+				fmt.Printf("  🗑️  %s %s %.2f %s\n", txn.TxnTimestamp.Local().Format("2006-01-02"), txn.UUID, txn.SignedAmount(), txn.Narration)
+			}
+		}
+	}
+
+	// Only stage what the store didn't already hold, so the overlap window
+	// doesn't leave duplicate copies in the staging directory
+	if fresh := result.Fresh(kept); len(fresh) > 0 {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create staging directory: %w", err)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("sync_%s.json", report.StartedAt.Format("20060102_150405")))
+		if err := staging.Save(path, fresh, counts, report.From, report.To); err != nil {
+			return fmt.Errorf("failed to save transactions: %w", err)
+		}
+		report.StagingFile = path
+	}
+
+	if err := st.Save(); err != nil {
+		return err
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✅ Synced %d account(s): %d transaction(s) fetched, %d new, %d already known, %d changed\n",
+			report.Accounts, report.Fetched, report.New, report.Known, report.Changed)
+		if report.Stale > 0 {
+			fmt.Printf("⚠️  %d account(s) have stale data, see above\n", report.Stale)
+		}
+		if report.Deleted > 0 {
+			fmt.Printf("🗑️  %d transaction(s) no longer returned by Bend were marked deleted\n", report.Deleted)
+		}
+	}
+	if isPartial {
+		return fmt.Errorf("incomplete fetch, the fetched pages were saved: %w", partial)
+	}
+	return nil
+}
+
+// syncStart is where the sync window begins: just before the last successful
+// sync, or sync.days back for the first one
+func syncStart(cfg *config.Config, report *syncreport.Report) time.Time {
+	if !report.LastSuccess.IsZero() && syncDays == 0 {
+		return report.LastSuccess.Add(-syncOverlap)
+	}
+
+	days := syncDays
+	if days <= 0 {
+		days = cfg.Sync.Days
+	}
+	if days <= 0 {
+		days = 30
+	}
+	return report.To.AddDate(0, 0, -days)
+}
+
+// includedAccounts drops the accounts the configuration excludes, so their
+// balances don't count towards net worth
+func includedAccounts(cfg *config.Config, accounts []blend.Account) []blend.Account {
+	included := make([]blend.Account, 0, len(accounts))
+	for _, account := range accounts {
+		if !cfg.AccountExcluded(account.UUID) {
+			included = append(included, account)
+		}
+	}
+	return included
+}
+
+// recordBalances adds each account's balance and the per-currency totals to
+// the report, flagging balances that are not current
+func recordBalances(cfg *config.Config, report *syncreport.Report, accounts []blend.Account) {
+	mode := cfg.RoundingMode()
+	totals := make(money.Totals)
+
+	for i := range accounts {
+		account := &accounts[i]
+		balance := money.FromFloat(account.CurrentBalance, mode)
+		entry := syncreport.AccountBalance{
+			AccountID: account.UUID,
+			Currency:  account.Currency,
+			Balance:   balance.Float64(),
+			AsOf:      account.LastFetchedAt,
+		}
+		if reason := account.StaleReason(report.StartedAt, cfg.Sync.StaleAfter); reason != "" {
+			entry.Stale, entry.StaleReason = true, reason
+			report.Stale++
+		}
+		report.Balances = append(report.Balances, entry)
+		totals.Add(money.New(balance, account.Currency))
+	}
+	sort.Slice(report.Balances, func(i, j int) bool {
+		return report.Balances[i].AccountID < report.Balances[j].AccountID
+	})
+
+	for _, currency := range totals.Currencies() {
+		report.BalanceTotals[currency] = totals[currency].Float64()
+	}
+	report.Accounts = len(accounts)
+}
+
+// printStaleBanner warns about accounts whose balance and transactions are
+// not current, so old figures aren't mistaken for today's
+func printStaleBanner(report *syncreport.Report, accounts []blend.Account) {
+	if report.Stale == 0 {
+		return
+	}
+	names := make(map[string]string, len(accounts))
+	for i := range accounts {
+		names[accounts[i].UUID] = accounts[i].DisplayName()
+	}
+
+	fmt.Printf("⚠️  STALE DATA: %d of %d account(s) are not current; their balances and recent transactions may be missing:\n",
+		report.Stale, len(report.Balances))
+	for _, balance := range report.Balances {
+		if balance.Stale {
+			fmt.Printf("   • %s: %s (balance %.2f %s as of %s)\n", names[balance.AccountID], balance.StaleReason,
+				balance.Balance, balance.Currency, balance.AsOf.Format("2006-01-02 15:04"))
+		}
+	}
+}
+
+// newSessionClient creates a Bend client from the stored session, renewing it
+// when it has expired
+func newSessionClient(cfg *config.Config) (*blend.Client, error) {
+	sessionManager := blend.NewSessionManager(cfg.Bend.SessionFile)
+
+	sessionInfo, err := sessionManager.GetSessionInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session info: %w", err)
+	}
+
+	client := blend.NewClient(cfg)
+	if sessionInfo.Valid {
+		session, err := sessionManager.LoadSession()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load session: %w", err)
+		}
+		if err := client.UseSession(session); err != nil {
+			return nil, err
+		}
+		return client, nil
+	}
+
+	// Renew an expired or missing session as 'fintrack bend check' does, so
+	// unattended runs (cron, a container's sync) keep working
+	var refresh func(current *blend.Session) (*blend.Session, error)
+	switch {
+	case sessionInfo.HasRefreshToken:
+		refresh = func(current *blend.Session) (*blend.Session, error) {
+			if err := client.UseSession(current); err != nil {
+				return nil, err
+			}
+			if err := client.RefreshSession(); err != nil {
+				return nil, err
+			}
+			return client.GetSession(), nil
+		}
+	case cfg.Bend.RefreshToken != "" && cfg.Session == "":
+		refresh = func(*blend.Session) (*blend.Session, error) {
+			if err := client.InitializeFromRefreshToken(cfg.Bend.RefreshToken); err != nil {
+				return nil, fmt.Errorf("failed to initialize from config token: %w", err)
+			}
+			return client.GetSession(), nil
+		}
+	case !sessionInfo.Exists:
+		return nil, fmt.Errorf("no session found. Run 'fintrack bend login' to authenticate")
+	default:
+		return nil, fmt.Errorf("session expired and no refresh token. Run 'fintrack bend login' to re-authenticate")
+	}
+
+	// The session on disk, if any, is the one that didn't work
+	stale, _ := sessionManager.LoadSession()
+	session, _, err := sessionManager.Refresh(stale, refresh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to renew session: %w. Run 'fintrack bend login' to re-authenticate", err)
+	}
+	if err := client.UseSession(session); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
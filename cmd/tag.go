@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/display"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/store"
+
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// TAG COMMAND DEFINITIONS
+// =============================================================================
+
+// tagCmd manages local transaction tags
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage local transaction tags",
+	Long: `Manage tags on transactions in the local store (store.path).
+
+Tags are local labels, independent of Bend categories, and are never sent to
+Bend. A transaction can carry any number of them. Statements and exports
+accept --tag, --any-tag and --exclude-tag to filter by them.
+
+Available subcommands:
+- add: Tag every transaction matching the filters
+- remove: Untag every transaction matching the filters
+- list: Show every tag with its transaction count`,
+}
+
+// tagAddCmd tags transactions in bulk
+var tagAddCmd = &cobra.Command{
+	Use:   "add <tag>",
+	Short: "Tag every transaction matching the filters",
+	Args:  cobra.ExactArgs(1),
+	Example: `  fintrack tag add vacation --merchant "AIRBNB"
+  fintrack tag add reimbursable --account-id 6f1c...-uuid --from 2024-03-01 --to 2024-03-31
+  fintrack tag add tax:80c --uuid 1b2c... --uuid 9f8e...
+  fintrack tag add big-ticket --type outgoing --min-amount 10000`,
+	RunE: runTagAdd,
+}
+
+// tagRemoveCmd untags transactions in bulk
+var tagRemoveCmd = &cobra.Command{
+	Use:   "remove <tag>",
+	Short: "Untag every transaction matching the filters",
+	Args:  cobra.ExactArgs(1),
+	Example: `  fintrack tag remove vacation --merchant "AIRBNB" --from 2024-01-01
+  fintrack tag remove vacation --all`,
+	RunE: runTagRemove,
+}
+
+// tagListCmd lists tags
+var tagListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show every tag with its transaction count",
+	Args:  cobra.NoArgs,
+	RunE:  runTagList,
+}
+
+var (
+	tagUUIDs      []string
+	tagMerchant   string
+	tagNarration  string
+	tagAccountID  string
+	tagCategoryID string
+	tagFrom       string
+	tagTo         string
+	tagType       string
+	tagMinAmount  string
+	tagMaxAmount  string
+	tagWithTags   []string
+	tagAll        bool
+)
+
+func init() {
+	for _, c := range []*cobra.Command{tagAddCmd, tagRemoveCmd} {
+		c.Flags().StringSliceVar(&tagUUIDs, "uuid", nil, "Transaction UUID (repeatable)")
+		c.Flags().StringVar(&tagMerchant, "merchant", "", "Merchant name (or narration) contains this text")
+		c.Flags().StringVar(&tagNarration, "narration", "", "Narration contains this text")
+		c.Flags().StringVar(&tagAccountID, "account-id", "", "Account UUID")
+		c.Flags().StringVar(&tagCategoryID, "category-id", "", "Bend category ID")
+		c.Flags().StringVar(&tagFrom, "from", "", "Transactions on or after this date (YYYY-MM-DD)")
+		c.Flags().StringVar(&tagTo, "to", "", "Transactions on or before this date (YYYY-MM-DD)")
+		amountFilterFlags(c, &tagMinAmount, &tagMaxAmount, &tagType)
+		c.Flags().StringSliceVar(&tagWithTags, "with-tag", nil, "Only transactions already carrying this tag (repeatable)")
+		c.Flags().BoolVar(&tagAll, "all", false, "Match every stored transaction when no other filter is given")
+	}
+
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+	tagCmd.AddCommand(tagListCmd)
+}
+
+// =============================================================================
+// TAG COMMAND IMPLEMENTATIONS
+// =============================================================================
+
+// runTagAdd tags the matching transactions
+func runTagAdd(cmd *cobra.Command, args []string) error {
+	return updateTags(cmd, args[0], true)
+}
+
+// runTagRemove untags the matching transactions
+func runTagRemove(cmd *cobra.Command, args []string) error {
+	return updateTags(cmd, args[0], false)
+}
+
+// updateTags adds or removes a tag on every transaction the flags select
+func updateTags(cmd *cobra.Command, name string, add bool) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	tag, err := store.NormalizeTag(name)
+	if err != nil {
+		return err
+	}
+
+	query, err := tagQuery(cfg.RoundingMode())
+	if err != nil {
+		return err
+	}
+	if query.IsEmpty() && !tagAll {
+		return fmt.Errorf("no filters given; pass --all to match every stored transaction")
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := st.Checkpoint()
+	if err != nil {
+		return err
+	}
+
+	matched := st.Find(query)
+	updated := 0
+	for _, record := range matched {
+		var changed bool
+		if add {
+			changed = record.AddTag(tag)
+		} else {
+			changed = record.RemoveTag(tag)
+		}
+		if !changed {
+			continue
+		}
+		updated++
+		if IsVerbose() {
+			txn := record.Transaction
+			fmt.Printf("  🏷️  %s %s %.2f %s\n", txn.TxnTimestamp.Local().Format("2006-01-02"), txn.UUID, txn.SignedAmount(), txn.Narration)
+		}
+	}
+
+	action := "Tagged"
+	if !add {
+		action = "Untagged"
+	}
+
+	if IsDryRun() {
+		fmt.Printf("🔍 [dry-run] Would update %d of %d matching transaction(s) (%s '%s')\n", updated, len(matched), action, tag)
+		return nil
+	}
+
+	if updated > 0 {
+		summary := fmt.Sprintf("tag %s '%s' on %d transaction(s)", cmd.Name(), tag, updated)
+		if err := saveWithHistory(st, checkpoint, summary); err != nil {
+			return err
+		}
+	}
+
+	if !IsQuiet() {
+		fmt.Printf("✅ %s %d transaction(s) '%s' (%d matched)\n", action, updated, tag, len(matched))
+	}
+	return nil
+}
+
+// tagFilterFlags adds the --tag, --any-tag and --exclude-tag filters to a command
+func tagFilterFlags(c *cobra.Command, all, any, exclude *[]string) {
+	c.Flags().StringSliceVar(all, "tag", nil, "Only transactions carrying every one of these tags (repeatable)")
+	c.Flags().StringSliceVar(any, "any-tag", nil, "Only transactions carrying at least one of these tags (repeatable)")
+	c.Flags().StringSliceVar(exclude, "exclude-tag", nil, "Leave out transactions carrying any of these tags (repeatable)")
+}
+
+// amountFilterFlags adds the --min-amount, --max-amount and --type filters to a command
+func amountFilterFlags(c *cobra.Command, min, max *string, txnType *string) {
+	c.Flags().StringVar(min, "min-amount", "", "Only transactions of at least this amount (e.g. 1000 or 1000INR)")
+	c.Flags().StringVar(max, "max-amount", "", "Only transactions of at most this amount (e.g. 5000 or 50USD)")
+	c.Flags().StringVar(txnType, "type", "", "Only incoming or outgoing transactions")
+}
+
+// amountFilter validates the amount and type filters, returning the type in API form
+func amountFilter(min, max, txnType string, mode money.RoundingMode) (money.Range, string, error) {
+	amounts, err := money.ParseRange(min, max, mode)
+	if err != nil {
+		return money.Range{}, "", fmt.Errorf("invalid --min-amount/--max-amount: %w", err)
+	}
+	if txnType == "" {
+		return amounts, "", nil
+	}
+	direction, err := blend.ParseTransactionType(txnType)
+	return amounts, direction, err
+}
+
+// tagQuery builds the store query from the selection flags
+func tagQuery(mode money.RoundingMode) (store.Query, error) {
+	query := store.Query{
+		UUIDs:      tagUUIDs,
+		Merchant:   tagMerchant,
+		Narration:  tagNarration,
+		AccountID:  tagAccountID,
+		CategoryID: tagCategoryID,
+	}
+
+	var err error
+	if query.Amount, query.Type, err = amountFilter(tagMinAmount, tagMaxAmount, tagType, mode); err != nil {
+		return query, err
+	}
+	if tagFrom != "" {
+		if query.From, err = time.Parse("2006-01-02", tagFrom); err != nil {
+			return query, fmt.Errorf("invalid --from date %q (use YYYY-MM-DD)", tagFrom)
+		}
+	}
+	if tagTo != "" {
+		if query.To, err = time.Parse("2006-01-02", tagTo); err != nil {
+			return query, fmt.Errorf("invalid --to date %q (use YYYY-MM-DD)", tagTo)
+		}
+		// Include the whole end day
+		query.To = query.To.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	if query.Tags, err = store.NewTagFilter(tagWithTags, nil, nil); err != nil {
+		return query, err
+	}
+	return query, nil
+}
+
+// runTagList prints every tag with its transaction count
+func runTagList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetFromContext(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	st, err := store.OpenBackend(cfg.Store.Backend, cfg.Store.Path)
+	if err != nil {
+		return err
+	}
+
+	counts := st.TagCounts()
+	if len(counts) == 0 {
+		fmt.Println("No tags yet. Use 'fintrack tag add <tag> --merchant ...' to add one")
+		return nil
+	}
+
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	table := display.New(cfg.Display).Table(display.Column{Header: "Tag"}, display.Column{Header: "Transactions", Right: true})
+	for _, tag := range tags {
+		table.Row(tag, strconv.Itoa(counts[tag]))
+	}
+	table.Render(os.Stdout)
+	return nil
+}
@@ -0,0 +1,147 @@
+package alerts
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/config"
+)
+
+// SMTP TLS modes
+const (
+	TLSStartTLS = "starttls" // Plain connection upgraded with STARTTLS (submission, port 587)
+	TLSImplicit = "implicit" // TLS from the first byte (port 465)
+	TLSNone     = "none"     // No encryption, for a relay on localhost
+)
+
+// ValidateSMTP checks the mail server settings
+func ValidateSMTP(cfg config.SMTPConfig) error {
+	switch strings.ToLower(cfg.TLS) {
+	case "", TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return fmt.Errorf("alerts.smtp.tls must be %s, %s or %s", TLSStartTLS, TLSImplicit, TLSNone)
+	}
+	if cfg.Port < 0 || cfg.Port > 65535 {
+		return fmt.Errorf("alerts.smtp.port must be between 1 and 65535")
+	}
+	return nil
+}
+
+// sendEmail mails an alert to recipients: the message is the subject, the
+// body (or the message) the text
+func sendEmail(ctx context.Context, cfg config.SMTPConfig, to []string, alert Alert) error {
+	mode := strings.ToLower(cfg.TLS)
+	if mode == "" {
+		mode = TLSStartTLS
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+		if mode == TLSImplicit {
+			port = 465
+		}
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	var err error
+	if mode == TLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: cfg.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session with %s: %w", addr, err)
+	}
+	defer client.Close()
+
+	if mode == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s does not offer STARTTLS; set alerts.smtp.tls to implicit or none", addr)
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			return fmt.Errorf("STARTTLS with %s failed: %w", addr, err)
+		}
+	}
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(cfg.From); err != nil {
+		return fmt.Errorf("SMTP server rejected sender %s: %w", cfg.From, err)
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("SMTP server rejected recipient %s: %w", recipient, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if _, err := w.Write(emailMessage(cfg.From, to, alert)); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return client.Quit()
+}
+
+// emailMessage builds a plain-text RFC 5322 message
+func emailMessage(from string, to []string, alert Alert) []byte {
+	subject := alert.Message
+	if i := strings.IndexByte(subject, '\n'); i >= 0 {
+		subject = subject[:i]
+	}
+	body := alert.Body
+	if body == "" {
+		body = alert.Message
+	}
+	if alert.File != "" {
+		body += "\n\nFile: " + alert.File
+	}
+	at := alert.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", "fintrack: "+subject) + "\r\n")
+	b.WriteString("Date: " + at.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	// Base64 keeps emoji and box drawing intact and lines short
+	encoded := base64.StdEncoding.EncodeToString([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return []byte(b.String())
+}
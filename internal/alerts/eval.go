@@ -0,0 +1,286 @@
+package alerts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/store"
+)
+
+// Alert is a rule that triggered
+type Alert struct {
+	Rule     string    `json:"rule"`
+	Message  string    `json:"message"`
+	Period   string    `json:"period"` // Current period, e.g. 2024-03; an alert is sent once per period
+	Channels []string  `json:"-"`
+	At       time.Time `json:"triggered_at"`
+	File     string    `json:"file,omitempty"` // Generated file the notification is about, if any
+	Body     string    `json:"body,omitempty"` // Longer text, such as a summary, for channels that can show it
+}
+
+// Rules parses every configured rule
+func Rules(cfg config.AlertsConfig) ([]*Rule, error) {
+	var rules []*Rule
+	for _, name := range sortedKeys(cfg.Rules) {
+		rule, err := Parse(name, cfg.Rules[name].When)
+		if err != nil {
+			return nil, err
+		}
+		for _, channel := range cfg.Rules[name].Channels {
+			if _, ok := cfg.Channels[strings.ToLower(channel)]; !ok {
+				return nil, fmt.Errorf("rule %s: unknown channel %q", name, channel)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Validate checks the rules and channels of an alerts section
+func Validate(cfg config.AlertsConfig) error {
+	for name, channel := range cfg.Channels {
+		switch strings.ToLower(channel.Type) {
+		case "webhook":
+			if channel.URL == "" {
+				return fmt.Errorf("alerts.channels.%s: url is required", name)
+			}
+		case "command":
+			if channel.Command == "" {
+				return fmt.Errorf("alerts.channels.%s: command is required", name)
+			}
+		case "plugin":
+			if channel.Plugin == "" {
+				return fmt.Errorf("alerts.channels.%s: plugin is required", name)
+			}
+		case "email":
+			if len(channel.To) == 0 {
+				return fmt.Errorf("alerts.channels.%s: to is required", name)
+			}
+			if cfg.SMTP.Host == "" || cfg.SMTP.From == "" {
+				return fmt.Errorf("alerts.channels.%s: email channels need alerts.smtp.host and alerts.smtp.from", name)
+			}
+		default:
+			return fmt.Errorf("alerts.channels.%s: unknown type %q (use webhook, command, email or plugin)", name, channel.Type)
+		}
+	}
+	if err := ValidateSMTP(cfg.SMTP); err != nil {
+		return err
+	}
+	_, err := Rules(cfg)
+	return err
+}
+
+// Evaluate checks every rule against the stored records at now
+func Evaluate(cfg config.AlertsConfig, records []*store.Record, now time.Time, rounding money.RoundingMode) ([]Alert, error) {
+	rules, err := Rules(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var triggered []Alert
+	for _, rule := range rules {
+		message, ok := rule.Evaluate(records, now, rounding)
+		if !ok {
+			continue
+		}
+		triggered = append(triggered, Alert{
+			Rule:     rule.Name,
+			Message:  message,
+			Period:   rule.periodKey(now),
+			Channels: cfg.Rules[rule.Name].Channels,
+			At:       now,
+		})
+	}
+	return triggered, nil
+}
+
+// Evaluate reports whether every comparison of the rule holds at now, with a
+// message describing the values that made it trigger
+func (r *Rule) Evaluate(records []*store.Record, now time.Time, rounding money.RoundingMode) (string, bool) {
+	var matching []blend.Transaction
+	for _, record := range records {
+		if r.matches(record) {
+			matching = append(matching, record.Transaction)
+		}
+	}
+
+	var details []string
+	for _, c := range r.Comparisons {
+		start := periodStart(now, c.Period)
+		current := aggregate(matching, start, now.Add(time.Nanosecond), c.Aggregate, rounding)
+
+		threshold := c.Factor
+		detail := ""
+		if c.Trailing > 0 {
+			baseline, ok := trailingAverage(matching, start, c, rounding)
+			if !ok {
+				// No history to compare with yet
+				return "", false
+			}
+			threshold = c.Factor * baseline
+			detail = fmt.Sprintf(" (%s × trailing %d %s average of %s)",
+				formatNumber(c.Factor), c.Trailing, periodUnits[c.Period], formatValue(baseline, c.Aggregate))
+		}
+
+		if !compare(current, c.Operator, threshold) {
+			return "", false
+		}
+		details = append(details, fmt.Sprintf("%s %s %s %s %s%s", c.Period, c.Aggregate,
+			formatValue(current, c.Aggregate), c.Operator, formatValue(threshold, c.Aggregate), detail))
+	}
+
+	return fmt.Sprintf("%s: %s", r.Name, strings.Join(details, "; ")), true
+}
+
+// reportedFlags leaves hidden and cash-flow-excluded transactions out of
+// rules, as in reports
+var reportedFlags = blend.TransactionFilters{ExcludeCashflowExcluded: true}
+
+// matches reports whether a record passes every filter of the rule.
+// Without a type filter only outgoing transactions (spending) count.
+func (r *Rule) matches(record *store.Record) bool {
+	txn := record.Transaction
+	if !reportedFlags.Allows(&txn) {
+		return false
+	}
+
+	typed := false
+	for _, f := range r.Filters {
+		var ok bool
+		switch f.Field {
+		case "category":
+			ok = txn.Category != nil && txn.Category.ID != nil && strings.EqualFold(*txn.Category.ID, f.Value)
+		case "subcategory":
+			ok = txn.Category != nil && txn.Category.SubcategoryID != nil && strings.EqualFold(*txn.Category.SubcategoryID, f.Value)
+		case "merchant":
+			ok = strings.Contains(strings.ToLower(merchant(txn)), strings.ToLower(f.Value))
+		case "account":
+			ok = strings.EqualFold(txn.AccountID, f.Value)
+		case "tag":
+			ok = record.HasTag(strings.ToLower(f.Value))
+		case "type":
+			typed = true
+			ok = strings.EqualFold(txn.Type, f.Value)
+		}
+		if ok == f.Negate {
+			return false
+		}
+	}
+
+	return typed || txn.Type == blend.TransactionTypeOutgoing
+}
+
+// periodKey names the current period of the rule's shortest comparison
+func (r *Rule) periodKey(now time.Time) string {
+	shortest := "monthly"
+	for _, c := range r.Comparisons {
+		if c.Period == "daily" || (c.Period == "weekly" && shortest == "monthly") {
+			shortest = c.Period
+		}
+	}
+	switch shortest {
+	case "daily":
+		return now.Format("2006-01-02")
+	case "weekly":
+		year, week := now.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	default:
+		return now.Format("2006-01")
+	}
+}
+
+// trailingAverage averages the aggregate over the periods before start. It
+// is not ok when none of them had any matching transaction.
+func trailingAverage(transactions []blend.Transaction, start time.Time, c Comparison, rounding money.RoundingMode) (float64, bool) {
+	sum := 0.0
+	seen := false
+	for k := 1; k <= c.Trailing; k++ {
+		from, to := shiftPeriod(start, c.Period, -k), shiftPeriod(start, c.Period, -k+1)
+		sum += aggregate(transactions, from, to, c.Aggregate, rounding)
+		if aggregate(transactions, from, to, "count", rounding) > 0 {
+			seen = true
+		}
+	}
+	return sum / float64(c.Trailing), seen
+}
+
+// aggregate totals or counts the transactions in [from, to)
+func aggregate(transactions []blend.Transaction, from, to time.Time, kind string, rounding money.RoundingMode) float64 {
+	var total money.Amount
+	count := 0
+	for _, txn := range transactions {
+		if txn.TxnTimestamp.Before(from) || !txn.TxnTimestamp.Before(to) {
+			continue
+		}
+		total += money.FromFloat(txn.Amount, rounding).Abs()
+		count++
+	}
+	if kind == "count" {
+		return float64(count)
+	}
+	return total.Float64()
+}
+
+// periodStart is the start of the period containing t (weeks start Monday)
+func periodStart(t time.Time, period string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch period {
+	case "daily":
+		return day
+	case "weekly":
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// shiftPeriod moves a period start by n periods
+func shiftPeriod(start time.Time, period string, n int) time.Time {
+	switch period {
+	case "daily":
+		return start.AddDate(0, 0, n)
+	case "weekly":
+		return start.AddDate(0, 0, 7*n)
+	default:
+		return start.AddDate(0, n, 0)
+	}
+}
+
+// compare applies a comparison operator
+func compare(value float64, op string, threshold float64) bool {
+	switch op {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case "==":
+		return value == threshold
+	default:
+		return value != threshold
+	}
+}
+
+// formatValue prints an aggregate value
+func formatValue(value float64, kind string) string {
+	if kind == "count" {
+		return formatNumber(value)
+	}
+	return fmt.Sprintf("%.2f", value)
+}
+
+// merchant is the merchant name Bend resolved, otherwise the narration
+func merchant(txn blend.Transaction) string {
+	if txn.Merchant != nil && txn.Merchant.Name != nil && *txn.Merchant.Name != "" {
+		return *txn.Merchant.Name
+	}
+	return txn.Narration
+}
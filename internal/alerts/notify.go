@@ -0,0 +1,172 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/plugin"
+	"github.com/quickkly/fintrack/internal/safefile"
+)
+
+// notifyTimeout bounds each delivery so a dead endpoint can't stall a sync
+const notifyTimeout = 30 * time.Second
+
+// Notify delivers an alert to one channel; smtp is the mail server for email
+// channels
+func Notify(ctx context.Context, name string, channel config.AlertChannel, smtp config.SMTPConfig, alert Alert) error {
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	switch strings.ToLower(channel.Type) {
+	case "webhook":
+		body, err := json.Marshal(alert)
+		if err != nil {
+			return fmt.Errorf("failed to marshal alert: %w", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.URL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("channel %s: failed to create request: %w", name, err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for header, value := range channel.Headers {
+			req.Header.Set(header, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("channel %s: %w", name, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("channel %s: webhook returned %s", name, resp.Status)
+		}
+		return nil
+
+	case "command":
+		cmd := plugin.ShellCommand(ctx, channel.Command)
+		cmd.Env = append(plugin.Environ(),
+			"FINTRACK_ALERT_RULE="+alert.Rule,
+			"FINTRACK_ALERT_MESSAGE="+alert.Message,
+			"FINTRACK_ALERT_PERIOD="+alert.Period,
+			"FINTRACK_ALERT_FILE="+alert.File,
+			"FINTRACK_ALERT_BODY="+alert.Body,
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("channel %s: command failed: %w: %s", name, err, strings.TrimSpace(string(out)))
+		}
+		return nil
+
+	case "email":
+		if err := sendEmail(ctx, smtp, channel.To, alert); err != nil {
+			return fmt.Errorf("channel %s: %w", name, err)
+		}
+		return nil
+
+	case "plugin":
+		if _, err := plugin.Call(ctx, channel.Plugin, plugin.Request{Kind: plugin.KindNotify, Target: name, Alert: alert}); err != nil {
+			return fmt.Errorf("channel %s: %w", name, err)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("channel %s: unknown type %q", name, channel.Type)
+	}
+}
+
+// State remembers the period each rule last notified for, so a rule that
+// stays triggered all month is only sent once
+type State struct {
+	Sent map[string]string `json:"sent"` // Rule name → period key
+
+	path string
+}
+
+// LoadState reads the alert state. A missing file is an empty state.
+func LoadState(path string) (*State, error) {
+	s := &State{Sent: make(map[string]string), path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert state: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse alert state %s: %w", path, err)
+	}
+	if s.Sent == nil {
+		s.Sent = make(map[string]string)
+	}
+	return s, nil
+}
+
+// Due reports whether an alert hasn't been sent for its period yet
+func (s *State) Due(alert Alert) bool {
+	return s.Sent[alert.Rule] != alert.Period
+}
+
+// MarkSent records an alert as delivered
+func (s *State) MarkSent(alert Alert) {
+	s.Sent[alert.Rule] = alert.Period
+}
+
+// Save writes the alert state
+func (s *State) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create alert state directory: %w", err)
+	}
+	if err := safefile.Write(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write alert state: %w", err)
+	}
+	return nil
+}
+
+// Deliver sends each due alert to its rule's channels and records it as sent
+// when every channel succeeded. It returns how many alerts were sent; failures
+// are returned, not fatal.
+func Deliver(ctx context.Context, cfg config.AlertsConfig, state *State, triggered []Alert) (int, []error) {
+	sent := 0
+	var errs []error
+	for _, alert := range triggered {
+		if !state.Due(alert) {
+			continue
+		}
+		ok := true
+		for _, name := range alert.Channels {
+			name = strings.ToLower(name)
+			if err := Notify(ctx, name, cfg.Channels[name], cfg.SMTP, alert); err != nil {
+				errs = append(errs, fmt.Errorf("alert %s: %w", alert.Rule, err))
+				ok = false
+			}
+		}
+		if ok {
+			state.MarkSent(alert)
+			sent++
+		}
+	}
+	return sent, errs
+}
+
+// sortedKeys returns the keys of a rules map in order
+func sortedKeys(m map[string]config.AlertRule) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
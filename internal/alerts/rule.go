@@ -0,0 +1,223 @@
+// Package alerts parses and evaluates alert rules over stored transactions
+// and delivers the resulting notifications.
+//
+// A rule is one or more conditions joined by "and". Filters choose the
+// transactions a rule looks at; comparisons test an aggregate of them in the
+// current period against a number or a trailing average:
+//
+//	category=dining and monthly total > 1.5 * trailing 3 month average
+//	merchant="AMAZON" and weekly count >= 5
+//	type=incoming and monthly total < 50000
+package alerts
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter fields a rule may use
+var filterFields = []string{"category", "subcategory", "merchant", "account", "tag", "type"}
+
+// Periods and aggregates a comparison may use
+var (
+	periods    = []string{"daily", "weekly", "monthly"}
+	aggregates = []string{"total", "count"}
+	operators  = []string{">", ">=", "<", "<=", "==", "!="}
+)
+
+// periodUnits maps a period to the unit of its trailing averages
+var periodUnits = map[string]string{"daily": "day", "weekly": "week", "monthly": "month"}
+
+// Rule is a parsed alert rule
+type Rule struct {
+	Name        string
+	Source      string
+	Filters     []Filter
+	Comparisons []Comparison
+}
+
+// Filter restricts the transactions a rule looks at
+type Filter struct {
+	Field  string
+	Negate bool // != instead of =
+	Value  string
+}
+
+// Comparison tests an aggregate of the current period
+type Comparison struct {
+	Period    string  // daily, weekly or monthly
+	Aggregate string  // total or count
+	Operator  string  // >, >=, <, <=, == or !=
+	Factor    float64 // Multiplier of the baseline, or the threshold itself when Trailing is 0
+	Trailing  int     // Number of previous periods averaged as the baseline; 0 for a fixed threshold
+}
+
+// String renders a comparison back in rule syntax
+func (c Comparison) String() string {
+	if c.Trailing == 0 {
+		return fmt.Sprintf("%s %s %s %s", c.Period, c.Aggregate, c.Operator, formatNumber(c.Factor))
+	}
+	baseline := fmt.Sprintf("trailing %d %s average", c.Trailing, periodUnits[c.Period])
+	if c.Factor != 1 {
+		baseline = formatNumber(c.Factor) + " * " + baseline
+	}
+	return fmt.Sprintf("%s %s %s %s", c.Period, c.Aggregate, c.Operator, baseline)
+}
+
+// baselinePattern matches the right-hand side of a comparison:
+// [factor *] trailing N <unit> average [* factor], or a plain number
+var baselinePattern = regexp.MustCompile(`^(?:(\d+(?:\.\d+)?)\s*[*×x]\s*)?trailing[\s_-]+(\d+)[\s_-]+(day|week|month)s?[\s_-]+(?:average|avg)(?:\s*[*×x]\s*(\d+(?:\.\d+)?))?$`)
+
+// Parse parses a rule
+func Parse(name, source string) (*Rule, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", name, err)
+	}
+
+	rule := &Rule{Name: name, Source: source}
+	for _, cond := range splitAnd(tokens) {
+		if len(cond) == 0 {
+			return nil, fmt.Errorf("rule %s: empty condition", name)
+		}
+		if len(cond) >= 3 && (cond[1] == "=" || cond[1] == "!=") && isOneOf(strings.ToLower(cond[0]), filterFields) {
+			if len(cond) != 3 {
+				return nil, fmt.Errorf("rule %s: unexpected %q after %s%s%s (quote values containing spaces)", name, cond[3], cond[0], cond[1], cond[2])
+			}
+			rule.Filters = append(rule.Filters, Filter{Field: strings.ToLower(cond[0]), Negate: cond[1] == "!=", Value: cond[2]})
+			continue
+		}
+
+		comparison, err := parseComparison(cond)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+		rule.Comparisons = append(rule.Comparisons, comparison)
+	}
+
+	if len(rule.Comparisons) == 0 {
+		return nil, fmt.Errorf("rule %s: needs at least one comparison such as 'monthly total > 5000'", name)
+	}
+	return rule, nil
+}
+
+// parseComparison parses "<period> <aggregate> <op> <threshold or baseline>"
+func parseComparison(cond []string) (Comparison, error) {
+	if len(cond) < 4 {
+		return Comparison{}, fmt.Errorf("incomplete condition %q (expected e.g. 'monthly total > 5000')", strings.Join(cond, " "))
+	}
+
+	c := Comparison{
+		Period:    strings.ToLower(cond[0]),
+		Aggregate: strings.ToLower(cond[1]),
+		Operator:  cond[2],
+	}
+	if !isOneOf(c.Period, periods) {
+		return c, fmt.Errorf("unknown period or filter %q (use %s, or a filter: %s)", cond[0], strings.Join(periods, ", "), strings.Join(filterFields, ", "))
+	}
+	if !isOneOf(c.Aggregate, aggregates) {
+		return c, fmt.Errorf("unknown aggregate %q (use %s)", cond[1], strings.Join(aggregates, ", "))
+	}
+	if !isOneOf(c.Operator, operators) {
+		return c, fmt.Errorf("unknown operator %q (use %s)", cond[2], strings.Join(operators, " "))
+	}
+
+	rhs := strings.ToLower(strings.Join(cond[3:], " "))
+	if value, err := strconv.ParseFloat(rhs, 64); err == nil {
+		c.Factor = value
+		return c, nil
+	}
+
+	m := baselinePattern.FindStringSubmatch(rhs)
+	if m == nil {
+		return c, fmt.Errorf("can't compare with %q (use a number or e.g. '1.5 * trailing 3 month average')", rhs)
+	}
+	c.Trailing, _ = strconv.Atoi(m[2])
+	if c.Trailing < 1 {
+		return c, fmt.Errorf("trailing average needs at least 1 %s", m[3])
+	}
+	if m[3] != periodUnits[c.Period] {
+		return c, fmt.Errorf("a %s comparison needs a trailing %s average, not %s", c.Period, periodUnits[c.Period], m[3])
+	}
+	c.Factor = 1
+	for _, factor := range []string{m[1], m[4]} {
+		if factor != "" {
+			f, _ := strconv.ParseFloat(factor, 64)
+			c.Factor *= f
+		}
+	}
+	return c, nil
+}
+
+// tokenize splits a rule into words, operators and quoted values
+func tokenize(source string) ([]string, error) {
+	var tokens []string
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			tokens = append(tokens, string(runes[i+1:end]))
+			i = end + 1
+		case strings.ContainsRune("<>=!", r):
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, string(runes[i:i+2]))
+				i += 2
+			} else if r == '!' {
+				return nil, fmt.Errorf("unexpected '!'")
+			} else {
+				tokens = append(tokens, string(r))
+				i++
+			}
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("<>=!\"'", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		}
+	}
+	return tokens, nil
+}
+
+// splitAnd splits tokens into conditions at each "and"
+func splitAnd(tokens []string) [][]string {
+	var conds [][]string
+	current := []string{}
+	for _, token := range tokens {
+		if strings.EqualFold(token, "and") {
+			conds = append(conds, current)
+			current = []string{}
+			continue
+		}
+		current = append(current, token)
+	}
+	return append(conds, current)
+}
+
+// isOneOf reports whether value is in allowed
+func isOneOf(value string, allowed []string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+// formatNumber prints a number without needless decimals
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
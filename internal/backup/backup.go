@@ -0,0 +1,260 @@
+// Package backup packs fintrack's local state (the store, the config file
+// and alert state) into one passphrase-encrypted archive that can be
+// restored on another machine. The archive is a gzipped tar with a manifest,
+// sealed like an encrypted store.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/redact"
+	"github.com/quickkly/fintrack/internal/store"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the archive format written by this release
+const Version = 1
+
+// magic starts every backup archive
+var magic = []byte("FINTRACK-BACKUP-1\n")
+
+// manifestName is the manifest's name inside the archive
+const manifestName = "manifest.json"
+
+// What each file in an archive is, which decides where it is restored
+const (
+	RoleConfig     = "config"
+	RoleStore      = "store"
+	RoleAlertState = "alert_state"
+	RoleReceipt    = "receipt"
+)
+
+// Manifest describes an archive's contents
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Host      string    `json:"host,omitempty"`
+	Secrets   bool      `json:"secrets"` // The config kept its secrets
+	Files     []Entry   `json:"files"`
+}
+
+// Entry is a file in an archive
+type Entry struct {
+	Name   string `json:"name"`   // Path inside the archive
+	Role   string `json:"role"`   // RoleConfig, RoleStore, ...
+	Source string `json:"source"` // Where it was backed up from
+	Size   int    `json:"size"`
+}
+
+// Archive is a backup held in memory
+type Archive struct {
+	Manifest Manifest
+	files    map[string][]byte
+}
+
+// New returns an empty archive
+func New(createdAt time.Time, host string) *Archive {
+	return &Archive{
+		Manifest: Manifest{Version: Version, CreatedAt: createdAt, Host: host},
+		files:    make(map[string][]byte),
+	}
+}
+
+// Add puts a file in the archive under name
+func (a *Archive) Add(name, role, source string, data []byte) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	if _, ok := a.files[name]; ok {
+		return fmt.Errorf("%s is already in the backup", name)
+	}
+	a.files[name] = data
+	a.Manifest.Files = append(a.Manifest.Files, Entry{Name: name, Role: role, Source: source, Size: len(data)})
+	return nil
+}
+
+// File returns the content of a file in the archive
+func (a *Archive) File(name string) []byte {
+	return a.files[name]
+}
+
+// Entries returns the files with role, in archive order
+func (a *Archive) Entries(role string) []Entry {
+	var entries []Entry
+	for _, entry := range a.Manifest.Files {
+		if entry.Role == role {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Encode writes the archive encrypted with passphrase
+func (a *Archive) Encode(passphrase string) ([]byte, error) {
+	manifest, err := json.MarshalIndent(a.Manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode backup manifest: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	write := func(name string, data []byte) error {
+		header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: a.Manifest.CreatedAt}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := write(manifestName, manifest); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	for _, entry := range a.Manifest.Files {
+		if err := write(entry.Name, a.files[entry.Name]); err != nil {
+			return nil, fmt.Errorf("failed to write backup: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	return store.Seal(magic, passphrase, buf.Bytes())
+}
+
+// IsArchive reports whether data looks like a backup archive
+func IsArchive(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// Decode decrypts and unpacks an archive
+func Decode(data []byte, passphrase string) (*Archive, error) {
+	if !IsArchive(data) {
+		return nil, fmt.Errorf("not a fintrack backup")
+	}
+	plain, err := store.Unseal(magic, passphrase, data)
+	if err != nil {
+		if err == store.ErrWrongPassphrase {
+			return nil, fmt.Errorf("wrong passphrase, or the backup is corrupted")
+		}
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(plain))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	files := make(map[string][]byte)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+		if err := checkName(header.Name); err != nil {
+			return nil, err
+		}
+		if files[header.Name], err = io.ReadAll(tr); err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+	}
+
+	a := &Archive{files: files}
+	manifest, ok := files[manifestName]
+	if !ok {
+		return nil, fmt.Errorf("backup has no manifest")
+	}
+	delete(files, manifestName)
+	if err := json.Unmarshal(manifest, &a.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to read backup manifest: %w", err)
+	}
+	if a.Manifest.Version > Version {
+		return nil, fmt.Errorf("backup has format version %d, newer than this fintrack supports (%d)", a.Manifest.Version, Version)
+	}
+	for _, entry := range a.Manifest.Files {
+		if _, ok := files[entry.Name]; !ok {
+			return nil, fmt.Errorf("backup is missing %s", entry.Name)
+		}
+	}
+	return a, nil
+}
+
+// checkName rejects archive paths that could escape the restore directory
+func checkName(name string) error {
+	if name == "" || path.IsAbs(name) || strings.Contains(name, "\\") || path.Clean(name) != name ||
+		name == ".." || strings.HasPrefix(name, "../") {
+		return fmt.Errorf("invalid path %q in backup", name)
+	}
+	return nil
+}
+
+// StripSecrets removes every secret string (tokens, passwords, device hash)
+// from a YAML config, keeping its comments and layout. It returns the dotted
+// keys removed, sorted.
+func StripSecrets(data []byte) ([]byte, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return data, nil, nil
+	}
+
+	var removed []string
+	stripNode(doc.Content[0], "", &removed)
+	if len(removed) == 0 {
+		return data, nil, nil
+	}
+	sort.Strings(removed)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to write config: %w", err)
+	}
+	return buf.Bytes(), removed, nil
+}
+
+// stripNode drops secret string values from a mapping, recursively. Only
+// strings are secrets: flags like secrets_from_env stay.
+func stripNode(node *yaml.Node, prefix string, removed *[]string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		kept := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			dotted := key.Value
+			if prefix != "" {
+				dotted = prefix + "." + key.Value
+			}
+			if value.Kind == yaml.ScalarNode && value.Tag == "!!str" && value.Value != "" && redact.IsSecret(key.Value) {
+				*removed = append(*removed, dotted)
+				continue
+			}
+			stripNode(value, dotted, removed)
+			kept = append(kept, key, value)
+		}
+		node.Content = kept
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			stripNode(item, fmt.Sprintf("%s[%d]", prefix, i), removed)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package balance
+
+import (
+	"sort"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// Tolerance is the largest difference treated as a rounding error rather than missing data
+const Tolerance money.Amount = 1
+
+// Point is a transaction together with the account balance after it
+type Point struct {
+	Transaction    blend.Transaction
+	RunningBalance money.Amount
+	Divergence     *Divergence // Set on the last point before a snapshot that disagrees
+}
+
+// Snapshot is a balance reported by Bend at a point in time
+type Snapshot struct {
+	Balance money.Amount
+	At      time.Time
+}
+
+// Divergence records a snapshot the computed balance failed to match
+type Divergence struct {
+	Snapshot Snapshot
+	Computed money.Amount
+}
+
+// Difference returns how far the reported balance is from the computed one
+func (d Divergence) Difference() money.Amount {
+	return d.Snapshot.Balance - d.Computed
+}
+
+// Result is the running balance computation for one account
+type Result struct {
+	Opening     money.Amount
+	Points      []Point
+	Closing     money.Amount
+	Divergences []Divergence
+}
+
+// Compute walks an account's transactions in time order starting from the
+// opening balance. Transactions before start are ignored (zero start keeps
+// everything). Each snapshot is compared with the computed balance at its
+// timestamp and disagreements beyond Tolerance are reported as divergences,
+// which usually means transactions are missing locally. Amounts are summed in
+// paisa, each transaction rounded once using mode.
+func Compute(opening money.Amount, start time.Time, transactions []blend.Transaction, snapshots []Snapshot, mode money.RoundingMode) Result {
+	sorted := make([]blend.Transaction, 0, len(transactions))
+	for _, txn := range transactions {
+		if !start.IsZero() && txn.TxnTimestamp.Before(start) {
+			continue
+		}
+		sorted = append(sorted, txn)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].TxnTimestamp.Before(sorted[j].TxnTimestamp)
+	})
+
+	snaps := append([]Snapshot(nil), snapshots...)
+	sort.Slice(snaps, func(i, j int) bool {
+		return snaps[i].At.Before(snaps[j].At)
+	})
+
+	result := Result{Opening: opening}
+	running := opening
+	next := 0
+
+	// checkSnapshots compares every snapshot taken before cutoff against the
+	// current running balance and attaches divergences to the latest point
+	checkSnapshots := func(cutoff time.Time, last bool) {
+		for next < len(snaps) && (last || snaps[next].At.Before(cutoff)) {
+			snap := snaps[next]
+			next++
+			if !start.IsZero() && snap.At.Before(start) {
+				continue
+			}
+			if (snap.Balance - running).Abs() <= Tolerance {
+				continue
+			}
+			d := Divergence{Snapshot: snap, Computed: running}
+			result.Divergences = append(result.Divergences, d)
+			if n := len(result.Points); n > 0 {
+				result.Points[n-1].Divergence = &d
+			}
+		}
+	}
+
+	for _, txn := range sorted {
+		checkSnapshots(txn.TxnTimestamp, false)
+		running += money.FromFloat(txn.SignedAmount(), mode)
+		result.Points = append(result.Points, Point{Transaction: txn, RunningBalance: running})
+	}
+	checkSnapshots(time.Time{}, true)
+
+	result.Closing = running
+	return result
+}
@@ -0,0 +1,60 @@
+package blend
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// Usage counts the API traffic of the current process
+type Usage struct {
+	Requests      int64 `json:"requests"`
+	BytesSent     int64 `json:"bytes_sent"`
+	BytesReceived int64 `json:"bytes_received"` // Response bodies as received, before decompression
+}
+
+// runUsage accumulates traffic across every client created in this process
+var runUsage struct {
+	requests      atomic.Int64
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+}
+
+// RunUsage returns the API traffic of this process so far
+func RunUsage() Usage {
+	return Usage{
+		Requests:      runUsage.requests.Load(),
+		BytesSent:     runUsage.bytesSent.Load(),
+		BytesReceived: runUsage.bytesReceived.Load(),
+	}
+}
+
+// accountingMiddleware counts every request that reaches the network and the
+// bytes sent and received for it
+func accountingMiddleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		runUsage.requests.Add(1)
+		if req.ContentLength > 0 {
+			runUsage.bytesSent.Add(req.ContentLength)
+		}
+
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = &countingReader{ReadCloser: resp.Body, count: &runUsage.bytesReceived}
+		return resp, nil
+	})
+}
+
+// countingReader adds the bytes read through it to count
+type countingReader struct {
+	io.ReadCloser
+	count *atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.count.Add(int64(n))
+	return n, err
+}
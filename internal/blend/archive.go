@@ -0,0 +1,157 @@
+package blend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultArchiveDir is where raw responses are archived when bend.archive_dir is unset
+const DefaultArchiveDir = "./staging/raw"
+
+// archiveMetaExt marks the metadata file written next to each archived body
+const archiveMetaExt = ".meta.json"
+
+// ArchivedResponse describes one raw API response stored on disk. The body is
+// kept exactly as received (still compressed) so future parsers can reprocess it.
+type ArchivedResponse struct {
+	Method          string    `json:"method"`
+	URL             string    `json:"url"`
+	Path            string    `json:"path"`
+	Status          int       `json:"status"`
+	ContentType     string    `json:"content_type,omitempty"`
+	ContentEncoding string    `json:"content_encoding,omitempty"`
+	FetchedAt       time.Time `json:"fetched_at"`
+	BodyFile        string    `json:"body_file"` // Relative to the metadata file
+
+	metaPath string
+}
+
+// Body reads and decompresses the archived response body
+func (a *ArchivedResponse) Body() ([]byte, error) {
+	raw, err := os.ReadFile(filepath.Join(filepath.Dir(a.metaPath), a.BodyFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archived body: %w", err)
+	}
+	return decompressBody(raw, a.ContentEncoding)
+}
+
+// ArchiveMiddleware stores the raw body of every API response under dir.
+// Auth endpoints are skipped so tokens never reach the archive.
+func ArchiveMiddleware(dir string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err != nil || strings.HasPrefix(req.URL.Path, "/api/v1/auth/") {
+				return resp, err
+			}
+
+			raw, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read response body: %w", err)
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(raw))
+
+			if err := archiveResponse(dir, req, resp, raw); err != nil {
+				// Archiving is best effort; never fail the request over it
+				fmt.Fprintf(os.Stderr, "⚠️  Failed to archive response: %v\n", err)
+			}
+			return resp, nil
+		})
+	}
+}
+
+// archiveResponse writes the body and its metadata into a per-day directory
+func archiveResponse(dir string, req *http.Request, resp *http.Response, raw []byte) error {
+	now := time.Now()
+	dayDir := filepath.Join(dir, now.Format("2006-01-02"))
+	if err := os.MkdirAll(dayDir, 0700); err != nil {
+		return err
+	}
+
+	slug := strings.Trim(strings.NewReplacer("/", "_", ".", "_").Replace(req.URL.Path), "_")
+	base := fmt.Sprintf("%s-%s-%s", now.Format("150405.000000000"), strings.ToLower(req.Method), slug)
+
+	meta := ArchivedResponse{
+		Method:          req.Method,
+		URL:             req.URL.String(),
+		Path:            req.URL.Path,
+		Status:          resp.StatusCode,
+		ContentType:     resp.Header.Get("Content-Type"),
+		ContentEncoding: resp.Header.Get("Content-Encoding"),
+		FetchedAt:       now,
+		BodyFile:        base + ".body",
+	}
+
+	if err := os.WriteFile(filepath.Join(dayDir, meta.BodyFile), raw, 0600); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dayDir, base+archiveMetaExt), data, 0600)
+}
+
+// ReadArchive lists archived responses fetched at or after since, oldest first
+func ReadArchive(dir string, since time.Time) ([]*ArchivedResponse, error) {
+	var responses []*ArchivedResponse
+
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, archiveMetaExt) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		var meta ArchivedResponse
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if meta.FetchedAt.Before(since) {
+			return nil
+		}
+		meta.metaPath = path
+		responses = append(responses, &meta)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].FetchedAt.Before(responses[j].FetchedAt)
+	})
+	return responses, nil
+}
+
+// decompressBody undoes the response's Content-Encoding
+func decompressBody(raw []byte, contentEncoding string) ([]byte, error) {
+	if !strings.Contains(contentEncoding, "gzip") && !strings.Contains(contentEncoding, "br") {
+		return raw, nil
+	}
+
+	reader, err := createDecompressionReader(io.NopCloser(bytes.NewReader(raw)), contentEncoding)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archived body: %w", err)
+	}
+	return body, nil
+}
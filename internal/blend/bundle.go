@@ -0,0 +1,162 @@
+package blend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/redact"
+	"github.com/quickkly/fintrack/internal/safefile"
+)
+
+// DebugBundle captures every API request and response of a run, sanitized,
+// into a directory that can be attached to a bug report
+type DebugBundle struct {
+	Dir string
+
+	mu  sync.Mutex
+	seq int
+}
+
+// BundleExchange is one captured request/response pair. Secret headers,
+// query parameters and JSON fields are masked; compressed bodies are stored
+// decompressed.
+type BundleExchange struct {
+	Seq             int         `json:"seq"`
+	StartedAt       time.Time   `json:"started_at"`
+	DurationMS      float64     `json:"duration_ms"`
+	Method          string      `json:"method"`
+	URL             string      `json:"url"`
+	RequestHeaders  http.Header `json:"request_headers"`
+	RequestBody     interface{} `json:"request_body,omitempty"`
+	Status          int         `json:"status,omitempty"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	ResponseBody    interface{} `json:"response_body,omitempty"`
+	Error           string      `json:"error,omitempty"` // Transport error, no response
+}
+
+// NewDebugBundle creates dir and its requests/ directory
+func NewDebugBundle(dir string) (*DebugBundle, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "requests"), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create debug bundle: %w", err)
+	}
+	return &DebugBundle{Dir: dir}, nil
+}
+
+// Requests returns how many exchanges have been captured
+func (b *DebugBundle) Requests() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.seq
+}
+
+// Middleware captures each exchange to requests/<seq>-<method>-<path>.json
+func (b *DebugBundle) Middleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		exchange := &BundleExchange{
+			Seq:            b.next(),
+			StartedAt:      time.Now(),
+			Method:         req.Method,
+			URL:            redactURL(req),
+			RequestHeaders: redact.Headers(req.Header),
+		}
+		if req.GetBody != nil {
+			if body, err := req.GetBody(); err == nil {
+				raw, _ := io.ReadAll(body)
+				body.Close()
+				exchange.RequestBody = bundleBody(raw)
+			}
+		}
+
+		resp, err := next.RoundTrip(req)
+		exchange.DurationMS = float64(time.Since(exchange.StartedAt).Microseconds()) / 1000
+		name := exchangeFileName(exchange.Seq, req)
+		if err != nil {
+			exchange.Error = err.Error()
+			b.write(name, exchange)
+			return resp, err
+		}
+
+		// Read the body for the bundle and hand the client an identical copy
+		raw, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(raw))
+
+		exchange.Status = resp.StatusCode
+		exchange.ResponseHeaders = redact.Headers(resp.Header)
+		if readErr != nil {
+			exchange.Error = fmt.Sprintf("failed to read response body: %v", readErr)
+		} else if body, err := decompressBody(raw, resp.Header.Get("Content-Encoding")); err == nil {
+			exchange.ResponseBody = bundleBody(body)
+		} else {
+			exchange.Error = err.Error()
+		}
+		b.write(name, exchange)
+		return resp, nil
+	})
+}
+
+// next returns the next exchange's sequence number
+func (b *DebugBundle) next() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	return b.seq
+}
+
+// write saves an exchange. Capturing must never break the request, so
+// failures are only reported on stderr.
+func (b *DebugBundle) write(name string, exchange *BundleExchange) {
+	data, err := json.MarshalIndent(exchange, "", "  ")
+	if err == nil {
+		err = safefile.Write(filepath.Join(b.Dir, "requests", name), data, 0600)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  failed to capture request %d for the debug bundle: %v\n", exchange.Seq, err)
+	}
+}
+
+// exchangeFileName names an exchange's file so a directory listing reads as
+// the run's request log, e.g. 0003-GET-api-v1-aa-data.json
+func exchangeFileName(seq int, req *http.Request) string {
+	path := strings.Trim(NormalizePath(req.URL.Path), "/")
+	path = strings.NewReplacer("/", "-", ":", "").Replace(path)
+	if len(path) > 80 {
+		path = path[:80]
+	}
+	return fmt.Sprintf("%04d-%s-%s.json", seq, req.Method, path)
+}
+
+// redactURL returns the request URL with secret query parameters masked
+func redactURL(req *http.Request) string {
+	u := *req.URL
+	query := u.Query()
+	for name, values := range query {
+		for i := range values {
+			values[i] = redact.Value(name, values[i])
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// bundleBody keeps a JSON body as JSON, masked, and anything else as text
+func bundleBody(body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	if json.Valid(body) {
+		return json.RawMessage(redact.JSON(body))
+	}
+	if !isTextContent(body) {
+		return fmt.Sprintf("[%d bytes of binary content]", len(body))
+	}
+	return string(body)
+}
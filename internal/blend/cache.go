@@ -0,0 +1,155 @@
+package blend
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheablePaths are the idempotent GET endpoints worth caching between runs
+var cacheablePaths = map[string]bool{
+	"/api/v2/users/me": true,
+	"/api/v1/aa/data":  true,
+}
+
+// cachedResponse is an HTTP response stored in the on-disk cache
+type cachedResponse struct {
+	URL      string      `json:"url"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"` // As received, possibly compressed
+	StoredAt time.Time   `json:"stored_at"`
+}
+
+// CacheMiddleware serves repeated GETs of cacheable endpoints from dir.
+// Entries younger than ttl are returned without a request; older entries
+// with an ETag are revalidated with If-None-Match. Requests sent with
+// "Cache-Control: no-cache" always revalidate.
+func CacheMiddleware(dir string, ttl time.Duration) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet || !cacheablePaths[req.URL.Path] {
+				return next.RoundTrip(req)
+			}
+
+			path := cachePath(dir, req.URL.String(), cacheIdentity(req))
+			cached := loadCachedResponse(path)
+			noCache := req.Header.Get("Cache-Control") == "no-cache"
+
+			if cached != nil && !noCache && time.Since(cached.StoredAt) < ttl {
+				return cached.response(req), nil
+			}
+
+			if etag := cached.etag(); etag != "" {
+				req = req.Clone(req.Context())
+				req.Header.Set("If-None-Match", etag)
+			}
+
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+
+			if resp.StatusCode == http.StatusNotModified && cached != nil {
+				resp.Body.Close()
+				cached.StoredAt = time.Now()
+				saveCachedResponse(path, cached)
+				return cached.response(req), nil
+			}
+
+			if resp.StatusCode != http.StatusOK {
+				return resp, nil
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read response body: %w", err)
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+
+			// Replaying cookies would overwrite the session's newer ones
+			header := resp.Header.Clone()
+			header.Del("Set-Cookie")
+
+			saveCachedResponse(path, &cachedResponse{
+				URL:      req.URL.String(),
+				Status:   resp.StatusCode,
+				Header:   header,
+				Body:     body,
+				StoredAt: time.Now(),
+			})
+			return resp, nil
+		})
+	}
+}
+
+// etag returns the cached entity tag, if any
+func (c *cachedResponse) etag() string {
+	if c == nil {
+		return ""
+	}
+	return c.Header.Get("ETag")
+}
+
+// response rebuilds an http.Response from the cache entry
+func (c *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.Status, http.StatusText(c.Status)),
+		StatusCode:    c.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        c.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+// cacheIdentity is the credentials a request is sent with. The cached
+// endpoints answer per user, so entries are keyed on them too: after logging
+// in to another account, or with another session, the previous user's
+// responses are never served.
+func cacheIdentity(req *http.Request) string {
+	return req.Header.Get("Authorization") + "\n" + req.Header.Get("Cookie")
+}
+
+// cachePath returns the cache file for a URL requested with identity
+func cachePath(dir, url, identity string) string {
+	sum := sha256.Sum256([]byte(url + "\n" + identity))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// loadCachedResponse reads a cache entry, returning nil if missing or unreadable
+func loadCachedResponse(path string) *cachedResponse {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil
+	}
+	return &cached
+}
+
+// saveCachedResponse writes a cache entry. The cache is best effort, so
+// failures are ignored and the next run simply refetches.
+func saveCachedResponse(path string, cached *cachedResponse) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,253 @@
+package blend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/safefile"
+)
+
+// Breaker is a circuit breaker per API endpoint. After threshold consecutive
+// failures (network errors, 429s and 5xx responses) an endpoint's circuit
+// opens: its requests fail at once, without reaching Bend, until the
+// cool-down has passed. Then one request is let through; success closes the
+// circuit, another failure opens it again.
+//
+// The state is kept in a file so that syncs started by cron honour a circuit
+// opened by the previous run.
+type Breaker struct {
+	path      string
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	endpoints map[string]*Circuit
+	now       func() time.Time
+}
+
+// Circuit is the state of one endpoint's circuit
+type Circuit struct {
+	Endpoint  string     `json:"endpoint"` // Method and path, IDs replaced
+	Failures  int        `json:"failures"` // Consecutive failures
+	LastError string     `json:"last_error,omitempty"`
+	OpenUntil *time.Time `json:"open_until,omitempty"` // Set while the circuit is open
+	Rejected  int        `json:"rejected,omitempty"`   // Requests failed fast while open
+}
+
+// Open reports whether the circuit rejects requests at now
+func (c Circuit) Open(now time.Time) bool {
+	return c.OpenUntil != nil && now.Before(*c.OpenUntil)
+}
+
+// CircuitOpenError is returned for a request to an endpoint whose circuit is open
+type CircuitOpenError struct {
+	Endpoint string
+	Until    time.Time
+	Failures int
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open for %s after %d consecutive failure(s); not retrying before %s",
+		e.Endpoint, e.Failures, e.Until.Local().Format("15:04:05"))
+}
+
+// IsCircuitOpen reports whether err is a request rejected by an open circuit
+func IsCircuitOpen(err error) bool {
+	var openErr *CircuitOpenError
+	return errors.As(err, &openErr)
+}
+
+// activeBreaker is the breaker installed by UseBreaker, if any
+var activeBreaker *Breaker
+
+// NewBreaker loads the breaker state kept in path. A threshold of zero or
+// less returns nil: the breaker is disabled.
+func NewBreaker(path string, threshold int, cooldown time.Duration) (*Breaker, error) {
+	if threshold <= 0 {
+		return nil, nil
+	}
+	b := &Breaker{
+		path:      path,
+		threshold: threshold,
+		cooldown:  cooldown,
+		endpoints: make(map[string]*Circuit),
+		now:       time.Now,
+	}
+
+	circuits, err := LoadCircuits(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range circuits {
+		b.endpoints[circuits[i].Endpoint] = &circuits[i]
+	}
+	return b, nil
+}
+
+// NewBreakerFromConfig loads the breaker configured in the bend section
+func NewBreakerFromConfig(cfg *config.Config) (*Breaker, error) {
+	return NewBreaker(cfg.Bend.CircuitFile, cfg.Bend.CircuitThreshold, cfg.Bend.CircuitCooldown)
+}
+
+// UseBreaker installs b on every client created from now on and makes its
+// state visible through ActiveCircuits. A nil breaker does nothing.
+func UseBreaker(b *Breaker) {
+	if b == nil {
+		return
+	}
+	activeBreaker = b
+	RegisterMiddleware(b.Middleware)
+}
+
+// StartBreaker loads the configured breaker and installs it with UseBreaker.
+// The returned function saves its state; call it when the run ends. It does
+// nothing when the breaker is disabled.
+func StartBreaker(cfg *config.Config) (func() error, error) {
+	b, err := NewBreakerFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return func() error { return nil }, nil
+	}
+	UseBreaker(b)
+	return b.Save, nil
+}
+
+// ActiveCircuits returns the circuits of the breaker installed with
+// UseBreaker, nil when there is none
+func ActiveCircuits() []Circuit {
+	if activeBreaker == nil {
+		return nil
+	}
+	return activeBreaker.Circuits()
+}
+
+// Middleware fails requests to open circuits and records every outcome
+func (b *Breaker) Middleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		endpoint := req.Method + " " + NormalizePath(req.URL.Path)
+		if err := b.allow(endpoint); err != nil {
+			return nil, err
+		}
+
+		resp, err := next.RoundTrip(req)
+		switch {
+		case err != nil:
+			b.record(endpoint, err.Error())
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			b.record(endpoint, resp.Status)
+		default:
+			b.record(endpoint, "")
+		}
+		return resp, err
+	})
+}
+
+// allow returns an error while endpoint's circuit is open
+func (b *Breaker) allow(endpoint string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.endpoints[endpoint]
+	if !ok || c.OpenUntil == nil {
+		return nil
+	}
+	now := b.now()
+	if c.Open(now) {
+		c.Rejected++
+		return &CircuitOpenError{Endpoint: endpoint, Until: *c.OpenUntil, Failures: c.Failures}
+	}
+	// The cool-down is over: this request probes the endpoint while the
+	// others keep failing fast until it returns
+	until := now.Add(b.cooldown)
+	c.OpenUntil = &until
+	return nil
+}
+
+// record notes a request's outcome, failure being its error ("" for success)
+func (b *Breaker) record(endpoint, failure string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.endpoints[endpoint]
+	if failure == "" {
+		if ok {
+			delete(b.endpoints, endpoint)
+		}
+		return
+	}
+	if !ok {
+		c = &Circuit{Endpoint: endpoint}
+		b.endpoints[endpoint] = c
+	}
+	c.Failures++
+	c.LastError = failure
+	// A failed probe after the cool-down opens the circuit again at once
+	if c.Failures >= b.threshold {
+		until := b.now().Add(b.cooldown)
+		c.OpenUntil = &until
+	}
+}
+
+// Circuits returns every endpoint with failures, sorted by endpoint
+func (b *Breaker) Circuits() []Circuit {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	circuits := make([]Circuit, 0, len(b.endpoints))
+	for _, c := range b.endpoints {
+		circuits = append(circuits, *c)
+	}
+	sort.Slice(circuits, func(i, j int) bool { return circuits[i].Endpoint < circuits[j].Endpoint })
+	return circuits
+}
+
+// Save writes the breaker state for the next run
+func (b *Breaker) Save() error {
+	circuits := b.Circuits()
+	if len(circuits) == 0 {
+		if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear circuit state: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(circuits, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal circuit state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+		return fmt.Errorf("failed to create circuit state directory: %w", err)
+	}
+	if err := safefile.Write(b.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write circuit state: %w", err)
+	}
+	return nil
+}
+
+// LoadCircuits reads the circuit state saved in path; a missing file means
+// every circuit is closed
+func LoadCircuits(path string) ([]Circuit, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read circuit state: %w", err)
+	}
+
+	var circuits []Circuit
+	if err := json.Unmarshal(data, &circuits); err != nil {
+		return nil, fmt.Errorf("failed to parse circuit state %s: %w", path, err)
+	}
+	return circuits, nil
+}
@@ -4,18 +4,26 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/profile"
+	"github.com/quickkly/fintrack/internal/redact"
 
 	"github.com/andybalholm/brotli"
 )
 
+// DefaultPageSize is the number of transactions requested per page when not configured
+const DefaultPageSize = 50
+
 // Client represents the Bend client
 type Client struct {
 	httpClient     *http.Client
@@ -26,8 +34,43 @@ type Client struct {
 	deviceType     string
 	deviceLocation string
 	enableLogging  bool
+	strictDecode   bool
+	bypassCache    bool
+	pageSize       int
+	maxPages       int
+	progress       ProgressFunc
+	userAgent      string
+	origin         string
+	headers        map[string]string // Extra headers from bend.headers, see setCustomHeaders
+	transport      http.RoundTripper // Base transport (nil uses http.DefaultTransport)
+	middlewares    []Middleware      // Interceptors applied around transport, see Use
+
+	// sessionMu guards the session cookie, which responses update, so one
+	// client can serve concurrent fetches
+	sessionMu sync.Mutex
+}
+
+// FetchProgress describes how far a paginated fetch has got
+type FetchProgress struct {
+	Page    int           // Pages fetched so far
+	Fetched int           // Transactions fetched so far
+	Total   int           // Total reported by the API (0 if unknown)
+	Elapsed time.Duration // Time since the first page was requested
+}
+
+// ETA estimates the time remaining from the rate so far. It returns 0 when
+// the total is unknown or already reached.
+func (p FetchProgress) ETA() time.Duration {
+	if p.Total <= 0 || p.Fetched <= 0 || p.Fetched >= p.Total {
+		return 0
+	}
+	perTxn := p.Elapsed / time.Duration(p.Fetched)
+	return perTxn * time.Duration(p.Total-p.Fetched)
 }
 
+// ProgressFunc receives progress updates after each page is fetched
+type ProgressFunc func(FetchProgress)
+
 // NewClient creates a new Bend financial client
 func NewClient(cfg *config.Config) *Client {
 	deviceHash := cfg.Bend.DeviceHash
@@ -36,7 +79,7 @@ func NewClient(cfg *config.Config) *Client {
 		deviceHash = GenerateDeviceHash()
 	}
 
-	return &Client{
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: cfg.Bend.Timeout,
 		},
@@ -46,7 +89,31 @@ func NewClient(cfg *config.Config) *Client {
 		deviceType:     cfg.Bend.DeviceType,
 		deviceLocation: cfg.Bend.DeviceLocation,
 		enableLogging:  false, // Default to false, can be enabled via SetLogging
+		strictDecode:   cfg.Bend.StrictDecode,
+		pageSize:       cfg.Bend.PageSize,
+		maxPages:       cfg.Bend.MaxPages,
+		userAgent:      UserAgent(cfg.Bend.UserAgent),
+		origin:         cfg.Bend.Origin,
+		headers:        cfg.Bend.Headers,
+		transport:      SharedTransport(cfg.Bend.Transport),
+	}
+
+	c.Use(globalMiddlewares...)
+
+	// The cache goes first so cache hits are not archived again
+	if cfg.Bend.Cache {
+		c.Use(CacheMiddleware(cfg.Bend.CacheDir, cfg.Bend.CacheTTL))
+	}
+	if cfg.Bend.ArchiveRaw {
+		dir := cfg.Bend.ArchiveDir
+		if dir == "" {
+			dir = DefaultArchiveDir
+		}
+		c.Use(ArchiveMiddleware(dir))
 	}
+	c.httpClient.Transport = c.chain()
+
+	return c
 }
 
 // SetSession sets the authentication session
@@ -54,6 +121,21 @@ func (c *Client) SetSession(session *Session) {
 	c.session = session
 }
 
+// UseSession sets a session loaded from disk. It refuses one issued by a
+// different Bend than the client talks to, so a production session is never
+// sent to a sandbox or the other way round, and it adopts the session's device
+// hash: the tokens are bound to the device that logged in.
+func (c *Client) UseSession(session *Session) error {
+	if session.BaseURL != "" && strings.TrimRight(session.BaseURL, "/") != strings.TrimRight(c.baseURL, "/") {
+		return fmt.Errorf("session was issued by %s, not %s; log in again for this Bend (or pick another --env/--session)", session.BaseURL, c.baseURL)
+	}
+	if session.DeviceHash != "" {
+		c.deviceHash = session.DeviceHash
+	}
+	c.session = session
+	return nil
+}
+
 // GetSession returns the current session
 func (c *Client) GetSession() *Session {
 	return c.session
@@ -144,6 +226,7 @@ func (c *Client) RefreshSession() error {
 	}
 
 	// Update session with new tokens
+	c.session.BaseURL = c.baseURL
 	c.session.AccessToken = response.Data.AccessToken
 	c.session.RefreshToken = response.Data.RefreshToken
 	c.session.TokenType = response.Data.TokenType
@@ -165,12 +248,68 @@ type TransactionFilters struct {
 	SortOrder       string    `json:"sort_order,omitempty"`       // e.g., "DESC"
 	StartDate       time.Time `json:"start_date,omitempty"`       // Start date for filtering
 	EndDate         time.Time `json:"end_date,omitempty"`         // End date for filtering
-	AccountID       string    `json:"account_id,omitempty"`       // Filter by account ID
-	CategoryID      string    `json:"category_id,omitempty"`      // Filter by category ID
-	SubcategoryID   string    `json:"subcategory_id,omitempty"`   // Filter by subcategory ID
+	AccountIDs      []string  `json:"account_ids,omitempty"`      // Filter by account IDs, any of them
+	CategoryIDs     []string  `json:"category_ids,omitempty"`     // Filter by category IDs, any of them
+	SubcategoryIDs  []string  `json:"subcategory_ids,omitempty"`  // Filter by subcategory IDs, any of them
 	IncludeCountBy  bool      `json:"include_count_by,omitempty"` // Include count_by_totals
 	IncludeDetailed bool      `json:"include_detailed,omitempty"` // Include detailed_search_summary
 	OrCategory      bool      `json:"or_category,omitempty"`      // Use OR logic for category/subcategory
+	Search          string    `json:"search,omitempty"`           // Free-text search over narration and merchant
+
+	// Transaction flag filters, see Allows
+	IncludeHidden           bool `json:"include_hidden,omitempty"`            // Keep transactions hidden in the app
+	ExcludeCashflowExcluded bool `json:"exclude_cashflow_excluded,omitempty"` // Drop transactions excluded from cash flow
+
+	// Exclusions, which Bend has no parameters for and are only applied locally
+	ExcludeCategoryIDs    []string `json:"exclude_category_ids,omitempty"`
+	ExcludeSubcategoryIDs []string `json:"exclude_subcategory_ids,omitempty"`
+}
+
+// Allows reports whether txn passes the hidden and cash flow flag filters
+// and the category filters. Bend may ignore the matching query parameters,
+// and has none for exclusions, so fetched pages and local aggregation both
+// check transactions with this.
+func (f TransactionFilters) Allows(txn *Transaction) bool {
+	if txn.IsHidden && !f.IncludeHidden {
+		return false
+	}
+	if txn.ExcludedFromCashFlow && f.ExcludeCashflowExcluded {
+		return false
+	}
+	return f.allowsCategory(txn)
+}
+
+// allowsCategory reports whether txn passes the category and subcategory
+// filters: in any of the listed categories and subcategories (either, with
+// OrCategory) and in none of the excluded ones
+func (f TransactionFilters) allowsCategory(txn *Transaction) bool {
+	var category, subcategory string
+	if txn.Category != nil {
+		if txn.Category.ID != nil {
+			category = *txn.Category.ID
+		}
+		if txn.Category.SubcategoryID != nil {
+			subcategory = *txn.Category.SubcategoryID
+		}
+	}
+
+	if slices.Contains(f.ExcludeCategoryIDs, category) || slices.Contains(f.ExcludeSubcategoryIDs, subcategory) {
+		return false
+	}
+
+	inCategory := slices.Contains(f.CategoryIDs, category)
+	inSubcategory := slices.Contains(f.SubcategoryIDs, subcategory)
+	switch {
+	case len(f.CategoryIDs) == 0 && len(f.SubcategoryIDs) == 0:
+		return true
+	case len(f.CategoryIDs) == 0:
+		return inSubcategory
+	case len(f.SubcategoryIDs) == 0:
+		return inCategory
+	case f.OrCategory:
+		return inCategory || inSubcategory
+	}
+	return inCategory && inSubcategory
 }
 
 // FetchTransactions fetches transactions for a specific user with advanced filtering
@@ -256,14 +395,19 @@ func (c *Client) buildTransactionQueryParams(filters TransactionFilters) url.Val
 	}
 
 	// Filtering parameters
-	if filters.CategoryID != "" {
-		params.Set("category_id", filters.CategoryID)
+	setList(params, "category_id", filters.CategoryIDs)
+	for _, id := range filters.AccountIDs {
+		params.Add("account_id[]", id)
+	}
+	setList(params, "subcategory_id", filters.SubcategoryIDs)
+	if filters.Search != "" {
+		params.Set("q", filters.Search)
 	}
-	if filters.AccountID != "" {
-		params.Set("account_id[]", filters.AccountID)
+	if filters.IncludeHidden {
+		params.Set("include_hidden", "true")
 	}
-	if filters.SubcategoryID != "" {
-		params.Set("subcategory_id", filters.SubcategoryID)
+	if filters.ExcludeCashflowExcluded {
+		params.Set("exclude_cash_flow_excluded", "true")
 	}
 
 	// Include parameters
@@ -283,31 +427,162 @@ func (c *Client) buildTransactionQueryParams(filters TransactionFilters) url.Val
 	return params
 }
 
-// FetchAllTransactions fetches all transactions with pagination support
+// setList sets a filter parameter: a single value as key, several as the
+// key[] array
+func setList(params url.Values, key string, values []string) {
+	if len(values) == 1 {
+		params.Set(key, values[0])
+		return
+	}
+	for _, value := range values {
+		params.Add(key+"[]", value)
+	}
+}
+
+// FetchAllTransactions fetches all transactions with pagination support.
+// A limit of 0 uses the configured page size. Pagination stops with an error
+// after the configured max pages or if the API repeats a cursor. When a page
+// after the first fails, the pages before it are returned with a *PartialError.
 func (c *Client) FetchAllTransactions(userID string, limit int) ([]Transaction, []TransactionCount, error) {
+	return c.FetchAllTransactionsWithFilters(userID, TransactionFilters{Limit: limit})
+}
+
+// FetchAllTransactionsWithFilters is FetchAllTransactions for a filtered query
+func (c *Client) FetchAllTransactionsWithFilters(userID string, filters TransactionFilters) ([]Transaction, []TransactionCount, error) {
 	var allTransactions []Transaction
 	var allCounts []TransactionCount
-	after := ""
 
-	for {
-		data, err := c.FetchTransactions(userID, limit, after)
+	err := c.IterateTransactions(userID, filters, func(page *TransactionsV3Data) error {
+		allTransactions = append(allTransactions, page.Transactions...)
+		allCounts = append(allCounts, page.Counts...)
+		return nil
+	})
+	if _, partial := AsPartial(err); err != nil && !partial {
+		return nil, nil, err
+	}
+	return allTransactions, allCounts, err
+}
+
+// ErrStopIteration can be returned by an IterateTransactions callback to stop
+// paging early without an error
+var ErrStopIteration = errors.New("stop iteration")
+
+// IterateTransactions fetches a filtered query page by page and hands each
+// page to fn as it arrives, so callers that process pages as they go (such as
+// streaming writers) never hold the whole history in memory. fn must not keep
+// the page past the call unless it copies what it needs.
+//
+// Paging follows the same rules as FetchAllTransactions: a limit of 0 uses
+// the configured page size, and it stops with an error after the configured
+// max pages or if the API repeats a cursor. A filters.After cursor resumes
+// an earlier query from there. When a page after the first
+// fails, the error is a *PartialError describing the pages already handed to
+// fn. An error from fn stops paging and is returned as is, except
+// ErrStopIteration, which stops it cleanly.
+func (c *Client) IterateTransactions(userID string, filters TransactionFilters, fn func(page *TransactionsV3Data) error) error {
+	if filters.Limit <= 0 {
+		filters.Limit = c.PageSize()
+	}
+
+	// A query resumed from a saved cursor can't be sent back to it either
+	seen := make(map[string]bool)
+	if filters.After != "" {
+		seen[filters.After] = true
+	}
+	start := time.Now()
+	fetched := 0
+	for page := 1; ; page++ {
+		if err := c.CheckPageCap(page); err != nil {
+			return err
+		}
+
+		data, err := c.FetchTransactionsWithFilters(userID, filters)
 		if err != nil {
-			return nil, nil, err
+			if page == 1 {
+				return err
+			}
+			return &PartialError{Pages: page - 1, Fetched: fetched, Cursor: filters.After, Err: err}
 		}
+		fetched += len(data.Transactions)
 
-		allTransactions = append(allTransactions, data.Transactions...)
-		if len(data.Counts) > 0 {
-			allCounts = append(allCounts, data.Counts...)
+		if err := fn(data); err != nil {
+			if errors.Is(err, ErrStopIteration) {
+				return nil
+			}
+			return err
+		}
+
+		if c.progress != nil {
+			c.progress(FetchProgress{
+				Page:    page,
+				Fetched: fetched,
+				Total:   data.Total,
+				Elapsed: time.Since(start),
+			})
 		}
 
 		// Check if there are more pages
-		if data.After == "" || len(data.Transactions) < limit {
-			break
+		if data.After == "" || len(data.Transactions) < filters.Limit {
+			return nil
 		}
-		after = data.After
+		if err := CheckCursor(seen, data.After); err != nil {
+			return err
+		}
+		filters.After = data.After
+	}
+}
+
+// PageSize returns the configured page size, defaulting to DefaultPageSize
+func (c *Client) PageSize() int {
+	if c.pageSize <= 0 {
+		return DefaultPageSize
 	}
+	return c.pageSize
+}
+
+// MaxPages returns the configured pagination safety cap (0 means unlimited)
+func (c *Client) MaxPages() int {
+	return c.maxPages
+}
 
-	return allTransactions, allCounts, nil
+// SetCacheBypass makes cached endpoints revalidate with the API on every request
+func (c *Client) SetCacheBypass(bypass bool) {
+	c.bypassCache = bypass
+}
+
+// SetProgress registers a callback invoked after each page in FetchAllTransactions
+func (c *Client) SetProgress(fn ProgressFunc) {
+	c.progress = fn
+}
+
+// SetPagination overrides the configured page size and max pages. A page
+// size of 0 keeps the configured one; a max pages of 0 removes the cap, as
+// bend.max_pages: 0 does.
+func (c *Client) SetPagination(pageSize, maxPages int) {
+	if pageSize > 0 {
+		c.pageSize = pageSize
+	}
+	if maxPages >= 0 {
+		c.maxPages = maxPages
+	}
+}
+
+// CheckPageCap returns an error once page would exceed the max pages cap
+func (c *Client) CheckPageCap(page int) error {
+	if c.maxPages > 0 && page > c.maxPages {
+		return fmt.Errorf("stopped after %d pages (--max-pages/bend.max_pages); narrow the date range or raise the limit", c.maxPages)
+	}
+	return nil
+}
+
+// CheckCursor records a pagination cursor and returns an error if the API
+// has already returned it, which would otherwise loop forever
+func CheckCursor(seen map[string]bool, cursor string) error {
+	if seen[cursor] {
+		return fmt.Errorf("API returned pagination cursor %q twice, aborting to avoid an endless loop", cursor)
+	}
+	seen[cursor] = true
+	return nil
 }
 
 // FetchTransactionsWithCurlParams creates filters matching the curl command parameters
@@ -324,10 +599,10 @@ func (c *Client) FetchTransactionsWithCurlParams(userID string, startDate, endDa
 	}
 
 	if categoryID != "" {
-		filters.CategoryID = categoryID
+		filters.CategoryIDs = []string{categoryID}
 	}
 	if subcategoryID != "" {
-		filters.SubcategoryID = subcategoryID
+		filters.SubcategoryIDs = []string{subcategoryID}
 	}
 
 	return c.FetchTransactionsWithFilters(userID, filters)
@@ -360,6 +635,37 @@ func (c *Client) GetAccounts() ([]Account, error) {
 	return response.Data.Accounts, nil
 }
 
+// TriggerAccountRefresh asks Bend to re-pull data for linked accounts.
+// An empty accountIDs slice refreshes every account on the profile.
+func (c *Client) TriggerAccountRefresh(accountIDs []string) error {
+	if c.session == nil {
+		return fmt.Errorf("no session available")
+	}
+
+	// Wait for rate limiter
+	<-c.rateLimiter.C
+
+	refreshReq := AccountRefreshRequest{
+		AccountIDs: accountIDs,
+	}
+
+	req, err := c.newRequest("POST", "/api/v1/aa/data/refresh", refreshReq)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	var response APIResponse
+	if err := c.doRequest(req, &response); err != nil {
+		return fmt.Errorf("failed to trigger account refresh: %w", err)
+	}
+
+	if response.Error != nil {
+		return fmt.Errorf("account refresh failed: %v", response.Error)
+	}
+
+	return nil
+}
+
 // InitializeFromRefreshToken initializes session from a refresh token
 func (c *Client) InitializeFromRefreshToken(refreshToken string) error {
 	// Create initial session with refresh token
@@ -370,7 +676,26 @@ func (c *Client) InitializeFromRefreshToken(refreshToken string) error {
 	return c.RefreshSession()
 }
 
-// RequestOTP requests an OTP to be sent to the given phone number
+// OTPChannels are the channels Bend can send an OTP on
+var OTPChannels = []string{"sms", "whatsapp", "voice"}
+
+// IsWrongOTP reports whether a VerifyOTP error is Bend refusing the code
+// itself, which the user can retry, rather than the request failing
+func IsWrongOTP(err error) bool {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	switch statusErr.StatusCode {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
+
+// RequestOTP requests an OTP to be sent to the given phone number over
+// channel, one of OTPChannels. Requesting it again with the same request ID
+// resends it.
 func (c *Client) RequestOTP(phone, channel string, requestID string) error {
 	// Wait for rate limiter
 	<-c.rateLimiter.C
@@ -437,24 +762,15 @@ func (c *Client) VerifyOTP(phone, otp, requestID string) (*OTPVerifyData, string
 
 	// Decode response
 	var response OTPVerifyResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, "", fmt.Errorf("failed to decode response: %w", err)
+	if err := c.decodeResponse(body, &response); err != nil {
+		return nil, "", err
 	}
 
 	if response.Error != nil {
 		return nil, "", fmt.Errorf("OTP verification failed: %v", response.Error)
 	}
 
-	// Extract marble-cookie from response cookies
-	marbleCookie := ""
-	for _, cookie := range resp.Cookies() {
-		if cookie.Name == "marble-cookie" {
-			marbleCookie = cookie.Value
-			break
-		}
-	}
-
-	return &response.Data, marbleCookie, nil
+	return &response.Data, marbleCookieOf(resp), nil
 }
 
 // newRequestWithID creates a new HTTP request with a specific request ID (for OTP flow)
@@ -486,6 +802,7 @@ func (c *Client) newRequestWithID(method, endpoint string, body interface{}, req
 
 	// Set device headers with specific request ID
 	c.setDeviceHeadersWithRequestID(req, requestID)
+	c.setCustomHeaders(req)
 
 	// Don't set authentication headers for OTP requests (no session yet)
 
@@ -512,23 +829,14 @@ func (c *Client) logRequest(req *http.Request, body []byte) {
 	fmt.Printf("Method: %s\n", req.Method)
 	fmt.Printf("URL: %s\n", req.URL.String())
 	fmt.Printf("Headers:\n")
-	for name, values := range req.Header {
+	for name, values := range redact.Headers(req.Header) {
 		for _, value := range values {
-			// Mask sensitive headers
-			if name == "Authorization" {
-				if len(value) > 10 {
-					fmt.Printf("  %s: %s...\n", name, value[:10])
-				} else {
-					fmt.Printf("  %s: [REDACTED]\n", name)
-				}
-			} else {
-				fmt.Printf("  %s: %s\n", name, value)
-			}
+			fmt.Printf("  %s: %s\n", name, value)
 		}
 	}
 
 	if len(body) > 0 {
-		fmt.Printf("Body: %s\n", string(body))
+		fmt.Printf("Body: %s\n", string(redact.JSON(body)))
 	}
 	fmt.Printf("==================\n")
 }
@@ -541,14 +849,16 @@ func (c *Client) logResponse(resp *http.Response, body []byte) {
 
 	fmt.Printf("\n=== HTTP RESPONSE ===\n")
 	fmt.Printf("Status: %s\n", resp.Status)
+	fmt.Printf("Protocol: %s\n", resp.Proto)
 	fmt.Printf("Headers:\n")
-	for name, values := range resp.Header {
+	for name, values := range redact.Headers(resp.Header) {
 		for _, value := range values {
 			fmt.Printf("  %s: %s\n", name, value)
 		}
 	}
 
 	if len(body) > 0 {
+		body = redact.JSON(body)
 		// Truncate very long responses for readability
 		if len(body) > 1000 {
 			fmt.Printf("Body (truncated): %s...\n", string(body[:1000]))
@@ -582,6 +892,7 @@ func (c *Client) newRequest(method, endpoint string, body interface{}) (*http.Re
 	// Set headers
 	c.setStandardHeaders(req)
 	c.setDeviceHeaders(req)
+	c.setCustomHeaders(req)
 	c.setAuthenticationHeaders(req)
 
 	// Log the request if logging is enabled
@@ -595,7 +906,15 @@ func (c *Client) setStandardHeaders(req *http.Request) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
-	req.Header.Set("Origin", "https://bend.example.com")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.origin != "" {
+		req.Header.Set("Origin", c.origin)
+	}
+	if c.bypassCache {
+		req.Header.Set("Cache-Control", "no-cache")
+	}
 }
 
 // setDeviceHeaders sets device-specific headers required by Bend
@@ -623,6 +942,8 @@ func (c *Client) setAuthenticationHeaders(req *http.Request) {
 	if c.session == nil {
 		return
 	}
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
 
 	// Set authorization header
 	if c.session.AccessToken != "" {
@@ -644,14 +965,23 @@ func (c *Client) setAuthenticationHeaders(req *http.Request) {
 
 // doRequest executes an HTTP request and decodes the response
 func (c *Client) doRequest(req *http.Request, v interface{}) error {
+	// Network time until the body is read; auth endpoints count as auth
+	phase := profile.API
+	if strings.HasPrefix(req.URL.Path, "/api/v1/auth/") {
+		phase = profile.Auth
+	}
+	stop := profile.Start(phase)
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		stop()
 		return fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read and process response body
 	body, err := c.readResponseBody(resp)
+	stop()
 	if err != nil {
 		return err
 	}
@@ -669,8 +999,8 @@ func (c *Client) doRequest(req *http.Request, v interface{}) error {
 
 	// Decode response if target provided
 	if v != nil {
-		if err := json.Unmarshal(body, v); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
+		if err := c.decodeResponse(body, v); err != nil {
+			return err
 		}
 	}
 
@@ -684,7 +1014,7 @@ func (c *Client) readResponseBody(resp *http.Response) ([]byte, error) {
 
 	if strings.Contains(contentEncoding, "gzip") || strings.Contains(contentEncoding, "br") {
 		var err error
-		reader, err = c.createDecompressionReader(resp.Body, contentEncoding)
+		reader, err = createDecompressionReader(resp.Body, contentEncoding)
 		if err != nil {
 			return nil, err
 		}
@@ -699,7 +1029,7 @@ func (c *Client) readResponseBody(resp *http.Response) ([]byte, error) {
 }
 
 // createDecompressionReader creates a reader for compressed content
-func (c *Client) createDecompressionReader(body io.ReadCloser, contentEncoding string) (io.Reader, error) {
+func createDecompressionReader(body io.ReadCloser, contentEncoding string) (io.Reader, error) {
 	if strings.Contains(contentEncoding, "gzip") {
 		gzReader, err := gzip.NewReader(body)
 		if err != nil {
@@ -725,10 +1055,28 @@ func (c *Client) handleErrorResponse(resp *http.Response, body []byte) error {
 	// Try to parse error as JSON for better error messages
 	var errorResp APIResponse
 	if json.Unmarshal(body, &errorResp) == nil && errorResp.Error != nil {
-		return fmt.Errorf("API request failed with status %d: %v", resp.StatusCode, errorResp.Error)
+		errorMsg = fmt.Sprintf("%v", errorResp.Error)
 	}
 
-	return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, errorMsg)
+	return &StatusError{StatusCode: resp.StatusCode, Message: errorMsg}
+}
+
+// StatusError is an API response with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// IsRejected reports whether err is the API refusing a request as invalid
+// (400 or 422), e.g. because of a query parameter it doesn't support
+func IsRejected(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) &&
+		(statusErr.StatusCode == http.StatusBadRequest || statusErr.StatusCode == http.StatusUnprocessableEntity)
 }
 
 // saveResponseCookies saves cookies from the response to the session
@@ -736,6 +1084,8 @@ func (c *Client) saveResponseCookies(resp *http.Response) {
 	if c.session == nil || len(resp.Cookies()) == 0 {
 		return
 	}
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
 
 	for _, cookie := range resp.Cookies() {
 		if cookie.Name == "marble-cookie" {
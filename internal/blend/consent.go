@@ -0,0 +1,58 @@
+package blend
+
+import (
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/config"
+)
+
+// Consent statuses under which data no longer flows
+var inactiveConsentStatuses = []string{"PAUSED", "REVOKED", "EXPIRED", "REJECTED"}
+
+// ConsentExpiry returns when the account's consent expires: the date Bend
+// reports, or accounts.settings.<uuid>.consent_expires when Bend has none.
+// The zero time means it isn't known.
+func ConsentExpiry(cfg *config.Config, account Account) time.Time {
+	if account.Consent != nil && account.Consent.ExpiresAt != nil {
+		return *account.Consent.ExpiresAt
+	}
+	if settings, ok := cfg.AccountSettingsFor(account.UUID); ok {
+		if expiry, err := settings.ConsentExpiry(); err == nil {
+			return expiry
+		}
+	}
+	return time.Time{}
+}
+
+// ConsentInactive reports whether a consent status means data no longer flows
+func ConsentInactive(status string) bool {
+	for _, inactive := range inactiveConsentStatuses {
+		if strings.EqualFold(status, inactive) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConsentDaysLeft is the number of whole days until expiry, negative once
+// the consent has expired
+func ConsentDaysLeft(expiry, now time.Time) int {
+	days := int(expiry.Sub(now).Hours() / 24)
+	if expiry.Before(now) {
+		days--
+	}
+	return days
+}
+
+// DisplayName is the account's nickname, its bank and masked number, or its UUID
+func (a *Account) DisplayName() string {
+	if a.Nickname != nil && *a.Nickname != "" {
+		return *a.Nickname
+	}
+	name := strings.TrimSpace(a.FinancialInformationProvider.Name + " " + a.MaskedAccountNumber)
+	if name == "" {
+		return a.UUID
+	}
+	return name
+}
@@ -0,0 +1,179 @@
+package blend
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/quickkly/fintrack/internal/profile"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decodeResponse decodes a response body into v. In strict mode unknown
+// fields are an error, and every field the models would drop is reported.
+// Fields repaired by tolerant decoding (see drift.go) are reported as
+// warnings.
+func (c *Client) decodeResponse(body []byte, v interface{}) error {
+	defer profile.Start(profile.Parse)()
+	defer reportDrift()
+
+	if !c.strictDecode {
+		if err := json.Unmarshal(body, v); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	// Tolerant models decode themselves, out of the decoder's sight, so their
+	// unknown fields only show up here
+	fields := UnknownFields(body, v)
+	if len(fields) == 0 {
+		if err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  Response has %d field(s) missing from the models:\n", len(fields))
+	for _, field := range fields {
+		fmt.Fprintf(os.Stderr, "  - %s\n", field)
+	}
+	return fmt.Errorf("strict decode: response has unknown fields: %s", strings.Join(fields, ", "))
+}
+
+// reportDrift prints the drift warnings recorded while decoding
+func reportDrift() {
+	warnings, dropped := TakeDriftWarnings()
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  Repaired %d malformed field(s) in a Bend response:\n", len(warnings)+dropped)
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "  - %s\n", warning)
+	}
+	if dropped > 0 {
+		fmt.Fprintf(os.Stderr, "  ... and %d more\n", dropped)
+	}
+}
+
+// SetStrictDecode makes response decoding fail on fields the models do not know
+func (c *Client) SetStrictDecode(enabled bool) {
+	c.strictDecode = enabled
+}
+
+// UnknownFields returns the dotted paths of every field in the JSON body that
+// has no matching field in v's type. Array elements appear as "[]".
+func UnknownFields(body []byte, v interface{}) []string {
+	var raw interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	collectUnknownFields(raw, reflect.TypeOf(v), "", seen)
+
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// collectUnknownFields walks raw alongside t, recording paths with no model field
+func collectUnknownFields(raw interface{}, t reflect.Type, path string, seen map[string]bool) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() == reflect.Interface {
+		return
+	}
+	// Types with custom decoding (time.Time etc.) are opaque to us, except
+	// the models that only decode tolerantly
+	if !tolerantTypes[t] && (reflect.PtrTo(t).Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType)) {
+		return
+	}
+
+	switch value := raw.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Map:
+			for key, item := range value {
+				collectUnknownFields(item, t.Elem(), joinPath(path, key), seen)
+			}
+		case reflect.Struct:
+			fields := jsonFields(t)
+			for key, item := range value {
+				field, ok := fields[strings.ToLower(key)]
+				if !ok {
+					seen[joinPath(path, key)] = true
+					continue
+				}
+				collectUnknownFields(item, field, joinPath(path, key), seen)
+			}
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return
+		}
+		for _, item := range value {
+			collectUnknownFields(item, t.Elem(), path+"[]", seen)
+		}
+	}
+}
+
+// jsonFields maps the lower-cased JSON names of t's fields to their types,
+// matching encoding/json's case-insensitive field lookup
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for key, typ := range jsonFields(embedded) {
+					if _, ok := fields[key]; !ok {
+						fields[key] = typ
+					}
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+	return fields
+}
+
+// joinPath appends key to a dotted path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,284 @@
+package blend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bend occasionally sends a number as a string, a flag as "1" or a timestamp
+// in another layout. Transaction and Account decode tolerantly: a field that
+// doesn't fit its type is converted when it can be and left empty when it
+// can't, instead of failing the whole page, and each repair is recorded as a
+// drift warning for the command to report.
+
+// maxDriftWarnings bounds the warnings kept between reports
+const maxDriftWarnings = 1000
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+
+	// tolerantTypes decode through tolerantUnmarshal. Their fields are still
+	// checked for unknown fields in strict mode.
+	tolerantTypes = map[reflect.Type]bool{
+		reflect.TypeOf(Transaction{}): true,
+		reflect.TypeOf(Account{}):     true,
+	}
+
+	// driftLayouts are the timestamp layouts accepted besides RFC 3339
+	driftLayouts = []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04:05Z07:00", "2006-01-02"}
+)
+
+// drift collects warnings until TakeDriftWarnings
+var drift struct {
+	sync.Mutex
+	warnings []string
+	dropped  int
+}
+
+// UnmarshalJSON decodes a transaction, repairing fields of the wrong type and
+// keeping unknown ones in RawExtra
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	type plain Transaction
+	warnings, err := tolerantUnmarshal(data, (*plain)(t))
+	if err != nil {
+		return err
+	}
+	recordDrift("transaction "+t.UUID, warnings)
+	t.RawExtra = extraFields(data, transactionJSONFields)
+	return nil
+}
+
+// UnmarshalJSON decodes an account, repairing fields of the wrong type and
+// keeping unknown ones in RawExtra
+func (a *Account) UnmarshalJSON(data []byte) error {
+	type plain Account
+	warnings, err := tolerantUnmarshal(data, (*plain)(a))
+	if err != nil {
+		return err
+	}
+	recordDrift("account "+a.UUID, warnings)
+	a.RawExtra = extraFields(data, accountJSONFields)
+	return nil
+}
+
+// TakeDriftWarnings returns the drift warnings recorded since the last call,
+// and how many more were dropped once the limit was reached
+func TakeDriftWarnings() ([]string, int) {
+	drift.Lock()
+	defer drift.Unlock()
+	warnings, dropped := drift.warnings, drift.dropped
+	drift.warnings, drift.dropped = nil, 0
+	return warnings, dropped
+}
+
+// recordDrift adds warnings about the named record
+func recordDrift(record string, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	drift.Lock()
+	defer drift.Unlock()
+	for _, warning := range warnings {
+		if len(drift.warnings) >= maxDriftWarnings {
+			drift.dropped++
+			continue
+		}
+		drift.warnings = append(drift.warnings, record+": "+warning)
+	}
+}
+
+// tolerantUnmarshal decodes a JSON object into v, a pointer to a struct
+// without custom decoding. Well-formed data decodes as usual; otherwise each
+// top-level field that doesn't decode into its type is repaired, and the
+// repairs are returned as warnings.
+func tolerantUnmarshal(data []byte, v interface{}) ([]string, error) {
+	err := json.Unmarshal(data, v)
+	if err == nil {
+		return nil, nil
+	}
+
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(data, &raw) != nil {
+		// Not an object at all; nothing field by field to repair
+		return nil, err
+	}
+
+	target := reflect.ValueOf(v).Elem()
+	fields := jsonFields(target.Type())
+	var warnings []string
+	for key, value := range raw {
+		typ, ok := fields[strings.ToLower(key)]
+		if !ok {
+			continue
+		}
+		repaired, warning := repairField(value, typ)
+		raw[key] = repaired
+		if warning != "" {
+			warnings = append(warnings, key+" "+warning)
+		}
+	}
+
+	sort.Strings(warnings)
+
+	fixed, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	// Start over, so nothing from the failed attempt survives
+	target.Set(reflect.Zero(target.Type()))
+	if err := json.Unmarshal(fixed, v); err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+// repairField returns value converted to fit typ and a warning describing the
+// repair, or an empty warning when value already fits
+func repairField(value json.RawMessage, typ reflect.Type) (json.RawMessage, string) {
+	if json.Unmarshal(value, reflect.New(typ).Interface()) == nil {
+		return value, ""
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	var decoded interface{}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	if decoder.Decode(&decoded) != nil {
+		return json.RawMessage("null"), fmt.Sprintf("%s is not valid JSON, left empty", value)
+	}
+
+	var repaired interface{}
+	ok := false
+	switch {
+	case typ == timeType:
+		repaired, ok = driftTime(decoded)
+	case typ.Kind() == reflect.Bool:
+		repaired, ok = driftBool(decoded)
+	case typ.Kind() == reflect.Float64 || typ.Kind() == reflect.Float32:
+		repaired, ok = driftNumber(decoded, false)
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Uint64:
+		repaired, ok = driftNumber(decoded, true)
+	case typ.Kind() == reflect.String:
+		switch decoded.(type) {
+		case json.Number, bool:
+			repaired, ok = fmt.Sprint(decoded), true
+		}
+	}
+
+	if !ok {
+		return json.RawMessage("null"), fmt.Sprintf("%s doesn't fit %s, left empty", value, typeName(typ))
+	}
+	fixed, err := json.Marshal(repaired)
+	if err != nil {
+		return json.RawMessage("null"), fmt.Sprintf("%s doesn't fit %s, left empty", value, typeName(typ))
+	}
+	// An empty string standing for "no value" isn't worth a warning
+	if s, isString := decoded.(string); isString && strings.TrimSpace(s) == "" {
+		return fixed, ""
+	}
+	return fixed, fmt.Sprintf("%s read as %s", value, fixed)
+}
+
+// driftNumber reads a number sent as a string ("1,234.50") or a flag
+func driftNumber(value interface{}, integer bool) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		s := strings.ReplaceAll(strings.TrimSpace(v), ",", "")
+		if s == "" {
+			return nil, true
+		}
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, false
+		}
+		if integer {
+			return math.Round(n), true
+		}
+		return n, true
+	case json.Number:
+		// Only an integer field can reject a number: round it
+		n, err := v.Float64()
+		return math.Round(n), err == nil && integer
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return nil, false
+}
+
+// driftBool reads a flag sent as a string ("true", "1", "yes") or a number
+func driftBool(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "1", "yes", "y", "t":
+			return true, true
+		case "false", "0", "no", "n", "f", "":
+			return false, true
+		}
+	case json.Number:
+		n, err := v.Float64()
+		return n != 0, err == nil
+	}
+	return nil, false
+}
+
+// driftTime reads a timestamp in another layout, or as Unix seconds or
+// milliseconds
+func driftTime(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return nil, true
+		}
+		for _, layout := range driftLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, true
+			}
+		}
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return unixTime(n), true
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return unixTime(n), true
+		}
+	}
+	return nil, false
+}
+
+// unixTime reads Unix seconds, or milliseconds when too large to be seconds
+func unixTime(n int64) time.Time {
+	if n > 1e11 {
+		return time.UnixMilli(n).UTC()
+	}
+	return time.Unix(n, 0).UTC()
+}
+
+// typeName names a field type in warnings
+func typeName(typ reflect.Type) string {
+	switch {
+	case typ == timeType:
+		return "a timestamp"
+	case typ.Kind() == reflect.Bool:
+		return "a flag"
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Float64:
+		return "a number"
+	case typ.Kind() == reflect.String:
+		return "a string"
+	}
+	return "the model (" + typ.String() + ")"
+}
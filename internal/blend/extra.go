@@ -0,0 +1,113 @@
+package blend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Fields Bend adds after a release would otherwise be dropped on decode and
+// lost for good once the transaction is stored. Transaction and Account keep
+// them in RawExtra instead and write them back out next to the known fields,
+// so the store, staging files and JSON exports carry them until the models
+// catch up.
+
+var (
+	transactionJSONFields = jsonFields(reflect.TypeOf(Transaction{}))
+	accountJSONFields     = jsonFields(reflect.TypeOf(Account{}))
+)
+
+// MarshalJSON encodes a transaction with its RawExtra fields
+func (t Transaction) MarshalJSON() ([]byte, error) {
+	type plain Transaction
+	data, err := json.Marshal(plain(t))
+	if err != nil {
+		return nil, err
+	}
+	return MergeJSONFields(data, t.RawExtra)
+}
+
+// MarshalJSON encodes an account with its RawExtra fields
+func (a Account) MarshalJSON() ([]byte, error) {
+	type plain Account
+	data, err := json.Marshal(plain(a))
+	if err != nil {
+		return nil, err
+	}
+	return MergeJSONFields(data, a.RawExtra)
+}
+
+// ExtraFieldNames returns the names of the RawExtra fields in sorted order
+func ExtraFieldNames(extra map[string]json.RawMessage) []string {
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MergeJSONFields adds fields to the JSON object data, in key order. Fields
+// the object already has are left as they are. Types that embed Transaction
+// or Account use it to add their own fields, since the embedded MarshalJSON
+// would otherwise encode only the embedded value.
+func MergeJSONFields(data []byte, fields map[string]json.RawMessage) ([]byte, error) {
+	if len(fields) == 0 {
+		return data, nil
+	}
+
+	var existing map[string]json.RawMessage
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return nil, fmt.Errorf("failed to merge JSON fields: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimSuffix(bytes.TrimSpace(data), []byte("}")))
+	empty := len(existing) == 0
+	for _, name := range ExtraFieldNames(fields) {
+		if _, ok := existing[name]; ok {
+			continue
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value := fields[name]
+		if len(value) == 0 {
+			value = json.RawMessage("null")
+		}
+		if !empty {
+			buf.WriteByte(',')
+		}
+		empty = false
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// extraFields returns the members of the JSON object data that known, a
+// model's lower-cased field names, doesn't have
+func extraFields(data []byte, known map[string]reflect.Type) map[string]json.RawMessage {
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(data, &raw) != nil {
+		return nil
+	}
+
+	var extra map[string]json.RawMessage
+	for key, value := range raw {
+		if _, ok := known[strings.ToLower(key)]; ok {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]json.RawMessage)
+		}
+		extra[key] = value
+	}
+	return extra
+}
@@ -0,0 +1,51 @@
+package blend
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fingerprint is a stable identity for the transaction, for systems that
+// dedupe fintrack's output: 32 hex digits of the SHA-256 of its UUID or, for
+// a transaction without one, of its account, time (in UTC), signed amount,
+// currency, narration and reference. It doesn't depend on the timezone,
+// rounding or the command that wrote the transaction, so every copy of a
+// transaction has the same fingerprint.
+func (t *Transaction) Fingerprint() string {
+	if t.UUID == "" {
+		return t.ContentFingerprint()
+	}
+	return fingerprintOf("uuid:" + t.UUID)
+}
+
+// ContentFingerprint is the fingerprint of the transaction's content alone,
+// ignoring its UUID, so copies of one bank transaction that Bend gave
+// different UUIDs share it
+func (t *Transaction) ContentFingerprint() string {
+	return fingerprintOf("content:" + strings.Join([]string{
+		t.AccountID,
+		t.TxnTimestamp.UTC().Format(time.RFC3339Nano),
+		strconv.FormatFloat(t.SignedAmount(), 'f', -1, 64),
+		strings.ToUpper(t.Currency),
+		t.Narration,
+		t.Reference,
+	}, "|"))
+}
+
+// fingerprintOf is 32 hex digits of the SHA-256 of key
+func fingerprintOf(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Fingerprints returns each transaction's Fingerprint, in order
+func Fingerprints(transactions []Transaction) []string {
+	fingerprints := make([]string, len(transactions))
+	for i := range transactions {
+		fingerprints[i] = transactions[i].Fingerprint()
+	}
+	return fingerprints
+}
@@ -0,0 +1,48 @@
+package blend
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Version is the fintrack version sent in the User-Agent, set by the
+// command from the build's -X main.Version
+var Version = "dev"
+
+// reservedHeaders carry credentials or framing and can't be set in bend.headers
+var reservedHeaders = map[string]bool{
+	"Authorization":  true,
+	"Cookie":         true,
+	"Host":           true,
+	"Content-Length": true,
+}
+
+// UserAgent expands the {version} placeholder of a configured User-Agent
+func UserAgent(template string) string {
+	return strings.ReplaceAll(template, "{version}", Version)
+}
+
+// ValidateHeaders checks the extra headers configured in bend.headers
+func ValidateHeaders(headers map[string]string) error {
+	for name, value := range headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return fmt.Errorf("bend.headers: invalid header name '%s'", name)
+		}
+		if reservedHeaders[http.CanonicalHeaderKey(name)] {
+			return fmt.Errorf("bend.headers: %s is set by fintrack and can't be overridden", http.CanonicalHeaderKey(name))
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("bend.headers: value of %s contains a line break", name)
+		}
+	}
+	return nil
+}
+
+// setCustomHeaders applies bend.headers last, so they win over every
+// built-in header, including the app headers sent while logging in
+func (c *Client) setCustomHeaders(req *http.Request) {
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+}
@@ -1,7 +1,12 @@
 package blend
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/quickkly/fintrack/internal/money"
 )
 
 // =============================================================================
@@ -61,6 +66,57 @@ type Transaction struct {
 	SplitType                *string           `json:"split_type"`
 	RemainingAmount          *float64          `json:"remaining_amount"`
 	ParentTransactionID      *string           `json:"parent_transaction_id"`
+
+	// Fields Bend sent that the model doesn't know yet, written back out with
+	// the rest so they survive the store and JSON exports (see extra.go)
+	RawExtra map[string]json.RawMessage `json:"-"`
+}
+
+// Transaction types
+const (
+	TransactionTypeIncoming = "INCOMING"
+	TransactionTypeOutgoing = "OUTGOING"
+)
+
+// SignedAmount returns the amount as it affects the account balance:
+// positive for incoming money, negative for outgoing
+func (t *Transaction) SignedAmount() float64 {
+	if t.Type == TransactionTypeOutgoing {
+		return -t.Amount
+	}
+	return t.Amount
+}
+
+// Money returns the (unsigned) amount in the transaction's currency
+func (t *Transaction) Money(mode money.RoundingMode) money.Money {
+	return money.FromFloatIn(t.Amount, t.Currency, mode)
+}
+
+// SignedMoney returns SignedAmount in the transaction's currency
+func (t *Transaction) SignedMoney(mode money.RoundingMode) money.Money {
+	return money.FromFloatIn(t.SignedAmount(), t.Currency, mode)
+}
+
+// MatchesSearch reports whether the narration or merchant name contains q,
+// ignoring case, as Bend's free-text search does
+func (t *Transaction) MatchesSearch(q string) bool {
+	q = strings.ToLower(q)
+	if strings.Contains(strings.ToLower(t.Narration), q) {
+		return true
+	}
+	return t.Merchant != nil && t.Merchant.Name != nil && strings.Contains(strings.ToLower(*t.Merchant.Name), q)
+}
+
+// ParseTransactionType accepts "incoming" or "outgoing" in any case
+func ParseTransactionType(s string) (string, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case TransactionTypeIncoming:
+		return TransactionTypeIncoming, nil
+	case TransactionTypeOutgoing:
+		return TransactionTypeOutgoing, nil
+	default:
+		return "", fmt.Errorf("invalid transaction type %q (use incoming or outgoing)", s)
+	}
 }
 
 // TransactionCategory represents transaction category information
@@ -127,6 +183,19 @@ type Account struct {
 
 	// Provider information
 	FinancialInformationProvider FinancialInformationProvider `json:"financial_information_provider"`
+
+	// Account aggregator consent the data flows under; nil when Bend leaves it out
+	Consent *AccountConsent `json:"consent,omitempty"`
+
+	// Fields Bend sent that the model doesn't know yet (see extra.go)
+	RawExtra map[string]json.RawMessage `json:"-"`
+}
+
+// AccountConsent is the account aggregator consent behind a linked account
+type AccountConsent struct {
+	ID        string     `json:"consent_id"`
+	Status    string     `json:"status"` // e.g., "ACTIVE", "PAUSED", "REVOKED", "EXPIRED"
+	ExpiresAt *time.Time `json:"expires_at"`
 }
 
 // FinancialInformationProvider represents bank details from /api/v1/aa/data
@@ -202,6 +271,7 @@ type Session struct {
 	TokenType    string    `json:"token_type"`
 	MarbleCookie string    `json:"marble_cookie"`
 	DeviceHash   string    `json:"device_hash"`
+	BaseURL      string    `json:"base_url,omitempty"` // Bend the session was issued by
 }
 
 // =============================================================================
@@ -249,7 +319,7 @@ type RefreshResponse struct {
 // OTPRequest represents OTP generation request
 type OTPRequest struct {
 	Phone   string `json:"phone"`
-	Channel string `json:"channel"` // "sms" or "whatsapp"
+	Channel string `json:"channel"` // "sms", "whatsapp" or "voice"
 }
 
 // OTPVerifyRequest represents OTP verification request
@@ -313,6 +383,11 @@ type AAData struct {
 	Accounts []Account `json:"accounts"`
 }
 
+// AccountRefreshRequest represents the /api/v1/aa/data/refresh request body
+type AccountRefreshRequest struct {
+	AccountIDs []string `json:"account_ids,omitempty"` // Empty refreshes all accounts
+}
+
 // =============================================================================
 // USER API RESPONSE MODELS
 // =============================================================================
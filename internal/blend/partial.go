@@ -0,0 +1,33 @@
+package blend
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PartialError is returned with the transactions of a paginated fetch that
+// failed after at least one page succeeded, so callers can keep what was
+// fetched instead of discarding it
+type PartialError struct {
+	Pages   int    // Pages fetched successfully
+	Fetched int    // Transactions in those pages
+	Cursor  string // Pagination cursor of the page that failed
+	Err     error  // Why the next page failed
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("fetch stopped after %d page(s) (%d transaction(s)) at cursor %q: %v", e.Pages, e.Fetched, e.Cursor, e.Err)
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// AsPartial returns the PartialError in err's chain, if any
+func AsPartial(err error) (*PartialError, bool) {
+	var partial *PartialError
+	if errors.As(err, &partial) {
+		return partial, true
+	}
+	return nil, false
+}
@@ -0,0 +1,118 @@
+package blend
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// PasswordLoginRequest is the body of an email and password login
+type PasswordLoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// TwoFactorRequest is the body completing a login that asked for a second
+// factor
+type TwoFactorRequest struct {
+	ChallengeID string `json:"challenge_id"`
+	OTP         string `json:"otp"`
+}
+
+// PasswordLoginData is the data section of a password login response: the
+// tokens, or a two-factor challenge when the account has 2FA enabled
+type PasswordLoginData struct {
+	OTPVerifyData
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeID       string `json:"challenge_id,omitempty"`
+	TwoFactorChannel  string `json:"two_factor_channel,omitempty"` // Where the code was sent, e.g. "sms" or "email"
+}
+
+// PasswordLoginResponse represents a password login response
+type PasswordLoginResponse struct {
+	Meta  APIResponseMeta   `json:"meta"`
+	Data  PasswordLoginData `json:"data"`
+	Error interface{}       `json:"error"`
+}
+
+// PasswordLogin logs in with an email and password. When the account has
+// two-factor authentication the returned data has TwoFactorRequired set and
+// no tokens; pass its ChallengeID to VerifyTwoFactor. Like the OTP flow it
+// sends no session and the same requestID and device hash must be used for
+// both steps.
+func (c *Client) PasswordLogin(email, password, requestID string) (*PasswordLoginData, string, error) {
+	var response PasswordLoginResponse
+	marbleCookie, err := c.postAuth("/api/v1/auth/login", PasswordLoginRequest{Email: email, Password: password}, requestID, &response)
+	if err != nil {
+		return nil, "", err
+	}
+	if response.Error != nil {
+		return nil, "", fmt.Errorf("login failed: %v", response.Error)
+	}
+	if response.Data.TwoFactorRequired && response.Data.ChallengeID == "" {
+		return nil, "", fmt.Errorf("login asked for a second factor without a challenge")
+	}
+	return &response.Data, marbleCookie, nil
+}
+
+// VerifyTwoFactor completes a password login with the code sent for its
+// two-factor challenge
+func (c *Client) VerifyTwoFactor(challengeID, code, requestID string) (*OTPVerifyData, string, error) {
+	var response OTPVerifyResponse
+	marbleCookie, err := c.postAuth("/api/v1/auth/login/2fa", TwoFactorRequest{ChallengeID: challengeID, OTP: code}, requestID, &response)
+	if err != nil {
+		return nil, "", err
+	}
+	if response.Error != nil {
+		return nil, "", fmt.Errorf("two-factor verification failed: %v", response.Error)
+	}
+	return &response.Data, marbleCookie, nil
+}
+
+// IsWrongPassword reports whether a PasswordLogin error is Bend refusing the
+// credentials rather than the request failing
+func IsWrongPassword(err error) bool {
+	return IsWrongOTP(err)
+}
+
+// postAuth sends an unauthenticated login request and decodes its response,
+// returning the marble-cookie it sets
+func (c *Client) postAuth(endpoint string, body interface{}, requestID string, out interface{}) (string, error) {
+	// Wait for rate limiter
+	<-c.rateLimiter.C
+
+	req, err := c.newRequestWithID("POST", endpoint, body, requestID)
+	if err != nil {
+		return "", fmt.Errorf("failed to create login request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := c.readResponseBody(resp)
+	if err != nil {
+		return "", err
+	}
+	c.logResponse(resp, respBody)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", c.handleErrorResponse(resp, respBody)
+	}
+	if err := c.decodeResponse(respBody, out); err != nil {
+		return "", err
+	}
+
+	return marbleCookieOf(resp), nil
+}
+
+// marbleCookieOf returns the marble-cookie a response sets, if any
+func marbleCookieOf(resp *http.Response) string {
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "marble-cookie" {
+			return cookie.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package blend
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/profile"
+)
+
+// MaxReceiptSize is the largest receipt file Bend accepts
+const MaxReceiptSize = 10 << 20
+
+// Receipt is a file attached to a transaction, as listed in its receipts
+type Receipt struct {
+	ID          string     `json:"id"`
+	FileName    string     `json:"file_name"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	UploadedAt  *time.Time `json:"uploaded_at,omitempty"`
+}
+
+// ReceiptResponse is the response to a receipt upload
+type ReceiptResponse struct {
+	Meta  APIResponseMeta `json:"meta"`
+	Data  Receipt         `json:"data"`
+	Error interface{}     `json:"error"`
+}
+
+// ReceiptsResponse is the response listing a transaction's receipts
+type ReceiptsResponse struct {
+	Meta  APIResponseMeta `json:"meta"`
+	Data  []Receipt       `json:"data"`
+	Error interface{}     `json:"error"`
+}
+
+// receiptsEndpoint is the path of a transaction's receipts
+func receiptsEndpoint(userID, txnUUID string) string {
+	return fmt.Sprintf("/api/v3/users/%s/transactions/%s/receipts", userID, txnUUID)
+}
+
+// ListReceipts fetches the receipts attached to a transaction
+func (c *Client) ListReceipts(userID, txnUUID string) ([]Receipt, error) {
+	if c.session == nil {
+		return nil, fmt.Errorf("no session available")
+	}
+
+	// Wait for rate limiter
+	<-c.rateLimiter.C
+
+	req, err := c.newRequest("GET", receiptsEndpoint(userID, txnUUID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	var response ReceiptsResponse
+	if err := c.doRequest(req, &response); err != nil {
+		return nil, fmt.Errorf("failed to list receipts: %w", err)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("failed to list receipts: %v", response.Error)
+	}
+	return response.Data, nil
+}
+
+// UploadReceipt attaches a file to a transaction as a multipart upload
+func (c *Client) UploadReceipt(userID, txnUUID, fileName, contentType string, data []byte) (*Receipt, error) {
+	if c.session == nil {
+		return nil, fmt.Errorf("no session available")
+	}
+	if len(data) > MaxReceiptSize {
+		return nil, fmt.Errorf("receipt %s is %d bytes, more than the %d Bend accepts", fileName, len(data), MaxReceiptSize)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, fileName))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	// Wait for rate limiter
+	<-c.rateLimiter.C
+
+	req, err := c.newRequest("POST", receiptsEndpoint(userID, txnUUID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	payload := body.Bytes()
+	req.Body = io.NopCloser(bytes.NewReader(payload))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(payload)), nil }
+	req.ContentLength = int64(len(payload))
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	var response ReceiptResponse
+	if err := c.doRequest(req, &response); err != nil {
+		return nil, fmt.Errorf("failed to upload receipt: %w", err)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("failed to upload receipt: %v", response.Error)
+	}
+	return &response.Data, nil
+}
+
+// DownloadReceipt fetches a receipt's file content
+func (c *Client) DownloadReceipt(userID, txnUUID, receiptID string) ([]byte, error) {
+	if c.session == nil {
+		return nil, fmt.Errorf("no session available")
+	}
+
+	// Wait for rate limiter
+	<-c.rateLimiter.C
+
+	req, err := c.newRequest("GET", receiptsEndpoint(userID, txnUUID)+"/"+url.PathEscape(receiptID)+"/content", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "*/*")
+
+	stop := profile.Start(profile.API)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		stop()
+		return nil, fmt.Errorf("failed to download receipt: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := c.readResponseBody(resp)
+	stop()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		c.logResponse(resp, body)
+		return nil, fmt.Errorf("failed to download receipt: %w", c.handleErrorResponse(resp, body))
+	}
+	c.saveResponseCookies(resp)
+	return body, nil
+}
@@ -6,8 +6,15 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/quickkly/fintrack/internal/profile"
+	"github.com/quickkly/fintrack/internal/safefile"
 )
 
+// sessionLockTimeout is how long a session write or refresh waits for
+// another process refreshing it; a refresh is one API call
+const sessionLockTimeout = time.Minute
+
 // SessionManager handles session persistence and management
 type SessionManager struct {
 	sessionFile string
@@ -22,6 +29,63 @@ func NewSessionManager(sessionFile string) *SessionManager {
 
 // SaveSession saves the session to disk
 func (sm *SessionManager) SaveSession(session *Session) error {
+	defer profile.Start(profile.Auth)()
+
+	// Concurrent runs writing the session take turns
+	unlock, err := sm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return sm.write(session)
+}
+
+// Refresh replaces the stored session with the one refresh returns, holding
+// the session lock from load to save so that concurrent runs don't both spend
+// the refresh token, which Bend only accepts once. stale is the session the
+// caller found unusable, nil if it had none. When the stored session differs
+// from it and is valid, another run refreshed it while this one waited: that
+// session is returned, with shared set, without calling refresh.
+func (sm *SessionManager) Refresh(stale *Session, refresh func(current *Session) (*Session, error)) (*Session, bool, error) {
+	unlock, err := sm.lock()
+	if err != nil {
+		return nil, false, err
+	}
+	defer unlock()
+
+	current, err := sm.LoadSession()
+	if err == nil && sm.IsSessionValid(current) && (stale == nil || current.AccessToken != stale.AccessToken ||
+		!current.ExpiresAt.Equal(stale.ExpiresAt)) {
+		return current, true, nil
+	}
+	if current == nil {
+		current = stale
+	}
+
+	session, err := refresh(current)
+	if err != nil {
+		return nil, false, err
+	}
+	defer profile.Start(profile.Auth)()
+	if err := sm.write(session); err != nil {
+		return nil, false, err
+	}
+	return session, false, nil
+}
+
+// lock takes the session lock, shared by every fintrack process using the
+// session file
+func (sm *SessionManager) lock() (func(), error) {
+	unlock, err := safefile.Lock(sm.sessionFile, sessionLockTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock session file: %w", err)
+	}
+	return unlock, nil
+}
+
+// write saves the session atomically with secure permissions; the caller
+// holds the lock
+func (sm *SessionManager) write(session *Session) error {
 	// Ensure directory exists
 	dir := filepath.Dir(sm.sessionFile)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -34,16 +98,18 @@ func (sm *SessionManager) SaveSession(session *Session) error {
 		return fmt.Errorf("failed to marshal session: %w", err)
 	}
 
-	// Write to file with secure permissions
-	if err := os.WriteFile(sm.sessionFile, data, 0600); err != nil {
+	if err := safefile.Write(sm.sessionFile, data, 0600); err != nil {
 		return fmt.Errorf("failed to write session file: %w", err)
 	}
 
 	return nil
 }
 
-// LoadSession loads the session from disk
+// LoadSession loads the session from disk. It takes no lock: writes replace
+// the file atomically, so a read sees either the old session or the new one.
 func (sm *SessionManager) LoadSession() (*Session, error) {
+	defer profile.Start(profile.Auth)()
+
 	// Check if file exists
 	if _, err := os.Stat(sm.sessionFile); os.IsNotExist(err) {
 		return nil, fmt.Errorf("session file does not exist")
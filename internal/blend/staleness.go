@@ -0,0 +1,43 @@
+package blend
+
+import (
+	"fmt"
+	"time"
+)
+
+// StaleReason explains why an account's balance and transactions can't be
+// taken as current, or returns "" when they can. Data goes stale when the
+// bank (FIP) is down or reports invalid data: Bend keeps serving what it
+// fetched last, with nothing to say it is old.
+func (a *Account) StaleReason(now time.Time, staleAfter time.Duration) string {
+	fip := a.FinancialInformationProvider
+	if (fip.UUID != "" || fip.FIPID != "") && !fip.IsValidTime {
+		return fmt.Sprintf("%s reports invalid status", a.bankName())
+	}
+	if a.LastFetchedAt.IsZero() {
+		return "never fetched by Bend"
+	}
+	if age := now.Sub(a.LastFetchedAt); staleAfter > 0 && age > staleAfter {
+		return fmt.Sprintf("last fetched from %s %s ago", a.bankName(), formatAge(age))
+	}
+	return ""
+}
+
+// bankName is the FIP's name, or a generic one when Bend leaves it out
+func (a *Account) bankName() string {
+	if a.FinancialInformationProvider.Name != "" {
+		return a.FinancialInformationProvider.Name
+	}
+	return "the bank"
+}
+
+// formatAge renders a duration in days, or hours or minutes when shorter
+func formatAge(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	if d < 48*time.Hour {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d days", int(d.Hours()/24))
+}
@@ -0,0 +1,111 @@
+package blend
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/config"
+)
+
+// Middleware wraps the transport used for every API request. Middlewares
+// can observe or rewrite requests and responses (metrics, tracing, caching,
+// custom proxies) or short-circuit a request entirely.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req)
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// globalMiddlewares are installed on every client created after registration
+var globalMiddlewares []Middleware
+
+// RegisterMiddleware installs middlewares on every client created from now on,
+// outside any middleware the client adds itself. Used for process-wide
+// concerns such as metrics and tracing.
+func RegisterMiddleware(middlewares ...Middleware) {
+	globalMiddlewares = append(globalMiddlewares, middlewares...)
+}
+
+// sharedTransports holds one transport per tuning, so every client of a run
+// (one per account in a parallel sync) draws from the same connection pool
+// instead of dialing and negotiating TLS again
+var (
+	transportsMu     sync.Mutex
+	sharedTransports = make(map[config.TransportConfig]*http.Transport)
+)
+
+// SharedTransport returns the process-wide transport tuned by cfg
+func SharedTransport(cfg config.TransportConfig) *http.Transport {
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+
+	if t, ok := sharedTransports[cfg]; ok {
+		return t
+	}
+
+	keepAlive := cfg.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = -1 // net.Dialer treats 0 as "use the default"
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: keepAlive}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = dialer.DialContext
+	t.MaxIdleConns = cfg.MaxIdleConns
+	t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	t.MaxConnsPerHost = cfg.MaxConnsPerHost
+	t.IdleConnTimeout = cfg.IdleConnTimeout
+	t.DisableKeepAlives = cfg.DisableKeepAlives
+	t.ForceAttemptHTTP2 = cfg.ForceHTTP2
+	sharedTransports[cfg] = t
+	return t
+}
+
+// NewClientWithTransport creates a client that sends requests through the
+// given transport instead of http.DefaultTransport. A nil transport uses the default.
+func NewClientWithTransport(cfg *config.Config, transport http.RoundTripper) *Client {
+	c := NewClient(cfg)
+	c.transport = transport
+	c.httpClient.Transport = c.chain()
+	return c
+}
+
+// Use appends middlewares to the interceptor chain. The first middleware
+// added is the outermost: it sees the request first and the response last.
+func (c *Client) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+	c.httpClient.Transport = c.chain()
+}
+
+// chain builds the transport with every middleware applied around the base
+// transport. Accounting sits innermost so it only sees real network traffic.
+func (c *Client) chain() http.RoundTripper {
+	transport := c.transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	transport = accountingMiddleware(transport)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		transport = c.middlewares[i](transport)
+	}
+	return transport
+}
+
+// NormalizePath replaces IDs in API paths with ":id", so requests to the
+// same endpoint share one key in metrics and the circuit breaker
+func NormalizePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if len(segment) >= 32 && strings.Count(segment, "-") == 4 {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
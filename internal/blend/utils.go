@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/quickkly/fintrack/internal/config"
 )
 
 // generateRequestID generates a UUID-like request ID for API calls
@@ -21,12 +23,11 @@ func GenerateDeviceHash() string {
 	if err != nil {
 		// Fallback to a deterministic but unique hash based on hostname and user
 		hostname, _ := os.Hostname()
-		user := os.Getenv("USER")
-		if user == "" {
-			user = os.Getenv("USERNAME") // Windows fallback
+		fallback := fmt.Sprintf("fintrack-%s-%s", hostname, config.CurrentUser())
+		// Convert to UUID format (pad as needed)
+		if len(fallback) < 16 {
+			fallback = fallback + "0000000000000000"
 		}
-		fallback := fmt.Sprintf("fintrack-%s-%s", hostname, user)
-		// Convert to UUID format
 		return fmt.Sprintf("%x-%x-%x-%x-%x",
 			[]byte(fallback)[:4],
 			[]byte(fallback)[4:6],
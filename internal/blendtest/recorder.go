@@ -0,0 +1,300 @@
+package blendtest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mode selects whether a Recorder talks to the real API or to golden files
+type Mode string
+
+const (
+	// ModeRecord forwards requests upstream and saves sanitized responses
+	ModeRecord Mode = "record"
+	// ModeReplay serves saved responses and never touches the network
+	ModeReplay Mode = "replay"
+)
+
+// Redacted replaces sensitive values in recorded files
+const Redacted = "REDACTED"
+
+// SensitiveFields are JSON keys whose string values are redacted before a
+// response is written to a golden file
+var SensitiveFields = map[string]bool{
+	"access_token":   true,
+	"refresh_token":  true,
+	"email":          true,
+	"phone":          true,
+	"first_name":     true,
+	"middle_name":    true,
+	"last_name":      true,
+	"username":       true,
+	"profile_pic":    true,
+	"masked_acc_no":  true,
+	"account_number": true,
+	"marble_cookie":  true,
+	"device_hash":    true,
+}
+
+// uuidPattern matches UUIDs in paths, which identify the user
+var uuidPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// Interaction is one recorded request/response pair
+type Interaction struct {
+	Method     string          `json:"method"`
+	Path       string          `json:"path"` // UUIDs replaced with Redacted
+	Query      string          `json:"query,omitempty"`
+	Status     int             `json:"status"`
+	Body       json.RawMessage `json:"body"`
+	RecordedAt time.Time       `json:"recorded_at"`
+}
+
+// Recorder is a VCR-style proxy. In record mode it forwards requests to the
+// upstream Bend API, answers with the upstream response as is and stores a
+// sanitized copy as a golden file; in replay mode it serves those files.
+// Point bend.base_url at URL to use it.
+type Recorder struct {
+	URL string // Base URL to use as bend.base_url
+
+	mode     Mode
+	dir      string
+	upstream string
+	client   *http.Client
+	srv      *httptest.Server
+	mu       sync.Mutex
+}
+
+// NewRecorder starts a recorder storing golden files in dir. upstream is only
+// used in record mode.
+func NewRecorder(mode Mode, dir, upstream string) (*Recorder, error) {
+	switch mode {
+	case ModeRecord:
+		if upstream == "" {
+			return nil, fmt.Errorf("record mode needs an upstream URL")
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create golden directory: %w", err)
+		}
+	case ModeReplay:
+	default:
+		return nil, fmt.Errorf("unknown recorder mode %q", mode)
+	}
+
+	r := &Recorder{
+		mode:     mode,
+		dir:      dir,
+		upstream: strings.TrimSuffix(upstream, "/"),
+		client:   &http.Client{Timeout: 60 * time.Second},
+	}
+	r.srv = httptest.NewServer(r)
+	r.URL = r.srv.URL
+	return r, nil
+}
+
+// ModeFromEnv returns ModeRecord when FINTRACK_RECORD is set, otherwise ModeReplay
+func ModeFromEnv() Mode {
+	if os.Getenv("FINTRACK_RECORD") != "" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Close shuts the recorder down
+func (r *Recorder) Close() {
+	r.srv.Close()
+}
+
+// ServeHTTP records or replays a single request
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := r.goldenPath(req)
+
+	if r.mode == ModeRecord {
+		resp, body, err := r.forward(req)
+		if err == nil {
+			err = r.record(path, req, resp.StatusCode, body)
+		}
+		if err != nil {
+			writeError(w, http.StatusBadGateway, fmt.Sprintf("recorder: %v", err))
+			return
+		}
+		// The client gets the real tokens and cookies; only the golden file
+		// is sanitized
+		for key, values := range resp.Header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(resp.StatusCode)
+		w.Write(body)
+		return
+	}
+
+	interaction, err := load(path)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Sprintf("recorder: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(interaction.Status)
+	w.Write(interaction.Body)
+}
+
+// forward sends the request upstream and returns the response with its body
+// read, uncompressed
+func (r *Recorder) forward(req *http.Request) (*http.Response, []byte, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	upstreamReq, err := http.NewRequest(req.Method, r.upstream+req.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create upstream request: %w", err)
+	}
+	upstreamReq.Header = req.Header.Clone()
+	// Let the transport negotiate compression so the stored body is plain JSON
+	upstreamReq.Header.Del("Accept-Encoding")
+
+	resp, err := r.client.Do(upstreamReq)
+	if err != nil {
+		return nil, nil, fmt.Errorf("upstream request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read upstream response: %w", err)
+	}
+	return resp, respBody, nil
+}
+
+// record saves the sanitized interaction for a forwarded request
+func (r *Recorder) record(path string, req *http.Request, status int, body []byte) error {
+	sanitized, err := Sanitize(body)
+	if err != nil {
+		return err
+	}
+	return r.save(path, &Interaction{
+		Method:     req.Method,
+		Path:       uuidPattern.ReplaceAllString(req.URL.Path, Redacted),
+		Query:      uuidPattern.ReplaceAllString(canonicalQuery(req), Redacted),
+		Status:     status,
+		Body:       sanitized,
+		RecordedAt: time.Now(),
+	})
+}
+
+// save writes an interaction as an indented golden file
+func (r *Recorder) save(path string, interaction *Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal interaction: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// load reads a golden file
+func load(path string) (*Interaction, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no recording at %s (record it with FINTRACK_RECORD=1)", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var interaction Interaction
+	if err := json.Unmarshal(data, &interaction); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &interaction, nil
+}
+
+// goldenPath names the golden file for a request. The name is readable but
+// carries a hash of the full request so distinct queries never collide.
+// UUIDs are normalized so a recording replays for any user.
+// Volatile query parameters are excluded so replays match across days.
+func (r *Recorder) goldenPath(req *http.Request) string {
+	path := uuidPattern.ReplaceAllString(req.URL.Path, "user")
+	name := strings.ToLower(req.Method) + strings.ReplaceAll(path, "/", "_")
+
+	sum := sha256.Sum256([]byte(req.Method + " " + path + "?" + canonicalQuery(req)))
+	return filepath.Join(r.dir, name+"_"+hex.EncodeToString(sum[:4])+".json")
+}
+
+// canonicalQuery returns the sorted query without date bounds, which are
+// usually derived from the current time
+func canonicalQuery(req *http.Request) string {
+	query := req.URL.Query()
+	query.Del("start_date")
+	query.Del("end_date")
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, key+"="+value)
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// Sanitize redacts SensitiveFields anywhere in a JSON document. Bodies that
+// aren't JSON are stored as a JSON string.
+func Sanitize(body []byte) (json.RawMessage, error) {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		quoted, err := json.Marshal(string(body))
+		if err != nil {
+			return nil, err
+		}
+		return quoted, nil
+	}
+
+	sanitized, err := json.Marshal(redact(doc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sanitized body: %w", err)
+	}
+	return sanitized, nil
+}
+
+// redact walks a decoded JSON value replacing sensitive string fields
+func redact(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if _, isString := field.(string); isString && SensitiveFields[key] {
+				value[key] = Redacted
+				continue
+			}
+			value[key] = redact(field)
+		}
+	case []interface{}:
+		for i := range value {
+			value[i] = redact(value[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,115 @@
+package blendtest_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/blendtest"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	srv := blendtest.NewServer()
+	srv.AddAccounts(blend.Account{UUID: "acc-1"}, blend.Account{UUID: "acc-2"})
+	srv.AddTransactions(testTransactions()...)
+
+	// Record: the client must get the real tokens to keep talking upstream
+	recorder, err := blendtest.NewRecorder(blendtest.ModeRecord, dir, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := srv.Config()
+	cfg.Bend.BaseURL = recorder.URL
+	client := blend.NewClient(cfg)
+	if err := client.InitializeFromRefreshToken(blendtest.RefreshToken); err != nil {
+		t.Fatalf("InitializeFromRefreshToken through the recorder: %v", err)
+	}
+	if got := client.GetSession().AccessToken; got != blendtest.AccessToken {
+		t.Fatalf("recorder handed the client access token %q, want the upstream one", got)
+	}
+	recorded, err := fetchAll(client)
+	if err != nil {
+		t.Fatalf("recording: %v", err)
+	}
+	recorder.Close()
+	srv.Close()
+
+	// The golden files hold no secrets
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no golden files written (%v)", err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, secret := range []string{blendtest.AccessToken, blendtest.RefreshToken, "test@example.com"} {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("%s contains %q", filepath.Base(file), secret)
+			}
+		}
+	}
+
+	// Replay: the same calls succeed with the upstream gone
+	replayer, err := blendtest.NewRecorder(blendtest.ModeReplay, dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replayer.Close()
+	cfg.Bend.BaseURL = replayer.URL
+	client = blend.NewClient(cfg)
+	client.SetSession(srv.Session())
+	replayed, err := fetchAll(client)
+	if err != nil {
+		t.Fatalf("replaying: %v", err)
+	}
+	if !equal(replayed, recorded) {
+		t.Errorf("replayed %v, recorded %v", replayed, recorded)
+	}
+}
+
+func TestReplayMissingRecording(t *testing.T) {
+	replayer, err := blendtest.NewRecorder(blendtest.ModeReplay, t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replayer.Close()
+
+	srv := blendtest.NewServer()
+	defer srv.Close()
+	cfg := srv.Config()
+	cfg.Bend.BaseURL = replayer.URL
+	client := blend.NewClient(cfg)
+	client.SetSession(srv.Session())
+	if _, err := client.GetAccounts(); err == nil {
+		t.Fatal("replay without a recording succeeded")
+	}
+}
+
+// fetchAll runs the calls a sync makes and returns what they found: the user
+// ID, the account IDs and the transaction UUIDs
+func fetchAll(client *blend.Client) ([]string, error) {
+	userID, err := client.GetUserID()
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := client.GetAccounts()
+	if err != nil {
+		return nil, err
+	}
+	txns, _, err := client.FetchAllTransactionsWithFilters(userID, blend.TransactionFilters{AccountIDs: []string{"acc-1"}})
+	if err != nil {
+		return nil, err
+	}
+
+	found := []string{userID}
+	for _, account := range accounts {
+		found = append(found, account.UUID)
+	}
+	return append(found, uuids(txns)...), nil
+}
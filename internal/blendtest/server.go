@@ -0,0 +1,561 @@
+// Package blendtest provides an in-memory Bend server and a record/replay
+// proxy for exercising the client and commands without the real API.
+package blendtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+)
+
+// Default credentials issued by the mock server
+const (
+	AccessToken  = "test-access-token"
+	RefreshToken = "test-refresh-token"
+	MarbleCookie = "test-marble-cookie"
+	UserID       = "00000000-0000-4000-8000-000000000001"
+)
+
+// Server is a mock Bend API backed by in-memory data. Mutate the exported
+// fields (under Lock/Unlock when the server is in use) to shape responses.
+type Server struct {
+	URL string // Base URL to use as bend.base_url
+
+	mu           sync.Mutex
+	User         blend.UserInfo
+	Accounts     []blend.Account
+	Transactions []blend.Transaction
+	Refreshes    [][]string                 // Account IDs of every refresh request received
+	Receipts     map[string][]blend.Receipt // Receipts by transaction UUID
+	ReceiptFiles map[string][]byte          // Receipt content by receipt ID
+	RejectSearch bool                       // Answer free-text searches (q) with 400, like a Bend without search
+	OTP          string                     // The only code OTP verification accepts; any code when empty
+	OTPChannels  []string                   // Channel of every OTP sent
+	Password     string                     // The only password login accepts; any when empty
+	TwoFactor    bool                       // Password logins need a second factor, checked like OTP
+
+	srv      *httptest.Server
+	failures map[string]int // Path → status code to fail with
+	requests []*http.Request
+}
+
+// NewServer starts a mock Bend server with a default user and no data
+func NewServer() *Server {
+	s := &Server{
+		User: blend.UserInfo{
+			UUID:      UserID,
+			FirstName: "Test",
+			LastName:  "User",
+			Email:     "test@example.com",
+			Timezone:  "Asia/Kolkata",
+		},
+		Receipts:     make(map[string][]blend.Receipt),
+		ReceiptFiles: make(map[string][]byte),
+		failures:     make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/auth/otp", s.handleOTP)
+	mux.HandleFunc("/api/v1/auth/otp/verify", s.handleOTPVerify)
+	mux.HandleFunc("/api/v1/auth/login", s.handlePasswordLogin)
+	mux.HandleFunc("/api/v1/auth/login/2fa", s.handleTwoFactor)
+	mux.HandleFunc("/api/v1/auth/tokens/refresh", s.handleRefresh)
+	mux.HandleFunc("/api/v2/users/me", s.authenticated(s.handleUserMe))
+	mux.HandleFunc("/api/v1/aa/data", s.authenticated(s.handleAccounts))
+	mux.HandleFunc("/api/v1/aa/data/refresh", s.authenticated(s.handleAccountRefresh))
+	mux.HandleFunc("/api/v3/users/", s.authenticated(s.handleTransactions))
+
+	s.srv = httptest.NewServer(s.record(mux))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Lock guards the exported data while the server is serving requests
+func (s *Server) Lock() { s.mu.Lock() }
+
+// Unlock releases the lock taken by Lock
+func (s *Server) Unlock() { s.mu.Unlock() }
+
+// AddTransactions appends transactions to the mock data
+func (s *Server) AddTransactions(txns ...blend.Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Transactions = append(s.Transactions, txns...)
+}
+
+// AddAccounts appends accounts to the mock data
+func (s *Server) AddAccounts(accounts ...blend.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Accounts = append(s.Accounts, accounts...)
+}
+
+// Fail makes every request to path respond with status until cleared with status 0
+func (s *Server) Fail(path string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status == 0 {
+		delete(s.failures, path)
+		return
+	}
+	s.failures[path] = status
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*http.Request(nil), s.requests...)
+}
+
+// Config returns a configuration pointing at the mock server with no rate limiting
+func (s *Server) Config() *config.Config {
+	return &config.Config{
+		Bend: config.BendConfig{
+			BaseURL:        s.URL,
+			RateLimit:      time.Millisecond,
+			Timeout:        5 * time.Second,
+			DeviceHash:     "test-device-hash",
+			DeviceType:     "Web",
+			DeviceLocation: "Test",
+			PageSize:       blend.DefaultPageSize,
+			MaxPages:       1000,
+		},
+	}
+}
+
+// Session returns a valid session for the mock server
+func (s *Server) Session() *blend.Session {
+	return &blend.Session{
+		AccessToken:  AccessToken,
+		RefreshToken: RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Hour),
+		TokenType:    "Bearer",
+		MarbleCookie: MarbleCookie,
+		DeviceHash:   "test-device-hash",
+	}
+}
+
+// Client returns a client for the mock server with a valid session
+func (s *Server) Client() *blend.Client {
+	client := blend.NewClient(s.Config())
+	client.SetSession(s.Session())
+	return client
+}
+
+// =============================================================================
+// HANDLERS
+// =============================================================================
+
+// record logs each request and applies injected failures
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r)
+		status, fail := s.failures[r.URL.Path]
+		s.mu.Unlock()
+
+		if fail {
+			writeError(w, status, "injected failure")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticated rejects requests without the mock access token
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+AccessToken {
+			writeError(w, http.StatusUnauthorized, "invalid access token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleOTP(w http.ResponseWriter, r *http.Request) {
+	var req blend.OTPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Phone == "" {
+		writeError(w, http.StatusBadRequest, "phone is required")
+		return
+	}
+	if !contains(blend.OTPChannels, req.Channel) {
+		writeError(w, http.StatusBadRequest, "unsupported channel")
+		return
+	}
+	s.mu.Lock()
+	s.OTPChannels = append(s.OTPChannels, req.Channel)
+	s.mu.Unlock()
+	writeData(w, map[string]string{"status": "sent"})
+}
+
+func (s *Server) handleOTPVerify(w http.ResponseWriter, r *http.Request) {
+	var req blend.OTPVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OTP == "" {
+		writeError(w, http.StatusBadRequest, "otp is required")
+		return
+	}
+
+	s.mu.Lock()
+	user, code := s.User, s.OTP
+	s.mu.Unlock()
+	if code != "" && req.OTP != code {
+		writeError(w, http.StatusUnauthorized, "invalid otp")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: "marble-cookie", Value: MarbleCookie})
+	writeData(w, tokenData(user))
+}
+
+// twoFactorChallenge is the challenge ID of every password login needing 2FA
+const twoFactorChallenge = "test-challenge"
+
+func (s *Server) handlePasswordLogin(w http.ResponseWriter, r *http.Request) {
+	var req blend.PasswordLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" || req.Password == "" {
+		writeError(w, http.StatusBadRequest, "email and password are required")
+		return
+	}
+
+	s.mu.Lock()
+	user, password, twoFactor := s.User, s.Password, s.TwoFactor
+	s.mu.Unlock()
+	if password != "" && req.Password != password {
+		writeError(w, http.StatusUnauthorized, "invalid email or password")
+		return
+	}
+	if twoFactor {
+		writeData(w, blend.PasswordLoginData{TwoFactorRequired: true, ChallengeID: twoFactorChallenge, TwoFactorChannel: "sms"})
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: "marble-cookie", Value: MarbleCookie})
+	writeData(w, blend.PasswordLoginData{OTPVerifyData: tokenData(user)})
+}
+
+func (s *Server) handleTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req blend.TwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OTP == "" {
+		writeError(w, http.StatusBadRequest, "otp is required")
+		return
+	}
+
+	s.mu.Lock()
+	user, code := s.User, s.OTP
+	s.mu.Unlock()
+	if req.ChallengeID != twoFactorChallenge {
+		writeError(w, http.StatusUnauthorized, "unknown challenge")
+		return
+	}
+	if code != "" && req.OTP != code {
+		writeError(w, http.StatusUnauthorized, "invalid otp")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: "marble-cookie", Value: MarbleCookie})
+	writeData(w, tokenData(user))
+}
+
+// tokenData is what a successful login returns for user
+func tokenData(user blend.UserInfo) blend.OTPVerifyData {
+	return blend.OTPVerifyData{
+		TokenType:    "Bearer",
+		AccessToken:  AccessToken,
+		RefreshToken: RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Hour).Format(time.RFC3339),
+		UserID:       user.UUID,
+		UserMeta:     user,
+	}
+}
+
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	var req blend.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		writeError(w, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+	writeData(w, blend.TokenData{
+		TokenType:    "Bearer",
+		AccessToken:  AccessToken,
+		RefreshToken: RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Hour).Format(time.RFC3339),
+	})
+}
+
+func (s *Server) handleUserMe(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeData(w, blend.UserDataResponse{User: s.User})
+}
+
+func (s *Server) handleAccounts(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeData(w, blend.AAData{Accounts: s.Accounts})
+}
+
+func (s *Server) handleAccountRefresh(w http.ResponseWriter, r *http.Request) {
+	var req blend.AccountRefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Refreshes = append(s.Refreshes, req.AccountIDs)
+
+	now := time.Now()
+	for i := range s.Accounts {
+		if len(req.AccountIDs) == 0 || contains(req.AccountIDs, s.Accounts[i].UUID) {
+			s.Accounts[i].LastFetchedAt = now
+		}
+	}
+	writeData(w, map[string]string{"status": "queued"})
+}
+
+// handleTransactions serves /api/v3/users/{id}/transactions with date,
+// account and category filtering and cursor pagination
+func (s *Server) handleTransactions(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) > 6 && parts[4] == "transactions" && parts[6] == "receipts" {
+		s.handleReceipts(w, r, parts)
+		return
+	}
+	if len(parts) != 5 || parts[4] != "transactions" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	s.mu.Lock()
+	userID := s.User.UUID
+	all := append([]blend.Transaction(nil), s.Transactions...)
+	s.mu.Unlock()
+
+	if parts[3] != userID {
+		writeError(w, http.StatusForbidden, "user mismatch")
+		return
+	}
+
+	query := r.URL.Query()
+	s.mu.Lock()
+	rejectSearch := s.RejectSearch
+	s.mu.Unlock()
+	if rejectSearch && query.Get("q") != "" {
+		writeError(w, http.StatusBadRequest, "unknown parameter q")
+		return
+	}
+
+	matched, err := filterTransactions(all, query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit := blend.DefaultPageSize
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+	}
+
+	offset := 0
+	if v := query.Get("after"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+	}
+
+	data := blend.TransactionsV3Data{Total: len(matched), Transactions: []blend.Transaction{}}
+	if offset < len(matched) {
+		end := offset + limit
+		if end > len(matched) {
+			end = len(matched)
+		}
+		data.Transactions = matched[offset:end]
+		if end < len(matched) {
+			data.After = strconv.Itoa(end)
+		}
+	}
+
+	writeData(w, data)
+}
+
+// handleReceipts serves /api/v3/users/{id}/transactions/{uuid}/receipts:
+// GET lists, POST uploads (multipart, field "file"), and
+// .../receipts/{receipt}/content returns a receipt's file
+func (s *Server) handleReceipts(w http.ResponseWriter, r *http.Request, parts []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if parts[3] != s.User.UUID {
+		writeError(w, http.StatusForbidden, "user mismatch")
+		return
+	}
+	txnUUID := parts[5]
+
+	switch {
+	case len(parts) == 9 && parts[8] == "content" && r.Method == http.MethodGet:
+		data, ok := s.ReceiptFiles[parts[7]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "receipt not found")
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(data)
+
+	case len(parts) == 7 && r.Method == http.MethodGet:
+		receipts := s.Receipts[txnUUID]
+		if receipts == nil {
+			receipts = []blend.Receipt{}
+		}
+		writeData(w, receipts)
+
+	case len(parts) == 7 && r.Method == http.MethodPost:
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "file is required")
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "unreadable file")
+			return
+		}
+
+		now := time.Now()
+		receipt := blend.Receipt{
+			ID:          fmt.Sprintf("rcpt-%d", len(s.ReceiptFiles)+1),
+			FileName:    header.Filename,
+			ContentType: header.Header.Get("Content-Type"),
+			Size:        int64(len(data)),
+			UploadedAt:  &now,
+		}
+		s.Receipts[txnUUID] = append(s.Receipts[txnUUID], receipt)
+		s.ReceiptFiles[receipt.ID] = data
+		writeData(w, receipt)
+
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// filterTransactions applies the query filters and sort order the client sends
+func filterTransactions(txns []blend.Transaction, query map[string][]string) ([]blend.Transaction, error) {
+	get := func(key string) string {
+		if v := query[key]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+
+	var start, end time.Time
+	var err error
+	if v := get("start_date"); v != "" {
+		if start, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("invalid start_date")
+		}
+	}
+	if v := get("end_date"); v != "" {
+		if end, err = time.Parse(time.RFC3339, v); err != nil {
+			return nil, fmt.Errorf("invalid end_date")
+		}
+	}
+
+	accounts := query["account_id[]"]
+	categories := query["category_id[]"]
+	if v := get("category_id"); v != "" {
+		categories = append(categories, v)
+	}
+	search := get("q")
+
+	var matched []blend.Transaction
+	for _, txn := range txns {
+		if !start.IsZero() && txn.TxnTimestamp.Before(start) {
+			continue
+		}
+		if !end.IsZero() && txn.TxnTimestamp.After(end) {
+			continue
+		}
+		if len(accounts) > 0 && !contains(accounts, txn.AccountID) {
+			continue
+		}
+		if len(categories) > 0 && (txn.Category == nil || txn.Category.ID == nil || !contains(categories, *txn.Category.ID)) {
+			continue
+		}
+		if search != "" && !txn.MatchesSearch(search) {
+			continue
+		}
+		matched = append(matched, txn)
+	}
+
+	ascending := strings.EqualFold(get("sort_order"), "ASC")
+	if get("sort_by") == "amount" {
+		sort.SliceStable(matched, func(i, j int) bool {
+			if ascending {
+				return matched[i].Amount < matched[j].Amount
+			}
+			return matched[i].Amount > matched[j].Amount
+		})
+	} else {
+		sort.SliceStable(matched, func(i, j int) bool {
+			if ascending {
+				return matched[i].TxnTimestamp.Before(matched[j].TxnTimestamp)
+			}
+			return matched[i].TxnTimestamp.After(matched[j].TxnTimestamp)
+		})
+	}
+
+	return matched, nil
+}
+
+// writeData writes a successful Bend response envelope
+func writeData(w http.ResponseWriter, data interface{}) {
+	writeJSON(w, http.StatusOK, blend.APIResponse{Meta: meta(), Data: data})
+}
+
+// writeError writes a failed Bend response envelope
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, blend.APIResponse{Meta: meta(), Error: map[string]string{"message": message}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func meta() blend.APIResponseMeta {
+	return blend.APIResponseMeta{
+		RequestID: fmt.Sprintf("mock-%d", time.Now().UnixNano()),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
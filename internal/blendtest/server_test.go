@@ -0,0 +1,148 @@
+package blendtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/blendtest"
+)
+
+// testTransactions are three transactions over two accounts and categories
+func testTransactions() []blend.Transaction {
+	category := func(id string) *blend.TransactionCategory { return &blend.TransactionCategory{ID: &id} }
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	return []blend.Transaction{
+		{UUID: "txn-1", AccountID: "acc-1", Amount: 120, Currency: "INR", TxnTimestamp: at, Type: "OUTGOING", Category: category("food")},
+		{UUID: "txn-2", AccountID: "acc-2", Amount: 5000, Currency: "INR", TxnTimestamp: at.Add(time.Hour), Type: "INCOMING", Category: category("salary")},
+		{UUID: "txn-3", AccountID: "acc-1", Amount: 80, Currency: "INR", TxnTimestamp: at.Add(2 * time.Hour), Type: "OUTGOING", Category: category("travel")},
+	}
+}
+
+func TestClientAgainstServer(t *testing.T) {
+	srv := blendtest.NewServer()
+	defer srv.Close()
+	srv.AddAccounts(blend.Account{UUID: "acc-1"}, blend.Account{UUID: "acc-2"})
+	srv.AddTransactions(testTransactions()...)
+
+	client := blend.NewClient(srv.Config())
+	if err := client.InitializeFromRefreshToken(blendtest.RefreshToken); err != nil {
+		t.Fatalf("InitializeFromRefreshToken: %v", err)
+	}
+	if got := client.GetSession().AccessToken; got != blendtest.AccessToken {
+		t.Errorf("access token = %q, want %q", got, blendtest.AccessToken)
+	}
+
+	userID, err := client.GetUserID()
+	if err != nil {
+		t.Fatalf("GetUserID: %v", err)
+	}
+	if userID != blendtest.UserID {
+		t.Errorf("user ID = %q, want %q", userID, blendtest.UserID)
+	}
+
+	accounts, err := client.GetAccounts()
+	if err != nil {
+		t.Fatalf("GetAccounts: %v", err)
+	}
+	if len(accounts) != 2 {
+		t.Errorf("got %d accounts, want 2", len(accounts))
+	}
+
+	tests := []struct {
+		name    string
+		filters blend.TransactionFilters
+		want    []string
+	}{
+		{"all", blend.TransactionFilters{}, []string{"txn-3", "txn-2", "txn-1"}},
+		{"one account", blend.TransactionFilters{AccountIDs: []string{"acc-1"}}, []string{"txn-3", "txn-1"}},
+		{"several accounts", blend.TransactionFilters{AccountIDs: []string{"acc-1", "acc-2"}}, []string{"txn-3", "txn-2", "txn-1"}},
+		{"categories", blend.TransactionFilters{CategoryIDs: []string{"food", "salary"}}, []string{"txn-2", "txn-1"}},
+		{"ascending", blend.TransactionFilters{SortBy: "txn_timestamp", SortOrder: "ASC"}, []string{"txn-1", "txn-2", "txn-3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txns, _, err := client.FetchAllTransactionsWithFilters(userID, tt.filters)
+			if err != nil {
+				t.Fatalf("FetchAllTransactionsWithFilters: %v", err)
+			}
+			if got := uuids(txns); !equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientRejectedWithoutSession(t *testing.T) {
+	srv := blendtest.NewServer()
+	defer srv.Close()
+
+	client := blend.NewClient(srv.Config())
+	client.SetSession(&blend.Session{AccessToken: "wrong", TokenType: "Bearer", ExpiresAt: time.Now().Add(time.Hour)})
+	if _, err := client.GetAccounts(); err == nil {
+		t.Fatal("GetAccounts with a wrong token succeeded")
+	}
+}
+
+func TestSetPaginationMaxPages(t *testing.T) {
+	srv := blendtest.NewServer()
+	defer srv.Close()
+	srv.AddTransactions(testTransactions()...)
+
+	tests := []struct {
+		name     string
+		maxPages int
+		wantErr  bool
+	}{
+		{"capped", 2, true},
+		{"enough", 3, false},
+		{"zero is unlimited", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := srv.Config()
+			cfg.Bend.MaxPages = 1
+			client := blend.NewClient(cfg)
+			client.SetSession(srv.Session())
+			client.SetPagination(1, tt.maxPages)
+			if got := client.MaxPages(); got != tt.maxPages {
+				t.Errorf("MaxPages() = %d, want %d", got, tt.maxPages)
+			}
+
+			txns, _, err := client.FetchAllTransactions(blendtest.UserID, 0)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("fetch past the cap succeeded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FetchAllTransactions: %v", err)
+			}
+			if len(txns) != 3 {
+				t.Errorf("got %d transactions, want 3", len(txns))
+			}
+		})
+	}
+}
+
+// uuids lists the transactions' UUIDs in order
+func uuids(txns []blend.Transaction) []string {
+	ids := make([]string, len(txns))
+	for i, txn := range txns {
+		ids[i] = txn.UUID
+	}
+	return ids
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AccountsConfig holds local, per-account settings that Bend does not provide
+type AccountsConfig struct {
+	Settings map[string]AccountSettings `mapstructure:"settings"` // Keyed by account UUID
+	Exclude  []string                   `mapstructure:"exclude"`  // UUIDs or nicknames of closed or duplicate accounts to skip
+}
+
+// AccountSettings anchors an account's computed balances when Bend only has
+// partial history for it, and records what Bend doesn't say about its consent
+type AccountSettings struct {
+	OpeningBalance float64 `mapstructure:"opening_balance"` // Balance at the start of StartDate
+	StartDate      string  `mapstructure:"start_date"`      // YYYY-MM-DD; transactions before it are ignored
+	ConsentExpires string  `mapstructure:"consent_expires"` // YYYY-MM-DD the AA consent ends, when Bend doesn't report it
+
+	LowBalance *float64 `mapstructure:"low_balance"` // Forecast warning level, overriding forecast.threshold
+
+	Nickname string `mapstructure:"nickname"` // Short name shown for the account, and accepted in place of its UUID
+	Group    string `mapstructure:"group"`    // e.g. "personal" or "business", for --group filters
+	Exclude  bool   `mapstructure:"exclude"`  // Skip the account, as if listed under accounts.exclude
+}
+
+// AccountSettingsFor returns the local settings for an account, if any
+func (c *Config) AccountSettingsFor(accountID string) (AccountSettings, bool) {
+	settings, ok := c.Accounts.Settings[accountID]
+	return settings, ok
+}
+
+// AccountName returns an account's nickname, or its UUID when it has none
+func (c *Config) AccountName(accountID string) string {
+	if settings, ok := c.Accounts.Settings[accountID]; ok && settings.Nickname != "" {
+		return settings.Nickname
+	}
+	return accountID
+}
+
+// AccountGroup returns the group an account is in, lowercased; "" for none
+func (c *Config) AccountGroup(accountID string) string {
+	return strings.ToLower(c.Accounts.Settings[accountID].Group)
+}
+
+// AccountGroups returns the configured group names in sorted order
+func (c *Config) AccountGroups() []string {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, settings := range c.Accounts.Settings {
+		group := strings.ToLower(settings.Group)
+		if group != "" && !seen[group] {
+			seen[group] = true
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// ResolveAccount turns a nickname into its account UUID. Anything that isn't
+// a nickname is returned as is, so UUIDs pass through.
+func (c *Config) ResolveAccount(ref string) string {
+	for id, settings := range c.Accounts.Settings {
+		if settings.Nickname != "" && strings.EqualFold(settings.Nickname, ref) {
+			return id
+		}
+	}
+	return ref
+}
+
+// AccountExcluded reports whether an account is skipped by sync, reports and
+// net worth, through accounts.exclude, its exclude setting or --exclude-account
+func (c *Config) AccountExcluded(accountID string) bool {
+	settings := c.Accounts.Settings[accountID]
+	if settings.Exclude {
+		return true
+	}
+	for _, ref := range c.Accounts.Exclude {
+		if strings.EqualFold(ref, accountID) || settings.Nickname != "" && strings.EqualFold(ref, settings.Nickname) {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountFilter returns whether an account's transactions belong in output
+// limited to groups. With no groups, every account but the excluded ones
+// does; with groups, the accounts in them do, excluded or not.
+func (c *Config) AccountFilter(groups []string) (func(accountID string) bool, error) {
+	if len(groups) == 0 {
+		return func(accountID string) bool {
+			return !c.AccountExcluded(accountID)
+		}, nil
+	}
+
+	known := c.AccountGroups()
+	wanted := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		group = strings.ToLower(strings.TrimSpace(group))
+		if !slices.Contains(known, group) {
+			return nil, fmt.Errorf("unknown account group %q (configured: %s)", group, strings.Join(known, ", "))
+		}
+		wanted[group] = true
+	}
+	return func(accountID string) bool {
+		return wanted[c.AccountGroup(accountID)]
+	}, nil
+}
+
+// HistoryStart returns the parsed start date for an account. The zero time
+// means no cutoff is configured.
+func (s AccountSettings) HistoryStart() (time.Time, error) {
+	if s.StartDate == "" {
+		return time.Time{}, nil
+	}
+	start, err := time.ParseInLocation("2006-01-02", s.StartDate, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid start_date %q (use YYYY-MM-DD): %w", s.StartDate, err)
+	}
+	return start, nil
+}
+
+// ConsentExpiry returns the parsed consent expiry date. The zero time means
+// none is configured.
+func (s AccountSettings) ConsentExpiry() (time.Time, error) {
+	if s.ConsentExpires == "" {
+		return time.Time{}, nil
+	}
+	expiry, err := time.ParseInLocation("2006-01-02", s.ConsentExpires, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid consent_expires %q (use YYYY-MM-DD): %w", s.ConsentExpires, err)
+	}
+	return expiry, nil
+}
+
+// ValidateAccounts checks every account's settings can be parsed and that
+// no two accounts share a nickname
+func (c *Config) ValidateAccounts() error {
+	nicknames := make(map[string]string)
+	for id, settings := range c.Accounts.Settings {
+		if settings.Nickname != "" {
+			key := strings.ToLower(settings.Nickname)
+			if other, ok := nicknames[key]; ok {
+				return fmt.Errorf("accounts.settings.%s: nickname %q is already used by %s", id, settings.Nickname, other)
+			}
+			nicknames[key] = id
+		}
+		if _, err := settings.HistoryStart(); err != nil {
+			return fmt.Errorf("accounts.settings.%s: %w", id, err)
+		}
+		if _, err := settings.ConsentExpiry(); err != nil {
+			return fmt.Errorf("accounts.settings.%s: %w", id, err)
+		}
+	}
+	return nil
+}
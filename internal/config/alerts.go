@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AlertsConfig holds the alert rules evaluated after each sync and the
+// channels their notifications go to
+type AlertsConfig struct {
+	Rules     map[string]AlertRule    `mapstructure:"rules"`      // Keyed by rule name
+	Channels  map[string]AlertChannel `mapstructure:"channels"`   // Keyed by channel name
+	StateFile string                  `mapstructure:"state_file"` // Remembers which alerts were already sent
+	SMTP      SMTPConfig              `mapstructure:"smtp"`       // Mail server for email channels
+}
+
+// SMTPConfig is the mail server email channels send through
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`     // Default 587, or 465 with tls: implicit
+	Username string `mapstructure:"username"` // No authentication when empty
+	Password string `mapstructure:"password"` // Prefer FINTRACK_ALERTS_SMTP_PASSWORD
+	From     string `mapstructure:"from"`     // Sender address
+	TLS      string `mapstructure:"tls"`      // starttls (default), implicit, or none for a local relay
+}
+
+// AlertRule is a condition in the alert rules language, e.g.
+// "category=dining and monthly total > 1.5 * trailing 3 month average"
+type AlertRule struct {
+	When     string   `mapstructure:"when"`
+	Channels []string `mapstructure:"channels"` // Channel names; none only records the alert in the sync report
+}
+
+// AlertChannel is where alert notifications are delivered
+type AlertChannel struct {
+	Type    string            `mapstructure:"type"`    // webhook, command, email or plugin
+	URL     string            `mapstructure:"url"`     // Endpoint (webhook)
+	Headers map[string]string `mapstructure:"headers"` // Extra request headers (webhook)
+	Command string            `mapstructure:"command"` // Shell command (command); the alert is in FINTRACK_ALERT_* variables
+	To      []string          `mapstructure:"to"`      // Recipients (email), sent through alerts.smtp
+	Plugin  string            `mapstructure:"plugin"`  // Name under plugins (plugin)
+}
+
+// BillsConfig controls reminders for upcoming bills and recurring payments
+type BillsConfig struct {
+	Channels   []string `mapstructure:"channels"`    // Alert channels reminders are sent to
+	NotifyDays int      `mapstructure:"notify_days"` // Remind this many days before a payment is due
+}
+
+// ConsentConfig controls warnings before account aggregator consents expire
+type ConsentConfig struct {
+	Channels   []string `mapstructure:"channels"`    // Alert channels warnings are sent to
+	NotifyDays int      `mapstructure:"notify_days"` // Warn this many days before a consent expires
+}
+
+// ValidateBills checks bill reminders only use configured alert channels
+func (c *Config) ValidateBills() error {
+	for _, channel := range c.Bills.Channels {
+		if _, ok := c.Alerts.Channels[strings.ToLower(channel)]; !ok {
+			return fmt.Errorf("bills.channels: unknown alert channel %q", channel)
+		}
+	}
+	if c.Bills.NotifyDays < 0 {
+		return fmt.Errorf("bills.notify_days cannot be negative")
+	}
+	return nil
+}
+
+// ValidateConsent checks consent warnings only use configured alert channels
+func (c *Config) ValidateConsent() error {
+	for _, channel := range c.Consent.Channels {
+		if _, ok := c.Alerts.Channels[strings.ToLower(channel)]; !ok {
+			return fmt.Errorf("consent.channels: unknown alert channel %q", channel)
+		}
+	}
+	if c.Consent.NotifyDays < 0 {
+		return fmt.Errorf("consent.notify_days cannot be negative")
+	}
+	return nil
+}
@@ -5,74 +5,231 @@ import (
 	"crypto/rand"
 	"fmt"
 	"os"
+	"os/user"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"time"
 
+	"github.com/quickkly/fintrack/internal/money"
+
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Bend BendConfig `mapstructure:"bend"`
+	Bend          BendConfig                `mapstructure:"bend"`
+	Accounts      AccountsConfig            `mapstructure:"accounts"`       // Local per-account settings
+	Goals         map[string]GoalConfig     `mapstructure:"goals"`          // Savings goals keyed by name
+	Alerts        AlertsConfig              `mapstructure:"alerts"`         // Alert rules and notification channels
+	Bills         BillsConfig               `mapstructure:"bills"`          // Upcoming payment reminders
+	Consent       ConsentConfig             `mapstructure:"consent"`        // Account aggregator consent expiry warnings
+	Reports       ReportsConfig             `mapstructure:"reports"`        // Scheduled report jobs
+	FX            FXConfig                  `mapstructure:"fx"`             // Currency conversion
+	Money         MoneyConfig               `mapstructure:"money"`          // Decimal handling
+	Entities      map[string]EntityConfig   `mapstructure:"entities"`       // Books keyed by entity name
+	DefaultEntity string                    `mapstructure:"default_entity"` // Entity for unassigned accounts
+	Telemetry     TelemetryConfig           `mapstructure:"telemetry"`      // Metrics, tracing and usage stats
+	Exports       map[string]ExportTarget   `mapstructure:"exports"`        // Export targets keyed by name
+	Timeseries    TimeseriesConfig          `mapstructure:"timeseries"`     // Balance and spend time series for dashboards
+	Forecast      ForecastConfig            `mapstructure:"forecast"`       // Balance projections
+	Refunds       RefundsConfig             `mapstructure:"refunds"`        // Refund matching
+	Contacts      []ContactConfig           `mapstructure:"contacts"`       // UPI counterparties with labels
+	Receipts      ReceiptsConfig            `mapstructure:"receipts"`       // Local copies of receipt files
+	Importers     map[string]ImporterConfig `mapstructure:"importers"`      // Custom 'fintrack import' formats keyed by name
+	Plugins       map[string]PluginConfig   `mapstructure:"plugins"`        // External exporters, alert channels and enrichers keyed by name
+	Ledger        LedgerConfig              `mapstructure:"ledger"`         // Account/category mapping for accounting exports
+	Display       DisplayConfig             `mapstructure:"display"`        // Output preferences
+	Sync          SyncConfig                `mapstructure:"sync"`           // fintrack sync
+	Store         StoreConfig               `mapstructure:"store"`          // Local transaction store
+	Logging       LoggingConfig             `mapstructure:"logging"`        // Console output format
+	Periods       PeriodsConfig             `mapstructure:"periods"`        // Fiscal year and named periods
+	Paths         PathsConfig               `mapstructure:"paths"`          // Data, cache and state directories
+
+	Timezone string `mapstructure:"timezone"` // IANA zone for dates and day boundaries ("" or "local" is the system's)
+
+	SecretsFromEnv bool `mapstructure:"secrets_from_env"` // Only accept tokens from FINTRACK_* variables, never the file
+
+	Environment  string                       `mapstructure:"environment"`  // Active environment ("" is plain bend settings)
+	Environments map[string]EnvironmentConfig `mapstructure:"environments"` // Bend overrides keyed by environment name
+	Session      string                       `mapstructure:"session"`      // Active named session ("" is the default one)
+
+	defaultSessionFile string                 // The environment's own session file, before a named session is selected
+	defaultCacheDir    string                 // The default session's HTTP cache directory
+	sources            map[string]traceSource // Where each value came from, for 'config show --trace'
 }
 
 // BendConfig represents Bend financial service configuration
 type BendConfig struct {
-	BaseURL        string        `mapstructure:"base_url"`
-	RateLimit      time.Duration `mapstructure:"rate_limit"`
-	SessionFile    string        `mapstructure:"session_file"`
-	Timeout        time.Duration `mapstructure:"timeout"`
-	RefreshToken   string        `mapstructure:"refresh_token"`   // Initial refresh token
-	DeviceHash     string        `mapstructure:"device_hash"`     // Device identifier
-	DeviceType     string        `mapstructure:"device_type"`     // Device type (Web/Mobile)
-	DeviceLocation string        `mapstructure:"device_location"` // Device location
+	BaseURL          string            `mapstructure:"base_url"`
+	RateLimit        time.Duration     `mapstructure:"rate_limit"`
+	SessionFile      string            `mapstructure:"session_file"`
+	Timeout          time.Duration     `mapstructure:"timeout"`
+	RefreshToken     string            `mapstructure:"refresh_token"`     // Initial refresh token
+	DeviceHash       string            `mapstructure:"device_hash"`       // Device identifier
+	DeviceType       string            `mapstructure:"device_type"`       // Device type (Web/Mobile)
+	DeviceLocation   string            `mapstructure:"device_location"`   // Device location
+	PageSize         int               `mapstructure:"page_size"`         // Transactions requested per page
+	MaxPages         int               `mapstructure:"max_pages"`         // Safety cap on pages fetched in one run
+	StrictDecode     bool              `mapstructure:"strict_decode"`     // Fail on response fields the models don't know
+	ArchiveRaw       bool              `mapstructure:"archive_raw"`       // Keep raw response bodies for reprocessing
+	ArchiveDir       string            `mapstructure:"archive_dir"`       // Where raw responses are archived
+	Cache            bool              `mapstructure:"cache"`             // Cache idempotent GETs on disk
+	CacheTTL         time.Duration     `mapstructure:"cache_ttl"`         // How long cached responses are used without revalidating
+	CacheDir         string            `mapstructure:"cache_dir"`         // Where cached responses are stored
+	UsageFile        string            `mapstructure:"usage_file"`        // Cumulative request and bandwidth totals
+	CircuitThreshold int               `mapstructure:"circuit_threshold"` // Consecutive failures that open an endpoint's circuit (0 disables)
+	CircuitCooldown  time.Duration     `mapstructure:"circuit_cooldown"`  // How long an open circuit fails requests fast
+	CircuitFile      string            `mapstructure:"circuit_file"`      // Circuit state carried between syncs
+	Transport        TransportConfig   `mapstructure:"transport"`         // HTTP connection pool and protocol tuning
+	UserAgent        string            `mapstructure:"user_agent"`        // User-Agent for API requests; {version} is the fintrack version
+	Origin           string            `mapstructure:"origin"`            // Origin header sent with API requests
+	Headers          map[string]string `mapstructure:"headers"`           // Extra headers for every request, overriding built-in ones
+}
+
+// TransportConfig tunes the HTTP connections shared by every Bend client of a run
+type TransportConfig struct {
+	MaxIdleConns        int           `mapstructure:"max_idle_conns"`          // Idle connections kept open in total
+	MaxIdleConnsPerHost int           `mapstructure:"max_idle_conns_per_host"` // Idle connections kept open to Bend
+	MaxConnsPerHost     int           `mapstructure:"max_conns_per_host"`      // Cap on connections to Bend (0 = no cap)
+	IdleConnTimeout     time.Duration `mapstructure:"idle_conn_timeout"`       // How long an idle connection is kept
+	KeepAlive           time.Duration `mapstructure:"keep_alive"`              // TCP keep-alive probe interval (0 = off)
+	DisableKeepAlives   bool          `mapstructure:"disable_keep_alives"`     // Open a new connection for every request
+	ForceHTTP2          bool          `mapstructure:"force_http2"`             // Negotiate HTTP/2 over TLS when Bend offers it
+}
+
+// FXConfig represents currency conversion settings
+type FXConfig struct {
+	BaseCurrency string `mapstructure:"base_currency"` // Currency amounts are normalized into
+	RatesURL     string `mapstructure:"rates_url"`     // Historical rates API ({date}, {from}, {to} placeholders)
+	CacheFile    string `mapstructure:"cache_file"`    // Local cache of fetched rates
+}
+
+// MoneyConfig represents decimal handling for reports and exports
+type MoneyConfig struct {
+	Rounding string `mapstructure:"rounding"` // half_even, half_up, down or up
+}
+
+// SyncConfig represents settings for fintrack sync
+type SyncConfig struct {
+	Days       int           `mapstructure:"days"`        // History fetched by the first sync
+	ReportFile string        `mapstructure:"report_file"` // Machine-readable outcome of the last run
+	MaxAge     time.Duration `mapstructure:"max_age"`     // fintrack healthz fails once the last success is older
+	StaleAfter time.Duration `mapstructure:"stale_after"` // Account data Bend last fetched longer ago is flagged stale
 }
 
-// Load initializes and loads the configuration
+// StoreConfig represents the local transaction store
+type StoreConfig struct {
+	Backend  string `mapstructure:"backend"`  // Storage backend; json (default) or one registered by an embedder
+	Path     string `mapstructure:"path"`     // Store file, or the backend's DSN
+	Keychain bool   `mapstructure:"keychain"` // Cache the passphrase of an encrypted store in the OS keychain
+}
+
+// LoggingConfig represents how console output is written
+type LoggingConfig struct {
+	Format string `mapstructure:"format"` // text (default) or json for log collectors
+}
+
+// TelemetryConfig represents metrics, tracing and usage stats settings. All are off unless set.
+type TelemetryConfig struct {
+	MetricsAddr   string `mapstructure:"metrics_addr"`   // Serve Prometheus /metrics here (e.g. ":9464")
+	OTLPEndpoint  string `mapstructure:"otlp_endpoint"`  // OTLP/HTTP traces endpoint (e.g. http://localhost:4318/v1/traces)
+	ServiceName   string `mapstructure:"service_name"`   // service.name reported with traces
+	Stats         bool   `mapstructure:"stats"`          // Keep anonymous command counts and error classes
+	StatsFile     string `mapstructure:"stats_file"`     // Where stats are kept
+	StatsEndpoint string `mapstructure:"stats_endpoint"` // Also POST each run's stats here (optional)
+}
+
+// RoundingMode returns the configured rounding mode, or the default if unset or invalid
+func (c *Config) RoundingMode() money.RoundingMode {
+	mode, err := money.ParseRounding(c.Money.Rounding)
+	if err != nil {
+		return money.DefaultRounding
+	}
+	return mode
+}
+
+// LoadTimezone resolves a timezone name such as "Asia/Kolkata". "" and
+// "local" are the system's zone.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q (use an IANA name such as Asia/Kolkata)", name)
+	}
+	return loc, nil
+}
+
+// UseTimezone makes the named zone (see LoadTimezone) the one every date is
+// parsed, formatted and bucketed into days in, by setting time.Local
+func UseTimezone(name string) error {
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		return err
+	}
+	time.Local = loc
+	return nil
+}
+
+// Load initializes and loads the configuration using the environment
+// selected in the config file, if any
 func Load(configFile string) (*Config, error) {
+	return LoadEnvironment(configFile, "")
+}
+
+// LoadEnvironment loads the configuration with the named environment's bend
+// overrides applied. An empty name uses the config's own "environment" key.
+func LoadEnvironment(configFile, environment string) (*Config, error) {
+	return loadFiles(ConfigFiles(configFile), environment)
+}
+
+// LoadFiles loads the configuration from files, lowest precedence first,
+// instead of the ones ConfigFiles finds
+func LoadFiles(files []ConfigFile) (*Config, error) {
+	return loadFiles(files, "")
+}
+
+func loadFiles(files []ConfigFile, environment string) (*Config, error) {
 	v := viper.New()
 
 	// Set defaults
-	setDefaults(v)
-
-	// Set config file
-	if configFile != "" {
-		v.SetConfigFile(configFile)
-	} else {
-		// Set config search paths (local first, then global)
-		v.SetConfigName("config")
-		v.SetConfigType("yaml")
-
-		// Local config paths (like Git's local config)
-		v.AddConfigPath(".fintrack")
-		v.AddConfigPath("./configs")
-		v.AddConfigPath(".")
-
-		// Global config path (like Git's global config)
-		configDir, err := getConfigDir()
-		if err == nil {
-			v.AddConfigPath(configDir)
-		}
+	SetDefaults(v)
+
+	// Environment variable support: FINTRACK_BEND_BASE_URL overrides bend.base_url
+	bindEnv(v)
+
+	// Read the global config, then the project-local one over it (see layers.go)
+	if err := ReadConfigFiles(v, files); err != nil {
+		return nil, err
 	}
 
-	// Environment variable support
-	v.AutomaticEnv()
-	v.SetEnvPrefix("FINTRACK")
+	if err := checkSecretsFromEnv(v); err != nil {
+		return nil, err
+	}
 
-	// Read config file
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
+	// Apply the selected environment's overrides
+	if environment == "" {
+		environment = v.GetString("environment")
+	}
+	if environment != "" {
+		if err := applyEnvironment(v, environment); err != nil {
+			return nil, err
 		}
 	}
 
 	// Unmarshal config
 	var config Config
-	if err := v.Unmarshal(&config); err != nil {
+	if err := Unmarshal(v, &config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	config.sources = traceSources(v, environment, files)
+
 	// Get the directory of the config file used
 	configFileDir := ""
 	if usedConfig := v.ConfigFileUsed(); usedConfig != "" {
@@ -89,22 +246,136 @@ func Load(configFile string) (*Config, error) {
 		return nil, fmt.Errorf("failed to ensure device hash: %w", err)
 	}
 
+	config.defaultSessionFile = config.Bend.SessionFile
+	config.defaultCacheDir = config.Bend.CacheDir
+	if err := config.SelectSession(config.Session); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
-// setDefaults sets default configuration values
-func setDefaults(v *viper.Viper) {
+// Unmarshal decodes v into cfg. Unquoted dates such as "by: 2026-12-31",
+// which YAML reads as timestamps, are accepted for string settings.
+func Unmarshal(v *viper.Viper, cfg *Config) error {
+	return v.Unmarshal(cfg, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		timeToStringHook,
+		// viper's own hooks, which a custom hook replaces
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)))
+}
+
+// timeToStringHook turns a YAML timestamp bound for a string back into
+// YYYY-MM-DD, or RFC 3339 when it has a time of day
+func timeToStringHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	t, ok := data.(time.Time)
+	if !ok || to.Kind() != reflect.String {
+		return data, nil
+	}
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format("2006-01-02"), nil
+	}
+	return t.Format(time.RFC3339), nil
+}
+
+// SetDefaults sets default configuration values
+func SetDefaults(v *viper.Viper) {
 	// Bend defaults
 	v.SetDefault("bend.base_url", "https://bend.example.com")
 	v.SetDefault("bend.rate_limit", "1s")
 	v.SetDefault("bend.timeout", "30s")
 	v.SetDefault("bend.device_type", "Web")
 	v.SetDefault("bend.device_location", "Default")
-
+	v.SetDefault("bend.page_size", 50)
+	v.SetDefault("bend.max_pages", 1000)
+	v.SetDefault("bend.cache_ttl", "5m")
+	v.SetDefault("bend.user_agent", "fintrack/{version}")
+	v.SetDefault("bend.origin", "https://bend.example.com")
+	v.SetDefault("bend.circuit_threshold", 5)
+	v.SetDefault("bend.circuit_cooldown", "5m")
+	v.SetDefault("bend.transport.max_idle_conns", 100)
+	v.SetDefault("bend.transport.max_idle_conns_per_host", 10)
+	v.SetDefault("bend.transport.idle_conn_timeout", "90s")
+	v.SetDefault("bend.transport.keep_alive", "30s")
+	v.SetDefault("bend.transport.force_http2", true)
+
+	// FX defaults
+	v.SetDefault("fx.base_currency", "INR")
+
+	// Money defaults
+	v.SetDefault("money.rounding", string(money.DefaultRounding))
+
+	// Logging defaults
+	v.SetDefault("logging.format", "text")
+
+	// Dates follow the system's timezone unless one is set
+	v.SetDefault("timezone", "")
+
+	// Sync defaults
+	v.SetDefault("sync.days", 30)
+	v.SetDefault("sync.max_age", "25h")
+	v.SetDefault("sync.stale_after", "48h")
+
+	// Bill reminders
+	v.SetDefault("bills.notify_days", 3)
+
+	// Consents take days to renew, so warn well ahead
+	v.SetDefault("consent.notify_days", 14)
+
+	// Time series exports
+	v.SetDefault("timeseries.format", "influx")
+	v.SetDefault("timeseries.days", 30)
+
+	// Balance forecasts
+	v.SetDefault("forecast.days", 60)
+	v.SetDefault("forecast.threshold", 0)
+
+	// Most refunds land within a month of the purchase
+	v.SetDefault("refunds.window_days", 30)
+
+	// Indian fiscal years run April to March
+	v.SetDefault("periods.fiscal_year_start", 4)
+
+	// The store is a JSON file; encrypted store passphrases are cached in the OS keychain
+	v.SetDefault("store.backend", "json")
+	v.SetDefault("store.keychain", true)
+
+	// Display defaults match the built-in output
+	v.SetDefault("display.output", "table")
+	v.SetDefault("display.date_format", "iso")
+	v.SetDefault("display.currency_symbol", "code")
+	v.SetDefault("display.table_style", "ascii")
 }
 
-// getConfigDir returns the configuration directory path
+// DataDirEnv overrides the directory holding the global config and data files.
+// Containers point it at a volume so nothing depends on a home directory.
+const DataDirEnv = "FINTRACK_DATA_DIR"
+
+// getConfigDir returns the configuration directory path: %APPDATA%\fintrack
+// on Windows, ~/.config/fintrack elsewhere
 func getConfigDir() (string, error) {
+	if dir := os.Getenv(DataDirEnv); dir != "" {
+		return dir, nil
+	}
+
+	if runtime.GOOS == "windows" {
+		appData, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(appData, "fintrack"), nil
+	}
+	return legacyDir()
+}
+
+// legacyDir is where earlier releases kept every file on every OS:
+// ~/.config/fintrack, or $FINTRACK_DATA_DIR when set
+func legacyDir() (string, error) {
+	if dir := os.Getenv(DataDirEnv); dir != "" {
+		return dir, nil
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -114,14 +385,111 @@ func getConfigDir() (string, error) {
 
 // expandPaths expands ~ and environment variables in file paths
 func expandPaths(config *Config, configFileDir string) error {
-	var err error
+	err := resolveDirs(config, configFileDir)
+	if err != nil {
+		return err
+	}
 
-	config.Bend.SessionFile, err = expandPath(config.Bend.SessionFile, configFileDir)
+	if config.Receipts.Dir == "" {
+		// Earlier releases kept receipts in ./receipts; keep using one that exists
+		if info, err := os.Stat(legacyReceiptsDir); err == nil && info.IsDir() {
+			config.Receipts.Dir = legacyReceiptsDir
+		} else if config.Paths.DataDir != "" {
+			config.Receipts.Dir = filepath.Join(config.Paths.DataDir, "receipts")
+		} else {
+			config.Receipts.Dir = legacyReceiptsDir
+		}
+	}
+	config.Receipts.Dir, err = expandPath(config.Receipts.Dir, config.keyDir("receipts.dir", configFileDir))
+	if err != nil {
+		return err
+	}
+
+	// Raw responses are archived next to the staged fetches
+	if config.Bend.ArchiveDir == "" {
+		config.Bend.ArchiveDir = filepath.Join(config.Paths.StagingDir, "raw")
+	}
+
+	if config.Bend.SessionFile == "" {
+		if configDir, err := getConfigDir(); err == nil {
+			config.Bend.SessionFile = defaultFile(configDir, "session.json")
+		}
+	}
+	config.Bend.SessionFile, err = expandPath(config.Bend.SessionFile, config.keyDir("bend.session_file", configFileDir))
+	if err != nil {
+		return err
+	}
+
+	if config.Bend.CacheDir == "" {
+		config.Bend.CacheDir = defaultFile(config.Paths.CacheDir, "http-cache")
+	}
+	config.Bend.CacheDir, err = expandPath(config.Bend.CacheDir, config.keyDir("bend.cache_dir", configFileDir))
+	if err != nil {
+		return err
+	}
+
+	if config.Bend.UsageFile == "" {
+		config.Bend.UsageFile = defaultFile(config.Paths.StateDir, "usage.json")
+	}
+	config.Bend.UsageFile, err = expandPath(config.Bend.UsageFile, config.keyDir("bend.usage_file", configFileDir))
+	if err != nil {
+		return err
+	}
+
+	if config.Bend.CircuitFile == "" {
+		config.Bend.CircuitFile = defaultFile(config.Paths.StateDir, "circuit.json")
+	}
+	config.Bend.CircuitFile, err = expandPath(config.Bend.CircuitFile, config.keyDir("bend.circuit_file", configFileDir))
+	if err != nil {
+		return err
+	}
+
+	if config.Telemetry.StatsFile == "" {
+		config.Telemetry.StatsFile = defaultFile(config.Paths.StateDir, "stats.json")
+	}
+	config.Telemetry.StatsFile, err = expandPath(config.Telemetry.StatsFile, config.keyDir("telemetry.stats_file", configFileDir))
+	if err != nil {
+		return err
+	}
+
+	if config.Sync.ReportFile == "" {
+		config.Sync.ReportFile = defaultFile(config.Paths.StateDir, "sync-report.json")
+	}
+	config.Sync.ReportFile, err = expandPath(config.Sync.ReportFile, config.keyDir("sync.report_file", configFileDir))
+	if err != nil {
+		return err
+	}
+
+	if config.Store.Path == "" {
+		config.Store.Path = defaultFile(config.Paths.DataDir, "store.json")
+	}
+	config.Store.Path, err = expandPath(config.Store.Path, config.keyDir("store.path", configFileDir))
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("[config] session_file resolved to: %s\n", config.Bend.SessionFile)
+	if config.Alerts.StateFile == "" {
+		config.Alerts.StateFile = defaultFile(config.Paths.StateDir, "alert-state.json")
+	}
+	config.Alerts.StateFile, err = expandPath(config.Alerts.StateFile, config.keyDir("alerts.state_file", configFileDir))
+	if err != nil {
+		return err
+	}
+
+	for name, job := range config.Reports.Schedule {
+		if job.Path, err = expandPath(job.Path, configFileDir); err != nil {
+			return err
+		}
+		config.Reports.Schedule[name] = job
+	}
+
+	if config.FX.CacheFile == "" {
+		config.FX.CacheFile = defaultFile(config.Paths.CacheDir, "fx_rates.json")
+	}
+	config.FX.CacheFile, err = expandPath(config.FX.CacheFile, config.keyDir("fx.cache_file", configFileDir))
+	if err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -133,8 +501,11 @@ func expandPath(path string, configFileDir string) (string, error) {
 		return path, nil
 	}
 
-	// Expand environment variables
+	// Expand environment variables, and %VAR% on Windows
 	path = os.ExpandEnv(path)
+	if runtime.GOOS == "windows" {
+		path = expandWindowsEnv(path)
+	}
 
 	// Expand ~ to home directory
 	if len(path) > 0 && path[0] == '~' {
@@ -163,6 +534,12 @@ func EnsureConfigDir() error {
 	return os.MkdirAll(configDir, 0755)
 }
 
+// Dir returns the global configuration directory (~/.config/fintrack or
+// %APPDATA%\fintrack, or $FINTRACK_DATA_DIR when set)
+func Dir() (string, error) {
+	return getConfigDir()
+}
+
 // GetConfigFilePath returns the default config file path
 func GetConfigFilePath() (string, error) {
 	configDir, err := getConfigDir()
@@ -178,16 +555,16 @@ func ensureDeviceHash(config *Config) error {
 		return nil // Already has a device hash
 	}
 
-	// Get config directory for storing device hash
-	configDir, err := getConfigDir()
-	if err != nil {
-		// If we can't get config dir, generate a temporary one
+	// Keep it in the state directory
+	path := config.DeviceHashFile()
+	if path == "" {
+		// If there's no directory for it, generate a temporary one
 		config.Bend.DeviceHash = generateDeviceHash()
 		return nil
 	}
 
 	// Try to get or create persistent device hash
-	deviceHash, err := getOrCreateDeviceHash(configDir)
+	deviceHash, err := getOrCreateDeviceHash(path)
 	if err != nil {
 		// If we can't persist, generate a temporary one
 		config.Bend.DeviceHash = generateDeviceHash()
@@ -205,11 +582,7 @@ func generateDeviceHash() string {
 	if err != nil {
 		// Fallback to a deterministic but unique hash based on hostname and user
 		hostname, _ := os.Hostname()
-		user := os.Getenv("USER")
-		if user == "" {
-			user = os.Getenv("USERNAME") // Windows fallback
-		}
-		fallback := fmt.Sprintf("fintrack-%s-%s", hostname, user)
+		fallback := fmt.Sprintf("fintrack-%s-%s", hostname, CurrentUser())
 		// Convert to UUID format (truncate/pad as needed)
 		if len(fallback) < 16 {
 			fallback = fallback + "0000000000000000"
@@ -229,10 +602,8 @@ func generateDeviceHash() string {
 	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
 }
 
-// getOrCreateDeviceHash returns existing device hash from config dir or creates a new one
-func getOrCreateDeviceHash(configDir string) (string, error) {
-	deviceHashFile := filepath.Join(configDir, "device_hash")
-
+// getOrCreateDeviceHash returns the device hash saved in deviceHashFile or creates a new one
+func getOrCreateDeviceHash(deviceHashFile string) (string, error) {
 	// Try to read existing device hash
 	if data, err := os.ReadFile(deviceHashFile); err == nil {
 		deviceHash := string(data)
@@ -244,9 +615,9 @@ func getOrCreateDeviceHash(configDir string) (string, error) {
 	// Generate new device hash
 	deviceHash := generateDeviceHash()
 
-	// Ensure config directory exists
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return deviceHash, fmt.Errorf("failed to create config directory: %w", err)
+	// Ensure the state directory exists
+	if err := os.MkdirAll(filepath.Dir(deviceHashFile), 0755); err != nil {
+		return deviceHash, fmt.Errorf("failed to create state directory: %w", err)
 	}
 
 	// Save device hash to file
@@ -287,3 +658,15 @@ func SetInContext(cmd *cobra.Command, cfg *Config) {
 		}
 	}
 }
+
+// CurrentUser names the user running fintrack, on Windows too, where USER
+// isn't set
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
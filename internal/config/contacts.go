@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContactConfig is an entry in the contact book of UPI counterparties. It's
+// a list rather than a map because VPAs contain dots, which viper would read
+// as nested keys.
+type ContactConfig struct {
+	VPA      string `mapstructure:"vpa"`      // UPI address, e.g. ravi.k@okaxis
+	Name     string `mapstructure:"name"`     // Display name
+	Label    string `mapstructure:"label"`    // Relationship, e.g. flatmate or maid
+	Merchant bool   `mapstructure:"merchant"` // A business paid over UPI, not a person
+}
+
+// ContactFor returns the contact book entry for a VPA, if any
+func (c *Config) ContactFor(vpa string) (ContactConfig, bool) {
+	for _, contact := range c.Contacts {
+		if strings.EqualFold(contact.VPA, vpa) {
+			return contact, true
+		}
+	}
+	return ContactConfig{}, false
+}
+
+// ValidateContacts checks every contact has a VPA and appears once
+func (c *Config) ValidateContacts() error {
+	seen := make(map[string]bool, len(c.Contacts))
+	for i, contact := range c.Contacts {
+		vpa := strings.ToLower(contact.VPA)
+		if !strings.Contains(vpa, "@") {
+			return fmt.Errorf("contacts[%d]: vpa %q is not a UPI address (name@bank)", i, contact.VPA)
+		}
+		if seen[vpa] {
+			return fmt.Errorf("contacts: %s is listed more than once", contact.VPA)
+		}
+		seen[vpa] = true
+	}
+	return nil
+}
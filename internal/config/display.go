@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DisplayConfig represents output preferences shared by every command, so they
+// are set once instead of passed as flags on each run
+type DisplayConfig struct {
+	Output         string `mapstructure:"output"`          // Default --output format: table, json or csv
+	DateFormat     string `mapstructure:"date_format"`     // iso, dmy, mdy or a Go time layout
+	CurrencySymbol string `mapstructure:"currency_symbol"` // code, before, after or none
+	TableStyle     string `mapstructure:"table_style"`     // ascii, plain, markdown or unicode
+}
+
+// Output formats, currency placements and table styles accepted in the display section
+var (
+	OutputFormats      = []string{"table", "json", "csv"}
+	CurrencyPlacements = []string{"code", "before", "after", "none"}
+	TableStyles        = []string{"ascii", "plain", "markdown", "unicode"}
+)
+
+// dateFormatPresets maps the named date formats to Go layouts
+var dateFormatPresets = map[string]string{
+	"iso": "2006-01-02",
+	"dmy": "02/01/2006",
+	"mdy": "01/02/2006",
+}
+
+// DateLayout returns the Go layout for a configured date format
+func DateLayout(format string) string {
+	if format == "" {
+		return dateFormatPresets["iso"]
+	}
+	if layout, ok := dateFormatPresets[strings.ToLower(format)]; ok {
+		return layout
+	}
+	return format
+}
+
+// ValidateDisplayValue checks a display.<field> value
+func ValidateDisplayValue(field, value string) error {
+	value = strings.ToLower(value)
+	switch field {
+	case "output":
+		return oneOf("display.output", value, OutputFormats)
+	case "currency_symbol":
+		return oneOf("display.currency_symbol", value, CurrencyPlacements)
+	case "table_style":
+		return oneOf("display.table_style", value, TableStyles)
+	case "date_format":
+		if _, ok := dateFormatPresets[value]; ok {
+			return nil
+		}
+		// A custom layout must at least contain the year
+		if !strings.Contains(value, "2006") && !strings.Contains(value, "06") {
+			return fmt.Errorf("display.date_format must be iso, dmy, mdy or a Go time layout such as 02 Jan 2006")
+		}
+	}
+	return nil
+}
+
+// Validate checks every display setting
+func (d DisplayConfig) Validate() error {
+	fields := map[string]string{
+		"output":          d.Output,
+		"date_format":     d.DateFormat,
+		"currency_symbol": d.CurrencySymbol,
+		"table_style":     d.TableStyle,
+	}
+	for field, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := ValidateDisplayValue(field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// oneOf reports an error unless value is one of allowed
+func oneOf(key, value string, allowed []string) error {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s must be one of: %s", key, strings.Join(allowed, ", "))
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EntityConfig assigns accounts and individual transactions to a set of books
+// (e.g. "personal" or "llp") so one Bend login can produce per-entity output
+type EntityConfig struct {
+	Accounts     []string `mapstructure:"accounts"`     // Account UUIDs owned by the entity
+	Transactions []string `mapstructure:"transactions"` // Transaction UUIDs overriding their account's entity
+}
+
+// EntityNames returns the configured entity names in sorted order
+func (c *Config) EntityNames() []string {
+	names := make([]string, 0, len(c.Entities))
+	for name := range c.Entities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasEntity reports whether an entity is configured (or is the default entity)
+func (c *Config) HasEntity(name string) bool {
+	name = strings.ToLower(name)
+	if _, ok := c.Entities[name]; ok {
+		return true
+	}
+	return name != "" && name == strings.ToLower(c.DefaultEntity)
+}
+
+// ValidateEntity returns an error if the entity name is not configured
+func (c *Config) ValidateEntity(name string) error {
+	if !c.HasEntity(name) {
+		return fmt.Errorf("unknown entity '%s' (configured: %s)", name, strings.Join(c.EntityNames(), ", "))
+	}
+	return nil
+}
+
+// EntityForAccount returns the entity owning an account, falling back to the default entity
+func (c *Config) EntityForAccount(accountID string) string {
+	for _, name := range c.EntityNames() {
+		for _, id := range c.Entities[name].Accounts {
+			if id == accountID {
+				return name
+			}
+		}
+	}
+	return strings.ToLower(c.DefaultEntity)
+}
+
+// EntityForTransaction resolves a transaction's entity. Explicit transaction
+// assignments win over the entity of the account the transaction belongs to.
+func (c *Config) EntityForTransaction(transactionID, accountID string) string {
+	for _, name := range c.EntityNames() {
+		for _, id := range c.Entities[name].Transactions {
+			if id == transactionID {
+				return name
+			}
+		}
+	}
+	return c.EntityForAccount(accountID)
+}
+
+// EntityFilter returns whether a transaction belongs to the named entity.
+// With no name, every transaction does.
+func (c *Config) EntityFilter(name string) (func(transactionID, accountID string) bool, error) {
+	if name == "" {
+		return func(string, string) bool { return true }, nil
+	}
+	if err := c.ValidateEntity(name); err != nil {
+		return nil, err
+	}
+	name = strings.ToLower(name)
+	return func(transactionID, accountID string) bool {
+		return c.EntityForTransaction(transactionID, accountID) == name
+	}, nil
+}
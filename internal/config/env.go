@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix is prepended to every environment variable override
+const EnvPrefix = "FINTRACK"
+
+// envKeyReplacer maps nested keys to variable names: bend.base_url → BEND_BASE_URL
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
+// secretKeys are only accepted from the environment when secrets_from_env is on
+var secretKeys = []string{"refresh_token", "password"}
+
+// EnvVar returns the environment variable that overrides a config key
+func EnvVar(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(envKeyReplacer.Replace(key))
+}
+
+// EnvKeys lists every fixed config key that can be overridden from the
+// environment, in sorted order. Keys inside maps (entities, exports,
+// environments) follow the same naming but only apply once the entry exists
+// in the config file.
+func EnvKeys() []string {
+	keys := structKeys(reflect.TypeOf(Config{}), "")
+	sort.Strings(keys)
+	return keys
+}
+
+// bindEnv makes every config key overridable from its FINTRACK_ variable.
+// AutomaticEnv alone only resolves keys viper already knows about, so keys
+// without a default or a value in the file would otherwise be missed on Unmarshal.
+func bindEnv(v *viper.Viper) {
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(envKeyReplacer)
+	v.AutomaticEnv()
+
+	for _, key := range EnvKeys() {
+		v.BindEnv(key)
+	}
+}
+
+// checkSecretsFromEnv rejects secrets stored in the config file when
+// secrets_from_env is enabled, so a token that slipped into the file is noticed
+func checkSecretsFromEnv(v *viper.Viper) error {
+	if !v.GetBool("secrets_from_env") {
+		return nil
+	}
+
+	sections := []string{"bend", "alerts.smtp"}
+	for _, name := range environmentNames(v) {
+		sections = append(sections, "environments."+name)
+	}
+
+	for _, section := range sections {
+		for _, secret := range secretKeys {
+			key := section + "." + secret
+			if v.InConfig(key) {
+				return fmt.Errorf("%s is set in the config file but secrets_from_env is enabled; remove it and set %s instead", key, EnvVar(key))
+			}
+		}
+	}
+	return nil
+}
+
+// IsSecretKey reports whether a dotted config key holds a secret
+func IsSecretKey(key string) bool {
+	for _, secret := range secretKeys {
+		if key == secret || strings.HasSuffix(key, "."+secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// structKeys collects the dotted mapstructure keys of a struct's leaf fields
+func structKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			keys = append(keys, structKeys(field.Type, key)...)
+		case reflect.Map:
+			// Map entries are user-named and can't be bound up front
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
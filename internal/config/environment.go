@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvironmentConfig overrides bend settings for one environment (e.g. a sandbox).
+// Unset fields fall back to the bend section.
+type EnvironmentConfig struct {
+	BaseURL        string            `mapstructure:"base_url"`
+	RefreshToken   string            `mapstructure:"refresh_token"`
+	SessionFile    string            `mapstructure:"session_file"`
+	DeviceHash     string            `mapstructure:"device_hash"`
+	DeviceType     string            `mapstructure:"device_type"`
+	DeviceLocation string            `mapstructure:"device_location"`
+	RateLimit      string            `mapstructure:"rate_limit"`
+	Timeout        string            `mapstructure:"timeout"`
+	UserAgent      string            `mapstructure:"user_agent"`
+	Origin         string            `mapstructure:"origin"`
+	Headers        map[string]string `mapstructure:"headers"`
+}
+
+// environmentKeys are the bend settings an environment may override
+var environmentKeys = []string{
+	"base_url", "refresh_token", "session_file",
+	"device_hash", "device_type", "device_location",
+	"rate_limit", "timeout",
+	"user_agent", "origin", "headers",
+}
+
+// applyEnvironment overlays environments.<name> onto the bend section. An
+// environment without its own session_file gets one derived from the default
+// (session.json → session-sandbox.json) so sessions never mix.
+func applyEnvironment(v *viper.Viper, name string) error {
+	name = strings.ToLower(name)
+	envKey := "environments." + name
+	if !v.IsSet(envKey) {
+		return fmt.Errorf("unknown environment '%s' (configured: %s)", name, strings.Join(environmentNames(v), ", "))
+	}
+
+	for _, key := range environmentKeys {
+		if v.IsSet(envKey + "." + key) {
+			v.Set("bend."+key, v.Get(envKey+"."+key))
+		}
+	}
+
+	if !v.IsSet(envKey + ".session_file") {
+		if sessionFile := v.GetString("bend.session_file"); sessionFile != "" {
+			ext := filepath.Ext(sessionFile)
+			v.Set("bend.session_file", strings.TrimSuffix(sessionFile, ext)+"-"+name+ext)
+		}
+	}
+
+	v.Set("environment", name)
+	return nil
+}
+
+// environmentNames lists the configured environments in sorted order
+func environmentNames(v *viper.Viper) []string {
+	var names []string
+	for name := range v.GetStringMap("environments") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BendKeyPrefix returns where the active environment's bend settings live,
+// "bend" or "environments.<name>", for commands that write them back
+func (c *Config) BendKeyPrefix() string {
+	if c.Environment == "" {
+		return "bend"
+	}
+	return "environments." + c.Environment
+}
@@ -0,0 +1,29 @@
+package config
+
+import "sort"
+
+// ExportTarget configures one destination for 'fintrack export'
+type ExportTarget struct {
+	Type      string            `mapstructure:"type"`       // csv, ndjson, qif, gnucash, webhook or plugin
+	Path      string            `mapstructure:"path"`       // Output file (csv, ndjson, qif, gnucash)
+	URL       string            `mapstructure:"url"`        // Endpoint (webhook)
+	BatchSize int               `mapstructure:"batch_size"` // Transactions per webhook request
+	Headers   map[string]string `mapstructure:"headers"`    // Extra webhook request headers
+	Plugin    string            `mapstructure:"plugin"`     // Name under plugins (plugin)
+	Compress  string            `mapstructure:"compress"`   // Webhook request bodies: none (default), gzip, or auto (gzip unless the server rejects it)
+	Entity    string            `mapstructure:"entity"`     // Only export this entity's transactions
+
+	// Incremental targets only receive transactions added since their last
+	// successful export (files are appended to)
+	Incremental bool `mapstructure:"incremental"`
+}
+
+// ExportNames returns the configured export target names in sorted order
+func (c *Config) ExportNames() []string {
+	names := make([]string, 0, len(c.Exports))
+	for name := range c.Exports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,26 @@
+package config
+
+import "fmt"
+
+// ForecastConfig controls 'fintrack forecast'
+type ForecastConfig struct {
+	Days      int     `mapstructure:"days"`      // How many days ahead to project
+	Threshold float64 `mapstructure:"threshold"` // Warn when a projected balance drops below this
+}
+
+// ValidateForecast checks the forecast settings
+func (c *Config) ValidateForecast() error {
+	if c.Forecast.Days < 0 {
+		return fmt.Errorf("forecast.days cannot be negative")
+	}
+	return nil
+}
+
+// LowBalanceFor returns the balance below which an account's forecast warns:
+// its low_balance setting, or forecast.threshold
+func (c *Config) LowBalanceFor(accountID string) float64 {
+	if settings, ok := c.AccountSettingsFor(accountID); ok && settings.LowBalance != nil {
+		return *settings.LowBalance
+	}
+	return c.Forecast.Threshold
+}
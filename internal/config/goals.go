@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// GoalConfig is a savings goal, e.g. save 50,000 by December. Progress comes
+// from the net flow into its accounts, or the outgoing payments in its
+// categories (such as an investments category), since StartDate.
+type GoalConfig struct {
+	Target     float64  `mapstructure:"target"`     // Amount to save, in fx.base_currency
+	By         string   `mapstructure:"by"`         // YYYY-MM-DD deadline
+	StartDate  string   `mapstructure:"start_date"` // YYYY-MM-DD when saving started
+	Accounts   []string `mapstructure:"accounts"`   // Account UUIDs whose net inflow counts
+	Categories []string `mapstructure:"categories"` // Category IDs whose payments count
+	Entity     string   `mapstructure:"entity"`     // Only count this entity's transactions
+}
+
+// Period returns the parsed start date and deadline of a goal
+func (g GoalConfig) Period() (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01-02", g.StartDate, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start_date %q (use YYYY-MM-DD)", g.StartDate)
+	}
+	by, err := time.ParseInLocation("2006-01-02", g.By, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid by date %q (use YYYY-MM-DD)", g.By)
+	}
+	if !by.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("by date %s must be after start_date %s", g.By, g.StartDate)
+	}
+	return start, by, nil
+}
+
+// Validate checks a goal can be tracked
+func (g GoalConfig) Validate() error {
+	if g.Target <= 0 {
+		return fmt.Errorf("target must be positive")
+	}
+	if len(g.Accounts) == 0 && len(g.Categories) == 0 {
+		return fmt.Errorf("needs at least one account or category")
+	}
+	_, _, err := g.Period()
+	return err
+}
+
+// ValidateGoals checks every configured goal
+func (c *Config) ValidateGoals() error {
+	for name, goal := range c.Goals {
+		if err := goal.Validate(); err != nil {
+			return fmt.Errorf("goals.%s: %w", name, err)
+		}
+		if goal.Entity != "" {
+			if err := c.ValidateEntity(goal.Entity); err != nil {
+				return fmt.Errorf("goals.%s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
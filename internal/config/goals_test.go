@@ -0,0 +1,38 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestUnmarshalUnquotedDates(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		wantBy    string
+		wantStart string
+	}{
+		{"quoted", "goals:\n  car:\n    by: \"2026-12-31\"\n    start_date: \"2026-01-01\"\n", "2026-12-31", "2026-01-01"},
+		{"unquoted", "goals:\n  car:\n    by: 2026-12-31\n    start_date: 2026-01-01\n", "2026-12-31", "2026-01-01"},
+		{"with a time", "goals:\n  car:\n    by: 2026-12-31T18:30:00Z\n    start_date: 2026-01-01\n", "2026-12-31T18:30:00Z", "2026-01-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := viper.New()
+			v.SetConfigType("yaml")
+			if err := v.ReadConfig(strings.NewReader(tt.config)); err != nil {
+				t.Fatal(err)
+			}
+			var cfg Config
+			if err := Unmarshal(v, &cfg); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			goal := cfg.Goals["car"]
+			if goal.By != tt.wantBy || goal.StartDate != tt.wantStart {
+				t.Errorf("by, start_date = %q, %q, want %q, %q", goal.By, goal.StartDate, tt.wantBy, tt.wantStart)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ImporterConfig declares a custom importer for 'fintrack import', tried
+// before the built-in formats
+type ImporterConfig struct {
+	Type  string   `mapstructure:"type"`  // csv (column mapping) or command (external parser)
+	Match []string `mapstructure:"match"` // Filename globs (e.g. "hdfc-*.csv") or text near the start of the file
+
+	// csv
+	Columns    CSVColumns `mapstructure:"columns"`     // Header names of the columns
+	DateFormat string     `mapstructure:"date_format"` // Go layout, e.g. "02/01/2006"
+	Delimiter  string     `mapstructure:"delimiter"`   // Sniffed when unset
+
+	// command: gets the file on stdin and writes a JSON array of transactions
+	Command string `mapstructure:"command"`
+}
+
+// CSVColumns maps transaction fields to CSV header names. Either amount
+// (signed, or unsigned with indicator) or debit and credit is required.
+type CSVColumns struct {
+	Date      string `mapstructure:"date"`
+	Narration string `mapstructure:"narration"`
+	Amount    string `mapstructure:"amount"`
+	Debit     string `mapstructure:"debit"`
+	Credit    string `mapstructure:"credit"`
+	Indicator string `mapstructure:"indicator"` // Dr/Cr column giving the sign of amount
+	Reference string `mapstructure:"reference"`
+}
+
+// ImporterNames returns the custom importer names in sorted order
+func (c *Config) ImporterNames() []string {
+	names := make([]string, 0, len(c.Importers))
+	for name := range c.Importers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateImporters checks each custom importer has what its type needs
+func (c *Config) ValidateImporters() error {
+	for _, name := range c.ImporterNames() {
+		imp := c.Importers[name]
+		switch strings.ToLower(imp.Type) {
+		case "csv":
+			if imp.Columns.Date == "" {
+				return fmt.Errorf("importers.%s: columns.date is required", name)
+			}
+			if imp.Columns.Amount == "" && (imp.Columns.Debit == "" || imp.Columns.Credit == "") {
+				return fmt.Errorf("importers.%s: columns.amount, or columns.debit and columns.credit, are required", name)
+			}
+			if len([]rune(imp.Delimiter)) > 1 {
+				return fmt.Errorf("importers.%s: delimiter must be a single character", name)
+			}
+		case "command":
+			if imp.Command == "" {
+				return fmt.Errorf("importers.%s: command is required", name)
+			}
+		default:
+			return fmt.Errorf("importers.%s: unknown type %q (use csv or command)", name, imp.Type)
+		}
+	}
+	return nil
+}
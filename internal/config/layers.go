@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// Settings are layered like git's: defaults < global config < project-local
+// config < FINTRACK_* variables < flags. A local .fintrack/config.yaml only
+// needs the keys it changes (a staging directory, say) and inherits the rest,
+// tokens included, from the global one.
+
+// localConfigDirs are searched in order for a project-local config file
+var localConfigDirs = []string{".fintrack", "./configs", "."}
+
+// ConfigFile is one config file a load reads
+type ConfigFile struct {
+	Path   string
+	Source string // SourceGlobal, SourceLocal, or SourceFile for one named explicitly
+}
+
+// ConfigFiles returns the config files to read, lowest precedence first: the
+// global one and the project-local one when they exist, or just configFile
+// when one is named
+func ConfigFiles(configFile string) []ConfigFile {
+	if configFile != "" {
+		return []ConfigFile{{Path: configFile, Source: SourceFile}}
+	}
+
+	var globalDirs []string
+	if dir, err := getConfigDir(); err == nil {
+		globalDirs = append(globalDirs, dir)
+	}
+	// Where earlier releases looked on Windows
+	if legacy, err := legacyDir(); err == nil && (len(globalDirs) == 0 || legacy != globalDirs[0]) {
+		globalDirs = append(globalDirs, legacy)
+	}
+
+	var files []ConfigFile
+	global := findConfigFile(globalDirs)
+	if global != "" {
+		files = append(files, ConfigFile{Path: global, Source: SourceGlobal})
+	}
+	// Run from the global config directory, "." finds the global file again
+	if local := findConfigFile(localConfigDirs); local != "" && !sameFile(local, global) {
+		files = append(files, ConfigFile{Path: local, Source: SourceLocal})
+	}
+	return files
+}
+
+// CredentialsFile is the config file credentials like keys are saved to:
+// configFile when one is named, the layer already setting one of keys, or
+// else the global config, created if need be. A project-local file, which
+// may well be committed, only gets a token it already holds.
+func CredentialsFile(configFile string, keys ...string) (string, error) {
+	files := ConfigFiles(configFile)
+	for i := len(files) - 1; i >= 0; i-- {
+		if files[i].Source == SourceFile {
+			return files[i].Path, nil
+		}
+		layer := viper.New()
+		layer.SetConfigFile(files[i].Path)
+		if err := layer.ReadInConfig(); err != nil {
+			return "", fmt.Errorf("failed to read config file %s: %w", files[i].Path, err)
+		}
+		for _, key := range keys {
+			if layer.InConfig(key) {
+				return files[i].Path, nil
+			}
+		}
+	}
+	for _, file := range files {
+		if file.Source == SourceGlobal {
+			return file.Path, nil
+		}
+	}
+
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// commandKeys are the settings, under each named entry of a section, that
+// fintrack runs as shell commands
+var commandKeys = []struct{ section, key string }{
+	{"plugins", "command"},
+	{"alerts.channels", "command"},
+	{"importers", "command"},
+}
+
+// ReadConfigFiles reads files into v, each overriding the ones before it.
+// A project-local file can't set commands: it comes with whatever directory
+// fintrack runs in, a cloned repository say, and would otherwise run its
+// commands on the next sync.
+func ReadConfigFiles(v *viper.Viper, files []ConfigFile) error {
+	for i, file := range files {
+		if file.Source == SourceLocal {
+			if err := checkLocalCommands(file.Path); err != nil {
+				return err
+			}
+		}
+		v.SetConfigFile(file.Path)
+		read := v.MergeInConfig
+		if i == 0 {
+			read = v.ReadInConfig
+		}
+		if err := read(); err != nil {
+			return fmt.Errorf("failed to read config file %s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
+// checkLocalCommands fails when the project-local config at path sets a
+// command
+func checkLocalCommands(path string) error {
+	layer := viper.New()
+	layer.SetConfigFile(path)
+	if err := layer.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	for _, command := range commandKeys {
+		for name := range layer.GetStringMap(command.section) {
+			key := command.section + "." + name + "." + command.key
+			if layer.InConfig(key) {
+				return fmt.Errorf("%s: %s runs a command, which is only read from the global config or a file given with --config/FINTRACK_CONFIG; move it there", path, key)
+			}
+		}
+	}
+	return nil
+}
+
+// findConfigFile returns the first config.<ext> in dirs, or empty when there
+// is none
+func findConfigFile(dirs []string) string {
+	if len(dirs) == 0 {
+		return ""
+	}
+	finder := viper.New()
+	finder.SetConfigName("config")
+	finder.SetConfigType("yaml")
+	for _, dir := range dirs {
+		finder.AddConfigPath(dir)
+	}
+	// A file that fails to parse is still returned, so reading it reports why
+	if _, notFound := finder.ReadInConfig().(viper.ConfigFileNotFoundError); notFound {
+		return ""
+	}
+	return finder.ConfigFileUsed()
+}
+
+// sameFile reports whether two paths name the same existing file
+func sameFile(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// keyDir is the directory of the config file that set key, against which a
+// relative path in it is resolved, or fallback when no file set it
+func (c *Config) keyDir(key, fallback string) string {
+	switch src := c.sources[key]; src.source {
+	case SourceFile, SourceGlobal, SourceLocal:
+		return filepath.Dir(src.origin)
+	}
+	return fallback
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckLocalCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{"no commands", "store:\n  backend: json\n", false},
+		{"plugin options only", "plugins:\n  categorize:\n    options:\n      model: small\n", false},
+		{"webhook channel", "alerts:\n  channels:\n    phone:\n      type: webhook\n      url: https://example.com\n", false},
+		{"plugin command", "plugins:\n  evil:\n    command: touch /tmp/x\n    enrich: true\n", true},
+		{"alert command", "alerts:\n  channels:\n    ops:\n      type: command\n      command: logger hi\n", true},
+		{"importer command", "importers:\n  bank:\n    type: command\n    command: ./parse\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := checkLocalCommands(path); (err != nil) != tt.wantErr {
+				t.Errorf("checkLocalCommands() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import "strings"
+
+// LedgerConfig maps Bend accounts and categories to the account names of
+// double-entry tools (GnuCash, QIF categories). Every exporter writing
+// accounting data shares it, so a mapping is configured once.
+type LedgerConfig struct {
+	Accounts       map[string]string `mapstructure:"accounts"`        // Bend account UUID → account name
+	Categories     map[string]string `mapstructure:"categories"`      // Category ID or "category/subcategory" → account name
+	DefaultAccount string            `mapstructure:"default_account"` // For unmapped Bend accounts
+	DefaultExpense string            `mapstructure:"default_expense"` // For unmapped outgoing transactions
+	DefaultIncome  string            `mapstructure:"default_income"`  // For unmapped incoming transactions
+}
+
+// AccountName returns the mapped name of a Bend account
+func (l LedgerConfig) AccountName(accountID string) string {
+	if name, ok := l.Accounts[strings.ToLower(accountID)]; ok && name != "" {
+		return name
+	}
+	if l.DefaultAccount != "" {
+		return l.DefaultAccount
+	}
+	return "Assets:Bank"
+}
+
+// CategoryName returns the mapped account of a transaction's category. The
+// most specific mapping wins: "category/subcategory", then "category".
+func (l LedgerConfig) CategoryName(categoryID, subcategoryID string, incoming bool) string {
+	categoryID, subcategoryID = strings.ToLower(categoryID), strings.ToLower(subcategoryID)
+	if subcategoryID != "" {
+		if name, ok := l.Categories[categoryID+"/"+subcategoryID]; ok && name != "" {
+			return name
+		}
+	}
+	if name, ok := l.Categories[categoryID]; ok && name != "" && categoryID != "" {
+		return name
+	}
+
+	if incoming {
+		if l.DefaultIncome != "" {
+			return l.DefaultIncome
+		}
+		return "Income:Uncategorized"
+	}
+	if l.DefaultExpense != "" {
+		return l.DefaultExpense
+	}
+	return "Expenses:Uncategorized"
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+)
+
+// legacyStagingDir is where earlier releases staged fetches (staging.DefaultDir,
+// which can't be imported here)
+const legacyStagingDir = "./staging"
+
+// legacyReceiptsDir is where earlier releases kept receipts
+const legacyReceiptsDir = "./receipts"
+
+// PathsConfig overrides where fintrack keeps its files. Empty directories
+// follow the XDG base directory spec, under a "fintrack" subdirectory:
+//
+//	data   $XDG_DATA_HOME   (~/.local/share)  store, staging
+//	cache  $XDG_CACHE_HOME  (~/.cache)        HTTP and FX rate caches
+//	state  $XDG_STATE_HOME  (~/.local/state)  usage, circuit, sync report, alert state, stats, device hash
+//
+// On Windows, unless the XDG variables are set, data goes to
+// %APPDATA%\fintrack\data and cache and state to %LOCALAPPDATA%\fintrack.
+// FINTRACK_DATA_DIR puts all three in one directory, as before. Files are
+// still found in ~/.config/fintrack, where earlier releases kept them, until
+// they're moved; explicit file settings (store.path, ...) win over all of it.
+type PathsConfig struct {
+	DataDir    string `mapstructure:"data_dir"`
+	CacheDir   string `mapstructure:"cache_dir"`
+	StateDir   string `mapstructure:"state_dir"`
+	StagingDir string `mapstructure:"staging_dir"` // Default: ./staging if it exists, else <data_dir>/staging
+}
+
+// Kinds of directory, for resolveDir
+const (
+	dirData  = "data"
+	dirCache = "cache"
+	dirState = "state"
+)
+
+// xdgDirs are each kind's XDG variable and its default under the home directory
+var xdgDirs = map[string]struct {
+	env  string
+	home string
+}{
+	dirData:  {"XDG_DATA_HOME", filepath.Join(".local", "share")},
+	dirCache: {"XDG_CACHE_HOME", ".cache"},
+	dirState: {"XDG_STATE_HOME", filepath.Join(".local", "state")},
+}
+
+// resolveDirs fills in the data, cache, state and staging directories
+func resolveDirs(config *Config, configFileDir string) error {
+	for _, dir := range []struct {
+		kind string
+		key  string
+		path *string
+	}{
+		{dirData, "paths.data_dir", &config.Paths.DataDir},
+		{dirCache, "paths.cache_dir", &config.Paths.CacheDir},
+		{dirState, "paths.state_dir", &config.Paths.StateDir},
+	} {
+		if *dir.path == "" {
+			*dir.path = defaultDir(dir.kind)
+			continue
+		}
+		expanded, err := expandPath(*dir.path, config.keyDir(dir.key, configFileDir))
+		if err != nil {
+			return err
+		}
+		*dir.path = expanded
+	}
+
+	if config.Paths.StagingDir == "" {
+		// Earlier releases staged into ./staging; keep using one that exists
+		if info, err := os.Stat(legacyStagingDir); err == nil && info.IsDir() {
+			config.Paths.StagingDir = legacyStagingDir
+		} else if config.Paths.DataDir != "" {
+			config.Paths.StagingDir = filepath.Join(config.Paths.DataDir, "staging")
+		} else {
+			config.Paths.StagingDir = legacyStagingDir
+		}
+		return nil
+	}
+	var err error
+	config.Paths.StagingDir, err = expandPath(config.Paths.StagingDir, config.keyDir("paths.staging_dir", configFileDir))
+	return err
+}
+
+// ValidatePaths checks that the directories aren't files
+func (c *Config) ValidatePaths() error {
+	for _, dir := range []struct{ key, path string }{
+		{"paths.data_dir", c.Paths.DataDir},
+		{"paths.cache_dir", c.Paths.CacheDir},
+		{"paths.state_dir", c.Paths.StateDir},
+		{"paths.staging_dir", c.Paths.StagingDir},
+		{"receipts.dir", c.Receipts.Dir},
+	} {
+		if info, err := os.Stat(dir.path); err == nil && !info.IsDir() {
+			return fmt.Errorf("%s: %s is a file, not a directory", dir.key, dir.path)
+		}
+	}
+	return nil
+}
+
+// DeviceHashFile is where the generated device hash is kept
+func (c *Config) DeviceHashFile() string {
+	return defaultFile(c.Paths.StateDir, "device_hash")
+}
+
+// defaultDir is the XDG directory of a kind, or FINTRACK_DATA_DIR when set.
+// It is empty when there is no home directory either.
+func defaultDir(kind string) string {
+	if dir := os.Getenv(DataDirEnv); dir != "" {
+		return dir
+	}
+	xdg := xdgDirs[kind]
+	if base := os.Getenv(xdg.env); filepath.IsAbs(base) {
+		return filepath.Join(base, "fintrack")
+	}
+	if runtime.GOOS == "windows" {
+		return windowsDir(kind)
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, xdg.home, "fintrack")
+}
+
+// windowsDir is a kind's directory under %APPDATA% (roaming data) or
+// %LOCALAPPDATA% (cache and state, which belong to one machine)
+func windowsDir(kind string) string {
+	base, err := os.UserCacheDir() // %LOCALAPPDATA%
+	if kind == dirData {
+		base, err = os.UserConfigDir() // %APPDATA%
+	}
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "fintrack", kind)
+}
+
+// windowsEnvPattern matches a %VAR% reference
+var windowsEnvPattern = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_()]*)%`)
+
+// expandWindowsEnv expands %VAR% references to set variables, leaving unset
+// ones as written like cmd.exe does
+func expandWindowsEnv(path string) string {
+	return windowsEnvPattern.ReplaceAllStringFunc(path, func(ref string) string {
+		if value, ok := os.LookupEnv(ref[1 : len(ref)-1]); ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// defaultFile is name inside dir, unless only the directory earlier releases
+// kept every file in has it
+func defaultFile(dir, name string) string {
+	legacy, err := legacyDir()
+	if dir == "" {
+		if err != nil {
+			return ""
+		}
+		dir = legacy
+	}
+	path := filepath.Join(dir, name)
+	if err != nil || legacy == dir {
+		return path
+	}
+	if legacy := filepath.Join(legacy, name); !exists(path) && exists(legacy) {
+		return legacy
+	}
+	return path
+}
+
+// exists reports whether path exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// setHome points the home directory at dir on every OS
+func setHome(t *testing.T, dir string) {
+	t.Helper()
+	t.Setenv("HOME", dir)
+	t.Setenv("USERPROFILE", dir)
+	t.Setenv(DataDirEnv, "")
+}
+
+func TestExpandWindowsEnv(t *testing.T) {
+	t.Setenv("FT_TEST_DIR", `C:\Users\me`)
+	t.Setenv("FT_TEST_EMPTY", "")
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"plain", `C:\data\store.json`, `C:\data\store.json`},
+		{"variable", `%FT_TEST_DIR%\fintrack`, `C:\Users\me\fintrack`},
+		{"twice", `%FT_TEST_DIR%\%FT_TEST_DIR%`, `C:\Users\me\C:\Users\me`},
+		{"empty variable", `%FT_TEST_EMPTY%\x`, `\x`},
+		{"unset left as written", `%FT_TEST_UNSET%\x`, `%FT_TEST_UNSET%\x`},
+		{"lone percent", `100%\x`, `100%\x`},
+		{"not a name", `%1%\x`, `%1%\x`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandWindowsEnv(tt.path); got != tt.want {
+				t.Errorf("expandWindowsEnv(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowsDir(t *testing.T) {
+	roaming, local := t.TempDir(), t.TempDir()
+	if runtime.GOOS == "windows" {
+		t.Setenv("APPDATA", roaming)
+		t.Setenv("LOCALAPPDATA", local)
+	} else {
+		// os.UserConfigDir and os.UserCacheDir follow XDG off Windows
+		t.Setenv("XDG_CONFIG_HOME", roaming)
+		t.Setenv("XDG_CACHE_HOME", local)
+	}
+
+	tests := []struct {
+		kind string
+		want string
+	}{
+		{dirData, filepath.Join(roaming, "fintrack", dirData)},
+		{dirCache, filepath.Join(local, "fintrack", dirCache)},
+		{dirState, filepath.Join(local, "fintrack", dirState)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			if got := windowsDir(tt.kind); got != tt.want {
+				t.Errorf("windowsDir(%q) = %q, want %q", tt.kind, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLegacyDir(t *testing.T) {
+	home := t.TempDir()
+	setHome(t, home)
+
+	got, err := legacyDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, ".config", "fintrack"); got != want {
+		t.Errorf("legacyDir() = %q, want %q", got, want)
+	}
+
+	override := t.TempDir()
+	t.Setenv(DataDirEnv, override)
+	if got, _ := legacyDir(); got != override {
+		t.Errorf("legacyDir() with %s = %q, want %q", DataDirEnv, got, override)
+	}
+}
+
+func TestDefaultFile(t *testing.T) {
+	home := t.TempDir()
+	setHome(t, home)
+	legacy := filepath.Join(home, ".config", "fintrack")
+	dir := filepath.Join(home, "new")
+	for _, d := range []string{legacy, dir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	touch := func(path string) {
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	touch(filepath.Join(legacy, "only-legacy.json"))
+	touch(filepath.Join(legacy, "both.json"))
+	touch(filepath.Join(dir, "both.json"))
+
+	tests := []struct {
+		name string
+		dir  string
+		file string
+		want string
+	}{
+		{"neither exists", dir, "store.json", filepath.Join(dir, "store.json")},
+		{"only in legacy directory", dir, "only-legacy.json", filepath.Join(legacy, "only-legacy.json")},
+		{"new location wins", dir, "both.json", filepath.Join(dir, "both.json")},
+		{"no directory", "", "store.json", filepath.Join(legacy, "store.json")},
+		{"directory is the legacy one", legacy, "store.json", filepath.Join(legacy, "store.json")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultFile(tt.dir, tt.file); got != tt.want {
+				t.Errorf("defaultFile(%q, %q) = %q, want %q", tt.dir, tt.file, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PeriodsConfig defines the fiscal year and named periods that --time-filter
+// and --period accept
+type PeriodsConfig struct {
+	FiscalYearStart int               `mapstructure:"fiscal_year_start"` // Month the fiscal year starts in, 1-12 (4 = April)
+	Custom          map[string]string `mapstructure:"custom"`            // Name → "YYYY-MM-DD..YYYY-MM-DD" or another period name
+}
+
+// fiscalPattern matches fy2024, fy24, fy2024q1, fy2024-q1 and q1
+var fiscalPattern = regexp.MustCompile(`^(?:fy(\d{2}|\d{4}))?-?(?:q([1-4]))?$`)
+
+// Resolve returns the inclusive range of a named period as of now, in the
+// user's timezone:
+//
+//	fy, this_fy, last_fy      the current or previous fiscal year
+//	fy2024, fy24              the fiscal year ending in 2024 (the calendar
+//	                          year when fiscal_year_start is 1)
+//	q1-q4, fy2024q1           a quarter of the current or a given fiscal year
+//	this_quarter, last_quarter
+//
+// and the names under custom. ok is false for names it doesn't know, such as
+// the API's own time filters (this_month, last_month).
+func (p PeriodsConfig) Resolve(name string, now time.Time) (from, to time.Time, ok bool, err error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if value, found := p.custom(name); found {
+		from, to, err = p.resolveCustom(name, value, now)
+		return from, to, err == nil, err
+	}
+	from, to, ok = p.builtin(name, now)
+	return from, to, ok, nil
+}
+
+// Validate checks the fiscal year start and that every custom period resolves
+func (p PeriodsConfig) Validate() error {
+	if p.FiscalYearStart < 1 || p.FiscalYearStart > 12 {
+		return fmt.Errorf("periods.fiscal_year_start must be a month from 1 to 12")
+	}
+	now := time.Now()
+	for name, value := range p.Custom {
+		if _, _, ok := p.builtin(strings.ToLower(name), now); ok {
+			return fmt.Errorf("periods.custom.%s: %s is a built-in period", name, name)
+		}
+		if _, _, err := p.resolveCustom(name, value, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// custom looks a custom period up, ignoring case
+func (p PeriodsConfig) custom(name string) (string, bool) {
+	for key, value := range p.Custom {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// resolveCustom parses a custom period: a date range or a built-in name
+func (p PeriodsConfig) resolveCustom(name, value string, now time.Time) (time.Time, time.Time, error) {
+	if start, end, found := strings.Cut(value, ".."); found {
+		from, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(start), time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("periods.custom.%s: invalid start date %q (use YYYY-MM-DD)", name, start)
+		}
+		to, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(end), time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("periods.custom.%s: invalid end date %q (use YYYY-MM-DD)", name, end)
+		}
+		if to.Before(from) {
+			return time.Time{}, time.Time{}, fmt.Errorf("periods.custom.%s: ends before it starts", name)
+		}
+		return from, endOfDay(to), nil
+	}
+
+	from, to, ok := p.builtin(strings.ToLower(strings.TrimSpace(value)), now)
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("periods.custom.%s: %q is neither a YYYY-MM-DD..YYYY-MM-DD range nor a built-in period", name, value)
+	}
+	return from, to, nil
+}
+
+// builtin resolves the fiscal year and quarter names
+func (p PeriodsConfig) builtin(name string, now time.Time) (time.Time, time.Time, bool) {
+	now = now.In(time.Local)
+	current := p.fiscalYear(now)
+
+	switch name {
+	case "fy", "this_fy":
+		return p.fiscalRange(current, 0)
+	case "last_fy":
+		return p.fiscalRange(current-1, 0)
+	case "this_quarter", "last_quarter":
+		quarter := p.quarterOf(now)
+		year := current
+		if name == "last_quarter" {
+			if quarter--; quarter == 0 {
+				quarter, year = 4, year-1
+			}
+		}
+		return p.fiscalRange(year, quarter)
+	case "", "-":
+		return time.Time{}, time.Time{}, false
+	}
+
+	match := fiscalPattern.FindStringSubmatch(name)
+	if match == nil || match[1] == "" && match[2] == "" {
+		return time.Time{}, time.Time{}, false
+	}
+	year := current
+	if match[1] != "" {
+		year, _ = strconv.Atoi(match[1])
+		if len(match[1]) == 2 {
+			year += 2000
+		}
+	}
+	quarter := 0
+	if match[2] != "" {
+		quarter, _ = strconv.Atoi(match[2])
+	}
+	return p.fiscalRange(year, quarter)
+}
+
+// fiscalYear is the fiscal year t falls in, named by the calendar year it ends in
+func (p PeriodsConfig) fiscalYear(t time.Time) int {
+	if p.startMonth() == 1 || int(t.Month()) < p.startMonth() {
+		return t.Year()
+	}
+	return t.Year() + 1
+}
+
+// quarterOf is the fiscal quarter t falls in, 1-4
+func (p PeriodsConfig) quarterOf(t time.Time) int {
+	months := (int(t.Month()) - p.startMonth() + 12) % 12
+	return months/3 + 1
+}
+
+// fiscalRange is fiscal year year, or its quarter when quarter is 1-4
+func (p PeriodsConfig) fiscalRange(year, quarter int) (time.Time, time.Time, bool) {
+	startYear := year
+	if p.startMonth() != 1 {
+		startYear--
+	}
+	from := time.Date(startYear, time.Month(p.startMonth()), 1, 0, 0, 0, 0, time.Local)
+	months := 12
+	if quarter > 0 {
+		from = from.AddDate(0, 3*(quarter-1), 0)
+		months = 3
+	}
+	return from, from.AddDate(0, months, 0).Add(-time.Nanosecond), true
+}
+
+// startMonth is the fiscal year's first month, January when unset
+func (p PeriodsConfig) startMonth() int {
+	if p.FiscalYearStart < 1 || p.FiscalYearStart > 12 {
+		return 1
+	}
+	return p.FiscalYearStart
+}
+
+// endOfDay is the last instant of t's day
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PluginConfig is an external program that adds an exporter, an alert
+// channel or an enricher without changing fintrack. It is sent a JSON request
+// on stdin and answers on stdout; see the plugin package for the protocol.
+type PluginConfig struct {
+	Command string            `mapstructure:"command"` // Shell command
+	Options map[string]string `mapstructure:"options"` // Passed to the plugin in every request
+	Enrich  bool              `mapstructure:"enrich"`  // Run on new transactions before they're stored, e.g. to categorize them
+	Timeout time.Duration     `mapstructure:"timeout"` // Per request; default 60s
+}
+
+// PluginNames returns the configured plugin names in sorted order
+func (c *Config) PluginNames() []string {
+	names := make([]string, 0, len(c.Plugins))
+	for name := range c.Plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidatePlugins checks each plugin has a command, and that export targets
+// and alert channels of type plugin name a configured one
+func (c *Config) ValidatePlugins() error {
+	for _, name := range c.PluginNames() {
+		plugin := c.Plugins[name]
+		if strings.TrimSpace(plugin.Command) == "" {
+			return fmt.Errorf("plugins.%s: command is required", name)
+		}
+		if plugin.Timeout < 0 {
+			return fmt.Errorf("plugins.%s: timeout must be positive", name)
+		}
+	}
+
+	for name, target := range c.Exports {
+		if strings.EqualFold(target.Type, "plugin") {
+			if err := c.checkPlugin("exports."+name, target.Plugin); err != nil {
+				return err
+			}
+		}
+	}
+	for name, channel := range c.Alerts.Channels {
+		if strings.EqualFold(channel.Type, "plugin") {
+			if err := c.checkPlugin("alerts.channels."+name, channel.Plugin); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkPlugin checks a plugin reference names a configured plugin
+func (c *Config) checkPlugin(key, name string) error {
+	if name == "" {
+		return fmt.Errorf("%s: plugin is required", key)
+	}
+	if _, ok := c.Plugins[strings.ToLower(name)]; !ok {
+		return fmt.Errorf("%s: unknown plugin %q (configured: %s)", key, name, strings.Join(c.PluginNames(), ", "))
+	}
+	return nil
+}
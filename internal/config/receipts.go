@@ -0,0 +1,16 @@
+package config
+
+import "fmt"
+
+// ReceiptsConfig controls where receipt files are kept locally
+type ReceiptsConfig struct {
+	Dir string `mapstructure:"dir"` // Media directory; each transaction's receipts go in a subdirectory named by its UUID
+}
+
+// ValidateReceipts checks the receipt settings
+func (c *Config) ValidateReceipts() error {
+	if c.Receipts.Dir == "" {
+		return fmt.Errorf("receipts.dir cannot be empty")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/quickkly/fintrack/internal/redact"
+)
+
+// Redacted returns a copy of the configuration with secrets masked, for
+// display: every setting whose key redact.IsSecret matches, including entries
+// of maps such as headers (an Authorization or x-api-key header, say)
+func (c *Config) Redacted() *Config {
+	masked := *c
+	redactStruct(reflect.ValueOf(&masked).Elem())
+	return &masked
+}
+
+// redactStruct masks the secret fields of an addressable struct in place.
+// Maps, slices and pointers are replaced by masked copies, so the original
+// configuration they were shared with is left alone.
+func redactStruct(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		name := strings.Split(t.Field(i).Tag.Get("mapstructure"), ",")[0]
+		if name == "" || name == "-" {
+			name = t.Field(i).Name
+		}
+		field.Set(redactValue(name, field))
+	}
+}
+
+// redactValue returns a masked copy of v, the value of the setting name
+func redactValue(name string, v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		masked := reflect.New(v.Type()).Elem()
+		masked.SetString(redact.Value(name, v.String()))
+		return masked
+	case reflect.Struct:
+		masked := reflect.New(v.Type()).Elem()
+		masked.Set(v)
+		redactStruct(masked)
+		return masked
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		masked := reflect.New(v.Type().Elem())
+		masked.Elem().Set(redactValue(name, v.Elem()))
+		return masked
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v
+		}
+		masked := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			masked.SetMapIndex(iter.Key(), redactValue(iter.Key().String(), iter.Value()))
+		}
+		return masked
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		masked := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			masked.Index(i).Set(redactValue(name, v.Index(i)))
+		}
+		return masked
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		masked := reflect.New(v.Type()).Elem()
+		masked.Set(reflect.ValueOf(redact.Setting(name, v.Interface())))
+		return masked
+	}
+	return v
+}
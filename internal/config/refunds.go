@@ -0,0 +1,16 @@
+package config
+
+import "fmt"
+
+// RefundsConfig controls matching refunds to the purchases they undo
+type RefundsConfig struct {
+	WindowDays int `mapstructure:"window_days"` // Longest gap between a purchase and its refund
+}
+
+// ValidateRefunds checks the refund matching settings
+func (c *Config) ValidateRefunds() error {
+	if c.Refunds.WindowDays < 0 {
+		return fmt.Errorf("refunds.window_days cannot be negative")
+	}
+	return nil
+}
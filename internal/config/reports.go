@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReportsConfig holds report jobs run unattended by 'fintrack sync'
+type ReportsConfig struct {
+	Schedule map[string]ReportJob `mapstructure:"schedule"` // Keyed by job name
+}
+
+// ReportJob generates a report for the previous week or month once that
+// period is over, e.g. "on the 1st, write last month's spending tree"
+type ReportJob struct {
+	Every    string   `mapstructure:"every"`    // weekly or monthly
+	Day      int      `mapstructure:"day"`      // Day of month (monthly) or weekday, 1 = Monday (weekly), to run from; default 1
+	Report   string   `mapstructure:"report"`   // Report to generate: tree (default) or summary
+	Format   string   `mapstructure:"format"`   // table, json or csv (tree only)
+	Path     string   `mapstructure:"path"`     // Output file; {period} is replaced with e.g. 2024-03 or 2024-W09
+	Depth    int      `mapstructure:"depth"`    // Tree depth (1-3, default 3)
+	Income   bool     `mapstructure:"income"`   // Report incoming instead of outgoing transactions
+	Channels []string `mapstructure:"channels"` // Alert channels told about the generated file
+	Entity   string   `mapstructure:"entity"`   // Only report on this entity's transactions
+}
+
+// ValidateReports checks every scheduled report job
+func (c *Config) ValidateReports() error {
+	for name, job := range c.Reports.Schedule {
+		prefix := "reports.schedule." + name
+		switch strings.ToLower(job.Every) {
+		case "weekly":
+			if job.Day < 0 || job.Day > 7 {
+				return fmt.Errorf("%s: day must be a weekday from 1 (Monday) to 7 (Sunday)", prefix)
+			}
+		case "monthly":
+			if job.Day < 0 || job.Day > 28 {
+				return fmt.Errorf("%s: day must be between 1 and 28", prefix)
+			}
+		default:
+			return fmt.Errorf("%s: every must be weekly or monthly, got %q", prefix, job.Every)
+		}
+		switch strings.ToLower(job.Report) {
+		case "", "tree":
+			switch strings.ToLower(job.Format) {
+			case "", "table", "json", "csv":
+			default:
+				return fmt.Errorf("%s: unknown format %q (use table, json or csv)", prefix, job.Format)
+			}
+		case "summary":
+			switch strings.ToLower(job.Format) {
+			case "", "table", "json":
+			default:
+				return fmt.Errorf("%s: unknown format %q for a summary (use table or json)", prefix, job.Format)
+			}
+		default:
+			return fmt.Errorf("%s: unknown report %q (use tree or summary)", prefix, job.Report)
+		}
+		if job.Path == "" {
+			return fmt.Errorf("%s: path is required", prefix)
+		}
+		if job.Depth < 0 || job.Depth > 3 {
+			return fmt.Errorf("%s: depth must be 1, 2 or 3", prefix)
+		}
+		for _, channel := range job.Channels {
+			if _, ok := c.Alerts.Channels[strings.ToLower(channel)]; !ok {
+				return fmt.Errorf("%s: unknown alert channel %q", prefix, channel)
+			}
+		}
+		if job.Entity != "" {
+			if err := c.ValidateEntity(job.Entity); err != nil {
+				return fmt.Errorf("%s: %w", prefix, err)
+			}
+		}
+	}
+	return nil
+}
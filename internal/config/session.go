@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// sessionNamePattern is what a named session may be called; the name ends up
+// in a file name
+var sessionNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// sessionSeparator joins a session file and a session name (session.json →
+// session@work.json). Environments use '-', so the two never collide.
+const sessionSeparator = "@"
+
+// ValidateSessionName checks a named session's name
+func ValidateSessionName(name string) error {
+	if !sessionNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid session name '%s': use lowercase letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// SelectSession makes name the active session: bend.session_file becomes the
+// named session's own file, next to the environment's default one, and
+// bend.cache_dir its own cache (http-cache@work), so sessions never see each
+// other's cached responses. An empty name selects the default session.
+func (c *Config) SelectSession(name string) error {
+	name = strings.ToLower(name)
+	if c.defaultSessionFile == "" {
+		c.defaultSessionFile = c.Bend.SessionFile
+	}
+	if c.defaultCacheDir == "" {
+		c.defaultCacheDir = c.Bend.CacheDir
+	}
+	if name == "" {
+		c.Session = ""
+		c.Bend.SessionFile = c.defaultSessionFile
+		c.Bend.CacheDir = c.defaultCacheDir
+		return nil
+	}
+	if err := ValidateSessionName(name); err != nil {
+		return err
+	}
+	c.Session = name
+	c.Bend.SessionFile = c.NamedSessionFile(name)
+	if c.defaultCacheDir != "" {
+		c.Bend.CacheDir = c.defaultCacheDir + sessionSeparator + name
+	}
+	return nil
+}
+
+// NamedSessionFile returns the session file of the named session in the
+// active environment, the default session's for an empty name
+func (c *Config) NamedSessionFile(name string) string {
+	base := c.defaultSessionFile
+	if base == "" {
+		base = c.Bend.SessionFile
+	}
+	if name == "" {
+		return base
+	}
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + sessionSeparator + name + ext
+}
+
+// SessionNames lists the named sessions of the active environment that have
+// a session file, sorted
+func (c *Config) SessionNames() ([]string, error) {
+	base := c.NamedSessionFile("")
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + sessionSeparator
+	matches, err := filepath.Glob(globEscape(prefix) + "*" + globEscape(ext))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var names []string
+	for _, match := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(match, prefix), ext)
+		if ValidateSessionName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// globEscape quotes the characters filepath.Glob treats specially
+func globEscape(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TimeseriesFormats are the supported 'fintrack export timeseries' formats
+var TimeseriesFormats = []string{"influx", "prometheus"}
+
+// TimeseriesConfig controls 'fintrack export timeseries'
+type TimeseriesConfig struct {
+	Format  string            `mapstructure:"format"`  // influx (line protocol) or prometheus (text format)
+	URL     string            `mapstructure:"url"`     // InfluxDB write endpoint or Pushgateway job URL for --push
+	Headers map[string]string `mapstructure:"headers"` // Extra push request headers, e.g. an InfluxDB token
+	Days    int               `mapstructure:"days"`    // Days of daily spend to export
+}
+
+// ValidateTimeseries checks the timeseries export settings
+func (c *Config) ValidateTimeseries() error {
+	format := strings.ToLower(c.Timeseries.Format)
+	if format != "" && format != "influx" && format != "prometheus" {
+		return fmt.Errorf("timeseries.format must be one of: %s", strings.Join(TimeseriesFormats, ", "))
+	}
+	if url := c.Timeseries.URL; url != "" && !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return fmt.Errorf("timeseries.url must be a valid HTTP/HTTPS URL")
+	}
+	if c.Timeseries.Days < 0 {
+		return fmt.Errorf("timeseries.days cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Where a config value came from, in order of precedence
+const (
+	SourceFlag        = "flag"        // Command-line flag (--tz, --env, ...)
+	SourceEnvironment = "environment" // environments.<name> overlay
+	SourceEnv         = "env"         // FINTRACK_* variable
+	SourceFile        = "file"        // Config file given with FINTRACK_CONFIG or --config
+	SourceLocal       = "local"       // Config file found in the working directory (.fintrack/, configs/, .)
+	SourceGlobal      = "global"      // Config file in the global config directory
+	SourceDefault     = "default"     // Built-in default
+	SourceDerived     = "derived"     // Computed at load: directories, paths and the device hash
+	SourceUnset       = "unset"
+)
+
+// TraceEntry is one config value and where it came from
+type TraceEntry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Origin string `json:"origin,omitempty"` // The file, variable or environment name
+}
+
+// traceSource is a key's source as found while loading
+type traceSource struct {
+	source string
+	origin string
+}
+
+// traceSources records where each fixed key's value comes from, once the
+// config files are read and the environment applied
+func traceSources(v *viper.Viper, environment string, files []ConfigFile) map[string]traceSource {
+	// Each file on its own, to tell which layer set a key
+	layers := make([]*viper.Viper, len(files))
+	for i, file := range files {
+		layers[i] = viper.New()
+		layers[i].SetConfigFile(file.Path)
+		_ = layers[i].ReadInConfig() // Already read once without error
+	}
+	fileLayer := func(key string) (ConfigFile, bool) {
+		for i := len(files) - 1; i >= 0; i-- {
+			if layers[i].InConfig(key) {
+				return files[i], true
+			}
+		}
+		return ConfigFile{}, false
+	}
+
+	environment = strings.ToLower(environment)
+	overlaid := make(map[string]bool)
+	if environment != "" {
+		for _, key := range environmentKeys {
+			if v.IsSet("environments." + environment + "." + key) {
+				overlaid["bend."+key] = true
+			}
+		}
+	}
+
+	sources := make(map[string]traceSource)
+	for _, key := range EnvKeys() {
+		file, inFile := fileLayer(key)
+		switch {
+		case overlaid[key]:
+			sources[key] = traceSource{SourceEnvironment, "environments." + environment}
+		case environment != "" && key == "bend.session_file":
+			sources[key] = traceSource{SourceEnvironment, environment + " (derived from the default session file)"}
+		case os.Getenv(EnvVar(key)) != "":
+			sources[key] = traceSource{SourceEnv, EnvVar(key)}
+		case inFile:
+			sources[key] = traceSource{file.Source, file.Path}
+		case v.IsSet(key):
+			sources[key] = traceSource{SourceDefault, ""}
+		default:
+			sources[key] = traceSource{SourceUnset, ""}
+		}
+	}
+	return sources
+}
+
+// SetSource records that a command-line flag (or another override the
+// caller applied after loading) set key
+func (c *Config) SetSource(key, source, origin string) {
+	if c.sources == nil {
+		c.sources = make(map[string]traceSource)
+	}
+	c.sources[key] = traceSource{source, origin}
+}
+
+// Trace lists every fixed config key with its resolved value and where it
+// came from, sorted by key. Keys left unset but filled in while loading
+// (file locations, the device hash) are reported as derived. Values are
+// taken from c, so call it on Redacted() to mask secrets.
+func (c *Config) Trace() []TraceEntry {
+	values := make(map[string]reflect.Value)
+	collectValues(reflect.ValueOf(*c), "", values)
+
+	entries := make([]TraceEntry, 0, len(c.sources))
+	for key, src := range c.sources {
+		entry := TraceEntry{Key: key, Source: src.source, Origin: src.origin}
+		if value, ok := values[key]; ok {
+			if !value.IsZero() || value.Kind() == reflect.Bool {
+				entry.Value = fmt.Sprint(value.Interface())
+			}
+			if entry.Source == SourceUnset && !value.IsZero() {
+				entry.Source = SourceDerived
+			}
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// collectValues collects the leaf fields of a config struct by dotted key
+func collectValues(v reflect.Value, prefix string, values map[string]reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+
+		switch value := v.Field(i); value.Kind() {
+		case reflect.Struct:
+			collectValues(value, key, values)
+		case reflect.Map:
+			// Map entries are user-named and not traced
+		default:
+			values[key] = value
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/quickkly/fintrack/internal/safefile"
+
+	"github.com/spf13/viper"
+)
+
+// WriteConfig saves v to the config file it was read from, atomically, so an
+// interrupted 'config set' can't leave a truncated config behind
+func WriteConfig(v *viper.Viper) error {
+	path := v.ConfigFileUsed()
+	if path == "" {
+		return fmt.Errorf("no config file to write")
+	}
+	return safefile.WriteWith(path, safefile.Perm(path, 0644), func(tmp *os.File) error {
+		return v.WriteConfigAs(tmp.Name())
+	})
+}
@@ -0,0 +1,97 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// Field is a column a command offers; --columns picks fields by name
+type Field struct {
+	Name string // As given to --columns, e.g. "amount"
+	Column
+}
+
+// PickFields returns the fields named in spec, a comma-separated list such as
+// "date,amount,merchant", in the order given. An empty spec picks defaults.
+func PickFields(available []Field, defaults []string, spec string) ([]Field, error) {
+	names := defaults
+	if strings.TrimSpace(spec) != "" {
+		names = strings.Split(spec, ",")
+	}
+
+	byName := make(map[string]Field, len(available))
+	known := make([]string, len(available))
+	for i, field := range available {
+		byName[field.Name] = field
+		known[i] = field.Name
+	}
+
+	picked := make([]Field, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		field, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(known, ", "))
+		}
+		picked = append(picked, field)
+	}
+	return picked, nil
+}
+
+// Columns returns the table columns of fields
+func Columns(fields []Field) []Column {
+	columns := make([]Column, len(fields))
+	for i, field := range fields {
+		columns[i] = field.Column
+	}
+	return columns
+}
+
+// Cells orders a row's values, keyed by field name, as fields
+func Cells(fields []Field, values map[string]string) []string {
+	cells := make([]string, len(fields))
+	for i, field := range fields {
+		cells[i] = values[field.Name]
+	}
+	return cells
+}
+
+// Template parses a --format template, rendered once per row. \t and \n in
+// the text stand for a tab and a newline, since shells pass them literally.
+// Besides the built-in functions it has date, datetime and amount, which
+// follow the display settings (amount rounding with mode), and upper, lower
+// and json.
+func (f *Formatter) Template(text string, mode money.RoundingMode) (*template.Template, error) {
+	text = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(text)
+	tmpl, err := template.New("format").Funcs(template.FuncMap{
+		"date":     f.Date,
+		"datetime": f.DateTime,
+		"amount": func(amount float64, currency string) string {
+			return f.Amount(money.FromFloat(amount, mode), currency)
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// ExecuteLine renders one row with tmpl, ending it with a newline
+func ExecuteLine(w io.Writer, tmpl *template.Template, row interface{}) error {
+	if err := tmpl.Execute(w, row); err != nil {
+		return fmt.Errorf("failed to render --format template: %w", err)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
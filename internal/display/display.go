@@ -0,0 +1,87 @@
+// Package display formats dates, amounts and tables according to the display
+// section of the configuration.
+package display
+
+import (
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/money"
+
+	"github.com/spf13/cobra"
+)
+
+// Formatter applies the configured display preferences
+type Formatter struct {
+	cfg        config.DisplayConfig
+	dateLayout string
+}
+
+// New creates a formatter for the given display settings
+func New(cfg config.DisplayConfig) *Formatter {
+	return &Formatter{cfg: cfg, dateLayout: config.DateLayout(cfg.DateFormat)}
+}
+
+// Date formats a date in the user's timezone
+func (f *Formatter) Date(t time.Time) string {
+	return t.Local().Format(f.dateLayout)
+}
+
+// DateTime formats a date with hours and minutes in the user's timezone
+func (f *Formatter) DateTime(t time.Time) string {
+	return t.Local().Format(f.dateLayout + " 15:04")
+}
+
+// Amount formats an amount in a currency, e.g. "1234.50 INR" or "₹1234.50"
+func (f *Formatter) Amount(amount money.Amount, currency string) string {
+	value := amount.String()
+	symbol, ok := money.Symbol(currency)
+	if !ok {
+		symbol = currency
+	}
+
+	switch strings.ToLower(f.cfg.CurrencySymbol) {
+	case "none":
+		return value
+	case "before":
+		// Keep the sign in front of the symbol: -₹12.00
+		if strings.HasPrefix(value, "-") {
+			return "-" + symbol + value[1:]
+		}
+		return symbol + value
+	case "after":
+		return value + " " + symbol
+	default: // code
+		if currency == "" {
+			return value
+		}
+		return value + " " + strings.ToUpper(currency)
+	}
+}
+
+// Money formats an amount with its own currency
+func (f *Formatter) Money(m money.Money) string {
+	return f.Amount(m.Amount, m.Currency)
+}
+
+// Table starts a table rendered in the configured style
+func (f *Formatter) Table(columns ...Column) *Table {
+	return &Table{style: strings.ToLower(f.cfg.TableStyle), columns: columns}
+}
+
+// Output returns the output format of a command: its --output flag when given,
+// otherwise the configured default
+func Output(cmd *cobra.Command, cfg config.DisplayConfig) string {
+	flag := cmd.Flags().Lookup("output")
+	if flag != nil && flag.Changed {
+		return strings.ToLower(flag.Value.String())
+	}
+	if cfg.Output != "" {
+		return strings.ToLower(cfg.Output)
+	}
+	if flag != nil {
+		return flag.Value.String()
+	}
+	return "table"
+}
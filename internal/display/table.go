@@ -0,0 +1,121 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Column is a table column
+type Column struct {
+	Header string
+	Right  bool // Right-align, for amounts
+}
+
+// Table collects rows and renders them in one of the configured styles:
+//
+//	ascii     a | b  with a -+- rule under the header (the default)
+//	plain     columns separated by spaces, no rule
+//	markdown  | a | b |  ready to paste into notes
+//	unicode   a │ b  with a ─┼─ rule
+type Table struct {
+	style   string
+	columns []Column
+	rows    [][]string
+}
+
+// Row adds a row. Missing cells are left blank.
+func (t *Table) Row(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// Render writes the table to w
+func (t *Table) Render(w io.Writer) {
+	widths := make([]int, len(t.columns))
+	for i, col := range t.columns {
+		widths[i] = utf8.RuneCountInString(col.Header)
+	}
+	for _, row := range t.rows {
+		for i := range t.columns {
+			if i < len(row) {
+				if n := utf8.RuneCountInString(row[i]); n > widths[i] {
+					widths[i] = n
+				}
+			}
+		}
+	}
+
+	headers := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		headers[i] = col.Header
+	}
+
+	t.renderRow(w, headers, widths)
+	t.renderRule(w, widths)
+	for _, row := range t.rows {
+		t.renderRow(w, row, widths)
+	}
+}
+
+// renderRow writes one padded row
+func (t *Table) renderRow(w io.Writer, row []string, widths []int) {
+	cells := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		cell := ""
+		if i < len(row) {
+			cell = row[i]
+		}
+		cells[i] = pad(cell, widths[i], col.Right)
+	}
+
+	switch t.style {
+	case "plain":
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, "  "), " "))
+	case "markdown":
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	case "unicode":
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, " │ "), " "))
+	default:
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, " | "), " "))
+	}
+}
+
+// renderRule writes the line between the header and the rows
+func (t *Table) renderRule(w io.Writer, widths []int) {
+	segments := make([]string, len(widths))
+	switch t.style {
+	case "plain":
+		return
+	case "markdown":
+		for i, width := range widths {
+			segments[i] = strings.Repeat("-", width)
+			if t.columns[i].Right {
+				segments[i] = segments[i][:width-1] + ":"
+			}
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(segments, " | "))
+	case "unicode":
+		for i, width := range widths {
+			segments[i] = strings.Repeat("─", width)
+		}
+		fmt.Fprintln(w, strings.Join(segments, "─┼─"))
+	default:
+		for i, width := range widths {
+			segments[i] = strings.Repeat("-", width)
+		}
+		fmt.Fprintln(w, strings.Join(segments, "-+-"))
+	}
+}
+
+// pad pads s to width runes
+func pad(s string, width int, right bool) string {
+	gap := width - utf8.RuneCountInString(s)
+	if gap <= 0 {
+		return s
+	}
+	if right {
+		return strings.Repeat(" ", gap) + s
+	}
+	return s + strings.Repeat(" ", gap)
+}
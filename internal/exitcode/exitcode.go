@@ -0,0 +1,115 @@
+// Package exitcode maps command errors to distinct process exit codes, so
+// cron jobs and scripts can react differently to different failures
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// Exit codes. They are part of the CLI's interface: never renumber them.
+const (
+	OK          = 0
+	Failure     = 1 // Any other error
+	Usage       = 2 // Invalid flags, arguments or configuration
+	Auth        = 3 // No valid session, or Bend rejected the credentials
+	Network     = 4 // Bend could not be reached or timed out
+	RateLimited = 5 // Bend answered 429, or an open circuit held requests back
+	Partial     = 6 // Part of the work failed; what succeeded was saved
+)
+
+// names are the short names of the codes, shown in quiet-mode error messages
+var names = map[int]string{
+	OK:          "ok",
+	Failure:     "error",
+	Usage:       "usage",
+	Auth:        "auth",
+	Network:     "network",
+	RateLimited: "rate_limited",
+	Partial:     "partial",
+}
+
+// authPhrases mark the session errors that carry no status code
+var authPhrases = []string{"no session found", "no session available", "session expired"}
+
+// Name returns the short name of code
+func Name(code int) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+	return names[Failure]
+}
+
+// Error attaches an exit code to an error
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap attaches code to err; a nil err stays nil
+func Wrap(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Of returns the exit code for err. A code attached with Wrap wins; a
+// partial fetch comes next, since its pages were saved whatever stopped it;
+// otherwise the cause is classified.
+func Of(err error) int {
+	if err == nil {
+		return OK
+	}
+
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	if _, ok := blend.AsPartial(err); ok {
+		return Partial
+	}
+
+	if blend.IsCircuitOpen(err) {
+		return RateLimited
+	}
+	var statusErr *blend.StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusTooManyRequests:
+			return RateLimited
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return Auth
+		}
+		return Failure
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Network
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return Network
+	}
+
+	message := err.Error()
+	for _, phrase := range authPhrases {
+		if strings.Contains(message, phrase) {
+			return Auth
+		}
+	}
+	return Failure
+}
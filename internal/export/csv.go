@@ -0,0 +1,94 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// csvHeader is the column layout of CSV exports
+var csvHeader = []string{"uuid", "date", "account_id", "type", "amount", "currency", "mode", "narration", "category_id", "subcategory_id", "fingerprint"}
+
+// CSVExporter writes transactions to a CSV file
+type CSVExporter struct {
+	Path     string
+	Rounding money.RoundingMode
+	Append   bool // Add rows to an existing file instead of replacing it
+}
+
+// Export writes the transactions to the CSV file
+func (e *CSVExporter) Export(ctx context.Context, transactions []blend.Transaction, progress ProgressFunc) error {
+	file, empty, err := openOutput(e.Path, e.Append)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", e.Path, err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if empty {
+		if err := w.Write(csvHeader); err != nil {
+			return fmt.Errorf("failed to write %s: %w", e.Path, err)
+		}
+	}
+	// Files started before the fingerprint column keep their layout
+	columns := len(csvHeader)
+	if !empty {
+		columns = csvColumns(e.Path)
+	}
+
+	for i, txn := range transactions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := w.Write(csvRecord(txn, e.Rounding)[:columns]); err != nil {
+			return fmt.Errorf("failed to write %s: %w", e.Path, err)
+		}
+		progress(i+1, len(transactions))
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", e.Path, err)
+	}
+	return file.Close()
+}
+
+// csvRecord formats a transaction as a CSV row
+func csvRecord(txn blend.Transaction, rounding money.RoundingMode) []string {
+	categoryID, subcategoryID := categoryIDs(txn)
+
+	return []string{
+		txn.UUID,
+		txn.TxnTimestamp.Format(time.RFC3339),
+		txn.AccountID,
+		txn.Type,
+		money.FromFloat(txn.SignedAmount(), rounding).String(),
+		txn.Currency,
+		txn.Mode,
+		txn.Narration,
+		categoryID,
+		subcategoryID,
+		txn.Fingerprint(),
+	}
+}
+
+// csvColumns is the number of columns in an existing export's header: the
+// current layout, or the one before the fingerprint column was added
+func csvColumns(path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		return len(csvHeader)
+	}
+	defer file.Close()
+
+	header, err := csv.NewReader(file).Read()
+	if err != nil || len(header) >= len(csvHeader) {
+		return len(csvHeader)
+	}
+	return len(csvHeader) - 1
+}
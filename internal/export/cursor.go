@@ -0,0 +1,89 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/safefile"
+)
+
+// cursorFile lives in the state directory (paths.state_dir)
+const cursorFile = "export-cursors.json"
+
+// legacyCursorFile is where earlier releases kept the cursors, in the staging
+// directory. It deliberately doesn't end in .json so it is never read as a
+// staging file.
+const legacyCursorFile = ".export-cursors"
+
+// Cursor is the high-water mark of an incremental export target: every
+// transaction first fetched at or before HighWater has been exported
+type Cursor struct {
+	HighWater  time.Time `json:"high_water"`
+	LastExport time.Time `json:"last_export"`
+	Exported   int       `json:"exported"` // Transactions exported in total
+}
+
+// Cursors are the stored cursors of every target, keyed by target name
+type Cursors map[string]Cursor
+
+// CursorPath returns the cursor file in a state directory
+func CursorPath(stateDir string) string {
+	return filepath.Join(stateDir, cursorFile)
+}
+
+// LegacyCursorPath returns where earlier releases kept the cursors of a
+// staging directory
+func LegacyCursorPath(stagingDir string) string {
+	return filepath.Join(stagingDir, legacyCursorFile)
+}
+
+// LoadCursors reads the cursor file, or the legacy one when it doesn't exist
+// yet. Neither existing means no target has run yet.
+func LoadCursors(path, legacy string) (Cursors, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && legacy != "" {
+		path = legacy
+		data, err = os.ReadFile(path)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return Cursors{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export cursors: %w", err)
+	}
+
+	cursors := Cursors{}
+	if err := json.Unmarshal(data, &cursors); err != nil {
+		return nil, fmt.Errorf("failed to parse export cursors %s: %w", path, err)
+	}
+	return cursors, nil
+}
+
+// Save writes the cursors atomically
+func (c Cursors) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal export cursors: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create export cursors directory: %w", err)
+	}
+	if err := safefile.Write(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write export cursors: %w", err)
+	}
+	return nil
+}
+
+// Advance moves a target's cursor to highWater after a successful export
+func (c Cursors) Advance(target string, highWater time.Time, exported int) {
+	cursor := c[target]
+	cursor.HighWater = highWater
+	cursor.LastExport = time.Now()
+	cursor.Exported += exported
+	c[target] = cursor
+}
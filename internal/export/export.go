@@ -0,0 +1,177 @@
+// Package export writes staged transactions to external targets (CSV, NDJSON,
+// QIF and GnuCash files, webhooks, plugins) and runs several targets concurrently over
+// one snapshot.
+package export
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// ProgressFunc is told how many transactions a target has exported so far
+type ProgressFunc func(done, total int)
+
+// Exporter writes transactions to one target. Implementations must treat the
+// transactions slice as read-only: it is shared by every target in a run.
+type Exporter interface {
+	Export(ctx context.Context, transactions []blend.Transaction, progress ProgressFunc) error
+}
+
+// Target is a named, configured exporter
+type Target struct {
+	Name        string
+	Type        string
+	Incremental bool   // Only export transactions added since the target's cursor
+	Entity      string // Only export this entity's transactions, when set
+	Exporter    Exporter
+}
+
+// Job pairs a target with the transactions it should receive
+type Job struct {
+	Target       *Target
+	Transactions []blend.Transaction
+}
+
+// Result is the outcome of exporting to one target
+type Result struct {
+	Target   string
+	Count    int
+	Duration time.Duration
+	Err      error
+}
+
+// New builds the exporter for a configured target
+func New(name string, target config.ExportTarget, rounding money.RoundingMode, ledger config.LedgerConfig) (*Target, error) {
+	var exporter Exporter
+	switch strings.ToLower(target.Type) {
+	case "csv":
+		if target.Path == "" {
+			return nil, fmt.Errorf("export target %s: path is required", name)
+		}
+		// Incremental CSV targets grow by appending each run's new rows
+		exporter = &CSVExporter{Path: target.Path, Rounding: rounding, Append: target.Incremental}
+	case "qif":
+		if target.Path == "" {
+			return nil, fmt.Errorf("export target %s: path is required", name)
+		}
+		exporter = &QIFExporter{Path: target.Path, Rounding: rounding, Ledger: ledger, Append: target.Incremental}
+	case "gnucash":
+		if target.Path == "" {
+			return nil, fmt.Errorf("export target %s: path is required", name)
+		}
+		exporter = &GnuCashExporter{Path: target.Path, Rounding: rounding, Ledger: ledger, Append: target.Incremental}
+	case "ndjson":
+		if target.Path == "" {
+			return nil, fmt.Errorf("export target %s: path is required", name)
+		}
+		exporter = &NDJSONExporter{Path: target.Path, Append: target.Incremental}
+	case "webhook":
+		if target.URL == "" {
+			return nil, fmt.Errorf("export target %s: url is required", name)
+		}
+		compress := strings.ToLower(target.Compress)
+		if !slices.Contains(Compressions, compress) {
+			return nil, fmt.Errorf("export target %s: unknown compress %q (use none, gzip or auto)", name, target.Compress)
+		}
+		exporter = &WebhookExporter{URL: target.URL, BatchSize: target.BatchSize, Headers: target.Headers, Compress: compress}
+	case "plugin":
+		if target.Plugin == "" {
+			return nil, fmt.Errorf("export target %s: plugin is required", name)
+		}
+		exporter = &PluginExporter{Target: name, Plugin: target.Plugin}
+	default:
+		return nil, fmt.Errorf("export target %s: unknown type %q (use csv, ndjson, qif, gnucash, webhook or plugin)", name, target.Type)
+	}
+
+	return &Target{
+		Name:        name,
+		Type:        strings.ToLower(target.Type),
+		Incremental: target.Incremental,
+		Entity:      strings.ToLower(target.Entity),
+		Exporter:    exporter,
+	}, nil
+}
+
+// Targets builds the named targets from the configuration, or every
+// configured target when no names are given
+func Targets(cfg *config.Config, names []string) ([]*Target, error) {
+	if len(names) == 0 {
+		for name := range cfg.Exports {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no export targets configured (add an exports: section to the config)")
+	}
+
+	targets := make([]*Target, 0, len(names))
+	for _, name := range names {
+		targetCfg, ok := cfg.Exports[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown export target '%s' (configured: %s)", name, strings.Join(cfg.ExportNames(), ", "))
+		}
+		target, err := New(strings.ToLower(name), targetCfg, cfg.RoundingMode(), cfg.Ledger)
+		if err != nil {
+			return nil, err
+		}
+		if target.Entity != "" {
+			if err := cfg.ValidateEntity(target.Entity); err != nil {
+				return nil, fmt.Errorf("export target %s: %w", target.Name, err)
+			}
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// Run runs every job concurrently. A failing (or panicking) target does not
+// affect the others; its error is in its Result.
+func Run(ctx context.Context, jobs []Job, progress func(target string, done, total int)) []Result {
+	results := make([]Result, len(jobs))
+
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func(i int, job Job) {
+			defer wg.Done()
+			results[i] = runTarget(ctx, job.Transactions, job.Target, progress)
+		}(i, job)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runTarget exports to a single target, converting panics into errors
+func runTarget(ctx context.Context, transactions []blend.Transaction, target *Target, progress func(string, int, int)) (result Result) {
+	start := time.Now()
+	result = Result{Target: target.Name}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result.Err = fmt.Errorf("exporter panicked: %v", r)
+		}
+		result.Duration = time.Since(start)
+	}()
+
+	err := target.Exporter.Export(ctx, transactions, func(done, total int) {
+		result.Count = done
+		if progress != nil {
+			progress(target.Name, done, total)
+		}
+	})
+	if err != nil {
+		result.Err = err
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// gnucashHeader matches GnuCash's multi-split CSV transaction import
+var gnucashHeader = []string{"Date", "Transaction ID", "Number", "Description", "Notes", "Commodity/Currency", "Memo", "Full Account Name", "Amount Num."}
+
+// GnuCashExporter writes transactions as a GnuCash multi-split CSV file: each
+// transaction is a balanced pair of splits between the mapped bank account
+// and the mapped category account
+type GnuCashExporter struct {
+	Path     string
+	Rounding money.RoundingMode
+	Ledger   config.LedgerConfig // Account and category names
+	Append   bool                // Add rows to an existing file instead of replacing it
+}
+
+// Export writes the transactions to the GnuCash CSV file
+func (e *GnuCashExporter) Export(ctx context.Context, transactions []blend.Transaction, progress ProgressFunc) error {
+	file, empty, err := openOutput(e.Path, e.Append)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", e.Path, err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if empty {
+		if err := w.Write(gnucashHeader); err != nil {
+			return fmt.Errorf("failed to write %s: %w", e.Path, err)
+		}
+	}
+
+	for i, txn := range transactions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := w.WriteAll(gnucashSplits(txn, e.Ledger, e.Rounding)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", e.Path, err)
+		}
+		progress(i+1, len(transactions))
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", e.Path, err)
+	}
+	return file.Close()
+}
+
+// gnucashSplits formats a transaction as its two split rows. Only the first
+// row carries the transaction fields; GnuCash groups following rows into it.
+func gnucashSplits(txn blend.Transaction, ledger config.LedgerConfig, rounding money.RoundingMode) [][]string {
+	amount := money.FromFloat(txn.SignedAmount(), rounding)
+
+	notes := ""
+	if txn.Notes != nil {
+		notes = *txn.Notes
+	}
+
+	return [][]string{
+		{
+			txn.TxnTimestamp.Local().Format("2006-01-02"),
+			txn.UUID,
+			txn.Reference,
+			payee(txn),
+			notes,
+			"CURRENCY::" + txn.Currency,
+			txn.Narration,
+			ledger.AccountName(txn.AccountID),
+			amount.String(),
+		},
+		{"", "", "", "", "", "", "", ledgerCategory(ledger, txn), (-amount).String()},
+	}
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"os"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+)
+
+// categoryIDs returns a transaction's category and subcategory IDs, empty when unset
+func categoryIDs(txn blend.Transaction) (string, string) {
+	categoryID, subcategoryID := "", ""
+	if txn.Category != nil {
+		if txn.Category.ID != nil {
+			categoryID = *txn.Category.ID
+		}
+		if txn.Category.SubcategoryID != nil {
+			subcategoryID = *txn.Category.SubcategoryID
+		}
+	}
+	return categoryID, subcategoryID
+}
+
+// ledgerCategory is the mapped counter-account of a transaction
+func ledgerCategory(ledger config.LedgerConfig, txn blend.Transaction) string {
+	categoryID, subcategoryID := categoryIDs(txn)
+	return ledger.CategoryName(categoryID, subcategoryID, txn.Type == blend.TransactionTypeIncoming)
+}
+
+// payee is the merchant name when Bend resolved one, otherwise the narration
+func payee(txn blend.Transaction) string {
+	if txn.Merchant != nil && txn.Merchant.Name != nil && *txn.Merchant.Name != "" {
+		return *txn.Merchant.Name
+	}
+	return txn.Narration
+}
+
+// openOutput opens an export file for writing, appending when asked, and
+// reports whether the file was empty (so headers are only written once)
+func openOutput(path string, appendRows bool) (*os.File, bool, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendRows {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, false, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, false, err
+	}
+	return file, info.Size() == 0, nil
+}
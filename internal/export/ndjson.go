@@ -0,0 +1,95 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// ndjsonRecord is one line of NDJSON output: the transaction as Bend sent it,
+// plus its fingerprint
+type ndjsonRecord struct {
+	blend.Transaction
+	Fingerprint string `json:"fingerprint"`
+}
+
+// MarshalJSON encodes the transaction and adds the fingerprint; the embedded
+// MarshalJSON alone would drop it
+func (r ndjsonRecord) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(r.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, err := json.Marshal(r.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	return blend.MergeJSONFields(data, map[string]json.RawMessage{"fingerprint": fingerprint})
+}
+
+// NDJSONWriter streams transactions as newline-delimited JSON, one object per
+// line, so a multi-year history never has to be marshalled as one document
+type NDJSONWriter struct {
+	w     *bufio.Writer
+	enc   *json.Encoder
+	count int
+}
+
+// NewNDJSONWriter returns a writer that streams to w
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	buffered := bufio.NewWriter(w)
+	enc := json.NewEncoder(buffered)
+	enc.SetEscapeHTML(false)
+	return &NDJSONWriter{w: buffered, enc: enc}
+}
+
+// Write emits the transactions and flushes them, so each fetched page
+// reaches the reader as soon as it arrives
+func (n *NDJSONWriter) Write(transactions []blend.Transaction) error {
+	for _, txn := range transactions {
+		if err := n.enc.Encode(ndjsonRecord{Transaction: txn, Fingerprint: txn.Fingerprint()}); err != nil {
+			return err
+		}
+		n.count++
+	}
+	return n.w.Flush()
+}
+
+// Count is the number of transactions written so far
+func (n *NDJSONWriter) Count() int {
+	return n.count
+}
+
+// NDJSONExporter writes transactions to an NDJSON file
+type NDJSONExporter struct {
+	Path   string
+	Append bool // Add lines to an existing file instead of replacing it
+}
+
+// Export writes the transactions to the NDJSON file
+func (e *NDJSONExporter) Export(ctx context.Context, transactions []blend.Transaction, progress ProgressFunc) error {
+	file, _, err := openOutput(e.Path, e.Append)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", e.Path, err)
+	}
+	defer file.Close()
+
+	// Written in batches so progress and cancellation are checked as it goes
+	const batch = 500
+	w := NewNDJSONWriter(file)
+	for start := 0; start < len(transactions); start += batch {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := min(start+batch, len(transactions))
+		if err := w.Write(transactions[start:end]); err != nil {
+			return fmt.Errorf("failed to write %s: %w", e.Path, err)
+		}
+		progress(end, len(transactions))
+	}
+	return file.Close()
+}
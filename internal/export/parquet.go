@@ -0,0 +1,195 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/parquet"
+	"github.com/quickkly/fintrack/internal/safefile"
+	"github.com/quickkly/fintrack/internal/store"
+)
+
+// ParquetFile is the name of the file in each partition directory
+const ParquetFile = "transactions.parquet"
+
+// Parquet partitioning schemes
+var ParquetPartitions = []string{"month", "year", "none"}
+
+// parquetColumns is the schema of Parquet exports
+var parquetColumns = []parquet.Column{
+	{Name: "uuid", Type: parquet.String},
+	{Name: "date", Type: parquet.Date},
+	{Name: "timestamp", Type: parquet.Timestamp},
+	{Name: "account_id", Type: parquet.String},
+	{Name: "type", Type: parquet.String},
+	{Name: "amount", Type: parquet.Double}, // Signed: negative for outgoing
+	{Name: "currency", Type: parquet.String},
+	{Name: "mode", Type: parquet.String},
+	{Name: "narration", Type: parquet.String},
+	{Name: "category_id", Type: parquet.String, Optional: true},
+	{Name: "subcategory_id", Type: parquet.String, Optional: true},
+	{Name: "merchant", Type: parquet.String, Optional: true},
+	{Name: "reference", Type: parquet.String, Optional: true},
+	{Name: "tags", Type: parquet.String, Optional: true}, // Comma-separated
+	{Name: "hidden", Type: parquet.Boolean},
+	{Name: "excluded_from_cash_flow", Type: parquet.Boolean},
+	{Name: "source", Type: parquet.String, Optional: true}, // Fetch or import that stored it
+	{Name: "first_seen", Type: parquet.Timestamp},
+	{Name: "fingerprint", Type: parquet.String}, // Stable ID for deduplication, see blend.Transaction.Fingerprint
+}
+
+// ParquetPartition is one written partition
+type ParquetPartition struct {
+	Path string
+	Rows int
+}
+
+// WriteParquet writes records under dir as Hive-style partitions
+// (year=2024/month=03/transactions.parquet), which DuckDB, PyArrow and Spark
+// read as a single table with year and month columns. Partition files from
+// earlier exports that this one didn't write are removed, so the directory
+// always holds exactly the current data.
+func WriteParquet(dir, partitionBy string, records []*store.Record, rounding money.RoundingMode) ([]ParquetPartition, error) {
+	partitions := make(map[string][]*store.Record)
+	for _, record := range records {
+		key, err := partitionDir(partitionBy, record)
+		if err != nil {
+			return nil, err
+		}
+		partitions[key] = append(partitions[key], record)
+	}
+
+	keys := make([]string, 0, len(partitions))
+	for key := range partitions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	written := make(map[string]bool)
+	var result []ParquetPartition
+	for _, key := range keys {
+		records := partitions[key]
+		sort.Slice(records, func(i, j int) bool {
+			return records[i].Transaction.TxnTimestamp.Before(records[j].Transaction.TxnTimestamp)
+		})
+
+		path := filepath.Join(dir, key, ParquetFile)
+		if err := writeParquetFile(path, records, rounding); err != nil {
+			return nil, err
+		}
+		written[path] = true
+		result = append(result, ParquetPartition{Path: path, Rows: len(records)})
+	}
+
+	if err := removeStalePartitions(dir, written); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// partitionDir is the directory a record's partition goes in, relative to the export directory
+func partitionDir(partitionBy string, record *store.Record) (string, error) {
+	// Partition by the user's calendar, whatever offset Bend sent
+	t := record.Transaction.TxnTimestamp.Local()
+	switch partitionBy {
+	case "month":
+		return filepath.Join(fmt.Sprintf("year=%d", t.Year()), fmt.Sprintf("month=%02d", int(t.Month()))), nil
+	case "year":
+		return fmt.Sprintf("year=%d", t.Year()), nil
+	case "none", "":
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown partitioning %q (use %s)", partitionBy, strings.Join(ParquetPartitions, ", "))
+	}
+}
+
+// writeParquetFile writes one partition, replacing the file in one step so
+// readers never see a partial file
+func writeParquetFile(path string, records []*store.Record, rounding money.RoundingMode) error {
+	rows := make([][]any, 0, len(records))
+	for _, record := range records {
+		rows = append(rows, parquetRow(record, rounding))
+	}
+
+	var buf bytes.Buffer
+	if err := parquet.Write(&buf, parquetColumns, rows, "fintrack"); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := safefile.Write(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// parquetRow converts a record to a row of parquetColumns
+func parquetRow(record *store.Record, rounding money.RoundingMode) []any {
+	txn := record.Transaction
+	categoryID, subcategoryID := categoryIDs(txn)
+
+	var merchant any
+	if txn.Merchant != nil && txn.Merchant.Name != nil {
+		merchant = *txn.Merchant.Name
+	}
+
+	return []any{
+		txn.UUID,
+		txn.TxnTimestamp,
+		txn.TxnTimestamp,
+		txn.AccountID,
+		txn.Type,
+		money.FromFloat(txn.SignedAmount(), rounding).Float64(),
+		txn.Currency,
+		txn.Mode,
+		txn.Narration,
+		optional(categoryID),
+		optional(subcategoryID),
+		merchant,
+		optional(txn.Reference),
+		optional(strings.Join(record.Tags, ",")),
+		txn.IsHidden,
+		txn.ExcludedFromCashFlow,
+		optional(record.Source),
+		record.FirstSeen,
+		txn.Fingerprint(),
+	}
+}
+
+// optional turns an empty string into a null
+func optional(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// removeStalePartitions deletes partition files under dir that the current
+// export didn't write, such as those of a different partitioning
+func removeStalePartitions(dir string, written map[string]bool) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || d.Name() != ParquetFile || written[path] {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove stale partition %s: %w", path, err)
+		}
+		os.Remove(filepath.Dir(path)) // Only succeeds once the directory is empty
+		return nil
+	})
+}
@@ -0,0 +1,26 @@
+package export
+
+import (
+	"context"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/plugin"
+)
+
+// PluginExporter hands the transactions to a plugin in one export request
+type PluginExporter struct {
+	Target string // Export target name, sent to the plugin
+	Plugin string // Name under plugins
+}
+
+// Export sends the transactions to the plugin
+func (p *PluginExporter) Export(ctx context.Context, transactions []blend.Transaction, progress ProgressFunc) error {
+	_, err := plugin.Call(ctx, p.Plugin, plugin.Request{Kind: plugin.KindExport, Target: p.Target, Transactions: transactions})
+	if err != nil {
+		return err
+	}
+	if progress != nil {
+		progress(len(transactions), len(transactions))
+	}
+	return nil
+}
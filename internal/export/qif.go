@@ -0,0 +1,83 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// QIFExporter writes transactions as a Quicken Interchange Format file, one
+// bank account block per Bend account, for desktop tools such as GnuCash,
+// KMyMoney or Quicken
+type QIFExporter struct {
+	Path     string
+	Rounding money.RoundingMode
+	Ledger   config.LedgerConfig // Account and category names
+	Append   bool                // Add entries to an existing file instead of replacing it
+}
+
+// Export writes the transactions to the QIF file
+func (e *QIFExporter) Export(ctx context.Context, transactions []blend.Transaction, progress ProgressFunc) error {
+	file, _, err := openOutput(e.Path, e.Append)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", e.Path, err)
+	}
+	defer file.Close()
+
+	// QIF entries belong to the account block above them, so group by
+	// account while keeping the order accounts first appear in
+	var order []string
+	byAccount := make(map[string][]blend.Transaction)
+	for _, txn := range transactions {
+		if _, ok := byAccount[txn.AccountID]; !ok {
+			order = append(order, txn.AccountID)
+		}
+		byAccount[txn.AccountID] = append(byAccount[txn.AccountID], txn)
+	}
+
+	w := bufio.NewWriter(file)
+	done := 0
+	for _, accountID := range order {
+		fmt.Fprintf(w, "!Account\nN%s\nTBank\n^\n!Type:Bank\n", e.Ledger.AccountName(accountID))
+
+		for _, txn := range byAccount[accountID] {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			writeQIFEntry(w, txn, e.Ledger, e.Rounding)
+			done++
+			progress(done, len(transactions))
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", e.Path, err)
+	}
+	return file.Close()
+}
+
+// writeQIFEntry writes one transaction record
+func writeQIFEntry(w *bufio.Writer, txn blend.Transaction, ledger config.LedgerConfig, rounding money.RoundingMode) {
+	fmt.Fprintf(w, "D%s\n", txn.TxnTimestamp.Local().Format("01/02/2006"))
+	fmt.Fprintf(w, "T%s\n", money.FromFloat(txn.SignedAmount(), rounding))
+	if txn.Reference != "" {
+		fmt.Fprintf(w, "N%s\n", qifText(txn.Reference))
+	}
+	name := payee(txn)
+	fmt.Fprintf(w, "P%s\n", qifText(name))
+	if txn.Narration != "" && txn.Narration != name {
+		fmt.Fprintf(w, "M%s\n", qifText(txn.Narration))
+	}
+	fmt.Fprintf(w, "L%s\n", ledgerCategory(ledger, txn))
+	w.WriteString("^\n")
+}
+
+// qifText keeps a field on one line, since QIF fields are line-delimited
+func qifText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
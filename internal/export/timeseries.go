@@ -0,0 +1,224 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/report"
+	"github.com/quickkly/fintrack/internal/syncreport"
+)
+
+// Time series measurements
+const (
+	MeasurementBalance  = "fintrack_balance"   // Account balance, tags account and currency
+	MeasurementNetWorth = "fintrack_net_worth" // Sum of balances per currency
+	MeasurementSpend    = "fintrack_spend"     // Outgoing amount per category and day, in the base currency
+)
+
+// Point is one sample of a time series
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Value       float64
+	At          time.Time
+}
+
+// TimeseriesPoints builds the balance and net worth series from the last sync
+// report (nil for none), and one daily spend point per category and day from
+// spending, outgoing transactions already converted to base
+func TimeseriesPoints(sync *syncreport.Report, spending []blend.Transaction, base string, rounding money.RoundingMode) []Point {
+	var points []Point
+
+	if sync != nil {
+		for _, balance := range sync.Balances {
+			at := balance.AsOf
+			if at.IsZero() {
+				at = sync.StartedAt
+			}
+			points = append(points, Point{
+				Measurement: MeasurementBalance,
+				Tags:        map[string]string{"account": balance.AccountID, "currency": balance.Currency},
+				Value:       balance.Balance,
+				At:          at,
+			})
+		}
+		for currency, total := range sync.BalanceTotals {
+			points = append(points, Point{
+				Measurement: MeasurementNetWorth,
+				Tags:        map[string]string{"currency": currency},
+				Value:       total,
+				At:          sync.StartedAt,
+			})
+		}
+	}
+
+	type dayKey struct {
+		category string
+		day      time.Time
+	}
+	spend := make(map[dayKey]money.Amount)
+	for _, txn := range spending {
+		category := report.Uncategorized
+		if txn.Category != nil && txn.Category.ID != nil && *txn.Category.ID != "" {
+			category = *txn.Category.ID
+		}
+		t := txn.TxnTimestamp.Local()
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+		spend[dayKey{category, day}] += money.FromFloat(txn.Amount, rounding).Abs()
+	}
+	for key, amount := range spend {
+		points = append(points, Point{
+			Measurement: MeasurementSpend,
+			Tags:        map[string]string{"category": key.category, "currency": base},
+			Value:       amount.Float64(),
+			At:          key.day,
+		})
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		if points[i].Measurement != points[j].Measurement {
+			return points[i].Measurement < points[j].Measurement
+		}
+		if !points[i].At.Equal(points[j].At) {
+			return points[i].At.Before(points[j].At)
+		}
+		return seriesKey(points[i]) < seriesKey(points[j])
+	})
+	return points
+}
+
+// WriteInflux writes points in the InfluxDB line protocol with nanosecond
+// timestamps:
+//
+//	fintrack_spend,category=food,currency=INR value=450 1709251200000000000
+func WriteInflux(w io.Writer, points []Point) error {
+	bw := bufio.NewWriter(w)
+	for _, point := range points {
+		bw.WriteString(influxEscape(point.Measurement, ", "))
+		for _, key := range sortedKeys(point.Tags) {
+			if point.Tags[key] == "" {
+				continue // Influx rejects empty tag values
+			}
+			bw.WriteString("," + influxEscape(key, ",= ") + "=" + influxEscape(point.Tags[key], ",= "))
+		}
+		fmt.Fprintf(bw, " value=%s %d\n", strconv.FormatFloat(point.Value, 'f', -1, 64), point.At.UnixNano())
+	}
+	return bw.Flush()
+}
+
+// WritePrometheus writes points as gauges in the Prometheus text format, as
+// accepted by a Pushgateway. The format has no history, so points of the same
+// series are added up: daily spend becomes spend over the exported days.
+func WritePrometheus(w io.Writer, points []Point) error {
+	type series struct {
+		measurement string
+		labels      string
+		value       float64
+	}
+	var order []string
+	byKey := make(map[string]*series)
+	for _, point := range points {
+		key := seriesKey(point)
+		s, ok := byKey[key]
+		if !ok {
+			var labels []string
+			for _, name := range sortedKeys(point.Tags) {
+				labels = append(labels, name+`="`+prometheusEscape(point.Tags[name])+`"`)
+			}
+			s = &series{measurement: point.Measurement, labels: strings.Join(labels, ",")}
+			byKey[key] = s
+			order = append(order, key)
+		}
+		s.value += point.Value
+	}
+
+	bw := bufio.NewWriter(w)
+	typed := make(map[string]bool)
+	for _, key := range order {
+		s := byKey[key]
+		if !typed[s.measurement] {
+			fmt.Fprintf(bw, "# TYPE %s gauge\n", s.measurement)
+			typed[s.measurement] = true
+		}
+		fmt.Fprintf(bw, "%s{%s} %s\n", s.measurement, s.labels, strconv.FormatFloat(s.value, 'f', -1, 64))
+	}
+	return bw.Flush()
+}
+
+// PushTimeseries sends an encoded export: a POST of line protocol to an
+// InfluxDB write endpoint, or a PUT of the text format to a Pushgateway job,
+// which replaces the job's previous metrics
+func PushTimeseries(ctx context.Context, client *http.Client, url, format string, headers map[string]string, body []byte) error {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	method, contentType := http.MethodPost, "text/plain; charset=utf-8"
+	if format == "prometheus" {
+		method, contentType = http.MethodPut, "text/plain; version=0.0.4"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push time series: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("push to %s returned %s: %s", url, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// seriesKey identifies a point's series: measurement and tags
+func seriesKey(point Point) string {
+	var b strings.Builder
+	b.WriteString(point.Measurement)
+	for _, key := range sortedKeys(point.Tags) {
+		b.WriteString("\x00" + key + "=" + point.Tags[key])
+	}
+	return b.String()
+}
+
+func sortedKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// influxEscape backslash-escapes the given special characters
+func influxEscape(s, special string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func prometheusEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,136 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// defaultWebhookBatch is the number of transactions posted per request when unset
+const defaultWebhookBatch = 100
+
+// Compressions are the values of a webhook target's compress setting: none
+// sends plain JSON, gzip always compresses, and auto compresses until the
+// server answers 415 Unsupported Media Type, then resends and continues
+// uncompressed
+var Compressions = []string{"", "none", "gzip", "auto"}
+
+// WebhookExporter POSTs transactions as JSON batches to a URL
+type WebhookExporter struct {
+	URL       string
+	BatchSize int
+	Headers   map[string]string
+	Compress  string       // none, gzip or auto (see Compressions)
+	Client    *http.Client // Defaults to a client with a 30s timeout
+
+	// Logf, when set, is told each batch's payload size (--verbose)
+	Logf func(format string, args ...interface{})
+}
+
+// webhookPayload is the body of each webhook request
+type webhookPayload struct {
+	Transactions []blend.Transaction `json:"transactions"`
+	Fingerprints []string            `json:"fingerprints"` // Of each transaction, in order, for deduplication
+	Batch        int                 `json:"batch"`
+	SentAt       time.Time           `json:"sent_at"`
+}
+
+// Export posts the transactions in batches, stopping at the first failed batch
+func (e *WebhookExporter) Export(ctx context.Context, transactions []blend.Transaction, progress ProgressFunc) error {
+	client := e.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	size := e.BatchSize
+	if size <= 0 {
+		size = defaultWebhookBatch
+	}
+	compress := e.Compress == "gzip" || e.Compress == "auto"
+
+	for start, batch := 0, 1; start < len(transactions); start, batch = start+size, batch+1 {
+		end := start + size
+		if end > len(transactions) {
+			end = len(transactions)
+		}
+
+		body, err := json.Marshal(webhookPayload{
+			Transactions: transactions[start:end],
+			Fingerprints: blend.Fingerprints(transactions[start:end]),
+			Batch:        batch,
+			SentAt:       time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal batch %d: %w", batch, err)
+		}
+
+		status, err := e.send(ctx, client, batch, body, compress)
+		if err == nil && status == http.StatusUnsupportedMediaType && compress && e.Compress == "auto" {
+			// The server doesn't take gzip; resend, and send the rest plain
+			compress = false
+			e.logf("batch %d: server rejected gzip, sending uncompressed\n", batch)
+			status, err = e.send(ctx, client, batch, body, false)
+		}
+		if err != nil {
+			return fmt.Errorf("batch %d: %w", batch, err)
+		}
+		if status < 200 || status >= 300 {
+			return fmt.Errorf("batch %d: webhook returned %d %s", batch, status, http.StatusText(status))
+		}
+
+		progress(end, len(transactions))
+	}
+
+	return nil
+}
+
+// send posts one batch, gzipped when compress is set, and returns the
+// response status
+func (e *WebhookExporter) send(ctx context.Context, client *http.Client, batch int, body []byte, compress bool) (int, error) {
+	payload := body
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return 0, fmt.Errorf("failed to compress request: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return 0, fmt.Errorf("failed to compress request: %w", err)
+		}
+		payload = buf.Bytes()
+		e.logf("batch %d: %d bytes (%d gzipped)\n", batch, len(body), len(payload))
+	} else {
+		e.logf("batch %d: %d bytes\n", batch, len(body))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for name, value := range e.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// logf reports a payload detail when the exporter has a logger
+func (e *WebhookExporter) logf(format string, args ...interface{}) {
+	if e.Logf != nil {
+		e.Logf(format, args...)
+	}
+}
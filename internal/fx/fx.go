@@ -0,0 +1,244 @@
+package fx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/safefile"
+)
+
+// DefaultRatesURL serves ECB reference rates for any past business day.
+// {date}, {from} and {to} are substituted per request.
+const DefaultRatesURL = "https://api.frankfurter.app/{date}?from={from}&to={to}"
+
+// dateFormat is the day granularity rates are fetched and cached at
+const dateFormat = "2006-01-02"
+
+// Source provides the exchange rate between two currencies on a given day
+type Source interface {
+	Rate(date time.Time, from, to string) (float64, error)
+}
+
+// HTTPSource fetches rates from a JSON API returning {"rates": {"<to>": rate}}
+type HTTPSource struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewHTTPSource creates an HTTP rate source; an empty URL uses DefaultRatesURL
+func NewHTTPSource(url string, timeout time.Duration) *HTTPSource {
+	if url == "" {
+		url = DefaultRatesURL
+	}
+	return &HTTPSource{
+		URL:        url,
+		HTTPClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// ratesResponse is the subset of the rates API response we use
+type ratesResponse struct {
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Rate fetches the rate for one day
+func (s *HTTPSource) Rate(date time.Time, from, to string) (float64, error) {
+	url := strings.NewReplacer(
+		"{date}", date.Format(dateFormat),
+		"{from}", from,
+		"{to}", to,
+	).Replace(s.URL)
+
+	resp, err := s.HTTPClient.Get(url)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch rate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("rate API returned status %d for %s %s→%s", resp.StatusCode, date.Format(dateFormat), from, to)
+	}
+
+	var response ratesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, fmt.Errorf("failed to decode rate response: %w", err)
+	}
+
+	rate, ok := response.Rates[to]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("no %s→%s rate for %s", from, to, date.Format(dateFormat))
+	}
+
+	return rate, nil
+}
+
+// Cache persists historical rates so each day/pair is fetched only once.
+// Historical rates never change, so entries don't expire.
+type Cache struct {
+	path  string
+	mu    sync.Mutex
+	rates map[string]float64
+	dirty bool
+}
+
+// OpenCache loads the cache file, starting empty if it doesn't exist
+func OpenCache(path string) (*Cache, error) {
+	cache := &Cache{path: path, rates: make(map[string]float64)}
+	if path == "" {
+		return cache, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cache.rates); err != nil {
+		return nil, fmt.Errorf("failed to parse rate cache %s: %w", path, err)
+	}
+
+	return cache, nil
+}
+
+// cacheKey identifies a rate by day and currency pair
+func cacheKey(date time.Time, from, to string) string {
+	return date.Format(dateFormat) + ":" + from + ":" + to
+}
+
+// Get returns a cached rate
+func (c *Cache) Get(date time.Time, from, to string) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rate, ok := c.rates[cacheKey(date, from, to)]
+	return rate, ok
+}
+
+// Put stores a rate
+func (c *Cache) Put(date time.Time, from, to string, rate float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rates[cacheKey(date, from, to)] = rate
+	c.dirty = true
+}
+
+// Save writes the cache to disk if it changed
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty || c.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create rate cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(c.rates, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rate cache: %w", err)
+	}
+
+	if err := safefile.Write(c.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write rate cache: %w", err)
+	}
+
+	c.dirty = false
+	return nil
+}
+
+// Converter converts amounts using the rate as of the transaction date
+type Converter struct {
+	source   Source
+	cache    *Cache
+	rounding money.RoundingMode
+}
+
+// NewConverter creates a converter backed by a source and a cache
+func NewConverter(source Source, cache *Cache) *Converter {
+	return &Converter{source: source, cache: cache, rounding: money.DefaultRounding}
+}
+
+// SetRounding sets how converted amounts are rounded to paisa
+func (c *Converter) SetRounding(mode money.RoundingMode) {
+	c.rounding = mode
+}
+
+// Rate returns the from→to rate on the given day, consulting the cache first
+func (c *Converter) Rate(date time.Time, from, to string) (float64, error) {
+	from = strings.ToUpper(from)
+	to = strings.ToUpper(to)
+	if from == to {
+		return 1, nil
+	}
+	if from == "" || to == "" {
+		return 0, fmt.Errorf("currency is required")
+	}
+
+	if rate, ok := c.cache.Get(date, from, to); ok {
+		return rate, nil
+	}
+
+	rate, err := c.source.Rate(date, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	c.cache.Put(date, from, to, rate)
+	return rate, nil
+}
+
+// Convert converts an amount at the rate in effect on date, rounded to paisa
+func (c *Converter) Convert(amount float64, from, to string, date time.Time) (float64, error) {
+	rate, err := c.Rate(date, from, to)
+	if err != nil {
+		return 0, err
+	}
+	return money.FromFloat(amount, c.rounding).Mul(rate, c.rounding).Float64(), nil
+}
+
+// ConvertTransaction returns a copy of txn with its amount in base, converted
+// at the rate on the transaction date. A transaction without a currency is
+// taken to be in base already.
+func (c *Converter) ConvertTransaction(txn blend.Transaction, base string) (blend.Transaction, error) {
+	if txn.Currency == "" || strings.EqualFold(txn.Currency, base) {
+		return txn, nil
+	}
+
+	amount, err := c.Convert(txn.Amount, txn.Currency, base, txn.TxnTimestamp)
+	if err != nil {
+		return txn, fmt.Errorf("failed to convert transaction %s: %w", txn.UUID, err)
+	}
+	txn.Amount = amount
+	txn.Currency = strings.ToUpper(base)
+	return txn, nil
+}
+
+// Close persists any newly fetched rates
+func (c *Converter) Close() error {
+	return c.cache.Save()
+}
+
+// New creates a converter from the fx configuration section
+func New(cfg *config.Config) (*Converter, error) {
+	cache, err := OpenCache(cfg.FX.CacheFile)
+	if err != nil {
+		return nil, err
+	}
+	source := NewHTTPSource(cfg.FX.RatesURL, cfg.Bend.Timeout)
+	converter := NewConverter(source, cache)
+	converter.SetRounding(cfg.RoundingMode())
+	return converter, nil
+}
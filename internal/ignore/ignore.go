@@ -0,0 +1,238 @@
+// Package ignore reads .fintrackignore files, which use gitignore syntax, and
+// tells the commands that scan directories (import --dir, migrate staging,
+// backup create --receipts) which files to leave out.
+package ignore
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FileName is the name of the ignore file
+const FileName = ".fintrackignore"
+
+// Rule is one pattern of an ignore file
+type Rule struct {
+	Pattern string // As written, without the leading "!"
+	Line    int
+	Negate  bool // A "!" pattern, which re-includes what an earlier one ignored
+
+	dirOnly  bool // Trailing "/": matches directories only
+	anchored bool // Contains a "/": matched against the path from the ignore file's directory
+	re       *regexp.Regexp
+}
+
+// Matcher holds the rules of one ignore file
+type Matcher struct {
+	File  string // Path of the ignore file, empty when there is none
+	Rules []Rule
+
+	base string // Directory anchored patterns are relative to
+}
+
+// Parse reads ignore rules from data; base is the directory anchored patterns
+// are relative to
+func Parse(data []byte, base string) (*Matcher, error) {
+	m := &Matcher{base: base}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		rule, ok, err := parseRule(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if ok {
+			rule.Line = line
+			m.Rules = append(m.Rules, rule)
+		}
+	}
+	return m, scanner.Err()
+}
+
+// Load reads the ignore file at path
+func Load(path string) (*Matcher, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	base, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	m, err := Parse(data, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	m.File = path
+	return m, nil
+}
+
+// Find loads the .fintrackignore in dir or the nearest directory above it,
+// the way git finds its repository. With none, the matcher ignores nothing.
+func Find(dir string) (*Matcher, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	for {
+		path := filepath.Join(abs, FileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return Load(path)
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return &Matcher{}, nil
+		}
+		abs = parent
+	}
+}
+
+// Match returns the rule deciding whether path is ignored, or nil when no
+// rule matches it. Paths under the ignore file's directory are matched like
+// git does, a file in an ignored directory being ignored too; paths elsewhere
+// (a staging directory under ~/.local/share, say) only by their name, so
+// patterns with a "/" don't apply to them.
+func (m *Matcher) Match(path string, isDir bool) *Rule {
+	if m == nil || len(m.Rules) == 0 {
+		return nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	rel, err := filepath.Rel(m.base, abs)
+	if m.base == "" || err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return m.match(filepath.Base(abs), filepath.Base(abs), isDir, false)
+	}
+	if rel == "." {
+		return nil
+	}
+
+	// Each parent directory first: nothing under an ignored one comes back
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := range parts {
+		last := i == len(parts)-1
+		rule := m.match(strings.Join(parts[:i+1], "/"), parts[i], isDir || !last, true)
+		if last || (rule != nil && !rule.Negate) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Ignored reports whether path is ignored
+func (m *Matcher) Ignored(path string, isDir bool) bool {
+	rule := m.Match(path, isDir)
+	return rule != nil && !rule.Negate
+}
+
+// match returns the last rule matching a path, given from the ignore file's
+// directory (when inBase) and by its last element
+func (m *Matcher) match(rel, name string, isDir, inBase bool) *Rule {
+	for i := len(m.Rules) - 1; i >= 0; i-- {
+		rule := &m.Rules[i]
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		switch {
+		case rule.anchored:
+			if inBase && rule.re.MatchString(rel) {
+				return rule
+			}
+		case rule.re.MatchString(name):
+			return rule
+		}
+	}
+	return nil
+}
+
+// parseRule parses one line of an ignore file; ok is false for blank lines
+// and comments
+func parseRule(line string) (rule Rule, ok bool, err error) {
+	line = trimTrailingSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return Rule{}, false, nil
+	}
+
+	if strings.HasPrefix(line, "!") {
+		rule.Negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	rule.Pattern = line
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	rule.anchored = strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return Rule{}, false, nil
+	}
+
+	expr, err := patternRegexp(line)
+	if err != nil {
+		return Rule{}, false, err
+	}
+	if rule.re, err = regexp.Compile(expr); err != nil {
+		return Rule{}, false, fmt.Errorf("invalid pattern %q: %w", rule.Pattern, err)
+	}
+	return rule, true, nil
+}
+
+// patternRegexp translates a gitignore glob into an anchored regular
+// expression
+func patternRegexp(pattern string) (string, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			// Any number of leading directories, including none
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**") && i+2 == len(pattern):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated [ in pattern %q", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String(), nil
+}
+
+// trimTrailingSpace drops trailing spaces unless escaped with a backslash
+func trimTrailingSpace(line string) string {
+	line = strings.TrimRight(line, "\r")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
@@ -0,0 +1,276 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// CAMT053Importer reads ISO 20022 camt.053 bank-to-customer statements. Only
+// booked entries are imported; pending ones may still change.
+type CAMT053Importer struct{}
+
+// camtDocument is the part of a camt.053 document that becomes transactions.
+// Element names are matched without their namespace, so every camt.053
+// version (001.02 to 001.13) decodes the same.
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	IBAN     string      `xml:"Acct>Id>IBAN"`
+	Other    string      `xml:"Acct>Id>Othr>Id"`
+	Currency string      `xml:"Acct>Ccy"`
+	Entries  []camtEntry `xml:"Ntry"`
+}
+
+type camtEntry struct {
+	Amount       camtAmount      `xml:"Amt"`
+	CreditDebit  string          `xml:"CdtDbtInd"`
+	Reversal     bool            `xml:"RvslInd"`
+	Status       camtStatus      `xml:"Sts"`
+	BookingDate  camtDate        `xml:"BookgDt"`
+	ValueDate    camtDate        `xml:"ValDt"`
+	ServicerRef  string          `xml:"AcctSvcrRef"`
+	EntryRef     string          `xml:"NtryRef"`
+	Details      []camtTxDetails `xml:"NtryDtls>TxDtls"`
+	AdditionalTx string          `xml:"AddtlNtryInf"`
+}
+
+type camtTxDetails struct {
+	Amount       *camtAmount `xml:"Amt"`
+	CreditDebit  string      `xml:"CdtDbtInd"`
+	EndToEndID   string      `xml:"Refs>EndToEndId"`
+	TxID         string      `xml:"Refs>TxId"`
+	ServicerRef  string      `xml:"Refs>AcctSvcrRef"`
+	Unstructured []string    `xml:"RmtInf>Ustrd"`
+	CreditorRef  string      `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	Debtor       string      `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPty    string      `xml:"RltdPties>Dbtr>Pty>Nm"` // camt.053.001.08 and later
+	Creditor     string      `xml:"RltdPties>Cdtr>Nm"`
+	CreditorPty  string      `xml:"RltdPties>Cdtr>Pty>Nm"`
+	Additional   string      `xml:"AddtlTxInf"`
+}
+
+// camtStatus is the entry status: text up to camt.053.001.08, a code after
+type camtStatus struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+// Name returns the format name
+func (c *CAMT053Importer) Name() string {
+	return "camt053"
+}
+
+// Detect looks for the camt.053 namespace or root element
+func (c *CAMT053Importer) Detect(head []byte, filename string) bool {
+	if bytes.Contains(head, []byte("camt.053")) || bytes.Contains(head, []byte("BkToCstmrStmt")) {
+		return true
+	}
+	return strings.EqualFold(filepath.Ext(filename), ".camt")
+}
+
+// Parse reads the booked entries of every statement in the document. A
+// batch entry whose transaction details carry their own amounts becomes one
+// transaction per detail.
+func (c *CAMT053Importer) Parse(data []byte, opts Options) ([]blend.Transaction, error) {
+	var doc camtDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid camt.053 XML: %w", err)
+	}
+	if len(doc.Statements) == 0 {
+		return nil, fmt.Errorf("no statements found")
+	}
+
+	var transactions []blend.Transaction
+	for _, stmt := range doc.Statements {
+		account := stmt.IBAN
+		if account == "" {
+			account = stmt.Other
+		}
+
+		for i, entry := range stmt.Entries {
+			if !entry.booked() {
+				continue
+			}
+			date, err := entry.date()
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+
+			parts, err := entry.split()
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+			for j, part := range parts {
+				txn := newTransaction(date, part.amount, part.narration, part.reference)
+				txn.AccountID = strings.TrimSpace(account)
+				txn.Currency = strings.ToUpper(part.currency)
+				if txn.Currency == "" {
+					txn.Currency = strings.ToUpper(stmt.Currency)
+				}
+				txn.TransactionID = entry.id(j, len(parts))
+				transactions = append(transactions, txn)
+			}
+		}
+	}
+	return transactions, nil
+}
+
+// booked reports whether the entry is booked rather than pending or informational
+func (e *camtEntry) booked() bool {
+	status := firstNonEmpty(e.Status.Code, e.Status.Text)
+	return status == "" || strings.EqualFold(status, "BOOK")
+}
+
+// date is the booking date, or the value date when the bank leaves it out
+func (e *camtEntry) date() (time.Time, error) {
+	for _, d := range []camtDate{e.BookingDate, e.ValueDate} {
+		if d.Date != "" {
+			return time.ParseInLocation("2006-01-02", strings.TrimSpace(d.Date), time.Local)
+		}
+		if d.DateTime != "" {
+			return time.Parse(time.RFC3339, strings.TrimSpace(d.DateTime))
+		}
+	}
+	return time.Time{}, fmt.Errorf("no booking or value date")
+}
+
+// id is the bank's reference for the entry, suffixed for split batch entries
+func (e *camtEntry) id(part, parts int) string {
+	ref := strings.TrimSpace(e.ServicerRef)
+	if ref == "" {
+		ref = strings.TrimSpace(e.EntryRef)
+	}
+	if ref == "" || parts == 1 {
+		return ref
+	}
+	return fmt.Sprintf("%s/%d", ref, part+1)
+}
+
+// camtPart is one transaction of an entry
+type camtPart struct {
+	amount    float64
+	currency  string
+	narration string
+	reference string
+}
+
+// split turns an entry into its transactions
+func (e *camtEntry) split() ([]camtPart, error) {
+	debit := isDebit(e.CreditDebit, e.Reversal)
+
+	if len(e.Details) > 1 {
+		parts := make([]camtPart, 0, len(e.Details))
+		for _, details := range e.Details {
+			if details.Amount == nil {
+				parts = nil
+				break // Batch without per-transaction amounts: keep it whole
+			}
+			detailDebit := debit
+			if details.CreditDebit != "" {
+				detailDebit = isDebit(details.CreditDebit, e.Reversal)
+			}
+			amount, err := signedCAMTAmount(*details.Amount, detailDebit)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, camtPart{
+				amount:    amount,
+				currency:  details.Amount.Currency,
+				narration: details.narration(detailDebit, ""),
+				reference: details.reference(),
+			})
+		}
+		if parts != nil {
+			return parts, nil
+		}
+	}
+
+	amount, err := signedCAMTAmount(e.Amount, debit)
+	if err != nil {
+		return nil, err
+	}
+	part := camtPart{amount: amount, currency: e.Amount.Currency, narration: strings.TrimSpace(e.AdditionalTx)}
+	if len(e.Details) > 0 {
+		part.narration = e.Details[0].narration(debit, e.AdditionalTx)
+		part.reference = e.Details[0].reference()
+	}
+	if part.reference == "" {
+		part.reference = strings.TrimSpace(e.ServicerRef)
+	}
+	return []camtPart{part}, nil
+}
+
+// narration is the counterparty and the remittance information
+func (d *camtTxDetails) narration(debit bool, fallback string) string {
+	counterparty := firstNonEmpty(d.Debtor, d.DebtorPty)
+	if debit {
+		counterparty = firstNonEmpty(d.Creditor, d.CreditorPty)
+	}
+
+	remittance := strings.Join(d.Unstructured, " ")
+	if remittance == "" {
+		remittance = d.CreditorRef
+	}
+	if remittance == "" {
+		remittance = firstNonEmpty(d.Additional, fallback)
+	}
+	return strings.Join(strings.Fields(counterparty+" "+remittance), " ")
+}
+
+// reference prefers the payer's end-to-end reference, which is what invoices
+// and payment confirmations quote
+func (d *camtTxDetails) reference() string {
+	if ref := strings.TrimSpace(d.EndToEndID); ref != "" && !strings.EqualFold(ref, "NOTPROVIDED") {
+		return ref
+	}
+	if ref := strings.TrimSpace(d.CreditorRef); ref != "" {
+		return ref
+	}
+	return firstNonEmpty(d.TxID, d.ServicerRef)
+}
+
+// isDebit reports whether an entry takes money out; a reversed debit adds it back
+func isDebit(creditDebit string, reversal bool) bool {
+	debit := strings.EqualFold(strings.TrimSpace(creditDebit), "DBIT")
+	return debit != reversal
+}
+
+// signedCAMTAmount reads an amount, negative for debits
+func signedCAMTAmount(amount camtAmount, debit bool) (float64, error) {
+	value, err := parseAmount(amount.Value)
+	if err != nil {
+		return 0, err
+	}
+	if debit {
+		value = -value
+	}
+	return value, nil
+}
+
+// firstNonEmpty returns the first non-blank string, trimmed
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/plugin"
+)
+
+// CommandImporter is a plugin: an external program that reads the file on
+// stdin and writes its transactions to stdout as a JSON array of
+// PluginTransaction
+type CommandImporter struct {
+	Format  string
+	Command string   // Shell command; the file's path is in FINTRACK_IMPORT_FILE
+	Match   []string // Filename globs or text near the start of the file
+}
+
+// PluginTransaction is what a command importer writes for each transaction
+type PluginTransaction struct {
+	Date      string  `json:"date"`   // YYYY-MM-DD or RFC 3339
+	Amount    float64 `json:"amount"` // Negative for money leaving the account
+	Narration string  `json:"narration"`
+	Reference string  `json:"reference,omitempty"`
+	ID        string  `json:"id,omitempty"` // Stable ID from the statement, if it has one
+	AccountID string  `json:"account_id,omitempty"`
+	Currency  string  `json:"currency,omitempty"`
+}
+
+// Name returns the format name
+func (c *CommandImporter) Name() string {
+	return c.Format
+}
+
+// Detect matches the configured patterns; without any the importer is only
+// used by name
+func (c *CommandImporter) Detect(head []byte, filename string) bool {
+	return len(c.Match) > 0 && matches(c.Match, head, filename)
+}
+
+// Parse runs the command over the file
+func (c *CommandImporter) Parse(data []byte, opts Options) ([]blend.Transaction, error) {
+	cmd := plugin.ShellCommand(context.Background(), c.Command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(plugin.Environ(), "FINTRACK_IMPORT_FILE="+opts.Path)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("importer command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var entries []PluginTransaction
+	if err := json.Unmarshal(stdout.Bytes(), &entries); err != nil {
+		return nil, fmt.Errorf("importer command wrote invalid JSON: %w", err)
+	}
+
+	transactions := make([]blend.Transaction, 0, len(entries))
+	for i, entry := range entries {
+		date, err := parseDate(entry.Date, "")
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+		txn := newTransaction(date, entry.Amount, entry.Narration, entry.Reference)
+		txn.TransactionID = entry.ID
+		txn.AccountID = entry.AccountID
+		txn.Currency = strings.ToUpper(entry.Currency)
+		transactions = append(transactions, txn)
+	}
+	return transactions, nil
+}
+
+// FromConfig builds a custom importer declared under importers
+func FromConfig(name string, cfg config.ImporterConfig) (Importer, error) {
+	switch strings.ToLower(cfg.Type) {
+	case "csv":
+		importer := &CSVImporter{Format: name, Columns: cfg.Columns, DateFormat: cfg.DateFormat, Match: cfg.Match}
+		if cfg.Delimiter != "" {
+			importer.Delimiter = []rune(cfg.Delimiter)[0]
+		}
+		return importer, nil
+	case "command":
+		return &CommandImporter{Format: name, Command: cfg.Command, Match: cfg.Match}, nil
+	default:
+		return nil, fmt.Errorf("importers.%s: unknown type %q (use csv or command)", name, cfg.Type)
+	}
+}
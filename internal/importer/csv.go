@@ -0,0 +1,317 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+)
+
+// CSVImporter reads bank CSV statements. With no Columns it finds the header
+// row and its columns by their usual names, which covers most banks' exports;
+// custom importers map the columns explicitly.
+type CSVImporter struct {
+	Format     string            // Name; "csv" when empty
+	Columns    config.CSVColumns // Explicit header names
+	DateFormat string            // Go layout; common layouts are tried when empty
+	Delimiter  rune              // Sniffed when zero
+	Match      []string          // Filename globs or text near the start of the file
+}
+
+// Usual header names, most specific first
+var (
+	dateHeaders      = []string{"transaction date", "txn date", "tran date", "posting date", "date"}
+	narrationHeaders = []string{"narration", "description", "particulars", "details", "remarks", "payee", "memo"}
+	debitHeaders     = []string{"debit", "withdrawal"}
+	creditHeaders    = []string{"credit", "deposit"}
+	amountHeaders    = []string{"amount"}
+	indicatorHeaders = []string{"dr/cr", "cr/dr", "dr / cr", "debit/credit"}
+	referenceHeaders = []string{"reference", "ref no", "ref", "chq", "cheque"}
+)
+
+// maxHeaderRow is how far down preamble lines (bank name, account number,
+// period) may push the header row
+const maxHeaderRow = 30
+
+// Name returns the format name
+func (c *CSVImporter) Name() string {
+	if c.Format == "" {
+		return "csv"
+	}
+	return c.Format
+}
+
+// Detect matches the configured patterns, or for the built-in importer a
+// .csv file or any file with a recognizable header row
+func (c *CSVImporter) Detect(head []byte, filename string) bool {
+	if len(c.Match) > 0 {
+		return matches(c.Match, head, filename)
+	}
+	if c.Format != "" {
+		return false // Custom importers without patterns are only used by name
+	}
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return true
+	}
+	records := readCSV(head, sniffDelimiter(head))
+	_, _, err := c.findHeader(records)
+	return err == nil
+}
+
+// Parse reads every row below the header. Rows without a date, such as
+// opening balance lines and footers, are skipped.
+func (c *CSVImporter) Parse(data []byte, opts Options) ([]blend.Transaction, error) {
+	delimiter := c.Delimiter
+	if delimiter == 0 {
+		delimiter = sniffDelimiter(data)
+	}
+	records := readCSV(data, delimiter)
+
+	headerRow, cols, err := c.findHeader(records)
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []blend.Transaction
+	for i, row := range records[headerRow+1:] {
+		line := headerRow + i + 2
+		date, err := parseDate(cell(row, cols.date), c.DateFormat)
+		if err != nil {
+			continue
+		}
+
+		amount, err := cols.amount(row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", line, err)
+		}
+		transactions = append(transactions, newTransaction(date, amount, cell(row, cols.narration), cell(row, cols.reference)))
+	}
+	return transactions, nil
+}
+
+// csvColumns are the indexes of the columns in a statement; -1 is absent
+type csvColumns struct {
+	date, narration, amountCol, debit, credit, indicator, reference int
+}
+
+// amount returns the signed amount of a row
+func (cols csvColumns) amount(row []string) (float64, error) {
+	if cols.amountCol < 0 {
+		// Separate debit and credit columns, one of them empty
+		if debit := cell(row, cols.debit); debit != "" {
+			amount, err := parseAmount(debit)
+			if err != nil {
+				return 0, err
+			}
+			if amount != 0 {
+				return -math.Abs(amount), nil
+			}
+		}
+		credit := cell(row, cols.credit)
+		if credit == "" {
+			return 0, fmt.Errorf("no debit or credit amount")
+		}
+		amount, err := parseAmount(credit)
+		return math.Abs(amount), err
+	}
+
+	amount, err := parseAmount(cell(row, cols.amountCol))
+	if err != nil {
+		return 0, err
+	}
+	indicator := strings.ToLower(cell(row, cols.indicator))
+	switch {
+	case strings.HasPrefix(indicator, "d"):
+		return -math.Abs(amount), nil
+	case strings.HasPrefix(indicator, "c"):
+		return math.Abs(amount), nil
+	}
+	return amount, nil
+}
+
+// findHeader locates the header row and the columns in it
+func (c *CSVImporter) findHeader(records [][]string) (int, csvColumns, error) {
+	for i, row := range records {
+		if i >= maxHeaderRow {
+			break
+		}
+		var cols csvColumns
+		var ok bool
+		if c.Columns.Date != "" {
+			cols, ok = configuredColumns(c.Columns, row)
+		} else {
+			cols, ok = guessColumns(row)
+		}
+		if ok {
+			return i, cols, nil
+		}
+	}
+
+	if c.Columns.Date != "" {
+		return 0, csvColumns{}, fmt.Errorf("no header row with a %q column", c.Columns.Date)
+	}
+	return 0, csvColumns{}, fmt.Errorf("no header row with date and amount (or debit and credit) columns")
+}
+
+// configuredColumns finds explicitly named columns in a header row
+func configuredColumns(c config.CSVColumns, row []string) (csvColumns, bool) {
+	find := func(name string) int {
+		if name == "" {
+			return -1
+		}
+		for i, header := range row {
+			if strings.EqualFold(strings.TrimSpace(header), name) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	cols := csvColumns{
+		date:      find(c.Date),
+		narration: find(c.Narration),
+		amountCol: find(c.Amount),
+		debit:     find(c.Debit),
+		credit:    find(c.Credit),
+		indicator: find(c.Indicator),
+		reference: find(c.Reference),
+	}
+	return cols, cols.date >= 0 && (cols.amountCol >= 0 || cols.debit >= 0 && cols.credit >= 0)
+}
+
+// guessColumns recognizes a header row by the usual column names
+func guessColumns(row []string) (csvColumns, bool) {
+	headers := make([]string, len(row))
+	for i, header := range row {
+		headers[i] = strings.ToLower(strings.TrimSpace(header))
+	}
+	used := make(map[int]bool)
+
+	// Exact names first, then names containing a keyword ("Withdrawal Amt.")
+	find := func(keywords []string) int {
+		for _, exact := range []bool{true, false} {
+			for _, keyword := range keywords {
+				for i, header := range headers {
+					if used[i] || header == "" {
+						continue
+					}
+					if header == keyword || !exact && strings.Contains(header, keyword) {
+						used[i] = true
+						return i
+					}
+				}
+			}
+		}
+		return -1
+	}
+
+	var cols csvColumns
+	cols.indicator = find(indicatorHeaders)
+	cols.date = find(dateHeaders)
+	cols.debit = find(debitHeaders)
+	cols.credit = find(creditHeaders)
+	cols.amountCol = -1
+	if cols.debit < 0 || cols.credit < 0 {
+		cols.amountCol = find(amountHeaders)
+	}
+	cols.narration = find(narrationHeaders)
+	cols.reference = find(referenceHeaders)
+
+	return cols, cols.date >= 0 && (cols.amountCol >= 0 || cols.debit >= 0 && cols.credit >= 0)
+}
+
+// readCSV reads as many records as it can; a statement's preamble often has
+// a different number of fields than its table
+func readCSV(data []byte, delimiter rune) [][]string {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+
+	var records [][]string
+	for {
+		record, err := r.Read()
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); ok {
+				continue
+			}
+			break // io.EOF, or a truncated last line when sniffing
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// sniffDelimiter picks the most frequent of comma, semicolon, tab and pipe
+// in the first lines
+func sniffDelimiter(data []byte) rune {
+	sample := data
+	if len(sample) > sniffSize {
+		sample = sample[:sniffSize]
+	}
+	best, bestCount := ',', 0
+	for _, candidate := range []rune{',', ';', '\t', '|'} {
+		if count := bytes.Count(sample, []byte(string(candidate))); count > bestCount {
+			best, bestCount = candidate, count
+		}
+	}
+	return best
+}
+
+// cell returns a trimmed cell, or "" for a missing column
+func cell(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// Date layouts tried when a statement's layout isn't configured. Day first,
+// as Indian banks write dates.
+var dateLayouts = []string{
+	"2006-01-02", "2006-01-02 15:04:05", time.RFC3339,
+	"02/01/2006", "2/1/2006", "02/01/2006 15:04:05", "02/01/06", "2/1/06",
+	"02-01-2006", "2-1-2006", "02-01-06",
+	"02.01.2006", "2006/01/02",
+	"02-Jan-2006", "2-Jan-2006", "02-Jan-06", "02 Jan 2006", "2 Jan 2006", "02 Jan 06",
+	"Jan 2, 2006", "January 2, 2006",
+}
+
+// parseDate parses a statement date with layout, or the common layouts
+func parseDate(s, layout string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+	if layout != "" {
+		return time.ParseInLocation(layout, s, time.Local)
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
+
+// matches reports whether a filename glob matches the file's name, or any
+// other pattern appears in its first bytes
+func matches(patterns []string, head []byte, filename string) bool {
+	base := filepath.Base(filename)
+	for _, pattern := range patterns {
+		if strings.ContainsAny(pattern, "*?[") {
+			if ok, _ := filepath.Match(strings.ToLower(pattern), strings.ToLower(base)); ok {
+				return true
+			}
+		} else if bytes.Contains(head, []byte(pattern)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+// Package importer reads bank statement files (CSV, OFX, QIF, MT940,
+// camt.053 and custom formats) into transactions for the local store. Importers are kept
+// in a registry and picked by sniffing the file, so callers rarely need to
+// name the format.
+package importer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// SourceType is the Source of imported transactions, where Bend has e.g. "BANK"
+const SourceType = "IMPORT"
+
+// sniffSize is how much of a file Detect gets to look at
+const sniffSize = 4096
+
+// Importer parses one statement file format
+type Importer interface {
+	// Name identifies the format, e.g. "ofx"
+	Name() string
+	// Detect reports whether a file looks like this format, from its first
+	// bytes and its name
+	Detect(head []byte, filename string) bool
+	// Parse reads every transaction in the file. UUIDs, and the account and
+	// currency when the file doesn't name them, are filled in by Read.
+	Parse(data []byte, opts Options) ([]blend.Transaction, error)
+}
+
+// Options are what the caller knows about a file that it may not say itself
+type Options struct {
+	Path      string // The file being imported
+	AccountID string // Overrides the file's account; defaults to the file name
+	Currency  string // Used when the file has no currency
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Importer
+)
+
+// Register adds an importer. Importers registered later are tried first, so
+// custom formats win over the built-in ones; registering a name again
+// replaces the earlier importer.
+func Register(imp Importer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, existing := range registry {
+		if existing.Name() == imp.Name() {
+			registry = append(registry[:i], registry[i+1:]...)
+			break
+		}
+	}
+	registry = append(registry, imp)
+}
+
+// Names returns the registered formats in detection order
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for i := len(registry) - 1; i >= 0; i-- {
+		names = append(names, registry[i].Name())
+	}
+	return names
+}
+
+// Lookup returns the importer registered under name
+func Lookup(name string) (Importer, error) {
+	registryMu.RLock()
+	for _, imp := range registry {
+		if strings.EqualFold(imp.Name(), name) {
+			registryMu.RUnlock()
+			return imp, nil
+		}
+	}
+	registryMu.RUnlock()
+	return nil, fmt.Errorf("unknown import format %q (available: %s)", name, strings.Join(Names(), ", "))
+}
+
+// Detect picks the importer for a file from its content and name
+func Detect(data []byte, filename string) (Importer, error) {
+	head := data
+	if len(head) > sniffSize {
+		head = head[:sniffSize]
+	}
+	head = stripBOM(head)
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for i := len(registry) - 1; i >= 0; i-- {
+		if registry[i].Detect(head, filename) {
+			return registry[i], nil
+		}
+	}
+	return nil, fmt.Errorf("could not detect the format of %s (use --format)", filepath.Base(filename))
+}
+
+// Read parses a file with imp and completes its transactions: each gets a
+// UUID derived from its content, so importing the same file twice doesn't
+// duplicate anything, and a default account and currency.
+func Read(imp Importer, data []byte, opts Options) ([]blend.Transaction, error) {
+	transactions, err := imp.Parse(stripBOM(data), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s as %s: %w", filepath.Base(opts.Path), imp.Name(), err)
+	}
+
+	defaultAccount := opts.AccountID
+	if defaultAccount == "" {
+		base := filepath.Base(opts.Path)
+		defaultAccount = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	occurrences := make(map[string]int)
+	for i := range transactions {
+		txn := &transactions[i]
+		if opts.AccountID != "" || txn.AccountID == "" {
+			txn.AccountID = defaultAccount
+		}
+		if txn.Currency == "" {
+			txn.Currency = opts.Currency
+		}
+		txn.Source = SourceType
+
+		// Identical lines (two coffees on one day) stay distinct by position
+		key := contentKey(imp.Name(), txn)
+		occurrences[key]++
+		txn.UUID = contentUUID(key + "|" + strconv.Itoa(occurrences[key]))
+	}
+	return transactions, nil
+}
+
+// contentKey identifies a transaction by what the statement says about it
+func contentKey(format string, txn *blend.Transaction) string {
+	if txn.TransactionID != "" {
+		return strings.Join([]string{format, txn.AccountID, txn.TransactionID}, "|")
+	}
+	return strings.Join([]string{
+		format, txn.AccountID, txn.TxnTimestamp.Format("2006-01-02"),
+		strconv.FormatFloat(txn.SignedAmount(), 'f', -1, 64), txn.Narration, txn.Reference,
+	}, "|")
+}
+
+// contentUUID formats a hash of key as a version 5 style UUID
+func contentUUID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// newTransaction builds a transaction from a signed statement amount
+func newTransaction(date time.Time, amount float64, narration, reference string) blend.Transaction {
+	txnType := blend.TransactionTypeIncoming
+	if amount < 0 {
+		txnType = blend.TransactionTypeOutgoing
+	}
+	return blend.Transaction{
+		Amount:       math.Abs(amount),
+		TxnTimestamp: date,
+		Type:         txnType,
+		Narration:    strings.TrimSpace(narration),
+		Reference:    strings.TrimSpace(reference),
+		Kind:         "NORMAL",
+	}
+}
+
+// parseAmount reads a statement amount such as "1,234.50", "(12.00)",
+// "₹ 500" or "250.00 Dr"
+func parseAmount(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+	if lower := strings.ToLower(s); strings.HasSuffix(lower, "dr") || strings.HasSuffix(lower, "cr") {
+		negative = strings.HasSuffix(lower, "dr")
+		s = s[:len(s)-2]
+	}
+
+	cleaned := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' || r == '.' || r == '-' || r == '+' {
+			return r
+		}
+		return -1 // Currency symbols, codes, thousands separators
+	}, s)
+	if cleaned == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	amount, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if negative {
+		amount = -math.Abs(amount)
+	}
+	return amount, nil
+}
+
+// stripBOM removes a UTF-8 byte order mark, which spreadsheet exports often add
+func stripBOM(data []byte) []byte {
+	return bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+}
+
+func init() {
+	// Most general first: detection tries the most recently registered first
+	Register(&CSVImporter{})
+	Register(&QIFImporter{})
+	Register(&OFXImporter{})
+	Register(&MT940Importer{})
+	Register(&CAMT053Importer{})
+}
@@ -0,0 +1,226 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// MT940Importer reads SWIFT MT940 customer statements, as European and many
+// corporate banks export them
+type MT940Importer struct{}
+
+var (
+	mt940Tag = regexp.MustCompile(`^:(\d{2}[A-Z]?):(.*)$`)
+	// :61: value date, optional entry date, mark, optional funds code,
+	// amount, type code, customer reference, optional //bank reference
+	mt940Line = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d*)([NSF][A-Z0-9]{3})?([^/]*)(?://(.*))?`)
+
+	// German (DFÜ) :86: subfields such as ?20, and SEPA keywords within them
+	mt940Subfield = regexp.MustCompile(`\?(\d{2})`)
+	mt940SEPA     = regexp.MustCompile(`(EREF|KREF|MREF|CRED|DEBT|SVWZ|ABWA|ABWE|IBAN|BIC)\+`)
+)
+
+// mt940Codes are the codes of slash-structured :86: fields (/NAME/.../REMI/...)
+var mt940Codes = map[string]bool{
+	"TRTP": true, "CNTP": true, "NAME": true, "REMI": true, "EREF": true, "MARF": true,
+	"CSID": true, "IBAN": true, "BIC": true, "USTD": true, "STRD": true, "PURP": true,
+	"ULTC": true, "ULTD": true, "ORDP": true, "BENM": true, "ADDR": true, "ID": true,
+	"RTRN": true, "ISDT": true, "PREF": true, "CDTRREFTP": true, "CDTRREF": true,
+	"CD": true, "ISSR": true, "TYPE": true,
+}
+
+// Name returns the format name
+func (m *MT940Importer) Name() string {
+	return "mt940"
+}
+
+// Detect looks for the statement's reference and account or balance fields
+func (m *MT940Importer) Detect(head []byte, filename string) bool {
+	if bytes.Contains(head, []byte(":20:")) &&
+		(bytes.Contains(head, []byte(":25:")) || bytes.Contains(head, []byte(":60F:"))) {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".sta" || ext == ".mt940"
+}
+
+// mt940Field is one tagged field; continuation lines are joined with newlines
+type mt940Field struct {
+	tag, value string
+}
+
+// Parse reads the :61: statement lines of every statement in the file, with
+// the counterparty, purpose and reference from the :86: field after each
+func (m *MT940Importer) Parse(data []byte, opts Options) ([]blend.Transaction, error) {
+	var fields []mt940Field
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if match := mt940Tag.FindStringSubmatch(line); match != nil {
+			fields = append(fields, mt940Field{tag: match[1], value: match[2]})
+		} else if len(fields) > 0 && line != "-" && !strings.HasPrefix(line, "-}") {
+			fields[len(fields)-1].value += "\n" + line
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read: %w", err)
+	}
+
+	var (
+		transactions []blend.Transaction
+		account      string
+		currency     string
+		last         = -1 // Transaction the next :86: belongs to
+	)
+	for _, field := range fields {
+		switch field.tag {
+		case "25":
+			account = strings.TrimSpace(field.value)
+		case "60F", "60M":
+			// Opening balance: mark, date, currency, amount
+			if value := strings.TrimSpace(field.value); len(value) >= 10 {
+				currency = value[7:10]
+			}
+		case "61":
+			txn, err := mt940Entry(field.value)
+			if err != nil {
+				return nil, err
+			}
+			txn.AccountID = account
+			txn.Currency = currency
+			transactions = append(transactions, txn)
+			last = len(transactions) - 1
+		case "86":
+			if last >= 0 {
+				narration, reference := mt940Info(field.value)
+				if narration != "" {
+					transactions[last].Narration = narration
+				}
+				if reference != "" {
+					transactions[last].Reference = reference
+				}
+				last = -1
+			}
+		}
+	}
+	return transactions, nil
+}
+
+// mt940Entry converts a :61: statement line
+func mt940Entry(value string) (blend.Transaction, error) {
+	first, supplementary, _ := strings.Cut(value, "\n")
+	match := mt940Line.FindStringSubmatch(first)
+	if match == nil {
+		return blend.Transaction{}, fmt.Errorf("invalid :61: statement line %q", first)
+	}
+
+	date, err := time.ParseInLocation("060102", match[1], time.Local)
+	if err != nil {
+		return blend.Transaction{}, fmt.Errorf("invalid value date in %q", first)
+	}
+	amount, err := parseAmount(strings.Replace(match[5], ",", ".", 1))
+	if err != nil {
+		return blend.Transaction{}, err
+	}
+	if match[3] == "D" || match[3] == "RC" { // Debits and reversed credits leave the account
+		amount = -amount
+	}
+
+	reference := strings.TrimSpace(match[7])
+	if strings.EqualFold(reference, "NONREF") {
+		reference = ""
+	}
+	txn := newTransaction(date, amount, strings.TrimSpace(supplementary), reference)
+	txn.TransactionID = strings.TrimSpace(match[8])
+	return txn, nil
+}
+
+// mt940Info reads the :86: information to account owner, which banks fill in
+// one of three ways: German subfields (?00 posting text, ?20-?29 purpose,
+// ?32-?33 counterparty), slash codes (/NAME/ /REMI/ /EREF/), or free text.
+// It returns the counterparty and purpose as the narration, and the
+// end-to-end reference when there is one.
+func mt940Info(info string) (narration, reference string) {
+	switch {
+	case mt940Subfield.MatchString(info) && strings.Contains(info, "?2"):
+		fields := make(map[string]string)
+		joined := strings.ReplaceAll(info, "\n", "")
+		locs := mt940Subfield.FindAllStringSubmatchIndex(joined, -1)
+		for i, loc := range locs {
+			end := len(joined)
+			if i+1 < len(locs) {
+				end = locs[i+1][0]
+			}
+			fields[joined[loc[2]:loc[3]]] += joined[loc[1]:end]
+		}
+
+		var purpose strings.Builder
+		for _, code := range []string{"20", "21", "22", "23", "24", "25", "26", "27", "28", "29", "60", "61", "62", "63"} {
+			purpose.WriteString(fields[code])
+		}
+		sepa := mt940SEPAFields(purpose.String())
+		remittance := sepa["SVWZ"]
+		if remittance == "" && len(sepa) == 0 {
+			remittance = purpose.String()
+		}
+		if remittance == "" {
+			remittance = fields["00"]
+		}
+		narration = strings.TrimSpace(fields["32"] + fields["33"] + " " + remittance)
+		reference = firstNonEmpty(notProvided(sepa["EREF"]), sepa["KREF"], sepa["MREF"])
+
+	case strings.HasPrefix(strings.TrimSpace(info), "/"):
+		codes := make(map[string]string)
+		parts := strings.Split(strings.ReplaceAll(info, "\n", ""), "/")
+		code := ""
+		for _, part := range parts {
+			if mt940Codes[part] {
+				code = part
+				continue
+			}
+			if code != "" {
+				codes[code] = strings.TrimPrefix(codes[code]+"/"+part, "/")
+			}
+		}
+		for code, value := range codes {
+			codes[code] = strings.Trim(value, "/ ")
+		}
+		remittance := firstNonEmpty(codes["REMI"], codes["USTD"], codes["STRD"])
+		narration = strings.TrimSpace(codes["NAME"] + " " + remittance)
+		reference = notProvided(codes["EREF"])
+
+	default:
+		narration = info
+	}
+	return strings.Join(strings.Fields(narration), " "), strings.TrimSpace(reference)
+}
+
+// mt940SEPAFields splits a purpose such as "EREF+123SVWZ+Invoice 9" into its
+// SEPA keywords
+func mt940SEPAFields(purpose string) map[string]string {
+	fields := make(map[string]string)
+	locs := mt940SEPA.FindAllStringSubmatchIndex(purpose, -1)
+	for i, loc := range locs {
+		end := len(purpose)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		fields[purpose[loc[2]:loc[3]]] = strings.TrimSpace(purpose[loc[1]:end])
+	}
+	return fields
+}
+
+// notProvided blanks the placeholder banks put in empty reference fields
+func notProvided(ref string) string {
+	if strings.EqualFold(strings.TrimSpace(ref), "NOTPROVIDED") {
+		return ""
+	}
+	return ref
+}
@@ -0,0 +1,139 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// OFXImporter reads OFX (and QFX) statements, both the SGML flavour of OFX 1.x
+// where closing tags are optional and the XML of OFX 2.x
+type OFXImporter struct{}
+
+var (
+	ofxAccount  = regexp.MustCompile(`(?i)<ACCTID>\s*([^<\r\n]+)`)
+	ofxCurrency = regexp.MustCompile(`(?i)<CURDEF>\s*([^<\r\n]+)`)
+)
+
+// Name returns the format name
+func (o *OFXImporter) Name() string {
+	return "ofx"
+}
+
+// Detect looks for the OFX header or root element
+func (o *OFXImporter) Detect(head []byte, filename string) bool {
+	upper := bytes.ToUpper(head)
+	if bytes.Contains(upper, []byte("OFXHEADER")) || bytes.Contains(upper, []byte("<OFX>")) {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	return (ext == ".ofx" || ext == ".qfx") && bytes.Contains(upper, []byte("<OFX"))
+}
+
+// Parse reads every STMTTRN of the statement
+func (o *OFXImporter) Parse(data []byte, opts Options) ([]blend.Transaction, error) {
+	text := string(data)
+	accountID := firstMatch(ofxAccount, text)
+	currency := strings.ToUpper(firstMatch(ofxCurrency, text))
+
+	var transactions []blend.Transaction
+	for _, block := range ofxBlocks(text) {
+		txn, err := ofxEntry(block)
+		if err != nil {
+			return nil, err
+		}
+		txn.AccountID = accountID
+		txn.Currency = currency
+		transactions = append(transactions, txn)
+	}
+
+	if len(transactions) == 0 && !strings.Contains(strings.ToUpper(text), "<BANKTRANLIST>") {
+		return nil, fmt.Errorf("no statement transactions found")
+	}
+	return transactions, nil
+}
+
+// ofxBlocks splits out the STMTTRN blocks. Without closing tags a block ends
+// where the next one, or the transaction list, does.
+func ofxBlocks(text string) []string {
+	upper := strings.ToUpper(text)
+	var blocks []string
+	for start := strings.Index(upper, "<STMTTRN>"); start >= 0; {
+		body := start + len("<STMTTRN>")
+		end := len(text)
+		for _, closer := range []string{"</STMTTRN>", "<STMTTRN>", "</BANKTRANLIST>"} {
+			if i := strings.Index(upper[body:], closer); i >= 0 && body+i < end {
+				end = body + i
+			}
+		}
+		blocks = append(blocks, text[body:end])
+
+		next := strings.Index(upper[end:], "<STMTTRN>")
+		if next < 0 {
+			break
+		}
+		start = end + next
+	}
+	return blocks
+}
+
+// ofxEntry reads one STMTTRN block
+func ofxEntry(block string) (blend.Transaction, error) {
+	posted := ofxField(block, "DTPOSTED")
+	if len(posted) < 8 {
+		return blend.Transaction{}, fmt.Errorf("transaction without a valid DTPOSTED: %q", posted)
+	}
+	date, err := time.ParseInLocation("20060102", posted[:8], time.Local)
+	if err != nil {
+		return blend.Transaction{}, fmt.Errorf("invalid DTPOSTED %q", posted)
+	}
+
+	amount, err := parseAmount(ofxField(block, "TRNAMT"))
+	if err != nil {
+		return blend.Transaction{}, err
+	}
+
+	narration := ofxField(block, "NAME")
+	if memo := ofxField(block, "MEMO"); memo != "" && memo != narration {
+		narration = strings.TrimSpace(narration + " " + memo)
+	}
+	reference := ofxField(block, "CHECKNUM")
+	if reference == "" {
+		reference = ofxField(block, "REFNUM")
+	}
+
+	txn := newTransaction(date, amount, narration, reference)
+	txn.TransactionID = ofxField(block, "FITID")
+	return txn, nil
+}
+
+// ofxField returns the value of an element, closed or not
+func ofxField(block, tag string) string {
+	start := strings.Index(strings.ToUpper(block), "<"+tag+">")
+	if start < 0 {
+		return ""
+	}
+	value := block[start+len(tag)+2:]
+	if end := strings.IndexAny(value, "<\r\n"); end >= 0 {
+		value = value[:end]
+	}
+	return unescapeSGML(strings.TrimSpace(value))
+}
+
+// unescapeSGML decodes the character entities OFX uses
+func unescapeSGML(s string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'").Replace(s)
+}
+
+// firstMatch returns the first submatch of re in text, trimmed
+func firstMatch(re *regexp.Regexp, text string) string {
+	if m := re.FindStringSubmatch(text); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return ""
+}
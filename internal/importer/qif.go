@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// QIFImporter reads Quicken Interchange Format files: bank, cash and credit
+// card registers, optionally inside !Account blocks as fintrack's own QIF
+// export writes them
+type QIFImporter struct{}
+
+// QIF dates are month first; Quicken writes the year after an apostrophe
+var qifDateLayouts = []string{"01/02/2006", "1/2/2006", "01/02/06", "1/2/06", "2006-01-02", "01-02-2006", "1-2-2006"}
+
+// Name returns the format name
+func (q *QIFImporter) Name() string {
+	return "qif"
+}
+
+// Detect looks for a !Type or !Account header
+func (q *QIFImporter) Detect(head []byte, filename string) bool {
+	trimmed := bytes.TrimSpace(head)
+	if bytes.HasPrefix(trimmed, []byte("!Type:")) || bytes.HasPrefix(trimmed, []byte("!Account")) ||
+		bytes.HasPrefix(trimmed, []byte("!Option:")) {
+		return true
+	}
+	return strings.EqualFold(filepath.Ext(filename), ".qif")
+}
+
+// Parse reads every entry of the bank, cash and credit card registers
+func (q *QIFImporter) Parse(data []byte, opts Options) ([]blend.Transaction, error) {
+	var (
+		transactions []blend.Transaction
+		account      string
+		inAccount    bool // Inside an !Account header block
+		register     = true
+		entry        = map[byte]string{}
+		line         int
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "!Account"):
+			inAccount = true
+			continue
+		case strings.HasPrefix(text, "!Type:"):
+			kind := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(text, "!Type:")))
+			register = kind == "bank" || kind == "cash" || kind == "ccard" || kind == "oth a" || kind == "oth l"
+			continue
+		case strings.HasPrefix(text, "!"):
+			continue // Other options and headers
+		}
+
+		if text[0] == '^' {
+			if inAccount {
+				account, inAccount = entry['N'], false
+			} else if register && len(entry) > 0 {
+				txn, err := qifEntry(entry)
+				if err != nil {
+					return nil, fmt.Errorf("entry ending on line %d: %w", line, err)
+				}
+				txn.AccountID = account
+				transactions = append(transactions, txn)
+			}
+			entry = map[byte]string{}
+			continue
+		}
+		if _, ok := entry[text[0]]; !ok { // Split lines (S, E, $) repeat; keep the first
+			entry[text[0]] = strings.TrimSpace(text[1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read: %w", err)
+	}
+	return transactions, nil
+}
+
+// qifEntry converts one register entry
+func qifEntry(entry map[byte]string) (blend.Transaction, error) {
+	raw := strings.ReplaceAll(strings.ReplaceAll(entry['D'], "'", "/"), " ", "")
+	var date time.Time
+	var err error
+	for _, layout := range qifDateLayouts {
+		if date, err = time.ParseInLocation(layout, raw, time.Local); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return blend.Transaction{}, fmt.Errorf("invalid date %q", entry['D'])
+	}
+
+	amountText := entry['T']
+	if amountText == "" {
+		amountText = entry['U']
+	}
+	amount, err := parseAmount(amountText)
+	if err != nil {
+		return blend.Transaction{}, err
+	}
+
+	narration := entry['P']
+	if memo := entry['M']; memo != "" && memo != narration {
+		narration = strings.TrimSpace(narration + " " + memo)
+	}
+	return newTransaction(date, amount, narration, entry['N']), nil
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package keychain
+
+// The Windows Credential Manager only exists on Windows
+
+func credAvailable() bool { return false }
+
+func credGet(account string) (string, error) { return "", ErrUnsupported }
+
+func credSet(account, secret string) error { return ErrUnsupported }
+
+func credDelete(account string) error { return ErrUnsupported }
@@ -0,0 +1,113 @@
+//go:build windows
+
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// Windows Credential Manager, called through advapi32 directly so no helper
+// tool or cgo is needed. Secrets are generic credentials named
+// "fintrack:<account>", visible under Windows Credentials in the control panel.
+
+var (
+	advapi32        = syscall.NewLazyDLL("advapi32.dll")
+	procCredReadW   = advapi32.NewProc("CredReadW")
+	procCredWriteW  = advapi32.NewProc("CredWriteW")
+	procCredDeleteW = advapi32.NewProc("CredDeleteW")
+	procCredFree    = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	errNotFound             = syscall.Errno(1168) // ERROR_NOT_FOUND
+)
+
+// credential is the CREDENTIALW structure
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// credTarget is the Credential Manager name of account's secret
+func credTarget(account string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(Service + ":" + account)
+}
+
+func credAvailable() bool {
+	return procCredReadW.Find() == nil
+}
+
+func credGet(account string) (string, error) {
+	target, err := credTarget(account)
+	if err != nil {
+		return "", err
+	}
+
+	var cred *credential
+	ok, _, callErr := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ok == 0 {
+		if errors.Is(callErr, errNotFound) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to read secret from Credential Manager: %w", callErr)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	if cred.CredentialBlobSize == 0 {
+		return "", ErrNotFound
+	}
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+func credSet(account, secret string) error {
+	if secret == "" {
+		return fmt.Errorf("failed to store secret in Credential Manager: empty secret")
+	}
+	target, err := credTarget(account)
+	if err != nil {
+		return err
+	}
+	user, err := syscall.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		CredentialBlob:     &blob[0],
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if ok, _, callErr := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); ok == 0 {
+		return fmt.Errorf("failed to store secret in Credential Manager: %w", callErr)
+	}
+	return nil
+}
+
+func credDelete(account string) error {
+	target, err := credTarget(account)
+	if err != nil {
+		return err
+	}
+	// Fails when there was nothing to delete
+	procCredDeleteW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0)
+	return nil
+}
@@ -0,0 +1,131 @@
+// Package keychain stores secrets in the operating system's keychain: the
+// login keychain on macOS (security), the Secret Service on Linux
+// (secret-tool, from libsecret) and the Credential Manager on Windows.
+package keychain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Service is the keychain service fintrack's secrets are filed under
+const Service = "fintrack"
+
+// ErrNotFound is returned when the keychain has no such secret
+var ErrNotFound = errors.New("secret not found in keychain")
+
+// ErrUnsupported is returned when no keychain tool is available
+var ErrUnsupported = errors.New("no OS keychain available (needs macOS security, Linux secret-tool or Windows Credential Manager)")
+
+// Available reports whether a keychain can be used on this system
+func Available() bool {
+	if runtime.GOOS == "windows" {
+		return credAvailable()
+	}
+	if tool() == "" {
+		return false
+	}
+	_, err := exec.LookPath(tool())
+	return err == nil
+}
+
+// tool is the keychain command line tool for this OS
+func tool() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "security"
+	case "linux", "freebsd", "openbsd":
+		return "secret-tool"
+	default:
+		return ""
+	}
+}
+
+// Get returns the secret stored for account
+func Get(account string) (string, error) {
+	if !Available() {
+		return "", ErrUnsupported
+	}
+	if runtime.GOOS == "windows" {
+		return credGet(account)
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "find-generic-password", "-s", Service, "-a", account, "-w")
+	} else {
+		cmd = exec.Command("secret-tool", "lookup", "service", Service, "account", account)
+	}
+
+	out, err := cmd.Output()
+	secret := strings.TrimRight(string(out), "\n")
+	if err != nil || secret == "" {
+		// Both tools exit non-zero (secret-tool: with no output) for a missing secret
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+// Set stores secret for account, replacing any previous one
+func Set(account, secret string) error {
+	if !Available() {
+		return ErrUnsupported
+	}
+	if runtime.GOOS == "windows" {
+		return credSet(account, secret)
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		// security only takes the secret as an argument; given as a command
+		// on stdin to its interactive mode, it stays out of the process list
+		if strings.ContainsAny(secret, "\r\n") {
+			return fmt.Errorf("failed to store secret in keychain: secret contains a line break")
+		}
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+			securityQuote(Service), securityQuote(account), securityQuote(secret)))
+	} else {
+		cmd = exec.Command("secret-tool", "store", "--label", Service+" "+account, "service", Service, "account", account)
+		cmd.Stdin = strings.NewReader(secret)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to store secret in keychain: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	// security -i reports a failed command on stderr but may still exit 0
+	if runtime.GOOS == "darwin" && stderr.Len() > 0 {
+		return fmt.Errorf("failed to store secret in keychain: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Delete removes the secret for account. A missing secret is not an error.
+func Delete(account string) error {
+	if !Available() {
+		return ErrUnsupported
+	}
+	if runtime.GOOS == "windows" {
+		return credDelete(account)
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "delete-generic-password", "-s", Service, "-a", account)
+	} else {
+		cmd = exec.Command("secret-tool", "clear", "service", Service, "account", account)
+	}
+	_ = cmd.Run() // Fails when there was nothing to delete
+	return nil
+}
+
+// securityQuote quotes an argument for a command line read by security -i
+func securityQuote(arg string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
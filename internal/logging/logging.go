@@ -0,0 +1,97 @@
+// Package logging turns fintrack's console output into JSON log records for
+// headless deployments, where a log collector reads the container's stdout
+package logging
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// JSON captures everything written to stdout and stderr while it is active
+// and re-emits each line as a JSON record on the real stdout
+type JSON struct {
+	logger *slog.Logger
+	stdout *os.File
+	stderr *os.File
+	pipes  []*os.File // Write ends, closed on Stop
+	wg     sync.WaitGroup
+}
+
+// StartJSON redirects stdout and stderr into JSON records tagged with command
+func StartJSON(command string) (*JSON, error) {
+	j := &JSON{stdout: os.Stdout, stderr: os.Stderr}
+	j.logger = slog.New(slog.NewJSONHandler(j.stdout, nil)).With("command", command)
+
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		outR.Close()
+		outW.Close()
+		return nil, err
+	}
+
+	j.pipes = []*os.File{outW, errW}
+	j.wg.Add(2)
+	go j.forward(outR)
+	go j.forward(errR)
+
+	os.Stdout = outW
+	os.Stderr = errW
+	return j, nil
+}
+
+// Logger returns the logger writing to the real stdout
+func (j *JSON) Logger() *slog.Logger {
+	return j.logger
+}
+
+// Stop restores stdout and stderr after flushing every captured line
+func (j *JSON) Stop() {
+	os.Stdout = j.stdout
+	os.Stderr = j.stderr
+	for _, pipe := range j.pipes {
+		pipe.Close()
+	}
+	j.wg.Wait()
+}
+
+// forward logs each line read from r
+func (j *JSON) forward(r io.ReadCloser) {
+	defer j.wg.Done()
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// Progress bars redraw with carriage returns; keep the final state
+		line := scanner.Text()
+		if i := strings.LastIndex(line, "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+		line = strings.TrimSpace(strings.ReplaceAll(line, "\033[K", ""))
+		if line == "" {
+			continue
+		}
+		j.logger.Log(context.Background(), lineLevel(line), line)
+	}
+}
+
+// lineLevel picks a level from the markers fintrack's output uses. Progress
+// also goes to stderr, so the stream alone says nothing about severity.
+func lineLevel(line string) slog.Level {
+	switch {
+	case strings.HasPrefix(line, "❌"), strings.HasPrefix(line, "Error:"):
+		return slog.LevelError
+	case strings.HasPrefix(line, "⚠️"), strings.HasPrefix(line, "Warning:"):
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
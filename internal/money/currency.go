@@ -0,0 +1,240 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Money is an amount in a currency. Arithmetic and comparisons refuse to mix
+// currencies; an empty currency is "unspecified" and combines with any.
+type Money struct {
+	Amount   Amount
+	Currency string // ISO 4217 code, upper case
+}
+
+// ErrCurrencyMismatch is returned when amounts in different currencies are combined
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// New returns an amount in a currency
+func New(amount Amount, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// FromFloatIn converts an API amount in a currency
+func FromFloatIn(f float64, currency string, mode RoundingMode) Money {
+	return New(FromFloat(f, mode), currency)
+}
+
+// symbols are the currency symbols shown by display formatting and accepted by ParseMoney
+var symbols = map[string]string{
+	"INR": "₹",
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"SGD": "S$",
+	"AED": "د.إ",
+}
+
+// Symbol returns a currency's symbol, or false for currencies without one
+func Symbol(currency string) (string, bool) {
+	symbol, ok := symbols[strings.ToUpper(currency)]
+	return symbol, ok
+}
+
+// ParseMoney reads an amount with an optional currency: "1000", "1000INR",
+// "1000 inr", "INR 1000", "₹1000" or "$12.50"
+func ParseMoney(s string, mode RoundingMode) (Money, error) {
+	number := strings.TrimSpace(s)
+	currency := ""
+
+	// Symbols, longest first so S$ wins over $
+	var codes []string
+	for code := range symbols {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return len(symbols[codes[i]]) > len(symbols[codes[j]]) })
+	for _, code := range codes {
+		if rest, ok := strings.CutPrefix(number, symbols[code]); ok {
+			number, currency = rest, code
+			break
+		}
+	}
+
+	if currency == "" {
+		rest := strings.TrimLeftFunc(number, unicode.IsLetter)
+		prefix := number[:len(number)-len(rest)]
+		number = strings.TrimRightFunc(rest, unicode.IsLetter)
+		suffix := rest[len(number):]
+		if prefix != "" && suffix != "" {
+			return Money{}, fmt.Errorf("invalid amount %q", s)
+		}
+		currency = prefix + suffix
+		if currency != "" && !IsCurrencyCode(currency) {
+			return Money{}, fmt.Errorf("invalid amount %q: currency must be a 3-letter code", s)
+		}
+	}
+
+	amount, err := Parse(strings.ReplaceAll(number, ",", ""), mode)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	return New(amount, currency), nil
+}
+
+// IsCurrencyCode reports whether s looks like an ISO 4217 code: three letters
+func IsCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) || r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// SameCurrency reports whether two amounts can be combined
+func (m Money) SameCurrency(other Money) bool {
+	return m.Currency == "" || other.Currency == "" || strings.EqualFold(m.Currency, other.Currency)
+}
+
+// Add sums two amounts in the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if !m.SameCurrency(other) {
+		return Money{}, fmt.Errorf("%w: cannot add %s to %s", ErrCurrencyMismatch, other, m)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.currencyWith(other)}, nil
+}
+
+// Cmp compares two amounts in the same currency: -1, 0 or +1
+func (m Money) Cmp(other Money) (int, error) {
+	if !m.SameCurrency(other) {
+		return 0, fmt.Errorf("%w: cannot compare %s with %s", ErrCurrencyMismatch, m, other)
+	}
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Abs returns the absolute value
+func (m Money) Abs() Money {
+	return Money{Amount: m.Amount.Abs(), Currency: m.Currency}
+}
+
+// Neg returns the amount with its sign flipped
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// String formats the amount as "1234.50 INR"
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Amount.String()
+	}
+	return m.Amount.String() + " " + m.Currency
+}
+
+func (m Money) currencyWith(other Money) string {
+	if m.Currency != "" {
+		return m.Currency
+	}
+	return other.Currency
+}
+
+// Range is an inclusive range of absolute amounts, as used by the
+// --min-amount and --max-amount filters. A zero bound is no bound. A bound
+// with a currency only matches amounts in that currency.
+type Range struct {
+	Min Money
+	Max Money
+}
+
+// ParseRange parses the bounds of an amount filter; empty strings are no bound
+func ParseRange(min, max string, mode RoundingMode) (Range, error) {
+	var r Range
+	var err error
+	if min != "" {
+		if r.Min, err = ParseMoney(min, mode); err != nil {
+			return Range{}, err
+		}
+	}
+	if max != "" {
+		if r.Max, err = ParseMoney(max, mode); err != nil {
+			return Range{}, err
+		}
+	}
+	if r.Min.Amount < 0 || r.Max.Amount < 0 {
+		return Range{}, fmt.Errorf("amount bounds cannot be negative")
+	}
+	if !r.Min.SameCurrency(r.Max) {
+		return Range{}, fmt.Errorf("%w: bounds %s and %s", ErrCurrencyMismatch, r.Min, r.Max)
+	}
+	if !r.Max.IsZero() && r.Min.Amount > r.Max.Amount {
+		return Range{}, fmt.Errorf("minimum %s is above maximum %s", r.Min, r.Max)
+	}
+	return r, nil
+}
+
+// IsEmpty reports whether the range has no bounds
+func (r Range) IsEmpty() bool {
+	return r.Min.IsZero() && r.Max.IsZero()
+}
+
+// Contains reports whether the absolute value of m lies within the range
+func (r Range) Contains(m Money) bool {
+	if !r.Min.SameCurrency(m) || !r.Max.SameCurrency(m) {
+		return false
+	}
+	amount := m.Amount.Abs()
+	return (r.Min.IsZero() || amount >= r.Min.Amount) && (r.Max.IsZero() || amount <= r.Max.Amount)
+}
+
+// String describes the range, e.g. "at least 1000.00 INR"
+func (r Range) String() string {
+	switch {
+	case r.Max.IsZero():
+		return "at least " + r.Min.String()
+	case r.Min.IsZero():
+		return "at most " + r.Max.String()
+	}
+	return r.Min.String() + " to " + r.Max.String()
+}
+
+// Totals sums amounts per currency, so different currencies are never added
+// together
+type Totals map[string]Amount
+
+// Add accumulates an amount into its currency's total
+func (t Totals) Add(m Money) {
+	t[m.Currency] += m.Amount
+}
+
+// Currencies returns the currencies with a total, sorted
+func (t Totals) Currencies() []string {
+	currencies := make([]string, 0, len(t))
+	for currency := range t {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// Get returns a currency's total
+func (t Totals) Get(currency string) Money {
+	currency = strings.ToUpper(currency)
+	return Money{Amount: t[currency], Currency: currency}
+}
@@ -0,0 +1,127 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Amount is a monetary value in minor units (paisa for INR). Summing integers
+// avoids the drift float64 accumulates over thousands of transactions.
+type Amount int64
+
+// RoundingMode controls how values with more than two decimals are rounded
+type RoundingMode string
+
+const (
+	// HalfEven rounds to the nearest paisa, ties to even (banker's rounding)
+	HalfEven RoundingMode = "half_even"
+	// HalfUp rounds to the nearest paisa, ties away from zero
+	HalfUp RoundingMode = "half_up"
+	// Down truncates towards zero
+	Down RoundingMode = "down"
+	// Up rounds away from zero
+	Up RoundingMode = "up"
+)
+
+// DefaultRounding is used when no rounding mode is configured
+const DefaultRounding = HalfEven
+
+// ParseRounding validates a configured rounding mode
+func ParseRounding(s string) (RoundingMode, error) {
+	if s == "" {
+		return DefaultRounding, nil
+	}
+	switch mode := RoundingMode(strings.ToLower(s)); mode {
+	case HalfEven, HalfUp, Down, Up:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid rounding mode %q (use half_even, half_up, down, up)", s)
+}
+
+// FromFloat converts an API amount to minor units. The float is read via its
+// shortest decimal representation so 0.1 means exactly ten paise.
+func FromFloat(f float64, mode RoundingMode) Amount {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	if !ok {
+		return 0
+	}
+	return round(r.Mul(r, big.NewRat(100, 1)), mode)
+}
+
+// Parse converts a decimal string such as "1234.565" to minor units
+func Parse(s string, mode RoundingMode) (Amount, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return round(r.Mul(r, big.NewRat(100, 1)), mode), nil
+}
+
+// Mul multiplies by a factor such as an exchange rate and rounds the result
+func (a Amount) Mul(factor float64, mode RoundingMode) Amount {
+	f, ok := new(big.Rat).SetString(strconv.FormatFloat(factor, 'f', -1, 64))
+	if !ok {
+		return 0
+	}
+	return round(f.Mul(f, big.NewRat(int64(a), 1)), mode)
+}
+
+// Float64 returns the amount in major units for JSON output and display
+func (a Amount) Float64() float64 {
+	f, _ := strconv.ParseFloat(a.String(), 64)
+	return f
+}
+
+// Abs returns the absolute value
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// String formats the amount with exactly two decimals
+func (a Amount) String() string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+// round rounds r to an integer using mode
+func round(r *big.Rat, mode RoundingMode) Amount {
+	num := new(big.Int).Set(r.Num())
+	den := r.Denom()
+
+	neg := num.Sign() < 0
+	num.Abs(num)
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		// Compare twice the remainder with the denominator to find the tie point
+		cmp := new(big.Int).Lsh(rem, 1).Cmp(den)
+		switch mode {
+		case Up:
+			quo.Add(quo, big.NewInt(1))
+		case Down:
+		case HalfUp:
+			if cmp >= 0 {
+				quo.Add(quo, big.NewInt(1))
+			}
+		default: // HalfEven
+			if cmp > 0 || (cmp == 0 && quo.Bit(0) == 1) {
+				quo.Add(quo, big.NewInt(1))
+			}
+		}
+	}
+
+	if neg {
+		quo.Neg(quo)
+	}
+	return Amount(quo.Int64())
+}
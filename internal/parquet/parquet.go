@@ -0,0 +1,359 @@
+// Package parquet writes Apache Parquet files: one row group, one
+// gzip-compressed PLAIN data page per column, and flat schemas of required
+// or optional primitive columns. That is all a transaction table needs, and
+// every Parquet reader (DuckDB, Pandas/PyArrow, Spark) can read it.
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Type is a column's logical type
+type Type int
+
+const (
+	Boolean   Type = iota // bool
+	Int32                 // int32
+	Int64                 // int64
+	Double                // float64
+	String                // string, UTF-8
+	Date                  // time.Time, days since the epoch
+	Timestamp             // time.Time, milliseconds since the epoch (UTC)
+)
+
+// Column describes one column of the schema
+type Column struct {
+	Name     string
+	Type     Type
+	Optional bool // Values may be nil
+}
+
+// Parquet physical types, converted types and enums
+const (
+	physBoolean   = 0
+	physInt32     = 1
+	physInt64     = 2
+	physDouble    = 5
+	physByteArray = 6
+
+	convertedUTF8            = 0
+	convertedDate            = 6
+	convertedTimestampMillis = 9
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecGzip    = 2
+	pageTypeData = 0
+)
+
+const magic = "PAR1"
+
+// physical returns the physical type and converted type (-1 for none)
+func (t Type) physical() (int32, int32) {
+	switch t {
+	case Boolean:
+		return physBoolean, -1
+	case Int32:
+		return physInt32, -1
+	case Int64:
+		return physInt64, -1
+	case Double:
+		return physDouble, -1
+	case String:
+		return physByteArray, convertedUTF8
+	case Date:
+		return physInt32, convertedDate
+	default:
+		return physInt64, convertedTimestampMillis
+	}
+}
+
+// columnChunk is an encoded column and where it was written
+type columnChunk struct {
+	offset       int64
+	uncompressed int64
+	compressed   int64
+}
+
+// Write writes rows as a Parquet file. Each row holds one value per column,
+// of the Go type listed for the column's Type, or nil in optional columns.
+func Write(w io.Writer, columns []Column, rows [][]any, createdBy string) error {
+	out := &countingWriter{w: w}
+	if _, err := io.WriteString(out, magic); err != nil {
+		return err
+	}
+
+	chunks := make([]columnChunk, len(columns))
+	for i, column := range columns {
+		page, err := encodeColumn(column, i, rows)
+		if err != nil {
+			return err
+		}
+		compressed, err := gzipBytes(page)
+		if err != nil {
+			return err
+		}
+
+		header := pageHeader(len(rows), len(page), len(compressed))
+		chunks[i] = columnChunk{
+			offset:       out.n,
+			uncompressed: int64(len(header) + len(page)),
+			compressed:   int64(len(header) + len(compressed)),
+		}
+		if _, err := out.Write(header); err != nil {
+			return err
+		}
+		if _, err := out.Write(compressed); err != nil {
+			return err
+		}
+	}
+
+	footer := fileMetadata(columns, chunks, len(rows), createdBy)
+	if _, err := out.Write(footer); err != nil {
+		return err
+	}
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	if _, err := out.Write(length[:]); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, magic)
+	return err
+}
+
+// encodeColumn encodes a column's data page: definition levels for optional
+// columns, then the non-null values
+func encodeColumn(column Column, index int, rows [][]any) ([]byte, error) {
+	var page, values bytes.Buffer
+	defined := make([]bool, len(rows))
+	var bools []bool
+
+	for r, row := range rows {
+		if index >= len(row) {
+			return nil, fmt.Errorf("row %d has no value for column %s", r, column.Name)
+		}
+		value := row[index]
+		if value == nil {
+			if !column.Optional {
+				return nil, fmt.Errorf("row %d: column %s is required", r, column.Name)
+			}
+			continue
+		}
+		defined[r] = true
+
+		var err error
+		switch column.Type {
+		case Boolean:
+			v, ok := value.(bool)
+			if !ok {
+				err = typeError(column, value)
+			}
+			bools = append(bools, v)
+		case Int32:
+			v, ok := value.(int32)
+			if !ok {
+				err = typeError(column, value)
+			}
+			binary.Write(&values, binary.LittleEndian, v)
+		case Int64:
+			v, ok := value.(int64)
+			if !ok {
+				err = typeError(column, value)
+			}
+			binary.Write(&values, binary.LittleEndian, v)
+		case Double:
+			v, ok := value.(float64)
+			if !ok {
+				err = typeError(column, value)
+			}
+			binary.Write(&values, binary.LittleEndian, math.Float64bits(v))
+		case String:
+			v, ok := value.(string)
+			if !ok {
+				err = typeError(column, value)
+			}
+			binary.Write(&values, binary.LittleEndian, uint32(len(v)))
+			values.WriteString(v)
+		case Date:
+			v, ok := value.(time.Time)
+			if !ok {
+				err = typeError(column, value)
+			}
+			// Days since the epoch of the calendar date, whatever the zone
+			days := time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+			binary.Write(&values, binary.LittleEndian, int32(days))
+		case Timestamp:
+			v, ok := value.(time.Time)
+			if !ok {
+				err = typeError(column, value)
+			}
+			binary.Write(&values, binary.LittleEndian, v.UnixMilli())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", r, err)
+		}
+	}
+
+	if column.Type == Boolean {
+		values.Write(packBits(bools))
+	}
+	if column.Optional {
+		levels := definitionLevels(defined)
+		binary.Write(&page, binary.LittleEndian, uint32(len(levels)))
+		page.Write(levels)
+	}
+	page.Write(values.Bytes())
+	return page.Bytes(), nil
+}
+
+func typeError(column Column, value any) error {
+	return fmt.Errorf("column %s: unexpected value of type %T", column.Name, value)
+}
+
+// packBits bit-packs booleans, least significant bit first
+func packBits(bools []bool) []byte {
+	packed := make([]byte, (len(bools)+7)/8)
+	for i, b := range bools {
+		if b {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
+
+// definitionLevels encodes 0/1 levels in the RLE hybrid encoding as runs of
+// equal values (bit width 1, so each run is a varint header and one byte)
+func definitionLevels(defined []bool) []byte {
+	var buf bytes.Buffer
+	var b [binary.MaxVarintLen64]byte
+	for i := 0; i < len(defined); {
+		j := i
+		for j < len(defined) && defined[j] == defined[i] {
+			j++
+		}
+		buf.Write(b[:binary.PutUvarint(b[:], uint64(j-i)<<1)])
+		if defined[i] {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		i = j
+	}
+	return buf.Bytes()
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pageHeader encodes the PageHeader of a data page
+func pageHeader(numValues, uncompressed, compressed int) []byte {
+	t := newThriftWriter()
+	t.i32(1, pageTypeData)
+	t.i32(2, int32(uncompressed))
+	t.i32(3, int32(compressed))
+	t.beginStruct(5) // DataPageHeader
+	t.i32(1, int32(numValues))
+	t.i32(2, encodingPlain)
+	t.i32(3, encodingRLE)
+	t.i32(4, encodingRLE)
+	t.endStruct()
+	t.endStruct()
+	return t.bytes()
+}
+
+// fileMetadata encodes the footer's FileMetaData
+func fileMetadata(columns []Column, chunks []columnChunk, numRows int, createdBy string) []byte {
+	t := newThriftWriter()
+	t.i32(1, 1) // Format version
+
+	t.list(2, thriftStruct, len(columns)+1)
+	t.beginStruct(0) // Root
+	t.binary(4, "schema")
+	t.i32(5, int32(len(columns)))
+	t.endStruct()
+	for _, column := range columns {
+		physical, converted := column.Type.physical()
+		repetition := int32(repetitionRequired)
+		if column.Optional {
+			repetition = repetitionOptional
+		}
+		t.beginStruct(0)
+		t.i32(1, physical)
+		t.i32(3, repetition)
+		t.binary(4, column.Name)
+		if converted >= 0 {
+			t.i32(6, converted)
+		}
+		t.endStruct()
+	}
+
+	t.i64(3, int64(numRows))
+
+	var totalSize int64
+	for _, chunk := range chunks {
+		totalSize += chunk.uncompressed
+	}
+	t.list(4, thriftStruct, 1)
+	t.beginStruct(0) // RowGroup
+	t.list(1, thriftStruct, len(columns))
+	for i, column := range columns {
+		physical, _ := column.Type.physical()
+		chunk := chunks[i]
+		t.beginStruct(0) // ColumnChunk
+		t.i64(2, chunk.offset)
+		t.beginStruct(3) // ColumnMetaData
+		t.i32(1, physical)
+		t.list(2, thriftI32, 2)
+		t.varint(zigzag(encodingPlain))
+		t.varint(zigzag(encodingRLE))
+		t.list(3, thriftBinary, 1)
+		t.rawBinary(column.Name)
+		t.i32(4, codecGzip)
+		t.i64(5, int64(numRows))
+		t.i64(6, chunk.uncompressed)
+		t.i64(7, chunk.compressed)
+		t.i64(9, chunk.offset)
+		t.endStruct()
+		t.endStruct()
+	}
+	t.i64(2, totalSize)
+	t.i64(3, int64(numRows))
+	t.endStruct()
+
+	if createdBy != "" {
+		t.binary(6, createdBy)
+	}
+	t.endStruct()
+	return t.bytes()
+}
+
+// countingWriter tracks the file offset of each column chunk
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
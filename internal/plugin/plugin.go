@@ -0,0 +1,186 @@
+// Package plugin runs external programs that extend fintrack: exporters,
+// alert channels and enrichers (such as categorizers), configured under
+// plugins. Each call runs the plugin's command once, writes a Request as JSON
+// to its stdin and reads a Response from its stdout. Anything the plugin
+// writes to stderr is passed through, and a non-zero exit or an error in the
+// response fails the call.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+)
+
+// ProtocolVersion is sent in every request, so plugins can reject requests
+// they don't understand
+const ProtocolVersion = 1
+
+// DefaultTimeout bounds a request when the plugin doesn't set timeout
+const DefaultTimeout = 60 * time.Second
+
+// Request kinds
+const (
+	KindPing   = "ping"   // Check the plugin runs; no payload
+	KindExport = "export" // Transactions for an export target
+	KindEnrich = "enrich" // New transactions, answered with the changed ones
+	KindNotify = "notify" // An alert for an alert channel
+)
+
+// Request is what a plugin reads from stdin
+type Request struct {
+	Version      int                 `json:"version"`
+	Kind         string              `json:"kind"`
+	Plugin       string              `json:"plugin"`
+	Target       string              `json:"target,omitempty"` // Export target or alert channel name
+	Options      map[string]string   `json:"options,omitempty"`
+	Transactions []blend.Transaction `json:"transactions,omitempty"` // export, enrich
+	Fingerprints []string            `json:"fingerprints,omitempty"` // Of each transaction, in order
+	Alert        interface{}         `json:"alert,omitempty"`        // notify
+}
+
+// Response is what a plugin writes to stdout. Empty output is an empty
+// response.
+type Response struct {
+	Error        string              `json:"error,omitempty"`        // Fails the request
+	Transactions []blend.Transaction `json:"transactions,omitempty"` // enrich: the transactions it changed
+	Name         string              `json:"name,omitempty"`         // ping: what the plugin calls itself
+	Kinds        []string            `json:"kinds,omitempty"`        // ping: the request kinds it handles
+}
+
+// Stderr is where plugins' stderr goes; tests can replace it
+var Stderr io.Writer = os.Stderr
+
+// configured are the plugins Call can run, keyed by name
+var configured map[string]config.PluginConfig
+
+// Configure sets the plugins Call can run, from the plugins section of the
+// configuration
+func Configure(plugins map[string]config.PluginConfig) {
+	configured = plugins
+}
+
+// Names returns the configured plugin names in sorted order
+func Names() []string {
+	names := make([]string, 0, len(configured))
+	for name := range configured {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns a configured plugin's settings
+func Lookup(name string) (config.PluginConfig, error) {
+	plugin, ok := configured[strings.ToLower(name)]
+	if !ok {
+		return config.PluginConfig{}, fmt.Errorf("unknown plugin %q (configured: %s)", name, strings.Join(Names(), ", "))
+	}
+	return plugin, nil
+}
+
+// Call sends one request to the named plugin and returns its response
+func Call(ctx context.Context, name string, req Request) (*Response, error) {
+	name = strings.ToLower(name)
+	plugin, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := plugin.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req.Version = ProtocolVersion
+	req.Plugin = name
+	req.Options = plugin.Options
+	if len(req.Transactions) > 0 {
+		req.Fingerprints = blend.Fingerprints(req.Transactions)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: failed to marshal request: %w", name, err)
+	}
+
+	cmd := ShellCommand(ctx, plugin.Command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(Environ(), "FINTRACK_PLUGIN="+name, "FINTRACK_PLUGIN_KIND="+req.Kind)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = Stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("plugin %s: no response within %s", name, timeout)
+		}
+		return nil, fmt.Errorf("plugin %s: command failed: %w", name, err)
+	}
+
+	resp := &Response{}
+	if out := bytes.TrimSpace(stdout.Bytes()); len(out) > 0 {
+		if err := json.Unmarshal(out, resp); err != nil {
+			return nil, fmt.Errorf("plugin %s: invalid JSON response: %w", name, err)
+		}
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", name, resp.Error)
+	}
+	return resp, nil
+}
+
+// Enrich passes transactions through every plugin with enrich set, in name
+// order. Plugins answer with the transactions they changed, matched by UUID;
+// the rest are kept as they were, so a plugin can't drop transactions.
+func Enrich(ctx context.Context, transactions []blend.Transaction) ([]blend.Transaction, error) {
+	for _, name := range Names() {
+		if !configured[name].Enrich || len(transactions) == 0 {
+			continue
+		}
+
+		resp, err := Call(ctx, name, Request{Kind: KindEnrich, Transactions: transactions})
+		if err != nil {
+			return nil, err
+		}
+
+		index := make(map[string]int, len(transactions))
+		for i, txn := range transactions {
+			index[txn.UUID] = i
+		}
+		enriched := append([]blend.Transaction(nil), transactions...)
+		for _, txn := range resp.Transactions {
+			i, ok := index[txn.UUID]
+			if !ok {
+				return nil, fmt.Errorf("plugin %s: returned transaction %q it wasn't sent", name, txn.UUID)
+			}
+			enriched[i] = txn
+		}
+		transactions = enriched
+	}
+	return transactions, nil
+}
+
+// Environ is fintrack's environment without its own FINTRACK_* variables,
+// which carry refresh tokens, passwords and passphrases that configured
+// commands (plugins, alert channels, importers) have no business seeing.
+// Plugins get what they need in the request.
+func Environ() []string {
+	prefix := config.EnvPrefix + "_"
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(strings.ToUpper(kv), prefix) {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
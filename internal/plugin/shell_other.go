@@ -0,0 +1,14 @@
+//go:build !windows
+
+package plugin
+
+import (
+	"context"
+	"os/exec"
+)
+
+// ShellCommand runs command with sh, so configured commands can use
+// arguments, pipes and quoting
+func ShellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
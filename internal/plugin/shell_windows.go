@@ -0,0 +1,23 @@
+//go:build windows
+
+package plugin
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// ShellCommand runs command with cmd.exe. The command line is passed as
+// written: Go's argument quoting would escape the quotes cmd /C expects to
+// see unchanged.
+func ShellCommand(ctx context.Context, command string) *exec.Cmd {
+	shell := os.Getenv("COMSPEC")
+	if shell == "" {
+		shell = "cmd.exe"
+	}
+	cmd := exec.CommandContext(ctx, shell)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: `"` + shell + `" /S /C "` + command + `"`}
+	return cmd
+}
@@ -0,0 +1,127 @@
+// Package profile breaks a command's run time down into phases (config load,
+// authentication, network, parsing, writes) for --profile-run, so a slow run
+// can be told apart as network-bound or local-processing-bound.
+package profile
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Phase is a part of a run that is timed separately
+type Phase string
+
+const (
+	Config Phase = "config load"
+	Auth   Phase = "auth"
+	API    Phase = "API (network)"
+	Parse  Phase = "parse"
+	Write  Phase = "write"
+)
+
+// phases is the order phases are reported in
+var phases = []Phase{Config, Auth, API, Parse, Write}
+
+var (
+	mu      sync.Mutex
+	enabled bool
+	totals  = make(map[Phase]time.Duration)
+	counts  = make(map[Phase]int)
+)
+
+// Enable starts recording; until then Start and Add do nothing
+func Enable() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = true
+}
+
+// Enabled reports whether the run is being profiled
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enabled
+}
+
+// Start times a phase until the returned function is called:
+//
+//	defer profile.Start(profile.Write)()
+func Start(phase Phase) func() {
+	if !Enabled() {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { Add(phase, time.Since(start)) }
+}
+
+// Add records time spent in a phase. Phases may overlap in parallel work, in
+// which case their totals can exceed the wall time.
+func Add(phase Phase, d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		return
+	}
+	totals[phase] += d
+	counts[phase]++
+}
+
+// Print writes the breakdown of a run that took total. Time not spent in any
+// phase is reported as other local processing.
+func Print(w io.Writer, total time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var measured time.Duration
+	for _, phase := range phases {
+		measured += totals[phase]
+	}
+	network := totals[Auth] + totals[API]
+	other := total - measured
+	if other < 0 {
+		other = 0
+	}
+
+	fmt.Fprintf(w, "\n⏱️  Run profile (%s total)\n", round(total))
+	for _, phase := range phases {
+		line := fmt.Sprintf("  %-14s %9s %6.1f%%", phase, round(totals[phase]), percent(totals[phase], total))
+		if n := counts[phase]; n > 0 {
+			line += fmt.Sprintf("  %d call(s)", n)
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "  %-14s %9s %6.1f%%\n", "other", round(other), percent(other, total))
+
+	if measured > total {
+		fmt.Fprintln(w, "  (parallel requests overlap, so phases add up to more than the total)")
+	}
+	switch local := total - network; {
+	case network == 0:
+		fmt.Fprintln(w, "  → No network time: the run was local processing")
+	case network >= local:
+		fmt.Fprintln(w, "  → Network-bound: most time went to Bend API requests")
+	default:
+		fmt.Fprintln(w, "  → Local-processing-bound: most time was spent outside API requests")
+	}
+}
+
+func percent(d, total time.Duration) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(d) * 100 / float64(total)
+}
+
+// round shortens a duration for display
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(100 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
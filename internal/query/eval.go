@@ -0,0 +1,488 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Value is a cell value: nil, float64, string or bool
+type Value = any
+
+// expr is a parsed expression
+type expr interface {
+	eval(ctx *evalContext) (Value, error)
+}
+
+// evalContext is what an expression is evaluated against: a single row, or
+// in grouped queries a group of rows (bare columns then read the first row)
+type evalContext struct {
+	row   []Value
+	group [][]Value
+}
+
+type literal struct {
+	value Value
+}
+
+type columnRef struct {
+	name  string
+	index int // Resolved against the table before evaluation
+}
+
+type unaryExpr struct {
+	op      string
+	operand expr
+}
+
+type binaryExpr struct {
+	op          string
+	left, right expr
+}
+
+type isNullExpr struct {
+	operand expr
+	not     bool
+}
+
+type likeExpr struct {
+	operand, pattern expr
+	not              bool
+	cache            map[string]*regexp.Regexp
+}
+
+type inExpr struct {
+	operand expr
+	list    []expr
+	not     bool
+}
+
+type betweenExpr struct {
+	operand, low, high expr
+	not                bool
+}
+
+type callExpr struct {
+	name     string
+	args     []expr
+	star     bool // COUNT(*)
+	distinct bool // COUNT(DISTINCT x)
+}
+
+// aggregates are the functions that reduce a group to one value
+var aggregates = map[string]bool{"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true}
+
+func (e *literal) eval(ctx *evalContext) (Value, error) {
+	return e.value, nil
+}
+
+func (e *columnRef) eval(ctx *evalContext) (Value, error) {
+	if ctx.row == nil {
+		return nil, nil // Empty group
+	}
+	return ctx.row[e.index], nil
+}
+
+func (e *unaryExpr) eval(ctx *evalContext) (Value, error) {
+	v, err := e.operand.eval(ctx)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	if e.op == "NOT" {
+		return !truthy(v), nil
+	}
+	n, ok := toNumber(v)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %q", toString(v))
+	}
+	return -n, nil
+}
+
+func (e *binaryExpr) eval(ctx *evalContext) (Value, error) {
+	left, err := e.left.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// AND and OR short-circuit, and treat NULL as false
+	switch e.op {
+	case "AND":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := e.right.eval(ctx)
+		return truthy(right), err
+	case "OR":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := e.right.eval(ctx)
+		return truthy(right), err
+	}
+
+	right, err := e.right.eval(ctx)
+	if err != nil || left == nil || right == nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "||":
+		return toString(left) + toString(right), nil
+	case "=", "!=", "<", "<=", ">", ">=":
+		c := compare(left, right)
+		switch e.op {
+		case "=":
+			return c == 0, nil
+		case "!=":
+			return c != 0, nil
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	}
+
+	a, okA := toNumber(left)
+	b, okB := toNumber(right)
+	if !okA || !okB {
+		return nil, fmt.Errorf("%s needs numbers, got %q and %q", e.op, toString(left), toString(right))
+	}
+	switch e.op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, nil
+		}
+		return a / b, nil
+	default:
+		if b == 0 {
+			return nil, nil
+		}
+		return math.Mod(a, b), nil
+	}
+}
+
+func (e *isNullExpr) eval(ctx *evalContext) (Value, error) {
+	v, err := e.operand.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return (v == nil) != e.not, nil
+}
+
+func (e *likeExpr) eval(ctx *evalContext) (Value, error) {
+	v, err := e.operand.eval(ctx)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	p, err := e.pattern.eval(ctx)
+	if err != nil || p == nil {
+		return nil, err
+	}
+
+	pattern := toString(p)
+	re, ok := e.cache[pattern]
+	if !ok {
+		re = likePattern(pattern)
+		if e.cache == nil {
+			e.cache = make(map[string]*regexp.Regexp)
+		}
+		e.cache[pattern] = re
+	}
+	return re.MatchString(toString(v)) != e.not, nil
+}
+
+// likePattern converts a LIKE pattern (% any run, _ one character) to a
+// case-insensitive regexp
+func likePattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+func (e *inExpr) eval(ctx *evalContext) (Value, error) {
+	v, err := e.operand.eval(ctx)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	for _, item := range e.list {
+		candidate, err := item.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if candidate != nil && compare(v, candidate) == 0 {
+			return !e.not, nil
+		}
+	}
+	return e.not, nil
+}
+
+func (e *betweenExpr) eval(ctx *evalContext) (Value, error) {
+	v, err := e.operand.eval(ctx)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	low, err := e.low.eval(ctx)
+	if err != nil || low == nil {
+		return nil, err
+	}
+	high, err := e.high.eval(ctx)
+	if err != nil || high == nil {
+		return nil, err
+	}
+	in := compare(v, low) >= 0 && compare(v, high) <= 0
+	return in != e.not, nil
+}
+
+func (e *callExpr) eval(ctx *evalContext) (Value, error) {
+	if aggregates[e.name] {
+		return e.aggregate(ctx)
+	}
+
+	args := make([]Value, len(e.args))
+	for i, arg := range e.args {
+		v, err := arg.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+
+	switch e.name {
+	case "COALESCE", "IFNULL":
+		for _, v := range args {
+			if v != nil {
+				return v, nil
+			}
+		}
+		return nil, nil
+	}
+
+	if err := e.checkArgs(args); err != nil {
+		return nil, err
+	}
+	if args[0] == nil {
+		return nil, nil
+	}
+
+	switch e.name {
+	case "LOWER":
+		return strings.ToLower(toString(args[0])), nil
+	case "UPPER":
+		return strings.ToUpper(toString(args[0])), nil
+	case "LENGTH":
+		return float64(len([]rune(toString(args[0])))), nil
+	case "TRIM":
+		return strings.TrimSpace(toString(args[0])), nil
+	case "ABS", "ROUND":
+		n, ok := toNumber(args[0])
+		if !ok {
+			return nil, fmt.Errorf("%s needs a number, got %q", e.name, toString(args[0]))
+		}
+		if e.name == "ABS" {
+			return math.Abs(n), nil
+		}
+		places := 0.0
+		if len(args) > 1 {
+			places, _ = toNumber(args[1])
+		}
+		scale := math.Pow(10, math.Trunc(places))
+		return math.Round(n*scale) / scale, nil
+	case "SUBSTR":
+		// SUBSTR(text, start[, length]) with a 1-based start
+		runes := []rune(toString(args[0]))
+		start, _ := toNumber(args[1])
+		from := int(start) - 1
+		if from < 0 {
+			from = 0
+		}
+		if from > len(runes) {
+			from = len(runes)
+		}
+		to := len(runes)
+		if len(args) > 2 {
+			length, _ := toNumber(args[2])
+			if end := from + int(length); end < to {
+				to = max(end, from)
+			}
+		}
+		return string(runes[from:to]), nil
+	}
+	return nil, fmt.Errorf("unknown function %s", e.name)
+}
+
+// checkArgs checks a scalar function's argument count
+func (e *callExpr) checkArgs(args []Value) error {
+	least, most := 1, 1
+	switch e.name {
+	case "ROUND":
+		most = 2
+	case "SUBSTR":
+		least, most = 2, 3
+	case "LOWER", "UPPER", "LENGTH", "TRIM", "ABS":
+	default:
+		return fmt.Errorf("unknown function %s", e.name)
+	}
+	if e.star || len(args) < least || len(args) > most {
+		return fmt.Errorf("wrong number of arguments to %s", e.name)
+	}
+	return nil
+}
+
+// aggregate reduces the context's group; NULLs are skipped, and SUM, AVG,
+// MIN and MAX of no values are NULL
+func (e *callExpr) aggregate(ctx *evalContext) (Value, error) {
+	if ctx.group == nil {
+		return nil, fmt.Errorf("%s is not allowed here", e.name)
+	}
+	if e.star {
+		if e.name != "COUNT" {
+			return nil, fmt.Errorf("%s(*) is not supported", e.name)
+		}
+		return float64(len(ctx.group)), nil
+	}
+	if len(e.args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments to %s", e.name)
+	}
+
+	seen := make(map[string]bool)
+	var values []Value
+	for _, row := range ctx.group {
+		v, err := e.args[0].eval(&evalContext{row: row})
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		if e.distinct {
+			key := toString(v)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		values = append(values, v)
+	}
+
+	switch e.name {
+	case "COUNT":
+		return float64(len(values)), nil
+	case "MIN", "MAX":
+		var best Value
+		for _, v := range values {
+			if best == nil || (e.name == "MIN" && compare(v, best) < 0) || (e.name == "MAX" && compare(v, best) > 0) {
+				best = v
+			}
+		}
+		return best, nil
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+	var sum float64
+	for _, v := range values {
+		n, ok := toNumber(v)
+		if !ok {
+			return nil, fmt.Errorf("%s needs numbers, got %q", e.name, toString(v))
+		}
+		sum += n
+	}
+	if e.name == "AVG" {
+		return sum / float64(len(values)), nil
+	}
+	return sum, nil
+}
+
+// truthy is a value's truth in WHERE and HAVING; NULL is false
+func truthy(v Value) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return err == nil && n != 0
+	}
+	return false
+}
+
+// toNumber converts a value to a number, parsing numeric strings
+func toNumber(v Value) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// toString formats a value as text
+func toString(v Value) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// compare orders two non-NULL values: numerically when both are numbers (or
+// a number and a numeric string), otherwise as text
+func compare(a, b Value) int {
+	_, aText := a.(string)
+	_, bText := b.(string)
+	if !aText || !bText {
+		x, okX := toNumber(a)
+		y, okY := toNumber(b)
+		if okX && okY {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(toString(a), toString(b))
+}
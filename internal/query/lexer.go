@@ -0,0 +1,118 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind classifies a token
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokKeyword
+	tokNumber
+	tokString
+	tokSymbol
+)
+
+// token is one lexical token; keywords are upper-cased
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// keywords are the reserved words of the dialect
+var keywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "BY": true,
+	"HAVING": true, "ORDER": true, "LIMIT": true, "ASC": true, "DESC": true,
+	"AS": true, "AND": true, "OR": true, "NOT": true, "LIKE": true, "IN": true,
+	"IS": true, "NULL": true, "TRUE": true, "FALSE": true, "BETWEEN": true,
+	"DISTINCT": true,
+}
+
+// lex splits a statement into tokens
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' { // Comment
+				i++
+			}
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			word := string(runes[start:i])
+			if upper := strings.ToUpper(word); keywords[upper] {
+				tokens = append(tokens, token{kind: tokKeyword, text: upper, pos: start})
+			} else {
+				tokens = append(tokens, token{kind: tokIdent, text: strings.ToLower(word), pos: start})
+			}
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: string(runes[start:i]), pos: start})
+
+		case r == '\'' || r == '"':
+			// 'text' is a string; "name" a quoted identifier
+			quote, start := r, i
+			var text strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated %c at position %d", quote, start+1)
+				}
+				if runes[i] == quote {
+					if i+1 < len(runes) && runes[i+1] == quote { // Doubled quote escapes itself
+						text.WriteRune(quote)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				text.WriteRune(runes[i])
+				i++
+			}
+			kind := tokString
+			if quote == '"' {
+				kind = tokIdent
+			}
+			tokens = append(tokens, token{kind: kind, text: text.String(), pos: start})
+
+		default:
+			start := i
+			two := ""
+			if i+1 < len(runes) {
+				two = string(runes[i : i+2])
+			}
+			switch two {
+			case "!=", "<>", "<=", ">=", "||":
+				tokens = append(tokens, token{kind: tokSymbol, text: two, pos: start})
+				i += 2
+				continue
+			}
+			if !strings.ContainsRune("(),*+-/%=<>;", r) {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, start+1)
+			}
+			tokens = append(tokens, token{kind: tokSymbol, text: string(r), pos: start})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
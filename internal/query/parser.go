@@ -0,0 +1,446 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// statement is a parsed SELECT
+type statement struct {
+	distinct bool
+	star     bool // SELECT *
+	columns  []selectColumn
+	table    string
+	where    expr
+	groupBy  []expr
+	having   expr
+	orderBy  []orderTerm
+	limit    int // -1 for none
+}
+
+type selectColumn struct {
+	expr expr
+	name string // Alias, or the expression's text
+}
+
+type orderTerm struct {
+	expr expr
+	desc bool
+}
+
+// parser is a recursive descent parser over the token stream
+type parser struct {
+	tokens []token
+	pos    int
+	src    string
+}
+
+// parse parses one SELECT statement
+func parse(src string) (*statement, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, src: src}
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	p.accept(tokSymbol, ";")
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return stmt, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the token if it matches
+func (p *parser) accept(kind tokenKind, text string) bool {
+	if t := p.peek(); t.kind == kind && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if !p.accept(kind, text) {
+		t := p.peek()
+		if t.kind == tokEOF {
+			return p.errorf(t, "expected %s at end of query", text)
+		}
+		return p.errorf(t, "expected %s, found %q", text, t.text)
+	}
+	return nil
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("syntax error at position %d: %s", t.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parseSelect() (*statement, error) {
+	if err := p.expect(tokKeyword, "SELECT"); err != nil {
+		return nil, err
+	}
+	stmt := &statement{limit: -1}
+	stmt.distinct = p.accept(tokKeyword, "DISTINCT")
+
+	if p.accept(tokSymbol, "*") {
+		stmt.star = true
+	} else {
+		for {
+			start := p.peek().pos
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			name := strings.TrimSpace(p.src[start:p.peek().pos])
+			if p.accept(tokKeyword, "AS") {
+				t := p.next()
+				if t.kind != tokIdent && t.kind != tokString {
+					return nil, p.errorf(t, "expected a column alias after AS")
+				}
+				name = t.text
+			} else if t := p.peek(); t.kind == tokIdent {
+				name = p.next().text
+			}
+			stmt.columns = append(stmt.columns, selectColumn{expr: e, name: name})
+			if !p.accept(tokSymbol, ",") {
+				break
+			}
+		}
+	}
+
+	if err := p.expect(tokKeyword, "FROM"); err != nil {
+		return nil, err
+	}
+	t := p.next()
+	if t.kind != tokIdent {
+		return nil, p.errorf(t, "expected a table name after FROM")
+	}
+	stmt.table = t.text
+
+	var err error
+	if p.accept(tokKeyword, "WHERE") {
+		if stmt.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(tokKeyword, "GROUP") {
+		if err := p.expect(tokKeyword, "BY"); err != nil {
+			return nil, err
+		}
+		if stmt.groupBy, err = p.parseExprList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(tokKeyword, "HAVING") {
+		if stmt.having, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(tokKeyword, "ORDER") {
+		if err := p.expect(tokKeyword, "BY"); err != nil {
+			return nil, err
+		}
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			term := orderTerm{expr: e}
+			if p.accept(tokKeyword, "DESC") {
+				term.desc = true
+			} else {
+				p.accept(tokKeyword, "ASC")
+			}
+			stmt.orderBy = append(stmt.orderBy, term)
+			if !p.accept(tokSymbol, ",") {
+				break
+			}
+		}
+	}
+	if p.accept(tokKeyword, "LIMIT") {
+		t := p.next()
+		n, err := strconv.Atoi(t.text)
+		if t.kind != tokNumber || err != nil || n < 0 {
+			return nil, p.errorf(t, "LIMIT needs a whole number")
+		}
+		stmt.limit = n
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseExprList() ([]expr, error) {
+	var list []expr
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+		if !p.accept(tokSymbol, ",") {
+			return list, nil
+		}
+	}
+}
+
+// comparisonOps are the binary comparison operators
+var comparisonOps = map[string]bool{"=": true, "!=": true, "<>": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// Precedence, lowest first: OR, AND, NOT, comparison, ||, + -, * / %, unary -
+
+func (p *parser) parseExpr() (expr, error) {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokKeyword, "OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokKeyword, "AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.accept(tokKeyword, "NOT") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{op: "NOT", operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	left, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	switch {
+	case t.kind == tokSymbol && comparisonOps[t.text]:
+		p.next()
+		right, err := p.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		op := t.text
+		if op == "<>" {
+			op = "!="
+		}
+		return &binaryExpr{op: op, left: left, right: right}, nil
+
+	case t.kind == tokKeyword && t.text == "IS":
+		p.next()
+		not := p.accept(tokKeyword, "NOT")
+		if err := p.expect(tokKeyword, "NULL"); err != nil {
+			return nil, err
+		}
+		return &isNullExpr{operand: left, not: not}, nil
+
+	case t.kind == tokKeyword && (t.text == "NOT" || t.text == "LIKE" || t.text == "IN" || t.text == "BETWEEN"):
+		not := p.accept(tokKeyword, "NOT")
+		switch {
+		case p.accept(tokKeyword, "LIKE"):
+			pattern, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			return &likeExpr{operand: left, pattern: pattern, not: not}, nil
+		case p.accept(tokKeyword, "IN"):
+			if err := p.expect(tokSymbol, "("); err != nil {
+				return nil, err
+			}
+			list, err := p.parseExprList()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(tokSymbol, ")"); err != nil {
+				return nil, err
+			}
+			return &inExpr{operand: left, list: list, not: not}, nil
+		case p.accept(tokKeyword, "BETWEEN"):
+			low, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(tokKeyword, "AND"); err != nil {
+				return nil, err
+			}
+			high, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			return &betweenExpr{operand: left, low: low, high: high, not: not}, nil
+		default:
+			return nil, p.errorf(p.peek(), "expected LIKE, IN or BETWEEN after NOT")
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseConcat() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokSymbol, "||") {
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokSymbol || (t.text != "+" && t.text != "-") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: t.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokSymbol || (t.text != "*" && t.text != "/" && t.text != "%") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: t.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.accept(tokSymbol, "-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{op: "-", operand: operand}, nil
+	}
+	p.accept(tokSymbol, "+")
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf(t, "invalid number %q", t.text)
+		}
+		return &literal{value: n}, nil
+
+	case tokString:
+		return &literal{value: t.text}, nil
+
+	case tokKeyword:
+		switch t.text {
+		case "NULL":
+			return &literal{value: nil}, nil
+		case "TRUE":
+			return &literal{value: true}, nil
+		case "FALSE":
+			return &literal{value: false}, nil
+		}
+
+	case tokIdent:
+		if !p.accept(tokSymbol, "(") {
+			return &columnRef{name: t.text}, nil
+		}
+		call := &callExpr{name: strings.ToUpper(t.text)}
+		if p.accept(tokSymbol, "*") {
+			call.star = true
+		} else if !p.accept(tokSymbol, ")") {
+			call.distinct = p.accept(tokKeyword, "DISTINCT")
+			args, err := p.parseExprList()
+			if err != nil {
+				return nil, err
+			}
+			call.args = args
+		} else {
+			return call, nil
+		}
+		if err := p.expect(tokSymbol, ")"); err != nil {
+			return nil, err
+		}
+		return call, nil
+
+	case tokSymbol:
+		if t.text == "(" {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(tokSymbol, ")"); err != nil {
+				return nil, err
+			}
+			return e, nil
+		}
+	}
+
+	if t.kind == tokEOF {
+		return nil, p.errorf(t, "unexpected end of query")
+	}
+	return nil, p.errorf(t, "unexpected %q", t.text)
+}
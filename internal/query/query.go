@@ -0,0 +1,356 @@
+// Package query runs read-only SQL queries over an in-memory table. It
+// implements the SELECT subset that ad-hoc questions about transactions
+// need: WHERE, GROUP BY, HAVING, ORDER BY, LIMIT, DISTINCT, the usual
+// operators, COUNT/SUM/AVG/MIN/MAX and a few scalar functions.
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Table is a named table of rows
+type Table struct {
+	Name    string
+	Columns []string
+	Rows    [][]Value
+}
+
+// Result is the outcome of a query
+type Result struct {
+	Columns []string
+	Rows    [][]Value
+}
+
+// columnIndex is the position of a column, or -1
+func (t *Table) columnIndex(name string) int {
+	for i, column := range t.Columns {
+		if strings.EqualFold(column, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Execute runs a SELECT statement against table
+func Execute(sql string, table *Table) (*Result, error) {
+	stmt, err := parse(sql)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.table != strings.ToLower(table.Name) {
+		return nil, fmt.Errorf("no such table: %s (query the %s table)", stmt.table, table.Name)
+	}
+
+	if stmt.star {
+		for i, column := range table.Columns {
+			stmt.columns = append(stmt.columns, selectColumn{expr: &columnRef{name: column, index: i}, name: column})
+		}
+	}
+
+	// GROUP BY and ORDER BY may name output columns by position or alias
+	outputs := make([]int, len(stmt.orderBy)) // Output column of each ORDER BY term, or -1
+	for i, e := range stmt.groupBy {
+		n, err := stmt.outputColumn("GROUP BY", i, e, table, false)
+		if err != nil {
+			return nil, err
+		}
+		if n >= 0 {
+			stmt.groupBy[i] = stmt.columns[n].expr
+		}
+	}
+	for i, term := range stmt.orderBy {
+		var err error
+		if outputs[i], err = stmt.outputColumn("ORDER BY", i, term.expr, table, true); err != nil {
+			return nil, err
+		}
+		if outputs[i] >= 0 {
+			stmt.orderBy[i].expr = stmt.columns[outputs[i]].expr
+		}
+	}
+
+	if err := stmt.resolve(table); err != nil {
+		return nil, err
+	}
+	if stmt.where != nil && hasAggregate(stmt.where) {
+		return nil, fmt.Errorf("aggregate functions are not allowed in WHERE")
+	}
+	for _, e := range stmt.groupBy {
+		if hasAggregate(e) {
+			return nil, fmt.Errorf("aggregate functions are not allowed in GROUP BY")
+		}
+	}
+
+	var rows [][]Value
+	for _, row := range table.Rows {
+		if stmt.where != nil {
+			v, err := stmt.where.eval(&evalContext{row: row})
+			if err != nil {
+				return nil, err
+			}
+			if !truthy(v) {
+				continue
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	contexts, err := stmt.contexts(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	type outputRow struct {
+		values []Value
+		keys   []Value
+	}
+	var output []outputRow
+	seen := make(map[string]bool)
+	for _, ctx := range contexts {
+		if stmt.having != nil {
+			v, err := stmt.having.eval(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if !truthy(v) {
+				continue
+			}
+		}
+
+		values := make([]Value, len(stmt.columns))
+		for i, column := range stmt.columns {
+			if values[i], err = column.expr.eval(ctx); err != nil {
+				return nil, err
+			}
+		}
+		if stmt.distinct {
+			key := rowKey(values)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+
+		keys := make([]Value, len(stmt.orderBy))
+		for i, term := range stmt.orderBy {
+			if outputs[i] >= 0 {
+				keys[i] = values[outputs[i]]
+			} else if keys[i], err = term.expr.eval(ctx); err != nil {
+				return nil, err
+			}
+		}
+		output = append(output, outputRow{values: values, keys: keys})
+	}
+
+	if len(stmt.orderBy) > 0 {
+		sort.SliceStable(output, func(i, j int) bool {
+			for k, term := range stmt.orderBy {
+				c := compareNullsFirst(output[i].keys[k], output[j].keys[k])
+				if c == 0 {
+					continue
+				}
+				if term.desc {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+	}
+	if stmt.limit >= 0 && len(output) > stmt.limit {
+		output = output[:stmt.limit]
+	}
+
+	result := &Result{}
+	for _, column := range stmt.columns {
+		result.Columns = append(result.Columns, column.name)
+	}
+	for _, row := range output {
+		result.Rows = append(result.Rows, row.values)
+	}
+	return result, nil
+}
+
+// outputColumn is the select-list column the term-th (0-based) ORDER BY or
+// GROUP BY term refers to by 1-based position or alias, or -1. A position
+// outside the select list is an error, as in SQL. GROUP BY prefers a table
+// column over an alias of the same name; ORDER BY prefers the alias.
+func (s *statement) outputColumn(clause string, term int, e expr, table *Table, preferAlias bool) (int, error) {
+	switch e := e.(type) {
+	case *literal:
+		n, ok := e.value.(float64)
+		if !ok || n != float64(int(n)) {
+			return -1, nil
+		}
+		if n < 1 || int(n) > len(s.columns) {
+			return -1, fmt.Errorf("%s term %d out of range: %v should be between 1 and %d", clause, term+1, n, len(s.columns))
+		}
+		return int(n) - 1, nil
+	case *columnRef:
+		if !preferAlias && table.columnIndex(e.name) >= 0 {
+			return -1, nil
+		}
+		for i, column := range s.columns {
+			if strings.EqualFold(column.name, e.name) {
+				return i, nil
+			}
+		}
+	}
+	return -1, nil
+}
+
+// resolve binds column references to table positions
+func (s *statement) resolve(table *Table) error {
+	var exprs []expr
+	for _, column := range s.columns {
+		exprs = append(exprs, column.expr)
+	}
+	exprs = append(exprs, s.where, s.having)
+	exprs = append(exprs, s.groupBy...)
+	for _, term := range s.orderBy {
+		exprs = append(exprs, term.expr)
+	}
+
+	for _, e := range exprs {
+		var err error
+		walk(e, func(e expr) {
+			if ref, ok := e.(*columnRef); ok && err == nil {
+				if ref.index = table.columnIndex(ref.name); ref.index < 0 {
+					err = fmt.Errorf("no such column: %s (columns: %s)", ref.name, strings.Join(table.Columns, ", "))
+				}
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contexts are what the select list is evaluated against: one per row, or
+// one per group when the query groups or aggregates
+func (s *statement) contexts(rows [][]Value) ([]*evalContext, error) {
+	grouped := len(s.groupBy) > 0 || s.having != nil
+	for _, column := range s.columns {
+		grouped = grouped || hasAggregate(column.expr)
+	}
+	for _, term := range s.orderBy {
+		grouped = grouped || hasAggregate(term.expr)
+	}
+
+	if !grouped {
+		contexts := make([]*evalContext, len(rows))
+		for i, row := range rows {
+			contexts[i] = &evalContext{row: row}
+		}
+		return contexts, nil
+	}
+
+	if len(s.groupBy) == 0 {
+		ctx := &evalContext{group: rows}
+		if ctx.group == nil {
+			ctx.group = [][]Value{} // Aggregates of no rows: COUNT is 0, SUM NULL
+		} else {
+			ctx.row = rows[0]
+		}
+		return []*evalContext{ctx}, nil
+	}
+
+	var contexts []*evalContext
+	index := make(map[string]*evalContext)
+	for _, row := range rows {
+		keys := make([]Value, len(s.groupBy))
+		for i, e := range s.groupBy {
+			v, err := e.eval(&evalContext{row: row})
+			if err != nil {
+				return nil, err
+			}
+			keys[i] = v
+		}
+		key := rowKey(keys)
+		ctx, ok := index[key]
+		if !ok {
+			ctx = &evalContext{row: row}
+			index[key] = ctx
+			contexts = append(contexts, ctx)
+		}
+		ctx.group = append(ctx.group, row)
+	}
+	return contexts, nil
+}
+
+// walk calls fn for e and each expression below it
+func walk(e expr, fn func(expr)) {
+	if e == nil {
+		return
+	}
+	fn(e)
+	switch e := e.(type) {
+	case *unaryExpr:
+		walk(e.operand, fn)
+	case *binaryExpr:
+		walk(e.left, fn)
+		walk(e.right, fn)
+	case *isNullExpr:
+		walk(e.operand, fn)
+	case *likeExpr:
+		walk(e.operand, fn)
+		walk(e.pattern, fn)
+	case *inExpr:
+		walk(e.operand, fn)
+		for _, item := range e.list {
+			walk(item, fn)
+		}
+	case *betweenExpr:
+		walk(e.operand, fn)
+		walk(e.low, fn)
+		walk(e.high, fn)
+	case *callExpr:
+		for _, arg := range e.args {
+			walk(arg, fn)
+		}
+	}
+}
+
+func hasAggregate(e expr) bool {
+	found := false
+	walk(e, func(e expr) {
+		if call, ok := e.(*callExpr); ok && aggregates[call.name] {
+			found = true
+		}
+	})
+	return found
+}
+
+// rowKey identifies a tuple of values for grouping and DISTINCT
+func rowKey(values []Value) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		switch v.(type) {
+		case nil:
+			parts[i] = "n"
+		case float64:
+			parts[i] = "f" + toString(v)
+		case bool:
+			parts[i] = "b" + toString(v)
+		default:
+			parts[i] = "s" + strconv.Quote(toString(v))
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// compareNullsFirst orders values for ORDER BY, with NULLs before everything
+func compareNullsFirst(a, b Value) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return compare(a, b)
+}
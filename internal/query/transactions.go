@@ -0,0 +1,75 @@
+package query
+
+import (
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/money"
+	"github.com/quickkly/fintrack/internal/store"
+)
+
+// TransactionColumns are the columns of the transactions table
+var TransactionColumns = []string{
+	"uuid", "date", "month", "year", "timestamp", "account_id", "type", "amount",
+	"currency", "mode", "narration", "merchant", "category_id", "subcategory_id",
+	"reference", "tags", "hidden", "excluded_from_cash_flow", "source", "first_seen",
+	"fingerprint",
+}
+
+// Transactions builds the transactions table from store records. Amounts are
+// signed (negative for outgoing) and rounded like the rest of fintrack; dates
+// are text (2024-03-15, 2024-03) so they compare and group as expected.
+func Transactions(records []*store.Record, rounding money.RoundingMode) *Table {
+	table := &Table{Name: "transactions", Columns: TransactionColumns}
+	for _, record := range records {
+		txn := record.Transaction
+
+		var merchant, categoryID, subcategoryID Value
+		if txn.Merchant != nil && txn.Merchant.Name != nil {
+			merchant = *txn.Merchant.Name
+		}
+		if txn.Category != nil {
+			if txn.Category.ID != nil {
+				categoryID = *txn.Category.ID
+			}
+			if txn.Category.SubcategoryID != nil {
+				subcategoryID = *txn.Category.SubcategoryID
+			}
+		}
+
+		// Dates are the user's calendar days, whatever offset Bend sent
+		t := txn.TxnTimestamp.Local()
+		table.Rows = append(table.Rows, []Value{
+			txn.UUID,
+			t.Format("2006-01-02"),
+			t.Format("2006-01"),
+			float64(t.Year()),
+			t.Format(time.RFC3339),
+			txn.AccountID,
+			txn.Type,
+			money.FromFloat(txn.SignedAmount(), rounding).Float64(),
+			txn.Currency,
+			txn.Mode,
+			txn.Narration,
+			merchant,
+			categoryID,
+			subcategoryID,
+			nullable(txn.Reference),
+			nullable(strings.Join(record.Tags, ",")),
+			txn.IsHidden,
+			txn.ExcludedFromCashFlow,
+			nullable(record.Source),
+			record.FirstSeen.Format(time.RFC3339),
+			txn.Fingerprint(),
+		})
+	}
+	return table
+}
+
+// nullable turns an empty string into NULL
+func nullable(s string) Value {
+	if s == "" {
+		return nil
+	}
+	return s
+}
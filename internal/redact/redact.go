@@ -0,0 +1,132 @@
+// Package redact masks secrets (tokens, cookies, credentials) before they are
+// printed, so config dumps and debug logs are safe to paste into issues
+package redact
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Mask is what a fully hidden value is replaced with
+const Mask = "********"
+
+// secretNames are key fragments that mark a value as secret, matched
+// case-insensitively against config keys, JSON fields and header names
+var secretNames = []string{
+	"token", "secret", "password", "passwd", "cookie",
+	"authorization", "api_key", "apikey", "otp", "device_hash",
+}
+
+// IsSecret reports whether a config key, JSON field or header name holds a secret.
+// Only the last segment of a dotted key is considered.
+func IsSecret(name string) bool {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.ToLower(strings.ReplaceAll(name, "-", "_"))
+	for _, secret := range secretNames {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// String masks a secret value, keeping the first four characters of long
+// values so different secrets can still be told apart
+func String(value string) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) < 16 {
+		return Mask
+	}
+	return value[:4] + Mask
+}
+
+// Value masks value if name is secret
+func Value(name, value string) string {
+	if IsSecret(name) {
+		return String(value)
+	}
+	return value
+}
+
+// Setting masks the secrets in a config value read by key: the value itself
+// when the key is secret, and in maps (such as headers) each entry whose name
+// is. The value is copied, not changed.
+func Setting(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return Value(key, v)
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for name, entry := range v {
+			masked[name] = Setting(name, entry)
+		}
+		return masked
+	case map[string]string:
+		masked := make(map[string]string, len(v))
+		for name, entry := range v {
+			masked[name] = Value(name, entry)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, entry := range v {
+			masked[i] = Setting(key, entry)
+		}
+		return masked
+	}
+	return value
+}
+
+// Headers returns a copy of h with secret headers masked
+func Headers(h http.Header) http.Header {
+	masked := make(http.Header, len(h))
+	for name, values := range h {
+		for _, value := range values {
+			// Keep the scheme of "Bearer <token>" readable
+			if scheme, credentials, ok := strings.Cut(value, " "); ok && strings.EqualFold(name, "Authorization") {
+				masked.Add(name, scheme+" "+String(credentials))
+				continue
+			}
+			masked.Add(name, Value(name, value))
+		}
+	}
+	return masked
+}
+
+// JSON masks secret string fields anywhere in a JSON document. Anything that
+// isn't JSON is returned unchanged.
+func JSON(body []byte) []byte {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return body
+	}
+	masked, err := json.Marshal(walk(doc))
+	if err != nil {
+		return body
+	}
+	return masked
+}
+
+// walk masks secret string fields in a decoded JSON value
+func walk(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if s, ok := field.(string); ok && IsSecret(key) {
+				value[key] = String(s)
+				continue
+			}
+			value[key] = walk(field)
+		}
+	case []interface{}:
+		for i := range value {
+			value[i] = walk(value[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,61 @@
+package report
+
+import (
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// AccountActivity is an account's money in and out over a window, to spot
+// dormant accounts and unusual swings
+type AccountActivity struct {
+	AccountID string       `json:"account_id"`
+	Inflow    money.Amount `json:"-"`
+	Outflow   money.Amount `json:"-"`
+	Count     int          `json:"count"`          // Transactions in the window
+	Last      *time.Time   `json:"last,omitempty"` // Latest stored transaction, in the window or not
+
+	InflowAmount  float64 `json:"inflow"`
+	OutflowAmount float64 `json:"outflow"`
+}
+
+// Dormant reports whether the account had no transactions in the window
+func (a *AccountActivity) Dormant() bool {
+	return a.Count == 0
+}
+
+// Activity totals each account's incoming and outgoing transactions from
+// from onwards, in the transactions' own currency. Accounts without any
+// transactions are missing from the result.
+func Activity(transactions []blend.Transaction, from time.Time, rounding money.RoundingMode) map[string]*AccountActivity {
+	activity := make(map[string]*AccountActivity)
+	for _, txn := range transactions {
+		a, ok := activity[txn.AccountID]
+		if !ok {
+			a = &AccountActivity{AccountID: txn.AccountID}
+			activity[txn.AccountID] = a
+		}
+		if a.Last == nil || txn.TxnTimestamp.After(*a.Last) {
+			last := txn.TxnTimestamp
+			a.Last = &last
+		}
+		if txn.TxnTimestamp.Before(from) {
+			continue
+		}
+
+		a.Count++
+		amount := money.FromFloat(txn.Amount, rounding).Abs()
+		if txn.Type == blend.TransactionTypeIncoming {
+			a.Inflow += amount
+		} else {
+			a.Outflow += amount
+		}
+	}
+
+	for _, a := range activity {
+		a.InflowAmount = a.Inflow.Float64()
+		a.OutflowAmount = a.Outflow.Float64()
+	}
+	return activity
+}
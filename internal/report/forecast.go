@@ -0,0 +1,174 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// forecastSpendDays is the history average discretionary spend is taken over
+const forecastSpendDays = 90
+
+// ForecastAccount is an account's starting point for a forecast
+type ForecastAccount struct {
+	AccountID string
+	Currency  string
+	Balance   money.Amount // Current balance, usually from the last sync
+	Threshold money.Amount // Warn when the projected balance drops below this
+}
+
+// ForecastEvent is an expected recurring payment or income in a forecast
+type ForecastEvent struct {
+	Date   time.Time    `json:"date"`
+	Name   string       `json:"name"`
+	Kind   string       `json:"kind"`
+	Amount money.Amount `json:"-"` // Negative for payments
+
+	AmountValue float64 `json:"amount"`
+}
+
+// ForecastDay is the projected balance at the end of a day
+type ForecastDay struct {
+	Date    time.Time    `json:"date"`
+	Balance money.Amount `json:"-"`
+
+	BalanceValue float64 `json:"balance"`
+}
+
+// AccountForecast is one account's projected balance
+type AccountForecast struct {
+	AccountID  string          `json:"account_id"`
+	Currency   string          `json:"currency"`
+	Start      money.Amount    `json:"-"`
+	DailySpend money.Amount    `json:"-"` // Average discretionary spend per day
+	End        money.Amount    `json:"-"`
+	Lowest     money.Amount    `json:"-"`
+	LowestOn   time.Time       `json:"lowest_on"`
+	Threshold  money.Amount    `json:"-"`
+	BelowOn    *time.Time      `json:"below_on,omitempty"` // First day below the threshold
+	BelowDays  int             `json:"below_days"`         // Days ending below the threshold
+	Events     []ForecastEvent `json:"events"`
+	Days       []ForecastDay   `json:"days"`
+
+	StartAmount      float64 `json:"start"`
+	DailySpendAmount float64 `json:"daily_spend"`
+	EndAmount        float64 `json:"end"`
+	LowestAmount     float64 `json:"lowest"`
+	ThresholdAmount  float64 `json:"threshold"`
+}
+
+// Forecast projects each account's balance days ahead of now: recurring
+// income and payments detected in transactions are applied on their expected
+// dates and the account's average daily discretionary spend - outgoing
+// transactions that are not recurring or excluded from cash flow, over the
+// last forecastSpendDays - is taken off every day. Overdue payments are
+// expected tomorrow, while overdue income is left out: erring low is what a
+// low-balance warning needs.
+func Forecast(transactions []blend.Transaction, accounts []ForecastAccount, now time.Time, days int, rounding money.RoundingMode) []AccountForecast {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	until := today.AddDate(0, 0, days+1).Add(-time.Nanosecond)
+
+	recurring := append(DetectRecurring(transactions, now, rounding), DetectRecurringIncome(transactions, now, rounding)...)
+	recurringKeys := make(map[string]bool, len(recurring))
+	for _, r := range recurring {
+		recurringKeys[r.key] = true
+	}
+
+	forecasts := make([]AccountForecast, 0, len(accounts))
+	for _, account := range accounts {
+		f := AccountForecast{
+			AccountID: account.AccountID,
+			Currency:  account.Currency,
+			Start:     account.Balance,
+			Threshold: account.Threshold,
+			Events:    []ForecastEvent{},
+		}
+		f.DailySpend = dailySpend(transactions, account.AccountID, recurringKeys, now, rounding)
+
+		for _, r := range recurring {
+			if r.AccountID != account.AccountID {
+				continue
+			}
+			amount := r.Amount.Abs()
+			if r.Kind != KindIncome {
+				amount = -amount
+			}
+			for _, due := range r.Due(until) {
+				if due.Before(today.AddDate(0, 0, 1)) {
+					if r.Kind == KindIncome {
+						continue
+					}
+					due = today.AddDate(0, 0, 1)
+				}
+				f.Events = append(f.Events, ForecastEvent{Date: due, Name: r.Name, Kind: r.Kind, Amount: amount, AmountValue: amount.Float64()})
+			}
+		}
+		sort.SliceStable(f.Events, func(i, j int) bool { return f.Events[i].Date.Before(f.Events[j].Date) })
+
+		balance := f.Start
+		f.Lowest, f.LowestOn = balance, today
+		next := 0
+		for i := 1; i <= days; i++ {
+			day := today.AddDate(0, 0, i)
+			balance -= f.DailySpend
+			for next < len(f.Events) && f.Events[next].Date.Before(day.AddDate(0, 0, 1)) {
+				balance += f.Events[next].Amount
+				next++
+			}
+			f.Days = append(f.Days, ForecastDay{Date: day, Balance: balance, BalanceValue: balance.Float64()})
+
+			if balance < f.Lowest {
+				f.Lowest, f.LowestOn = balance, day
+			}
+			if balance < f.Threshold {
+				f.BelowDays++
+				if f.BelowOn == nil {
+					below := day
+					f.BelowOn = &below
+				}
+			}
+		}
+		f.End = balance
+
+		f.StartAmount = f.Start.Float64()
+		f.DailySpendAmount = f.DailySpend.Float64()
+		f.EndAmount = f.End.Float64()
+		f.LowestAmount = f.Lowest.Float64()
+		f.ThresholdAmount = f.Threshold.Float64()
+		forecasts = append(forecasts, f)
+	}
+	return forecasts
+}
+
+// dailySpend averages an account's discretionary spending per day over the
+// last forecastSpendDays, or since its first transaction when that is later
+func dailySpend(transactions []blend.Transaction, accountID string, recurringKeys map[string]bool, now time.Time, rounding money.RoundingMode) money.Amount {
+	from := now.AddDate(0, 0, -forecastSpendDays)
+	flags := blend.TransactionFilters{ExcludeCashflowExcluded: true}
+
+	var total money.Amount
+	var first time.Time
+	for _, txn := range transactions {
+		if txn.AccountID != accountID || txn.TxnTimestamp.Before(from) || txn.TxnTimestamp.After(now) {
+			continue
+		}
+		if first.IsZero() || txn.TxnTimestamp.Before(first) {
+			first = txn.TxnTimestamp
+		}
+		if txn.Type != blend.TransactionTypeOutgoing || recurringKeys[recurringKey(txn)] || !flags.Allows(&txn) {
+			continue
+		}
+		total += money.FromFloat(txn.Amount, rounding).Abs()
+	}
+	if first.IsZero() {
+		return 0
+	}
+
+	days := now.Sub(first).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+	return money.Amount(float64(total) / days)
+}
@@ -0,0 +1,131 @@
+package report
+
+import (
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// daysPerMonth is the average month length used for pacing
+const daysPerMonth = 30.44
+
+// Goal statuses
+const (
+	GoalAchieved = "achieved"
+	GoalOnTrack  = "on track"
+	GoalBehind   = "behind"
+	GoalMissed   = "missed"
+)
+
+// GoalProgress is how far a savings goal has come
+type GoalProgress struct {
+	Name         string       `json:"name"`
+	Target       money.Amount `json:"-"`
+	Saved        money.Amount `json:"-"`
+	Start        time.Time    `json:"start"`
+	By           time.Time    `json:"by"`
+	MonthsLeft   float64      `json:"months_left"`
+	RequiredPace money.Amount `json:"-"` // Per month to reach the target by the deadline
+	ActualPace   money.Amount `json:"-"` // Per month saved so far
+	Projected    money.Amount `json:"-"` // Saved by the deadline at the actual pace
+	Status       string       `json:"status"`
+
+	TargetAmount       float64 `json:"target"`
+	SavedAmount        float64 `json:"saved"`
+	RequiredPaceAmount float64 `json:"required_monthly"`
+	ActualPaceAmount   float64 `json:"actual_monthly"`
+	ProjectedAmount    float64 `json:"projected"`
+}
+
+// Percent is the share of the target saved so far
+func (p GoalProgress) Percent() float64 {
+	if p.Target == 0 {
+		return 0
+	}
+	return float64(p.Saved) / float64(p.Target) * 100
+}
+
+// Goal computes a goal's progress at now from base-currency transactions.
+// Transactions in the goal's accounts count with their sign (net inflow);
+// other transactions count when they are payments in one of its categories.
+func Goal(name string, goal config.GoalConfig, transactions []blend.Transaction, now time.Time, rounding money.RoundingMode) (GoalProgress, error) {
+	start, by, err := goal.Period()
+	if err != nil {
+		return GoalProgress{}, err
+	}
+
+	p := GoalProgress{
+		Name:   name,
+		Target: money.FromFloat(goal.Target, rounding),
+		Start:  start,
+		By:     by,
+	}
+
+	end := now
+	if by.Before(end) {
+		end = by
+	}
+	for _, txn := range transactions {
+		if txn.TxnTimestamp.Before(start) || txn.TxnTimestamp.After(end) {
+			continue
+		}
+		switch {
+		case containsFold(goal.Accounts, txn.AccountID):
+			p.Saved += money.FromFloat(txn.SignedAmount(), rounding)
+		case txn.Type == blend.TransactionTypeOutgoing && txn.Category != nil && txn.Category.ID != nil &&
+			containsFold(goal.Categories, *txn.Category.ID):
+			p.Saved += money.FromFloat(txn.Amount, rounding)
+		}
+	}
+
+	elapsed := months(end.Sub(start))
+	p.MonthsLeft = months(by.Sub(now))
+	if p.MonthsLeft < 0 {
+		p.MonthsLeft = 0
+	}
+
+	remaining := p.Target - p.Saved
+	if remaining > 0 && p.MonthsLeft > 0 {
+		p.RequiredPace = money.FromFloat(remaining.Float64()/p.MonthsLeft, rounding)
+	}
+	if elapsed > 0 {
+		p.ActualPace = money.FromFloat(p.Saved.Float64()/elapsed, rounding)
+	}
+	p.Projected = p.Saved + money.FromFloat(p.ActualPace.Float64()*p.MonthsLeft, rounding)
+
+	switch {
+	case p.Saved >= p.Target:
+		p.Status = GoalAchieved
+	case !now.Before(by):
+		p.Status = GoalMissed
+	case p.Projected >= p.Target:
+		p.Status = GoalOnTrack
+	default:
+		p.Status = GoalBehind
+	}
+
+	p.TargetAmount = p.Target.Float64()
+	p.SavedAmount = p.Saved.Float64()
+	p.RequiredPaceAmount = p.RequiredPace.Float64()
+	p.ActualPaceAmount = p.ActualPace.Float64()
+	p.ProjectedAmount = p.Projected.Float64()
+	return p, nil
+}
+
+// months converts a duration to (fractional) average months
+func months(d time.Duration) float64 {
+	return d.Hours() / 24 / daysPerMonth
+}
+
+// containsFold reports whether list holds s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
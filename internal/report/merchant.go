@@ -0,0 +1,121 @@
+package report
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// MerchantKey normalizes a merchant name for matching: case, punctuation and
+// spacing are ignored, so "SWIGGY*ORDER", "Swiggy Order" and "swiggy-order"
+// are the same merchant
+func MerchantKey(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// MerchantSpend is the spending at one merchant over a run of months
+type MerchantSpend struct {
+	Query     string         `json:"query"`
+	Merchants []string       `json:"merchants"` // Merchant names that matched, most spent first
+	Currency  string         `json:"currency"`
+	Total     money.Amount   `json:"-"`
+	Count     int            `json:"count"`
+	Average   money.Amount   `json:"-"` // Average ticket size
+	Largest   money.Amount   `json:"-"`
+	First     *time.Time     `json:"first,omitempty"` // First and last payment in the window
+	Last      *time.Time     `json:"last,omitempty"`
+	Months    []MonthlySpend `json:"months"` // Oldest first, including months without payments
+
+	TotalAmount   float64 `json:"total"`
+	AverageAmount float64 `json:"average"`
+	LargestAmount float64 `json:"largest"`
+}
+
+// MonthlySpend is one month of a merchant's spending
+type MonthlySpend struct {
+	Month string       `json:"month"` // YYYY-MM
+	Total money.Amount `json:"-"`
+	Count int          `json:"count"`
+
+	TotalAmount float64 `json:"total"`
+}
+
+// MerchantDrillDown totals the outgoing base-currency transactions at
+// merchants matching query (their MerchantKey contains the query's, or equals
+// it when exact is set) over the months months ending with the one holding to
+func MerchantDrillDown(transactions []blend.Transaction, query string, exact bool, months int, to time.Time, currency string, rounding money.RoundingMode) *MerchantSpend {
+	spend := &MerchantSpend{Query: query, Merchants: []string{}, Currency: currency}
+
+	end := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, to.Location())
+	start := end.AddDate(0, -(months - 1), 0)
+	index := make(map[string]int, months)
+	for i := 0; i < months; i++ {
+		month := start.AddDate(0, i, 0).Format("2006-01")
+		index[month] = i
+		spend.Months = append(spend.Months, MonthlySpend{Month: month})
+	}
+
+	key := MerchantKey(query)
+	byName := make(map[string]money.Amount)
+	for _, txn := range transactions {
+		if txn.Type != blend.TransactionTypeOutgoing || txn.TxnTimestamp.Before(start) || txn.TxnTimestamp.After(to) {
+			continue
+		}
+		name := Merchant(txn)
+		merchantKey := MerchantKey(name)
+		if exact && merchantKey != key || !exact && !strings.Contains(merchantKey, key) {
+			continue
+		}
+		i, ok := index[txn.TxnTimestamp.In(to.Location()).Format("2006-01")]
+		if !ok {
+			continue
+		}
+
+		amount := money.FromFloat(txn.Amount, rounding).Abs()
+		spend.Total += amount
+		spend.Count++
+		if amount > spend.Largest {
+			spend.Largest = amount
+		}
+		spend.Months[i].Total += amount
+		spend.Months[i].Count++
+		byName[name] += amount
+
+		at := txn.TxnTimestamp
+		if spend.First == nil || at.Before(*spend.First) {
+			spend.First = &at
+		}
+		if spend.Last == nil || at.After(*spend.Last) {
+			spend.Last = &at
+		}
+	}
+
+	for name := range byName {
+		spend.Merchants = append(spend.Merchants, name)
+	}
+	sort.Slice(spend.Merchants, func(i, j int) bool {
+		a, b := spend.Merchants[i], spend.Merchants[j]
+		if byName[a] == byName[b] {
+			return a < b
+		}
+		return byName[a] > byName[b]
+	})
+
+	if spend.Count > 0 {
+		spend.Average = spend.Total / money.Amount(spend.Count)
+	}
+	spend.TotalAmount = spend.Total.Float64()
+	spend.AverageAmount = spend.Average.Float64()
+	spend.LargestAmount = spend.Largest.Float64()
+	for i := range spend.Months {
+		spend.Months[i].TotalAmount = spend.Months[i].Total.Float64()
+	}
+	return spend
+}
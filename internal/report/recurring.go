@@ -0,0 +1,199 @@
+package report
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// Recurring payment kinds
+const (
+	KindCardBill  = "card bill" // Payments towards a linked credit card
+	KindRecurring = "recurring" // Same merchant, similar amount, regular interval
+	KindIncome    = "income"    // Incoming from the same payer, similar amount, regular interval
+)
+
+// intervals are the recognised payment cycles, with the day ranges they cover
+var intervals = []struct {
+	name     string
+	min, max float64
+	months   int // Calendar months per cycle, so due dates keep their day of month
+	days     int
+}{
+	{"weekly", 6, 8, 0, 7},
+	{"fortnightly", 13, 16, 0, 14},
+	{"monthly", 27, 33, 1, 0},
+	{"quarterly", 85, 97, 3, 0},
+	{"yearly", 355, 375, 12, 0},
+}
+
+// Recurring is a payment that repeats on a regular cycle
+type Recurring struct {
+	Name        string       `json:"name"`
+	Kind        string       `json:"kind"`
+	AccountID   string       `json:"account_id"`
+	Interval    string       `json:"interval"`
+	Amount      money.Amount `json:"-"`
+	Currency    string       `json:"currency"`
+	Occurrences int          `json:"occurrences"`
+	Last        time.Time    `json:"last"`
+	Next        time.Time    `json:"next"`
+
+	AmountValue float64 `json:"amount"`
+
+	key string // recurringKey of its transactions
+}
+
+// DetectRecurring finds outgoing payments that repeat: card bill payments
+// (transactions linked to a credit card bill) and payments to the same
+// merchant at a regular interval and similar amount. Payments that stopped,
+// i.e. are overdue by more than a full cycle at now, are left out.
+func DetectRecurring(transactions []blend.Transaction, now time.Time, rounding money.RoundingMode) []Recurring {
+	return detectRecurring(transactions, blend.TransactionTypeOutgoing, now, rounding)
+}
+
+// DetectRecurringIncome finds incoming transactions that repeat, such as a
+// salary: the same payer at a regular interval and similar amount
+func DetectRecurringIncome(transactions []blend.Transaction, now time.Time, rounding money.RoundingMode) []Recurring {
+	return detectRecurring(transactions, blend.TransactionTypeIncoming, now, rounding)
+}
+
+// Due returns the dates the payment is expected on from Next up to and
+// including until
+func (r Recurring) Due(until time.Time) []time.Time {
+	var months, days int
+	for _, interval := range intervals {
+		if interval.name == r.Interval {
+			months, days = interval.months, interval.days
+		}
+	}
+	if months == 0 && days == 0 {
+		return nil
+	}
+
+	var dates []time.Time
+	for n, next := 1, r.Next; !next.After(until); n++ {
+		dates = append(dates, next)
+		// Step from Last each time so month-end dates don't drift
+		next = r.Last.AddDate(0, months*(n+1), days*(n+1))
+	}
+	return dates
+}
+
+func detectRecurring(transactions []blend.Transaction, direction string, now time.Time, rounding money.RoundingMode) []Recurring {
+	groups := make(map[string][]blend.Transaction)
+	for _, txn := range transactions {
+		if txn.Type != direction {
+			continue
+		}
+		groups[recurringKey(txn)] = append(groups[recurringKey(txn)], txn)
+	}
+
+	var found []Recurring
+	for key, txns := range groups {
+		cardBill := strings.HasPrefix(key, "card:")
+		if len(txns) < 3 && !(cardBill && len(txns) == 2) {
+			continue
+		}
+		sort.Slice(txns, func(i, j int) bool { return txns[i].TxnTimestamp.Before(txns[j].TxnTimestamp) })
+
+		var gaps, amounts []float64
+		for i, txn := range txns {
+			amounts = append(amounts, txn.Amount)
+			if i > 0 {
+				gaps = append(gaps, txn.TxnTimestamp.Sub(txns[i-1].TxnTimestamp).Hours()/24)
+			}
+		}
+
+		gap := median(gaps)
+		cycle := -1
+		for i, interval := range intervals {
+			if gap >= interval.min && gap <= interval.max {
+				cycle = i
+				break
+			}
+		}
+		if cycle < 0 || !mostlyWithin(gaps, gap, 0.25) {
+			continue
+		}
+		// Card bills vary with spending; other recurring payments shouldn't
+		if !cardBill && !mostlyWithin(amounts, median(amounts), 0.2) {
+			continue
+		}
+
+		last := txns[len(txns)-1]
+		interval := intervals[cycle]
+		next := last.TxnTimestamp.AddDate(0, interval.months, interval.days)
+		if next.AddDate(0, interval.months, interval.days).Before(now) {
+			continue
+		}
+
+		r := Recurring{
+			Name:        Merchant(last),
+			Kind:        KindRecurring,
+			AccountID:   last.AccountID,
+			Interval:    interval.name,
+			Amount:      money.FromFloat(median(amounts), rounding),
+			Currency:    last.Currency,
+			Occurrences: len(txns),
+			Last:        last.TxnTimestamp,
+			Next:        next,
+			key:         key,
+		}
+		if direction == blend.TransactionTypeIncoming {
+			r.Kind = KindIncome
+		}
+		if cardBill {
+			r.Kind = KindCardBill
+			r.Name = "Card bill " + strings.TrimPrefix(key, "card:")
+			r.Amount = money.FromFloat(last.Amount, rounding)
+		}
+		r.AmountValue = r.Amount.Float64()
+		found = append(found, r)
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Next.Equal(found[j].Next) {
+			return found[i].Name < found[j].Name
+		}
+		return found[i].Next.Before(found[j].Next)
+	})
+	return found
+}
+
+// recurringKey groups payments that may be the same recurring payment
+func recurringKey(txn blend.Transaction) string {
+	if txn.LinkedCCAccountIDForBill != nil && *txn.LinkedCCAccountIDForBill != "" {
+		return "card:" + *txn.LinkedCCAccountIDForBill
+	}
+	return "merchant:" + txn.AccountID + ":" + strings.ToLower(strings.TrimSpace(Merchant(txn)))
+}
+
+// median returns the middle value of values
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// mostlyWithin reports whether at least three quarters of values lie within
+// tolerance (a fraction) of target, so one late or odd payment is forgiven
+func mostlyWithin(values []float64, target, tolerance float64) bool {
+	within := 0
+	for _, v := range values {
+		if v >= target*(1-tolerance) && v <= target*(1+tolerance) {
+			within++
+		}
+	}
+	return float64(within) >= 0.75*float64(len(values))
+}
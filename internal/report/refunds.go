@@ -0,0 +1,164 @@
+package report
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// refundTolerance is how far (a fraction) a refund may differ from the
+// purchase it matches, so fees and partial reversals still pair up
+const refundTolerance = 0.1
+
+// refundWords mark a narration as a refund; they're left out when comparing
+// merchants so "REFUND SWIGGY" matches "SWIGGY"
+var refundWords = map[string]bool{"refund": true, "rfnd": true, "reversal": true, "reversed": true, "rev": true}
+
+// Bend refund statuses that don't mean a refund is expected
+var noRefundStatuses = map[string]bool{"": true, "NONE": true}
+
+// RefundMatch pairs a refund or reversal with the purchase it undoes
+type RefundMatch struct {
+	Refund   blend.Transaction `json:"refund"`
+	Purchase blend.Transaction `json:"purchase"`
+	Days     int               `json:"days"` // From purchase to refund
+}
+
+// MatchRefunds pairs incoming transactions with the outgoing purchase they
+// refund: same account and currency, the same merchant once refund words and
+// reference numbers are ignored (one name's words all appear in the other, so
+// "REFUND AMAZON" matches "AMAZON ORDER"), an amount within refundTolerance, and dated
+// up to window days after the purchase. Each purchase takes at most one
+// refund; the closest amount wins, then the most recent purchase.
+func MatchRefunds(transactions []blend.Transaction, window int, rounding money.RoundingMode) []RefundMatch {
+	purchases := make(map[string][]blend.Transaction) // By account
+	var refunds []blend.Transaction
+	for _, txn := range transactions {
+		if len(refundKey(txn)) == 0 || txn.UUID == "" {
+			continue
+		}
+		if txn.Type == blend.TransactionTypeOutgoing {
+			purchases[txn.AccountID] = append(purchases[txn.AccountID], txn)
+		} else if txn.Type == blend.TransactionTypeIncoming {
+			refunds = append(refunds, txn)
+		}
+	}
+	sort.SliceStable(refunds, func(i, j int) bool { return refunds[i].TxnTimestamp.Before(refunds[j].TxnTimestamp) })
+
+	matched := make(map[string]bool)
+	var matches []RefundMatch
+	for _, refund := range refunds {
+		amount := money.FromFloat(refund.Amount, rounding).Abs()
+		best := -1
+		var bestDiff money.Amount
+		key := refundKey(refund)
+		candidates := purchases[refund.AccountID]
+		for i, purchase := range candidates {
+			if matched[purchase.UUID] || !strings.EqualFold(purchase.Currency, refund.Currency) || !sameMerchant(key, refundKey(purchase)) {
+				continue
+			}
+			if purchase.TxnTimestamp.After(refund.TxnTimestamp) || refund.TxnTimestamp.Sub(purchase.TxnTimestamp) > time.Duration(window)*24*time.Hour {
+				continue
+			}
+			paid := money.FromFloat(purchase.Amount, rounding).Abs()
+			diff := (paid - amount).Abs()
+			if float64(diff) > refundTolerance*float64(paid) {
+				continue
+			}
+			if best < 0 || diff < bestDiff || diff == bestDiff && purchase.TxnTimestamp.After(candidates[best].TxnTimestamp) {
+				best, bestDiff = i, diff
+			}
+		}
+		if best < 0 {
+			continue
+		}
+
+		purchase := candidates[best]
+		matched[purchase.UUID] = true
+		matches = append(matches, RefundMatch{
+			Refund:   refund,
+			Purchase: purchase,
+			Days:     int(refund.TxnTimestamp.Sub(purchase.TxnTimestamp).Hours() / 24),
+		})
+	}
+	return matches
+}
+
+// NetRefunds takes matched refunds off their purchases: the refunds are
+// dropped and each purchase is reduced by its refund, or dropped when fully
+// refunded, so spending totals are net of refunds
+func NetRefunds(transactions []blend.Transaction, matches []RefundMatch) []blend.Transaction {
+	if len(matches) == 0 {
+		return transactions
+	}
+	refunded := make(map[string]float64, len(matches))
+	refunds := make(map[string]bool, len(matches))
+	for _, m := range matches {
+		refunded[m.Purchase.UUID] += m.Refund.Amount
+		refunds[m.Refund.UUID] = true
+	}
+
+	net := make([]blend.Transaction, 0, len(transactions))
+	for _, txn := range transactions {
+		if refunds[txn.UUID] {
+			continue
+		}
+		if amount, ok := refunded[txn.UUID]; ok {
+			if amount >= txn.Amount {
+				continue
+			}
+			txn.Amount -= amount
+		}
+		net = append(net, txn)
+	}
+	return net
+}
+
+// PendingRefunds returns the purchases Bend flags with a refund status that
+// no local refund has been matched to yet, oldest first
+func PendingRefunds(transactions []blend.Transaction, matches []RefundMatch) []blend.Transaction {
+	matched := make(map[string]bool, len(matches))
+	for _, m := range matches {
+		matched[m.Purchase.UUID] = true
+	}
+
+	var pending []blend.Transaction
+	for _, txn := range transactions {
+		if txn.Type == blend.TransactionTypeOutgoing && !noRefundStatuses[strings.ToUpper(txn.Refund.Status)] && !matched[txn.UUID] {
+			pending = append(pending, txn)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].TxnTimestamp.Before(pending[j].TxnTimestamp) })
+	return pending
+}
+
+// refundKey is the merchant a purchase or refund is matched on: the words of
+// its MerchantKey, without refund words or words holding digits (references)
+func refundKey(txn blend.Transaction) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(MerchantKey(Merchant(txn))) {
+		if !refundWords[word] && !strings.ContainsAny(word, "0123456789") {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// sameMerchant reports whether every word of the shorter key is in the other
+func sameMerchant(a, b map[string]bool) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(a) == 0 {
+		return false
+	}
+	for word := range a {
+		if !b[word] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// summaryTopCategories is how many categories a summary lists
+const summaryTopCategories = 5
+
+// Anomaly thresholds: a category is unusual when its spending reaches
+// anomalyFactor times its average over the previous anomalyPeriods periods
+const (
+	anomalyFactor  = 1.5
+	anomalyPeriods = 3
+)
+
+// Summary is an overview of a period for the scheduled email summary:
+// spending and income, where the money went, savings goals and anything
+// unusual
+type Summary struct {
+	From          time.Time         `json:"from"`
+	To            time.Time         `json:"to"`
+	Currency      string            `json:"currency"`
+	Spent         money.Amount      `json:"-"`
+	Income        money.Amount      `json:"-"`
+	PreviousSpent money.Amount      `json:"-"` // Spending in the period before
+	Transactions  int               `json:"transactions"`
+	Categories    []CategorySummary `json:"top_categories"`
+	Goals         []GoalProgress    `json:"goals,omitempty"`
+	Anomalies     []string          `json:"anomalies"` // Unusual category spending and triggered alert rules
+
+	SpentAmount         float64 `json:"spent"`
+	IncomeAmount        float64 `json:"income"`
+	PreviousSpentAmount float64 `json:"previous_spent"`
+}
+
+// CategorySummary is a category's spending in a summary
+type CategorySummary struct {
+	Name    string       `json:"name"`
+	Total   money.Amount `json:"-"`
+	Average money.Amount `json:"-"` // Average over the previous periods
+	Count   int          `json:"count"`
+
+	TotalAmount   float64 `json:"total"`
+	AverageAmount float64 `json:"average"`
+}
+
+// Summarize builds the summary of [from, to] from base-currency transactions,
+// which should include a few periods of history before from for comparison.
+// Goals and alert anomalies are added by the caller.
+func Summarize(transactions []blend.Transaction, from, to time.Time, currency string, rounding money.RoundingMode) *Summary {
+	s := &Summary{From: from, To: to, Currency: currency, Anomalies: []string{}}
+
+	var spending []blend.Transaction
+	for _, txn := range inPeriod(transactions, from, to) {
+		s.Transactions++
+		if txn.Type == blend.TransactionTypeIncoming {
+			s.Income += money.FromFloat(txn.Amount, rounding)
+		} else {
+			spending = append(spending, txn)
+		}
+	}
+	tree := Tree(spending, rounding)
+	s.Spent = tree.Total
+
+	// Category totals of the previous periods, for the averages
+	previous := make(map[string]money.Amount)
+	for n := 1; n <= anomalyPeriods; n++ {
+		prevFrom, prevTo := ShiftRange(from, to, -n)
+		var prevSpending []blend.Transaction
+		for _, txn := range inPeriod(transactions, prevFrom, prevTo) {
+			if txn.Type != blend.TransactionTypeIncoming {
+				prevSpending = append(prevSpending, txn)
+			}
+		}
+		prevTree := Tree(prevSpending, rounding)
+		if n == 1 {
+			s.PreviousSpent = prevTree.Total
+		}
+		for _, category := range prevTree.Children {
+			previous[category.Name] += category.Total
+		}
+	}
+
+	for i, category := range tree.Children {
+		average := previous[category.Name] / anomalyPeriods
+		if i < summaryTopCategories {
+			s.Categories = append(s.Categories, CategorySummary{
+				Name: category.Name, Total: category.Total, Average: average, Count: category.Count,
+				TotalAmount: category.Total.Float64(), AverageAmount: average.Float64(),
+			})
+		}
+		if average > 0 && float64(category.Total) >= anomalyFactor*float64(average) {
+			s.Anomalies = append(s.Anomalies, fmt.Sprintf("%s: %s, %.1fx the %d-period average of %s", category.Name,
+				money.New(category.Total, currency), float64(category.Total)/float64(average), anomalyPeriods, money.New(average, currency)))
+		}
+	}
+
+	s.SpentAmount = s.Spent.Float64()
+	s.IncomeAmount = s.Income.Float64()
+	s.PreviousSpentAmount = s.PreviousSpent.Float64()
+	return s
+}
+
+// inPeriod keeps the transactions dated within [from, to]
+func inPeriod(transactions []blend.Transaction, from, to time.Time) []blend.Transaction {
+	var kept []blend.Transaction
+	for _, txn := range transactions {
+		if !txn.TxnTimestamp.Before(from) && !txn.TxnTimestamp.After(to) {
+			kept = append(kept, txn)
+		}
+	}
+	return kept
+}
+
+// ShiftRange moves [from, to] by n periods of its own length. Whole calendar
+// months shift by months, so February follows January.
+func ShiftRange(from, to time.Time, n int) (time.Time, time.Time) {
+	end := to.Add(time.Nanosecond)
+	if from.Day() == 1 && end.Day() == 1 && from.Hour() == 0 && end.Hour() == 0 {
+		months := (end.Year()-from.Year())*12 + int(end.Month()-from.Month())
+		if months > 0 {
+			start := from.AddDate(0, n*months, 0)
+			return start, start.AddDate(0, months, 0).Add(-time.Nanosecond)
+		}
+	}
+	length := end.Sub(from)
+	start := from.Add(time.Duration(n) * length)
+	return start, start.Add(length - time.Nanosecond)
+}
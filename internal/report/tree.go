@@ -0,0 +1,137 @@
+// Package report aggregates stored transactions into spending reports
+package report
+
+import (
+	"sort"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// Uncategorized names transactions Bend didn't categorize
+const Uncategorized = "uncategorized"
+
+// Node is one level of the category → subcategory → merchant hierarchy
+type Node struct {
+	Name     string       `json:"name"`
+	Total    money.Amount `json:"-"`
+	Amount   float64      `json:"amount"`
+	Count    int          `json:"count"`
+	Children []*Node      `json:"children,omitempty"`
+
+	index map[string]*Node
+}
+
+// Tree groups transactions by category, subcategory and merchant. Amounts are
+// absolute, so the totals of a spending tree are positive.
+func Tree(transactions []blend.Transaction, rounding money.RoundingMode) *Node {
+	root := &Node{Name: "total"}
+	for _, txn := range transactions {
+		amount := money.FromFloat(txn.Amount, rounding).Abs()
+
+		category, subcategory := Uncategorized, Uncategorized
+		if txn.Category != nil {
+			if txn.Category.ID != nil && *txn.Category.ID != "" {
+				category = *txn.Category.ID
+			}
+			if txn.Category.SubcategoryID != nil && *txn.Category.SubcategoryID != "" {
+				subcategory = *txn.Category.SubcategoryID
+			}
+		}
+
+		node := root
+		node.add(amount)
+		for _, name := range []string{category, subcategory, Merchant(txn)} {
+			node = node.child(name)
+			node.add(amount)
+		}
+	}
+	root.finish()
+	return root
+}
+
+// Merchant is the name a transaction is grouped under: the merchant Bend
+// resolved, otherwise the narration
+func Merchant(txn blend.Transaction) string {
+	if txn.Merchant != nil && txn.Merchant.Name != nil && *txn.Merchant.Name != "" {
+		return *txn.Merchant.Name
+	}
+	if txn.Narration != "" {
+		return txn.Narration
+	}
+	return "unknown"
+}
+
+// Share is the node's fraction of a parent total, in percent
+func (n *Node) Share(parent *Node) float64 {
+	if parent == nil || parent.Total == 0 {
+		return 0
+	}
+	return float64(n.Total) / float64(parent.Total) * 100
+}
+
+// Walk visits the nodes below n depth-first, largest first, down to maxDepth
+// levels (0 for all)
+func (n *Node) Walk(maxDepth int, visit func(node, parent *Node, depth int)) {
+	n.walk(maxDepth, 1, visit)
+}
+
+func (n *Node) walk(maxDepth, depth int, visit func(node, parent *Node, depth int)) {
+	if maxDepth > 0 && depth > maxDepth {
+		return
+	}
+	for _, child := range n.Children {
+		visit(child, n, depth)
+		child.walk(maxDepth, depth+1, visit)
+	}
+}
+
+// Prune drops the levels below maxDepth, for output that nests children
+func (n *Node) Prune(maxDepth int) {
+	if maxDepth <= 0 {
+		return
+	}
+	if maxDepth == 1 {
+		for _, child := range n.Children {
+			child.Children = nil
+		}
+		return
+	}
+	for _, child := range n.Children {
+		child.Prune(maxDepth - 1)
+	}
+}
+
+// add counts a transaction in the node
+func (n *Node) add(amount money.Amount) {
+	n.Total += amount
+	n.Count++
+}
+
+// child returns the named child, creating it on first use
+func (n *Node) child(name string) *Node {
+	if n.index == nil {
+		n.index = make(map[string]*Node)
+	}
+	c, ok := n.index[name]
+	if !ok {
+		c = &Node{Name: name}
+		n.index[name] = c
+		n.Children = append(n.Children, c)
+	}
+	return c
+}
+
+// finish sorts children largest first and fills in the JSON amounts
+func (n *Node) finish() {
+	n.Amount = n.Total.Float64()
+	sort.Slice(n.Children, func(i, j int) bool {
+		if n.Children[i].Total == n.Children[j].Total {
+			return n.Children[i].Name < n.Children[j].Name
+		}
+		return n.Children[i].Total > n.Children[j].Total
+	})
+	for _, child := range n.Children {
+		child.finish()
+	}
+}
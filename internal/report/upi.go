@@ -0,0 +1,149 @@
+package report
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/config"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// PeopleCategory is the category person-to-person UPI transfers are reported
+// under, with the contact's label as the subcategory
+const PeopleCategory = "people"
+
+// Unlabelled is the subcategory of transfers to contacts without a label
+const Unlabelled = "unlabelled"
+
+// vpaPattern matches a UPI virtual payment address: handle@psp
+var vpaPattern = regexp.MustCompile(`(?i)[a-z0-9][a-z0-9._-]{1,255}@[a-z][a-z0-9]{1,63}`)
+
+// ExtractVPA returns the first UPI address in text, lowercased, or "".
+// Email addresses (the part after @ has a dot) are skipped.
+func ExtractVPA(text string) string {
+	for _, loc := range vpaPattern.FindAllStringIndex(text, -1) {
+		if loc[1] < len(text) && text[loc[1]] == '.' {
+			continue
+		}
+		return strings.ToLower(text[loc[0]:loc[1]])
+	}
+	return ""
+}
+
+// Counterparty is someone paid or paid by over UPI, with what went each way
+type Counterparty struct {
+	VPA           string       `json:"vpa"`
+	Name          string       `json:"name,omitempty"`
+	Label         string       `json:"label,omitempty"`
+	Merchant      bool         `json:"merchant"`
+	Saved         bool         `json:"saved"` // In the contact book
+	Sent          money.Amount `json:"-"`
+	Received      money.Amount `json:"-"`
+	SentCount     int          `json:"sent_count"`
+	ReceivedCount int          `json:"received_count"`
+	Last          time.Time    `json:"last"`
+
+	SentAmount     float64 `json:"sent"`
+	ReceivedAmount float64 `json:"received"`
+}
+
+// Counterparties collects the UPI addresses found in transactions' narrations,
+// merged with the contact book, most money moved first. Amounts are summed
+// as given, so pass base-currency transactions.
+func Counterparties(transactions []blend.Transaction, contacts []config.ContactConfig, rounding money.RoundingMode) []Counterparty {
+	byVPA := make(map[string]*Counterparty)
+	for _, contact := range contacts {
+		vpa := strings.ToLower(contact.VPA)
+		byVPA[vpa] = &Counterparty{VPA: vpa, Name: contact.Name, Label: contact.Label, Merchant: contact.Merchant, Saved: true}
+	}
+
+	for _, txn := range transactions {
+		vpa := ExtractVPA(txn.Narration)
+		if vpa == "" {
+			continue
+		}
+		c, ok := byVPA[vpa]
+		if !ok {
+			c = &Counterparty{VPA: vpa, Merchant: resolvedMerchant(txn)}
+			byVPA[vpa] = c
+		}
+
+		amount := money.FromFloat(txn.Amount, rounding).Abs()
+		if txn.Type == blend.TransactionTypeIncoming {
+			c.Received += amount
+			c.ReceivedCount++
+		} else {
+			c.Sent += amount
+			c.SentCount++
+		}
+		if txn.TxnTimestamp.After(c.Last) {
+			c.Last = txn.TxnTimestamp
+		}
+	}
+
+	found := make([]Counterparty, 0, len(byVPA))
+	for _, c := range byVPA {
+		c.SentAmount = c.Sent.Float64()
+		c.ReceivedAmount = c.Received.Float64()
+		found = append(found, *c)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		a, b := found[i].Sent+found[i].Received, found[j].Sent+found[j].Received
+		if a == b {
+			return found[i].VPA < found[j].VPA
+		}
+		return a > b
+	})
+	return found
+}
+
+// IsPersonToPerson reports whether a transaction is a UPI transfer to or from
+// a person: its narration has a VPA, and neither the contact book nor Bend
+// (by resolving a merchant) says it's a business
+func IsPersonToPerson(txn blend.Transaction, contacts []config.ContactConfig) bool {
+	vpa := ExtractVPA(txn.Narration)
+	if vpa == "" {
+		return false
+	}
+	for _, contact := range contacts {
+		if strings.EqualFold(contact.VPA, vpa) {
+			return !contact.Merchant
+		}
+	}
+	return !resolvedMerchant(txn)
+}
+
+// GroupPeople moves person-to-person transfers out of their Bend category into
+// PeopleCategory, under the contact's label and name (or VPA), so reports
+// total them apart from merchant spend
+func GroupPeople(transactions []blend.Transaction, contacts []config.ContactConfig) []blend.Transaction {
+	grouped := make([]blend.Transaction, 0, len(transactions))
+	for _, txn := range transactions {
+		if IsPersonToPerson(txn, contacts) {
+			category, label, name := PeopleCategory, Unlabelled, ExtractVPA(txn.Narration)
+			for _, contact := range contacts {
+				if !strings.EqualFold(contact.VPA, name) {
+					continue
+				}
+				if contact.Label != "" {
+					label = contact.Label
+				}
+				if contact.Name != "" {
+					name = contact.Name
+				}
+			}
+			txn.Category = &blend.TransactionCategory{ID: &category, SubcategoryID: &label}
+			txn.Merchant = &blend.TransactionMerchant{Name: &name}
+		}
+		grouped = append(grouped, txn)
+	}
+	return grouped
+}
+
+// resolvedMerchant reports whether Bend matched the transaction to a merchant
+func resolvedMerchant(txn blend.Transaction) bool {
+	return txn.Merchant != nil && txn.Merchant.Name != nil && *txn.Merchant.Name != ""
+}
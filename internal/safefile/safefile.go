@@ -0,0 +1,120 @@
+// Package safefile writes files so a crash or a concurrent fintrack never
+// leaves a truncated one behind: data goes to a temporary file in the same
+// directory, is synced, and then renamed over the target. Lock serializes
+// writers of files several processes update, like the session.
+package safefile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Write replaces path with data atomically
+func Write(path string, data []byte, perm os.FileMode) error {
+	return WriteWith(path, perm, func(tmp *os.File) error {
+		_, err := tmp.Write(data)
+		return err
+	})
+}
+
+// WriteWith replaces path atomically with what write puts in the temporary
+// file. The temporary file keeps path's extension, for writers that go by it.
+func WriteWith(path string, perm os.FileMode, write func(tmp *os.File) error) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))+".tmp-*"+filepath.Ext(path))
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	done := false
+	defer func() {
+		if !done {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if err := write(tmp); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	done = true
+	syncDir(dir)
+	return nil
+}
+
+// Perm returns path's permissions, or fallback when it doesn't exist yet,
+// so rewriting a file keeps the mode its owner gave it
+func Perm(path string, fallback os.FileMode) os.FileMode {
+	if info, err := os.Stat(path); err == nil {
+		return info.Mode().Perm()
+	}
+	return fallback
+}
+
+// syncDir makes a rename durable. Not every platform can open a directory
+// for syncing (Windows can't), so failures are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// lockStale is how old a lock file may get before it is taken to be left
+// over from a crashed process. It must outlast the longest lock holder.
+const lockStale = 2 * time.Minute
+
+// lockPoll is how often a held lock is retried
+const lockPoll = 50 * time.Millisecond
+
+// ErrLocked is returned when a lock stays held past the timeout
+var ErrLocked = errors.New("file is locked by another fintrack process")
+
+// Lock takes an exclusive lock on path, held as path+".lock", waiting up to
+// timeout for another process to release it. Call the returned function to
+// release it.
+func Lock(path string, timeout time.Duration) (func(), error) {
+	lockPath := path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.WriteString(strconv.Itoa(os.Getpid()))
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: %s (remove it if no fintrack is running)", ErrLocked, lockPath)
+		}
+		time.Sleep(lockPoll)
+	}
+}
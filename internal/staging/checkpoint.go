@@ -0,0 +1,90 @@
+package staging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/profile"
+	"github.com/quickkly/fintrack/internal/safefile"
+)
+
+// checkpointExt is deliberately not .json so checkpoints are never read as staging files
+const checkpointExt = ".checkpoint"
+
+// Checkpoint records the progress of an interrupted --fetch-all run so it can
+// be resumed from the last saved page instead of starting over
+type Checkpoint struct {
+	Key          string                   `json:"key"`
+	After        string                   `json:"after"`
+	Page         int                      `json:"page"`
+	Fetched      int                      `json:"fetched"`
+	Total        int                      `json:"total"`
+	Transactions []blend.Transaction      `json:"transactions,omitempty"` // Not kept for a streamed fetch
+	Counts       []blend.TransactionCount `json:"counts,omitempty"`
+	UpdatedAt    time.Time                `json:"updated_at"`
+}
+
+// CheckpointKey derives a stable key for a fetch from its parameters, so a
+// resumed run only picks up a checkpoint written by the same query
+func CheckpointKey(params ...interface{}) string {
+	data, _ := json.Marshal(params)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// CheckpointPath returns the checkpoint file for key inside the staging dir
+func CheckpointPath(dir, key string) string {
+	return filepath.Join(dir, ".fetch-"+key+checkpointExt)
+}
+
+// LoadCheckpoint reads a checkpoint. It returns nil without error when none exists.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+
+	return &cp, nil
+}
+
+// SaveCheckpoint writes a checkpoint atomically so a crash mid-write never
+// leaves a truncated file behind
+func SaveCheckpoint(path string, cp *Checkpoint) error {
+	defer profile.Start(profile.Write)()
+
+	cp.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	if err := safefile.Write(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveCheckpoint deletes a checkpoint once its fetch has completed
+func RemoveCheckpoint(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package staging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/profile"
+	"github.com/quickkly/fintrack/internal/safefile"
+)
+
+// DefaultDir is the staging directory used when none is configured
+const DefaultDir = "./staging"
+
+// TransactionFileV3 represents the structure for saving fetched v3 transaction data
+type TransactionFileV3 struct {
+	Transactions []blend.Transaction      `json:"transactions"`
+	Counts       []blend.TransactionCount `json:"counts"`
+	FetchedAt    time.Time                `json:"fetched_at"`
+	DateRange    DateRange                `json:"date_range"`
+	TotalCount   int                      `json:"total_count"`
+
+	// Amounts converted at each transaction's historical rate (see --normalize)
+	BaseCurrency string             `json:"base_currency,omitempty"`
+	BaseAmounts  map[string]float64 `json:"base_amounts,omitempty"` // Keyed by transaction UUID
+}
+
+// DateRange represents the date range for fetched transactions
+type DateRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// NewFile builds a staging file for freshly fetched transactions
+func NewFile(transactions []blend.Transaction, counts []blend.TransactionCount, from, to time.Time) *TransactionFileV3 {
+	return &TransactionFileV3{
+		Transactions: transactions,
+		Counts:       counts,
+		FetchedAt:    time.Now(),
+		DateRange: DateRange{
+			From: from,
+			To:   to,
+		},
+		TotalCount: len(transactions),
+	}
+}
+
+// Save writes a staging file for the given transactions
+func Save(path string, transactions []blend.Transaction, counts []blend.TransactionCount, from, to time.Time) error {
+	return Write(path, NewFile(transactions, counts, from, to))
+}
+
+// Write writes a staging file to path
+func Write(path string, data *TransactionFileV3) error {
+	defer profile.Start(profile.Write)()
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction data: %w", err)
+	}
+
+	return safefile.Write(path, jsonData, 0644)
+}
+
+// ReadFile reads a single staging file
+func ReadFile(path string) (*TransactionFileV3, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var file TransactionFileV3
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return &file, nil
+}
+
+// Files returns the staging JSON files in dir, oldest first by name
+func Files(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staging directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		// Hidden files are temporaries of a write that didn't finish
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// Snapshot is the deduplicated content of a staging directory
+type Snapshot struct {
+	Transactions []blend.Transaction  // Oldest first
+	FirstSeen    map[string]time.Time // Earliest fetch time of each transaction UUID
+	LatestFetch  time.Time            // Fetch time of the newest staging file
+}
+
+// LoadTransactions reads every staging file in dir and returns the union of
+// their transactions, deduplicated by UUID. When a transaction appears in
+// several files the copy from the most recently fetched file wins.
+func LoadTransactions(dir string) ([]blend.Transaction, error) {
+	snapshot, err := LoadSnapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Transactions, nil
+}
+
+// LoadSnapshot is LoadTransactions that also records when each transaction
+// was first fetched, for consumers that only want what is new
+func LoadSnapshot(dir string) (*Snapshot, error) {
+	files, err := Files(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type versioned struct {
+		txn       blend.Transaction
+		fetchedAt time.Time
+	}
+	byID := make(map[string]versioned)
+	snapshot := &Snapshot{FirstSeen: make(map[string]time.Time)}
+
+	for _, path := range files {
+		file, err := ReadFile(path)
+		if err != nil {
+			// Other JSON files may share the directory; skip what isn't ours
+			continue
+		}
+		if file.FetchedAt.After(snapshot.LatestFetch) {
+			snapshot.LatestFetch = file.FetchedAt
+		}
+		for _, txn := range file.Transactions {
+			if first, ok := snapshot.FirstSeen[txn.UUID]; !ok || file.FetchedAt.Before(first) {
+				snapshot.FirstSeen[txn.UUID] = file.FetchedAt
+			}
+			if existing, ok := byID[txn.UUID]; ok && existing.fetchedAt.After(file.FetchedAt) {
+				continue
+			}
+			byID[txn.UUID] = versioned{txn: txn, fetchedAt: file.FetchedAt}
+		}
+	}
+
+	snapshot.Transactions = make([]blend.Transaction, 0, len(byID))
+	for _, v := range byID {
+		snapshot.Transactions = append(snapshot.Transactions, v.txn)
+	}
+	sort.Slice(snapshot.Transactions, func(i, j int) bool {
+		return snapshot.Transactions[i].TxnTimestamp.Before(snapshot.Transactions[j].TxnTimestamp)
+	})
+
+	return snapshot, nil
+}
+
+// Since returns the snapshot's transactions first fetched after t
+func (s *Snapshot) Since(t time.Time) []blend.Transaction {
+	var fresh []blend.Transaction
+	for _, txn := range s.Transactions {
+		if s.FirstSeen[txn.UUID].After(t) {
+			fresh = append(fresh, txn)
+		}
+	}
+	return fresh
+}
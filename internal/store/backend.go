@@ -0,0 +1,158 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backend persists a store's records. The Store keeps its working set in
+// memory: it reads every record through Query when opened and writes them
+// back with Put on Save, so a backend only needs to load and save records
+// faithfully. The JSON file backend is built in; embedders can Register
+// others (a database, a key-value store) and select them with store.backend.
+type Backend interface {
+	// Location is the file or DSN the backend reads, for messages
+	Location() string
+	// Query returns the records matching q, soft-deleted ones included, oldest
+	// first. An empty query returns every record.
+	Query(q Query) (Cursor, error)
+	// Imported returns the staging files migrated so far, with their fetch time
+	Imported() (map[string]time.Time, error)
+	// History returns the undo log, oldest operation first
+	History() ([]Operation, error)
+	// Put writes a batch, replacing records stored under the same UUID and
+	// removing the deleted ones. It should be atomic: after a failed Put the
+	// previous records must remain.
+	Put(batch Batch) error
+	// Close releases the backend's resources
+	Close() error
+}
+
+// Cursor iterates over the records a query returned:
+//
+//	for cursor.Next() {
+//		record := cursor.Record()
+//	}
+//	if err := cursor.Err(); err != nil { ... }
+type Cursor interface {
+	Next() bool
+	Record() *Record
+	Err() error
+	Close() error
+}
+
+// Batch is what a Save writes
+type Batch struct {
+	Records  []*Record            // Records to insert or replace, by UUID
+	Deleted  []string             // UUIDs of records to remove outright (undone imports)
+	Imported map[string]time.Time // Every staging file migrated so far
+	History  []Operation          // The whole undo log
+}
+
+// Encrypter is implemented by backends that can encrypt records at rest
+type Encrypter interface {
+	Encrypted() bool
+	// SetPassphrase makes the next Put encrypt with passphrase; empty turns
+	// encryption off
+	SetPassphrase(passphrase string) error
+}
+
+// Opener opens a backend at a location (a path or DSN)
+type Opener func(location string) (Backend, error)
+
+// DefaultBackend is the backend used when store.backend is not set
+const DefaultBackend = "json"
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]Opener{DefaultBackend: OpenFile}
+)
+
+// Register makes a backend available under name. Registering a name twice
+// replaces the earlier opener.
+func Register(name string, open Opener) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[strings.ToLower(name)] = open
+}
+
+// Backends returns the registered backend names, sorted
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateBackend checks that a backend is registered; empty is the default
+func ValidateBackend(name string) error {
+	if name == "" {
+		return nil
+	}
+	backendsMu.RLock()
+	_, ok := backends[strings.ToLower(name)]
+	backendsMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("store.backend: unknown backend %q (available: %s)", name, strings.Join(Backends(), ", "))
+	}
+	return nil
+}
+
+// OpenBackend opens the store with the named backend (empty for the default)
+// at location
+func OpenBackend(name, location string) (*Store, error) {
+	if name == "" {
+		name = DefaultBackend
+	}
+	if err := ValidateBackend(name); err != nil {
+		return nil, err
+	}
+	backendsMu.RLock()
+	open := backends[strings.ToLower(name)]
+	backendsMu.RUnlock()
+
+	backend, err := open(location)
+	if err != nil {
+		return nil, err
+	}
+	return New(backend)
+}
+
+// SliceCursor is a Cursor over records already in memory, for backends
+// without a cursor of their own
+type SliceCursor struct {
+	records []*Record
+	pos     int
+}
+
+// NewSliceCursor returns a cursor over records, in the order given
+func NewSliceCursor(records []*Record) *SliceCursor {
+	return &SliceCursor{records: records}
+}
+
+// Next advances to the next record
+func (c *SliceCursor) Next() bool {
+	if c.pos >= len(c.records) {
+		return false
+	}
+	c.pos++
+	return true
+}
+
+// Record returns the current record
+func (c *SliceCursor) Record() *Record {
+	return c.records[c.pos-1]
+}
+
+// Err is always nil
+func (c *SliceCursor) Err() error { return nil }
+
+// Close does nothing
+func (c *SliceCursor) Close() error { return nil }
@@ -0,0 +1,221 @@
+package store
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Encrypted stores are the JSON document sealed with AES-256-GCM under a key
+// derived from a passphrase with PBKDF2-HMAC-SHA256:
+//
+//	magic | salt (16) | nonce (12) | ciphertext
+//
+// The store is only ever decrypted in memory.
+var encryptedMagic = []byte("FINTRACK-ENCRYPTED-STORE-1\n")
+
+const (
+	saltSize      = 16
+	kdfIterations = 600000
+	keySize       = 32
+)
+
+// ErrWrongPassphrase is returned when a passphrase doesn't decrypt the store
+var ErrWrongPassphrase = errors.New("wrong passphrase, or the store is corrupted")
+
+// PassphraseSource supplies the passphrase of an encrypted store
+type PassphraseSource interface {
+	// Passphrase returns the passphrase for the store at path
+	Passphrase(path string) (string, error)
+	// Verified is called once a passphrase decrypted the store, so it can be cached
+	Verified(path, passphrase string)
+}
+
+var passphraseSource PassphraseSource
+
+// SetPassphraseSource sets where Open gets passphrases for encrypted stores
+func SetPassphraseSource(source PassphraseSource) {
+	passphraseSource = source
+}
+
+// IsEncrypted reports whether data is an encrypted store
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedMagic)
+}
+
+// Encrypted reports whether the store is saved encrypted
+func (s *Store) Encrypted() bool {
+	encrypter, ok := s.backend.(Encrypter)
+	return ok && encrypter.Encrypted()
+}
+
+// SetPassphrase makes Save encrypt the store with passphrase; an empty
+// passphrase saves it in plain JSON again. Only backends implementing
+// Encrypter support it.
+func (s *Store) SetPassphrase(passphrase string) error {
+	encrypter, ok := s.backend.(Encrypter)
+	if !ok {
+		return fmt.Errorf("the store backend at %s does not support encryption", s.Path())
+	}
+	return encrypter.SetPassphrase(passphrase)
+}
+
+func (b *fileBackend) Encrypted() bool {
+	return b.passphrase != ""
+}
+
+func (b *fileBackend) SetPassphrase(passphrase string) error {
+	b.passphrase = passphrase
+	b.salt = nil
+	if passphrase == "" {
+		return nil
+	}
+	b.salt = make([]byte, saltSize)
+	if _, err := rand.Read(b.salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return nil
+}
+
+// decrypt opens an encrypted store document, asking the passphrase source
+func (b *fileBackend) decrypt(data []byte) ([]byte, error) {
+	if passphraseSource == nil {
+		return nil, fmt.Errorf("store %s is encrypted and no passphrase is available", b.path)
+	}
+	passphrase, err := passphraseSource.Passphrase(b.path)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, salt, err := unseal(encryptedMagic, passphrase, data)
+	if err != nil {
+		return nil, err
+	}
+
+	b.passphrase = passphrase
+	b.salt = salt
+	passphraseSource.Verified(b.path, passphrase)
+	return plain, nil
+}
+
+// encrypt seals the store document with the store's passphrase
+func (b *fileBackend) encrypt(plain []byte) ([]byte, error) {
+	return seal(encryptedMagic, b.passphrase, b.salt, plain)
+}
+
+// Seal encrypts data under a passphrase the way stores are, with a fresh
+// salt and its own magic header, for other files holding store data
+func Seal(magic []byte, passphrase string, plain []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return seal(magic, passphrase, salt, plain)
+}
+
+// Unseal decrypts data sealed with Seal under the same magic header
+func Unseal(magic []byte, passphrase string, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, magic) {
+		return nil, fmt.Errorf("not a fintrack encrypted file")
+	}
+	plain, _, err := unseal(magic, passphrase, data)
+	return plain, err
+}
+
+// seal writes magic | salt | nonce | ciphertext, authenticating the magic
+func seal(magic []byte, passphrase string, salt, plain []byte) ([]byte, error) {
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := append([]byte(nil), magic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plain, magic), nil
+}
+
+// unseal reverses seal, returning the plaintext and the salt it used
+func unseal(magic []byte, passphrase string, data []byte) ([]byte, []byte, error) {
+	body := data[len(magic):]
+	if len(body) < saltSize {
+		return nil, nil, ErrWrongPassphrase
+	}
+	salt := body[:saltSize]
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	body = body[saltSize:]
+	if len(body) < gcm.NonceSize() {
+		return nil, nil, ErrWrongPassphrase
+	}
+	plain, err := gcm.Open(nil, body[:gcm.NonceSize()], body[gcm.NonceSize():], magic)
+	if err != nil {
+		return nil, nil, ErrWrongPassphrase
+	}
+	return plain, append([]byte(nil), salt...), nil
+}
+
+// derivedKeys caches keys per passphrase and salt; the KDF is deliberately
+// slow and a command may open the store several times
+var (
+	derivedKeysMu sync.Mutex
+	derivedKeys   = make(map[string][]byte)
+)
+
+// newGCM derives the key for passphrase and salt and returns its AEAD
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	cacheKey := passphrase + "\x00" + string(salt)
+	derivedKeysMu.Lock()
+	key, ok := derivedKeys[cacheKey]
+	if !ok {
+		key = pbkdf2SHA256([]byte(passphrase), salt, kdfIterations, keySize)
+		derivedKeys[cacheKey] = key
+	}
+	derivedKeysMu.Unlock()
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return gcm, nil
+}
+
+// pbkdf2SHA256 is PBKDF2 (RFC 8018) with HMAC-SHA256
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.Write(prf, binary.BigEndian, block)
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
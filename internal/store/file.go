@@ -0,0 +1,185 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/profile"
+	"github.com/quickkly/fintrack/internal/safefile"
+)
+
+// SchemaVersion is the version of the store document written by this
+// release; older documents are migrated on open (see migrate.go)
+const SchemaVersion = 1
+
+// document is the JSON file backend's on-disk format
+type document struct {
+	Version  int                  `json:"version"`
+	Records  map[string]*Record   `json:"records"`  // Keyed by transaction UUID
+	Imported map[string]time.Time `json:"imported"` // Staging files already migrated, with their fetch time
+	History  []Operation          `json:"history,omitempty"`
+}
+
+// fileBackend keeps the store in a single JSON document, optionally
+// encrypted. The whole document is read on open and rewritten on Put.
+type fileBackend struct {
+	path       string
+	doc        document
+	passphrase string // Set when the store is saved encrypted
+	salt       []byte
+
+	// Schema migration applied on open (see migrate.go)
+	version  int         // Version of the document as read
+	migrated []Migration // Applied, not yet saved
+	original []byte      // The file as read, kept as a backup when the migration is saved
+}
+
+// OpenFile opens the JSON file backend at path. A missing file is an empty
+// store.
+func OpenFile(path string) (Backend, error) {
+	b := &fileBackend{
+		path:    path,
+		version: SchemaVersion,
+		doc: document{
+			Version:  SchemaVersion,
+			Records:  make(map[string]*Record),
+			Imported: make(map[string]time.Time),
+		},
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	}
+
+	raw := data
+	if IsEncrypted(data) {
+		if data, err = b.decrypt(data); err != nil {
+			return nil, err
+		}
+	}
+
+	// Older documents are upgraded in memory and reach disk with the next Put
+	data, b.version, b.migrated, err = migrateDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse store %s: %w", path, err)
+	}
+	if b.version > SchemaVersion {
+		return nil, fmt.Errorf("store %s has schema version %d, newer than this fintrack supports (%d)", path, b.version, SchemaVersion)
+	}
+	if len(b.migrated) > 0 {
+		b.original = raw
+	}
+
+	if err := json.Unmarshal(data, &b.doc); err != nil {
+		return nil, fmt.Errorf("failed to parse store %s: %w", path, err)
+	}
+	if b.doc.Records == nil {
+		b.doc.Records = make(map[string]*Record)
+	}
+	if b.doc.Imported == nil {
+		b.doc.Imported = make(map[string]time.Time)
+	}
+	return b, nil
+}
+
+func (b *fileBackend) Location() string {
+	return b.path
+}
+
+func (b *fileBackend) OpenedVersion() int {
+	return b.version
+}
+
+func (b *fileBackend) Migrated() []Migration {
+	return b.migrated
+}
+
+// BackupPath is the store path with the old schema version appended, e.g.
+// transactions.json.v0.bak
+func (b *fileBackend) BackupPath() string {
+	if b.version >= SchemaVersion {
+		return ""
+	}
+	return fmt.Sprintf("%s.v%d.bak", b.path, b.version)
+}
+
+func (b *fileBackend) Query(q Query) (Cursor, error) {
+	var records []*Record
+	for _, record := range b.doc.Records {
+		if q.Matches(record) {
+			records = append(records, record)
+		}
+	}
+	sortRecords(records)
+	return NewSliceCursor(records), nil
+}
+
+func (b *fileBackend) Imported() (map[string]time.Time, error) {
+	imported := make(map[string]time.Time, len(b.doc.Imported))
+	for name, at := range b.doc.Imported {
+		imported[name] = at
+	}
+	return imported, nil
+}
+
+// History returns a copy, so the store's appends reach disk only on Put
+func (b *fileBackend) History() ([]Operation, error) {
+	return append([]Operation(nil), b.doc.History...), nil
+}
+
+// Put writes the document atomically
+func (b *fileBackend) Put(batch Batch) error {
+	defer profile.Start(profile.Write)()
+
+	for _, record := range batch.Records {
+		b.doc.Records[record.Transaction.UUID] = record
+	}
+	for _, uuid := range batch.Deleted {
+		delete(b.doc.Records, uuid)
+	}
+	if batch.Imported != nil {
+		b.doc.Imported = batch.Imported
+	}
+	if batch.History != nil {
+		b.doc.History = batch.History
+	}
+	b.doc.Version = SchemaVersion
+
+	data, err := json.MarshalIndent(b.doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal store: %w", err)
+	}
+	if b.Encrypted() {
+		if data, err = b.encrypt(data); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+		return fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	// The first save after a migration keeps the old file, so an upgrade can
+	// be rolled back by hand
+	if len(b.migrated) > 0 {
+		if err := safefile.Write(b.BackupPath(), b.original, 0600); err != nil {
+			return fmt.Errorf("failed to back up store before migrating: %w", err)
+		}
+	}
+	if err := safefile.Write(b.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write store: %w", err)
+	}
+	b.migrated, b.original = nil, nil
+	return nil
+}
+
+func (b *fileBackend) Close() error {
+	return nil
+}
@@ -0,0 +1,210 @@
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// historyLimit is how many operations the undo log keeps; older ones are
+// dropped and can no longer be undone
+const historyLimit = 200
+
+// Operation is one local mutation of the store (an import, a tag change, a
+// migration) with what it needs to be undone. The log lives in the store
+// document, so encryption and backups cover it.
+type Operation struct {
+	ID       int       `json:"id"`
+	At       time.Time `json:"at"`
+	Command  string    `json:"command"` // Command line that made the change
+	Summary  string    `json:"summary"`
+	Changes  []Change  `json:"changes,omitempty"`
+	Imported []string  `json:"imported,omitempty"` // Staging files it marked migrated
+	UndoOf   int       `json:"undo_of,omitempty"`  // Operation this one undid
+}
+
+// Change is a record an operation added, changed or removed
+type Change struct {
+	UUID   string  `json:"uuid"`
+	Before *Record `json:"before,omitempty"` // Nil when the operation added the record
+	After  string  `json:"after,omitempty"`  // Hash of the record it left; empty when it removed it
+}
+
+// Checkpoint is the state of a store before an operation, to diff against
+// with Record
+type Checkpoint struct {
+	records  map[string][]byte
+	imported map[string]bool
+}
+
+// Checkpoint snapshots the records and migrated staging files
+func (s *Store) Checkpoint() (*Checkpoint, error) {
+	cp := &Checkpoint{records: make(map[string][]byte, len(s.Records)), imported: make(map[string]bool, len(s.Imported))}
+	for uuid, record := range s.Records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot record %s: %w", uuid, err)
+		}
+		cp.records[uuid] = data
+	}
+	for key := range s.Imported {
+		cp.imported[key] = true
+	}
+	return cp, nil
+}
+
+// Record appends what changed since cp to the history as a new operation.
+// It returns nil when nothing changed.
+func (s *Store) Record(command, summary string, cp *Checkpoint, at time.Time) (*Operation, error) {
+	return s.record(command, summary, cp, at, 0)
+}
+
+func (s *Store) record(command, summary string, cp *Checkpoint, at time.Time, undoOf int) (*Operation, error) {
+	op := Operation{At: at, Command: command, Summary: summary, UndoOf: undoOf}
+
+	for uuid, record := range s.Records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode record %s: %w", uuid, err)
+		}
+		before, existed := cp.records[uuid]
+		if existed && bytes.Equal(before, data) {
+			continue
+		}
+		change := Change{UUID: uuid, After: recordHash(data)}
+		if existed {
+			if change.Before, err = decodeRecord(before); err != nil {
+				return nil, err
+			}
+		}
+		op.Changes = append(op.Changes, change)
+	}
+	for uuid, before := range cp.records {
+		if _, ok := s.Records[uuid]; ok {
+			continue
+		}
+		record, err := decodeRecord(before)
+		if err != nil {
+			return nil, err
+		}
+		op.Changes = append(op.Changes, Change{UUID: uuid, Before: record})
+	}
+	for key := range s.Imported {
+		if !cp.imported[key] {
+			op.Imported = append(op.Imported, key)
+		}
+	}
+
+	if len(op.Changes) == 0 && len(op.Imported) == 0 {
+		return nil, nil
+	}
+	sort.Slice(op.Changes, func(i, j int) bool { return op.Changes[i].UUID < op.Changes[j].UUID })
+	sort.Strings(op.Imported)
+
+	op.ID = 1
+	if n := len(s.History); n > 0 {
+		op.ID = s.History[n-1].ID + 1
+	}
+	s.History = append(s.History, op)
+	if len(s.History) > historyLimit {
+		s.History = append([]Operation(nil), s.History[len(s.History)-historyLimit:]...)
+	}
+	return &s.History[len(s.History)-1], nil
+}
+
+// Operation returns the logged operation with id, or nil
+func (s *Store) Operation(id int) *Operation {
+	for i := range s.History {
+		if s.History[i].ID == id {
+			return &s.History[i]
+		}
+	}
+	return nil
+}
+
+// UndoneBy returns the operation that undid id, or nil
+func (s *Store) UndoneBy(id int) *Operation {
+	for i := range s.History {
+		if s.History[i].UndoOf == id {
+			return &s.History[i]
+		}
+	}
+	return nil
+}
+
+// Conflicts returns the UUIDs of records an operation touched that have
+// changed since, which undoing it would overwrite
+func (s *Store) Conflicts(op *Operation) ([]string, error) {
+	var conflicts []string
+	for _, change := range op.Changes {
+		current := ""
+		if record, ok := s.Records[change.UUID]; ok {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode record %s: %w", change.UUID, err)
+			}
+			current = recordHash(data)
+		}
+		if current != change.After {
+			conflicts = append(conflicts, change.UUID)
+		}
+	}
+	return conflicts, nil
+}
+
+// Undo puts back the records an operation changed and forgets the staging
+// files it migrated, logging the undo as an operation of its own (which can
+// be undone in turn). It refuses when records were changed since, unless
+// force is set, and when the operation was already undone.
+func (s *Store) Undo(id int, command string, force bool, at time.Time) (*Operation, error) {
+	op := s.Operation(id)
+	if op == nil {
+		return nil, fmt.Errorf("operation %d is not in the history", id)
+	}
+	if undo := s.UndoneBy(id); undo != nil {
+		return nil, fmt.Errorf("operation %d was already undone by operation %d", id, undo.ID)
+	}
+	conflicts, err := s.Conflicts(op)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 && !force {
+		return nil, fmt.Errorf("%d transaction(s) changed since operation %d (first: %s); use --force to undo anyway", len(conflicts), id, conflicts[0])
+	}
+
+	cp, err := s.Checkpoint()
+	if err != nil {
+		return nil, err
+	}
+	target := *op
+	for _, change := range target.Changes {
+		if change.Before == nil {
+			s.Remove(change.UUID)
+			continue
+		}
+		s.Records[change.UUID] = change.Before
+	}
+	for _, key := range target.Imported {
+		delete(s.Imported, key)
+	}
+	return s.record(command, fmt.Sprintf("undo #%d: %s", target.ID, target.Summary), cp, at, target.ID)
+}
+
+func decodeRecord(data []byte) (*Record, error) {
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode record snapshot: %w", err)
+	}
+	return &record, nil
+}
+
+// recordHash fingerprints a record's JSON, to detect later changes without
+// keeping a second copy
+func recordHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
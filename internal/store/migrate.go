@@ -0,0 +1,106 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Migration upgrades the JSON store document by one schema version. It works
+// on the raw document rather than the Go types, which change with the models
+// and can't describe older layouts.
+type Migration struct {
+	Version     int    // Schema version the migration produces
+	Description string // What it changes, for 'fintrack store migrate'
+	Up          func(doc map[string]json.RawMessage) error
+}
+
+// migrations are applied in order to documents older than their Version.
+// When the document's format changes, add one here and bump SchemaVersion;
+// users' stores are then upgraded on open instead of having to be rebuilt.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "add the schema version, and the records and imported maps to stores written before them",
+		Up: func(doc map[string]json.RawMessage) error {
+			for _, key := range []string{"records", "imported"} {
+				if value, ok := doc[key]; !ok || string(value) == "null" {
+					doc[key] = json.RawMessage("{}")
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// Migrator is implemented by backends whose on-disk format is versioned. The
+// JSON file backend migrates older documents in memory when opened; the
+// upgrade reaches disk with the next Put.
+type Migrator interface {
+	// OpenedVersion is the schema version the data had when opened
+	OpenedVersion() int
+	// Migrated returns the migrations applied since opening that aren't saved yet
+	Migrated() []Migration
+	// BackupPath is where the data from before the migration is kept once the
+	// migrated data is saved, or empty when there is none
+	BackupPath() string
+}
+
+// Migrations returns every migration, oldest first
+func Migrations() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// migrate upgrades doc from version to SchemaVersion and returns the
+// migrations it applied
+func migrate(doc map[string]json.RawMessage, version int) ([]Migration, error) {
+	var applied []Migration
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+		if err := m.Up(doc); err != nil {
+			return nil, fmt.Errorf("store migration to version %d (%s) failed: %w", m.Version, m.Description, err)
+		}
+		version = m.Version
+		applied = append(applied, m)
+	}
+	if version != SchemaVersion {
+		return nil, fmt.Errorf("store migrations end at version %d, not schema version %d", version, SchemaVersion)
+	}
+
+	encoded, err := json.Marshal(version)
+	if err != nil {
+		return nil, err
+	}
+	doc["version"] = encoded
+	return applied, nil
+}
+
+// migrateDocument upgrades the JSON document data when it is older than
+// SchemaVersion, returning the document to parse, its version as read, and
+// the migrations applied
+func migrateDocument(data []byte) ([]byte, int, []Migration, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, 0, nil, err
+	}
+	if header.Version >= SchemaVersion {
+		return data, header.Version, nil, nil
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, nil, err
+	}
+	applied, err := migrate(doc, header.Version)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to encode migrated store: %w", err)
+	}
+	return migrated, header.Version, applied, nil
+}
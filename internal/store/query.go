@@ -0,0 +1,97 @@
+package store
+
+import (
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// Query selects stored transactions. Empty fields match everything; text
+// fields are case-insensitive substring matches.
+type Query struct {
+	UUIDs      []string
+	Merchant   string // Merchant name, or narration when Bend found no merchant
+	Narration  string
+	AccountID  string
+	CategoryID string
+	Type       string      // INCOMING or OUTGOING; empty for both
+	Amount     money.Range // On the absolute amount; a bound with a currency only matches that currency
+	From       time.Time   // Inclusive; zero for no lower bound
+	To         time.Time   // Inclusive; zero for no upper bound
+	Tags       TagFilter
+}
+
+// IsEmpty reports whether the query would select every transaction
+func (q Query) IsEmpty() bool {
+	return len(q.UUIDs) == 0 && q.Merchant == "" && q.Narration == "" && q.AccountID == "" &&
+		q.CategoryID == "" && q.Type == "" && q.Amount.IsEmpty() && q.From.IsZero() && q.To.IsZero() && q.Tags.IsEmpty()
+}
+
+// Matches reports whether a record satisfies the query
+func (q Query) Matches(record *Record) bool {
+	txn := record.Transaction
+
+	if len(q.UUIDs) > 0 {
+		found := false
+		for _, uuid := range q.UUIDs {
+			if strings.EqualFold(uuid, txn.UUID) || strings.EqualFold(uuid, txn.Fingerprint()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if q.Merchant != "" {
+		name := txn.Narration
+		if txn.Merchant != nil && txn.Merchant.Name != nil && *txn.Merchant.Name != "" {
+			name = *txn.Merchant.Name
+		}
+		if !containsFold(name, q.Merchant) {
+			return false
+		}
+	}
+	if q.Narration != "" && !containsFold(txn.Narration, q.Narration) {
+		return false
+	}
+	if q.AccountID != "" && !strings.EqualFold(txn.AccountID, q.AccountID) {
+		return false
+	}
+	if q.CategoryID != "" && (txn.Category == nil || txn.Category.ID == nil || !strings.EqualFold(*txn.Category.ID, q.CategoryID)) {
+		return false
+	}
+	if q.Type != "" && !strings.EqualFold(txn.Type, q.Type) {
+		return false
+	}
+	// Bend amounts have two decimals, so the rounding mode makes no difference
+	if !q.Amount.Contains(txn.Money(money.DefaultRounding)) {
+		return false
+	}
+	if !q.From.IsZero() && txn.TxnTimestamp.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && txn.TxnTimestamp.After(q.To) {
+		return false
+	}
+	return q.Tags.Matches(record)
+}
+
+// Find returns the live records matching the query, oldest first
+func (s *Store) Find(q Query) []*Record {
+	var found []*Record
+	for _, record := range s.Records {
+		if record.DeletedAt == nil && q.Matches(record) {
+			found = append(found, record)
+		}
+	}
+	sortRecords(found)
+	return found
+}
+
+// containsFold is a case-insensitive strings.Contains
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
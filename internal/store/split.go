@@ -0,0 +1,122 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/money"
+)
+
+// personPattern is what a person's name in a split may look like after normalization
+var personPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// Split marks a transaction as shared with a person, or as a payment settling
+// up with them. Like tags it's local and never sent to Bend.
+type Split struct {
+	With   string  `json:"with"`             // Person, normalized
+	Share  float64 `json:"share,omitempty"`  // Their part of a shared transaction, in its currency
+	Settle bool    `json:"settle,omitempty"` // The whole transaction is a settlement payment
+	Note   string  `json:"note,omitempty"`
+}
+
+// NormalizePerson lowercases and validates a person's name
+func NormalizePerson(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !personPattern.MatchString(name) {
+		return "", fmt.Errorf("invalid person %q: use letters, digits, '_', '-' or '.'", name)
+	}
+	return name, nil
+}
+
+// SplitOwed is what a record's split adds to the person's balance: positive
+// when they owe more (I paid for their share, or paid them), negative when I
+// owe more (I was paid their share, or they paid me)
+func (r *Record) SplitOwed(mode money.RoundingMode) money.Amount {
+	if r.Split == nil {
+		return 0
+	}
+	owed := money.FromFloat(r.Split.Share, mode)
+	if r.Split.Settle {
+		owed = money.FromFloat(r.Transaction.Amount, mode)
+	}
+	owed = owed.Abs()
+	if r.Transaction.Type == blend.TransactionTypeIncoming {
+		return -owed
+	}
+	return owed
+}
+
+// PersonBalance is where things stand with one person in one currency
+type PersonBalance struct {
+	Person   string       `json:"person"`
+	Currency string       `json:"currency"`
+	Balance  money.Amount `json:"-"` // Positive: they owe me; negative: I owe them
+	Shared   money.Amount `json:"-"` // Net of shared transactions
+	Settled  money.Amount `json:"-"` // Net of settlements
+	Entries  int          `json:"entries"`
+	Last     time.Time    `json:"last"`
+
+	BalanceAmount float64 `json:"balance"`
+	SharedAmount  float64 `json:"shared"`
+	SettledAmount float64 `json:"settled"`
+}
+
+// Splits returns the live records with a split, oldest first, optionally
+// only those with person
+func (s *Store) Splits(person string) []*Record {
+	var records []*Record
+	for _, record := range s.Records {
+		if record.Split == nil || record.DeletedAt != nil {
+			continue
+		}
+		if person != "" && record.Split.With != person {
+			continue
+		}
+		records = append(records, record)
+	}
+	sortRecords(records)
+	return records
+}
+
+// SplitBalances totals the splits per person and currency
+func (s *Store) SplitBalances(person string, mode money.RoundingMode) []PersonBalance {
+	byKey := make(map[string]*PersonBalance)
+	for _, record := range s.Splits(person) {
+		key := record.Split.With + "\x00" + record.Transaction.Currency
+		b, ok := byKey[key]
+		if !ok {
+			b = &PersonBalance{Person: record.Split.With, Currency: record.Transaction.Currency}
+			byKey[key] = b
+		}
+		owed := record.SplitOwed(mode)
+		b.Balance += owed
+		if record.Split.Settle {
+			b.Settled += owed
+		} else {
+			b.Shared += owed
+		}
+		b.Entries++
+		if record.Transaction.TxnTimestamp.After(b.Last) {
+			b.Last = record.Transaction.TxnTimestamp
+		}
+	}
+
+	balances := make([]PersonBalance, 0, len(byKey))
+	for _, b := range byKey {
+		b.BalanceAmount = b.Balance.Float64()
+		b.SharedAmount = b.Shared.Float64()
+		b.SettledAmount = b.Settled.Float64()
+		balances = append(balances, *b)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].Person == balances[j].Person {
+			return balances[i].Currency < balances[j].Currency
+		}
+		return balances[i].Person < balances[j].Person
+	})
+	return balances
+}
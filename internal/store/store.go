@@ -0,0 +1,317 @@
+// Package store is fintrack's local transaction store: a single JSON document
+// holding every transaction fetched so far, keyed by UUID. Unlike the staging
+// directory, where each fetch is a separate file, the store keeps exactly one
+// copy of each transaction.
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// Record is a stored transaction with its fetch history
+type Record struct {
+	Transaction blend.Transaction `json:"transaction"`
+	FirstSeen   time.Time         `json:"first_seen"` // First fetch that returned it
+	LastSeen    time.Time         `json:"last_seen"`  // Fetch the stored copy came from
+	Source      string            `json:"source,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`  // Local tags, sorted; never sent to Bend
+	Split       *Split            `json:"split,omitempty"` // Shared with or settling up with a person
+
+	// Set when a re-sync of the transaction's window no longer returned it
+	DeletedAt *time.Time   `json:"deleted_at,omitempty"`
+	Audit     []AuditEntry `json:"audit,omitempty"` // Deletions and restorations, oldest first
+}
+
+// AuditEntry records a soft-delete or restore of a stored transaction
+type AuditEntry struct {
+	At     time.Time `json:"at"`
+	Action string    `json:"action"` // deleted or restored
+	Source string    `json:"source,omitempty"`
+}
+
+// ImportSourcePrefix starts the Source of records imported from statement
+// files ('fintrack import'), which Bend never returns
+const ImportSourcePrefix = "import:"
+
+// IsImported reports whether the record came from a statement file
+func (r *Record) IsImported() bool {
+	return strings.HasPrefix(r.Source, ImportSourcePrefix)
+}
+
+// Audit actions
+const (
+	ActionDeleted  = "deleted"
+	ActionRestored = "restored"
+)
+
+// Store is the local store, held in memory. Load it with Open or OpenBackend
+// and persist changes with Save.
+type Store struct {
+	Records  map[string]*Record   // Keyed by transaction UUID
+	Imported map[string]time.Time // Staging files already migrated, with their fetch time
+	History  []Operation          // Undo log of local mutations, oldest first
+
+	backend Backend
+	removed map[string]bool // UUIDs dropped from Records since opening
+}
+
+// UpsertResult counts what an upsert did
+type UpsertResult struct {
+	New       int // Not stored before
+	Changed   int // Stored, but Bend now returns different data
+	Unchanged int // Stored and identical
+
+	Modified []string // UUIDs of the new and changed transactions
+}
+
+// Add accumulates another result
+func (r *UpsertResult) Add(other UpsertResult) {
+	r.New += other.New
+	r.Changed += other.Changed
+	r.Unchanged += other.Unchanged
+	r.Modified = append(r.Modified, other.Modified...)
+}
+
+// Total is the number of transactions upserted
+func (r UpsertResult) Total() int {
+	return r.New + r.Changed + r.Unchanged
+}
+
+// Fresh returns the transactions the upsert found new or changed, in their
+// original order
+func (r UpsertResult) Fresh(transactions []blend.Transaction) []blend.Transaction {
+	modified := make(map[string]bool, len(r.Modified))
+	for _, uuid := range r.Modified {
+		modified[uuid] = true
+	}
+
+	var fresh []blend.Transaction
+	for _, txn := range transactions {
+		if modified[txn.UUID] {
+			fresh = append(fresh, txn)
+		}
+	}
+	return fresh
+}
+
+// Open loads the JSON file store at path. A missing file is an empty store.
+func Open(path string) (*Store, error) {
+	return OpenBackend(DefaultBackend, path)
+}
+
+// New loads every record of a backend into a store
+func New(backend Backend) (*Store, error) {
+	s := &Store{Records: make(map[string]*Record), backend: backend}
+
+	cursor, err := backend.Query(Query{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store %s: %w", backend.Location(), err)
+	}
+	defer cursor.Close()
+	for cursor.Next() {
+		record := cursor.Record()
+		s.Records[record.Transaction.UUID] = record
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read store %s: %w", backend.Location(), err)
+	}
+
+	if s.Imported, err = backend.Imported(); err != nil {
+		return nil, fmt.Errorf("failed to read store %s: %w", backend.Location(), err)
+	}
+	if s.Imported == nil {
+		s.Imported = make(map[string]time.Time)
+	}
+	if s.History, err = backend.History(); err != nil {
+		return nil, fmt.Errorf("failed to read store %s: %w", backend.Location(), err)
+	}
+	return s, nil
+}
+
+// Path returns the file or DSN the store was opened from
+func (s *Store) Path() string {
+	return s.backend.Location()
+}
+
+// Backend returns the backend the store persists to
+func (s *Store) Backend() Backend {
+	return s.backend
+}
+
+// Save writes every record back to the backend
+func (s *Store) Save() error {
+	records := make([]*Record, 0, len(s.Records))
+	for _, record := range s.Records {
+		records = append(records, record)
+	}
+	sortRecords(records)
+
+	var deleted []string
+	for uuid := range s.removed {
+		if _, ok := s.Records[uuid]; !ok {
+			deleted = append(deleted, uuid)
+		}
+	}
+	sort.Strings(deleted)
+	return s.backend.Put(Batch{Records: records, Deleted: deleted, Imported: s.Imported, History: s.History})
+}
+
+// Remove drops a record outright, unlike MarkDeleted's soft delete. Only
+// undoing the operation that added it should need this.
+func (s *Store) Remove(uuid string) {
+	delete(s.Records, uuid)
+	if s.removed == nil {
+		s.removed = make(map[string]bool)
+	}
+	s.removed[uuid] = true
+}
+
+// Close releases the backend
+func (s *Store) Close() error {
+	return s.backend.Close()
+}
+
+// Upsert stores transactions fetched at fetchedAt. A copy older than the one
+// already stored never overwrites it, so imports may happen in any order.
+func (s *Store) Upsert(transactions []blend.Transaction, fetchedAt time.Time, source string) UpsertResult {
+	var result UpsertResult
+	for _, txn := range transactions {
+		existing, ok := s.Records[txn.UUID]
+		if !ok {
+			s.Records[txn.UUID] = &Record{Transaction: txn, FirstSeen: fetchedAt, LastSeen: fetchedAt, Source: source}
+			result.New++
+			result.Modified = append(result.Modified, txn.UUID)
+			continue
+		}
+
+		if fetchedAt.Before(existing.FirstSeen) {
+			existing.FirstSeen = fetchedAt
+		}
+		if fetchedAt.Before(existing.LastSeen) {
+			result.Unchanged++
+			continue
+		}
+
+		if existing.DeletedAt != nil {
+			// Bend returns it again: the removal was undone upstream
+			existing.DeletedAt = nil
+			existing.Audit = append(existing.Audit, AuditEntry{At: fetchedAt, Action: ActionRestored, Source: source})
+			existing.Transaction = txn
+			existing.Source = source
+			result.Changed++
+			result.Modified = append(result.Modified, txn.UUID)
+		} else if sameTransaction(existing.Transaction, txn) {
+			result.Unchanged++
+		} else {
+			existing.Transaction = txn
+			existing.Source = source
+			result.Changed++
+			result.Modified = append(result.Modified, txn.UUID)
+		}
+		existing.LastSeen = fetchedAt
+	}
+	return result
+}
+
+// MarkDeleted soft-deletes every live transaction dated within [from, to]
+// that is missing from seen, a complete fetch of that window. The records are
+// kept, with an audit entry, and returned oldest first. Imported records are
+// never in a fetch and are left alone.
+func (s *Store) MarkDeleted(from, to time.Time, seen []blend.Transaction, at time.Time, source string) []*Record {
+	present := make(map[string]bool, len(seen))
+	for _, txn := range seen {
+		present[txn.UUID] = true
+	}
+
+	var deleted []*Record
+	for uuid, record := range s.Records {
+		ts := record.Transaction.TxnTimestamp
+		if record.DeletedAt != nil || present[uuid] || record.IsImported() || ts.Before(from) || ts.After(to) {
+			continue
+		}
+		deletedAt := at
+		record.DeletedAt = &deletedAt
+		record.Audit = append(record.Audit, AuditEntry{At: at, Action: ActionDeleted, Source: source})
+		deleted = append(deleted, record)
+	}
+	sortRecords(deleted)
+	return deleted
+}
+
+// IsDeleted reports whether a transaction was soft-deleted
+func (s *Store) IsDeleted(uuid string) bool {
+	record, ok := s.Records[uuid]
+	return ok && record.DeletedAt != nil
+}
+
+// WithoutDeleted drops soft-deleted transactions, for consumers reading
+// transactions from elsewhere (such as the staging directory)
+func (s *Store) WithoutDeleted(transactions []blend.Transaction) []blend.Transaction {
+	kept := make([]blend.Transaction, 0, len(transactions))
+	for _, txn := range transactions {
+		if !s.IsDeleted(txn.UUID) {
+			kept = append(kept, txn)
+		}
+	}
+	return kept
+}
+
+// Deleted returns the soft-deleted records, oldest transaction first
+func (s *Store) Deleted() []*Record {
+	var deleted []*Record
+	for _, record := range s.Records {
+		if record.DeletedAt != nil {
+			deleted = append(deleted, record)
+		}
+	}
+	sortRecords(deleted)
+	return deleted
+}
+
+// Transactions returns every stored transaction that isn't soft-deleted,
+// oldest first
+func (s *Store) Transactions() []blend.Transaction {
+	transactions := make([]blend.Transaction, 0, len(s.Records))
+	for _, record := range s.Records {
+		if record.DeletedAt == nil {
+			transactions = append(transactions, record.Transaction)
+		}
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		if transactions[i].TxnTimestamp.Equal(transactions[j].TxnTimestamp) {
+			return transactions[i].UUID < transactions[j].UUID
+		}
+		return transactions[i].TxnTimestamp.Before(transactions[j].TxnTimestamp)
+	})
+	return transactions
+}
+
+// Len returns the number of stored transactions
+func (s *Store) Len() int {
+	return len(s.Records)
+}
+
+// sortRecords orders records by transaction time, then UUID
+func sortRecords(records []*Record) {
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i].Transaction, records[j].Transaction
+		if a.TxnTimestamp.Equal(b.TxnTimestamp) {
+			return a.UUID < b.UUID
+		}
+		return a.TxnTimestamp.Before(b.TxnTimestamp)
+	})
+}
+
+// sameTransaction compares two copies of a transaction field by field
+func sameTransaction(a, b blend.Transaction) bool {
+	left, errA := json.Marshal(a)
+	right, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(left, right)
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// tagPattern is what a tag may look like after normalization
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_:.-]*$`)
+
+// NormalizeTag lowercases and validates a tag name
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if !tagPattern.MatchString(tag) {
+		return "", fmt.Errorf("invalid tag %q: use letters, digits, '_', '-', ':' or '.'", tag)
+	}
+	return tag, nil
+}
+
+// AddTag tags a record, reporting whether it wasn't tagged already
+func (r *Record) AddTag(tag string) bool {
+	if r.HasTag(tag) {
+		return false
+	}
+	r.Tags = append(r.Tags, tag)
+	sort.Strings(r.Tags)
+	return true
+}
+
+// RemoveTag untags a record, reporting whether it was tagged
+func (r *Record) RemoveTag(tag string) bool {
+	for i, t := range r.Tags {
+		if t == tag {
+			r.Tags = append(r.Tags[:i], r.Tags[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// HasTag reports whether a record carries a tag
+func (r *Record) HasTag(tag string) bool {
+	for _, t := range r.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// TagCounts returns how many live transactions carry each tag
+func (s *Store) TagCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, record := range s.Records {
+		if record.DeletedAt != nil {
+			continue
+		}
+		for _, tag := range record.Tags {
+			counts[tag]++
+		}
+	}
+	return counts
+}
+
+// TagFilter selects transactions by their local tags
+type TagFilter struct {
+	All     []string // Every one of these tags is required
+	Any     []string // At least one of these tags is required
+	Exclude []string // None of these tags may be present
+}
+
+// NewTagFilter normalizes the tags of a filter built from flags
+func NewTagFilter(all, any, exclude []string) (TagFilter, error) {
+	var f TagFilter
+	var err error
+	if f.All, err = normalizeTags(all); err != nil {
+		return f, err
+	}
+	if f.Any, err = normalizeTags(any); err != nil {
+		return f, err
+	}
+	if f.Exclude, err = normalizeTags(exclude); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// IsEmpty reports whether the filter selects everything
+func (f TagFilter) IsEmpty() bool {
+	return len(f.All) == 0 && len(f.Any) == 0 && len(f.Exclude) == 0
+}
+
+// Matches reports whether a record satisfies the filter. Untagged or unstored
+// transactions (nil record) only match filters without required tags.
+func (f TagFilter) Matches(record *Record) bool {
+	has := func(tag string) bool { return record != nil && record.HasTag(tag) }
+
+	for _, tag := range f.All {
+		if !has(tag) {
+			return false
+		}
+	}
+	if len(f.Any) > 0 {
+		found := false
+		for _, tag := range f.Any {
+			if has(tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, tag := range f.Exclude {
+		if has(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterTags keeps the transactions whose stored record matches the filter
+func (s *Store) FilterTags(transactions []blend.Transaction, f TagFilter) []blend.Transaction {
+	if f.IsEmpty() {
+		return transactions
+	}
+	var kept []blend.Transaction
+	for _, txn := range transactions {
+		if f.Matches(s.Records[txn.UUID]) {
+			kept = append(kept, txn)
+		}
+	}
+	return kept
+}
+
+// normalizeTags normalizes a list of tags
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		t, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, t)
+	}
+	return normalized, nil
+}
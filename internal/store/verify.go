@@ -0,0 +1,164 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Integrity checks run by Verify
+const (
+	CheckKey       = "key"       // Record stored under another UUID than its own
+	CheckDuplicate = "duplicate" // Live records with the same content under different UUIDs
+	CheckSplit     = "split"     // Split on a deleted record, or one that can't be right
+	CheckHistory   = "history"   // Undo log entries referring to nothing
+	CheckAudit     = "audit"     // Deletion state disagreeing with the audit trail
+	CheckBalance   = "balance"   // Computed balance disagreeing with Bend's (checked by the caller)
+)
+
+// Issue is one problem Verify found
+type Issue struct {
+	Check  string `json:"check"`
+	UUID   string `json:"uuid,omitempty"` // Record it concerns, when there is one
+	Detail string `json:"detail"`
+}
+
+// Verify checks the store's internal consistency: record keys, duplicate
+// transactions, splits, the undo log and the audit trail. It changes nothing.
+// Issues are sorted by check, then UUID.
+func (s *Store) Verify() []Issue {
+	var issues []Issue
+	add := func(check, uuid, format string, args ...interface{}) {
+		issues = append(issues, Issue{Check: check, UUID: uuid, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	byContent := make(map[string][]string)
+	for key, record := range s.Records {
+		txn := record.Transaction
+		switch {
+		case txn.UUID == "":
+			add(CheckKey, key, "transaction has no UUID")
+		case txn.UUID != key:
+			add(CheckKey, key, "stored under %s but its UUID is %s", key, txn.UUID)
+		}
+
+		if record.DeletedAt == nil {
+			fingerprint := txn.ContentFingerprint()
+			byContent[fingerprint] = append(byContent[fingerprint], key)
+		}
+
+		if split := record.Split; split != nil {
+			if record.DeletedAt != nil {
+				add(CheckSplit, key, "split with %s is on a transaction deleted upstream", split.With)
+			}
+			if normalized, err := NormalizePerson(split.With); err != nil || normalized != split.With {
+				add(CheckSplit, key, "split person %q is not a valid name", split.With)
+			}
+			if split.Settle && split.Share != 0 {
+				add(CheckSplit, key, "split is both a settlement and a share of %.2f", split.Share)
+			}
+			if math.Abs(split.Share) > math.Abs(txn.Amount)+0.005 {
+				add(CheckSplit, key, "share %.2f is more than the amount %.2f", split.Share, txn.Amount)
+			}
+		}
+
+		deleted := false
+		if n := len(record.Audit); n > 0 {
+			deleted = record.Audit[n-1].Action == ActionDeleted
+		}
+		switch {
+		case record.DeletedAt != nil && !deleted && len(record.Audit) > 0:
+			add(CheckAudit, key, "marked deleted but the last audit entry is %q", record.Audit[len(record.Audit)-1].Action)
+		case record.DeletedAt == nil && deleted:
+			add(CheckAudit, key, "audit says deleted but the record is live")
+		}
+	}
+
+	for _, uuids := range byContent {
+		if len(uuids) < 2 {
+			continue
+		}
+		sort.Strings(uuids)
+		for _, uuid := range uuids[1:] {
+			add(CheckDuplicate, uuid, "same transaction as %s under another UUID", uuids[0])
+		}
+	}
+
+	ids := make(map[int]bool, len(s.History))
+	for i, op := range s.History {
+		if i > 0 && op.ID <= s.History[i-1].ID {
+			add(CheckHistory, "", "operation %d follows operation %d; IDs must increase", op.ID, s.History[i-1].ID)
+		}
+		// Operations older than the kept history may be undone by ones still in it
+		if op.UndoOf != 0 && !ids[op.UndoOf] && len(s.History) > 0 && op.UndoOf >= s.History[0].ID {
+			add(CheckHistory, "", "operation %d undoes operation %d, which isn't in the history", op.ID, op.UndoOf)
+		}
+		ids[op.ID] = true
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Check != issues[j].Check {
+			return issues[i].Check < issues[j].Check
+		}
+		return issues[i].UUID < issues[j].UUID
+	})
+	return issues
+}
+
+// Stats summarizes the store's contents
+type Stats struct {
+	Records  int   `json:"records"`
+	Deleted  int   `json:"deleted"`  // Soft-deleted records among them
+	Splits   int   `json:"splits"`   // Records with a split
+	History  int   `json:"history"`  // Operations in the undo log
+	Imported int   `json:"imported"` // Staging files marked migrated
+	Bytes    int64 `json:"bytes"`    // Size on disk, when the backend is a file
+}
+
+// Stats counts the store's records and log entries. Bytes is left for the
+// caller, which knows whether the backend is a file.
+func (s *Store) Stats() Stats {
+	stats := Stats{Records: len(s.Records), History: len(s.History), Imported: len(s.Imported)}
+	for _, record := range s.Records {
+		if record.DeletedAt != nil {
+			stats.Deleted++
+		}
+		if record.Split != nil {
+			stats.Splits++
+		}
+	}
+	return stats
+}
+
+// CompactOptions chooses what Compact removes
+type CompactOptions struct {
+	// PurgeDeletedBefore drops soft-deleted records deleted before it; zero
+	// keeps them all
+	PurgeDeletedBefore time.Time
+	// DropHistory clears the undo log, so nothing before can be undone
+	DropHistory bool
+}
+
+// CompactResult counts what Compact removed
+type CompactResult struct {
+	Purged  int // Soft-deleted records dropped
+	History int // Undo log operations dropped
+}
+
+// Compact shrinks the store by dropping old soft-deleted records and, when
+// asked, the undo log. Save writes the result.
+func (s *Store) Compact(opts CompactOptions) CompactResult {
+	var result CompactResult
+	for uuid, record := range s.Records {
+		if record.DeletedAt != nil && !opts.PurgeDeletedBefore.IsZero() && record.DeletedAt.Before(opts.PurgeDeletedBefore) {
+			s.Remove(uuid)
+			result.Purged++
+		}
+	}
+	if opts.DropHistory {
+		result.History = len(s.History)
+		s.History = []Operation{}
+	}
+	return result
+}
@@ -0,0 +1,140 @@
+// Package syncreport records the outcome of each sync run in a JSON file that
+// monitoring systems can scrape
+package syncreport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/profile"
+	"github.com/quickkly/fintrack/internal/safefile"
+)
+
+// Report is the machine-readable outcome of one sync run
+type Report struct {
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Success         bool      `json:"success"`
+	LastSuccess     time.Time `json:"last_success,omitempty"` // Carried over from earlier runs when this one fails
+	Environment     string    `json:"environment,omitempty"`
+
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Accounts    int       `json:"accounts"`
+	Stale       int       `json:"stale_accounts"`    // Accounts whose data is not current, see AccountBalance.Stale
+	Fetched     int       `json:"fetched"`           // Transactions returned by Bend
+	New         int       `json:"new"`               // Transactions not in the local store before
+	Known       int       `json:"known"`             // Already stored and unchanged
+	Changed     int       `json:"changed"`           // Already stored, but Bend returned different data
+	Deleted     int       `json:"deleted"`           // Stored in the window but no longer returned (soft-deleted)
+	StagingFile string    `json:"staging_file"`      // Where the new and changed transactions were written
+	Partial     *Partial  `json:"partial,omitempty"` // Set when pagination failed part-way
+
+	Balances      []AccountBalance   `json:"balances"`
+	BalanceTotals map[string]float64 `json:"balance_totals"` // Sum of account balances per currency
+	Consents      []Consent          `json:"consents,omitempty"`
+
+	Errors []string `json:"errors"`
+	Alerts []string `json:"alerts"`
+}
+
+// Partial describes a transaction fetch that failed after some pages
+// arrived. Those transactions were stored, but the run counts as failed.
+type Partial struct {
+	Pages   int    `json:"pages"`   // Pages fetched before the failure
+	Fetched int    `json:"fetched"` // Transactions in those pages
+	Cursor  string `json:"cursor"`  // Pagination cursor of the failed page
+}
+
+// AccountBalance is an account's balance as reported at sync time
+type AccountBalance struct {
+	AccountID string    `json:"account_id"`
+	Currency  string    `json:"currency"`
+	Balance   float64   `json:"balance"`
+	AsOf      time.Time `json:"as_of"`
+
+	// Set when the bank is down or Bend hasn't fetched from it recently,
+	// so Balance is not current
+	Stale       bool   `json:"stale,omitempty"`
+	StaleReason string `json:"stale_reason,omitempty"`
+}
+
+// Consent is the state of an account's account aggregator consent at sync
+// time, for accounts whose expiry or status is known
+type Consent struct {
+	AccountID string     `json:"account_id"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	DaysLeft  *int       `json:"days_left,omitempty"` // Negative once expired
+}
+
+// New starts a report, carrying over the last success time of the previous one
+func New(previous *Report) *Report {
+	r := &Report{
+		StartedAt:     time.Now(),
+		Balances:      []AccountBalance{},
+		BalanceTotals: map[string]float64{},
+		Errors:        []string{},
+		Alerts:        []string{},
+	}
+	if previous != nil {
+		r.LastSuccess = previous.LastSuccess
+	}
+	return r
+}
+
+// AddError records a problem. Errors don't fail the run by themselves.
+func (r *Report) AddError(err error) {
+	r.Errors = append(r.Errors, err.Error())
+}
+
+// Finish stamps the end of the run
+func (r *Report) Finish(success bool) {
+	r.FinishedAt = time.Now()
+	r.DurationSeconds = r.FinishedAt.Sub(r.StartedAt).Seconds()
+	r.Success = success
+	if success {
+		r.LastSuccess = r.FinishedAt
+	}
+}
+
+// Load reads a report. A missing file yields nil with no error.
+func Load(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync report: %w", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse sync report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// Write saves the report atomically so a scraper never sees a partial file
+func Write(path string, report *Report) error {
+	defer profile.Start(profile.Write)()
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync report: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create sync report directory: %w", err)
+	}
+
+	if err := safefile.Write(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write sync report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+// Package telemetry provides Prometheus metrics and OTLP tracing for the Bend
+// client. Both are client middleware and only run when configured.
+package telemetry
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the latency histograms
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// histogram is a cumulative Prometheus histogram
+type histogram struct {
+	counts []uint64 // One per bucket, plus +Inf
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(seconds float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(latencyBuckets)+1)
+	}
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.counts[len(latencyBuckets)]++
+	h.sum += seconds
+	h.count++
+}
+
+// Metrics collects request and command metrics in memory and serves them in
+// the Prometheus text format
+type Metrics struct {
+	mu        sync.Mutex
+	requests  map[string]uint64     // method, path, status
+	errors    map[string]uint64     // method, path
+	latency   map[string]*histogram // method, path
+	durations map[string]*histogram // command, result
+}
+
+// NewMetrics creates an empty metrics registry
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests:  make(map[string]uint64),
+		errors:    make(map[string]uint64),
+		latency:   make(map[string]*histogram),
+		durations: make(map[string]*histogram),
+	}
+}
+
+// Middleware records the count, latency and outcome of every API request
+func (m *Metrics) Middleware(next http.RoundTripper) http.RoundTripper {
+	return blend.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+		elapsed := time.Since(start).Seconds()
+
+		path := blend.NormalizePath(req.URL.Path)
+		route := labels("method", req.Method, "path", path)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		observe(m.latency, route, elapsed)
+		if err != nil {
+			m.errors[route]++
+			return nil, err
+		}
+		m.requests[labels("method", req.Method, "path", path, "status", strconv.Itoa(resp.StatusCode))]++
+		if resp.StatusCode >= 400 {
+			m.errors[route]++
+		}
+		return resp, nil
+	})
+}
+
+// ObserveCommand records how long a command (e.g. a sync) took and whether it failed
+func (m *Metrics) ObserveCommand(command string, elapsed time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	observe(m.durations, labels("command", command, "result", result), elapsed.Seconds())
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP fintrack_api_requests_total Bend API requests by response status.")
+	fmt.Fprintln(w, "# TYPE fintrack_api_requests_total counter")
+	writeCounters(w, "fintrack_api_requests_total", m.requests)
+
+	fmt.Fprintln(w, "# HELP fintrack_api_errors_total Bend API requests that failed or returned an error status.")
+	fmt.Fprintln(w, "# TYPE fintrack_api_errors_total counter")
+	writeCounters(w, "fintrack_api_errors_total", m.errors)
+
+	fmt.Fprintln(w, "# HELP fintrack_api_request_duration_seconds Bend API request latency.")
+	fmt.Fprintln(w, "# TYPE fintrack_api_request_duration_seconds histogram")
+	writeHistograms(w, "fintrack_api_request_duration_seconds", m.latency)
+
+	fmt.Fprintln(w, "# HELP fintrack_command_duration_seconds Duration of fintrack commands such as syncs.")
+	fmt.Fprintln(w, "# TYPE fintrack_command_duration_seconds histogram")
+	writeHistograms(w, "fintrack_command_duration_seconds", m.durations)
+
+	circuits := blend.ActiveCircuits()
+	if len(circuits) == 0 {
+		return
+	}
+	now := time.Now()
+	fmt.Fprintln(w, "# HELP fintrack_api_circuit_open Whether the circuit breaker is failing requests to an endpoint fast (1) or not (0).")
+	fmt.Fprintln(w, "# TYPE fintrack_api_circuit_open gauge")
+	for _, c := range circuits {
+		open := 0
+		if c.Open(now) {
+			open = 1
+		}
+		fmt.Fprintf(w, "fintrack_api_circuit_open{%s} %d\n", labels("endpoint", c.Endpoint), open)
+	}
+	fmt.Fprintln(w, "# HELP fintrack_api_circuit_failures Consecutive failed requests to an endpoint.")
+	fmt.Fprintln(w, "# TYPE fintrack_api_circuit_failures gauge")
+	for _, c := range circuits {
+		fmt.Fprintf(w, "fintrack_api_circuit_failures{%s} %d\n", labels("endpoint", c.Endpoint), c.Failures)
+	}
+	fmt.Fprintln(w, "# HELP fintrack_api_circuit_rejected Requests failed fast while an endpoint's circuit was open.")
+	fmt.Fprintln(w, "# TYPE fintrack_api_circuit_rejected gauge")
+	for _, c := range circuits {
+		fmt.Fprintf(w, "fintrack_api_circuit_rejected{%s} %d\n", labels("endpoint", c.Endpoint), c.Rejected)
+	}
+}
+
+// Serve exposes /metrics on addr in the background
+func (m *Metrics) Serve(addr string) (*http.Server, error) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	srv := &http.Server{Addr: addr, Handler: mux}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+
+	// Surface bind errors (port in use) instead of failing silently
+	select {
+	case err := <-errc:
+		return nil, fmt.Errorf("failed to serve metrics on %s: %w", addr, err)
+	case <-time.After(100 * time.Millisecond):
+		return srv, nil
+	}
+}
+
+// observe adds a sample to the histogram stored under key
+func observe(histograms map[string]*histogram, key string, seconds float64) {
+	h, ok := histograms[key]
+	if !ok {
+		h = &histogram{}
+		histograms[key] = h
+	}
+	h.observe(seconds)
+}
+
+// writeCounters writes one sample line per label set, in a stable order
+func writeCounters(w http.ResponseWriter, name string, counters map[string]uint64) {
+	for _, key := range sortedKeys(counters) {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, key, counters[key])
+	}
+}
+
+// writeHistograms writes the bucket, sum and count series of each histogram
+func writeHistograms(w http.ResponseWriter, name string, histograms map[string]*histogram) {
+	for _, key := range sortedKeys(histograms) {
+		h := histograms[key]
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%g\"} %d\n", name, key, bound, h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, key, h.counts[len(latencyBuckets)])
+		fmt.Fprintf(w, "%s_sum{%s} %g\n", name, key, h.sum)
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, key, h.count)
+	}
+}
+
+// labels renders name/value pairs as a Prometheus label set
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=%q", pairs[i], pairs[i+1]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
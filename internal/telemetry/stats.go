@@ -0,0 +1,198 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// Error classes recorded in usage statistics instead of error messages
+const (
+	ErrorAuth      = "auth"       // No session, or Bend rejected it
+	ErrorAPIClient = "api_client" // Bend refused the request (other 4xx)
+	ErrorAPIServer = "api_server" // Bend failed (5xx)
+	ErrorNetwork   = "network"    // Bend could not be reached
+	ErrorTimeout   = "timeout"
+	ErrorCircuit   = "circuit_open" // Not sent: the endpoint's circuit was open
+	ErrorOther     = "other"
+)
+
+// statsTimeout bounds reporting to the stats endpoint so it never holds up a run
+const statsTimeout = 5 * time.Second
+
+// Stats are the anonymous usage statistics kept in the stats file: how often
+// each command ran and failed, and the classes of the failures. They never
+// hold arguments, error messages or financial data.
+type Stats struct {
+	Since    time.Time                `json:"since"`
+	Commands map[string]*CommandStats `json:"commands"` // Keyed by command path, e.g. "fintrack sync"
+	Errors   map[string]int           `json:"errors"`   // Failures by error class
+}
+
+// CommandStats counts the runs of one command
+type CommandStats struct {
+	Runs       int       `json:"runs"`
+	Failures   int       `json:"failures"`
+	DurationMS int64     `json:"duration_ms"` // Total over all runs
+	LastRun    time.Time `json:"last_run"`
+}
+
+// StatsEvent is one command run, as recorded and reported
+type StatsEvent struct {
+	Command    string    `json:"command"`
+	Success    bool      `json:"success"`
+	ErrorClass string    `json:"error_class,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	At         time.Time `json:"at"`
+	OS         string    `json:"os"`
+	Arch       string    `json:"arch"`
+}
+
+// NewStatsEvent describes a finished command run
+func NewStatsEvent(command string, duration time.Duration, errorClass string) StatsEvent {
+	return StatsEvent{
+		Command:    command,
+		Success:    errorClass == "",
+		ErrorClass: errorClass,
+		DurationMS: duration.Milliseconds(),
+		At:         time.Now().UTC(),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+	}
+}
+
+// ClassifyError maps an error to its class; nil has no class
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	if blend.IsCircuitOpen(err) {
+		return ErrorCircuit
+	}
+
+	var statusErr *blend.StatusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden:
+			return ErrorAuth
+		case statusErr.StatusCode >= 500:
+			return ErrorAPIServer
+		default:
+			return ErrorAPIClient
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorTimeout
+		}
+		return ErrorNetwork
+	}
+
+	message := err.Error()
+	if strings.Contains(message, "no session found") || strings.Contains(message, "session expired") {
+		return ErrorAuth
+	}
+	return ErrorOther
+}
+
+// LoadStats reads the stats file. A missing file yields empty stats.
+func LoadStats(path string) (*Stats, error) {
+	stats := &Stats{Commands: make(map[string]*CommandStats), Errors: make(map[string]int)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stats file: %w", err)
+	}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("failed to parse stats file %s: %w", path, err)
+	}
+	if stats.Commands == nil {
+		stats.Commands = make(map[string]*CommandStats)
+	}
+	if stats.Errors == nil {
+		stats.Errors = make(map[string]int)
+	}
+	return stats, nil
+}
+
+// RecordStats adds a run to the stats file
+func RecordStats(path string, event StatsEvent) error {
+	stats, err := LoadStats(path)
+	if err != nil {
+		return err
+	}
+
+	if stats.Since.IsZero() {
+		stats.Since = event.At
+	}
+	command := stats.Commands[event.Command]
+	if command == nil {
+		command = &CommandStats{}
+		stats.Commands[event.Command] = command
+	}
+	command.Runs++
+	command.DurationMS += event.DurationMS
+	command.LastRun = event.At
+	if !event.Success {
+		command.Failures++
+		stats.Errors[event.ErrorClass]++
+	}
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create stats directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write stats file: %w", err)
+	}
+	return nil
+}
+
+// SendStats posts a run as JSON to a user-configured endpoint
+func SendStats(endpoint string, event StatsEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), statsTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create stats request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send stats: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("stats endpoint returned %s", resp.Status)
+	}
+	return nil
+}
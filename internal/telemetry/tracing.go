@@ -0,0 +1,169 @@
+package telemetry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+)
+
+// OTLP span kinds and status codes used here
+const (
+	spanKindClient = 3
+	statusCodeOK   = 1
+	statusCodeErr  = 2
+)
+
+// Tracer records one client span per API request and exports them to an
+// OTLP/HTTP collector (JSON encoding) when flushed
+type Tracer struct {
+	endpoint string // e.g. http://localhost:4318/v1/traces
+	service  string
+	client   *http.Client
+
+	mu      sync.Mutex
+	traceID string // All spans of a run share one trace
+	spans   []otlpSpan
+}
+
+// NewTracer creates a tracer exporting to endpoint under the given service name
+func NewTracer(endpoint, service string) *Tracer {
+	if service == "" {
+		service = "fintrack"
+	}
+	return &Tracer{
+		endpoint: endpoint,
+		service:  service,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		traceID:  randomHex(16),
+	}
+}
+
+// Middleware starts a span for every API request and propagates it to Bend
+// with a W3C traceparent header
+func (t *Tracer) Middleware(next http.RoundTripper) http.RoundTripper {
+	return blend.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		spanID := randomHex(8)
+		req = req.Clone(req.Context())
+		req.Header.Set("traceparent", fmt.Sprintf("00-%s-%s-01", t.traceID, spanID))
+
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+
+		span := otlpSpan{
+			TraceID:           t.traceID,
+			SpanID:            spanID,
+			Name:              req.Method + " " + blend.NormalizePath(req.URL.Path),
+			Kind:              spanKindClient,
+			StartTimeUnixNano: strconv.FormatInt(start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(time.Now().UnixNano(), 10),
+			Attributes: []otlpAttribute{
+				stringAttribute("http.request.method", req.Method),
+				stringAttribute("url.path", req.URL.Path),
+			},
+			Status: otlpStatus{Code: statusCodeOK},
+		}
+		if err != nil {
+			span.Status = otlpStatus{Code: statusCodeErr, Message: err.Error()}
+		} else {
+			span.Attributes = append(span.Attributes, intAttribute("http.response.status_code", resp.StatusCode))
+			if resp.StatusCode >= 400 {
+				span.Status = otlpStatus{Code: statusCodeErr}
+			}
+		}
+
+		t.mu.Lock()
+		t.spans = append(t.spans, span)
+		t.mu.Unlock()
+
+		return resp, err
+	})
+}
+
+// Flush exports the spans recorded so far
+func (t *Tracer) Flush() error {
+	t.mu.Lock()
+	spans := t.spans
+	t.spans = nil
+	t.mu.Unlock()
+
+	if len(spans) == 0 {
+		return nil
+	}
+
+	payload := map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": []otlpAttribute{stringAttribute("service.name", t.service)},
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]string{"name": "github.com/quickkly/fintrack/internal/blend"},
+						"spans": spans,
+					},
+				},
+			},
+		},
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spans: %w", err)
+	}
+
+	resp, err := t.client.Post(t.endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to export spans: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to export spans: collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+// otlpSpan is a span in the OTLP JSON encoding
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: map[string]interface{}{"stringValue": value}}
+}
+
+func intAttribute(key string, value int) otlpAttribute {
+	return otlpAttribute{Key: key, Value: map[string]interface{}{"intValue": strconv.Itoa(value)}}
+}
+
+// randomHex returns n random bytes hex encoded
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,89 @@
+// Package usage keeps a running total of the API traffic fintrack generates
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quickkly/fintrack/internal/blend"
+	"github.com/quickkly/fintrack/internal/safefile"
+)
+
+// Run is the traffic of a single fintrack invocation
+type Run struct {
+	Command string      `json:"command"`
+	At      time.Time   `json:"at"`
+	Usage   blend.Usage `json:"usage"`
+}
+
+// Totals is the cumulative traffic stored in the usage file
+type Totals struct {
+	Since   time.Time   `json:"since"`
+	Runs    int         `json:"runs"`
+	Usage   blend.Usage `json:"usage"`
+	LastRun *Run        `json:"last_run,omitempty"`
+}
+
+// Load reads the usage file. A missing file yields empty totals.
+func Load(path string) (*Totals, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Totals{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage file: %w", err)
+	}
+
+	var totals Totals
+	if err := json.Unmarshal(data, &totals); err != nil {
+		return nil, fmt.Errorf("failed to parse usage file %s: %w", path, err)
+	}
+	return &totals, nil
+}
+
+// Record adds a run to the totals in the usage file
+func Record(path string, run Run) error {
+	totals, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	if totals.Since.IsZero() {
+		totals.Since = run.At
+	}
+	totals.Runs++
+	totals.Usage.Requests += run.Usage.Requests
+	totals.Usage.BytesSent += run.Usage.BytesSent
+	totals.Usage.BytesReceived += run.Usage.BytesReceived
+	totals.LastRun = &run
+
+	data, err := json.MarshalIndent(totals, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create usage directory: %w", err)
+	}
+	if err := safefile.Write(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+	return nil
+}
+
+// FormatBytes renders a byte count for humans (e.g. "1.4 MB")
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
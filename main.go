@@ -1,9 +1,17 @@
 package main
 
 import (
+	// Timezone names resolve even where the system has no zoneinfo, such as
+	// the container image
+	_ "time/tzdata"
+
 	"github.com/quickkly/fintrack/cmd"
 )
 
+// Version is set at build time, see LDFLAGS in the Makefile
+var Version = "dev"
+
 func main() {
+	cmd.SetVersion(Version)
 	cmd.Execute()
 }